@@ -0,0 +1,281 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/logger"
+)
+
+// dashboardHTML 内置控制面板（单页，无外部依赖，可在隔离环境使用）
+//
+//go:embed dashboard/index.html
+var dashboardHTML embed.FS
+
+// sseHeartbeatInterval SSE 心跳间隔（防止代理或浏览器因空闲断开连接）
+const sseHeartbeatInterval = 15 * time.Second
+
+// sseClientBuffer 每个 SSE 客户端的事件缓冲
+const sseClientBuffer = 64
+
+// StatusFunc 返回当前状态（序列化为 JSON）
+type StatusFunc func() interface{}
+
+// Controls 管理接口可触发的操作（未设置的操作返回 404）
+type Controls struct {
+	Flatten func() // 紧急平仓（异步执行）
+	Resume  func() // 紧急平仓后恢复交易
+	// 导出订单与槽位映射，返回文件路径和订单数量
+	ExportOrderMap func() (string, int, error)
+}
+
+// Server 管理接口服务
+// GET / 控制面板；GET /status 返回当前状态；GET /events 通过 SSE 推送事件总线上的实时事件
+type Server struct {
+	cfg        *config.Config
+	status     StatusFunc
+	controls   Controls
+	httpServer *http.Server
+}
+
+// sseMessage SSE 推送的事件格式
+type sseMessage struct {
+	Type    event.Type  `json:"type"`
+	Time    time.Time   `json:"time"`
+	Payload interface{} `json:"payload"`
+}
+
+// NewServer 创建管理接口服务
+func NewServer(cfg *config.Config, status StatusFunc) *Server {
+	s := &Server{
+		cfg:    cfg,
+		status: status,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.auth(s.handleDashboard))
+	mux.HandleFunc("GET /status", s.auth(s.handleStatus))
+	mux.HandleFunc("GET /events", s.auth(s.handleEvents))
+	mux.HandleFunc("POST /flatten", s.auth(s.handleFlatten))
+	mux.HandleFunc("POST /resume", s.auth(s.handleResume))
+	mux.HandleFunc("POST /order-map", s.auth(s.handleExportOrderMap))
+	s.httpServer = &http.Server{Handler: mux}
+
+	return s
+}
+
+// SetControls 设置可触发的操作（需在 Start 之前调用）
+func (s *Server) SetControls(controls Controls) {
+	s.controls = controls
+}
+
+// Start 启动管理接口（ctx 取消时关闭服务）
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.cfg.Admin.Listen)
+	if err != nil {
+		return fmt.Errorf("管理接口监听失败: %w", err)
+	}
+
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Error("❌ [管理接口] 服务异常退出: %v", err)
+		}
+	}()
+
+	go s.publishSnapshots(ctx)
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		s.httpServer.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("✅ [管理接口] 已启动: http://%s (GET / 控制面板, GET /status, GET /events, POST /flatten, POST /resume, POST /order-map)", listener.Addr())
+	return nil
+}
+
+// publishSnapshots 定期向事件总线发布状态快照
+func (s *Server) publishSnapshots(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.cfg.Admin.SnapshotInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			event.Publish(event.TypeStatusSnapshot, s.status())
+		}
+	}
+}
+
+// auth 校验访问令牌（未配置令牌时放行）
+func (s *Server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.cfg.Admin.Token != "" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" {
+				token = r.URL.Query().Get("token")
+			}
+			if subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Admin.Token)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// handleDashboard 控制面板页面（页面通过 /events 实时更新，访问令牌从页面地址的 ?token= 透传）
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	page, err := dashboardHTML.ReadFile("dashboard/index.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(page)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.status()); err != nil {
+		logger.Warn("⚠️ [管理接口] 写入状态失败: %v", err)
+	}
+}
+
+// handleFlatten 紧急平仓（平仓耗时数秒，异步执行，立即返回 202）
+func (s *Server) handleFlatten(w http.ResponseWriter, r *http.Request) {
+	if s.controls.Flatten == nil {
+		http.NotFound(w, r)
+		return
+	}
+	logger.Warn("🆘 [管理接口] %s 请求紧急平仓", r.RemoteAddr)
+	go s.controls.Flatten()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleResume 恢复交易
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	if s.controls.Resume == nil {
+		http.NotFound(w, r)
+		return
+	}
+	logger.Info("▶️ [管理接口] %s 请求恢复交易", r.RemoteAddr)
+	s.controls.Resume()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleExportOrderMap 导出订单与槽位映射到 system.order_map_file，返回文件路径和订单数量
+func (s *Server) handleExportOrderMap(w http.ResponseWriter, r *http.Request) {
+	if s.controls.ExportOrderMap == nil {
+		http.NotFound(w, r)
+		return
+	}
+	path, orders, err := s.controls.ExportOrderMap()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	logger.Info("🗺️ [管理接口] %s 导出订单映射: %s (%d 个订单)", r.RemoteAddr, path, orders)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"file": path, "orders": orders})
+}
+
+// handleEvents SSE 事件流
+// 可选参数 types=order_filled,price 只订阅指定类型的事件
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var types []event.Type
+	if raw := r.URL.Query().Get("types"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, event.Type(t))
+			}
+		}
+	}
+
+	ch := make(chan event.Event, sseClientBuffer)
+	unsubscribe := event.Subscribe("sse:"+r.RemoteAddr, func(e event.Event) {
+		// 客户端过慢时丢弃事件，不阻塞事件总线的分发协程
+		select {
+		case ch <- e:
+		default:
+		}
+	}, types...)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// 连接建立后立即推送一次状态，客户端无需等待下一次快照
+	var id int64
+	if len(types) == 0 || containsType(types, event.TypeStatusSnapshot) {
+		id++
+		writeSSE(w, id, event.Event{Type: event.TypeStatusSnapshot, Time: time.Now(), Payload: s.status()})
+	}
+	flusher.Flush()
+
+	logger.Debug("🔗 [管理接口] SSE 客户端已连接: %s", r.RemoteAddr)
+	defer logger.Debug("🔌 [管理接口] SSE 客户端已断开: %s", r.RemoteAddr)
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-ch:
+			id++
+			if err := writeSSE(w, id, e); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeSSE 按 SSE 格式写入一个事件
+func writeSSE(w http.ResponseWriter, id int64, e event.Event) error {
+	data, err := json.Marshal(sseMessage{Type: e.Type, Time: e.Time, Payload: e.Payload})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, e.Type, data)
+	return err
+}
+
+// containsType 判断类型列表是否包含指定类型
+func containsType(types []event.Type, t event.Type) bool {
+	for _, item := range types {
+		if item == t {
+			return true
+		}
+	}
+	return false
+}
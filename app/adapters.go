@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+
+	"opensqt/exchange"
+	"opensqt/order"
+	"opensqt/position"
+)
+
+// positionExchangeAdapter 适配器，将 exchange.IExchange 转换为 position.IExchange
+// 模拟交易时挂单、订单、持仓、保证金查询和撤单都由 simulator 处理（对账、保证金检查看到的是模拟账户），
+// 交易对信息仍查询交易所
+type positionExchangeAdapter struct {
+	exchange  exchange.IExchange
+	simulator *order.SimulatedFillEngine // nil 表示真实交易
+}
+
+func (a *positionExchangeAdapter) GetPositions(ctx context.Context, symbol string) (interface{}, error) {
+	if a.simulator != nil {
+		result := []*position.PositionInfo{}
+		if size, _ := a.simulator.Position(); size != 0 {
+			result = append(result, &position.PositionInfo{Symbol: symbol, Size: size})
+		}
+		return result, nil
+	}
+
+	positions, err := a.exchange.GetPositions(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// 转换为 position.PositionInfo 切片
+	result := make([]*position.PositionInfo, len(positions))
+	for i, pos := range positions {
+		result[i] = &position.PositionInfo{
+			Symbol: pos.Symbol,
+			Size:   pos.Size,
+		}
+	}
+
+	return result, nil
+}
+
+func (a *positionExchangeAdapter) GetOpenOrders(ctx context.Context, symbol string) (interface{}, error) {
+	if a.simulator != nil {
+		return a.simulator.OpenOrders(), nil
+	}
+	return a.exchange.GetOpenOrders(ctx, symbol)
+}
+
+func (a *positionExchangeAdapter) GetOrder(ctx context.Context, symbol string, orderID int64) (interface{}, error) {
+	if a.simulator != nil {
+		ord, err := a.simulator.GetOrder(orderID)
+		if err != nil {
+			return nil, err
+		}
+		return ord, nil
+	}
+	return a.exchange.GetOrder(ctx, symbol, orderID)
+}
+
+func (a *positionExchangeAdapter) GetBaseAsset() string {
+	return a.exchange.GetBaseAsset()
+}
+
+func (a *positionExchangeAdapter) GetMinNotional() float64 {
+	return a.exchange.GetMinNotional()
+}
+
+func (a *positionExchangeAdapter) GetName() string {
+	return a.exchange.GetName()
+}
+
+func (a *positionExchangeAdapter) CancelAllOrders(ctx context.Context, symbol string) error {
+	if a.simulator != nil {
+		a.simulator.CancelAll()
+		return nil
+	}
+	return a.exchange.CancelAllOrders(ctx, symbol)
+}
+
+func (a *positionExchangeAdapter) GetMarginInfo(ctx context.Context, symbol string) (float64, int, error) {
+	available, _, leverage, err := a.GetMarginBalance(ctx, symbol)
+	return available, leverage, err
+}
+
+func (a *positionExchangeAdapter) GetMarginBalance(ctx context.Context, symbol string) (float64, float64, int, error) {
+	if a.simulator != nil {
+		available, equity, leverage := a.simulator.Balance()
+		return available, equity, leverage, nil
+	}
+
+	account, err := a.exchange.GetAccount(ctx)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	// 优先使用当前交易对持仓上的杠杆，其次使用账户级别杠杆
+	leverage := account.AccountLeverage
+	for _, pos := range account.Positions {
+		if pos.Symbol == symbol && pos.Leverage > 0 {
+			leverage = pos.Leverage
+			break
+		}
+	}
+
+	// 保证金余额含未实现盈亏，交易所未提供时使用钱包余额
+	equity := account.TotalMarginBalance
+	if equity <= 0 {
+		equity = account.TotalWalletBalance
+	}
+	return account.AvailableBalance, equity, leverage, nil
+}
+
+// exchangeExecutorAdapter 适配器，将 order.ExchangeOrderExecutor 转换为 position.OrderExecutorInterface
+type exchangeExecutorAdapter struct {
+	executor *order.ExchangeOrderExecutor
+}
+
+func (a *exchangeExecutorAdapter) PlaceOrder(req *position.OrderRequest) (*position.Order, error) {
+	orderReq := &order.OrderRequest{
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		PriceDecimals: req.PriceDecimals,
+		ReduceOnly:    req.ReduceOnly,
+		PostOnly:      req.PostOnly, // 传递 PostOnly 参数
+		Market:        req.Market,
+		ClientOrderID: req.ClientOrderID, // 传递 ClientOrderID
+		Reason:        req.Reason,
+	}
+	ord, err := a.executor.PlaceOrder(orderReq)
+	if err != nil {
+		return nil, err
+	}
+	return &position.Order{
+		OrderID:       ord.OrderID,
+		ClientOrderID: ord.ClientOrderID, // 返回 ClientOrderID
+		Symbol:        ord.Symbol,
+		Side:          ord.Side,
+		Price:         ord.Price,
+		Quantity:      ord.Quantity,
+		Status:        ord.Status,
+		CreatedAt:     ord.CreatedAt,
+	}, nil
+}
+
+func (a *exchangeExecutorAdapter) BatchPlaceOrders(orders []*position.OrderRequest) ([]*position.Order, bool) {
+	orderReqs := make([]*order.OrderRequest, len(orders))
+	for i, req := range orders {
+		orderReqs[i] = &order.OrderRequest{
+			Symbol:        req.Symbol,
+			Side:          req.Side,
+			Price:         req.Price,
+			Quantity:      req.Quantity,
+			PriceDecimals: req.PriceDecimals,
+			ReduceOnly:    req.ReduceOnly,
+			PostOnly:      req.PostOnly, // 传递 PostOnly 参数
+			Market:        req.Market,
+			ClientOrderID: req.ClientOrderID, // 传递 ClientOrderID
+			Reason:        req.Reason,
+		}
+	}
+	ords, marginError := a.executor.BatchPlaceOrders(orderReqs)
+	result := make([]*position.Order, len(ords))
+	for i, ord := range ords {
+		result[i] = &position.Order{
+			OrderID:       ord.OrderID,
+			ClientOrderID: ord.ClientOrderID, // 返回 ClientOrderID
+			Symbol:        ord.Symbol,
+			Side:          ord.Side,
+			Price:         ord.Price,
+			Quantity:      ord.Quantity,
+			Status:        ord.Status,
+			CreatedAt:     ord.CreatedAt,
+		}
+	}
+	return result, marginError
+}
+
+func (a *exchangeExecutorAdapter) BatchCancelOrders(orderIDs []int64, reason string) error {
+	return a.executor.BatchCancelOrders(orderIDs, reason)
+}
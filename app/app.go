@@ -0,0 +1,498 @@
+// Package app 做市系统的启动编排
+// 组件的创建和启动顺序决定了启动期间的成交推送是否会丢失（先启动订单流，再初始化仓位管理器并挂单），
+// 因此集中在 Run 中完成；交易所、退出信号和标准输入通过 Deps 注入，便于在模拟交易所上端到端运行
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"opensqt/admin"
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/exchange"
+	"opensqt/logger"
+	"opensqt/notify"
+	"opensqt/order"
+	"opensqt/safety"
+	"opensqt/utils"
+)
+
+// Deps 运行依赖（零值字段使用默认实现）
+type Deps struct {
+	// Exchange 交易所实例（nil 时按 app.current_exchange 创建）
+	Exchange exchange.IExchange
+	// Stop 关闭后开始优雅退出（nil 时监听 SIGINT/SIGTERM）
+	Stop <-chan struct{}
+	// Stdin 终端快捷键的输入（nil 时使用 os.Stdin，仅 system.interactive 启用时读取）
+	Stdin *os.File
+	// Reload 重新加载后的配置（nil 时不支持热重载），交易参数在运行中生效，见 watchConfigReload
+	Reload <-chan *config.Config
+	// Notifier 告警推送（nil 时不推送）：风控触发/解除、止盈止损、下单失败和优雅关闭
+	Notifier notify.INotifier
+}
+
+// Run 按顺序创建并启动所有组件，阻塞直到收到退出信号、终端快捷键退出或止盈/止损退出
+// 多交易对（trading.symbols）时每个交易对运行独立的价格流、仓位管理器、对账器和订单清理器（见 runSymbol），
+// 订单流共用一条交易所连接并按交易对分发，资金分配、止盈止损和外部资金变动监控按整个账户统计
+// 启动阶段失败时返回错误（调用方负责退出进程），正常退出返回 nil
+func Run(cfg *config.Config, deps Deps) error {
+	symbols := cfg.Trading.Symbols
+	if len(symbols) == 0 {
+		symbols = []string{cfg.Trading.Symbol}
+	}
+	multiSymbol := len(symbols) > 1
+	if multiSymbol && deps.Exchange != nil {
+		return fmt.Errorf("多交易对模式不支持注入交易所实例（每个交易对需要独立的交易所实例）")
+	}
+
+	// 2. 创建交易所实例（使用工厂模式）
+	// 适配器的精度、合约面值绑定在交易对上，多交易对时每个交易对一个实例（共用同一组密钥），
+	// 订单流只在主交易对的实例上建立一条连接，订单更新按交易对分发给各自的仓位管理器（见 exchange.ShareOrderStream）
+	symbolCfgs := make([]*config.Config, len(symbols))
+	exchanges := make([]exchange.IExchange, len(symbols))
+	for i, symbol := range symbols {
+		symbolCfg, err := cfg.ForSymbol(symbol)
+		if err != nil {
+			return err
+		}
+		symbolCfgs[i] = symbolCfg
+		if exchanges[i] = deps.Exchange; exchanges[i] == nil {
+			if exchanges[i], err = exchange.NewExchange(symbolCfg); err != nil {
+				return fmt.Errorf("创建交易所实例失败: %w", err)
+			}
+		}
+	}
+	if multiSymbol {
+		var shared bool
+		if exchanges, shared = exchange.ShareOrderStream(exchanges, symbols); !shared {
+			logger.Warn("⚠️ [多交易对] %s 不支持在一条连接上订阅多个交易对的订单流，每个交易对使用独立的订单流连接", exchanges[0].GetName())
+		}
+	}
+	ex := exchanges[0] // 主交易对的实例，用于账户级查询
+	logger.Info("✅ 使用交易所: %s", ex.GetName())
+	if err := exchange.ValidateCredentials(context.Background(), ex); err != nil {
+		return err
+	}
+
+	// 资金分配：以分配金额作为各交易对的可用余额，合计不能超过账户余额
+	requested := make(map[string]float64, len(symbols))
+	for i, symbol := range symbols {
+		requested[symbol] = symbolCfgs[i].Trading.CapitalAllocation
+		if multiSymbol && requested[symbol] == 0 {
+			logger.Warn("⚠️ [多交易对] %s 未设置 capital_allocation，可使用账户全部余额，可能挤占其他交易对的资金", symbol)
+		}
+	}
+	allocations, err := safety.ResolveCapitalAllocations(ex, requested)
+	if err != nil {
+		return fmt.Errorf("资金分配检查失败: %w", err)
+	}
+
+	// 下单限流和主动风控按账户共用（限流的 per_symbol 桶按交易对区分）
+	rateLimits := cfg.Timing.RateLimits
+	rateLimiter := order.NewRateLimiter(map[string]order.BucketLimit{
+		order.BucketOrder: {Rate: rateLimits.Order.Rate, Burst: rateLimits.Order.Burst},
+		order.BucketQuery: {Rate: rateLimits.Query.Rate, Burst: rateLimits.Query.Burst},
+	}, order.BucketLimit{Rate: rateLimits.PerSymbol.Rate, Burst: rateLimits.PerSymbol.Burst})
+
+	// === 新增：初始化风控监视器 ===
+	// 风控监控市场整体行情，traded_symbol_weight 按主交易对 symbol 计算
+	riskMonitor := safety.NewRiskMonitor(cfg, ex)
+	riskMonitor.SetNotifier(deps.Notifier)
+
+	// 止盈/止损按真实账户余额判断：模拟交易（system.dry_run）的盈亏不体现在真实账户中，
+	// 真实账户的余额变化反而会触发模拟盘退出，因此模拟交易时不启用
+	takeProfitEnabled := cfg.Trading.TakeProfit.Enabled && !cfg.System.DryRun
+	stopLossEnabled := cfg.Trading.StopLoss.Enabled && !cfg.System.DryRun
+	if cfg.System.DryRun && (cfg.Trading.TakeProfit.Enabled || cfg.Trading.StopLoss.Enabled) {
+		logger.Warn("⚠️ [模拟交易] 止盈/止损按真实账户余额判断，模拟交易时不启用（take_profit / stop_loss 配置被忽略）")
+	}
+
+	// === 新增：创建止盈监控器 ===
+	takeProfitMonitor := safety.NewTakeProfitMonitor(cfg, ex)
+	// 监控类轮询按下单限流桶的压力自适应调整间隔（timing.adaptive_polling 启用时生效）
+	takeProfitMonitor.SetPressureSource(rateLimiter)
+	takeProfitMonitor.SetNotifier(deps.Notifier)
+	// 止损监控器（stop_loss 启用时生效）
+	stopLossMonitor := safety.NewStopLossMonitor(cfg, ex)
+	stopLossMonitor.SetPressureSource(rateLimiter)
+	stopLossMonitor.SetNotifier(deps.Notifier)
+	// 多交易对时按各交易对的实例查询持仓和成交（止盈 marked 估值、外部资金监控）
+	symbolExchanges := make(map[string]exchange.IExchange, len(symbols))
+	for i, symbol := range symbols {
+		symbolExchanges[symbol] = exchanges[i]
+	}
+	if multiSymbol {
+		takeProfitMonitor.SetSymbolExchanges(symbolExchanges)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// 订单审计记录（system.order_audit_file 设置时生效，需在首次挂单前订阅；多交易对共用一个文件，记录中包含交易对）
+	startOrderAudit(ctx, cfg.System.OrderAuditFile)
+
+	// === 新增：设置初始余额（第一笔交易前） ===
+	if takeProfitEnabled {
+		logger.Info("💰 [止盈初始化] 正在记录初始余额...")
+		if err := takeProfitMonitor.SetInitialBalance(ctx); err != nil {
+			return fmt.Errorf("设置初始余额失败: %w", err)
+		}
+	}
+	if stopLossEnabled {
+		logger.Info("💰 [止损初始化] 正在记录初始余额...")
+		if err := stopLossMonitor.SetInitialBalance(ctx); err != nil {
+			return fmt.Errorf("设置止损初始余额失败: %w", err)
+		}
+	}
+
+	// 依次启动各交易对（后一个交易对启动失败时停止之前已启动的交易对）
+	shared := sharedComponents{
+		rateLimiter: rateLimiter,
+		riskMonitor: riskMonitor,
+		allocations: allocations,
+		crashes:     &crashDumpers{},
+		notifier:    deps.Notifier,
+	}
+	runs := make([]*symbolRun, 0, len(symbols))
+	for i, symbol := range symbols {
+		if multiSymbol {
+			logger.Info("📈 [多交易对] 启动交易对 %s (%d/%d)", symbol, i+1, len(symbols))
+		}
+		run, err := runSymbol(ctx, symbolCfgs[i], exchanges[i], symbol, shared)
+		if err != nil {
+			for _, started := range runs {
+				started.stop()
+			}
+			if multiSymbol {
+				return fmt.Errorf("交易对 %s: %w", symbol, err)
+			}
+			return err
+		}
+		runs = append(runs, run)
+	}
+	defer runs[0].crash.Recover("主流程")
+
+	// 启动前的历史成交盈亏计入止盈基准（各交易对合计）
+	if takeProfitEnabled {
+		historical, loaded := 0.0, false
+		for _, run := range runs {
+			if run.tradeHistory != nil {
+				historical += run.tradeHistory.NetPnL()
+				loaded = true
+			}
+		}
+		if loaded {
+			takeProfitMonitor.ApplyHistoricalPnL(historical)
+		}
+	}
+
+	// 启动外部资金变动监控（需在记录止盈初始余额之后启动）
+	// 未启用的止盈/止损不调整基准
+	var rebaselineTakeProfit *safety.TakeProfitMonitor
+	if takeProfitEnabled {
+		rebaselineTakeProfit = takeProfitMonitor
+	}
+	externalBalanceMonitor := safety.NewExternalBalanceMonitor(cfg, ex, rebaselineTakeProfit)
+	externalBalanceMonitor.SetPressureSource(rateLimiter)
+	if stopLossEnabled {
+		externalBalanceMonitor.SetStopLoss(stopLossMonitor)
+	}
+	if multiSymbol {
+		externalBalanceMonitor.SetSymbolExchanges(symbolExchanges)
+	}
+	go externalBalanceMonitor.Start(ctx)
+
+	// 启动风控监控
+	go riskMonitor.Start(ctx)
+
+	// 配置热重载：价格间隔、订单金额、窗口大小和最小订单价值在运行中生效
+	if deps.Reload != nil {
+		go watchConfigReload(ctx, cfg, deps.Reload, runs)
+	}
+
+	// === 紧急平仓：撤单 + 市价平仓，进程保持运行并暂停挂单，等待手动恢复 ===
+	emergencyFlatten := func(source string) {
+		for _, run := range runs {
+			run.flatten(source)
+		}
+	}
+	resumeTrading := func(source string) {
+		for _, run := range runs {
+			run.resume(source)
+		}
+	}
+
+	if cfg.System.EmergencyFlatten && utils.FlattenSignal != nil {
+		controlChan := make(chan os.Signal, 1)
+		signal.Notify(controlChan, utils.FlattenSignal, utils.ResumeSignal)
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case sig := <-controlChan:
+					if sig == utils.FlattenSignal {
+						emergencyFlatten("信号 SIGUSR1")
+					} else {
+						resumeTrading("信号 SIGUSR2")
+					}
+				}
+			}
+		}()
+		logger.Info("🆘 紧急平仓已启用: kill -USR1 %d 撤单并平仓，kill -USR2 %d 恢复交易", os.Getpid(), os.Getpid())
+	}
+
+	// 终端快捷键：q 紧急平仓并退出，p 暂停/恢复挂单
+	quitChan := make(chan struct{})
+	if cfg.System.Interactive {
+		stdin := deps.Stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		if !utils.IsTerminal(stdin) {
+			logger.Info("ℹ️ [终端交互] 标准输入不是终端，快捷键已关闭")
+		} else {
+			go func() {
+				scanner := bufio.NewScanner(stdin)
+				for scanner.Scan() {
+					switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+					case "q":
+						emergencyFlatten("终端快捷键 q")
+						close(quitChan)
+						return
+					case "p":
+						paused := !runs[0].manualPaused.Load()
+						for _, run := range runs {
+							run.manualPaused.Store(paused)
+						}
+						if paused {
+							logger.Warn("⏸️ [终端交互] 已暂停挂单（保留现有订单），再次输入 p 回车恢复")
+						} else {
+							logger.Info("▶️ [终端交互] 已恢复挂单")
+						}
+					}
+				}
+			}()
+			logger.Info("⌨️ [终端交互] 已启用: 输入 q 回车 撤单、市价平仓并退出；输入 p 回车 暂停/恢复挂单")
+		}
+	}
+
+	// Prometheus 指标接口（system.metrics_addr 设置时启动）
+	startMetrics(ctx, cfg, runs, takeProfitMonitor, riskMonitor)
+
+	// 本地控制接口（system.control_socket 设置时启动）：暂停/恢复挂单、查询状态
+	startControl(ctx, cfg.System.ControlSocket, runs)
+
+	// 启动管理接口（状态查询 + SSE 事件推送，配置校验保证只有一个交易对）
+	if cfg.Admin.Enabled {
+		adminServer := admin.NewServer(cfg, func() interface{} {
+			return runs[0].status()
+		})
+		controls := admin.Controls{
+			Flatten: func() { emergencyFlatten("管理接口请求") },
+			Resume:  func() { resumeTrading("管理接口请求") },
+		}
+		if cfg.System.OrderMapFile != "" {
+			controls.ExportOrderMap = runs[0].spm.ExportOrderMapping
+		}
+		adminServer.SetControls(controls)
+		if err := adminServer.Start(ctx); err != nil {
+			logger.Error("❌ %v", err)
+		}
+	}
+
+	// 自动退出（止盈/止损共用同一退出流程）：撤单、处理持仓后停止所有组件，不再走下面的常规退出流程
+	// 止盈与止损同时触发时只执行先到的一个
+	stopAll := func() {
+		cancel()
+		for _, run := range runs {
+			run.stop()
+		}
+		riskMonitor.Stop()
+	}
+	var autoExiting atomic.Bool
+	autoExitDone := make(chan struct{})
+	startAutoExit := func(exit autoExit) {
+		if !autoExiting.CompareAndSwap(false, true) {
+			return
+		}
+		runAutoExit(runs, exit, stopAll)
+		close(autoExitDone)
+	}
+
+	// === 新增：启动止盈监控 ===
+	// 止盈退出挂交易所原生追踪止损保护持仓，交易所不支持或下单失败时市价平仓
+	if takeProfitEnabled {
+		go takeProfitMonitor.Start(ctx, func() {
+			startAutoExit(autoExit{
+				name:   "止盈",
+				reason: "take_profit",
+				closePositions: func(run *symbolRun) {
+					if cfg.Trading.TakeProfit.UseNativeTrailing {
+						placed, err := placeTrailingStopExit(run.ex, run.symbol, cfg.Trading.TakeProfit.TrailingCallbackPct)
+						switch {
+						case err != nil:
+							logger.Error("❌ [止盈退出] 挂追踪止损失败，改为市价平仓: %v", err)
+						case !placed:
+							logger.Warn("⚠️ [止盈退出] %s 不支持原生追踪止损，改为市价平仓", run.ex.GetName())
+						default:
+							return
+						}
+					}
+					run.closePositions("止盈退出")
+				},
+				printStats: func() {
+					initialBalance, currentBalance, profit := takeProfitMonitor.GetCurrentProfit()
+					logger.Info("📊 [止盈统计] ===")
+					logger.Info("📊 [止盈统计] 初始余额: %.2f USDT", initialBalance)
+					logger.Info("📊 [止盈统计] 最终余额: %.2f USDT", currentBalance)
+					logger.Info("📊 [止盈统计] 总盈利: %.2f USDT", profit)
+					logger.Info("📊 [止盈统计] 盈利率: %.2f%%", (profit/initialBalance)*100)
+					logger.Info("📊 [止盈统计] ===")
+				},
+				finalMessage: "已停止交易，请手动重启程序",
+			})
+		})
+	}
+
+	// === 启动止损监控 ===
+	// 止损退出撤单后直接市价平仓（不挂追踪止损）
+	if stopLossEnabled {
+		go stopLossMonitor.Start(ctx, func() {
+			startAutoExit(autoExit{
+				name:   "止损",
+				reason: "stop_loss",
+				closePositions: func(run *symbolRun) {
+					run.closePositions("止损退出")
+				},
+				printStats: func() {
+					initialBalance, currentBalance, loss := stopLossMonitor.GetCurrentLoss()
+					logger.Info("📊 [止损统计] ===")
+					logger.Info("📊 [止损统计] 初始余额: %.2f USDT", initialBalance)
+					logger.Info("📊 [止损统计] 最终余额: %.2f USDT", currentBalance)
+					logger.Info("📊 [止损统计] 总亏损: %.2f USDT（止损线 %.2f USDT）", loss, stopLossMonitor.MaxLoss())
+					logger.Info("📊 [止损统计] ===")
+				},
+				finalMessage: "已停止交易，请检查行情和参数后手动重启程序",
+			})
+		})
+	}
+
+	// 13. 定期打印持仓和订单状态
+	go func() {
+		statusInterval := time.Duration(cfg.Timing.StatusPrintInterval) * time.Minute
+		ticker := time.NewTicker(statusInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// 风控触发时不打印状态
+				if !riskMonitor.IsTriggered() {
+					for _, run := range runs {
+						run.spm.PrintPositions()
+					}
+				}
+
+				// === 新增：打印止盈状态 ===
+				if takeProfitEnabled {
+					initialBalance, currentBalance, profit := takeProfitMonitor.GetCurrentProfit()
+					// target_pct 模式下目标按初始余额换算为金额显示
+					logger.Info("📊 [止盈监控] 初始: %.2f USDT, 当前: %.2f USDT, 盈利: %.2f USDT (%.1f%%), 目标: %.2f USDT",
+						initialBalance, currentBalance, profit, (profit/initialBalance)*100, takeProfitMonitor.GetTargetProfit())
+				}
+				if stopLossEnabled {
+					initialBalance, currentBalance, loss := stopLossMonitor.GetCurrentLoss()
+					logger.Info("📊 [止损监控] 初始: %.2f USDT, 当前: %.2f USDT, 亏损: %.2f USDT (止损线 %.2f USDT)",
+						initialBalance, currentBalance, loss, stopLossMonitor.MaxLoss())
+				}
+			}
+		}
+	}()
+
+	// 14. 等待退出信号
+	stop := deps.Stop
+	if stop == nil {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(sigChan)
+		sigStop := make(chan struct{})
+		go func() {
+			select {
+			case <-sigChan:
+				close(sigStop)
+			case <-ctx.Done():
+			}
+		}()
+		stop = sigStop
+	}
+	shutdownReason := "signal"
+	select {
+	case <-stop:
+	case <-quitChan:
+		shutdownReason = "quit"
+	case <-autoExitDone:
+		return nil
+	}
+
+	logger.Info("🛑 收到退出信号，开始优雅关闭...")
+	event.Publish(event.TypeShutdown, event.Shutdown{Reason: shutdownReason})
+	notify.Send(deps.Notifier, notify.Event{
+		Type:    notify.EventShutdown,
+		Symbol:  strings.Join(symbols, ","),
+		Message: "🛑 收到退出信号，开始优雅关闭",
+		Fields:  map[string]interface{}{"reason": shutdownReason},
+	})
+
+	// 撤单前先排空：进行中的下单在撤单之后才返回会在交易所留下挂单
+	for _, run := range runs {
+		run.drain()
+	}
+
+	// 🔥 第一优先级：立即撤销所有订单（最重要！）
+	// 使用独立的超时 context，确保撤单请求能发送成功
+	if cfg.System.CancelOnExit {
+		logger.Info("🔄 正在撤销所有订单（最高优先级）...")
+		cancelCtx, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Second)
+		for _, run := range runs {
+			if err := cancelAllOrders(cancelCtx, run.ex, run.executor, run.symbol, event.OrderReasonExit); err != nil {
+				logger.Error("❌ 撤销 %s 订单失败: %v", run.symbol, err)
+			} else {
+				logger.Info("✅ %s 所有订单已成功撤销", run.symbol)
+			}
+		}
+		cancelTimeout()
+	}
+
+	// 🔥 第二优先级：停止所有协程（取消 context）
+	// 这会通知所有使用 ctx 的协程停止工作
+	cancel()
+
+	// 🔥 第三优先级：优雅停止各个组件
+	// 注意：这些组件的 Stop() 方法内部会处理 WebSocket 关闭等清理工作
+	logger.Info("⏹️ 正在停止价格监控和订单流...")
+	for _, run := range runs {
+		run.stop()
+	}
+
+	logger.Info("⏹️ 正在停止风控监视器...")
+	riskMonitor.Stop()
+
+	// 等待一小段时间，让协程完成清理（避免强制退出导致日志丢失）
+	time.Sleep(500 * time.Millisecond)
+
+	// 打印最终状态
+	for _, run := range runs {
+		run.spm.PrintPositions()
+		run.report.Write("signal")
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package app
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"opensqt/config"
+	"opensqt/exchange"
+	"opensqt/exchange/mock"
+	"opensqt/utils"
+)
+
+// startupFillExchange 在启动订单流时让上次运行遗留的买单成交（模拟初始化期间到达的成交）
+// fillBeforeStream 为 true 时在订阅订单流之前成交（推送丢失，只能从持仓恢复），否则在订阅之后、仓位管理器初始化之前成交
+type startupFillExchange struct {
+	exchange.IExchange
+	server           *mock.Server
+	fillPrice        float64
+	fillBeforeStream bool
+}
+
+func (e *startupFillExchange) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	if e.fillBeforeStream {
+		e.server.SetPrice(e.fillPrice)
+		return e.IExchange.StartOrderStream(ctx, callback)
+	}
+	if err := e.IExchange.StartOrderStream(ctx, callback); err != nil {
+		return err
+	}
+	e.server.SetPrice(e.fillPrice)
+	return nil
+}
+
+func (e *startupFillExchange) Unwrap() exchange.IExchange { return e.IExchange }
+
+// testRunConfig 使用外部模拟交易所服务的最小运行配置
+func testRunConfig(t *testing.T, baseURL string) *config.Config {
+	t.Helper()
+	yaml := `
+app:
+  current_exchange: "mock"
+exchanges:
+  mock:
+    base_url: "` + baseURL + `"
+    fee_rate: 0.0002
+trading:
+  symbol: "ETHUSDT"
+  price_interval: 5
+  order_quantity: 30
+  buy_window_size: 3
+  sell_window_size: 3
+  reconcile_interval: 3600
+  order_cleanup_threshold: 100
+  cleanup_batch_size: 10
+  margin_lock_duration_seconds: 10
+  position_safety_check: 100
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	return cfg
+}
+
+// TestRunKeepsFillDuringStartup 启动期间成交的遗留买单既不能丢失也不能重复计入：
+// 仓位管理器应为成交的持仓挂出数量相同的卖单
+func TestRunKeepsFillDuringStartup(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		fillBeforeStream bool
+	}{
+		{"订阅订单流之前成交", true},
+		{"订阅订单流之后初始化之前成交", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			serverCfg := mock.DefaultServerConfig("ETHUSDT")
+			serverCfg.TickInterval = 0 // 价格只由测试驱动
+			server := mock.NewServer(serverCfg)
+			if err := server.Start(""); err != nil {
+				t.Fatal(err)
+			}
+			defer server.Stop()
+
+			// 上次运行遗留的网格买单（cancel_on_start 未启用，启动时保留）
+			cfg := testRunConfig(t, server.URL())
+			ex, err := exchange.NewExchange(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			leftover, err := ex.PlaceOrder(context.Background(), &exchange.OrderRequest{
+				Symbol: "ETHUSDT", Side: exchange.SideBuy, Type: exchange.OrderTypeLimit, TimeInForce: exchange.TimeInForceGTC,
+				Price: 2995, Quantity: 0.01, PriceDecimals: 2, PostOnly: true,
+				ClientOrderID: utils.GenerateOrderID(2995, "BUY", 2),
+			})
+			if err != nil {
+				t.Fatalf("下遗留买单失败: %v", err)
+			}
+
+			stop := make(chan struct{})
+			done := make(chan error, 1)
+			wrapped := &startupFillExchange{IExchange: ex, server: server, fillPrice: 2994, fillBeforeStream: tt.fillBeforeStream}
+			go func() { done <- Run(cfg, Deps{Exchange: wrapped, Stop: stop}) }()
+
+			// 等待仓位管理器为成交的持仓挂出卖单，并确认卖单数量稳定在持仓数量（不重复计入）
+			var position, sellQty float64
+			deadline := time.Now().Add(10 * time.Second)
+			for time.Now().Before(deadline) {
+				position, sellQty = accountState(t, ex)
+				if position > 0 && math.Abs(sellQty-position) < 1e-9 {
+					break
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+			time.Sleep(500 * time.Millisecond)
+			position, sellQty = accountState(t, ex)
+
+			close(stop)
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("Run 返回错误: %v", err)
+				}
+			case <-time.After(30 * time.Second):
+				t.Fatal("Run 未在退出信号后返回")
+			}
+
+			if math.Abs(position-leftover.Quantity) > 1e-9 {
+				t.Fatalf("遗留买单应已成交，持仓 %.4f", position)
+			}
+			if math.Abs(sellQty-position) > 1e-9 {
+				t.Fatalf("启动期间的成交应挂出等量卖单，持仓 %.4f，卖单合计 %.4f", position, sellQty)
+			}
+		})
+	}
+}
+
+// accountState 模拟交易所上的持仓数量和卖单挂单数量合计
+func accountState(t *testing.T, ex exchange.IExchange) (position, sellQty float64) {
+	t.Helper()
+	ctx := context.Background()
+	positions, err := ex.GetPositions(ctx, "ETHUSDT")
+	if err != nil {
+		t.Fatalf("查询持仓失败: %v", err)
+	}
+	for _, pos := range positions {
+		position += pos.Size
+	}
+	orders, err := ex.GetOpenOrders(ctx, "ETHUSDT")
+	if err != nil {
+		t.Fatalf("查询挂单失败: %v", err)
+	}
+	for _, ord := range orders {
+		if ord.Side == exchange.SideSell {
+			sellQty += ord.Quantity - ord.ExecutedQty
+		}
+	}
+	return position, sellQty
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+
+	"opensqt/control"
+	"opensqt/logger"
+)
+
+// controlStatus 控制接口 status 命令返回的交易对状态
+type controlStatus struct {
+	Symbol        string  `json:"symbol"`
+	Price         float64 `json:"price"`
+	BuyOrders     int     `json:"buy_orders"`
+	SellOrders    int     `json:"sell_orders"`
+	PositionQty   float64 `json:"position_qty"`
+	Paused        bool    `json:"paused"` // 控制接口暂停
+	RiskTriggered bool    `json:"risk_triggered"`
+	Flattened     bool    `json:"flattened"` // 紧急平仓后暂停
+}
+
+// startControl 启动本地控制接口（system.control_socket 为空时不启动）
+// pause 对所有交易对生效：价格循环在下一次价格变化时撤销买单并暂停挂单，resume 后恢复
+func startControl(ctx context.Context, path string, runs []*symbolRun) {
+	if path == "" {
+		return
+	}
+	server := control.NewServer(path, control.Controls{
+		Pause: func() bool {
+			changed := false
+			for _, run := range runs {
+				if run.controlPaused.CompareAndSwap(false, true) {
+					changed = true
+				}
+			}
+			return changed
+		},
+		Resume: func() bool {
+			changed := false
+			for _, run := range runs {
+				if run.controlPaused.CompareAndSwap(true, false) {
+					changed = true
+				}
+			}
+			return changed
+		},
+		Status: func() interface{} {
+			statuses := make([]controlStatus, 0, len(runs))
+			for _, run := range runs {
+				snapshot := run.spm.GetStatusSnapshot()
+				statuses = append(statuses, controlStatus{
+					Symbol:        run.symbol,
+					Price:         run.priceMonitor.GetLastPrice(),
+					BuyOrders:     snapshot.ActiveBuyOrders,
+					SellOrders:    snapshot.ActiveSellOrders,
+					PositionQty:   snapshot.PositionQty,
+					Paused:        run.controlPaused.Load(),
+					RiskTriggered: run.riskMonitor.IsTriggered(),
+					Flattened:     run.flattened.Load(),
+				})
+			}
+			return statuses
+		},
+	})
+	if err := server.Start(ctx); err != nil {
+		logger.Error("❌ %v", err)
+	}
+}
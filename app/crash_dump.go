@@ -0,0 +1,169 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"opensqt/exchange"
+	"opensqt/logger"
+	"opensqt/position"
+)
+
+// crashDumpTimeout 导出崩溃现场时每项查询的最长等待时间（崩溃时锁可能未释放、交易所可能不可用）
+const crashDumpTimeout = 5 * time.Second
+
+// crashReport 崩溃现场（system.crash_dump_file），致命错误或未恢复的 panic 退出前写入
+type crashReport struct {
+	Time     string `json:"time"`
+	Reason   string `json:"reason"`
+	Stack    string `json:"stack,omitempty"`
+	Exchange string `json:"exchange"`
+	Symbol   string `json:"symbol"`
+
+	Snapshot   *position.StatusSnapshot `json:"snapshot,omitempty"` // 仓位管理器快照（含网格槽位）
+	OpenOrders []*exchange.Order        `json:"open_orders,omitempty"`
+	Positions  []*exchange.Position     `json:"positions,omitempty"`
+	Account    *exchange.Account        `json:"account,omitempty"`
+	Errors     []string                 `json:"errors,omitempty"` // 各项查询失败原因
+}
+
+// crashDumpers 同一进程内各交易对的崩溃现场导出器，致命错误或 panic 时全部导出
+type crashDumpers struct {
+	mu      sync.Mutex
+	dumpers []*crashDumper
+}
+
+// crashDumper 单个交易对的崩溃现场导出器（path 为空时为 nil，所有方法对 nil 安全）
+type crashDumper struct {
+	path   string
+	ex     exchange.IExchange
+	symbol string
+	group  *crashDumpers
+
+	mu   sync.Mutex
+	spm  *position.SuperPositionManager // 创建仓位管理器之前为 nil
+	once sync.Once
+}
+
+// add 创建交易对的崩溃现场导出器（path 为空时返回 nil），第一个导出器创建时注册 logger.Fatal 退出前的回调
+func (g *crashDumpers) add(path string, ex exchange.IExchange, symbol string) *crashDumper {
+	if path == "" {
+		return nil
+	}
+	d := &crashDumper{path: path, ex: ex, symbol: symbol, group: g}
+	g.mu.Lock()
+	g.dumpers = append(g.dumpers, d)
+	first := len(g.dumpers) == 1
+	g.mu.Unlock()
+	if first {
+		logger.SetFatalHook(func(message string) {
+			g.dump("fatal: "+message, string(debug.Stack()))
+		})
+	}
+	return d
+}
+
+// dump 导出所有交易对的现场
+func (g *crashDumpers) dump(reason, stack string) {
+	g.mu.Lock()
+	dumpers := append([]*crashDumper(nil), g.dumpers...)
+	g.mu.Unlock()
+	for _, d := range dumpers {
+		d.dump(reason, stack)
+	}
+}
+
+// SetPositionManager 关联仓位管理器，之后的崩溃现场包含其快照
+func (d *crashDumper) SetPositionManager(spm *position.SuperPositionManager) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.spm = spm
+	d.mu.Unlock()
+}
+
+// Recover 在协程顶层 defer 调用：捕获 panic 导出现场后继续 panic（保持原有的崩溃退出行为）
+func (d *crashDumper) Recover(where string) {
+	if d == nil {
+		return
+	}
+	if r := recover(); r != nil {
+		d.group.dump(fmt.Sprintf("panic in %s: %v", where, r), string(debug.Stack()))
+		panic(r)
+	}
+}
+
+// dump 收集现场并写入文件（只写一次：panic 之后的 Fatal 不覆盖最初的现场）
+func (d *crashDumper) dump(reason, stack string) {
+	d.once.Do(func() {
+		report := crashReport{
+			Time:     time.Now().Format("2006-01-02T15:04:05.000Z07:00"),
+			Reason:   reason,
+			Stack:    stack,
+			Exchange: d.ex.GetName(),
+			Symbol:   d.symbol,
+		}
+		d.collect(&report)
+
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			logger.Error("❌ [崩溃现场] 序列化失败: %v", err)
+			return
+		}
+		if dir := filepath.Dir(d.path); dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				logger.Error("❌ [崩溃现场] 创建目录失败: %v", err)
+				return
+			}
+		}
+		if err := os.WriteFile(d.path, append(data, '\n'), 0644); err != nil {
+			logger.Error("❌ [崩溃现场] 写入文件失败: %v", err)
+			return
+		}
+		logger.Error("🧾 [崩溃现场] 仓位快照、挂单和账户信息已写入 %s", d.path)
+	})
+}
+
+// collect 收集仓位快照和交易所状态（快照与交易所查询分别限时），失败或超时记入 Errors
+func (d *crashDumper) collect(report *crashReport) {
+	d.mu.Lock()
+	spm := d.spm
+	d.mu.Unlock()
+
+	if spm != nil {
+		// 崩溃时仓位管理器的锁可能未释放，超时则放弃快照
+		done := make(chan position.StatusSnapshot, 1)
+		go func() { done <- spm.GetStatusSnapshot() }()
+		select {
+		case snapshot := <-done:
+			report.Snapshot = &snapshot
+		case <-time.After(crashDumpTimeout):
+			report.Errors = append(report.Errors, "仓位快照: 等待超时（锁可能未释放）")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), crashDumpTimeout)
+	defer cancel()
+	if orders, err := d.ex.GetOpenOrders(ctx, d.symbol); err != nil {
+		report.Errors = append(report.Errors, "挂单: "+err.Error())
+	} else {
+		report.OpenOrders = orders
+	}
+	if positions, err := d.ex.GetPositions(ctx, d.symbol); err != nil {
+		report.Errors = append(report.Errors, "持仓: "+err.Error())
+	} else {
+		report.Positions = positions
+	}
+	if account, err := d.ex.GetAccount(ctx); err != nil {
+		report.Errors = append(report.Errors, "账户: "+err.Error())
+	} else {
+		report.Account = account
+	}
+}
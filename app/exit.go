@@ -0,0 +1,158 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"opensqt/event"
+	"opensqt/exchange"
+	"opensqt/logger"
+	"opensqt/order"
+)
+
+// drainPlacements 退出撤单前停止接受新的下单请求，并等待进行中的下单返回（最多等待 timeout）
+func drainPlacements(executor *order.ExchangeOrderExecutor, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	logger.Info("⏳ 停止接受新的下单请求，等待进行中的下单返回...")
+	if err := executor.Drain(ctx); err != nil {
+		logger.Warn("⚠️ 等待进行中的下单超时 (%v)，撤单后仍可能残留未返回的下单", timeout)
+	}
+}
+
+// batchCanceller 交易所不支持一键撤单时分批撤单的执行器（*order.ExchangeOrderExecutor）
+type batchCanceller interface {
+	IsDryRun() bool
+	CancelAllViaBatch(ctx context.Context, reason string) error
+}
+
+// cancelAllOrders 撤销交易对的所有订单，并以 event.TypeOrderAction 记录撤单原因
+// 交易所不支持一键撤销全部订单时改由 executor 分批撤销（executor 为 nil 时直接返回错误）；模拟交易时只撤销模拟挂单
+func cancelAllOrders(ctx context.Context, ex exchange.IExchange, executor batchCanceller, symbol, reason string) error {
+	if executor != nil && executor.IsDryRun() {
+		return executor.CancelAllViaBatch(ctx, reason)
+	}
+	err := ex.CancelAllOrders(ctx, symbol)
+	if exchange.IsUnsupportedError(err) && executor != nil {
+		logger.Warn("⚠️ %s 不支持撤销全部订单（%v），改为分批撤单", ex.GetName(), err)
+		return executor.CancelAllViaBatch(ctx, reason) // 每个订单的撤单结果由执行器发布
+	}
+	action := event.OrderAction{Symbol: symbol, Action: "cancel_all", Reason: reason}
+	if err != nil {
+		action.Error = err.Error()
+	}
+	event.Publish(event.TypeOrderAction, action)
+	return err
+}
+
+// autoExit 止盈/止损触发后的退出流程参数
+type autoExit struct {
+	name           string               // 触发来源（止盈 / 止损），用作日志前缀
+	reason         string               // 运行汇总的退出原因（take_profit / stop_loss）
+	closePositions func(run *symbolRun) // 撤单后处理交易对的持仓（市价平仓或挂追踪止损）
+	printStats     func()               // 打印最终盈亏统计
+	finalMessage   string               // 退出完成后的提示
+}
+
+// runAutoExit 执行自动退出：排空进行中的下单 → 撤销所有订单 → 处理持仓 → 停止所有组件 → 打印统计并写入运行汇总
+func runAutoExit(runs []*symbolRun, exit autoExit, stopAll func()) {
+	logger.Warn("🚨 [%s触发] 检测到%s信号，开始安全退出...", exit.name, exit.name)
+	tag := exit.name + "退出"
+
+	// 1. 等待进行中的下单返回后撤销所有订单
+	for _, run := range runs {
+		run.drain()
+	}
+	cancelCtx, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelTimeout()
+	for _, run := range runs {
+		run.cancelOrders(cancelCtx, tag)
+	}
+
+	// 2. 处理持仓
+	for _, run := range runs {
+		exit.closePositions(run)
+	}
+
+	// 3. 停止所有组件
+	stopAll()
+
+	// 4. 打印最终状态
+	exit.printStats()
+	for _, run := range runs {
+		run.spm.PrintPositions()
+		run.report.Write(exit.reason)
+	}
+	logger.Info("✅ [%s] %s", tag, exit.finalMessage)
+}
+
+// closeAllPositionsMarket 市价平仓所有持仓（止盈退出时使用）
+func closeAllPositionsMarket(ex exchange.IExchange, symbol string) error {
+	ctx := context.Background()
+	positions, err := ex.GetPositions(ctx, symbol)
+	if err != nil || len(positions) == 0 {
+		logger.Info("📊 [市价平仓] 无持仓需要平仓")
+		return nil
+	}
+
+	logger.Info("📊 [市价平仓] 开始市价平仓 %d 个持仓", len(positions))
+
+	for _, pos := range positions {
+		if pos.Size > 0 {
+			orderReq := &exchange.OrderRequest{
+				Symbol:      symbol,
+				Side:        exchange.SideSell,
+				Type:        exchange.OrderTypeMarket,
+				TimeInForce: exchange.TimeInForceIOC,
+				Quantity:    pos.Size,
+				ReduceOnly:  true,
+			}
+
+			order, err := ex.PlaceOrder(ctx, orderReq)
+			recordExitOrder(symbol, pos.Size, order, err)
+			if err != nil {
+				logger.Error("❌ [市价平仓] 平仓失败: %v", err)
+				continue
+			}
+			logger.Info("✅ [市价平仓] 已下市价平仓单: ID=%d, 数量=%.4f", order.OrderID, order.Quantity)
+		}
+	}
+
+	time.Sleep(2 * time.Second)
+	return nil
+}
+
+// placeTrailingStopExit 为多头持仓挂只减仓的交易所原生追踪止损（take_profit.use_native_trailing）
+// 返回 false 表示交易所不支持追踪止损或下单失败，调用方应回退为市价平仓
+func placeTrailingStopExit(ex exchange.IExchange, symbol string, callbackRate float64) (bool, error) {
+	ctx := context.Background()
+	positions, err := ex.GetPositions(ctx, symbol)
+	if err != nil {
+		return false, err
+	}
+
+	placed := 0
+	for _, pos := range positions {
+		if pos.Size <= 0 {
+			continue
+		}
+		order, ok, err := exchange.PlaceTrailingStop(ctx, ex, &exchange.TrailingStopRequest{
+			Symbol:       symbol,
+			Side:         exchange.SideSell,
+			Quantity:     pos.Size,
+			CallbackRate: callbackRate,
+		})
+		if ok {
+			recordExitOrder(symbol, pos.Size, order, err)
+		}
+		if !ok || err != nil {
+			return false, err
+		}
+		placed++
+		logger.Info("✅ [追踪止损] 已挂交易所追踪止损: ID=%d, 数量=%.4f, 回撤 %.2f%%，程序退出后继续保护持仓", order.OrderID, pos.Size, callbackRate)
+	}
+	if placed == 0 {
+		logger.Info("📊 [追踪止损] 无持仓需要保护")
+	}
+	return true, nil
+}
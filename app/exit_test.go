@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"opensqt/event"
+	"opensqt/exchange"
+)
+
+// cancelExchange 测试用交易所：cancelAllErr 为一键撤单的返回值，成功时清空挂单
+type cancelExchange struct {
+	exchange.IExchange
+
+	mu             sync.Mutex
+	open           []int64
+	cancelAllErr   error
+	cancelAllCalls int
+}
+
+func (f *cancelExchange) GetName() string { return "Fake" }
+
+func (f *cancelExchange) CancelAllOrders(ctx context.Context, symbol string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cancelAllCalls++
+	if f.cancelAllErr == nil {
+		f.open = nil
+	}
+	return f.cancelAllErr
+}
+
+// fakeCanceller 测试用执行器：分批撤销交易所上的挂单并记录撤销的订单ID
+type fakeCanceller struct {
+	ex       *cancelExchange
+	dryRun   bool
+	canceled []int64
+	reasons  []string
+}
+
+func (f *fakeCanceller) IsDryRun() bool { return f.dryRun }
+
+func (f *fakeCanceller) CancelAllViaBatch(ctx context.Context, reason string) error {
+	f.reasons = append(f.reasons, reason)
+	if f.dryRun {
+		return nil // 模拟交易只撤销模拟挂单，不碰交易所
+	}
+	f.ex.mu.Lock()
+	defer f.ex.mu.Unlock()
+	f.canceled = append(f.canceled, f.ex.open...)
+	f.ex.open = nil
+	return nil
+}
+
+func TestCancelAllOrders(t *testing.T) {
+	unsupported := fmt.Errorf("Fake 不支持撤销全部订单: %w", errors.ErrUnsupported)
+	rejected := errors.New("code=-1021, msg=Timestamp outside of recvWindow")
+
+	tests := []struct {
+		name         string
+		cancelAllErr error
+		dryRun       bool
+		wantErr      error
+		wantCalls    int     // 一键撤单请求次数
+		wantCanceled []int64 // 执行器分批撤销的订单
+		wantOpen     int
+	}{
+		{"支持一键撤单", nil, false, nil, 1, nil, 0},
+		{"不支持一键撤单时分批撤销", unsupported, false, nil, 1, []int64{1, 2, 3}, 0},
+		{"一键撤单失败时返回错误，不分批撤销", rejected, false, rejected, 1, nil, 3},
+		{"模拟交易不向交易所撤单", nil, true, nil, 0, nil, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := &cancelExchange{open: []int64{1, 2, 3}, cancelAllErr: tt.cancelAllErr}
+			executor := &fakeCanceller{ex: ex, dryRun: tt.dryRun}
+
+			err := cancelAllOrders(context.Background(), ex, executor, "ETHUSDT", event.OrderReasonExit)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("返回错误应为 %v，实际 %v", tt.wantErr, err)
+			}
+			if ex.cancelAllCalls != tt.wantCalls {
+				t.Fatalf("一键撤单请求应为 %d 次，实际 %d 次", tt.wantCalls, ex.cancelAllCalls)
+			}
+			if !reflect.DeepEqual(executor.canceled, tt.wantCanceled) {
+				t.Fatalf("分批撤销的订单应为 %v，实际 %v", tt.wantCanceled, executor.canceled)
+			}
+			if len(ex.open) != tt.wantOpen {
+				t.Fatalf("交易所上应剩余 %d 个挂单，实际 %d 个", tt.wantOpen, len(ex.open))
+			}
+			for _, reason := range executor.reasons {
+				if reason != event.OrderReasonExit {
+					t.Fatalf("分批撤单应沿用撤单原因 %s，实际 %s", event.OrderReasonExit, reason)
+				}
+			}
+		})
+	}
+}
+
+func TestCancelAllOrdersRecordsAction(t *testing.T) {
+	actions := make(chan event.OrderAction, 4)
+	unsubscribe := event.Subscribe("exit-test", func(e event.Event) {
+		if action, ok := e.Payload.(event.OrderAction); ok && action.Symbol == "BTCUSDT" {
+			actions <- action
+		}
+	}, event.TypeOrderAction)
+	defer unsubscribe()
+
+	ex := &cancelExchange{cancelAllErr: errors.New("network down")}
+	_ = cancelAllOrders(context.Background(), ex, &fakeCanceller{ex: ex}, "BTCUSDT", event.OrderReasonExit)
+
+	select {
+	case action := <-actions:
+		if action.Action != "cancel_all" || action.Reason != event.OrderReasonExit || action.Error != "network down" {
+			t.Fatalf("撤单动作记录不符: %+v", action)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("一键撤单应发布撤单动作")
+	}
+}
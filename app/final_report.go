@@ -0,0 +1,211 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"opensqt/event"
+	"opensqt/exchange"
+	"opensqt/logger"
+	"opensqt/position"
+	"opensqt/safety"
+)
+
+// finalReport 运行结束汇总（system.final_report_file），每次正常退出写入一个 JSON 文件，便于跨多次运行汇总分析
+type finalReport struct {
+	Exchange        string  `json:"exchange"`
+	Symbol          string  `json:"symbol"`
+	StartTime       string  `json:"start_time"`
+	EndTime         string  `json:"end_time"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	ExitReason      string  `json:"exit_reason"` // signal（退出信号/终端退出）/ take_profit（止盈退出）/ stop_loss（止损退出）
+
+	InitialBalance float64 `json:"initial_balance"` // 账户净值（查询失败时为0）
+	FinalBalance   float64 `json:"final_balance"`
+	BalanceChange  float64 `json:"balance_change"`
+
+	RealizedPnL    float64 `json:"realized_pnl"` // 本次运行卖单结转的已实现盈亏（含手续费估算）
+	RoundTrips     int64   `json:"round_trips"`  // 完成的买卖轮次
+	TotalBuyQty    float64 `json:"total_buy_qty"`
+	TotalSellQty   float64 `json:"total_sell_qty"`
+	MaxInventory   float64 `json:"max_inventory"`   // 运行期间的最大持仓数量
+	FinalInventory float64 `json:"final_inventory"` // 退出时的槽位持仓合计
+
+	Reconnects   int `json:"reconnects"`    // WebSocket 断线重连次数（各流首次连接不计）
+	RiskTriggers int `json:"risk_triggers"` // 主动风控触发次数
+}
+
+// runReport 收集运行期间的统计，退出时写入 system.final_report_file
+type runReport struct {
+	path      string
+	ex        exchange.IExchange
+	startTime time.Time
+
+	mu             sync.Mutex
+	spm            *position.SuperPositionManager // Start 之前为 nil
+	initialBalance float64
+	maxInventory   float64
+	connects       map[string]int // 交易所/流类型 -> 连接次数
+	riskTriggers   int
+
+	unsubscribe func()
+}
+
+// newRunReport 创建运行汇总收集器（path 为空时返回 nil，所有方法对 nil 安全）
+// 需在启动价格流之前创建，才能区分首次连接和断线重连
+func newRunReport(path string, ex exchange.IExchange) *runReport {
+	if path == "" {
+		return nil
+	}
+	r := &runReport{
+		path:      path,
+		ex:        ex,
+		startTime: time.Now(),
+		connects:  make(map[string]int),
+	}
+	r.unsubscribe = event.Subscribe("final-report", r.onEvent,
+		event.TypeOrderFilled, event.TypeStreamConnected, event.TypeRiskTriggered)
+	return r
+}
+
+// onEvent 统计重连、风控触发次数，买单成交后更新最大持仓
+func (r *runReport) onEvent(e event.Event) {
+	switch payload := e.Payload.(type) {
+	case event.StreamConnected:
+		r.mu.Lock()
+		r.connects[payload.Exchange+"/"+payload.Stream]++
+		r.mu.Unlock()
+	case event.RiskTriggered:
+		r.mu.Lock()
+		r.riskTriggers++
+		r.mu.Unlock()
+	case event.OrderFilled:
+		// 多交易对时只统计本交易对的成交
+		r.mu.Lock()
+		spm := r.spm
+		r.mu.Unlock()
+		if payload.Side == "BUY" && spm != nil && payload.Symbol == spm.GetSymbol() {
+			r.sampleInventory()
+		}
+	}
+}
+
+// sampleInventory 按当前槽位持仓更新最大持仓
+func (r *runReport) sampleInventory() float64 {
+	r.mu.Lock()
+	spm := r.spm
+	r.mu.Unlock()
+	if spm == nil {
+		return 0
+	}
+
+	qty := spm.GetStatusSnapshot().PositionQty
+	r.mu.Lock()
+	if qty > r.maxInventory {
+		r.maxInventory = qty
+	}
+	r.mu.Unlock()
+	return qty
+}
+
+// Start 关联仓位管理器并记录初始账户净值和持仓（持仓恢复之后、首次挂单之前调用）
+func (r *runReport) Start(ctx context.Context, spm *position.SuperPositionManager) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.spm = spm
+	r.mu.Unlock()
+	r.sampleInventory()
+	balance, err := r.balance(ctx)
+	if err != nil {
+		logger.Warn("⚠️ [运行汇总] 获取初始余额失败，汇总中初始余额记为0: %v", err)
+		return
+	}
+	r.mu.Lock()
+	r.initialBalance = balance
+	r.mu.Unlock()
+}
+
+// balance 查询账户净值
+func (r *runReport) balance(ctx context.Context) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	account, err := r.ex.GetAccount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return safety.EffectiveBalance(account), nil
+}
+
+// Write 查询最终余额并写入汇总文件（正常退出时调用，需在 Start 之后）
+func (r *runReport) Write(exitReason string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	spm := r.spm
+	r.mu.Unlock()
+	if spm == nil {
+		return
+	}
+	r.unsubscribe()
+
+	finalBalance, err := r.balance(context.Background())
+	if err != nil {
+		logger.Warn("⚠️ [运行汇总] 获取最终余额失败，汇总中最终余额记为0: %v", err)
+	}
+	finalInventory := r.sampleInventory()
+	endTime := time.Now()
+
+	roundTrips, realized := spm.RealizedStats()
+	r.mu.Lock()
+	reconnects := 0
+	for _, count := range r.connects {
+		reconnects += count - 1
+	}
+	report := finalReport{
+		Exchange:        r.ex.GetName(),
+		Symbol:          spm.GetSymbol(),
+		StartTime:       r.startTime.Format(time.RFC3339),
+		EndTime:         endTime.Format(time.RFC3339),
+		DurationSeconds: endTime.Sub(r.startTime).Seconds(),
+		ExitReason:      exitReason,
+		InitialBalance:  r.initialBalance,
+		FinalBalance:    finalBalance,
+		RealizedPnL:     realized,
+		RoundTrips:      roundTrips,
+		TotalBuyQty:     spm.GetTotalBuyQty(),
+		TotalSellQty:    spm.GetTotalSellQty(),
+		MaxInventory:    r.maxInventory,
+		FinalInventory:  finalInventory,
+		Reconnects:      reconnects,
+		RiskTriggers:    r.riskTriggers,
+	}
+	r.mu.Unlock()
+	if report.InitialBalance > 0 && report.FinalBalance > 0 {
+		report.BalanceChange = report.FinalBalance - report.InitialBalance
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		logger.Error("❌ [运行汇总] 序列化失败: %v", err)
+		return
+	}
+	if dir := filepath.Dir(r.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			logger.Error("❌ [运行汇总] 创建目录失败: %v", err)
+			return
+		}
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0644); err != nil {
+		logger.Error("❌ [运行汇总] 写入汇总文件失败: %v", err)
+		return
+	}
+	logger.Info("📝 [运行汇总] 运行 %v，完成 %d 轮，已实现盈亏 %.4f，汇总已写入 %s",
+		endTime.Sub(r.startTime).Round(time.Second), roundTrips, realized, r.path)
+}
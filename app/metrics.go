@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"sync/atomic"
+
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/logger"
+	"opensqt/monitor"
+	"opensqt/safety"
+)
+
+// startMetrics 注册各组件的指标并启动 Prometheus 指标接口（system.metrics_addr 为空时不启动）
+// 账户级指标（盈利、风控状态）不带标签，交易对级指标带 symbol 标签
+func startMetrics(ctx context.Context, cfg *config.Config, runs []*symbolRun, takeProfit *safety.TakeProfitMonitor, risk *safety.RiskMonitor) {
+	if cfg.System.MetricsAddr == "" {
+		return
+	}
+
+	monitor.RegisterGauge("opensqt_current_profit", "当前盈利（USDT，止盈监控最近一次余额 - 初始余额，未启用止盈时为0）", nil, func() float64 {
+		_, _, profit := takeProfit.GetCurrentProfit()
+		return profit
+	})
+	monitor.RegisterGauge("opensqt_risk_triggered", "主动风控是否触发（1 触发，0 正常）", nil, func() float64 {
+		return boolMetric(risk.IsTriggered())
+	})
+
+	filled := make(map[string]*atomic.Int64, len(runs))
+	for _, run := range runs {
+		labels := monitor.Labels{"symbol": run.symbol}
+		count := &atomic.Int64{}
+		filled[run.symbol] = count
+
+		monitor.RegisterGauge("opensqt_last_price", "最新成交价格", labels, run.priceMonitor.GetLastPrice)
+		for _, side := range []string{"BUY", "SELL"} {
+			monitor.RegisterGauge("opensqt_open_orders", "本地跟踪的挂单数", monitor.Labels{"symbol": run.symbol, "side": side}, func() float64 {
+				snapshot := run.spm.GetStatusSnapshot()
+				if side == "BUY" {
+					return float64(snapshot.ActiveBuyOrders)
+				}
+				return float64(snapshot.ActiveSellOrders)
+			})
+		}
+		monitor.RegisterCounter("opensqt_filled_orders_total", "完全成交的订单数", labels, func() float64 {
+			return float64(count.Load())
+		})
+		monitor.RegisterCounter("opensqt_orders_placed_total", "下单成功的订单数", labels, func() float64 {
+			placed, _ := run.executor.OrderStats()
+			return float64(placed)
+		})
+		monitor.RegisterCounter("opensqt_order_failures_total", "下单失败的订单数（重试后仍失败）", labels, func() float64 {
+			_, failed := run.executor.OrderStats()
+			return float64(failed)
+		})
+		monitor.RegisterCounter("opensqt_reconcile_mismatches_total", "对账发现的挂单差异数", labels, func() float64 {
+			return float64(run.reconciler.GetMismatchCount())
+		})
+	}
+
+	unsubscribe := event.Subscribe("metrics", func(e event.Event) {
+		if fill, ok := e.Payload.(event.OrderFilled); ok && fill.Complete {
+			if count := filled[fill.Symbol]; count != nil {
+				count.Add(1)
+			}
+		}
+	}, event.TypeOrderFilled)
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+
+	if err := monitor.StartMetricsServer(cfg.System.MetricsAddr); err != nil {
+		logger.Error("❌ %v", err)
+	}
+}
+
+// boolMetric 布尔状态转换为指标值
+func boolMetric(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
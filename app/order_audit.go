@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"opensqt/event"
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// startOrderAudit 将下单/撤单动作及原因以 JSON Lines 追加到 system.order_audit_file（未配置时直接返回）
+// 订阅在返回前完成，确保首次挂单也被记录；ctx 取消后停止记录
+func startOrderAudit(ctx context.Context, path string) {
+	if path == "" {
+		return
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			logger.Error("❌ [订单审计] 创建目录失败，订单动作不记录: %v", err)
+			return
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logger.Error("❌ [订单审计] 打开记录文件失败，订单动作不记录: %v", err)
+		return
+	}
+
+	type record struct {
+		Time string `json:"time"`
+		event.OrderAction
+	}
+	unsubscribe := event.Subscribe("order-audit", func(e event.Event) {
+		action, ok := e.Payload.(event.OrderAction)
+		if !ok {
+			return
+		}
+		line, err := json.Marshal(record{Time: e.Time.Format("2006-01-02T15:04:05.000Z07:00"), OrderAction: action})
+		if err != nil {
+			return
+		}
+		if _, err := file.Write(append(line, '\n')); err != nil {
+			logger.Warn("⚠️ [订单审计] 写入记录文件失败: %v", err)
+		}
+	}, event.TypeOrderAction)
+
+	logger.Info("📝 [订单审计] 下单/撤单动作及原因记录到 %s", path)
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+		file.Close()
+	}()
+}
+
+// recordExitOrder 记录退出时的平仓/追踪止损下单动作（order 为 nil 表示下单失败）
+func recordExitOrder(symbol string, quantity float64, order *exchange.Order, err error) {
+	action := event.OrderAction{Symbol: symbol, Action: "place", Side: string(exchange.SideSell), Quantity: quantity, Reason: event.OrderReasonExit}
+	if err != nil {
+		action.Error = err.Error()
+	} else if order != nil {
+		action.OrderID = order.OrderID
+		action.ClientOrderID = order.ClientOrderID
+	}
+	event.Publish(event.TypeOrderAction, action)
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"opensqt/config"
+	"opensqt/logger"
+)
+
+// fixedFields 运行中不能修改的配置字段（交易所实例、价格流和订单流都绑定在交易对上），重载时保持原值
+var fixedFields = map[string]bool{
+	"app.current_exchange": true,
+	"trading.symbol":       true,
+	"trading.symbols":      true,
+}
+
+// watchConfigReload 接收重新加载的配置并应用到各交易对（阻塞直到 ctx 取消）
+func watchConfigReload(ctx context.Context, cfg *config.Config, reload <-chan *config.Config, runs []*symbolRun) {
+	current := cfg
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case next := <-reload:
+			current = applyReloadedConfig(current, next, runs)
+		}
+	}
+}
+
+// applyReloadedConfig 对比新旧配置，把可在运行中修改的交易参数应用到各交易对的仓位管理器
+// 交易对和交易所的修改被拒绝并保持原值，其余字段的修改在重启后生效；
+// 返回下一次重载的比较基准：当前配置加上实际生效的字段，需重启和被拒绝的字段保持原值，下次重载仍会对比出来
+func applyReloadedConfig(current, next *config.Config, runs []*symbolRun) *config.Config {
+	changed := current.Diff(next)
+	if len(changed) == 0 {
+		logger.Info("🔄 [配置重载] 配置文件未变化")
+		return current
+	}
+	logger.Info("🔄 [配置重载] 配置变化的字段: %s", strings.Join(changed, ", "))
+
+	applied := *next
+	for _, field := range changed {
+		if fixedFields[field] {
+			logger.Warn("⚠️ [配置重载] %s 不能在运行中修改，保持原值（修改后需重启生效）", field)
+		}
+	}
+	applied.App.CurrentExchange = current.App.CurrentExchange
+	applied.Trading.Symbol = current.Trading.Symbol
+	applied.Trading.Symbols = current.Trading.Symbols
+
+	restart := make(map[string]bool)
+	rejected := make(map[string]bool)
+	for _, run := range runs {
+		before, err := current.ForSymbol(run.symbol)
+		if err == nil {
+			var after *config.Config
+			if after, err = applied.ForSymbol(run.symbol); err == nil {
+				applyTradingParams(run, before, after, restart, rejected)
+				continue
+			}
+		}
+		logger.Warn("⚠️ [配置重载] %s: %v，保持当前交易参数", run.symbol, err)
+		for _, field := range changed {
+			rejected[field] = true
+		}
+	}
+
+	pending := make([]string, 0, len(restart))
+	for field := range restart {
+		pending = append(pending, field)
+	}
+	sort.Strings(pending)
+	if len(pending) > 0 {
+		logger.Warn("⚠️ [配置重载] 以下字段需重启后生效: %s", strings.Join(pending, ", "))
+	}
+	return reloadBaseline(current, &applied, changed, restart, rejected)
+}
+
+// reloadBaseline 以当前配置为基础，只写入所有交易对都已生效的交易参数
+// 交易对覆盖（symbol_overrides）展开到各交易对的字段后无法逐项区分，只在本次重载没有需重启或被拒绝的字段时写入
+func reloadBaseline(current, applied *config.Config, changed []string, restart, rejected map[string]bool) *config.Config {
+	baseline := *current
+	for _, field := range changed {
+		if restart[field] || rejected[field] {
+			continue
+		}
+		switch field {
+		case "trading.price_interval":
+			baseline.Trading.PriceInterval = applied.Trading.PriceInterval
+		case "trading.order_quantity":
+			baseline.Trading.OrderQuantity = applied.Trading.OrderQuantity
+		case "trading.buy_window_size":
+			baseline.Trading.BuyWindowSize = applied.Trading.BuyWindowSize
+		case "trading.sell_window_size":
+			baseline.Trading.SellWindowSize = applied.Trading.SellWindowSize
+		case "trading.min_order_value":
+			baseline.Trading.MinOrderValue = applied.Trading.MinOrderValue
+		case "trading.symbol_overrides":
+			if len(restart) == 0 && len(rejected) == 0 {
+				baseline.Trading.SymbolOverrides = applied.Trading.SymbolOverrides
+			}
+		}
+	}
+	return &baseline
+}
+
+// applyTradingParams 把单个交易对配置中变化的交易参数应用到仓位管理器，无法在运行中生效的字段记入 restart，
+// 参数无效被拒绝时本次要应用的字段记入 rejected
+// 网格密度模式（grid_levels）下价格间隔和窗口由网格换算，按目标资金使用率计算买单窗口时 buy_window_size 不生效，均需重启
+func applyTradingParams(run *symbolRun, before, after *config.Config, restart, rejected map[string]bool) {
+	params := run.spm.CurrentTradingParams()
+	gridMode := after.Trading.GridLevels > 0
+	var updated []string
+	for _, field := range before.Diff(after) {
+		switch {
+		case field == "trading.symbol_overrides":
+			continue // 覆盖值已按交易对展开到对应字段
+		case field == "trading.price_interval" && !gridMode:
+			params.PriceInterval = after.Trading.PriceInterval
+		case field == "trading.order_quantity":
+			params.OrderQuantity = after.Trading.OrderQuantity
+		case field == "trading.buy_window_size" && !gridMode && after.Trading.TargetUtilization <= 0:
+			params.BuyWindowSize = after.Trading.BuyWindowSize
+		case field == "trading.sell_window_size" && !gridMode:
+			params.SellWindowSize = after.Trading.SellWindowSize
+		case field == "trading.min_order_value":
+			params.MinOrderValue = after.Trading.MinOrderValue
+		default:
+			restart[field] = true
+			continue
+		}
+		updated = append(updated, field)
+	}
+	if len(updated) == 0 {
+		return
+	}
+
+	changes, err := run.spm.UpdateTradingParams(params)
+	if err != nil {
+		logger.Warn("⚠️ [配置重载] %s 交易参数无效，保持当前参数: %v", run.symbol, err)
+		for _, field := range updated {
+			rejected[field] = true
+		}
+		return
+	}
+	if len(changes) > 0 {
+		logger.Info("✅ [配置重载] %s 已应用: %s", run.symbol, strings.Join(changes, ", "))
+	}
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"opensqt/config"
+	"opensqt/position"
+)
+
+// reloadTestRun 只带仓位管理器的交易对（配置重载只调整交易参数）
+func reloadTestRun(cfg *config.Config) *symbolRun {
+	symbolCfg, _ := cfg.ForSymbol(cfg.Trading.Symbol)
+	return &symbolRun{symbol: cfg.Trading.Symbol, spm: position.NewSuperPositionManager(symbolCfg, nil, nil, 2, 4)}
+}
+
+func reloadTestConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Trading.Symbol = "ETHUSDT"
+	cfg.Trading.PriceInterval = 1
+	cfg.Trading.OrderQuantity = 20
+	cfg.Trading.BuyWindowSize = 10
+	cfg.Trading.SellWindowSize = 10
+	cfg.Trading.MarginLockDurationSec = 10
+	return cfg
+}
+
+func TestReloadBaselineKeepsPendingFields(t *testing.T) {
+	current := reloadTestConfig()
+	run := reloadTestRun(current)
+
+	next := reloadTestConfig()
+	next.Trading.OrderQuantity = 30         // 运行中生效
+	next.Trading.MarginLockDurationSec = 20 // 需重启后生效
+	baseline := applyReloadedConfig(current, next, []*symbolRun{run})
+
+	if got := run.spm.CurrentTradingParams().OrderQuantity; got != 30 {
+		t.Fatalf("order_quantity 应在运行中生效，实际 %g", got)
+	}
+	if baseline.Trading.OrderQuantity != 30 || baseline.Trading.MarginLockDurationSec != 10 {
+		t.Fatalf("比较基准应只写入已生效的字段，实际 order_quantity=%g margin_lock_duration_sec=%d",
+			baseline.Trading.OrderQuantity, baseline.Trading.MarginLockDurationSec)
+	}
+	if diff := baseline.Diff(next); len(diff) != 1 {
+		t.Fatalf("需重启的字段下次重载仍应对比出来，实际 %v", diff)
+	}
+}
+
+func TestReloadBaselineKeepsRejectedFields(t *testing.T) {
+	current := reloadTestConfig()
+	run := reloadTestRun(current)
+
+	next := reloadTestConfig()
+	next.Trading.OrderQuantity = 30
+	next.Trading.BuyWindowSize = 0 // 无效，整组参数被拒绝
+	baseline := applyReloadedConfig(current, next, []*symbolRun{run})
+
+	if got := run.spm.CurrentTradingParams().OrderQuantity; got != 20 {
+		t.Fatalf("参数被拒绝时应保持当前参数，实际 order_quantity=%g", got)
+	}
+	if !reflect.DeepEqual(baseline.Diff(next), current.Diff(next)) {
+		t.Fatalf("被拒绝的字段应保留在比较基准中，下次重载重试，实际差异 %v", baseline.Diff(next))
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"opensqt/logger"
+	"opensqt/position"
+)
+
+// runtimeStatus 运行状态（管理接口 GET /status、SSE 状态快照和状态时间序列共用）
+type runtimeStatus struct {
+	position.StatusSnapshot
+	Exchange       string             `json:"exchange"`
+	MarketPrice    float64            `json:"market_price"`
+	RiskTriggered  bool               `json:"risk_triggered"`
+	ExchangePaused bool               `json:"exchange_paused"`
+	Flattened      bool               `json:"flattened"`
+	RateLimits     map[string]float64 `json:"rate_limits"`           // 各限流桶可用令牌数
+	WSEndpoint     string             `json:"ws_endpoint,omitempty"` // 当前使用的 WebSocket 地址
+}
+
+// recordStatusHistory 按间隔将运行状态以 JSON Lines 追加到 system.status_history_file（阻塞直到 ctx 取消，未配置时直接返回）
+// 每行包含记录时间和运行状态各字段，不含网格槽位明细，便于长时间运行后逐行读取绘制时间序列
+func recordStatusHistory(ctx context.Context, path string, intervalSec int, status func() runtimeStatus) {
+	if path == "" || intervalSec <= 0 {
+		return
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			logger.Error("❌ [状态记录] 创建目录失败，状态不记录: %v", err)
+			return
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logger.Error("❌ [状态记录] 打开记录文件失败，状态不记录: %v", err)
+		return
+	}
+	defer file.Close()
+
+	type record struct {
+		Time string `json:"time"`
+		runtimeStatus
+	}
+	write := func() {
+		s := status()
+		s.Levels = nil
+		line, err := json.Marshal(record{Time: time.Now().Format("2006-01-02T15:04:05.000Z07:00"), runtimeStatus: s})
+		if err != nil {
+			return
+		}
+		if _, err := file.Write(append(line, '\n')); err != nil {
+			logger.Warn("⚠️ [状态记录] 写入记录文件失败: %v", err)
+		}
+	}
+
+	logger.Info("📝 [状态记录] 每 %d 秒追加运行状态到 %s", intervalSec, path)
+	ticker := time.NewTicker(time.Duration(intervalSec) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			write() // 退出前记录最后一次状态
+			return
+		case <-ticker.C:
+			write()
+		}
+	}
+}
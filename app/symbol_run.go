@@ -0,0 +1,784 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/exchange"
+	"opensqt/logger"
+	"opensqt/monitor"
+	"opensqt/notify"
+	"opensqt/order"
+	"opensqt/position"
+	"opensqt/safety"
+	"opensqt/utils"
+)
+
+// sharedComponents 同一账户下各交易对共用的组件
+type sharedComponents struct {
+	rateLimiter *order.RateLimiter  // 限流针对整个账户（per_symbol 桶按交易对区分）
+	riskMonitor *safety.RiskMonitor // 主动风控监控的是市场整体行情
+	allocations map[string]float64  // 交易对 -> 分配资金（0 表示不限制）
+	crashes     *crashDumpers       // 致命错误时导出所有交易对的现场
+	notifier    notify.INotifier    // 告警推送（nil 表示不推送）
+}
+
+// symbolRun 单个交易对的运行组件（价格流、订单流、仓位管理器、对账器、订单清理器等）
+type symbolRun struct {
+	cfg    *config.Config
+	symbol string
+	ex     exchange.IExchange
+
+	priceMonitor  *monitor.PriceMonitor
+	executor      *order.ExchangeOrderExecutor
+	simulator     *order.SimulatedFillEngine // system.dry_run 时的模拟成交引擎（否则为 nil）
+	spm           *position.SuperPositionManager
+	healthMonitor *safety.ExchangeHealthMonitor
+	riskMonitor   *safety.RiskMonitor
+	rateLimiter   *order.RateLimiter
+	report        *runReport
+	crash         *crashDumper
+	tradeHistory  *safety.TradeHistorySummary // 未启用或回溯失败时为 nil
+	reconciler    *safety.Reconciler
+
+	flattened     atomic.Bool // 紧急平仓后暂停挂单，直到手动恢复
+	manualPaused  atomic.Bool // 终端快捷键手动暂停挂单
+	controlPaused atomic.Bool // 控制接口暂停挂单（撤销买单），直到 resume
+	flattenMu     sync.Mutex
+}
+
+// runSymbol 按顺序创建并启动单个交易对的组件（cfg 为 Config.ForSymbol 返回的该交易对配置，ex 为该交易对的交易所实例）
+// 返回前已完成安全检查、启动订单流并初始化仓位管理器，挂单由价格协程在收到价格后开始；失败时停止已启动的价格流和订单流
+func runSymbol(ctx context.Context, cfg *config.Config, ex exchange.IExchange, symbol string, shared sharedComponents) (*symbolRun, error) {
+	// 交易所健康监测：统计所有 REST 调用的 5xx 错误（健康检查使用原始实例，避免自我计数）
+	healthMonitor := safety.NewExchangeHealthMonitor(cfg, ex)
+	if cfg.ExchangeHealth.Enabled {
+		ex = exchange.WithCallObserver(ex, healthMonitor.RecordResult)
+	}
+	// 止盈退出、信号退出、紧急平仓可能同时撤单：合并并发的 CancelAllOrders，撤单后确认无残留挂单
+	ex = exchange.WithIdempotentCancelAll(ex, cfg.System.CancelAllRetries)
+
+	// 3. 创建价格监控组件（该交易对唯一的价格来源）
+	// 架构说明：
+	// - 这是该交易对唯一的价格流启动点
+	// - WebSocket 是唯一的价格来源，不使用 REST API 轮询
+	// - 所有组件需要价格时，都应该通过 priceMonitor.GetLastPrice() 获取
+	// - 必须在其他组件初始化前启动，确保价格数据就绪
+	priceMonitor := monitor.NewPriceMonitor(
+		ex,
+		symbol,
+		cfg.Timing.PriceSendInterval,
+	)
+	priceMonitor.SetAnchorSource(cfg.Trading.AnchorSource, cfg.Trading.DepthPollInterval)
+
+	// 运行汇总（system.final_report_file 设置时生效，需在价格流启动前订阅连接事件）
+	report := newRunReport(cfg.System.FinalReportFile, ex)
+
+	// 崩溃现场（system.crash_dump_file 设置时生效）：致命错误（含 Run 返回错误后 main 的退出）和主流程 panic 退出前导出
+	crash := shared.crashes.add(cfg.System.CrashDumpFile, ex, symbol)
+	defer crash.Recover("主流程")
+
+	// 4. 启动价格监控（WebSocket 必须成功）
+	logger.Info("🔗 启动 WebSocket 价格流...")
+	if err := priceMonitor.Start(); err != nil {
+		return nil, fmt.Errorf("启动价格流失败（WebSocket 是唯一价格来源）: %w", err)
+	}
+
+	// 5. 等待从 WebSocket 获取初始价格
+	logger.Debugln("⏳ 等待 WebSocket 推送初始价格...")
+	var currentPrice float64
+	var currentPriceStr string
+	pollInterval := time.Duration(cfg.Timing.PricePollInterval) * time.Millisecond
+	for i := 0; i < 10; i++ {
+		currentPrice = priceMonitor.GetLastPrice()
+		currentPriceStr = priceMonitor.GetLastPriceString()
+		if currentPrice > 0 {
+			break
+		}
+		time.Sleep(pollInterval)
+	}
+
+	if currentPrice <= 0 {
+		priceMonitor.Stop()
+		return nil, fmt.Errorf("无法从 WebSocket 获取价格（超时），系统无法启动")
+	}
+
+	// 从交易所获取精度信息
+	priceDecimals := ex.GetPriceDecimals()
+	quantityDecimals := ex.GetQuantityDecimals()
+	logger.Info("ℹ️ 交易精度 - 价格精度:%d, 数量精度:%d", priceDecimals, quantityDecimals)
+	logger.Debug("📊 当前价格: %.*f", priceDecimals, currentPrice)
+
+	// 6. 持仓安全性检查（必须在开始交易之前执行）
+	requiredPositions := cfg.Trading.PositionSafetyCheck
+	if requiredPositions <= 0 {
+		requiredPositions = 100 // 默认100
+	}
+
+	// 获取当前交易所的手续费率
+	exchangeCfg := cfg.Exchanges[cfg.App.CurrentExchange]
+	feeRate := exchangeCfg.FeeRate
+	// 注意：支持0费率，不需要特殊处理
+
+	// 资金分配：以分配金额作为该交易对的可用余额（由 Run 统一换算，合计不超过账户余额）
+	capitalAllocation := shared.allocations[symbol]
+
+	// 按网格密度换算价格间隔（trading.grid_range_percent / grid_levels，买单窗口在配置校验时已设为 grid_levels）
+	if cfg.Trading.GridLevels > 0 {
+		interval, err := position.GridInterval(currentPrice, cfg.Trading.GridRangePercent, cfg.Trading.GridLevels, priceDecimals)
+		if err != nil {
+			priceMonitor.Stop()
+			return nil, fmt.Errorf("计算网格间隔失败: %w", err)
+		}
+		if err := cfg.ApplyPriceInterval(interval); err != nil {
+			priceMonitor.Stop()
+			return nil, fmt.Errorf("网格间隔无效: %w", err)
+		}
+		logger.Info("📐 [网格密度] 区间 %.2f%% / %d 层 → 价格间隔 %.*f, 覆盖 %.*f ~ %.*f",
+			cfg.Trading.GridRangePercent, cfg.Trading.GridLevels, priceDecimals, interval,
+			priceDecimals, currentPrice-float64(cfg.Trading.GridLevels)*interval, priceDecimals, currentPrice-interval)
+		rangeInterval := currentPrice * cfg.Trading.GridRangePercent / 100 / float64(cfg.Trading.GridLevels)
+		if interval > rangeInterval*1.5 {
+			logger.Warn("⚠️ [网格密度] 层数过多，间隔已提高到最小价格单位 %.*f，实际覆盖区间大于 %.2f%%",
+				priceDecimals, interval, cfg.Trading.GridRangePercent)
+		}
+	}
+
+	// 按目标资金使用率计算买单窗口（trading.target_utilization）
+	if cfg.Trading.TargetUtilization > 0 {
+		maxOrders := cfg.Trading.OrderCleanupThreshold
+		if maxOrders <= 0 {
+			maxOrders = 100 // 与订单清理器默认阈值一致
+		}
+		// 买单 + 卖单总数需低于清理阈值，卖单窗口未设置时与买单窗口相同
+		if cfg.Trading.SellWindowSize > 0 {
+			maxOrders -= cfg.Trading.SellWindowSize + 1
+		} else {
+			maxOrders = (maxOrders - 1) / 2
+		}
+		buyWindowSize, err := safety.DeriveBuyWindow(ex, safety.WindowSizingParams{
+			Symbol:            symbol,
+			CurrentPrice:      currentPrice,
+			OrderAmount:       cfg.Trading.OrderQuantity,
+			PriceInterval:     cfg.Trading.PriceInterval,
+			CapitalAllocation: capitalAllocation,
+			TargetUtilization: cfg.Trading.TargetUtilization,
+			MaxOrders:         maxOrders,
+			PriceDecimals:     priceDecimals,
+		})
+		if err != nil {
+			priceMonitor.Stop()
+			return nil, fmt.Errorf("计算买单窗口失败: %w", err)
+		}
+		cfg.Trading.BuyWindowSize = buyWindowSize
+		if cfg.Trading.SellWindowSize <= 0 {
+			cfg.Trading.SellWindowSize = buyWindowSize
+		}
+	}
+
+	// 执行持仓安全性检查（使用独立的 safety 包）
+	// 动态间隔运行中可能缩小到 min_interval，按下限核算每笔净利润
+	safetyInterval := cfg.Trading.PriceInterval
+	if cfg.Trading.DynamicInterval.Enabled {
+		safetyInterval = cfg.Trading.DynamicInterval.MinInterval
+	}
+	// 每笔利润取决于卖单间隔（设置 sell_price_interval 时卖单间隔与价格间隔按比例换算）
+	if sellInterval := cfg.Trading.SellPriceInterval; sellInterval > 0 {
+		safetyInterval = safetyInterval * sellInterval / cfg.Trading.PriceInterval
+	}
+	if err := safety.CheckAccountSafety(
+		ex,
+		symbol,
+		currentPrice,
+		cfg.Trading.OrderQuantity,
+		safetyInterval,
+		cfg.Trading.MinOrderValue,
+		capitalAllocation,
+		feeRate,
+		requiredPositions,
+		cfg.Trading.BuyWindowSize,
+		priceDecimals,
+		cfg.Trading.MaxLeverage,
+	); err != nil {
+		priceMonitor.Stop()
+		if reason, ok := safety.SafetyCheckReasonOf(err); ok {
+			return nil, fmt.Errorf("[%s] %w", reason, err)
+		}
+		return nil, err
+	}
+	// 交易所能力检查：适配器缺少策略需要的订单类型/标记时直接失败
+	if err := safety.CheckCapabilities(cfg, ex); err != nil {
+		priceMonitor.Stop()
+		if reason, ok := safety.SafetyCheckReasonOf(err); ok {
+			return nil, fmt.Errorf("[%s] %w", reason, err)
+		}
+		return nil, err
+	}
+	// 只减仓检查：适配器未声明传递只减仓标记时直接失败，verify_reduce_only 启用时再下单验证
+	// 模拟交易不下验证单
+	if err := safety.CheckReduceOnly(context.Background(), ex, symbol, currentPrice, priceDecimals, cfg.Safety.VerifyReduceOnly && !cfg.System.DryRun); err != nil {
+		priceMonitor.Stop()
+		if reason, ok := safety.SafetyCheckReasonOf(err); ok {
+			return nil, fmt.Errorf("[%s] %w", reason, err)
+		}
+		return nil, err
+	}
+	logger.Info("✅ 持仓安全性检查通过，开始初始化交易组件...")
+
+	// 8. 创建核心组件
+	rateLimiter := shared.rateLimiter
+	exchangeExecutor := order.NewExchangeOrderExecutor(
+		ex,
+		symbol,
+		rateLimiter,
+		cfg.Timing.RateLimitRetryDelay,
+		cfg.Timing.OrderRetryDelay,
+	)
+	// 操作节奏：配置优先，未配置时使用交易所声明的最小操作间隔
+	minActionInterval := exchange.GetMinActionInterval(ex)
+	if ms := cfg.Exchanges[cfg.App.CurrentExchange].MinActionIntervalMs; ms > 0 {
+		minActionInterval = time.Duration(ms) * time.Millisecond
+	}
+	exchangeExecutor.SetMinActionInterval(minActionInterval)
+	placementConfirm := cfg.Trading.PlacementConfirm
+	exchangeExecutor.SetPlacementConfirm(placementConfirm.Sides,
+		time.Duration(placementConfirm.DelayMs)*time.Millisecond, placementConfirm.MaxReplaces)
+	exchangeExecutor.SetNotifier(shared.notifier)
+	exchangeExecutor.SetCancelBatchSize(cfg.System.CancelBatchSize)
+	executorAdapter := &exchangeExecutorAdapter{executor: exchangeExecutor}
+
+	// 模拟挂单只存在于本进程，不能恢复或写入真实运行的仓位状态
+	if cfg.System.DryRun && cfg.System.StateFile != "" {
+		logger.Warn("🧪 [模拟交易] 不读取也不保存仓位状态文件 %s", cfg.System.StateFile)
+		cfg.System.StateFile = ""
+	}
+
+	// 创建交易所适配器（匹配 position.IExchange 接口）
+	exchangeAdapter := &positionExchangeAdapter{exchange: ex}
+	superPositionManager := position.NewSuperPositionManager(cfg, executorAdapter, exchangeAdapter, priceDecimals, quantityDecimals)
+
+	// 订单更新（交易所订单流或模拟成交引擎）按交易对过滤后交给仓位管理器
+	deliverOrderUpdate := func(update exchange.OrderUpdate) {
+		if !ownsOrderUpdate(update.Symbol, symbol) {
+			return
+		}
+		posUpdate := position.OrderUpdate{
+			OrderID:       update.OrderID,
+			ClientOrderID: update.ClientOrderID, // 🔥 关键：传递 ClientOrderID
+			Symbol:        update.Symbol,
+			Status:        string(update.Status),
+			ExecutedQty:   update.ExecutedQty,
+			Price:         update.Price,
+			AvgPrice:      update.AvgPrice,
+			Side:          string(update.Side),
+			Type:          string(update.Type),
+			UpdateTime:    update.UpdateTime,
+		}
+
+		logger.Debug("🔍 [订单流] 收到订单更新回调: ID=%d, ClientOID=%s, Price=%.2f, Status=%s",
+			posUpdate.OrderID, posUpdate.ClientOrderID, posUpdate.Price, posUpdate.Status)
+		exchangeExecutor.NotifyOrderUpdate(posUpdate.ClientOrderID)
+		superPositionManager.OnOrderUpdate(posUpdate)
+	}
+	// 后台下单确认的结果（重新下单后的订单ID、确认失败）与订单流推送一样交给仓位管理器
+	exchangeExecutor.SetConfirmHandler(deliverOrderUpdate)
+
+	// 模拟交易（system.dry_run）：下单撤单和挂单查询由模拟成交引擎处理，成交按最新价格模拟
+	var simulator *order.SimulatedFillEngine
+	if cfg.System.DryRun {
+		simulator = order.NewSimulatedFillEngine(deliverOrderUpdate, utils.NewFillModel(
+			time.Duration(exchangeCfg.FillLatencyMs)*time.Millisecond, exchangeCfg.QueueThroughTicks))
+		// 模拟账户以分配资金（未配置时为真实账户的保证金余额）起步，杠杆与真实账户相同
+		_, equity, leverage, err := exchangeAdapter.GetMarginBalance(context.Background(), symbol)
+		if err != nil {
+			priceMonitor.Stop()
+			return nil, fmt.Errorf("查询账户初始化模拟账户失败: %w", err)
+		}
+		if capitalAllocation > 0 {
+			equity = capitalAllocation
+		}
+		simulator.SetAccount(equity, leverage, feeRate)
+		logger.Info("🧪 [模拟交易] 模拟账户: 余额 %.2f, 杠杆 %dx, 手续费率 %.4f%%", equity, leverage, feeRate*100)
+		exchangeExecutor.SetSimulator(simulator)
+		exchangeAdapter.simulator = simulator
+	}
+
+	crash.SetPositionManager(superPositionManager)
+	superPositionManager.SetCapitalAllocation(capitalAllocation)
+
+	run := &symbolRun{
+		cfg:           cfg,
+		symbol:        symbol,
+		ex:            ex,
+		priceMonitor:  priceMonitor,
+		executor:      exchangeExecutor,
+		simulator:     simulator,
+		spm:           superPositionManager,
+		healthMonitor: healthMonitor,
+		riskMonitor:   shared.riskMonitor,
+		rateLimiter:   rateLimiter,
+		report:        report,
+		crash:         crash,
+	}
+
+	// 成交数量语义：配置优先，未配置时使用交易所声明
+	incrementalFills := exchange.ExecutedQtyIsIncremental(ex)
+	switch cfg.Exchanges[cfg.App.CurrentExchange].ExecutedQtyMode {
+	case "incremental":
+		incrementalFills = true
+	case "cumulative":
+		incrementalFills = false
+	}
+	superPositionManager.SetIncrementalFills(incrementalFills)
+	if incrementalFills {
+		logger.Info("ℹ️ 订单推送的成交数量按增量处理")
+	}
+
+	// 运行中盈利复核：网格参数变化后按当前价格重新检查手续费覆盖
+	profitGuard := safety.NewProfitabilityGuard(cfg, ex, superPositionManager, currentPrice)
+	if cfg.Trading.ProfitabilityRecheck.Enabled {
+		superPositionManager.SetGridChangeHandler(profitGuard.Recheck)
+	}
+
+	// 回溯启动前的历史成交（失败不影响启动，仅从零开始统计）
+	if cfg.Trading.TradeHistory.Enabled {
+		lookback := time.Duration(cfg.Trading.TradeHistory.LookbackHours) * time.Hour
+		tradeHistory, err := safety.LoadTradeHistory(context.Background(), ex, symbol, lookback)
+		if err != nil {
+			logger.Warn("⚠️ 回溯历史成交失败，本次从零开始统计: %v", err)
+		} else {
+			superPositionManager.SeedTradeStats(tradeHistory.BuyQty, tradeHistory.SellQty)
+			run.tradeHistory = tradeHistory
+		}
+	}
+
+	// 运行中安全复核（safety.recheck_interval 大于0时生效）：不通过时只暂停新增买单
+	safetyRechecker := safety.NewSafetyRechecker(cfg, ex, superPositionManager, priceMonitor.GetLastPrice, capitalAllocation)
+	// 资金费率风控（risk_control.funding 启用时生效）：资金费率过高时只暂停新增买单
+	fundingMonitor := safety.NewFundingMonitor(cfg, ex)
+	fundingMonitor.SetNotifier(shared.notifier)
+	superPositionManager.SetBuyPauseChecker(func() bool {
+		return safetyRechecker.IsPaused() || fundingMonitor.IsPaused()
+	})
+	superPositionManager.SetMarketPriceSource(priceMonitor.GetLastPrice)
+	drawdownDepth := safety.NewDrawdownDepth(cfg, ex, superPositionManager, capitalAllocation)
+
+	// 成交滑点保护（slippage_guard 启用时生效）
+	slippageGuard := safety.NewSlippageGuard(cfg)
+
+	// 风控监视器由各交易对共用
+	riskMonitor := shared.riskMonitor
+
+	// 低波动集中挂单（low_volatility 启用时生效）：平静行情缩小间隔，把买单集中到当前价格附近
+	lowVolatility := safety.NewLowVolatilityGrid(cfg, riskMonitor, superPositionManager, priceMonitor.GetLastPrice)
+	// 买单深度上限取回撤深度和低波动集中中较小的一个（负数表示不限制）
+	var depthLimiters []func() int
+	if cfg.Trading.DrawdownDepth.Enabled {
+		depthLimiters = append(depthLimiters, drawdownDepth.MaxBuyDepth)
+	}
+	if cfg.Trading.LowVolatility.Enabled && cfg.Trading.LowVolatility.MaxBuyDepth > 0 {
+		depthLimiters = append(depthLimiters, lowVolatility.MaxBuyDepth)
+	}
+	if len(depthLimiters) > 0 {
+		superPositionManager.SetBuyDepthLimiter(func() int {
+			depth := -1
+			for _, limiter := range depthLimiters {
+				if d := limiter(); d >= 0 && (depth < 0 || d < depth) {
+					depth = d
+				}
+			}
+			return depth
+		})
+	}
+
+	// 持仓时长监控（max_hold_seconds 大于0时生效）
+	holdTimeMonitor := safety.NewHoldTimeMonitor(cfg, superPositionManager)
+
+	// 创建手续费率监控器（fee_reprice 启用时生效）
+	feeRateMonitor := safety.NewFeeRateMonitor(cfg, ex)
+	feeRateMonitor.SetReseeder(superPositionManager)
+	symbolInfoMonitor := safety.NewSymbolInfoMonitor(cfg, ex)
+
+	// === 创建对账器（从仓位管理器剖离） ===
+	reconciler := safety.NewReconciler(cfg, exchangeAdapter, superPositionManager)
+	run.reconciler = reconciler
+	// 将风控状态注入到对账器，用于暂停对账日志
+	reconciler.SetPressureSource(rateLimiter)
+	reconciler.SetPauseChecker(func() bool {
+		return riskMonitor.IsTriggered() || healthMonitor.IsPaused() || run.flattened.Load() || run.manualPaused.Load() || run.controlPaused.Load() || profitGuard.IsPaused() ||
+			holdTimeMonitor.IsPaused() || slippageGuard.IsPaused()
+	})
+
+	// 9. 启动组件
+	// 启动失败时停止已启动的价格流和订单流
+	started := false
+	defer func() {
+		if !started {
+			run.stop()
+		}
+	}()
+
+	// 铺设新网格前撤销上次运行遗留的网格订单（在订单流启动前执行，撤单推送不会干扰新网格）
+	if cfg.Trading.CancelOnStart && cfg.System.DryRun {
+		logger.Info("🧪 [模拟交易] 跳过启动撤单，交易所上的挂单保持不变")
+	} else if cfg.Trading.CancelOnStart {
+		cancelCtx, cancelTimeout := context.WithTimeout(ctx, 30*time.Second)
+		if _, err := safety.CancelStaleOrders(cancelCtx, ex, symbol); err != nil {
+			cancelTimeout()
+			return nil, fmt.Errorf("启动撤单失败: %w", err)
+		}
+		cancelTimeout()
+	}
+
+	// 🔥 关键修复：先启动订单流，再下单（避免错过成交推送）
+	// 启动订单流（通过交易所接口）
+	// 架构说明：
+	// - 订单流与价格流共用同一个 WebSocket 连接（对于支持的交易所）
+	// - 订单更新通过回调函数实时推送给该交易对的 SuperPositionManager
+	// - 多交易对共用订单流连接时由 exchange.OrderStreamMux 按交易对分发；账户级订单流（包含其他交易对的订单）在这里再按交易对过滤
+	// - 模拟交易时不订阅账户的真实订单流，订单更新全部来自模拟成交引擎
+	//logger.Info("🔗 启动 WebSocket 订单流...")
+	if simulator != nil {
+		go func() {
+			defer crash.Recover("模拟成交")
+			simulator.Start(ctx, priceMonitor.GetLastPrice, pollInterval)
+		}()
+	} else if err := ex.StartOrderStream(ctx, func(updateInterface interface{}) {
+		defer crash.Recover("订单流")
+		// 适配器推送各自的结构体（兼容匿名结构体），按字段名提取
+		update, _, err := exchange.ToOrderUpdate(updateInterface)
+		if err != nil {
+			logger.Warn("⚠️ [订单流] %v", err)
+			return
+		}
+		deliverOrderUpdate(update)
+	}); err != nil {
+		logger.Warn("⚠️ 启动订单流失败: %v (将继续运行，但订单状态更新可能延迟)", err)
+	} else {
+		logger.Info("✅ [%s] 订单流已启动", ex.GetName())
+	}
+
+	// 初始化超级仓位管理器（设置价格锚点并创建初始槽位）
+	// 注意：必须在订单流启动后再初始化，避免错过买单成交推送
+	if err := superPositionManager.Initialize(currentPrice, currentPriceStr); err != nil {
+		return nil, fmt.Errorf("初始化超级仓位管理器失败: %w", err)
+	}
+
+	report.Start(ctx, superPositionManager)
+	started = true
+
+	// 启动持仓对账（使用独立的 Reconciler）
+	reconciler.Start(ctx)
+
+	// === 创建订单清理器（从仓位管理器剥离） ===
+	orderCleaner := safety.NewOrderCleaner(cfg, exchangeExecutor, superPositionManager)
+	// 启动订单清理协程
+	orderCleaner.Start(ctx)
+	// 启动网格对齐自检（grid_audit 启用时生效）
+	superPositionManager.StartGridAudit(ctx)
+	// 定期导出订单与槽位映射（order_map_file 和 order_map_interval 均设置时生效）
+	superPositionManager.StartOrderMapExport(ctx)
+	// 定期保存仓位状态（system.state_file 设置时生效）
+	superPositionManager.StartStatePersist(ctx)
+	// 启动残余持仓清理（dust_sweep 启用时生效）
+	superPositionManager.StartDustSweep(ctx)
+	// 启动保证金窗口（margin_window 启用时生效）
+	superPositionManager.StartMarginWindow(ctx)
+	superPositionManager.StartFreeMarginGuard(ctx)
+	go holdTimeMonitor.Start(ctx)
+	go safetyRechecker.Start(ctx)
+	go fundingMonitor.Start(ctx)
+	go slippageGuard.Start(ctx)
+	go drawdownDepth.Start(ctx)
+	go lowVolatility.Start(ctx)
+
+	// 启动价格监控（WebSocket 是唯一的价格来源）
+	// 注意：毫秒级量化系统不支持 REST API 轮询，WebSocket 失败时系统将停止
+	go func() {
+		// 检查是否已经在运行
+		if err := priceMonitor.Start(); err != nil {
+			// 忽略"已在运行"的错误
+			if err.Error() != "价格监控已在运行" {
+				logger.Fatalf("❌ 启动价格监控失败（WebSocket 必须可用）: %v", err)
+			}
+		}
+	}()
+
+	// 启动交易所健康监测
+	go healthMonitor.Start(ctx)
+
+	// 启动手续费率监控（费率变化时重新定价卖单）
+	go feeRateMonitor.Start(ctx, superPositionManager.OnFeeRateChanged)
+
+	// 启动交易规则监控（交易所调整精度时按新精度对齐网格）
+	go symbolInfoMonitor.Start(ctx, func(old, updated exchange.SymbolInfo) {
+		superPositionManager.OnPrecisionChanged(updated.PriceDecimals, updated.QuantityDecimals)
+	})
+
+	// 启动自适应价格间隔（按窗口净值表现放大/缩小间隔）
+	adaptiveInterval := safety.NewAdaptiveInterval(cfg, ex, superPositionManager, priceDecimals)
+	go adaptiveInterval.Start(ctx)
+
+	// 启动成交频率自适应间隔（成交过少缩小间隔、过多放大间隔）
+	fillRateInterval := safety.NewFillRateInterval(cfg, superPositionManager, priceMonitor.GetLastPrice)
+	go fillRateInterval.Start(ctx)
+
+	// 启动波动率动态间隔（按最近价格标准差放大/缩小间隔）
+	dynamicInterval := safety.NewDynamicInterval(cfg, superPositionManager, priceMonitor.GetLastPrice)
+	go dynamicInterval.Start(ctx)
+
+	// 状态时间序列（system.status_history_file 设置时生效）
+	go recordStatusHistory(ctx, cfg.System.StatusHistoryFile, cfg.System.StatusHistoryInterval, run.status)
+
+	// 10. 监听价格变化,调整订单窗口（实时调整，不打印价格变化日志）
+	go func() {
+		defer crash.Recover("价格循环")
+		priceCh := priceMonitor.Subscribe()
+		var lastTriggered bool // 记录上一次的风控状态，用于检测状态切换
+		var lastUnprofitable bool
+		var lastHoldPaused bool
+		var lastSafetyFailed bool
+		var lastSlippagePaused bool
+		var lastControlPaused bool
+		var lastFundingPaused bool
+
+		var followUp <-chan time.Time // 上次调整有订单因 max_orders_per_tick 延后时，价格不变也按发送间隔继续补挂
+
+		for {
+			var priceChange monitor.PriceChange
+			select {
+			case change, ok := <-priceCh:
+				if !ok {
+					return
+				}
+				priceChange = change
+			case <-followUp:
+				priceChange = monitor.PriceChange{NewPrice: priceMonitor.GetAnchorPrice(), Timestamp: time.Now()}
+			}
+			followUp = nil
+
+			// === 风控检查：触发时撤销所有买单并暂停交易 ===
+			isTriggered := riskMonitor.IsTriggered()
+
+			if isTriggered {
+				// 检测状态切换：从未触发 -> 触发（首次触发）
+				if !lastTriggered {
+					logger.Warn("🚨 [风控触发] 市场异常，正在撤销所有买单并暂停交易...")
+					superPositionManager.CancelAllBuyOrders(event.OrderReasonRiskCancel) // 🔥 只撤销买单，保留卖单
+					lastTriggered = true
+				}
+				// 风控触发期间跳过后续下单逻辑
+				continue
+			}
+
+			// 检测状态切换：从触发 -> 未触发（风控解除）
+			if lastTriggered {
+				logger.Info("✅ [风控解除] 市场恢复正常，恢复自动交易")
+				lastTriggered = false
+				// 暂停期间锚点未随价格调整，按当前价格重建网格，避免沿用暂停前的旧锚点
+				if cfg.RiskControl.RecoveryAnchor == "reanchor" {
+					superPositionManager.Reanchor(priceChange.NewPrice, "风控解除")
+				} else {
+					superPositionManager.SetPlacementReason(event.OrderReasonRecovery)
+				}
+			}
+
+			// 交易所服务端故障期间暂停挂单（保留现有订单，暂停/恢复日志由健康监测器输出）
+			if healthMonitor.IsPaused() {
+				continue
+			}
+
+			// 紧急平仓后暂停挂单，直到手动恢复
+			if run.flattened.Load() {
+				continue
+			}
+
+			// 终端快捷键手动暂停挂单（保留现有订单）
+			if run.manualPaused.Load() {
+				continue
+			}
+
+			// 控制接口暂停时撤销买单并暂停挂单，直到 resume（保留卖单）
+			if run.controlPaused.Load() {
+				if !lastControlPaused {
+					superPositionManager.CancelAllBuyOrders(event.OrderReasonControlPause)
+					lastControlPaused = true
+				}
+				continue
+			}
+			if lastControlPaused {
+				superPositionManager.SetPlacementReason(event.OrderReasonRecovery)
+			}
+			lastControlPaused = false
+
+			// 盈利复核失败时撤销买单并暂停挂单（暂停/恢复日志由盈利复核器输出）
+			profitGuard.OnPrice(priceChange.NewPrice)
+			if profitGuard.IsPaused() {
+				if !lastUnprofitable {
+					superPositionManager.CancelAllBuyOrders(event.OrderReasonProfitGuard)
+					lastUnprofitable = true
+				}
+				continue
+			}
+			if lastUnprofitable {
+				superPositionManager.SetPlacementReason(event.OrderReasonRecovery)
+			}
+			lastUnprofitable = false
+
+			// 超时平仓后暂停挂单到下一个交易时段（撤销买单，避免暂停期间继续建仓）
+			if holdTimeMonitor.IsPaused() {
+				if !lastHoldPaused {
+					superPositionManager.CancelAllBuyOrders(event.OrderReasonHoldTime)
+					lastHoldPaused = true
+				}
+				continue
+			}
+			if lastHoldPaused {
+				superPositionManager.SetPlacementReason(event.OrderReasonRecovery)
+			}
+			lastHoldPaused = false
+
+			// 成交滑点持续过大时撤销买单并暂停挂单，冷却后自动恢复（暂停/恢复日志由滑点保护输出）
+			if slippageGuard.IsPaused() {
+				if !lastSlippagePaused {
+					superPositionManager.CancelAllBuyOrders(event.OrderReasonSlippage)
+					lastSlippagePaused = true
+				}
+				continue
+			}
+			if lastSlippagePaused {
+				superPositionManager.SetPlacementReason(event.OrderReasonRecovery)
+			}
+			lastSlippagePaused = false
+
+			// 安全复核未通过时撤销买单，之后仍继续调整订单以挂出卖单（AdjustOrders 内部跳过新增买单）
+			if safetyRechecker.IsPaused() {
+				if !lastSafetyFailed {
+					superPositionManager.CancelAllBuyOrders(event.OrderReasonSafetyCheck)
+					lastSafetyFailed = true
+				}
+			} else {
+				if lastSafetyFailed {
+					superPositionManager.SetPlacementReason(event.OrderReasonRecovery)
+				}
+				lastSafetyFailed = false
+			}
+
+			// 资金费率过高时同样只撤销买单，继续调整订单以挂出卖单（暂停/恢复日志由资金费率风控输出）
+			if fundingMonitor.IsPaused() {
+				if !lastFundingPaused {
+					superPositionManager.CancelAllBuyOrders(event.OrderReasonFunding)
+					lastFundingPaused = true
+				}
+			} else {
+				if lastFundingPaused {
+					superPositionManager.SetPlacementReason(event.OrderReasonRecovery)
+				}
+				lastFundingPaused = false
+			}
+
+			// 实时调整订单，不打印价格变化日志（避免日志过多）
+			if err := superPositionManager.AdjustOrders(priceChange.NewPrice); err != nil {
+				logger.Error("❌ 调整订单失败: %v", err)
+			}
+			if superPositionManager.HasDeferredOrders() {
+				followUp = time.After(time.Duration(cfg.Timing.PriceSendInterval) * time.Millisecond)
+			}
+		}
+	}()
+
+	return run, nil
+}
+
+// ownsOrderUpdate 订单推送是否属于该交易对（账户级订单流会推送其他交易对的订单，未携带交易对的推送按本交易对处理）
+func ownsOrderUpdate(updateSymbol, symbol string) bool {
+	if updateSymbol == "" {
+		return true
+	}
+	return exchange.NormalizeSymbol(updateSymbol) == exchange.NormalizeSymbol(symbol)
+}
+
+// status 运行状态（管理接口和状态时间序列共用）
+func (r *symbolRun) status() runtimeStatus {
+	return runtimeStatus{
+		StatusSnapshot: r.spm.GetStatusSnapshot(),
+		Exchange:       r.ex.GetName(),
+		MarketPrice:    r.priceMonitor.GetLastPrice(),
+		RiskTriggered:  r.riskMonitor.IsTriggered(),
+		ExchangePaused: r.healthMonitor.IsPaused(),
+		Flattened:      r.flattened.Load(),
+		RateLimits:     r.rateLimiter.Levels(),
+		WSEndpoint:     exchange.GetActiveWSEndpoint(r.ex),
+	}
+}
+
+// drain 停止接受新的下单请求，并等待进行中的下单返回
+func (r *symbolRun) drain() {
+	drainPlacements(r.executor, time.Duration(r.cfg.System.ShutdownDrainTimeout)*time.Second)
+}
+
+// cancelOrders 撤销该交易对的所有订单（tag 为日志前缀，如 止盈退出）
+func (r *symbolRun) cancelOrders(ctx context.Context, tag string) {
+	if err := cancelAllOrders(ctx, r.ex, r.executor, r.symbol, event.OrderReasonExit); err != nil {
+		logger.Error("❌ [%s] 撤销订单失败: %v", tag, err)
+	} else {
+		logger.Info("✅ [%s] 所有订单已撤销", tag)
+	}
+}
+
+// closePositions 市价平掉该交易对的所有持仓（tag 为日志前缀）
+func (r *symbolRun) closePositions(tag string) {
+	if r.simulator != nil {
+		closed := r.simulator.ClosePosition()
+		logger.Info("🧪 [%s] 模拟交易按最新价格平掉模拟持仓 %.4f，交易所上的持仓保持不变", tag, closed)
+		return
+	}
+	if err := closeAllPositionsMarket(r.ex, r.symbol); err != nil {
+		logger.Error("❌ [%s] 平仓失败: %v", tag, err)
+	} else {
+		logger.Info("✅ [%s] 所有持仓已平仓", tag)
+	}
+}
+
+// flatten 紧急平仓：撤单 + 市价平仓，之后暂停挂单直到 resume
+func (r *symbolRun) flatten(source string) {
+	r.flattenMu.Lock()
+	defer r.flattenMu.Unlock()
+
+	// 先暂停挂单，避免价格协程在平仓过程中继续下单
+	r.flattened.Store(true)
+	logger.Warn("🆘🆘🆘 [紧急平仓] 收到%s，立即撤销 %s 的所有订单并市价平仓（进程保持运行）", source, r.symbol)
+
+	cancelCtx, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelTimeout()
+	r.cancelOrders(cancelCtx, "紧急平仓")
+	r.closePositions("紧急平仓")
+
+	// 再撤一次：清理暂停前已在途的下单请求
+	if err := cancelAllOrders(cancelCtx, r.ex, r.executor, r.symbol, event.OrderReasonExit); err != nil {
+		logger.Error("❌ [紧急平仓] 二次撤单失败: %v", err)
+	}
+	r.spm.ResetAllSlots()
+
+	logger.Warn("🆘🆘🆘 [紧急平仓] 已完成，挂单已暂停；发送 SIGUSR2 或 POST /resume 恢复自动交易")
+}
+
+// resume 解除紧急平仓后的挂单暂停
+func (r *symbolRun) resume(source string) {
+	if !r.flattened.CompareAndSwap(true, false) {
+		logger.Info("ℹ️ [紧急平仓] 收到%s，但 %s 当前未处于紧急平仓暂停状态", source, r.symbol)
+		return
+	}
+	logger.Info("▶️ [紧急平仓] 收到%s，恢复 %s 的自动交易", source, r.symbol)
+}
+
+// stop 停止该交易对的价格流和订单流
+func (r *symbolRun) stop() {
+	r.priceMonitor.Stop()
+	r.ex.StopOrderStream()
+}
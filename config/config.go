@@ -2,7 +2,12 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -18,32 +23,274 @@ type Config struct {
 	Exchanges map[string]ExchangeConfig `yaml:"exchanges"`
 
 	Trading struct {
-		Symbol                string  `yaml:"symbol"`
-		PriceInterval         float64 `yaml:"price_interval"`
-		OrderQuantity         float64 `yaml:"order_quantity"`  // 每单购买金额（USDT/USDC）
-		MinOrderValue         float64 `yaml:"min_order_value"` // 最小订单价值（USDT），默认6U，小于此值不挂单
-		BuyWindowSize         int     `yaml:"buy_window_size"`
+		Symbol            string  `yaml:"symbol"`
+		PriceInterval     float64 `yaml:"price_interval"`
+		OrderQuantity     float64 `yaml:"order_quantity"`  // 每单购买金额（USDT/USDC）
+		MinOrderValue     float64 `yaml:"min_order_value"` // 用户设定的最小订单价值（USDT），小于此值不挂单；与交易所最小下单金额取较大者生效
+		BuyWindowSize     int     `yaml:"buy_window_size"`
+		TargetUtilization float64 `yaml:"target_utilization"` // 目标资金使用率（百分比，>0 时按余额自动计算 buy_window_size）
+		// 买卖两侧分别设置间隔（为0时使用 price_interval）：buy_price_interval 为买单网格间隔（替代 price_interval），
+		// sell_price_interval 为卖单相对买入槽位的价格差
+		BuyPriceInterval  float64 `yaml:"buy_price_interval"`
+		SellPriceInterval float64 `yaml:"sell_price_interval"`
+		// 网格密度：grid_levels 层买单覆盖当前价格下方 grid_range_percent% 的价格区间（设置后替代 price_interval 和 buy_window_size）
+		GridRangePercent      float64 `yaml:"grid_range_percent"`
+		GridLevels            int     `yaml:"grid_levels"`
 		SellWindowSize        int     `yaml:"sell_window_size"` // 卖单窗口大小
+		LadderMode            bool    `yaml:"ladder_mode"`      // 阶梯模式：买单固定在启动锚点下方，不随价格上移（不重置锚点、不调整价格间隔）
+		CancelOnStart         bool    `yaml:"cancel_on_start"`  // 启动铺网前撤销上次运行遗留的网格订单
 		ReconcileInterval     int     `yaml:"reconcile_interval"`
+		ReconcileGuard        bool    `yaml:"reconcile_guard"`              // 对账读取订单集合期间暂停新增挂单，避免双方看到的订单不一致
+		ReconcileGracePeriod  int     `yaml:"reconcile_grace_period"`       // 下单后多久内不在交易所挂单列表中不计为缺失（秒，默认10）
 		OrderCleanupThreshold int     `yaml:"order_cleanup_threshold"`      // 订单清理上限（默认100）
 		CleanupBatchSize      int     `yaml:"cleanup_batch_size"`           // 清理批次大小（默认10）
 		MarginLockDurationSec int     `yaml:"margin_lock_duration_seconds"` // 保证金锁定时间（秒，默认10）
 		PositionSafetyCheck   int     `yaml:"position_safety_check"`        // 持仓安全性检查（默认100，最少能向下持有多少仓）
 		MaxLeverage           int     `yaml:"max_leverage"`                 // 最大允许杠杆倍数（默认10）
+		PreseedMarginCheck    bool    `yaml:"preseed_margin_check"`         // 初始挂单前预估保证金，不足时只挂最近的若干层（默认false）
+		// 低价层名义价值低于 min_order_value 时自动上调数量（否则跳过该层）
+		MinNotionalAutoRaise     bool    `yaml:"min_notional_auto_raise"`
+		MinNotionalMaxMultiplier float64 `yaml:"min_notional_max_multiplier"` // 上调后金额不超过 order_quantity 的倍数（默认1.5）
+		// 比较名义价值与最小订单价值时使用的计价币精度（小数位数，默认8）；两者按此精度取整后再比较，避免浮点误差把恰好等于最小值的订单判为不足
+		QuoteDecimals int `yaml:"quote_decimals"`
+		// 每单数量按数量精度取整的方式：floor 向下取整（名义价值不超过 order_quantity，默认）/ round 四舍五入
+		QuantityRounding string `yaml:"quantity_rounding"`
+		// 卖单成交后输出该轮（买入 -> 卖出）的实现盈亏：卖出金额 - 买入成本 - 手续费
+		LogRoundTrips bool `yaml:"log_round_trips"`
+		// 订单推送的处理顺序：update_time 按订单的 UpdateTime 单调处理，忽略迟到的旧推送和订单结束后的推送（默认）/ arrival 按到达顺序处理
+		OrderUpdateOrdering string `yaml:"order_update_ordering"`
+		// 最近一档偏移：最近的买单至少低于当前价格、卖单至少高于当前价格该距离（ticks 个最小价格单位与 percent% 取较大者，0 不启用）
+		FirstLevelOffset struct {
+			Ticks   int     `yaml:"ticks"`
+			Percent float64 `yaml:"percent"`
+		} `yaml:"first_level_offset"`
+		// 分配给该交易对的资金：0 不限制，(0,1] 按账户总余额比例，>1 为绝对金额（计价币种）
+		CapitalAllocation float64 `yaml:"capital_allocation"`
+		// 多交易对：同一进程内为每个交易对运行独立的网格（留空时只交易 symbol），symbol 为主交易对（留空时取第一个）
+		Symbols []string `yaml:"symbols"`
+		// 按交易对覆盖价格间隔、订单金额和资金分配（未设置的字段沿用上面的配置）
+		SymbolOverrides map[string]SymbolOverride `yaml:"symbol_overrides"`
+		// 挂单锚定价格来源：last（最新成交价）/ mid（盘口中间价）/ microprice（按挂单量加权的中间价）
+		AnchorSource      string `yaml:"anchor_source"`
+		DepthPollInterval int    `yaml:"depth_poll_interval"` // 盘口深度轮询间隔（毫秒，默认1000，仅 mid/microprice 生效）
+		// 最长持仓时间：槽位持仓超过该时长后市价平掉（0 不限制），max_hold_pause_until 为平仓后暂停挂单到的本地时间 HH:MM（留空不暂停）
+		MaxHoldSeconds    int    `yaml:"max_hold_seconds"`
+		MaxHoldPauseUntil string `yaml:"max_hold_pause_until"`
 		// 注意：price_decimals 和 quantity_decimals 已废弃，现在从交易所自动获取
 
 		// 自动止盈配置
 		TakeProfit struct {
 			Enabled       bool    `yaml:"enabled"`        // 是否启用止盈
 			TargetProfit  float64 `yaml:"target_profit"`  // 止盈目标金额（USDT）
+			TargetPct     float64 `yaml:"target_pct"`     // 止盈目标收益率（初始余额的百分比，与 target_profit 二选一）
 			CheckInterval int     `yaml:"check_interval"` // 检查间隔（秒）
 			BalanceMode   string  `yaml:"balance_mode"`   // 余额模式：auto/precise
+			BalanceSource string  `yaml:"balance_source"` // 盈利计算使用的余额：margin/wallet/available/marked/auto（默认auto）
+			// balance_source 为 marked 时持仓的保守估值：bid 多仓按买一价（默认）/ mark 按交易所标记价格 / loss_only 只计浮亏不计浮盈
+			InventoryMark       string  `yaml:"inventory_mark"`
+			InventoryHaircutPct float64 `yaml:"inventory_haircut_pct"` // 估值价格再向不利方向折让的百分比（默认0）
+			// 止盈触发后挂交易所原生追踪止损代替市价平仓（交易所不支持时回退为市价平仓）
+			UseNativeTrailing   bool    `yaml:"use_native_trailing"`
+			TrailingCallbackPct float64 `yaml:"trailing_callback_pct"` // 追踪止损回撤比例（百分比，默认1）
+			// 启动时获取初始余额失败的重试次数（默认3，退避 1s、2s、4s…）
+			InitBalanceRetries int `yaml:"init_balance_retries"`
 		} `yaml:"take_profit"`
+
+		// 自动止损配置（账户净值相对初始余额亏损达到 max_loss_usdt 或 max_loss_pct 时撤单、市价平仓并退出）
+		StopLoss struct {
+			Enabled       bool    `yaml:"enabled"`        // 是否启用止损
+			MaxLossUSDT   float64 `yaml:"max_loss_usdt"`  // 最大亏损金额（USDT，0 不按金额止损）
+			MaxLossPct    float64 `yaml:"max_loss_pct"`   // 最大亏损比例（初始余额的百分比，0 不按比例止损）
+			CheckInterval int     `yaml:"check_interval"` // 检查间隔（秒，默认30）
+		} `yaml:"stop_loss"`
+
+		// 启动时回溯历史成交：把本进程启动前的成交计入累计统计和止盈基准
+		TradeHistory struct {
+			Enabled       bool `yaml:"enabled"`        // 是否启用（默认false）
+			LookbackHours int  `yaml:"lookback_hours"` // 回溯时长（小时，默认24）
+		} `yaml:"trade_history"`
+
+		// 手续费变化后重新定价卖单（依赖交易所支持实时费率查询）
+		FeeReprice struct {
+			Enabled          bool    `yaml:"enabled"`            // 是否启用（默认false）
+			CheckInterval    int     `yaml:"check_interval"`     // 费率查询间隔（秒，默认300）
+			MinMarginPercent float64 `yaml:"min_margin_percent"` // 卖单在保本价之上至少保留的利润（百分比，默认0.02）
+
+			// 费率明显变化后按新费率重新计算价格间隔并重铺网格
+			ReseedGrid struct {
+				Enabled             bool    `yaml:"enabled"`               // 是否启用（默认false）
+				MinChangePercent    float64 `yaml:"min_change_percent"`    // 费率相对上次铺网时变化超过多少百分比才重铺（默认10）
+				TargetMarginPercent float64 `yaml:"target_margin_percent"` // 新间隔在保本之上保留的利润（百分比，默认0.02）
+				MinInterval         float64 `yaml:"min_interval"`          // 新间隔下限（默认0 不限制）
+				MaxInventoryLevels  int     `yaml:"max_inventory_levels"`  // 持仓层数不超过多少时才重铺（默认2）
+			} `yaml:"reseed_grid"`
+		} `yaml:"fee_reprice"`
+
+		// 运行中刷新合约信息（交易所调整价格精度/数量精度/最小下单金额后按新规则下单）
+		SymbolInfoRefresh struct {
+			Enabled  bool `yaml:"enabled"`   // 是否启用（默认false）
+			CacheTTL int  `yaml:"cache_ttl"` // 合约信息缓存有效期，过期后重新获取（秒，默认3600）
+		} `yaml:"symbol_info_refresh"`
+
+		// 外部资金变动检测：钱包余额变化中无法由成交盈亏和手续费解释的部分视为外部变动（充值/提现等）
+		ExternalBalanceCheck struct {
+			Enabled       bool    `yaml:"enabled"`        // 是否启用（默认false）
+			CheckInterval int     `yaml:"check_interval"` // 检查间隔（秒，默认60）
+			Threshold     float64 `yaml:"threshold"`      // 未解释变动超过该金额视为外部变动（计价币种，默认10）
+			Rebaseline    bool    `yaml:"rebaseline"`     // 检测到外部变动时调整止盈/止损初始余额（默认false 只记录告警）
+		} `yaml:"external_balance_check"`
+
+		// 运行中盈利复核：网格参数变化或价格大幅变动后按当前价格重新做手续费盈利检查
+		ProfitabilityRecheck struct {
+			Enabled     bool    `yaml:"enabled"`      // 是否启用（默认false）
+			MovePercent float64 `yaml:"move_percent"` // 价格相对上次复核偏离多少百分比时复核（默认10）
+		} `yaml:"profitability_recheck"`
+
+		// 网格对齐自检：定期比对挂单价格与理论网格，撤销偏离的挂单后按理论价格重新挂出
+		GridAudit struct {
+			Enabled        bool `yaml:"enabled"`         // 是否启用（默认false）
+			CheckInterval  int  `yaml:"check_interval"`  // 自检间隔（秒，默认300）
+			ToleranceTicks int  `yaml:"tolerance_ticks"` // 允许偏离的最小价格单位数（默认0，偏离一个最小价格单位即重新对齐）
+		} `yaml:"grid_audit"`
+
+		// 库存偏斜：净持仓超过目标库存时，新增买单整体下移、卖单向当前价格收紧，使库存回归目标
+		InventorySkew struct {
+			Enabled         bool    `yaml:"enabled"`          // 是否启用（默认false）
+			TargetInventory float64 `yaml:"target_inventory"` // 目标库存（基础币数量，默认0），净持仓不超过该值时不偏斜
+			MaxInventory    float64 `yaml:"max_inventory"`    // 达到最大偏斜的库存（基础币数量，必须大于 target_inventory）
+			SkewStrength    float64 `yaml:"skew_strength"`    // 最大偏斜距离（价格间隔的倍数，默认1）
+		} `yaml:"inventory_skew"`
+
+		// 成交滑点保护：最近成交的平均逆向滑点持续超过阈值时暂停挂单，冷却后恢复
+		SlippageGuard struct {
+			Enabled         bool    `yaml:"enabled"`          // 是否启用（默认false）
+			MaxSlippageBps  float64 `yaml:"max_slippage_bps"` // 平均逆向滑点阈值（基点，默认5）
+			Window          int     `yaml:"window"`           // 统计最近多少笔成交（默认20）
+			MinFills        int     `yaml:"min_fills"`        // 至少多少笔成交才参与判断（默认5）
+			CooldownSeconds int     `yaml:"cooldown_seconds"` // 暂停时长（秒，默认300）
+		} `yaml:"slippage_guard"`
+
+		// 下单确认：下单成功后等待订单流推送确认订单存在，超时未收到时通过 REST 查询，查不到时重新下单（只对配置的方向生效，市价单不确认）
+		PlacementConfirm struct {
+			Sides       []string `yaml:"sides"`        // 需要确认的方向：BUY / SELL（默认空，不确认）
+			DelayMs     int      `yaml:"delay_ms"`     // 下单后最多等待订单流推送多久再查询（毫秒，默认500）
+			MaxReplaces int      `yaml:"max_replaces"` // 查不到时最多重新下单次数（默认1）
+		} `yaml:"placement_confirm"`
+
+		// 回撤限深：浮动亏损占资金的比例越大，买单窗口越浅，回撤收窄后恢复
+		DrawdownDepth struct {
+			Enabled       bool    `yaml:"enabled"`        // 是否启用（默认false）
+			CheckInterval int     `yaml:"check_interval"` // 浮亏查询间隔（秒，默认30）
+			StartPercent  float64 `yaml:"start_percent"`  // 浮亏超过资金的多少百分比开始缩减深度（默认3）
+			StopPercent   float64 `yaml:"stop_percent"`   // 浮亏达到多少百分比时缩减到最小深度（默认10）
+			MinDepth      int     `yaml:"min_depth"`      // 最小买单层数（默认0，即停止新增买单）
+		} `yaml:"drawdown_depth"`
+
+		// 残余持仓清理：名义价值低于阈值、无法通过网格卖单卖出的槽位持仓
+		DustSweep struct {
+			Enabled       bool    `yaml:"enabled"`        // 是否启用（默认false）
+			CheckInterval int     `yaml:"check_interval"` // 检查间隔（秒，默认600）
+			MaxNotional   float64 `yaml:"max_notional"`   // 名义价值低于多少视为残余（默认0，取生效的最小下单金额）
+			Action        string  `yaml:"action"`         // market（合并后只减仓市价卖出）/ flag（只记录，默认market）
+		} `yaml:"dust_sweep"`
+
+		// 保证金窗口：可用保证金低于缓冲时按比例缩小买单窗口，卖单成交释放保证金后逐步恢复
+		MarginWindow struct {
+			Enabled          bool    `yaml:"enabled"`            // 是否启用（默认false）
+			CheckInterval    int     `yaml:"check_interval"`     // 可用保证金查询间隔（秒，默认30）
+			FreeMarginBuffer float64 `yaml:"free_margin_buffer"` // 可用保证金缓冲（计价币种，默认0 表示两个完整买单窗口占用的保证金）
+			MinDepth         int     `yaml:"min_depth"`          // 最小买单层数（默认1）
+		} `yaml:"margin_window"`
+
+		// 可用保证金下限：新增买单会让 可用保证金 / 保证金余额 低于该百分比时不再新增买单（默认0 不限制）
+		MinFreeMarginPercent    float64 `yaml:"min_free_margin_percent"`
+		FreeMarginCheckInterval int     `yaml:"free_margin_check_interval"` // 可用保证金查询间隔（秒，默认10）
+
+		// 低波动集中挂单：风控K线的平均振幅低时缩小价格间隔，把买单集中到当前价格附近，波动回升后恢复
+		LowVolatility struct {
+			Enabled       bool    `yaml:"enabled"`        // 是否启用（默认false）
+			CheckInterval int     `yaml:"check_interval"` // 检查间隔（秒，默认60）
+			CalmPercent   float64 `yaml:"calm_percent"`   // 平均K线振幅低于多少百分比时缩小间隔（必填）
+			NormalPercent float64 `yaml:"normal_percent"` // 平均K线振幅高于多少百分比时恢复（默认 calm_percent 的2倍）
+			IntervalRatio float64 `yaml:"interval_ratio"` // 缩小后的间隔 = 原间隔 × 比例（0~1，默认0.5）
+			MaxBuyDepth   int     `yaml:"max_buy_depth"`  // 低波动期间最多挂出的买单层数（默认0 不限制）
+		} `yaml:"low_volatility"`
+
+		// 自适应价格间隔：连续多个统计窗口有成交但净值下降时放大间隔，连续盈利时逐步恢复
+		AdaptiveInterval struct {
+			Enabled       bool    `yaml:"enabled"`         // 是否启用（默认false）
+			WindowMinutes int     `yaml:"window_minutes"`  // 统计窗口（分钟，默认60）
+			LossWindows   int     `yaml:"loss_windows"`    // 连续亏损多少个窗口后放大间隔（默认3）
+			ProfitWindows int     `yaml:"profit_windows"`  // 连续盈利多少个窗口后缩小间隔（默认3）
+			MinRoundTrips int     `yaml:"min_round_trips"` // 窗口内至少完成多少次卖出才参与评估（默认1）
+			StepPercent   float64 `yaml:"step_percent"`    // 每次调整幅度（百分比，默认25）
+			MaxInterval   float64 `yaml:"max_interval"`    // 间隔上限（默认 price_interval 的2倍），下限为 price_interval
+		} `yaml:"adaptive_interval"`
+
+		// 成交频率自适应间隔：窗口内成交过少时缩小间隔，成交过多（频繁换手）时放大间隔
+		FillRateInterval struct {
+			Enabled       bool    `yaml:"enabled"`        // 是否启用（默认false）
+			WindowMinutes int     `yaml:"window_minutes"` // 统计窗口（分钟，默认30）
+			MinFills      int     `yaml:"min_fills"`      // 窗口内完全成交少于多少笔时缩小间隔（默认2）
+			MaxFills      int     `yaml:"max_fills"`      // 窗口内完全成交多于多少笔时放大间隔（默认20）
+			StepPercent   float64 `yaml:"step_percent"`   // 每次调整幅度（百分比，默认10）
+			MinInterval   float64 `yaml:"min_interval"`   // 间隔下限（默认 price_interval 的一半，且每笔净利润需为正）
+			MaxInterval   float64 `yaml:"max_interval"`   // 间隔上限（默认 price_interval 的2倍）
+		} `yaml:"fill_rate_interval"`
+
+		// 波动率动态间隔：按最近价格样本的标准差计算价格间隔，剧烈行情放大、平静行情缩小
+		DynamicInterval struct {
+			Enabled          bool    `yaml:"enabled"`            // 是否启用（默认false）
+			SampleInterval   int     `yaml:"sample_interval"`    // 价格采样间隔（秒，默认10）
+			Window           int     `yaml:"window"`             // 计算标准差的样本数（默认60）
+			Multiplier       float64 `yaml:"multiplier"`         // 间隔 = 价格标准差 × multiplier（默认1）
+			MinInterval      float64 `yaml:"min_interval"`       // 间隔下限（默认 price_interval 的一半，启动安全检查按下限核算盈利）
+			MaxInterval      float64 `yaml:"max_interval"`       // 间隔上限（默认 price_interval 的3倍）
+			MinChangePercent float64 `yaml:"min_change_percent"` // 目标间隔与当前间隔相差超过多少百分比才调整（默认20）
+		} `yaml:"dynamic_interval"`
 	} `yaml:"trading"`
 
 	System struct {
-		LogLevel     string `yaml:"log_level"`
-		CancelOnExit bool   `yaml:"cancel_on_exit"`
+		LogLevel         string            `yaml:"log_level"`
+		LogLevels        map[string]string `yaml:"log_levels"`         // 组件级别覆盖，如 {reconciler: debug, position: info}
+		LogFormat        string            `yaml:"log_format"`         // 输出格式：text（默认）/ json（每行一个 JSON 对象）
+		LogRetentionDays int               `yaml:"log_retention_days"` // 日志文件保留天数（默认0 不清理）
+		CancelOnExit     bool              `yaml:"cancel_on_exit"`
+		// 写入日志文件（与控制台日志级别无关）：false 时沿用旧行为，仅 DEBUG 级别写入文件
+		LogToFile    bool   `yaml:"log_to_file"`
+		FileLogLevel string `yaml:"file_log_level"` // 文件日志级别（默认与 log_level 相同）
+		// 撤销全部订单后仍查询到未完成订单时的重试次数（默认2）
+		CancelAllRetries int `yaml:"cancel_all_retries"`
+		// 交易所不支持一键撤销全部订单时，改为查询未完成订单后分批撤销，每批的订单数（默认10）
+		CancelBatchSize int `yaml:"cancel_batch_size"`
+		// 退出排空：撤销全部订单前停止接受新的下单请求，并最多等待该时长让进行中的下单返回（秒，默认5）
+		ShutdownDrainTimeout int `yaml:"shutdown_drain_timeout"`
+		// 收到 SIGUSR1 时撤销所有订单并市价平仓，进程保持运行并暂停挂单，SIGUSR2 恢复
+		EmergencyFlatten bool `yaml:"emergency_flatten"`
+		// 终端交互：q+回车 紧急平仓并退出，p+回车 暂停/恢复挂单（标准输入不是终端时自动关闭）
+		Interactive bool `yaml:"interactive"`
+		// 订单与槽位映射导出（排查槽位记账问题时与交易所挂单比对）：为空不导出
+		OrderMapFile     string `yaml:"order_map_file"`
+		OrderMapInterval int    `yaml:"order_map_interval"` // 定期导出间隔（秒，默认0 只通过管理接口按需导出）
+		// 状态时间序列（JSON Lines，按间隔追加一行状态，用于离线绘图分析）：为空不记录
+		StatusHistoryFile     string `yaml:"status_history_file"`
+		StatusHistoryInterval int    `yaml:"status_history_interval"` // 记录间隔（秒，默认60）
+		// 订单审计记录（JSON Lines，每次下单/撤单追加一行，含动作原因）：为空不记录
+		OrderAuditFile string `yaml:"order_audit_file"`
+		// 运行汇总（正常退出时写入 JSON：运行时长、初始/最终余额、已实现盈亏、轮次、最大持仓、重连和风控触发次数）：为空不写入
+		FinalReportFile string `yaml:"final_report_file"`
+		// 崩溃现场（致命错误或未恢复的 panic 退出前写入 JSON：仓位快照与网格槽位、挂单、持仓、账户）：为空不写入
+		CrashDumpFile string `yaml:"crash_dump_file"`
+		// Prometheus 指标接口监听地址（如 127.0.0.1:9100，提供 GET /metrics）：为空不启动
+		MetricsAddr string `yaml:"metrics_addr"`
+		// 仓位状态文件（锚点和有持仓/挂单的槽位，重启时核对交易所挂单和持仓后恢复）：为空不保存
+		StateFile         string `yaml:"state_file"`
+		StateSaveInterval int    `yaml:"state_save_interval"` // 定期保存间隔（秒，默认5）
+		StateMaxAge       int    `yaml:"state_max_age"`       // 状态文件的最长有效期（秒，默认300），过期则从持仓重建
+		// 本地控制接口 Unix socket 路径（pause / resume / status 命令）：为空不启动
+		ControlSocket string `yaml:"control_socket"`
+		// 模拟交易：下单撤单不发送到交易所，由模拟成交引擎按最新价格撮合；行情、账户、持仓仍查询真实交易所
+		DryRun bool `yaml:"dry_run"`
 	} `yaml:"system"`
 
 	// 主动安全风控配置
@@ -54,8 +301,65 @@ type Config struct {
 		VolumeMultiplier  float64  `yaml:"volume_multiplier"`  // 成交量倍数阈值，默认3.0
 		AverageWindow     int      `yaml:"average_window"`     // 移动平均窗口大小，默认20
 		RecoveryThreshold int      `yaml:"recovery_threshold"` // 恢复交易所需的正常币种数量，默认3
+		RecordFile        string   `yaml:"record_file"`        // 评估记录文件（JSON Lines，为空不保存）
+		// 均线状态文件：定期保存各币种的K线窗口，重启时直接恢复（为空不保存，启动时通过 REST 回填）
+		StateFile   string `yaml:"state_file"`
+		StateMaxAge int    `yaml:"state_max_age"` // 状态文件的最长有效期（秒，默认0 = 3根K线周期），过期则回填
+		// 监控币种 -> 交易所K线使用的交易对名称（交易所上的名称与 monitor_symbols 不同时配置，如 {BTCUSDT: XBTUSDTM}）
+		SymbolAliases map[string]string `yaml:"symbol_aliases"`
+		// 风控解除后的网格锚点：reanchor 以当前价格重置锚点后重建买单窗口（默认）/ keep 保持暂停前的锚点
+		RecoveryAnchor string `yaml:"recovery_anchor"`
+
+		// 交易对加权：自动把 trading.symbol 加入监控币种，并提高其异常信号的权重（其他币种权重为1）
+		// 加权异常分数达到 trigger_score 时触发，交易对自身的异动在大盘平稳时也能暂停网格
+		IncludeTradedSymbol bool    `yaml:"include_traded_symbol"` // 自动加入交易对（默认false）
+		TradedSymbolWeight  float64 `yaml:"traded_symbol_weight"`  // 交易对权重（默认1，与其他币种相同）
+		TriggerScore        float64 `yaml:"trigger_score"`         // 触发所需的加权异常分数（默认0 = 总权重，即全部币种异常）
+
+		// 资金费率风控：定期查询交易对的资金费率，超过 max_funding_rate 时撤销买单并暂停新增买单，回落后自动恢复
+		// 网格只做多，资金费率为正时多头持续支付资金费，高费率期间继续建仓会侵蚀网格利润
+		Funding struct {
+			Enabled        bool    `yaml:"enabled"`          // 是否启用（默认false，与K线风控相互独立）
+			MaxFundingRate float64 `yaml:"max_funding_rate"` // 资金费率上限（如 0.0005 表示 0.05%）
+			CheckInterval  int     `yaml:"check_interval"`   // 查询间隔（秒，默认300）
+		} `yaml:"funding"`
 	} `yaml:"risk_control"`
 
+	// 交易所健康监测配置（持续出现5xx服务端错误时暂停挂单）
+	ExchangeHealth struct {
+		Enabled         bool `yaml:"enabled"`          // 是否启用健康监测
+		ErrorThreshold  int  `yaml:"error_threshold"`  // 统计窗口内触发暂停的5xx错误次数（默认5）
+		ErrorWindow     int  `yaml:"error_window"`     // 错误统计窗口（秒，默认60）
+		RecheckInterval int  `yaml:"recheck_interval"` // 暂停期间健康检查间隔（秒，默认15）
+	} `yaml:"exchange_health"`
+
+	// 运行中安全复核配置（定期按最新账户数据重新执行启动时的持仓与盈利检查）
+	Safety struct {
+		RecheckInterval  int  `yaml:"recheck_interval"`   // 复核间隔（秒，默认0 不复核）
+		VerifyReduceOnly bool `yaml:"verify_reduce_only"` // 启动时挂无持仓的只减仓卖单验证只减仓生效（默认false）
+		// 除网格必需的 post_only、reduce_only 外，额外要求交易所支持的能力（ioc / native_stop），不支持时拒绝启动
+		RequiredCapabilities []string `yaml:"required_capabilities"`
+
+		// 余额读数合理性检查：交易所异常时账户查询可能短暂返回0或明显错误的余额，
+		// 启用后止盈检查、安全复核和外部资金监控跳过异常读数，而不是据此误判盈亏或暂停买单
+		BalanceSanity struct {
+			Enabled          bool    `yaml:"enabled"`            // 是否启用（默认false）
+			MaxChangePercent float64 `yaml:"max_change_percent"` // 相对上次有效读数的单次变化超过该百分比视为异常（默认50）
+			ConfirmReadings  int     `yaml:"confirm_readings"`   // 连续多少次异常读数后接受为新基准（真实的大额资金变动，默认3）
+		} `yaml:"balance_sanity"`
+	} `yaml:"safety"`
+
+	// 管理接口配置（HTTP 状态查询 + SSE 事件推送）
+	Admin struct {
+		Enabled          bool   `yaml:"enabled"`           // 是否启用管理接口（默认false）
+		Listen           string `yaml:"listen"`            // 监听地址（默认 127.0.0.1:8090）
+		Token            string `yaml:"token"`             // 访问令牌（为空则不校验，建议仅监听本机）
+		SnapshotInterval int    `yaml:"snapshot_interval"` // 状态快照推送间隔（秒，默认5）
+	} `yaml:"admin"`
+
+	// 告警通知（风控触发/解除、止盈止损、下单失败、程序退出时推送），可同时配置多个
+	Notify []NotifierConfig `yaml:"notify"`
+
 	// 时间间隔配置（单位：秒，除非特别说明）
 	Timing struct {
 		// WebSocket相关
@@ -74,15 +378,86 @@ type Config struct {
 		PricePollInterval    int `yaml:"price_poll_interval"`    // 等待获取价格的轮询间隔（毫秒，默认500）
 		StatusPrintInterval  int `yaml:"status_print_interval"`  // 定期打印状态的间隔（分钟，默认1）
 		OrderCleanupInterval int `yaml:"order_cleanup_interval"` // 订单清理检查间隔（秒，默认60）
+		// 单次调整订单最多新增的订单数（默认0，不限制）；超出部分优先保留离价格最近的订单，其余延后到下次调整
+		MaxOrdersPerTick int `yaml:"max_orders_per_tick"`
+
+		// 限流桶：下单/撤单与订单查询分开计数，可选按交易对独立计数
+		RateLimits struct {
+			Order struct {
+				Rate  float64 `yaml:"rate"`  // 每秒请求数（默认25）
+				Burst int     `yaml:"burst"` // 突发容量（默认30）
+			} `yaml:"order"`
+			Query struct {
+				Rate  float64 `yaml:"rate"`  // 每秒请求数（默认10）
+				Burst int     `yaml:"burst"` // 突发容量（默认20）
+			} `yaml:"query"`
+			PerSymbol struct {
+				Rate  float64 `yaml:"rate"`  // 每个交易对每秒请求数（默认0，不按交易对限流）
+				Burst int     `yaml:"burst"` // 突发容量（默认等于 rate 向上取整）
+			} `yaml:"per_symbol"`
+		} `yaml:"rate_limits"`
+
+		// 监控轮询自适应：下单配额紧张时拉长止盈/对账/外部资金检查的轮询间隔
+		AdaptivePolling struct {
+			Enabled           bool    `yaml:"enabled"`            // 是否启用（默认false）
+			PressureThreshold float64 `yaml:"pressure_threshold"` // 下单限流桶已用比例超过多少百分比视为压力大（默认70）
+			MaxMultiplier     float64 `yaml:"max_multiplier"`     // 轮询间隔最多放大到配置值的多少倍（默认4）
+		} `yaml:"adaptive_polling"`
 	} `yaml:"timing"`
 }
 
+// SymbolOverride 单个交易对的参数覆盖（trading.symbol_overrides，0 表示沿用 trading 中的配置）
+type SymbolOverride struct {
+	PriceInterval     float64 `yaml:"price_interval"`
+	OrderQuantity     float64 `yaml:"order_quantity"`
+	CapitalAllocation float64 `yaml:"capital_allocation"`
+}
+
+// NotifierConfig 告警通知配置（notify 列表中的一项）
+type NotifierConfig struct {
+	Type string `yaml:"type"` // webhook / telegram
+
+	// webhook：POST JSON 到 url（Slack、Discord 的 incoming webhook 或自定义接口）
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"` // 附加请求头（如 Authorization）
+
+	// telegram：通过机器人推送到会话
+	BotToken string `yaml:"bot_token"`
+	ChatID   string `yaml:"chat_id"` // 接收告警的会话ID（用户、群组或频道）
+}
+
 // ExchangeConfig 交易所配置
 type ExchangeConfig struct {
 	APIKey     string  `yaml:"api_key"`
 	SecretKey  string  `yaml:"secret_key"`
-	Passphrase string  `yaml:"passphrase"` // Bitget 需要
+	Passphrase string  `yaml:"passphrase"` // Bitget、OKX 需要
 	FeeRate    float64 `yaml:"fee_rate"`   // 手续费率（例如 0.0002 表示 0.02%）
+	BaseURL    string  `yaml:"base_url"`   // REST 接口地址覆盖（留空使用官方地址；mock 留空则启动进程内模拟交易所）
+
+	// WebSocket 基础地址列表（scheme://host，留空使用官方地址）：当前地址连续连接失败 ws_failover_after 次后切换到下一个
+	WSEndpoints     []string `yaml:"ws_endpoints"`
+	WSFailoverAfter int      `yaml:"ws_failover_after"` // 连续失败多少次切换（默认3）
+
+	// 只读密钥（可选）：账户、余额、持仓、挂单查询使用只读密钥，下单撤单使用上面的交易密钥
+	ReadOnlyAPIKey     string `yaml:"read_only_api_key"`
+	ReadOnlySecretKey  string `yaml:"read_only_secret_key"`
+	ReadOnlyPassphrase string `yaml:"read_only_passphrase"` // Bitget、OKX 需要
+
+	// 模拟成交延迟和排队（使模拟盘结果更接近实盘）：进程内 mock 和 system.dry_run 的模拟成交引擎生效
+	FillLatencyMs     int `yaml:"fill_latency_ms"`     // 挂单满足成交条件后延迟多久成交（毫秒，默认0）
+	QueueThroughTicks int `yaml:"queue_through_ticks"` // 价格需穿过挂单价多少个最小价格单位才成交，近似排队位置（默认0 触价即成交）
+	// 以下仅 mock 生效
+	ActionCooldownMs int `yaml:"action_cooldown_ms"` // 同一交易对相邻下单/撤单的最小间隔，不足时拒绝请求（毫秒，默认0 不限制）
+	// 模拟的资金费率（仅供 risk_control.funding 查询，不实际结算；运行中可通过 POST /api/v1/funding 修改）
+	FundingRate float64 `yaml:"funding_rate"`
+
+	// 同一交易对相邻下单/撤单的最小间隔（毫秒），执行器会主动拉开操作间隔
+	// 0 使用交易所适配器声明的间隔（未声明则不限制），大于0时覆盖适配器声明
+	MinActionIntervalMs int `yaml:"min_action_interval_ms"`
+
+	// 订单推送中成交数量的语义：cumulative（累计成交数量）/ incremental（本次新增成交数量）
+	// 留空使用交易所适配器的声明；mock 设置为 incremental 时模拟增量推送
+	ExecutedQtyMode string `yaml:"executed_qty_mode"`
 }
 
 // LoadConfig 加载配置文件
@@ -121,35 +496,143 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("交易所 %s 的配置不存在", c.App.CurrentExchange)
 	}
 
-	if exchangeCfg.APIKey == "" || exchangeCfg.SecretKey == "" {
+	// 模拟交易所不需要 API 密钥
+	if c.App.CurrentExchange != "mock" && (exchangeCfg.APIKey == "" || exchangeCfg.SecretKey == "") {
 		return fmt.Errorf("交易所 %s 的 API 配置不完整", c.App.CurrentExchange)
 	}
+	// Bitget、OKX 的 API 密钥需要 passphrase
+	needPassphrase := c.App.CurrentExchange == "bitget" || c.App.CurrentExchange == "okx"
+	if needPassphrase && exchangeCfg.Passphrase == "" {
+		return fmt.Errorf("交易所 %s 的 API 配置不完整（缺少 passphrase）", c.App.CurrentExchange)
+	}
+	if exchangeCfg.ReadOnlyAPIKey != "" {
+		if exchangeCfg.ReadOnlySecretKey == "" {
+			return fmt.Errorf("交易所 %s 的只读密钥配置不完整（缺少 read_only_secret_key）", c.App.CurrentExchange)
+		}
+		if needPassphrase && exchangeCfg.ReadOnlyPassphrase == "" {
+			return fmt.Errorf("交易所 %s 的只读密钥配置不完整（缺少 read_only_passphrase）", c.App.CurrentExchange)
+		}
+		if exchangeCfg.ReadOnlyAPIKey == exchangeCfg.APIKey {
+			return fmt.Errorf("交易所 %s 的 read_only_api_key 与 api_key 相同，请使用单独创建的只读密钥", c.App.CurrentExchange)
+		}
+	}
+	if exchangeCfg.FillLatencyMs < 0 || exchangeCfg.QueueThroughTicks < 0 {
+		return fmt.Errorf("交易所 %s 的 fill_latency_ms 和 queue_through_ticks 不能为负数", c.App.CurrentExchange)
+	}
 
 	// 验证手续费率配置
 	if exchangeCfg.FeeRate < 0 {
 		return fmt.Errorf("交易所 %s 的手续费率不能为负数", c.App.CurrentExchange)
 	}
 
+	switch exchangeCfg.ExecutedQtyMode {
+	case "", "cumulative", "incremental":
+	default:
+		return fmt.Errorf("交易所 %s 的 executed_qty_mode 必须是 cumulative 或 incremental", c.App.CurrentExchange)
+	}
+
+	if err := c.normalizeSymbols(); err != nil {
+		return err
+	}
 	if c.Trading.Symbol == "" {
 		return fmt.Errorf("交易对不能为空")
 	}
 	if c.Trading.OrderQuantity <= 0 {
 		return fmt.Errorf("订单金额必须大于0")
 	}
-	if c.Trading.BuyWindowSize <= 0 {
-		return fmt.Errorf("买单窗口大小必须大于0")
+	if c.Trading.TargetUtilization < 0 || c.Trading.TargetUtilization > 100 {
+		return fmt.Errorf("目标资金使用率 (target_utilization) 必须在 0-100 之间")
+	}
+	gridMode := c.Trading.GridRangePercent > 0 || c.Trading.GridLevels > 0
+	if c.Trading.BuyPriceInterval < 0 || c.Trading.SellPriceInterval < 0 {
+		return fmt.Errorf("buy_price_interval 和 sell_price_interval 不能为负数")
 	}
-	if c.Trading.SellWindowSize <= 0 {
-		c.Trading.SellWindowSize = c.Trading.BuyWindowSize // 默认与买单窗口相同
+	if c.Trading.BuyPriceInterval > 0 || c.Trading.SellPriceInterval > 0 {
+		if gridMode {
+			return fmt.Errorf("buy_price_interval / sell_price_interval 不能与 grid_levels 同时使用（网格密度模式的间隔在启动时按价格换算）")
+		}
+		if c.Trading.BuyPriceInterval > 0 {
+			c.Trading.PriceInterval = c.Trading.BuyPriceInterval
+		}
+		if c.Trading.PriceInterval <= 0 {
+			return fmt.Errorf("设置 sell_price_interval 时必须同时设置 price_interval 或 buy_price_interval")
+		}
+	}
+	if gridMode {
+		if c.Trading.GridRangePercent <= 0 || c.Trading.GridRangePercent >= 100 {
+			return fmt.Errorf("网格区间 (grid_range_percent) 必须在 0-100 之间（不含）")
+		}
+		if c.Trading.GridLevels <= 0 {
+			return fmt.Errorf("网格层数 (grid_levels) 必须大于0")
+		}
+		if c.Trading.TargetUtilization > 0 {
+			return fmt.Errorf("grid_levels 与 target_utilization 都会决定买单窗口，只能设置其中一个")
+		}
+		// 价格间隔依赖当前价格，启动时由 ApplyPriceInterval 设置
+		c.Trading.BuyWindowSize = c.Trading.GridLevels
+		if c.Trading.SellWindowSize <= 0 {
+			c.Trading.SellWindowSize = c.Trading.GridLevels
+		}
+	}
+	// 设置了目标资金使用率时，买单窗口在启动时按余额计算（卖单窗口未设置时同样随之确定）
+	if c.Trading.TargetUtilization == 0 && !gridMode {
+		if c.Trading.BuyWindowSize <= 0 {
+			return fmt.Errorf("买单窗口大小必须大于0")
+		}
+		if c.Trading.SellWindowSize <= 0 {
+			c.Trading.SellWindowSize = c.Trading.BuyWindowSize // 默认与买单窗口相同
+		}
 	}
 	if c.Trading.CleanupBatchSize <= 0 {
 		c.Trading.CleanupBatchSize = 10 // 默认10
 	}
+	if c.Trading.ReconcileGracePeriod < 0 {
+		return fmt.Errorf("reconcile_grace_period 不能为负数")
+	}
+	if c.Trading.ReconcileGracePeriod == 0 {
+		c.Trading.ReconcileGracePeriod = 10 // 默认10秒
+	}
+	// 最小价格单位依赖交易所精度，偏移与价格间隔的比较在启动时由持仓管理器完成
+	if offset := c.Trading.FirstLevelOffset; offset.Ticks < 0 || offset.Percent < 0 {
+		return fmt.Errorf("first_level_offset 不能为负数")
+	} else if offset.Percent >= 50 {
+		return fmt.Errorf("first_level_offset.percent 必须小于50")
+	} else if c.Trading.GridRangePercent > 0 && c.Trading.GridLevels > 0 &&
+		2*offset.Percent >= c.Trading.GridRangePercent/float64(c.Trading.GridLevels) {
+		return fmt.Errorf("first_level_offset.percent (%.4f%%) 的两倍必须小于网格间隔 (%.4f%%)，否则刚成交的买单无法挂出卖单",
+			offset.Percent, c.Trading.GridRangePercent/float64(c.Trading.GridLevels))
+	}
 	// 注意：price_decimals 和 quantity_decimals 已从配置中移除，现在从交易所自动获取
 	if c.Trading.MinOrderValue <= 0 {
 		c.Trading.MinOrderValue = 20.0 // 默认6U (币安通常最小5U)
 	}
 
+	if c.Trading.MinNotionalMaxMultiplier <= 0 {
+		c.Trading.MinNotionalMaxMultiplier = 1.5 // 默认1.5倍
+	} else if c.Trading.MinNotionalMaxMultiplier < 1 {
+		return fmt.Errorf("最小名义价值上调倍数不能小于1")
+	}
+	if c.Trading.QuoteDecimals < 0 {
+		return fmt.Errorf("trading.quote_decimals 不能为负数")
+	} else if c.Trading.QuoteDecimals == 0 {
+		c.Trading.QuoteDecimals = 8 // 默认8位小数
+	}
+
+	switch c.Trading.QuantityRounding {
+	case "":
+		c.Trading.QuantityRounding = "floor"
+	case "floor", "round":
+	default:
+		return fmt.Errorf("quantity_rounding 必须是 floor 或 round，当前: %s", c.Trading.QuantityRounding)
+	}
+	switch c.Trading.OrderUpdateOrdering {
+	case "":
+		c.Trading.OrderUpdateOrdering = "update_time"
+	case "update_time", "arrival":
+	default:
+		return fmt.Errorf("order_update_ordering 必须是 update_time 或 arrival，当前: %s", c.Trading.OrderUpdateOrdering)
+	}
+
 	if c.Trading.MaxLeverage <= 0 {
 		c.Trading.MaxLeverage = 10 // 默认10倍
 	}
@@ -176,6 +659,37 @@ func (c *Config) Validate() error {
 	if c.Timing.RateLimitRetryDelay <= 0 {
 		c.Timing.RateLimitRetryDelay = 1 // 默认1秒
 	}
+	limits := &c.Timing.RateLimits
+	if limits.Order.Rate <= 0 {
+		limits.Order.Rate = 25 // 默认25单/秒
+	}
+	if limits.Order.Burst <= 0 {
+		limits.Order.Burst = 30 // 默认突发30
+	}
+	if limits.Query.Rate <= 0 {
+		limits.Query.Rate = 10 // 默认10次/秒
+	}
+	if limits.Query.Burst <= 0 {
+		limits.Query.Burst = 20 // 默认突发20
+	}
+	if limits.PerSymbol.Rate < 0 {
+		return fmt.Errorf("timing.rate_limits.per_symbol.rate 不能为负数")
+	}
+	if limits.PerSymbol.Rate > 0 && limits.PerSymbol.Burst <= 0 {
+		limits.PerSymbol.Burst = int(math.Ceil(limits.PerSymbol.Rate))
+	}
+	if c.Timing.AdaptivePolling.PressureThreshold <= 0 {
+		c.Timing.AdaptivePolling.PressureThreshold = 70 // 默认70%
+	}
+	if c.Timing.AdaptivePolling.PressureThreshold > 100 {
+		return fmt.Errorf("timing.adaptive_polling.pressure_threshold 必须在 0-100 之间")
+	}
+	if c.Timing.AdaptivePolling.MaxMultiplier <= 0 {
+		c.Timing.AdaptivePolling.MaxMultiplier = 4 // 默认4倍
+	}
+	if c.Timing.AdaptivePolling.MaxMultiplier < 1 {
+		return fmt.Errorf("timing.adaptive_polling.max_multiplier 不能小于1")
+	}
 	if c.Timing.OrderRetryDelay <= 0 {
 		c.Timing.OrderRetryDelay = 500 // 默认500毫秒
 	}
@@ -188,11 +702,17 @@ func (c *Config) Validate() error {
 	if c.Timing.OrderCleanupInterval <= 0 {
 		c.Timing.OrderCleanupInterval = 60 // 默认60秒
 	}
+	if c.Timing.MaxOrdersPerTick < 0 {
+		return fmt.Errorf("timing.max_orders_per_tick 不能为负数")
+	}
 
 	// 验证风控配置并设置默认值
 	if c.RiskControl.Interval == "" {
 		c.RiskControl.Interval = "1m" // 默认1分钟
 	}
+	if c.RiskControl.StateMaxAge < 0 {
+		return fmt.Errorf("risk_control.state_max_age 不能为负数")
+	}
 	if c.RiskControl.VolumeMultiplier <= 0 {
 		c.RiskControl.VolumeMultiplier = 3.0 // 默认3倍
 	}
@@ -202,6 +722,48 @@ func (c *Config) Validate() error {
 	if len(c.RiskControl.MonitorSymbols) == 0 {
 		c.RiskControl.MonitorSymbols = []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "XRPUSDT", "DOGEUSDT"}
 	}
+	if c.RiskControl.IncludeTradedSymbol {
+		// 多交易对时所有交易对都加入监控
+		for _, traded := range c.Trading.Symbols {
+			if !containsSymbol(c.RiskControl.MonitorSymbols, traded) {
+				c.RiskControl.MonitorSymbols = append(c.RiskControl.MonitorSymbols, traded)
+			}
+		}
+	}
+	tradedMonitored := containsSymbol(c.RiskControl.MonitorSymbols, c.Trading.Symbol)
+	if c.RiskControl.TradedSymbolWeight < 0 {
+		return fmt.Errorf("risk_control.traded_symbol_weight 不能为负数")
+	}
+	if c.RiskControl.TradedSymbolWeight == 0 {
+		c.RiskControl.TradedSymbolWeight = 1 // 默认与其他币种相同
+	}
+	if c.RiskControl.TriggerScore < 0 {
+		return fmt.Errorf("risk_control.trigger_score 不能为负数")
+	}
+	totalWeight := float64(len(c.RiskControl.MonitorSymbols))
+	if tradedMonitored {
+		totalWeight += c.RiskControl.TradedSymbolWeight - 1
+	}
+	if c.RiskControl.TriggerScore > totalWeight {
+		return fmt.Errorf("risk_control.trigger_score %.2f 超过监控币种的总权重 %.2f，风控永远不会触发",
+			c.RiskControl.TriggerScore, totalWeight)
+	}
+
+	for symbol, native := range c.RiskControl.SymbolAliases {
+		monitored := false
+		for _, s := range c.RiskControl.MonitorSymbols {
+			if s == symbol {
+				monitored = true
+				break
+			}
+		}
+		if !monitored {
+			return fmt.Errorf("risk_control.symbol_aliases 中的 %s 不在 monitor_symbols 中", symbol)
+		}
+		if strings.TrimSpace(native) == "" {
+			return fmt.Errorf("risk_control.symbol_aliases.%s 不能为空", symbol)
+		}
+	}
 
 	// 验证恢复阈值配置
 	monitorCount := len(c.RiskControl.MonitorSymbols)
@@ -212,11 +774,430 @@ func (c *Config) Validate() error {
 	} else if c.RiskControl.RecoveryThreshold > monitorCount {
 		c.RiskControl.RecoveryThreshold = monitorCount // 最大为监控币种数量
 	}
+	switch c.RiskControl.RecoveryAnchor {
+	case "":
+		c.RiskControl.RecoveryAnchor = "reanchor"
+	case "reanchor", "keep":
+	default:
+		return fmt.Errorf("risk_control.recovery_anchor 必须是 reanchor 或 keep，当前: %s", c.RiskControl.RecoveryAnchor)
+	}
+	if funding := &c.RiskControl.Funding; funding.Enabled {
+		if funding.MaxFundingRate <= 0 {
+			return fmt.Errorf("risk_control.funding.max_funding_rate 必须大于0")
+		}
+		if funding.CheckInterval <= 0 {
+			funding.CheckInterval = 300 // 默认300秒
+		}
+	}
 
+	// 交易所健康监测默认值
+	if c.ExchangeHealth.ErrorThreshold <= 0 {
+		c.ExchangeHealth.ErrorThreshold = 5 // 默认5次
+	}
+	if c.ExchangeHealth.ErrorWindow <= 0 {
+		c.ExchangeHealth.ErrorWindow = 60 // 默认60秒
+	}
+	if c.ExchangeHealth.RecheckInterval <= 0 {
+		c.ExchangeHealth.RecheckInterval = 15 // 默认15秒
+	}
+
+	switch c.System.LogFormat {
+	case "":
+		c.System.LogFormat = "text"
+	case "text", "json":
+	default:
+		return fmt.Errorf("system.log_format 必须是 text 或 json，当前: %s", c.System.LogFormat)
+	}
+	if c.System.CancelAllRetries < 0 {
+		return fmt.Errorf("system.cancel_all_retries 不能为负数")
+	}
+	if c.System.CancelAllRetries == 0 {
+		c.System.CancelAllRetries = 2 // 默认重试2次
+	}
+	if c.System.CancelBatchSize < 0 {
+		return fmt.Errorf("system.cancel_batch_size 不能为负数")
+	}
+	if c.System.CancelBatchSize == 0 {
+		c.System.CancelBatchSize = 10 // 默认每批10个（币安批量撤单上限）
+	}
+	if c.System.ShutdownDrainTimeout < 0 {
+		return fmt.Errorf("system.shutdown_drain_timeout 不能为负数")
+	}
+	if c.System.ShutdownDrainTimeout == 0 {
+		c.System.ShutdownDrainTimeout = 5 // 默认5秒
+	}
+	if c.System.OrderMapInterval < 0 {
+		return fmt.Errorf("system.order_map_interval 不能为负数")
+	}
+	if c.System.StatusHistoryInterval < 0 {
+		return fmt.Errorf("system.status_history_interval 不能为负数")
+	}
+	if c.System.StatusHistoryInterval == 0 {
+		c.System.StatusHistoryInterval = 60 // 默认60秒
+	}
+	if c.System.StateSaveInterval < 0 {
+		return fmt.Errorf("system.state_save_interval 不能为负数")
+	}
+	if c.System.StateSaveInterval == 0 {
+		c.System.StateSaveInterval = 5 // 默认5秒
+	}
+	if c.System.StateMaxAge < 0 {
+		return fmt.Errorf("system.state_max_age 不能为负数")
+	}
+	if c.System.StateMaxAge == 0 {
+		c.System.StateMaxAge = 300 // 默认5分钟
+	}
+
+	if c.Safety.RecheckInterval < 0 {
+		return fmt.Errorf("safety.recheck_interval 不能为负数")
+	}
+	for _, name := range c.Safety.RequiredCapabilities {
+		switch name {
+		case "post_only", "reduce_only", "ioc", "native_stop":
+		default:
+			return fmt.Errorf("safety.required_capabilities 包含未知能力 %q（可选 post_only / reduce_only / ioc / native_stop）", name)
+		}
+	}
+	if c.Safety.BalanceSanity.MaxChangePercent < 0 {
+		return fmt.Errorf("safety.balance_sanity.max_change_percent 不能为负数")
+	}
+	if c.Safety.BalanceSanity.MaxChangePercent == 0 {
+		c.Safety.BalanceSanity.MaxChangePercent = 50 // 默认50%
+	}
+	if c.Safety.BalanceSanity.ConfirmReadings < 0 {
+		return fmt.Errorf("safety.balance_sanity.confirm_readings 不能为负数")
+	}
+	if c.Safety.BalanceSanity.ConfirmReadings == 0 {
+		c.Safety.BalanceSanity.ConfirmReadings = 3 // 默认3次
+	}
+
+	if c.Admin.Listen == "" {
+		c.Admin.Listen = "127.0.0.1:8090" // 默认仅本机访问
+	}
+	if c.Admin.SnapshotInterval <= 0 {
+		c.Admin.SnapshotInterval = 5 // 默认5秒
+	}
+
+	for i, n := range c.Notify {
+		switch n.Type {
+		case "webhook":
+			if n.URL == "" {
+				return fmt.Errorf("notify[%d] (webhook) 必须设置 url", i)
+			}
+		case "telegram":
+			if n.BotToken == "" || n.ChatID == "" {
+				return fmt.Errorf("notify[%d] (telegram) 必须同时设置 bot_token 和 chat_id", i)
+			}
+		default:
+			return fmt.Errorf("notify[%d].type 必须是 webhook 或 telegram，当前: %s", i, n.Type)
+		}
+	}
+
+	if c.Trading.CapitalAllocation < 0 {
+		return fmt.Errorf("资金分配 (capital_allocation) 不能为负数")
+	}
+	if c.System.LogRetentionDays < 0 {
+		return fmt.Errorf("日志保留天数不能为负数")
+	}
+	switch c.Trading.AnchorSource {
+	case "":
+		c.Trading.AnchorSource = "last" // 默认使用最新成交价
+	case "last", "mid", "microprice":
+	default:
+		return fmt.Errorf("不支持的锚定价格来源: %s（可选 last/mid/microprice）", c.Trading.AnchorSource)
+	}
+	if c.Trading.DepthPollInterval <= 0 {
+		c.Trading.DepthPollInterval = 1000 // 默认1秒
+	}
+	if c.Trading.AdaptiveInterval.WindowMinutes <= 0 {
+		c.Trading.AdaptiveInterval.WindowMinutes = 60 // 默认1小时
+	}
+	if c.Trading.AdaptiveInterval.LossWindows <= 0 {
+		c.Trading.AdaptiveInterval.LossWindows = 3
+	}
+	if c.Trading.AdaptiveInterval.ProfitWindows <= 0 {
+		c.Trading.AdaptiveInterval.ProfitWindows = 3
+	}
+	if c.Trading.AdaptiveInterval.MinRoundTrips <= 0 {
+		c.Trading.AdaptiveInterval.MinRoundTrips = 1
+	}
+	if c.Trading.AdaptiveInterval.StepPercent <= 0 {
+		c.Trading.AdaptiveInterval.StepPercent = 25
+	}
+	if fillRate := &c.Trading.FillRateInterval; fillRate.Enabled {
+		if fillRate.WindowMinutes <= 0 {
+			fillRate.WindowMinutes = 30 // 默认30分钟
+		}
+		if fillRate.MinFills < 0 {
+			return fmt.Errorf("fill_rate_interval.min_fills 不能为负数")
+		}
+		if fillRate.MinFills == 0 {
+			fillRate.MinFills = 2
+		}
+		if fillRate.MaxFills == 0 {
+			fillRate.MaxFills = 20
+		}
+		if fillRate.MaxFills <= fillRate.MinFills {
+			return fmt.Errorf("fill_rate_interval.max_fills 必须大于 min_fills")
+		}
+		if fillRate.StepPercent < 0 {
+			return fmt.Errorf("fill_rate_interval.step_percent 不能为负数")
+		}
+		if fillRate.StepPercent == 0 {
+			fillRate.StepPercent = 10
+		}
+		if fillRate.MinInterval < 0 || fillRate.MaxInterval < 0 {
+			return fmt.Errorf("fill_rate_interval 的间隔范围不能为负数")
+		}
+		if c.Trading.AdaptiveInterval.Enabled || c.Trading.LowVolatility.Enabled {
+			return fmt.Errorf("fill_rate_interval 与 adaptive_interval、low_volatility 都会调整价格间隔，只能启用其中一个")
+		}
+	}
+	if dynamic := &c.Trading.DynamicInterval; dynamic.Enabled {
+		if dynamic.SampleInterval < 0 || dynamic.Window < 0 || dynamic.Multiplier < 0 || dynamic.MinChangePercent < 0 {
+			return fmt.Errorf("dynamic_interval 的参数不能为负数")
+		}
+		if dynamic.SampleInterval == 0 {
+			dynamic.SampleInterval = 10 // 默认10秒
+		}
+		if dynamic.Window == 0 {
+			dynamic.Window = 60 // 默认60个样本
+		}
+		if dynamic.Window < 2 {
+			return fmt.Errorf("dynamic_interval.window 至少为2")
+		}
+		if dynamic.Multiplier == 0 {
+			dynamic.Multiplier = 1
+		}
+		if dynamic.MinChangePercent == 0 {
+			dynamic.MinChangePercent = 20
+		}
+		if dynamic.MinInterval < 0 || dynamic.MaxInterval < 0 {
+			return fmt.Errorf("dynamic_interval 的间隔范围不能为负数")
+		}
+		if c.Trading.AdaptiveInterval.Enabled || c.Trading.LowVolatility.Enabled || c.Trading.FillRateInterval.Enabled {
+			return fmt.Errorf("dynamic_interval 与 adaptive_interval、low_volatility、fill_rate_interval 都会调整价格间隔，只能启用其中一个")
+		}
+	}
+	// 阶梯模式的买单阶梯固定不动，自动调整价格间隔会重塑阶梯
+	if c.Trading.LadderMode && (c.Trading.DynamicInterval.Enabled || c.Trading.AdaptiveInterval.Enabled ||
+		c.Trading.LowVolatility.Enabled || c.Trading.FillRateInterval.Enabled) {
+		return fmt.Errorf("ladder_mode 固定买单阶梯，不能与调整价格间隔的 dynamic_interval、adaptive_interval、low_volatility、fill_rate_interval 同时启用")
+	}
+	// 多交易对时价格间隔可按交易对覆盖，启动时由 ForSymbol 分别设置，这里逐个检查
+	if !gridMode && len(c.Trading.Symbols) == 1 {
+		if err := c.ApplyPriceInterval(c.Trading.PriceInterval); err != nil {
+			return err
+		}
+	} else if !gridMode {
+		for _, symbol := range c.Trading.Symbols {
+			if _, err := c.ForSymbol(symbol); err != nil {
+				return err
+			}
+		}
+	}
+	if c.Trading.TradeHistory.LookbackHours <= 0 {
+		c.Trading.TradeHistory.LookbackHours = 24 // 默认回溯1天
+	}
+	if c.Trading.FeeReprice.CheckInterval <= 0 {
+		c.Trading.FeeReprice.CheckInterval = 300 // 默认5分钟
+	}
+	if c.Trading.FeeReprice.MinMarginPercent <= 0 {
+		c.Trading.FeeReprice.MinMarginPercent = 0.02 // 默认0.02%
+	}
+	reseed := &c.Trading.FeeReprice.ReseedGrid
+	if reseed.MinChangePercent <= 0 {
+		reseed.MinChangePercent = 10 // 默认10%
+	}
+	if reseed.TargetMarginPercent <= 0 {
+		reseed.TargetMarginPercent = 0.02 // 默认0.02%
+	}
+	if reseed.MinInterval < 0 {
+		return fmt.Errorf("fee_reprice.reseed_grid.min_interval 不能为负数")
+	}
+	if reseed.MaxInventoryLevels < 0 {
+		return fmt.Errorf("fee_reprice.reseed_grid.max_inventory_levels 不能为负数")
+	} else if reseed.MaxInventoryLevels == 0 {
+		reseed.MaxInventoryLevels = 2 // 默认2层
+	}
+	if c.Trading.SymbolInfoRefresh.CacheTTL <= 0 {
+		c.Trading.SymbolInfoRefresh.CacheTTL = 3600 // 默认1小时
+	}
+	if c.Trading.ExternalBalanceCheck.CheckInterval <= 0 {
+		c.Trading.ExternalBalanceCheck.CheckInterval = 60 // 默认1分钟
+	}
+	if c.Trading.ExternalBalanceCheck.Threshold <= 0 {
+		c.Trading.ExternalBalanceCheck.Threshold = 10 // 默认10
+	}
+	if c.Trading.ProfitabilityRecheck.MovePercent <= 0 {
+		c.Trading.ProfitabilityRecheck.MovePercent = 10 // 默认10%
+	}
+	if c.Trading.MaxHoldSeconds < 0 {
+		return fmt.Errorf("max_hold_seconds 不能为负数")
+	}
+	if c.Trading.MaxHoldPauseUntil != "" {
+		if _, err := time.Parse("15:04", c.Trading.MaxHoldPauseUntil); err != nil {
+			return fmt.Errorf("max_hold_pause_until 格式错误（应为 HH:MM）: %s", c.Trading.MaxHoldPauseUntil)
+		}
+	}
+	if c.Trading.GridAudit.CheckInterval <= 0 {
+		c.Trading.GridAudit.CheckInterval = 300 // 默认300秒
+	}
+	if c.Trading.GridAudit.ToleranceTicks < 0 {
+		return fmt.Errorf("grid_audit.tolerance_ticks 不能为负数")
+	}
+	if skew := &c.Trading.InventorySkew; skew.Enabled {
+		if skew.TargetInventory < 0 {
+			return fmt.Errorf("inventory_skew.target_inventory 不能为负数")
+		}
+		if skew.MaxInventory <= skew.TargetInventory {
+			return fmt.Errorf("inventory_skew.max_inventory (%v) 必须大于 target_inventory (%v)", skew.MaxInventory, skew.TargetInventory)
+		}
+		if skew.SkewStrength < 0 {
+			return fmt.Errorf("inventory_skew.skew_strength 不能为负数")
+		}
+		if skew.SkewStrength == 0 {
+			skew.SkewStrength = 1 // 默认最多偏斜一个价格间隔
+		}
+	}
+	if c.Trading.SlippageGuard.MaxSlippageBps <= 0 {
+		c.Trading.SlippageGuard.MaxSlippageBps = 5 // 默认5个基点
+	}
+	if c.Trading.SlippageGuard.Window <= 0 {
+		c.Trading.SlippageGuard.Window = 20 // 默认最近20笔
+	}
+	if c.Trading.SlippageGuard.MinFills <= 0 {
+		c.Trading.SlippageGuard.MinFills = 5 // 默认5笔
+	}
+	if c.Trading.SlippageGuard.MinFills > c.Trading.SlippageGuard.Window {
+		c.Trading.SlippageGuard.MinFills = c.Trading.SlippageGuard.Window
+	}
+	if c.Trading.SlippageGuard.CooldownSeconds <= 0 {
+		c.Trading.SlippageGuard.CooldownSeconds = 300 // 默认300秒
+	}
+	if c.Trading.DrawdownDepth.CheckInterval <= 0 {
+		c.Trading.DrawdownDepth.CheckInterval = 30 // 默认30秒
+	}
+	if c.Trading.DrawdownDepth.StartPercent <= 0 {
+		c.Trading.DrawdownDepth.StartPercent = 3 // 默认3%
+	}
+	if c.Trading.DrawdownDepth.StopPercent <= 0 {
+		c.Trading.DrawdownDepth.StopPercent = 10 // 默认10%
+	}
+	if c.Trading.DrawdownDepth.StopPercent <= c.Trading.DrawdownDepth.StartPercent {
+		return fmt.Errorf("drawdown_depth.stop_percent 必须大于 start_percent")
+	}
+	if c.Trading.DrawdownDepth.MinDepth < 0 {
+		return fmt.Errorf("drawdown_depth.min_depth 不能为负数")
+	}
+	if lowVol := &c.Trading.LowVolatility; lowVol.Enabled {
+		if lowVol.CheckInterval <= 0 {
+			lowVol.CheckInterval = 60 // 默认60秒
+		}
+		if lowVol.CalmPercent <= 0 {
+			return fmt.Errorf("low_volatility.calm_percent 必须大于0")
+		}
+		if lowVol.NormalPercent == 0 {
+			lowVol.NormalPercent = lowVol.CalmPercent * 2
+		}
+		if lowVol.NormalPercent <= lowVol.CalmPercent {
+			return fmt.Errorf("low_volatility.normal_percent 必须大于 calm_percent")
+		}
+		if lowVol.IntervalRatio == 0 {
+			lowVol.IntervalRatio = 0.5
+		}
+		if lowVol.IntervalRatio <= 0 || lowVol.IntervalRatio >= 1 {
+			return fmt.Errorf("low_volatility.interval_ratio 必须在0到1之间")
+		}
+		if lowVol.MaxBuyDepth < 0 {
+			return fmt.Errorf("low_volatility.max_buy_depth 不能为负数")
+		}
+		// 波动率来自风控K线，交易对必须在监控币种中
+		for _, traded := range c.Trading.Symbols {
+			if !c.RiskControl.Enabled || !containsSymbol(c.RiskControl.MonitorSymbols, traded) {
+				return fmt.Errorf("low_volatility 需要启用 risk_control 且 monitor_symbols 包含交易对 %s", traded)
+			}
+		}
+		if c.Trading.AdaptiveInterval.Enabled {
+			return fmt.Errorf("low_volatility 与 adaptive_interval 都会调整价格间隔，只能启用其中一个")
+		}
+	}
+	for i, side := range c.Trading.PlacementConfirm.Sides {
+		side = strings.ToUpper(side)
+		if side != "BUY" && side != "SELL" {
+			return fmt.Errorf("placement_confirm.sides 只能包含 BUY 或 SELL，当前: %s", c.Trading.PlacementConfirm.Sides[i])
+		}
+		c.Trading.PlacementConfirm.Sides[i] = side
+	}
+	if c.Trading.PlacementConfirm.DelayMs <= 0 {
+		c.Trading.PlacementConfirm.DelayMs = 500 // 默认500毫秒
+	}
+	if c.Trading.PlacementConfirm.MaxReplaces <= 0 {
+		c.Trading.PlacementConfirm.MaxReplaces = 1 // 默认重新下单1次
+	}
+	if c.Trading.DustSweep.CheckInterval <= 0 {
+		c.Trading.DustSweep.CheckInterval = 600 // 默认10分钟
+	}
+	if c.Trading.DustSweep.MaxNotional < 0 {
+		return fmt.Errorf("dust_sweep.max_notional 不能为负数")
+	}
+	switch c.Trading.DustSweep.Action {
+	case "":
+		c.Trading.DustSweep.Action = "market"
+	case "market", "flag":
+	default:
+		return fmt.Errorf("dust_sweep.action 必须是 market 或 flag，当前: %s", c.Trading.DustSweep.Action)
+	}
+
+	if mw := &c.Trading.MarginWindow; mw.Enabled {
+		if mw.CheckInterval <= 0 {
+			mw.CheckInterval = 30 // 默认30秒
+		}
+		if mw.FreeMarginBuffer < 0 {
+			return fmt.Errorf("margin_window.free_margin_buffer 不能为负数")
+		}
+		if mw.MinDepth < 0 {
+			return fmt.Errorf("margin_window.min_depth 不能为负数")
+		}
+		if mw.MinDepth == 0 {
+			mw.MinDepth = 1 // 默认至少保留1层买单
+		}
+	}
+
+	if c.Trading.MinFreeMarginPercent < 0 || c.Trading.MinFreeMarginPercent >= 100 {
+		return fmt.Errorf("min_free_margin_percent 必须在 0-100 之间（不含100）")
+	}
+	if c.Trading.FreeMarginCheckInterval < 0 {
+		return fmt.Errorf("free_margin_check_interval 不能为负数")
+	} else if c.Trading.FreeMarginCheckInterval == 0 {
+		c.Trading.FreeMarginCheckInterval = 10 // 默认10秒
+	}
+
+	// 验证止损配置（金额和比例至少设置一个，两者都设置时先达到的一个触发）
+	if c.Trading.StopLoss.Enabled {
+		if c.Trading.StopLoss.MaxLossUSDT < 0 {
+			return fmt.Errorf("止损金额 (max_loss_usdt) 不能为负数")
+		}
+		if c.Trading.StopLoss.MaxLossPct < 0 || c.Trading.StopLoss.MaxLossPct >= 100 {
+			return fmt.Errorf("止损比例 (max_loss_pct) 必须在 0-100 之间（不含100）")
+		}
+		if c.Trading.StopLoss.MaxLossUSDT == 0 && c.Trading.StopLoss.MaxLossPct == 0 {
+			return fmt.Errorf("启用止损时 max_loss_usdt 和 max_loss_pct 至少设置一个（大于0）")
+		}
+		if c.Trading.StopLoss.CheckInterval == 0 {
+			c.Trading.StopLoss.CheckInterval = 30 // 默认30秒
+		}
+		if c.Trading.StopLoss.CheckInterval < 10 || c.Trading.StopLoss.CheckInterval > 300 {
+			return fmt.Errorf("止损检查间隔必须在10-300秒之间")
+		}
+	}
 	// 验证止盈配置
 	if c.Trading.TakeProfit.Enabled {
-		if c.Trading.TakeProfit.TargetProfit <= 0 {
-			return fmt.Errorf("止盈目标金额必须大于0")
+		if c.Trading.TakeProfit.TargetProfit < 0 || c.Trading.TakeProfit.TargetPct < 0 {
+			return fmt.Errorf("止盈目标 (target_profit / target_pct) 不能为负数")
+		}
+		if (c.Trading.TakeProfit.TargetProfit > 0) == (c.Trading.TakeProfit.TargetPct > 0) {
+			return fmt.Errorf("止盈目标 target_profit（金额）和 target_pct（收益率）必须且只能设置一个")
 		}
 		if c.Trading.TakeProfit.CheckInterval < 10 || c.Trading.TakeProfit.CheckInterval > 300 {
 			return fmt.Errorf("止盈检查间隔必须在10-300秒之间")
@@ -224,6 +1205,23 @@ func (c *Config) Validate() error {
 		if c.Trading.TakeProfit.BalanceMode != "auto" && c.Trading.TakeProfit.BalanceMode != "precise" {
 			return fmt.Errorf("止盈余额模式必须是 auto 或 precise")
 		}
+		switch c.Trading.TakeProfit.BalanceSource {
+		case "":
+			c.Trading.TakeProfit.BalanceSource = "auto" // 默认auto
+		case "auto", "margin", "wallet", "available", "marked":
+		default:
+			return fmt.Errorf("止盈余额来源 (balance_source) 必须是 margin、wallet、available、marked 或 auto")
+		}
+		switch c.Trading.TakeProfit.InventoryMark {
+		case "":
+			c.Trading.TakeProfit.InventoryMark = "bid" // 默认按买一价
+		case "bid", "mark", "loss_only":
+		default:
+			return fmt.Errorf("take_profit.inventory_mark 必须是 bid、mark 或 loss_only")
+		}
+		if c.Trading.TakeProfit.InventoryHaircutPct < 0 || c.Trading.TakeProfit.InventoryHaircutPct >= 100 {
+			return fmt.Errorf("take_profit.inventory_haircut_pct 必须在0到100之间")
+		}
 
 		// 设置默认值
 		if c.Trading.TakeProfit.CheckInterval <= 0 {
@@ -232,7 +1230,197 @@ func (c *Config) Validate() error {
 		if c.Trading.TakeProfit.BalanceMode == "" {
 			c.Trading.TakeProfit.BalanceMode = "auto" // 默认auto
 		}
+		if c.Trading.TakeProfit.InitBalanceRetries < 0 {
+			return fmt.Errorf("take_profit.init_balance_retries 不能为负数")
+		}
+		if c.Trading.TakeProfit.InitBalanceRetries == 0 {
+			c.Trading.TakeProfit.InitBalanceRetries = 3 // 默认重试3次
+		}
+		if c.Trading.TakeProfit.UseNativeTrailing {
+			if c.Trading.TakeProfit.TrailingCallbackPct == 0 {
+				c.Trading.TakeProfit.TrailingCallbackPct = 1 // 默认1%
+			}
+			if c.Trading.TakeProfit.TrailingCallbackPct < 0.1 || c.Trading.TakeProfit.TrailingCallbackPct > 10 {
+				return fmt.Errorf("追踪止损回撤比例 (trailing_callback_pct) 必须在0.1-10之间")
+			}
+		}
 	}
 
 	return nil
 }
+
+// normalizeSymbols 整理交易对列表：symbols 留空时为 [symbol]，symbol 留空时取 symbols 的第一个
+func (c *Config) normalizeSymbols() error {
+	c.Trading.Symbol = strings.TrimSpace(c.Trading.Symbol)
+	if len(c.Trading.Symbols) == 0 {
+		if c.Trading.Symbol == "" {
+			return nil // 由调用方报告交易对为空
+		}
+		c.Trading.Symbols = []string{c.Trading.Symbol}
+	}
+
+	symbols := make([]string, 0, len(c.Trading.Symbols))
+	for _, symbol := range c.Trading.Symbols {
+		symbol = strings.TrimSpace(symbol)
+		if symbol == "" {
+			return fmt.Errorf("trading.symbols 中的交易对不能为空")
+		}
+		if containsSymbol(symbols, symbol) {
+			return fmt.Errorf("trading.symbols 中的交易对 %s 重复", symbol)
+		}
+		symbols = append(symbols, symbol)
+	}
+	c.Trading.Symbols = symbols
+	if c.Trading.Symbol == "" {
+		c.Trading.Symbol = symbols[0]
+	} else if !containsSymbol(symbols, c.Trading.Symbol) {
+		return fmt.Errorf("trading.symbol %s 不在 trading.symbols 中", c.Trading.Symbol)
+	}
+
+	for symbol, override := range c.Trading.SymbolOverrides {
+		if !containsSymbol(symbols, symbol) {
+			return fmt.Errorf("trading.symbol_overrides 中的 %s 不在 trading.symbols 中", symbol)
+		}
+		if override.PriceInterval < 0 || override.OrderQuantity < 0 || override.CapitalAllocation < 0 {
+			return fmt.Errorf("trading.symbol_overrides.%s 的参数不能为负数", symbol)
+		}
+	}
+
+	// 管理接口的状态和控制只对应一个仓位管理器
+	if len(symbols) > 1 && c.Admin.Enabled {
+		return fmt.Errorf("多交易对模式暂不支持管理接口 (admin.enabled)")
+	}
+	return nil
+}
+
+// containsSymbol 交易对列表中是否包含指定交易对
+func containsSymbol(symbols []string, symbol string) bool {
+	for _, s := range symbols {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// ForSymbol 返回单个交易对使用的配置副本（symbol 和 symbols 只包含该交易对，并应用 symbol_overrides）
+// 多交易对时按交易对区分各自的输出文件（如 logs/report.json -> logs/report.ETHUSDT.json），避免互相覆盖
+func (c *Config) ForSymbol(symbol string) (*Config, error) {
+	copied := *c
+	copied.Trading.Symbol = symbol
+	copied.Trading.Symbols = []string{symbol}
+	if override, ok := c.Trading.SymbolOverrides[symbol]; ok {
+		// 按交易对覆盖的价格间隔同时作用于买卖两侧
+		if override.PriceInterval > 0 {
+			copied.Trading.PriceInterval = override.PriceInterval
+			copied.Trading.BuyPriceInterval = 0
+			copied.Trading.SellPriceInterval = 0
+		}
+		if override.OrderQuantity > 0 {
+			copied.Trading.OrderQuantity = override.OrderQuantity
+		}
+		if override.CapitalAllocation > 0 {
+			copied.Trading.CapitalAllocation = override.CapitalAllocation
+		}
+	}
+	if len(c.Trading.Symbols) <= 1 {
+		return &copied, nil
+	}
+
+	for _, path := range []*string{
+		&copied.System.FinalReportFile,
+		&copied.System.CrashDumpFile,
+		&copied.System.OrderMapFile,
+		&copied.System.StatusHistoryFile,
+		&copied.System.StateFile,
+	} {
+		*path = symbolFilePath(*path, symbol)
+	}
+	if copied.Trading.GridRangePercent <= 0 && copied.Trading.GridLevels <= 0 {
+		if copied.Trading.PriceInterval <= 0 {
+			return nil, fmt.Errorf("交易对 %s 的价格间隔必须大于0", symbol)
+		}
+		if err := copied.ApplyPriceInterval(copied.Trading.PriceInterval); err != nil {
+			return nil, fmt.Errorf("交易对 %s: %w", symbol, err)
+		}
+	}
+	return &copied, nil
+}
+
+// symbolFilePath 在文件扩展名前插入交易对（路径为空时保持为空）
+func symbolFilePath(path, symbol string) string {
+	if path == "" {
+		return ""
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + symbol + ext
+}
+
+// ApplyPriceInterval 设置价格间隔并补全依赖它的默认值（网格密度模式下启动时按当前价格换算后调用）
+func (c *Config) ApplyPriceInterval(interval float64) error {
+	c.Trading.PriceInterval = interval
+	if c.Trading.AdaptiveInterval.MaxInterval <= 0 {
+		c.Trading.AdaptiveInterval.MaxInterval = interval * 2
+	}
+	if c.Trading.AdaptiveInterval.MaxInterval < interval {
+		return fmt.Errorf("自适应间隔上限 (max_interval) 不能小于 price_interval")
+	}
+	if fillRate := &c.Trading.FillRateInterval; fillRate.Enabled {
+		if fillRate.MinInterval == 0 {
+			fillRate.MinInterval = interval / 2
+		}
+		if fillRate.MaxInterval == 0 {
+			fillRate.MaxInterval = interval * 2
+		}
+		if fillRate.MinInterval > interval || fillRate.MaxInterval < interval {
+			return fmt.Errorf("fill_rate_interval 的间隔范围 [min_interval, max_interval] 必须包含 price_interval")
+		}
+	}
+	if dynamic := &c.Trading.DynamicInterval; dynamic.Enabled {
+		if dynamic.MinInterval == 0 {
+			dynamic.MinInterval = interval / 2
+		}
+		if dynamic.MaxInterval == 0 {
+			dynamic.MaxInterval = interval * 3
+		}
+		if dynamic.MinInterval > interval || dynamic.MaxInterval < interval {
+			return fmt.Errorf("dynamic_interval 的间隔范围 [min_interval, max_interval] 必须包含 price_interval")
+		}
+	}
+	return nil
+}
+
+// Diff 比较两份配置，返回取值不同的字段（yaml 路径，如 trading.price_interval），用于热重载时记录变更
+// 结构体逐字段展开比较，map 和切片整体比较（exchanges 只返回 exchanges，不展开密钥等字段）
+func (c *Config) Diff(other *Config) []string {
+	var changed []string
+	diffFields("", reflect.ValueOf(*c), reflect.ValueOf(*other), &changed)
+	return changed
+}
+
+// diffFields 递归比较结构体字段，把不同的字段路径追加到 changed
+func diffFields(path string, a, b reflect.Value, changed *[]string) {
+	if a.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changed = append(*changed, path)
+		}
+		return
+	}
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+		diffFields(name, a.Field(i), b.Field(i), changed)
+	}
+}
@@ -0,0 +1,139 @@
+// Package control 本地控制接口（system.control_socket）：通过 Unix socket 暂停/恢复挂单、查询状态
+// 每行一个命令（pause / resume / status），每个命令返回一行 JSON，例如：
+//
+//	echo pause | nc -U /tmp/opensqt.sock
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"opensqt/logger"
+)
+
+// idleTimeout 连接空闲超时（超过该时长未收到命令时断开）
+const idleTimeout = 30 * time.Second
+
+// Controls 控制接口可触发的操作
+type Controls struct {
+	Pause  func() bool        // 暂停挂单并撤销买单，已处于暂停状态时返回 false
+	Resume func() bool        // 恢复挂单，未处于暂停状态时返回 false
+	Status func() interface{} // 当前状态（序列化为 JSON）
+}
+
+// Response 命令的返回结果
+type Response struct {
+	OK      bool        `json:"ok"`
+	Message string      `json:"message,omitempty"`
+	Status  interface{} `json:"status,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// Server 控制接口服务
+type Server struct {
+	path     string
+	controls Controls
+}
+
+// NewServer 创建控制接口服务，path 为 Unix socket 路径
+func NewServer(path string, controls Controls) *Server {
+	return &Server{
+		path:     path,
+		controls: controls,
+	}
+}
+
+// Start 启动控制接口（ctx 取消时关闭并删除 socket 文件）
+// 上次运行异常退出遗留的 socket 文件在监听前删除
+func (s *Server) Start(ctx context.Context) error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("删除旧的控制接口 socket 失败: %w", err)
+	}
+	listener, err := net.Listen("unix", s.path)
+	if err != nil {
+		return fmt.Errorf("控制接口监听失败: %w", err)
+	}
+	// 仅允许当前用户访问
+	if err := os.Chmod(s.path, 0600); err != nil {
+		logger.Warn("⚠️ [控制接口] 设置 socket 权限失败: %v", err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Error("❌ [控制接口] 接受连接失败: %v", err)
+				}
+				return
+			}
+			go s.serveConn(conn)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close() // Unix socket 关闭时删除 socket 文件
+	}()
+
+	logger.Info("✅ [控制接口] 已启动: %s (命令: pause, resume, status)", s.path)
+	return nil
+}
+
+// serveConn 逐行读取命令并返回一行 JSON
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		if !scanner.Scan() {
+			return
+		}
+		command := strings.TrimSpace(scanner.Text())
+		if command == "" {
+			continue
+		}
+		if err := encoder.Encode(s.Handle(command)); err != nil {
+			return
+		}
+	}
+}
+
+// Handle 执行一个命令（大小写不敏感）
+func (s *Server) Handle(command string) Response {
+	switch strings.ToLower(strings.TrimSpace(command)) {
+	case "pause":
+		if s.controls.Pause == nil {
+			return Response{Error: "不支持 pause"}
+		}
+		if !s.controls.Pause() {
+			return Response{OK: true, Message: "已处于暂停状态"}
+		}
+		logger.Warn("⏸️ [控制接口] 已暂停挂单并撤销买单，发送 resume 恢复")
+		return Response{OK: true, Message: "已暂停挂单并撤销买单"}
+	case "resume":
+		if s.controls.Resume == nil {
+			return Response{Error: "不支持 resume"}
+		}
+		if !s.controls.Resume() {
+			return Response{OK: true, Message: "当前未暂停"}
+		}
+		logger.Info("▶️ [控制接口] 已恢复挂单")
+		return Response{OK: true, Message: "已恢复挂单"}
+	case "status":
+		if s.controls.Status == nil {
+			return Response{Error: "不支持 status"}
+		}
+		return Response{OK: true, Status: s.controls.Status()}
+	default:
+		return Response{Error: fmt.Sprintf("未知命令: %s（支持 pause, resume, status）", command)}
+	}
+}
@@ -0,0 +1,130 @@
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newPausableServer 返回以 paused 记录暂停状态的控制接口
+func newPausableServer() (*Server, *bool) {
+	paused := false
+	return NewServer("", Controls{
+		Pause: func() bool {
+			if paused {
+				return false
+			}
+			paused = true
+			return true
+		},
+		Resume: func() bool {
+			if !paused {
+				return false
+			}
+			paused = false
+			return true
+		},
+		Status: func() interface{} { return map[string]bool{"paused": paused} },
+	}), &paused
+}
+
+func TestHandle(t *testing.T) {
+	s, paused := newPausableServer()
+	steps := []struct {
+		command    string
+		wantOK     bool
+		wantPaused bool
+		message    string // 期望的 Message（为空时不检查）
+	}{
+		{"status", true, false, ""},
+		{"resume", true, false, "当前未暂停"},
+		{"pause", true, true, "已暂停挂单并撤销买单"},
+		{"PAUSE", true, true, "已处于暂停状态"},
+		{"  status \n", true, true, ""},
+		{"Resume", true, false, "已恢复挂单"},
+	}
+	for _, step := range steps {
+		resp := s.Handle(step.command)
+		if resp.OK != step.wantOK || resp.Error != "" {
+			t.Fatalf("%q: 应返回成功，实际 %+v", step.command, resp)
+		}
+		if *paused != step.wantPaused {
+			t.Fatalf("%q 之后暂停状态应为 %v", step.command, step.wantPaused)
+		}
+		if step.message != "" && resp.Message != step.message {
+			t.Fatalf("%q: 消息应为 %q，实际 %q", step.command, step.message, resp.Message)
+		}
+		if strings.TrimSpace(strings.ToLower(step.command)) == "status" {
+			if status, ok := resp.Status.(map[string]bool); !ok || status["paused"] != step.wantPaused {
+				t.Fatalf("%q: 状态应为 paused=%v，实际 %+v", step.command, step.wantPaused, resp.Status)
+			}
+		}
+	}
+}
+
+func TestHandleInvalidCommands(t *testing.T) {
+	s, paused := newPausableServer()
+	for _, command := range []string{"stop", "pause now", "", "flatten"} {
+		resp := s.Handle(command)
+		if resp.OK || !strings.Contains(resp.Error, "未知命令") {
+			t.Fatalf("%q: 应返回未知命令错误，实际 %+v", command, resp)
+		}
+	}
+	if *paused {
+		t.Fatal("无效命令不应改变暂停状态")
+	}
+
+	// 未提供对应操作时返回不支持
+	empty := NewServer("", Controls{})
+	for _, command := range []string{"pause", "resume", "status"} {
+		if resp := empty.Handle(command); resp.OK || !strings.Contains(resp.Error, "不支持") {
+			t.Fatalf("%q: 未提供操作时应返回不支持，实际 %+v", command, resp)
+		}
+	}
+}
+
+func TestServeConn(t *testing.T) {
+	s, _ := newPausableServer()
+	s.path = filepath.Join(t.TempDir(), "control.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := s.Start(ctx); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+
+	conn, err := net.Dial("unix", s.path)
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	// 空行被忽略，其余每个命令返回一行 JSON
+	if _, err := conn.Write([]byte("pause\n\nbogus\nstatus\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(conn)
+	var responses []Response
+	for i := 0; i < 3; i++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("读取第 %d 个响应失败: %v", i+1, err)
+		}
+		var resp Response
+		if err := json.Unmarshal(line, &resp); err != nil {
+			t.Fatalf("响应不是合法 JSON: %v\n%s", err, line)
+		}
+		responses = append(responses, resp)
+	}
+	if !responses[0].OK || responses[1].OK || !responses[2].OK {
+		t.Fatalf("响应应依次为成功、失败、成功，实际 %+v", responses)
+	}
+	if status, _ := responses[2].Status.(map[string]interface{}); status["paused"] != true {
+		t.Fatalf("暂停之后 status 应返回 paused=true，实际 %+v", responses[2].Status)
+	}
+}
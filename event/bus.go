@@ -0,0 +1,119 @@
+package event
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"opensqt/logger"
+)
+
+// defaultBufferSize 每个订阅者的默认缓冲大小
+const defaultBufferSize = 256
+
+// Handler 事件处理函数
+type Handler func(Event)
+
+// subscription 订阅
+type subscription struct {
+	name    string
+	types   map[Type]bool // 为空表示订阅全部事件
+	ch      chan Event
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// Bus 进程内事件总线
+// 生产者（仓位管理器、风控、止盈、WebSocket 流）发布类型化事件，订阅者（通知、指标、报告等）各自消费。
+// Publish 从不阻塞：每个订阅者拥有独立缓冲和处理协程，缓冲满时丢弃该订阅者的事件，
+// 因此可以在持锁的交易路径上安全调用
+type Bus struct {
+	mu   sync.RWMutex
+	subs []*subscription
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe 订阅事件，types 为空表示订阅全部事件
+// handler 在订阅者独立的协程中串行调用；返回的函数用于取消订阅
+func (b *Bus) Subscribe(name string, handler Handler, types ...Type) func() {
+	sub := &subscription{
+		name: name,
+		ch:   make(chan Event, defaultBufferSize),
+	}
+	if len(types) > 0 {
+		sub.types = make(map[Type]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+
+	go func() {
+		for e := range sub.ch {
+			handler(e)
+		}
+	}()
+
+	return func() { b.unsubscribe(sub) }
+}
+
+// unsubscribe 取消订阅
+func (b *Bus) unsubscribe(sub *subscription) {
+	b.mu.Lock()
+	for i, s := range b.subs {
+		if s == sub {
+			b.subs = append(b.subs[:i], b.subs[i+1:]...)
+			break
+		}
+	}
+	b.mu.Unlock()
+
+	sub.once.Do(func() { close(sub.ch) })
+}
+
+// Publish 发布事件（非阻塞）
+func (b *Bus) Publish(t Type, payload interface{}) {
+	e := Event{Type: t, Time: time.Now(), Payload: payload}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subs {
+		if sub.types != nil && !sub.types[t] {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			// 首次丢弃及之后每100次提示一次，避免日志刷屏
+			if n := sub.dropped.Add(1); n == 1 || n%100 == 0 {
+				logger.Warn("⚠️ [事件总线] 订阅者 %s 处理过慢，已丢弃 %d 个事件", sub.name, n)
+			}
+		}
+	}
+}
+
+// defaultBus 全局事件总线
+var defaultBus = NewBus()
+
+// Default 返回全局事件总线
+func Default() *Bus {
+	return defaultBus
+}
+
+// Publish 向全局事件总线发布事件
+func Publish(t Type, payload interface{}) {
+	defaultBus.Publish(t, payload)
+}
+
+// Subscribe 订阅全局事件总线
+func Subscribe(name string, handler Handler, types ...Type) func() {
+	return defaultBus.Subscribe(name, handler, types...)
+}
@@ -0,0 +1,209 @@
+package event
+
+import "time"
+
+// Type 事件类型
+type Type string
+
+const (
+	TypeOrderFilled          Type = "order_filled"           // 订单成交（含部分成交）
+	TypeRiskTriggered        Type = "risk_triggered"         // 主动风控触发
+	TypeRiskRecovered        Type = "risk_recovered"         // 主动风控解除
+	TypeRiskEvaluation       Type = "risk_evaluation"        // 主动风控评估记录（K线完结或状态切换时）
+	TypeTakeProfitTriggered  Type = "take_profit_triggered"  // 自动止盈触发
+	TypeStopLossTriggered    Type = "stop_loss_triggered"    // 自动止损触发
+	TypeStreamConnected      Type = "stream_connected"       // WebSocket 流连接成功（含断线重连）
+	TypeExchangePaused       Type = "exchange_paused"        // 交易所故障暂停挂单
+	TypeExchangeResumed      Type = "exchange_resumed"       // 交易所恢复，解除暂停
+	TypeFeeRateChanged       Type = "fee_rate_changed"       // 手续费率变化
+	TypeProfitabilityPaused  Type = "profitability_paused"   // 盈利复核失败，暂停挂单
+	TypeProfitabilityResumed Type = "profitability_resumed"  // 盈利复核恢复，解除暂停
+	TypeSafetyCheckFailed    Type = "safety_check_failed"    // 运行中安全复核失败，暂停新增买单
+	TypeSafetyCheckRecovered Type = "safety_check_recovered" // 运行中安全复核恢复通过
+	TypeSlippagePaused       Type = "slippage_paused"        // 成交滑点持续超过阈值，暂停挂单
+	TypeSlippageResumed      Type = "slippage_resumed"       // 滑点暂停冷却结束，恢复挂单
+	TypeFundingPaused        Type = "funding_paused"         // 资金费率超过上限，暂停新增买单
+	TypeFundingResumed       Type = "funding_resumed"        // 资金费率回落，恢复新增买单
+	TypePriceUpdate          Type = "price"                  // 最新价格（每秒最多一次）
+	TypeStatusSnapshot       Type = "status"                 // 定期状态快照
+	TypeShutdown             Type = "shutdown"               // 收到退出信号，开始优雅关闭
+
+	TypeOrderAction Type = "order_action" // 下单/撤单动作（含原因，用于审计记录）
+)
+
+// 订单动作原因（OrderAction.Reason）
+const (
+	OrderReasonInitial       = "initial"        // 启动后首次挂单
+	OrderReasonGridBuy       = "grid_buy"       // 价格移动后补挂网格买单
+	OrderReasonFillSell      = "fill_sell"      // 买单成交后挂出卖单
+	OrderReasonReanchor      = "reanchor"       // 网格锚点重置后重建
+	OrderReasonRecovery      = "recovery"       // 风控/暂停解除后恢复挂单
+	OrderReasonRiskCancel    = "risk_cancel"    // 市场风控触发时撤单
+	OrderReasonControlPause  = "control_pause"  // 控制接口暂停时撤单
+	OrderReasonProfitGuard   = "profit_guard"   // 盈利复核失败暂停时撤单
+	OrderReasonHoldTime      = "hold_time"      // 超时平仓后暂停到下一个交易时段时撤单
+	OrderReasonSlippage      = "slippage"       // 成交滑点过大暂停时撤单
+	OrderReasonSafetyCheck   = "safety_check"   // 安全复核未通过时撤单
+	OrderReasonFunding       = "funding"        // 资金费率过高暂停买单时撤单
+	OrderReasonCleanup       = "cleanup"        // 订单数量超限清理
+	OrderReasonRepair        = "repair"         // 网格自检修复（重复/漂移订单）
+	OrderReasonReprice       = "reprice"        // 手续费或精度变化后重新挂单
+	OrderReasonMargin        = "margin"         // 保证金不足或保证金窗口收缩
+	OrderReasonAgedInventory = "aged_inventory" // 老化库存处理
+	OrderReasonDustSweep     = "dust_sweep"     // 碎仓清理
+	OrderReasonExit          = "exit"           // 止盈、平仓或程序退出
+)
+
+// Event 事件
+// Payload 为与 Type 对应的具体事件结构体（如 OrderFilled），订阅者按类型断言取用
+type Event struct {
+	Type    Type
+	Time    time.Time
+	Payload interface{}
+}
+
+// OrderFilled 订单成交事件
+type OrderFilled struct {
+	Symbol     string  `json:"symbol"`
+	Side       string  `json:"side"`        // BUY / SELL
+	SlotPrice  float64 `json:"slot_price"`  // 槽位价格（买入价）
+	Price      float64 `json:"price"`       // 成交均价（交易所未提供时为委托价）
+	OrderPrice float64 `json:"order_price"` // 委托价（市价单为0）
+	Quantity   float64 `json:"quantity"`    // 本次成交增量
+	OrderID    int64   `json:"order_id"`
+	Complete   bool    `json:"complete"` // 是否完全成交
+}
+
+// RiskTriggered 主动风控触发事件
+type RiskTriggered struct {
+	PanicCount   int      `json:"panic_count"`
+	TotalSymbols int      `json:"total_symbols"`
+	Details      []string `json:"details"`
+}
+
+// RiskRecovered 主动风控解除事件
+type RiskRecovered struct {
+	RecoveredCount int      `json:"recovered_count"`
+	TotalSymbols   int      `json:"total_symbols"`
+	Details        []string `json:"details"`
+}
+
+// RiskEvaluation 主动风控单次评估记录（各币种指标与阈值、异常数量、判定结果）
+type RiskEvaluation struct {
+	Mode              string             `json:"mode"`           // trigger（检测是否触发）/ recovery（检测是否解除）
+	Decision          string             `json:"decision"`       // triggered / recovered / normal / holding
+	AbnormalCount     int                `json:"abnormal_count"` // 满足触发条件（trigger）或未恢复（recovery）的币种数量
+	TotalSymbols      int                `json:"total_symbols"`
+	RecoveryThreshold int                `json:"recovery_threshold"` // 解除风控所需的恢复币种数量
+	VolumeMultiplier  float64            `json:"volume_multiplier"`  // 成交量倍数阈值
+	AverageWindow     int                `json:"average_window"`     // 移动平均窗口
+	Symbols           []RiskSymbolMetric `json:"symbols"`
+}
+
+// RiskSymbolMetric 单个币种的风控指标
+type RiskSymbolMetric struct {
+	Symbol         string  `json:"symbol"`
+	Price          float64 `json:"price"`           // 判断使用的K线收盘价
+	AvgPrice       float64 `json:"avg_price"`       // 移动平均价
+	PriceDeviation float64 `json:"price_deviation"` // 价格偏离均价的百分比
+	Volume         float64 `json:"volume"`
+	AvgVolume      float64 `json:"avg_volume"`
+	VolumeRatio    float64 `json:"volume_ratio"` // 成交量 / 均量
+	CandleClosed   bool    `json:"candle_closed"`
+	Abnormal       bool    `json:"abnormal"` // 是否满足触发条件（recovery 模式下表示未恢复）
+	Reason         string  `json:"reason,omitempty"`
+}
+
+// TakeProfitTriggered 自动止盈触发事件
+type TakeProfitTriggered struct {
+	InitialBalance float64 `json:"initial_balance"`
+	CurrentBalance float64 `json:"current_balance"`
+	Profit         float64 `json:"profit"`
+	Target         float64 `json:"target"`
+}
+
+// StopLossTriggered 自动止损触发事件
+type StopLossTriggered struct {
+	InitialBalance float64 `json:"initial_balance"`
+	CurrentBalance float64 `json:"current_balance"`
+	Loss           float64 `json:"loss"` // 亏损金额（正数）
+	MaxLoss        float64 `json:"max_loss"`
+}
+
+// StreamConnected WebSocket 流连接事件
+type StreamConnected struct {
+	Exchange string `json:"exchange"` // 交易所名称
+	Stream   string `json:"stream"`   // 流类型：price / order / kline（共用连接时为 all）
+}
+
+// ExchangePaused 交易所故障暂停事件
+type ExchangePaused struct {
+	ErrorCount  int   `json:"error_count"` // 窗口内的5xx错误数
+	TotalErrors int64 `json:"total_errors"`
+}
+
+// ExchangeResumed 交易所恢复事件
+type ExchangeResumed struct {
+	PausedFor    time.Duration `json:"paused_for"`
+	PausedErrors int           `json:"paused_errors"`
+}
+
+// FeeRateChanged 手续费率变化事件
+type FeeRateChanged struct {
+	OldRate float64 `json:"old_rate"`
+	NewRate float64 `json:"new_rate"`
+}
+
+// ProfitabilityChanged 盈利复核暂停/恢复事件
+type ProfitabilityChanged struct {
+	Reason        string  `json:"reason"` // 触发复核的原因（价格变动、精度调整、价格间隔调整等）
+	Price         float64 `json:"price"`
+	PriceInterval float64 `json:"price_interval"`
+	FeeRate       float64 `json:"fee_rate"`
+	NetProfit     float64 `json:"net_profit"` // 每笔净利润
+}
+
+// SafetyCheckChanged 运行中安全复核失败/恢复事件
+type SafetyCheckChanged struct {
+	Reason  string `json:"reason"`  // 失败原因（SafetyCheckReason，恢复时为上次失败原因）
+	Message string `json:"message"` // 失败详情
+}
+
+// SlippageChanged 成交滑点暂停/恢复事件
+type SlippageChanged struct {
+	AvgSlippageBps float64 `json:"avg_slippage_bps"` // 最近成交的平均逆向滑点（基点，正数表示差于委托价）
+	Fills          int     `json:"fills"`            // 参与统计的成交笔数
+	CooldownSecs   int     `json:"cooldown_secs"`    // 暂停时长（秒）
+}
+
+// FundingChanged 资金费率暂停/恢复事件
+type FundingChanged struct {
+	Symbol         string  `json:"symbol"`
+	FundingRate    float64 `json:"funding_rate"`     // 当前资金费率（正数表示多头支付空头）
+	MaxFundingRate float64 `json:"max_funding_rate"` // 配置的资金费率上限
+}
+
+// PriceUpdate 价格更新事件
+type PriceUpdate struct {
+	Symbol string  `json:"symbol"`
+	Price  float64 `json:"price"`
+	Change float64 `json:"change"` // 相对上次推送的变化
+}
+
+// Shutdown 优雅关闭事件
+type Shutdown struct {
+	Reason string `json:"reason"` // signal / quit（终端快捷键）
+}
+
+// OrderAction 下单/撤单动作事件
+type OrderAction struct {
+	Symbol        string  `json:"symbol"`
+	Action        string  `json:"action"` // place / cancel / cancel_all
+	Side          string  `json:"side,omitempty"`
+	Price         float64 `json:"price,omitempty"`
+	Quantity      float64 `json:"quantity,omitempty"`
+	OrderID       int64   `json:"order_id,omitempty"`
+	ClientOrderID string  `json:"client_order_id,omitempty"`
+	Reason        string  `json:"reason"`          // 动作原因（OrderReason*）
+	Error         string  `json:"error,omitempty"` // 失败时的错误信息
+}
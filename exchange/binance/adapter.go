@@ -25,8 +25,9 @@ const (
 )
 
 const (
-	OrderTypeLimit  OrderType = "LIMIT"
-	OrderTypeMarket OrderType = "MARKET"
+	OrderTypeLimit              OrderType = "LIMIT"
+	OrderTypeMarket             OrderType = "MARKET"
+	OrderTypeTrailingStopMarket OrderType = "TRAILING_STOP_MARKET"
 )
 
 const (
@@ -89,6 +90,20 @@ type Account struct {
 	Positions          []*Position
 }
 
+type Trade struct {
+	TradeID     int64
+	OrderID     int64
+	Symbol      string
+	Side        Side
+	Price       float64
+	Quantity    float64
+	RealizedPnL float64
+	Fee         float64
+	FeeAsset    string
+	IsMaker     bool
+	Time        time.Time
+}
+
 type OrderUpdate struct {
 	OrderID       int64
 	ClientOrderID string
@@ -105,16 +120,21 @@ type OrderUpdate struct {
 
 type OrderUpdateCallback func(update OrderUpdate)
 
+// DefaultWSEndpoints 币安 U 本位合约 WebSocket 基础地址（可通过 ws_endpoints 配置覆盖或追加备用地址）
+var DefaultWSEndpoints = []string{"wss://fstream.binance.com"}
+
 // BinanceAdapter 币安交易所适配器
 type BinanceAdapter struct {
 	client           *futures.Client
 	symbol           string
 	wsManager        *WebSocketManager
 	klineWSManager   *KlineWebSocketManager
-	priceDecimals    int    // 价格精度（小数位数）
-	quantityDecimals int    // 数量精度（小数位数）
-	baseAsset        string // 基础资产（交易币种），如 BTC
-	quoteAsset       string // 计价资产（结算币种），如 USDT、USD
+	wsEndpoints      *utils.WSEndpoints // WebSocket 地址（订单流、价格流、K线流共用）
+	priceDecimals    int                // 价格精度（小数位数）
+	quantityDecimals int                // 数量精度（小数位数）
+	baseAsset        string             // 基础资产（交易币种），如 BTC
+	quoteAsset       string             // 计价资产（结算币种），如 USDT、USD
+	minNotional      float64            // 最小下单金额（MIN_NOTIONAL 过滤器）
 }
 
 // NewBinanceAdapter 创建币安适配器
@@ -127,16 +147,22 @@ func NewBinanceAdapter(cfg map[string]string, symbol string) (*BinanceAdapter, e
 	}
 
 	client := futures.NewClient(apiKey, secretKey)
+	if baseURL := cfg["base_url"]; baseURL != "" {
+		client.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
 
 	// 同步服务器时间
 	client.NewSetServerTimeService().Do(context.Background())
 
-	wsManager := NewWebSocketManager(apiKey, secretKey)
+	failoverAfter, _ := strconv.Atoi(cfg["ws_failover_after"])
+	wsEndpoints := utils.NewWSEndpoints("Binance", cfg["ws_endpoints"], DefaultWSEndpoints, failoverAfter)
+	wsManager := NewWebSocketManager(apiKey, secretKey, wsEndpoints)
 
 	adapter := &BinanceAdapter{
-		client:    client,
-		symbol:    symbol,
-		wsManager: wsManager,
+		client:      client,
+		symbol:      symbol,
+		wsManager:   wsManager,
+		wsEndpoints: wsEndpoints,
 	}
 
 	// 获取合约信息（价格精度、数量精度等）
@@ -172,9 +198,12 @@ func (b *BinanceAdapter) fetchExchangeInfo(ctx context.Context) error {
 			b.quantityDecimals = symbol.QuantityPrecision
 			b.baseAsset = symbol.BaseAsset
 			b.quoteAsset = symbol.QuoteAsset
+			if filter := symbol.MinNotionalFilter(); filter != nil {
+				b.minNotional, _ = strconv.ParseFloat(filter.Notional, 64)
+			}
 
-			logger.Info("ℹ️ [Binance 合约信息] %s - 数量精度:%d, 价格精度:%d, 基础币种:%s, 计价币种:%s",
-				b.symbol, b.quantityDecimals, b.priceDecimals, b.baseAsset, b.quoteAsset)
+			logger.Info("ℹ️ [Binance 合约信息] %s - 数量精度:%d, 价格精度:%d, 基础币种:%s, 计价币种:%s, 最小下单金额:%.2f",
+				b.symbol, b.quantityDecimals, b.priceDecimals, b.baseAsset, b.quoteAsset, b.minNotional)
 			return nil
 		}
 	}
@@ -182,6 +211,11 @@ func (b *BinanceAdapter) fetchExchangeInfo(ctx context.Context) error {
 	return fmt.Errorf("未找到合约信息: %s", b.symbol)
 }
 
+// RefreshSymbolInfo 重新获取合约信息（运行中交易规则可能变化）
+func (b *BinanceAdapter) RefreshSymbolInfo(ctx context.Context) error {
+	return b.fetchExchangeInfo(ctx)
+}
+
 // PlaceOrder 下单
 func (b *BinanceAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
 	priceStr := fmt.Sprintf("%.*f", req.PriceDecimals, req.Price)
@@ -393,6 +427,65 @@ func (b *BinanceAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*O
 	return result, nil
 }
 
+// userTradesWindow 币安成交历史单次查询的最大时间跨度
+const userTradesWindow = 7 * 24 * time.Hour
+
+// userTradesLimit 币安成交历史单次查询的最大条数
+const userTradesLimit = 1000
+
+// GetUserTrades 查询 since 之后的历史成交（按 7 天窗口分段，窗口内按条数翻页）
+func (b *BinanceAdapter) GetUserTrades(ctx context.Context, symbol string, since time.Time) ([]*Trade, error) {
+	var result []*Trade
+	now := time.Now()
+	start := since
+	for start.Before(now) {
+		end := start.Add(userTradesWindow)
+		if end.After(now) {
+			end = now
+		}
+
+		trades, err := b.client.NewListAccountTradeService().
+			Symbol(symbol).
+			StartTime(start.UnixMilli()).
+			EndTime(end.UnixMilli()).
+			Limit(userTradesLimit).
+			Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("查询成交历史失败: %w", err)
+		}
+
+		for _, t := range trades {
+			price, _ := strconv.ParseFloat(t.Price, 64)
+			quantity, _ := strconv.ParseFloat(t.Quantity, 64)
+			realizedPnl, _ := strconv.ParseFloat(t.RealizedPnl, 64)
+			commission, _ := strconv.ParseFloat(t.Commission, 64)
+
+			result = append(result, &Trade{
+				TradeID:     t.ID,
+				OrderID:     t.OrderID,
+				Symbol:      t.Symbol,
+				Side:        Side(t.Side),
+				Price:       price,
+				Quantity:    quantity,
+				RealizedPnL: realizedPnl,
+				Fee:         commission,
+				FeeAsset:    t.CommissionAsset,
+				IsMaker:     t.Maker,
+				Time:        time.UnixMilli(t.Time),
+			})
+		}
+
+		// 窗口内未取完时从最后一笔成交之后继续，否则进入下一个窗口
+		if len(trades) == userTradesLimit {
+			start = time.UnixMilli(trades[len(trades)-1].Time + 1)
+		} else {
+			start = end.Add(time.Millisecond)
+		}
+	}
+
+	return result, nil
+}
+
 // GetAccount 获取账户信息（合约账户）
 func (b *BinanceAdapter) GetAccount(ctx context.Context) (*Account, error) {
 	// 🔥 修复：使用合约账户专用的 API
@@ -565,7 +658,7 @@ func (b *BinanceAdapter) StartPriceStream(ctx context.Context, symbol string, ca
 // StartKlineStream 启动K线流（WebSocket）
 func (b *BinanceAdapter) StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
 	if b.klineWSManager == nil {
-		b.klineWSManager = NewKlineWebSocketManager()
+		b.klineWSManager = NewKlineWebSocketManager(b.wsEndpoints)
 	}
 	return b.klineWSManager.Start(ctx, symbols, interval, callback)
 }
@@ -578,6 +671,11 @@ func (b *BinanceAdapter) StopKlineStream() error {
 	return nil
 }
 
+// ActiveWSEndpoint 当前使用的 WebSocket 地址
+func (b *BinanceAdapter) ActiveWSEndpoint() string {
+	return b.wsEndpoints.Active()
+}
+
 // GetHistoricalKlines 获取历史K线数据
 func (b *BinanceAdapter) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
 	klines, err := b.client.NewKlinesService().
@@ -632,3 +730,122 @@ func (b *BinanceAdapter) GetBaseAsset() string {
 func (b *BinanceAdapter) GetQuoteAsset() string {
 	return b.quoteAsset
 }
+
+// ExecutedQtyIsIncremental 订单推送的成交数量语义（ORDER_TRADE_UPDATE 的 z 字段为累计成交数量）
+func (b *BinanceAdapter) ExecutedQtyIsIncremental() bool {
+	return false
+}
+
+// PlaceTrailingStop 下只减仓的追踪止损单（TRAILING_STOP_MARKET，回撤比例 0.1%~10%，精度 0.1%）
+// activationPrice 为 0 时立即激活（按下单时的最新价开始追踪）
+func (b *BinanceAdapter) PlaceTrailingStop(ctx context.Context, symbol string, side Side, quantity, callbackRate, activationPrice float64) (*Order, error) {
+	orderService := b.client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(futures.SideType(side)).
+		Type(futures.OrderTypeTrailingStopMarket).
+		Quantity(fmt.Sprintf("%.*f", b.quantityDecimals, quantity)).
+		CallbackRate(fmt.Sprintf("%.1f", callbackRate)).
+		ReduceOnly(true)
+	if activationPrice > 0 {
+		orderService = orderService.ActivationPrice(fmt.Sprintf("%.*f", b.priceDecimals, activationPrice))
+	}
+
+	resp, err := orderService.Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("下追踪止损单失败: %w", err)
+	}
+
+	return &Order{
+		OrderID:       resp.OrderID,
+		ClientOrderID: resp.ClientOrderID,
+		Symbol:        symbol,
+		Side:          side,
+		Type:          OrderTypeTrailingStopMarket,
+		Quantity:      quantity,
+		Status:        OrderStatus(resp.Status),
+		CreatedAt:     time.Now(),
+		UpdateTime:    resp.UpdateTime,
+	}, nil
+}
+
+// GetTradingFees 获取当前账户在该交易对的手续费率（maker, taker）
+func (b *BinanceAdapter) GetTradingFees(ctx context.Context, symbol string) (float64, float64, error) {
+	rate, err := b.client.NewCommissionRateService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("获取手续费率失败: %w", err)
+	}
+
+	maker, err := strconv.ParseFloat(rate.MakerCommissionRate, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("解析 maker 费率失败: %w", err)
+	}
+	taker, err := strconv.ParseFloat(rate.TakerCommissionRate, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("解析 taker 费率失败: %w", err)
+	}
+	return maker, taker, nil
+}
+
+// GetFundingRate 获取交易对当前周期的资金费率（premiumIndex 的 lastFundingRate，正数表示多头支付空头）
+func (b *BinanceAdapter) GetFundingRate(ctx context.Context, symbol string) (float64, error) {
+	indexes, err := b.client.NewPremiumIndexService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("获取资金费率失败: %w", err)
+	}
+	for _, index := range indexes {
+		if index.Symbol != symbol {
+			continue
+		}
+		rate, err := strconv.ParseFloat(index.LastFundingRate, 64)
+		if err != nil {
+			return 0, fmt.Errorf("解析资金费率失败: %w", err)
+		}
+		return rate, nil
+	}
+	return 0, fmt.Errorf("未返回 %s 的资金费率", symbol)
+}
+
+// GetSymbolLeverage 查询交易对当前设置的杠杆倍数（PositionRisk 无持仓时也会返回该交易对的杠杆设置）
+func (b *BinanceAdapter) GetSymbolLeverage(ctx context.Context, symbol string) (int, error) {
+	positionRisks, err := b.client.NewGetPositionRiskService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("获取杠杆倍数失败: %w", err)
+	}
+	for _, pos := range positionRisks {
+		if pos.Symbol != symbol {
+			continue
+		}
+		leverage, err := strconv.Atoi(pos.Leverage)
+		if err != nil {
+			return 0, fmt.Errorf("解析杠杆倍数失败: %w", err)
+		}
+		return leverage, nil
+	}
+	return 0, fmt.Errorf("未返回 %s 的杠杆信息", symbol)
+}
+
+// GetOrderBookTop 获取买一/卖一（返回 bidPrice, bidQty, askPrice, askQty）
+func (b *BinanceAdapter) GetOrderBookTop(ctx context.Context, symbol string) (float64, float64, float64, float64, error) {
+	depth, err := b.client.NewDepthService().Symbol(symbol).Limit(5).Do(ctx)
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("获取深度失败: %w", err)
+	}
+	if len(depth.Bids) == 0 || len(depth.Asks) == 0 {
+		return 0, 0, 0, 0, fmt.Errorf("深度数据为空")
+	}
+
+	bidPrice, bidQty, err := depth.Bids[0].Parse()
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("解析买一失败: %w", err)
+	}
+	askPrice, askQty, err := depth.Asks[0].Parse()
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("解析卖一失败: %w", err)
+	}
+	return bidPrice, bidQty, askPrice, askQty, nil
+}
+
+// GetMinNotional 获取最小下单金额
+func (b *BinanceAdapter) GetMinNotional() float64 {
+	return b.minNotional
+}
@@ -9,7 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"opensqt/event"
 	"opensqt/logger"
+	"opensqt/utils"
 
 	"github.com/gorilla/websocket"
 )
@@ -38,12 +40,14 @@ type KlineWebSocketManager struct {
 	pingInterval   time.Duration
 	pongWait       time.Duration
 	isRunning      bool
+	endpoints      *utils.WSEndpoints // WebSocket 地址（与订单流共用故障切换状态）
 }
 
 // NewKlineWebSocketManager 创建K线WebSocket管理器
-func NewKlineWebSocketManager() *KlineWebSocketManager {
+func NewKlineWebSocketManager(endpoints *utils.WSEndpoints) *KlineWebSocketManager {
 	return &KlineWebSocketManager{
 		done:           make(chan struct{}),
+		endpoints:      endpoints,
 		reconnectDelay: 5 * time.Second,  // 重连延迟
 		pingInterval:   30 * time.Second, // Ping间隔
 		pongWait:       60 * time.Second, // Pong等待超时
@@ -87,13 +91,15 @@ func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
 		for i, symbol := range k.symbols {
 			streams[i] = fmt.Sprintf("%s@kline_%s", strings.ToLower(symbol), k.interval)
 		}
-		wsURL := fmt.Sprintf("wss://fstream.binance.com/stream?streams=%s", strings.Join(streams, "/"))
+		base := k.endpoints.Active()
+		wsURL := fmt.Sprintf("%s/stream?streams=%s", base, strings.Join(streams, "/"))
 
 		logger.Info("🔗 正在连接 Binance K线WebSocket...")
 
 		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 		if err != nil {
 			logger.Error("❌ K线WebSocket连接失败: %v，%v后重试", err, k.reconnectDelay)
+			k.endpoints.OnConnectFailed(base)
 			// 使用 select 等待，可以立即响应 context 取消
 			select {
 			case <-ctx.Done():
@@ -107,11 +113,13 @@ func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
 			continue
 		}
 
+		k.endpoints.OnConnected(base)
 		k.mu.Lock()
 		k.conn = conn
 		k.mu.Unlock()
 
 		logger.Info("✅ Binance K线WebSocket已连接")
+		event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "Binance", Stream: "kline"})
 
 		// 启动心跳保活
 		go k.pingLoop(ctx, conn)
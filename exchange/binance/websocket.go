@@ -9,7 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"opensqt/event"
 	"opensqt/logger"
+	"opensqt/utils"
 
 	"github.com/adshao/go-binance/v2/futures"
 	"github.com/gorilla/websocket"
@@ -26,6 +28,7 @@ type WebSocketManager struct {
 	mu        sync.RWMutex
 	callbacks []OrderUpdateCallback
 	isRunning bool
+	endpoints *utils.WSEndpoints // WebSocket 地址（故障切换）
 
 	// 价格缓存
 	latestPrice float64
@@ -38,11 +41,12 @@ type WebSocketManager struct {
 }
 
 // NewWebSocketManager 创建 WebSocket 管理器
-func NewWebSocketManager(apiKey, secretKey string) *WebSocketManager {
+func NewWebSocketManager(apiKey, secretKey string, endpoints *utils.WSEndpoints) *WebSocketManager {
 	return &WebSocketManager{
 		client:            futures.NewClient(apiKey, secretKey),
 		apiKey:            apiKey,
 		secretKey:         secretKey,
+		endpoints:         endpoints,
 		doneC:             make(chan struct{}),
 		stopC:             make(chan struct{}),
 		callbacks:         make([]OrderUpdateCallback, 0),
@@ -86,7 +90,6 @@ func (w *WebSocketManager) StartPriceStream(ctx context.Context, symbol string,
 	// 格式: wss://fstream.binance.com/ws/<symbol>@aggTrade
 
 	symbolLower := strings.ToLower(symbol)
-	url := fmt.Sprintf("wss://fstream.binance.com/ws/%s@aggTrade", symbolLower)
 
 	// 使用通道等待首个价格
 	firstPriceCh := make(chan struct{})
@@ -101,17 +104,22 @@ func (w *WebSocketManager) StartPriceStream(ctx context.Context, symbol string,
 			default:
 			}
 
+			base := w.endpoints.Active()
+			url := fmt.Sprintf("%s/ws/%s@aggTrade", base, symbolLower)
 			logger.Debug("🔗 [Binance] 正在连接 WebSocket: %s", url)
 
 			// 导入 gorilla/websocket
 			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
 			if err != nil {
 				logger.Error("❌ [Binance] WebSocket 连接失败: %v，5秒后重试", err)
+				w.endpoints.OnConnectFailed(base)
 				time.Sleep(5 * time.Second)
 				continue
 			}
+			w.endpoints.OnConnected(base)
 
 			logger.Info("✅ [Binance] WebSocket 已连接: %s", url) // 读取消息循环
+			event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "Binance", Stream: "price"})
 			for {
 				select {
 				case <-ctx.Done():
@@ -228,16 +236,22 @@ func (w *WebSocketManager) listenUserDataStream(ctx context.Context) {
 		default:
 		}
 
-		logger.Info("🔗 [Binance] 连接WebSocket订单流...")
+		base := w.endpoints.Active()
+		logger.Info("🔗 [Binance] 连接WebSocket订单流 (%s)...", base)
 
+		// go-binance 通过包级变量决定订单流地址
+		futures.BaseWsMainUrl = base + "/ws"
 		doneC, stopC, err := futures.WsUserDataServe(w.listenKey, w.handleUserDataEvent, w.handleError)
 		if err != nil {
 			logger.Error("❌ [Binance] WebSocket连接失败: %v", err)
+			w.endpoints.OnConnectFailed(base)
 			time.Sleep(w.reconnectDelay)
 			continue
 		}
+		w.endpoints.OnConnected(base)
 
 		logger.Info("✅ [Binance] WebSocket订单流已连接")
+		event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "Binance", Stream: "order"})
 
 		// 等待断开或停止信号
 		select {
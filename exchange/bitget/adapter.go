@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"opensqt/logger"
+	"opensqt/utils"
 )
 
 // 为了避免循环导入，在这里定义需要的接口和类型
@@ -25,7 +26,8 @@ const (
 )
 
 const (
-	OrderTypeLimit OrderType = "LIMIT"
+	OrderTypeLimit              OrderType = "LIMIT"
+	OrderTypeTrailingStopMarket OrderType = "TRAILING_STOP_MARKET"
 )
 
 const (
@@ -84,6 +86,20 @@ type Account struct {
 	AccountLeverage    int    // 账户级别的杠杆倍数
 }
 
+type Trade struct {
+	TradeID     int64
+	OrderID     int64
+	Symbol      string
+	Side        Side
+	Price       float64
+	Quantity    float64
+	RealizedPnL float64
+	Fee         float64
+	FeeAsset    string
+	IsMaker     bool
+	Time        time.Time
+}
+
 type OrderUpdate struct {
 	OrderID       int64
 	ClientOrderID string
@@ -100,13 +116,17 @@ type OrderUpdate struct {
 
 type OrderUpdateCallback func(update OrderUpdate)
 
+// DefaultWSEndpoints Bitget WebSocket 基础地址（可通过 ws_endpoints 配置覆盖或追加备用地址）
+var DefaultWSEndpoints = []string{"wss://ws.bitget.com"}
+
 // BitgetAdapter Bitget 交易所适配器
 type BitgetAdapter struct {
 	client         *Client
 	wsManager      *WebSocketManager
 	klineWSManager *KlineWebSocketManager
-	symbol         string // 交易对（如 ETHUSDT，V2 API 不带 _UMCBL 后缀）
-	useWebSocket   bool   // 是否使用 WebSocket 下单
+	wsEndpoints    *utils.WSEndpoints // WebSocket 基础地址（订单流、价格流、K线流共用）
+	symbol         string             // 交易对（如 ETHUSDT，V2 API 不带 _UMCBL 后缀）
+	useWebSocket   bool               // 是否使用 WebSocket 下单
 
 	// 🔥 新增：订单ID到价格的映射注册回调
 	// 用于在下单成功后立即建立映射，避免 WebSocket 更新先到导致找不到槽位
@@ -137,11 +157,17 @@ func NewBitgetAdapter(cfg map[string]string, symbol string) (*BitgetAdapter, err
 	bitgetSymbol := convertToBitgetSymbol(symbol)
 
 	client := NewClient(apiKey, secretKey, passphrase)
-	wsManager := NewWebSocketManager(apiKey, secretKey, passphrase)
+	if baseURL := cfg["base_url"]; baseURL != "" {
+		client.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+	failoverAfter, _ := strconv.Atoi(cfg["ws_failover_after"])
+	wsEndpoints := utils.NewWSEndpoints("Bitget", cfg["ws_endpoints"], DefaultWSEndpoints, failoverAfter)
+	wsManager := NewWebSocketManager(apiKey, secretKey, passphrase, wsEndpoints)
 
 	adapter := &BitgetAdapter{
 		client:       client,
 		wsManager:    wsManager,
+		wsEndpoints:  wsEndpoints,
 		symbol:       bitgetSymbol,
 		useWebSocket: false, // 使用 REST API 下单（混合模式）
 	}
@@ -269,6 +295,11 @@ func (b *BitgetAdapter) fetchContractInfo(ctx context.Context) error {
 	return fmt.Errorf("未找到合约信息: %s", b.symbol)
 }
 
+// RefreshSymbolInfo 重新获取合约信息（运行中交易规则可能变化）
+func (b *BitgetAdapter) RefreshSymbolInfo(ctx context.Context) error {
+	return b.fetchContractInfo(ctx)
+}
+
 // PlaceOrder 下单（使用 REST API）
 func (b *BitgetAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
 	// 混合模式：使用 REST API 下单，更稳定可靠
@@ -702,6 +733,109 @@ func (b *BitgetAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Or
 	return orders, nil
 }
 
+// userTradesWindow Bitget 成交历史单次查询的最大时间跨度
+const userTradesWindow = 7 * 24 * time.Hour
+
+// userTradesLimit Bitget 成交历史单页最大条数
+const userTradesLimit = 100
+
+// GetUserTrades 查询 since 之后的历史成交（按 7 天窗口分段，窗口内按 idLessThan 向前翻页）
+func (b *BitgetAdapter) GetUserTrades(ctx context.Context, symbol string, since time.Time) ([]*Trade, error) {
+	var result []*Trade
+	now := time.Now()
+	for start := since; start.Before(now); start = start.Add(userTradesWindow) {
+		end := start.Add(userTradesWindow)
+		if end.After(now) {
+			end = now
+		}
+
+		var window []*Trade
+		idLessThan := ""
+		for {
+			path := fmt.Sprintf("/api/v2/mix/order/fill-history?symbol=%s&productType=%s&startTime=%d&endTime=%d&limit=%d",
+				b.symbol, b.productType, start.UnixMilli(), end.UnixMilli(), userTradesLimit)
+			if idLessThan != "" {
+				path += "&idLessThan=" + idLessThan
+			}
+			resp, err := b.client.DoRequest(ctx, "GET", path, nil)
+			if err != nil {
+				return nil, fmt.Errorf("查询成交历史失败: %w", err)
+			}
+
+			var data struct {
+				FillList []struct {
+					TradeId   string `json:"tradeId"`
+					OrderId   string `json:"orderId"`
+					Symbol    string `json:"symbol"`
+					Side      string `json:"side"` // "buy" or "sell"
+					Price     string `json:"price"`
+					Volume    string `json:"baseVolume"`
+					Profit    string `json:"profit"`
+					TradeRole string `json:"tradeScope"` // "maker" or "taker"
+					CTime     string `json:"cTime"`
+					FeeDetail []struct {
+						FeeCoin  string `json:"feeCoin"`
+						TotalFee string `json:"totalFee"`
+					} `json:"feeDetail"`
+				} `json:"fillList"`
+				EndId string `json:"endId"`
+			}
+			if err := json.Unmarshal(resp.Data, &data); err != nil {
+				return nil, fmt.Errorf("解析成交历史失败: %w", err)
+			}
+
+			for _, item := range data.FillList {
+				tradeID, _ := strconv.ParseInt(item.TradeId, 10, 64)
+				orderID, _ := strconv.ParseInt(item.OrderId, 10, 64)
+				price, _ := strconv.ParseFloat(item.Price, 64)
+				quantity, _ := strconv.ParseFloat(item.Volume, 64)
+				profit, _ := strconv.ParseFloat(item.Profit, 64)
+				cTime, _ := strconv.ParseInt(item.CTime, 10, 64)
+
+				side := SideBuy
+				if item.Side == "sell" {
+					side = SideSell
+				}
+
+				// Bitget 的 totalFee 以负数表示扣除的手续费
+				var fee float64
+				var feeAsset string
+				for _, d := range item.FeeDetail {
+					totalFee, _ := strconv.ParseFloat(d.TotalFee, 64)
+					fee -= totalFee
+					feeAsset = d.FeeCoin
+				}
+
+				window = append(window, &Trade{
+					TradeID:     tradeID,
+					OrderID:     orderID,
+					Symbol:      symbol,
+					Side:        side,
+					Price:       price,
+					Quantity:    quantity,
+					RealizedPnL: profit,
+					Fee:         fee,
+					FeeAsset:    feeAsset,
+					IsMaker:     item.TradeRole == "maker",
+					Time:        time.UnixMilli(cTime),
+				})
+			}
+
+			if len(data.FillList) < userTradesLimit || data.EndId == "" {
+				break
+			}
+			idLessThan = data.EndId
+		}
+
+		// 接口按时间倒序返回，转为升序
+		for i := len(window) - 1; i >= 0; i-- {
+			result = append(result, window[i])
+		}
+	}
+
+	return result, nil
+}
+
 // GetAccount 获取账户信息
 func (b *BitgetAdapter) GetAccount(ctx context.Context) (*Account, error) {
 	path := fmt.Sprintf("/api/v2/mix/account/account?symbol=%s&productType=%s&marginCoin=%s", b.symbol, b.productType, b.marginCoin)
@@ -952,7 +1086,7 @@ func (b *BitgetAdapter) StartPriceStream(ctx context.Context, symbol string, cal
 // StartKlineStream 启动K线流（WebSocket）
 func (b *BitgetAdapter) StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
 	if b.klineWSManager == nil {
-		b.klineWSManager = NewKlineWebSocketManager()
+		b.klineWSManager = NewKlineWebSocketManager(b.wsEndpoints)
 	}
 	return b.klineWSManager.Start(ctx, symbols, interval, callback)
 }
@@ -965,6 +1099,99 @@ func (b *BitgetAdapter) StopKlineStream() error {
 	return nil
 }
 
+// PlaceTrailingStop 下只减仓的追踪止损单（计划委托 track_plan，触发后市价成交）
+// Bitget 追踪委托必须指定触发价：activationPrice 为 0 时使用最新成交价（即立即开始追踪）
+func (b *BitgetAdapter) PlaceTrailingStop(ctx context.Context, symbol string, side Side, quantity, callbackRate, activationPrice float64) (*Order, error) {
+	if activationPrice <= 0 {
+		price, err := b.GetLatestPrice(ctx, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("获取追踪止损触发价失败: %w", err)
+		}
+		activationPrice = price
+	}
+
+	body := map[string]interface{}{
+		"planType":      "track_plan",
+		"symbol":        symbol,
+		"productType":   b.productType,
+		"marginMode":    "crossed",
+		"marginCoin":    "USDT",
+		"side":          strings.ToLower(string(side)),
+		"orderType":     "market",
+		"size":          fmt.Sprintf("%.*f", b.volumePlace, quantity),
+		"triggerPrice":  fmt.Sprintf("%.*f", b.pricePlace, activationPrice),
+		"triggerType":   "fill_price",
+		"callbackRatio": fmt.Sprintf("%.2f", callbackRate),
+	}
+	// 与普通下单一致：双向持仓用 tradeSide=close（平多 side=buy），单向持仓用 reduceOnly
+	if b.posMode == "hedge_mode" {
+		if side == SideSell {
+			body["side"] = "buy"
+		} else {
+			body["side"] = "sell"
+		}
+		body["tradeSide"] = "close"
+	} else {
+		body["reduceOnly"] = "YES"
+	}
+
+	resp, err := b.client.DoRequest(ctx, "POST", "/api/v2/mix/order/place-plan-order", body)
+	if err != nil {
+		return nil, fmt.Errorf("下追踪止损单失败: %w", err)
+	}
+
+	var data struct {
+		OrderID       string `json:"orderId"`
+		ClientOrderID string `json:"clientOid"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("解析追踪止损响应失败: %w", err)
+	}
+	orderID, _ := strconv.ParseInt(data.OrderID, 10, 64)
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: data.ClientOrderID,
+		Symbol:        symbol,
+		Side:          side,
+		Type:          OrderTypeTrailingStopMarket,
+		Price:         activationPrice,
+		Quantity:      quantity,
+		Status:        OrderStatusNew,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// GetFundingRate 获取交易对当前周期的资金费率（正数表示多头支付空头）
+func (b *BitgetAdapter) GetFundingRate(ctx context.Context, symbol string) (float64, error) {
+	path := fmt.Sprintf("/api/v2/mix/market/current-fund-rate?symbol=%s&productType=%s", b.symbol, b.productType)
+	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return 0, fmt.Errorf("获取资金费率失败: %w", err)
+	}
+
+	var dataList []struct {
+		Symbol      string `json:"symbol"`
+		FundingRate string `json:"fundingRate"`
+	}
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return 0, fmt.Errorf("解析资金费率失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return 0, fmt.Errorf("未返回 %s 的资金费率", b.symbol)
+	}
+	rate, err := strconv.ParseFloat(dataList[0].FundingRate, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析资金费率失败: %w", err)
+	}
+	return rate, nil
+}
+
+// ActiveWSEndpoint 当前使用的 WebSocket 地址
+func (b *BitgetAdapter) ActiveWSEndpoint() string {
+	return b.wsEndpoints.Active()
+}
+
 // GetHistoricalKlines 获取历史K线数据
 func (b *BitgetAdapter) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
 	// Bitget 支持的K线周期映射
@@ -1104,3 +1331,14 @@ func (b *BitgetAdapter) GetBaseAsset() string {
 func (b *BitgetAdapter) GetQuoteAsset() string {
 	return b.quoteAsset
 }
+
+// ExecutedQtyIsIncremental 订单推送的成交数量语义（orders 频道的 accBaseVolume 为累计成交数量）
+func (b *BitgetAdapter) ExecutedQtyIsIncremental() bool {
+	return false
+}
+
+// GetMinNotional 获取最小下单金额（合约信息中的 minTradeUSDT）
+func (b *BitgetAdapter) GetMinNotional() float64 {
+	minNotional, _ := strconv.ParseFloat(b.minTradeUSDT, 64)
+	return minNotional
+}
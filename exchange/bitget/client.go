@@ -83,6 +83,11 @@ func (c *Client) DoRequest(ctx context.Context, method, path string, body interf
 		return nil, fmt.Errorf("读取响应失败: %w", err)
 	}
 
+	// 服务端错误（5xx）时响应体通常不是标准 JSON，直接返回状态码便于上层识别
+	if resp.StatusCode >= 500 {
+		return nil, fmt.Errorf("bitget 服务端错误: 状态码=%d, 响应=%s", resp.StatusCode, string(respBody))
+	}
+
 	var bitgetResp BitgetResponse
 	if err := json.Unmarshal(respBody, &bitgetResp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w, 响应体: %s", err, string(respBody))
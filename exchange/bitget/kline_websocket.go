@@ -9,7 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"opensqt/event"
 	"opensqt/logger"
+	"opensqt/utils"
 
 	"github.com/gorilla/websocket"
 )
@@ -37,12 +39,14 @@ type KlineWebSocketManager struct {
 	reconnectDelay time.Duration
 	pingInterval   time.Duration
 	isRunning      bool
+	endpoints      *utils.WSEndpoints // WebSocket 基础地址（与订单流共用故障切换状态）
 }
 
 // NewKlineWebSocketManager 创建K线WebSocket管理器
-func NewKlineWebSocketManager() *KlineWebSocketManager {
+func NewKlineWebSocketManager(endpoints *utils.WSEndpoints) *KlineWebSocketManager {
 	return &KlineWebSocketManager{
 		done:           make(chan struct{}),
+		endpoints:      endpoints,
 		reconnectDelay: 5 * time.Second,  // 重连延迟
 		pingInterval:   15 * time.Second, // Ping间隔（Bitget官方SDK使用15秒）
 	}
@@ -81,13 +85,15 @@ func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
 		}
 
 		// Bitget WebSocket URL
-		wsURL := "wss://ws.bitget.com/v2/ws/public"
+		base := k.endpoints.Active()
+		wsURL := base + BitgetWSPublic
 
 		logger.Info("🔗 正在连接 Bitget K线WebSocket...")
 
 		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 		if err != nil {
 			logger.Error("❌ Bitget K线WebSocket连接失败: %v，%v后重试", err, k.reconnectDelay)
+			k.endpoints.OnConnectFailed(base)
 			// 使用 select 等待，可以立即响应 context 取消
 			select {
 			case <-ctx.Done():
@@ -101,11 +107,13 @@ func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
 			continue
 		}
 
+		k.endpoints.OnConnected(base)
 		k.mu.Lock()
 		k.conn = conn
 		k.mu.Unlock()
 
 		logger.Info("✅ Bitget K线WebSocket已连接")
+		event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "Bitget", Stream: "kline"})
 
 		// 订阅K线
 		if err := k.subscribe(k.symbols, k.interval); err != nil {
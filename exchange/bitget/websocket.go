@@ -32,15 +32,17 @@ import (
 	"sync"
 	"time"
 
+	"opensqt/event"
 	"opensqt/logger"
+	"opensqt/utils"
 
 	"github.com/gorilla/websocket"
 )
 
 const (
-	// Bitget V2 WebSocket 地址
-	BitgetWSPrivate = "wss://ws.bitget.com/v2/ws/private"
-	BitgetWSPublic  = "wss://ws.bitget.com/v2/ws/public"
+	// Bitget V2 WebSocket 频道路径
+	BitgetWSPrivate = "/v2/ws/private" // 私有频道路径（拼接在 WebSocket 基础地址后）
+	BitgetWSPublic  = "/v2/ws/public"  // 公共频道路径
 
 	// API Code - 重要：不要丢失！
 	BitgetAPICode = "3xh1b"
@@ -78,7 +80,8 @@ type WebSocketManager struct {
 	publicReconnectChan  chan struct{}
 	privateReconnectChan chan struct{}
 	reconnectDelay       time.Duration
-	subscribedSymbol     string // 记录订阅的交易对，用于重连后重新订阅
+	subscribedSymbol     string             // 记录订阅的交易对，用于重连后重新订阅
+	endpoints            *utils.WSEndpoints // WebSocket 基础地址（故障切换）
 }
 
 // SetPriceCallback 设置价格回调
@@ -139,7 +142,7 @@ type WSSubscribeArg struct {
 }
 
 // NewWebSocketManager 创建 WebSocket 管理器
-func NewWebSocketManager(apiKey, secretKey, passphrase string) *WebSocketManager {
+func NewWebSocketManager(apiKey, secretKey, passphrase string, endpoints *utils.WSEndpoints) *WebSocketManager {
 	return &WebSocketManager{
 		apiKey:               apiKey,
 		secretKey:            secretKey,
@@ -147,6 +150,7 @@ func NewWebSocketManager(apiKey, secretKey, passphrase string) *WebSocketManager
 		publicReconnectChan:  make(chan struct{}, 1),
 		privateReconnectChan: make(chan struct{}, 1),
 		reconnectDelay:       5 * time.Second,
+		endpoints:            endpoints,
 	}
 }
 
@@ -165,9 +169,11 @@ func (w *WebSocketManager) publicConnectLoop() {
 		logger.Info("🔗 [Bitget WS公共] 正在连接...")
 
 		// 连接公共频道
-		conn, _, err := websocket.DefaultDialer.Dial(BitgetWSPublic, nil)
+		base := w.endpoints.Active()
+		conn, _, err := websocket.DefaultDialer.Dial(base+BitgetWSPublic, nil)
 		if err != nil {
 			logger.Error("❌ [Bitget WS公共] 连接失败: %v，%v后重试", err, w.reconnectDelay)
+			w.endpoints.OnConnectFailed(base)
 			// 使用 select 等待，可以立即响应 context 取消
 			select {
 			case <-w.ctx.Done():
@@ -178,12 +184,14 @@ func (w *WebSocketManager) publicConnectLoop() {
 			continue
 		}
 
+		w.endpoints.OnConnected(base)
 		w.mu.Lock()
 		w.publicConn = conn
 		symbol := w.subscribedSymbol
 		w.mu.Unlock()
 
 		logger.Info("✅ [Bitget WS公共] 已连接")
+		event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "Bitget", Stream: "price"})
 
 		// 订阅价格更新
 		if err := w.subscribeTicker(symbol); err != nil {
@@ -261,8 +269,10 @@ func (w *WebSocketManager) privateConnectLoop() {
 		logger.Info("🔗 [Bitget WS私有] 正在连接...")
 
 		// 连接私有频道
-		if err := w.connectPrivate(); err != nil {
+		base := w.endpoints.Active()
+		if err := w.connectPrivate(base); err != nil {
 			logger.Error("❌ [Bitget WS私有] 连接失败: %v，%v后重试", err, w.reconnectDelay)
+			w.endpoints.OnConnectFailed(base)
 			// 使用 select 等待，可以立即响应 context 取消
 			select {
 			case <-w.ctx.Done():
@@ -399,9 +409,9 @@ func (w *WebSocketManager) Stop() {
 	logger.Info("✅ [Bitget WebSocket] 已停止")
 }
 
-// connectPrivate 连接私有 WebSocket
-func (w *WebSocketManager) connectPrivate() error {
-	conn, _, err := websocket.DefaultDialer.Dial(BitgetWSPrivate, nil)
+// connectPrivate 连接私有 WebSocket 并登录
+func (w *WebSocketManager) connectPrivate(base string) error {
+	conn, _, err := websocket.DefaultDialer.Dial(base+BitgetWSPrivate, nil)
 	if err != nil {
 		return err
 	}
@@ -438,13 +448,15 @@ func (w *WebSocketManager) connectPrivate() error {
 		return fmt.Errorf("登录失败: code=%s, msg=%s", codeStr, resp.Msg)
 	}
 
+	w.endpoints.OnConnected(base)
 	logger.Info("✅ [Bitget WebSocket] 私有频道登录成功")
+	event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "Bitget", Stream: "order"})
 	return nil
 }
 
 // connectPublic 连接公共 WebSocket
 func (w *WebSocketManager) connectPublic() error {
-	conn, _, err := websocket.DefaultDialer.Dial(BitgetWSPublic, nil)
+	conn, _, err := websocket.DefaultDialer.Dial(w.endpoints.Active()+BitgetWSPublic, nil)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,117 @@
+package exchange
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// serverStatusPattern 匹配错误信息中的 5xx HTTP 状态码
+// Gate.io: "状态码: 502" / "状态码=503"，Bitget: "状态码=500"，通用: "status code 504"
+var serverStatusPattern = regexp.MustCompile(`(状态码[:=]\s*|status code[:= ]\s*|HTTP\s)5\d\d`)
+
+// binanceServerErrorCodes 币安服务端错误码（对应交易所内部故障，非请求参数问题）
+// -1000 UNKNOWN, -1001 DISCONNECTED, -1006 UNEXPECTED_RESP, -1007 TIMEOUT, -1008 SERVER_BUSY
+var binanceServerErrorCodes = []string{"code=-1000,", "code=-1001,", "code=-1006,", "code=-1007,", "code=-1008,"}
+
+// IsServerError 判断错误是否为交易所服务端错误（5xx）
+// 与速率限制不同，服务端错误说明交易所本身不健康，重试通常无意义
+func IsServerError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	errStr := err.Error()
+	if serverStatusPattern.MatchString(errStr) {
+		return true
+	}
+
+	for _, code := range binanceServerErrorCodes {
+		if strings.Contains(errStr, code) {
+			return true
+		}
+	}
+
+	return strings.Contains(errStr, "Bad Gateway") ||
+		strings.Contains(errStr, "Service Unavailable") ||
+		strings.Contains(errStr, "Gateway Timeout")
+}
+
+// cooldownPatterns 交易所因同一交易对操作过于频繁而拒绝请求的错误信息特征
+// 与全局速率限制（-1003 / rate limit）不同，冷却只针对单个交易对，等待一个操作间隔即可恢复
+var cooldownPatterns = []string{"too frequent", "Too frequent", "too often", "cooldown", "cool down", "过于频繁", "操作频繁"}
+
+// IsCooldownError 判断错误是否为交易对级别的操作冷却拒绝
+func IsCooldownError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	errStr := err.Error()
+	for _, pattern := range cooldownPatterns {
+		if strings.Contains(errStr, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// orderNotFoundPatterns 查询订单时交易所返回"订单不存在"的错误信息特征
+// Binance/Mock: -2013 Order does not exist，Bitget: 40029，Gate.io: ORDER_NOT_FOUND
+var orderNotFoundPatterns = []string{"-2013", "does not exist", "40029", "ORDER_NOT_FOUND", "not found"}
+
+// IsOrderNotFoundError 判断查询订单的错误是否为订单不存在
+func IsOrderNotFoundError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	errStr := err.Error()
+	for _, pattern := range orderNotFoundPatterns {
+		if strings.Contains(errStr, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// duplicateOrderPatterns 下单时交易所因自定义订单ID重复而拒绝的错误信息特征
+// Binance: -4116 ClientOrderId is duplicated，OKX: 51016 Duplicated clOrdId，Bitget: 40786 Duplicate clientOid
+var duplicateOrderPatterns = []string{"-4116", "51016", "40786", "duplicate", "Duplicate", "duplicated"}
+
+// IsDuplicateOrderError 判断下单错误是否为自定义订单ID重复（同一ID的订单已存在）
+func IsDuplicateOrderError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	errStr := err.Error()
+	for _, pattern := range duplicateOrderPatterns {
+		if strings.Contains(errStr, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// unsupportedPatterns 交易所不支持该接口时的错误信息特征（如无一键撤销全部订单接口）
+var unsupportedPatterns = []string{"not supported", "unsupported", "not implemented", "不支持"}
+
+// IsUnsupportedError 判断错误是否表示交易所不支持该操作（errors.ErrUnsupported 或对应的错误信息）
+// 这类错误重试无意义，调用方应改用替代实现
+func IsUnsupportedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, errors.ErrUnsupported) {
+		return true
+	}
+
+	errStr := err.Error()
+	for _, pattern := range unsupportedPatterns {
+		if strings.Contains(errStr, pattern) {
+			return true
+		}
+	}
+	return false
+}
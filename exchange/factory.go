@@ -6,23 +6,62 @@ import (
 	"opensqt/exchange/binance"
 	"opensqt/exchange/bitget"
 	"opensqt/exchange/gate"
+	"opensqt/exchange/mock"
+	"opensqt/exchange/okx"
+	"opensqt/logger"
+	"strconv"
+	"strings"
 )
 
 // NewExchange 创建交易所实例
+// 配置了只读密钥（read_only_api_key）时另建一个只读实例，账户/持仓/挂单查询走只读密钥，下单撤单走交易密钥
 func NewExchange(cfg *config.Config) (IExchange, error) {
 	exchangeName := cfg.App.CurrentExchange
+	switch exchangeName {
+	case "bybit":
+		return nil, fmt.Errorf("bybit 尚未实现")
+	case "edgex":
+		return nil, fmt.Errorf("edgeX 尚未实现")
+	}
+
+	exchangeCfg, exists := cfg.Exchanges[exchangeName]
+	if !exists {
+		return nil, fmt.Errorf("%s 配置不存在", exchangeName)
+	}
+	ex, err := newAdapter(cfg, exchangeName, exchangeCfg)
+	if err != nil || exchangeCfg.ReadOnlyAPIKey == "" {
+		return ex, err
+	}
 
+	if exchangeName == "mock" {
+		logger.Warn("⚠️ 模拟交易所不校验密钥，忽略 read_only_api_key")
+		return ex, nil
+	}
+	readOnlyCfg := exchangeCfg
+	readOnlyCfg.APIKey = exchangeCfg.ReadOnlyAPIKey
+	readOnlyCfg.SecretKey = exchangeCfg.ReadOnlySecretKey
+	readOnlyCfg.Passphrase = exchangeCfg.ReadOnlyPassphrase
+	readOnly, err := newAdapter(cfg, exchangeName, readOnlyCfg)
+	if err != nil {
+		return nil, fmt.Errorf("创建只读密钥实例失败: %w", err)
+	}
+	logger.Info("🔑 交易密钥 %s: 下单、撤单、订单查询、WebSocket 推送", maskKey(exchangeCfg.APIKey))
+	logger.Info("🔑 只读密钥 %s: 账户、余额、持仓、挂单查询（止盈/对账/风控监控）", maskKey(exchangeCfg.ReadOnlyAPIKey))
+	return withReadOnlyQueries(ex, readOnly), nil
+}
+
+// newAdapter 按交易所名称和密钥创建适配器实例
+func newAdapter(cfg *config.Config, exchangeName string, exchangeCfg config.ExchangeConfig) (IExchange, error) {
 	switch exchangeName {
 	case "bitget":
-		exchangeCfg, exists := cfg.Exchanges["bitget"]
-		if !exists {
-			return nil, fmt.Errorf("bitget 配置不存在")
-		}
 		// 将 ExchangeConfig 转换为 map[string]string
 		cfgMap := map[string]string{
-			"api_key":    exchangeCfg.APIKey,
-			"secret_key": exchangeCfg.SecretKey,
-			"passphrase": exchangeCfg.Passphrase,
+			"api_key":           exchangeCfg.APIKey,
+			"secret_key":        exchangeCfg.SecretKey,
+			"passphrase":        exchangeCfg.Passphrase,
+			"base_url":          exchangeCfg.BaseURL,
+			"ws_endpoints":      strings.Join(exchangeCfg.WSEndpoints, ","),
+			"ws_failover_after": strconv.Itoa(exchangeCfg.WSFailoverAfter),
 		}
 		adapter, err := bitget.NewBitgetAdapter(cfgMap, cfg.Trading.Symbol)
 		if err != nil {
@@ -31,13 +70,12 @@ func NewExchange(cfg *config.Config) (IExchange, error) {
 		return &bitgetWrapper{adapter: adapter}, nil
 
 	case "binance":
-		exchangeCfg, exists := cfg.Exchanges["binance"]
-		if !exists {
-			return nil, fmt.Errorf("binance 配置不存在")
-		}
 		cfgMap := map[string]string{
-			"api_key":    exchangeCfg.APIKey,
-			"secret_key": exchangeCfg.SecretKey,
+			"api_key":           exchangeCfg.APIKey,
+			"secret_key":        exchangeCfg.SecretKey,
+			"base_url":          exchangeCfg.BaseURL,
+			"ws_endpoints":      strings.Join(exchangeCfg.WSEndpoints, ","),
+			"ws_failover_after": strconv.Itoa(exchangeCfg.WSFailoverAfter),
 		}
 		adapter, err := binance.NewBinanceAdapter(cfgMap, cfg.Trading.Symbol)
 		if err != nil {
@@ -46,14 +84,13 @@ func NewExchange(cfg *config.Config) (IExchange, error) {
 		return &binanceWrapper{adapter: adapter}, nil
 
 	case "gate":
-		exchangeCfg, exists := cfg.Exchanges["gate"]
-		if !exists {
-			return nil, fmt.Errorf("gate 配置不存在")
-		}
 		cfgMap := map[string]string{
-			"api_key":    exchangeCfg.APIKey,
-			"secret_key": exchangeCfg.SecretKey,
-			"settle":     "usdt", // 默认 USDT 永续合约
+			"api_key":           exchangeCfg.APIKey,
+			"secret_key":        exchangeCfg.SecretKey,
+			"settle":            "usdt", // 默认 USDT 永续合约
+			"base_url":          exchangeCfg.BaseURL,
+			"ws_endpoints":      strings.Join(exchangeCfg.WSEndpoints, ","),
+			"ws_failover_after": strconv.Itoa(exchangeCfg.WSFailoverAfter),
 		}
 		adapter, err := gate.NewGateAdapter(cfgMap, cfg.Trading.Symbol)
 		if err != nil {
@@ -61,13 +98,46 @@ func NewExchange(cfg *config.Config) (IExchange, error) {
 		}
 		return &gateWrapper{adapter: adapter}, nil
 
-	case "bybit":
-		return nil, fmt.Errorf("bybit 尚未实现")
+	case "okx":
+		cfgMap := map[string]string{
+			"api_key":           exchangeCfg.APIKey,
+			"secret_key":        exchangeCfg.SecretKey,
+			"passphrase":        exchangeCfg.Passphrase,
+			"base_url":          exchangeCfg.BaseURL,
+			"ws_endpoints":      strings.Join(exchangeCfg.WSEndpoints, ","),
+			"ws_failover_after": strconv.Itoa(exchangeCfg.WSFailoverAfter),
+		}
+		adapter, err := okx.NewOKXAdapter(cfgMap, cfg.Trading.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		return &okxWrapper{adapter: adapter}, nil
 
-	case "edgex":
-		return nil, fmt.Errorf("edgeX 尚未实现")
+	case "mock":
+		cfgMap := map[string]string{
+			"base_url":            exchangeCfg.BaseURL,
+			"fee_rate":            strconv.FormatFloat(exchangeCfg.FeeRate, 'f', -1, 64),
+			"fill_latency_ms":     strconv.Itoa(exchangeCfg.FillLatencyMs),
+			"queue_through_ticks": strconv.Itoa(exchangeCfg.QueueThroughTicks),
+			"action_cooldown_ms":  strconv.Itoa(exchangeCfg.ActionCooldownMs),
+			"executed_qty_mode":   exchangeCfg.ExecutedQtyMode,
+			"funding_rate":        strconv.FormatFloat(exchangeCfg.FundingRate, 'f', -1, 64),
+		}
+		adapter, err := mock.NewMockAdapter(cfgMap, cfg.Trading.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		return &mockWrapper{adapter: adapter}, nil
 
 	default:
 		return nil, fmt.Errorf("不支持的交易所: %s", exchangeName)
 	}
 }
+
+// maskKey 隐藏密钥中间部分（仅用于日志）
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:4] + "****" + key[len(key)-4:]
+}
@@ -3,6 +3,7 @@ package gate
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -19,10 +20,11 @@ type GateAdapter struct {
 	client         *Client
 	wsManager      *WebSocketManager
 	klineWSManager *KlineWebSocketManager
-	symbol         string // 交易对（如 BTCUSDT）
-	gateSymbol     string // Gate格式（如 BTC_USDT）
-	settle         string // 结算币种：usdt 或 btc
-	useWebSocket   bool   // 是否使用 WebSocket 下单
+	wsEndpoints    *utils.WSEndpoints // WebSocket 基础地址（订单流、K线流共用）
+	symbol         string             // 交易对（如 BTCUSDT）
+	gateSymbol     string             // Gate格式（如 BTC_USDT）
+	settle         string             // 结算币种：usdt 或 btc
+	useWebSocket   bool               // 是否使用 WebSocket 下单
 
 	// 订单ID到价格的映射注册回调
 	orderMappingCallback func(orderID int64, price float64)
@@ -57,11 +59,17 @@ func NewGateAdapter(cfg map[string]string, symbol string) (*GateAdapter, error)
 	gateSymbol := convertToGateSymbol(symbol)
 
 	client := NewClient(apiKey, secretKey)
-	wsManager := NewWebSocketManager(apiKey, secretKey, settle)
+	if baseURL := cfg["base_url"]; baseURL != "" {
+		client.baseURL = strings.TrimSuffix(baseURL, "/") // 需包含 /api/v4
+	}
+	failoverAfter, _ := strconv.Atoi(cfg["ws_failover_after"])
+	wsEndpoints := utils.NewWSEndpoints("Gate.io", cfg["ws_endpoints"], DefaultWSEndpoints, failoverAfter)
+	wsManager := NewWebSocketManager(apiKey, secretKey, settle, wsEndpoints)
 
 	adapter := &GateAdapter{
 		client:       client,
 		wsManager:    wsManager,
+		wsEndpoints:  wsEndpoints,
 		symbol:       symbol,
 		gateSymbol:   gateSymbol,
 		settle:       settle,
@@ -113,6 +121,11 @@ func (g *GateAdapter) GetPriceDecimals() int {
 	return g.pricePlace
 }
 
+// ExecutedQtyIsIncremental 订单推送的成交数量语义（成交数量按 size - left 计算，为累计成交数量）
+func (g *GateAdapter) ExecutedQtyIsIncremental() bool {
+	return false
+}
+
 // GetQuantityDecimals 获取数量精度
 func (g *GateAdapter) GetQuantityDecimals() int {
 	return g.volumePlace
@@ -171,6 +184,11 @@ func (g *GateAdapter) fetchContractInfo(ctx context.Context) error {
 	return nil
 }
 
+// RefreshSymbolInfo 重新获取合约信息（运行中交易规则可能变化）
+func (g *GateAdapter) RefreshSymbolInfo(ctx context.Context) error {
+	return g.fetchContractInfo(ctx)
+}
+
 // PlaceOrder 下单
 func (g *GateAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
 	// 使用 REST API 下单（更可靠）
@@ -450,6 +468,51 @@ func (g *GateAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Orde
 	return orders, nil
 }
 
+// userTradesLimit Gate.io 成交记录单页最大条数
+const userTradesLimit = 1000
+
+// GetUserTrades 查询 since 之后的历史成交（Gate.io 不返回单笔已实现盈亏）
+func (g *GateAdapter) GetUserTrades(ctx context.Context, symbol string, since time.Time) ([]*Trade, error) {
+	from, to := since.Unix(), time.Now().Unix()
+
+	var result []*Trade
+	for offset := 0; ; offset += userTradesLimit {
+		futuresTrades, err := g.client.GetMyTrades(ctx, g.settle, g.gateSymbol, from, to, userTradesLimit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("查询成交历史失败: %w", err)
+		}
+
+		for _, ft := range futuresTrades {
+			tradeID, _ := strconv.ParseInt(ft.TradeID, 10, 64)
+			orderID, _ := strconv.ParseInt(ft.OrderID, 10, 64)
+			price, _ := strconv.ParseFloat(ft.Price, 64)
+			fee, _ := strconv.ParseFloat(ft.Fee, 64)
+
+			result = append(result, &Trade{
+				TradeID:  tradeID,
+				OrderID:  orderID,
+				Symbol:   g.symbol,
+				Side:     convertSide(float64(ft.Size)),
+				Price:    price,
+				Quantity: abs(float64(ft.Size)),
+				Fee:      fee,
+				FeeAsset: strings.ToUpper(g.settle),
+				IsMaker:  ft.Role == "maker",
+				Time:     time.UnixMilli(int64(ft.CreateTime * 1000)),
+			})
+		}
+
+		if len(futuresTrades) < userTradesLimit {
+			break
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+	return result, nil
+}
+
 // GetAccount 获取账户信息
 func (g *GateAdapter) GetAccount(ctx context.Context) (*Account, error) {
 	futuresAcc, err := g.client.GetAccount(ctx, g.settle)
@@ -554,28 +617,63 @@ func (g *GateAdapter) GetBalance(ctx context.Context, asset string) (float64, er
 
 // StartOrderStream 启动订单流
 func (g *GateAdapter) StartOrderStream(ctx context.Context, callback func(interface{})) error {
-	// 包装回调函数,将合约张数转换为币数量
-	wrappedCallback := func(update interface{}) {
+	g.wsManager.SetOrderCallback(g.orderUpdateCallback(callback, nil))
+
+	// 如果 WebSocket 未运行，则启动
+	if !g.wsManager.IsRunning() {
+		return g.wsManager.Start(ctx, g.symbol)
+	}
+
+	return nil
+}
+
+// StartMultiSymbolOrderStream 在一条连接上订阅多个交易对的订单更新
+// 其他交易对的张数按各自的合约乘数换算（启动时查询一次合约信息）
+func (g *GateAdapter) StartMultiSymbolOrderStream(ctx context.Context, symbols []string, callback func(interface{})) error {
+	multipliers := make(map[string]float64, len(symbols))
+	for _, symbol := range symbols {
+		if convertToGateSymbol(symbol) == g.gateSymbol {
+			continue
+		}
+		queryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		contract, err := g.client.GetContract(queryCtx, g.settle, convertToGateSymbol(symbol))
+		cancel()
+		if err != nil {
+			return fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+		}
+		multiplier, _ := strconv.ParseFloat(contract.QuantoMultiplier, 64)
+		multipliers[convertFromGateSymbol(convertToGateSymbol(symbol))] = multiplier
+	}
+
+	g.wsManager.SetOrderCallback(g.orderUpdateCallback(callback, multipliers))
+	if err := g.wsManager.SetOrderSymbols(symbols); err != nil {
+		return err
+	}
+	if !g.wsManager.IsRunning() {
+		return g.wsManager.Start(ctx, g.symbol)
+	}
+	return nil
+}
+
+// orderUpdateCallback 将合约张数转换为币数量
+// 本交易对按当前合约乘数换算，multipliers 中的交易对按各自的合约乘数换算
+func (g *GateAdapter) orderUpdateCallback(callback func(interface{}), multipliers map[string]float64) func(interface{}) {
+	return func(update interface{}) {
 		if orderUpdate, ok := update.(OrderUpdate); ok {
 			// Gate.io返回的是合约张数,需要乘以quanto_multiplier转换为币数量
-			if g.quantoMultiplier > 0 {
-				orderUpdate.Quantity = orderUpdate.Quantity * g.quantoMultiplier
-				orderUpdate.ExecutedQty = orderUpdate.ExecutedQty * g.quantoMultiplier
+			multiplier, ok := multipliers[orderUpdate.Symbol]
+			if !ok {
+				multiplier = g.quantoMultiplier
+			}
+			if multiplier > 0 {
+				orderUpdate.Quantity = orderUpdate.Quantity * multiplier
+				orderUpdate.ExecutedQty = orderUpdate.ExecutedQty * multiplier
 			}
 			callback(orderUpdate)
 		} else {
 			callback(update)
 		}
 	}
-
-	g.wsManager.SetOrderCallback(wrappedCallback)
-
-	// 如果 WebSocket 未运行，则启动
-	if !g.wsManager.IsRunning() {
-		return g.wsManager.Start(ctx, g.symbol)
-	}
-
-	return nil
 }
 
 // StopOrderStream 停止订单流
@@ -669,7 +767,7 @@ func (g *GateAdapter) GetHistoricalKlines(ctx context.Context, symbol string, in
 // StartKlineStream 启动K线流
 func (g *GateAdapter) StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(interface{})) error {
 	if g.klineWSManager == nil {
-		g.klineWSManager = NewKlineWebSocketManager(g.settle)
+		g.klineWSManager = NewKlineWebSocketManager(g.settle, g.wsEndpoints)
 	}
 	return g.klineWSManager.Start(ctx, symbols, interval, callback)
 }
@@ -681,6 +779,11 @@ func (g *GateAdapter) StopKlineStream() {
 	}
 }
 
+// ActiveWSEndpoint 当前使用的 WebSocket 地址
+func (g *GateAdapter) ActiveWSEndpoint() string {
+	return g.wsEndpoints.Active()
+}
+
 // calculateDecimalPlaces 计算小数位数
 func calculateDecimalPlaces(value float64) int {
 	if value >= 1 {
@@ -708,3 +811,9 @@ func convertToBitgetSymbol(symbol string) string {
 	// Gate.io 使用下划线格式
 	return convertToGateSymbol(symbol)
 }
+
+// GetMinNotional 获取最小下单金额
+// Gate.io 仅按合约张数（order_size_min）限制最小下单量，没有最小金额限制，返回0
+func (g *GateAdapter) GetMinNotional() float64 {
+	return 0
+}
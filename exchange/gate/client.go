@@ -302,3 +302,22 @@ func (c *Client) GetOpenOrders(ctx context.Context, settle, contract string) ([]
 
 	return orders, nil
 }
+
+// GetMyTrades 获取指定时间范围内的个人成交记录
+// GET /futures/{settle}/my_trades_timerange
+func (c *Client) GetMyTrades(ctx context.Context, settle, contract string, from, to int64, limit, offset int) ([]*FuturesTrade, error) {
+	path := fmt.Sprintf("/futures/%s/my_trades_timerange", settle)
+	queryString := fmt.Sprintf("contract=%s&from=%d&to=%d&limit=%d&offset=%d", contract, from, to, limit, offset)
+
+	respBody, err := c.DoRequest(ctx, "GET", path, queryString, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []*FuturesTrade
+	if err := json.Unmarshal(respBody, &trades); err != nil {
+		return nil, fmt.Errorf("解析成交记录失败: %w", err)
+	}
+
+	return trades, nil
+}
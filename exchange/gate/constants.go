@@ -4,9 +4,12 @@ const (
 	// Gate.io API v4 基础 URL
 	GateBaseURL = "https://api.gateio.ws/api/v4"
 
-	// Gate.io WebSocket URL (USDT永续合约)
-	GateWSURL = "wss://fx-ws.gateio.ws/v4/ws/usdt"
+	// Gate.io 合约 WebSocket 路径（%s 为结算币种 usdt/btc，拼接在 WebSocket 基础地址后）
+	GateWSPathFormat = "/v4/ws/%s"
 
 	// 渠道标识
 	GateChannelID = "opensqt"
 )
+
+// DefaultWSEndpoints Gate.io 合约 WebSocket 基础地址（可通过 ws_endpoints 配置覆盖或追加备用地址）
+var DefaultWSEndpoints = []string{"wss://fx-ws.gateio.ws"}
@@ -8,7 +8,9 @@ import (
 	"sync"
 	"time"
 
+	"opensqt/event"
 	"opensqt/logger"
+	"opensqt/utils"
 
 	"github.com/gorilla/websocket"
 )
@@ -24,11 +26,12 @@ type KlineWebSocketManager struct {
 	reconnectDelay time.Duration
 	pingInterval   time.Duration
 	isRunning      bool
-	settle         string // usdt 或 btc
+	settle         string             // usdt 或 btc
+	endpoints      *utils.WSEndpoints // WebSocket 基础地址（与订单流共用故障切换状态）
 }
 
 // NewKlineWebSocketManager 创建K线WebSocket管理器
-func NewKlineWebSocketManager(settle string) *KlineWebSocketManager {
+func NewKlineWebSocketManager(settle string, endpoints *utils.WSEndpoints) *KlineWebSocketManager {
 	if settle == "" {
 		settle = "usdt" // 默认 USDT 永续合约
 	}
@@ -37,6 +40,7 @@ func NewKlineWebSocketManager(settle string) *KlineWebSocketManager {
 		reconnectDelay: 5 * time.Second,
 		pingInterval:   15 * time.Second,
 		settle:         settle,
+		endpoints:      endpoints,
 	}
 }
 
@@ -73,13 +77,15 @@ func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
 		}
 
 		// Gate.io WebSocket URL
-		wsURL := fmt.Sprintf("wss://fx-ws.gateio.ws/v4/ws/%s", k.settle)
+		base := k.endpoints.Active()
+		wsURL := base + fmt.Sprintf(GateWSPathFormat, k.settle)
 
 		logger.Info("🔗 [Gate K线] 正在连接 WebSocket...")
 
 		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 		if err != nil {
 			logger.Error("❌ [Gate K线] WebSocket连接失败: %v，%v后重试", err, k.reconnectDelay)
+			k.endpoints.OnConnectFailed(base)
 			// 使用 select 等待，可以立即响应 context 取消
 			select {
 			case <-ctx.Done():
@@ -93,11 +99,13 @@ func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
 			continue
 		}
 
+		k.endpoints.OnConnected(base)
 		k.mu.Lock()
 		k.conn = conn
 		k.mu.Unlock()
 
 		logger.Info("✅ [Gate K线] WebSocket已连接")
+		event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "Gate.io", Stream: "kline"})
 
 		// 订阅K线
 		if err := k.subscribe(k.symbols, k.interval); err != nil {
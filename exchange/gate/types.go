@@ -75,6 +75,20 @@ type Account struct {
 	AccountLeverage    int    // 账户级别的杠杆倍数
 }
 
+type Trade struct {
+	TradeID     int64
+	OrderID     int64
+	Symbol      string
+	Side        Side
+	Price       float64
+	Quantity    float64
+	RealizedPnL float64
+	Fee         float64
+	FeeAsset    string
+	IsMaker     bool
+	Time        time.Time
+}
+
 type OrderUpdate struct {
 	OrderID       int64
 	ClientOrderID string
@@ -202,6 +216,19 @@ type FuturesOrder struct {
 	RealisedPoint string  `json:"realised_point"` // 已实现点卡收益
 }
 
+// FuturesTrade Gate.io 合约个人成交记录
+type FuturesTrade struct {
+	TradeID    string  `json:"trade_id"`    // 成交ID
+	CreateTime float64 `json:"create_time"` // 成交时间（秒级时间戳）
+	Contract   string  `json:"contract"`    // 合约名称
+	OrderID    string  `json:"order_id"`    // 订单ID
+	Size       int64   `json:"size"`        // 成交数量（正数买入，负数卖出）
+	Price      string  `json:"price"`       // 成交价格
+	Role       string  `json:"role"`        // 成交角色 taker/maker
+	Text       string  `json:"text"`        // 用户自定义信息
+	Fee        string  `json:"fee"`         // 手续费
+}
+
 // WSRequest WebSocket 请求结构
 type WSRequest struct {
 	Time    int64                  `json:"time"`
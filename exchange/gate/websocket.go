@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"opensqt/event"
 	"opensqt/logger"
 	"opensqt/utils"
 
@@ -20,8 +21,9 @@ type WebSocketManager struct {
 	signer    *Signer
 
 	// 连接管理
-	conn *websocket.Conn
-	mu   sync.RWMutex
+	conn    *websocket.Conn
+	mu      sync.RWMutex
+	writeMu sync.Mutex // 连接不支持并发写（订阅、ping、下单可能来自不同协程）
 
 	// 回调函数
 	orderCallback func(interface{})
@@ -39,13 +41,15 @@ type WebSocketManager struct {
 	// 重连控制
 	reconnectChan    chan struct{}
 	reconnectDelay   time.Duration
-	subscribedSymbol string // 记录订阅的交易对，用于重连后重新订阅
-	settle           string // usdt 或 btc
-	isAuthenticated  bool   // 标记是否已认证
+	subscribedSymbol string             // 记录订阅的交易对，用于重连后重新订阅
+	orderSymbols     []string           // 订单频道订阅的交易对（多交易对共用订单流时设置，为空时只订阅 subscribedSymbol）
+	settle           string             // usdt 或 btc
+	isAuthenticated  bool               // 标记是否已认证
+	endpoints        *utils.WSEndpoints // WebSocket 基础地址（故障切换）
 }
 
 // NewWebSocketManager 创建 WebSocket 管理器
-func NewWebSocketManager(apiKey, secretKey, settle string) *WebSocketManager {
+func NewWebSocketManager(apiKey, secretKey, settle string, endpoints *utils.WSEndpoints) *WebSocketManager {
 	if settle == "" {
 		settle = "usdt"
 	}
@@ -56,6 +60,7 @@ func NewWebSocketManager(apiKey, secretKey, settle string) *WebSocketManager {
 		reconnectChan:  make(chan struct{}, 1),
 		reconnectDelay: 5 * time.Second,
 		settle:         settle,
+		endpoints:      endpoints,
 	}
 }
 
@@ -73,6 +78,19 @@ func (w *WebSocketManager) SetOrderCallback(callback func(interface{})) {
 	w.orderCallback = callback
 }
 
+// SetOrderSymbols 设置订单频道订阅的交易对（重连后按同一列表重新订阅）
+// 已连接时立即在当前连接上补订阅
+func (w *WebSocketManager) SetOrderSymbols(symbols []string) error {
+	w.mu.Lock()
+	w.orderSymbols = append([]string(nil), symbols...)
+	conn := w.conn
+	w.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return w.subscribeOrders(conn, symbols, time.Now().Unix())
+}
+
 // IsRunning 检查 WebSocket 是否运行中
 func (w *WebSocketManager) IsRunning() bool {
 	w.mu.RLock()
@@ -119,13 +137,16 @@ func (w *WebSocketManager) connectLoop() {
 		logger.Info("🔗 [Gate WS] 正在连接...")
 
 		// 连接 Gate.io WebSocket
-		wsURL := fmt.Sprintf("wss://fx-ws.gateio.ws/v4/ws/%s", w.settle)
+		base := w.endpoints.Active()
+		wsURL := base + fmt.Sprintf(GateWSPathFormat, w.settle)
 		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 		if err != nil {
 			logger.Error("❌ [Gate WS] 连接失败: %v，%v后重试", err, w.reconnectDelay)
+			w.endpoints.OnConnectFailed(base)
 			time.Sleep(w.reconnectDelay)
 			continue
 		}
+		w.endpoints.OnConnected(base)
 
 		w.mu.Lock()
 		w.conn = conn
@@ -134,6 +155,7 @@ func (w *WebSocketManager) connectLoop() {
 		w.mu.Unlock()
 
 		logger.Info("✅ [Gate WS] 已连接")
+		event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "Gate.io", Stream: "order"})
 
 		// Gate.io 不需要单独登录,直接在订阅时携带认证信息
 		// 订阅频道
@@ -202,7 +224,7 @@ func (w *WebSocketManager) login() error {
 		return fmt.Errorf("连接未建立")
 	}
 
-	if err := conn.WriteJSON(loginMsg); err != nil {
+	if err := w.writeJSON(conn, loginMsg); err != nil {
 		return fmt.Errorf("发送登录消息失败: %w", err)
 	}
 
@@ -215,21 +237,11 @@ func (w *WebSocketManager) subscribeChannels(symbol string) error {
 	gateSymbol := convertToGateSymbol(symbol)
 	timestamp := time.Now().Unix()
 
-	// 订阅订单更新（私有频道需要认证）
-	ordersSign := w.signer.SignWebSocket("futures.orders", "subscribe", timestamp)
-	ordersMsg := map[string]interface{}{
-		"time":    timestamp,
-		"channel": "futures.orders",
-		"event":   "subscribe",
-		"auth": map[string]interface{}{
-			"method": "api_key",
-			"KEY":    w.apiKey,
-			"SIGN":   ordersSign,
-		},
-		"req_header": map[string]string{
-			"X-Gate-Channel-Id": GateChannelID,
-		},
-		"payload": []string{w.apiKey, gateSymbol},
+	w.mu.RLock()
+	orderSymbols := w.orderSymbols
+	w.mu.RUnlock()
+	if len(orderSymbols) == 0 {
+		orderSymbols = []string{symbol}
 	}
 
 	// 订阅余额更新（私有频道需要认证）
@@ -266,15 +278,15 @@ func (w *WebSocketManager) subscribeChannels(symbol string) error {
 	}
 
 	// 发送订阅消息
-	if err := conn.WriteJSON(ordersMsg); err != nil {
-		return fmt.Errorf("订阅订单频道失败: %w", err)
+	if err := w.subscribeOrders(conn, orderSymbols, timestamp); err != nil {
+		return err
 	}
 
-	if err := conn.WriteJSON(balanceMsg); err != nil {
+	if err := w.writeJSON(conn, balanceMsg); err != nil {
 		return fmt.Errorf("订阅余额频道失败: %w", err)
 	}
 
-	if err := conn.WriteJSON(tickerMsg); err != nil {
+	if err := w.writeJSON(conn, tickerMsg); err != nil {
 		return fmt.Errorf("订阅价格频道失败: %w", err)
 	}
 
@@ -282,6 +294,38 @@ func (w *WebSocketManager) subscribeChannels(symbol string) error {
 	return nil
 }
 
+// subscribeOrders 订阅订单更新（私有频道需要认证，每个交易对一条订阅）
+func (w *WebSocketManager) subscribeOrders(conn *websocket.Conn, symbols []string, timestamp int64) error {
+	ordersSign := w.signer.SignWebSocket("futures.orders", "subscribe", timestamp)
+	for _, symbol := range symbols {
+		ordersMsg := map[string]interface{}{
+			"time":    timestamp,
+			"channel": "futures.orders",
+			"event":   "subscribe",
+			"auth": map[string]interface{}{
+				"method": "api_key",
+				"KEY":    w.apiKey,
+				"SIGN":   ordersSign,
+			},
+			"req_header": map[string]string{
+				"X-Gate-Channel-Id": GateChannelID,
+			},
+			"payload": []string{w.apiKey, convertToGateSymbol(symbol)},
+		}
+		if err := w.writeJSON(conn, ordersMsg); err != nil {
+			return fmt.Errorf("订阅订单频道失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeJSON 发送一条消息（串行化对同一连接的写入）
+func (w *WebSocketManager) writeJSON(conn *websocket.Conn, v interface{}) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	return conn.WriteJSON(v)
+}
+
 // keepAlive 保持连接活跃
 func (w *WebSocketManager) keepAlive(conn *websocket.Conn) {
 	ticker := time.NewTicker(15 * time.Second)
@@ -306,7 +350,7 @@ func (w *WebSocketManager) keepAlive(conn *websocket.Conn) {
 				"channel": "futures.ping",
 			}
 
-			if err := conn.WriteJSON(pingMsg); err != nil {
+			if err := w.writeJSON(conn, pingMsg); err != nil {
 				logger.Warn("⚠️ [Gate WS] Ping 失败: %v", err)
 				return
 			}
@@ -530,7 +574,7 @@ func (w *WebSocketManager) PlaceOrder(order map[string]interface{}) error {
 		return fmt.Errorf("未认证")
 	}
 
-	if err := conn.WriteJSON(orderMsg); err != nil {
+	if err := w.writeJSON(conn, orderMsg); err != nil {
 		return fmt.Errorf("发送下单消息失败: %w", err)
 	}
 
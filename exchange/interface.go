@@ -1,6 +1,9 @@
 package exchange
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // IExchange 交易所接口（所有交易所必须实现）
 type IExchange interface {
@@ -45,6 +48,9 @@ type IExchange interface {
 	// GetBalance 获取余额
 	GetBalance(ctx context.Context, asset string) (float64, error)
 
+	// GetUserTrades 查询 since 之后的历史成交（按时间升序）
+	GetUserTrades(ctx context.Context, symbol string, since time.Time) ([]*Trade, error)
+
 	// === WebSocket ===
 
 	// StartOrderStream 启动订单流（WebSocket）
@@ -88,4 +94,238 @@ type IExchange interface {
 	// GetQuoteAsset 获取计价资产（结算币种）
 	// 例如: BTCUSDT -> USDT, ETHUSDT -> USDT, BTCUSD_PERP -> USD
 	GetQuoteAsset() string
+
+	// GetMinNotional 获取交易所要求的最小下单金额（计价资产），0 表示交易所未提供
+	GetMinNotional() float64
+}
+
+// findCapability 查找交易所实现的可选接口，逐层解开观察包装等外层包装（外层包装实现的优先）
+func findCapability[T any](ex IExchange) (T, bool) {
+	for ex != nil {
+		if capability, ok := ex.(T); ok {
+			return capability, true
+		}
+		u, isWrapper := ex.(interface{ Unwrap() IExchange })
+		if !isWrapper {
+			break
+		}
+		ex = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// IFeeRateProvider 可选接口：支持查询账户实时手续费率的交易所实现
+// 未实现的交易所继续使用配置文件中的 fee_rate
+type IFeeRateProvider interface {
+	// GetTradingFees 获取交易对的 maker / taker 手续费率
+	GetTradingFees(ctx context.Context, symbol string) (maker, taker float64, err error)
+}
+
+// GetTradingFees 查询交易所实时手续费率
+// ok=false 表示该交易所不支持查询（已自动解开观察包装等外层包装）
+func GetTradingFees(ctx context.Context, ex IExchange, symbol string) (maker, taker float64, ok bool, err error) {
+	if provider, ok := findCapability[IFeeRateProvider](ex); ok {
+		maker, taker, err = provider.GetTradingFees(ctx, symbol)
+		return maker, taker, true, err
+	}
+	return 0, 0, false, nil
+}
+
+// IFundingRateProvider 可选接口：支持查询永续合约资金费率的交易所实现
+type IFundingRateProvider interface {
+	// GetFundingRate 获取交易对当前周期的资金费率（如 0.0001 表示 0.01%，正数表示多头支付空头）
+	GetFundingRate(ctx context.Context, symbol string) (float64, error)
+}
+
+// GetFundingRate 查询交易对当前资金费率
+// ok=false 表示该交易所不支持查询（已自动解开观察包装等外层包装）
+func GetFundingRate(ctx context.Context, ex IExchange, symbol string) (rate float64, ok bool, err error) {
+	if provider, ok := findCapability[IFundingRateProvider](ex); ok {
+		rate, err = provider.GetFundingRate(ctx, symbol)
+		return rate, true, err
+	}
+	return 0, false, nil
+}
+
+// IDepthProvider 可选接口：支持查询盘口深度的交易所实现
+type IDepthProvider interface {
+	// GetOrderBookTop 获取买一/卖一价格和挂单量
+	GetOrderBookTop(ctx context.Context, symbol string) (*OrderBookTop, error)
+}
+
+// GetOrderBookTop 查询盘口一档
+// ok=false 表示该交易所不支持深度查询（已自动解开观察包装等外层包装）
+func GetOrderBookTop(ctx context.Context, ex IExchange, symbol string) (top *OrderBookTop, ok bool, err error) {
+	if provider, ok := findCapability[IDepthProvider](ex); ok {
+		top, err = provider.GetOrderBookTop(ctx, symbol)
+		return top, true, err
+	}
+	return nil, false, nil
+}
+
+// ILeverageProvider 可选接口：支持按交易对查询杠杆倍数的交易所实现
+// 持仓和账户信息中查不到杠杆时（如无持仓），安全检查通过它获取交易对实际设置的杠杆
+type ILeverageProvider interface {
+	// GetSymbolLeverage 查询交易对当前设置的杠杆倍数
+	GetSymbolLeverage(symbol string) (int, error)
+}
+
+// GetSymbolLeverage 查询交易对杠杆倍数
+// ok=false 表示该交易所不支持查询（已自动解开观察包装等外层包装）
+func GetSymbolLeverage(ex IExchange, symbol string) (leverage int, ok bool, err error) {
+	if provider, ok := findCapability[ILeverageProvider](ex); ok {
+		leverage, err = provider.GetSymbolLeverage(symbol)
+		return leverage, true, err
+	}
+	return 0, false, nil
+}
+
+// IWSEndpointProvider 可选接口：交易所支持 WebSocket 地址故障切换时实现，返回当前使用的地址
+type IWSEndpointProvider interface {
+	ActiveWSEndpoint() string
+}
+
+// GetActiveWSEndpoint 查询当前使用的 WebSocket 地址（未实现时返回空，已自动解开外层包装）
+func GetActiveWSEndpoint(ex IExchange) string {
+	if provider, ok := findCapability[IWSEndpointProvider](ex); ok {
+		return provider.ActiveWSEndpoint()
+	}
+	return ""
+}
+
+// ITrailingStopPlacer 可选接口：支持交易所原生追踪止损单的交易所实现
+// 追踪止损挂在交易所一侧，机器人停止运行后仍然有效
+type ITrailingStopPlacer interface {
+	// PlaceTrailingStop 下只减仓的追踪止损单
+	PlaceTrailingStop(ctx context.Context, req *TrailingStopRequest) (*Order, error)
+}
+
+// PlaceTrailingStop 下交易所原生追踪止损单
+// ok=false 表示该交易所不支持追踪止损（已自动解开观察包装等外层包装）
+func PlaceTrailingStop(ctx context.Context, ex IExchange, req *TrailingStopRequest) (order *Order, ok bool, err error) {
+	if placer, ok := findCapability[ITrailingStopPlacer](ex); ok {
+		order, err = placer.PlaceTrailingStop(ctx, req)
+		return order, true, err
+	}
+	return nil, false, nil
+}
+
+// 交易所能力名称（配置 safety.required_capabilities 使用）
+const (
+	CapabilityPostOnly   = "post_only"   // 只做 Maker
+	CapabilityReduceOnly = "reduce_only" // 只减仓
+	CapabilityIOC        = "ioc"         // 市价单 / IOC
+	CapabilityNativeStop = "native_stop" // 交易所原生止损单
+)
+
+// Capabilities 交易所适配器支持的订单类型和标记
+// 只有适配器确实把对应参数传给交易所才算支持，参数被静默丢弃的视为不支持
+type Capabilities struct {
+	PostOnly   bool // OrderRequest.PostOnly 按只做 Maker 下单（网格挂单使用）
+	ReduceOnly bool // OrderRequest.ReduceOnly 按只减仓下单（被丢弃时卖单会在没有多仓时开出空仓）
+	IOC        bool // OrderRequest.Type=MARKET / TimeInForce=IOC 按市价立即成交（市价平仓、残余清理使用）
+	NativeStop bool // 交易所原生止损单（追踪止损，见 ITrailingStopPlacer）
+}
+
+// Supports 按能力名称查询是否支持，known=false 表示名称无效
+func (c Capabilities) Supports(name string) (supported, known bool) {
+	switch name {
+	case CapabilityPostOnly:
+		return c.PostOnly, true
+	case CapabilityReduceOnly:
+		return c.ReduceOnly, true
+	case CapabilityIOC:
+		return c.IOC, true
+	case CapabilityNativeStop:
+		return c.NativeStop, true
+	}
+	return false, false
+}
+
+// ICapabilityProvider 可选接口：声明适配器支持的订单类型和标记
+// 启动时按配置的策略检查所需能力，未声明的交易所视为全部不支持
+type ICapabilityProvider interface {
+	// Capabilities 适配器支持的订单类型和标记
+	Capabilities() Capabilities
+}
+
+// GetCapabilities 查询交易所适配器声明的能力
+// 未声明时返回零值（全部不支持，已自动解开观察包装等外层包装）
+func GetCapabilities(ex IExchange) Capabilities {
+	if provider, ok := findCapability[ICapabilityProvider](ex); ok {
+		return provider.Capabilities()
+	}
+	return Capabilities{}
+}
+
+// SupportsReduceOnly 查询交易所适配器是否传递只减仓标记（未声明时返回 false）
+func SupportsReduceOnly(ex IExchange) bool {
+	return GetCapabilities(ex).ReduceOnly
+}
+
+// IActionIntervalProvider 可选接口：交易所对同一交易对的下单/撤单有最小间隔要求时实现
+// 执行器据此主动拉开相邻操作的间隔，避免被交易所以"操作过于频繁"拒绝
+type IActionIntervalProvider interface {
+	// GetMinActionInterval 同一交易对相邻两次下单/撤单的最小间隔（0 表示无要求）
+	GetMinActionInterval() time.Duration
+}
+
+// GetMinActionInterval 查询交易所声明的最小操作间隔
+// 交易所未声明时返回 0（已自动解开观察包装等外层包装）
+func GetMinActionInterval(ex IExchange) time.Duration {
+	if provider, ok := findCapability[IActionIntervalProvider](ex); ok {
+		return provider.GetMinActionInterval()
+	}
+	return 0
+}
+
+// IExecutedQtyProvider 可选接口：声明订单更新中 ExecutedQty 的语义
+// 未实现时按累计成交数量处理
+type IExecutedQtyProvider interface {
+	// ExecutedQtyIsIncremental true 表示每次推送的是本次新增的成交数量，false 表示订单累计成交数量
+	ExecutedQtyIsIncremental() bool
+}
+
+// ExecutedQtyIsIncremental 查询交易所推送的 ExecutedQty 是否为增量
+// 交易所未声明时返回 false，即按累计成交数量处理（已自动解开观察包装等外层包装）
+func ExecutedQtyIsIncremental(ex IExchange) bool {
+	if provider, ok := findCapability[IExecutedQtyProvider](ex); ok {
+		return provider.ExecutedQtyIsIncremental()
+	}
+	return false
+}
+
+// SymbolInfo 交易对的交易规则（价格精度、数量精度、最小下单金额）
+type SymbolInfo struct {
+	PriceDecimals    int
+	QuantityDecimals int
+	MinNotional      float64
+}
+
+// CurrentSymbolInfo 交易所当前缓存的交易规则
+func CurrentSymbolInfo(ex IExchange) SymbolInfo {
+	return SymbolInfo{
+		PriceDecimals:    ex.GetPriceDecimals(),
+		QuantityDecimals: ex.GetQuantityDecimals(),
+		MinNotional:      ex.GetMinNotional(),
+	}
+}
+
+// ISymbolInfoRefresher 可选接口：支持运行中重新获取合约信息的交易所实现
+type ISymbolInfoRefresher interface {
+	// RefreshSymbolInfo 重新获取合约信息并更新缓存（之后 GetPriceDecimals 等返回新值）
+	RefreshSymbolInfo(ctx context.Context) error
+}
+
+// RefreshSymbolInfo 重新获取合约信息，返回刷新后的交易规则
+// ok=false 表示该交易所不支持刷新（已自动解开观察包装等外层包装）
+func RefreshSymbolInfo(ctx context.Context, ex IExchange) (info SymbolInfo, ok bool, err error) {
+	if refresher, ok := findCapability[ISymbolInfoRefresher](ex); ok {
+		if err := refresher.RefreshSymbolInfo(ctx); err != nil {
+			return SymbolInfo{}, true, err
+		}
+		return CurrentSymbolInfo(ex), true, nil
+	}
+	return SymbolInfo{}, false, nil
 }
@@ -0,0 +1,776 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"opensqt/event"
+	"opensqt/logger"
+
+	"github.com/gorilla/websocket"
+)
+
+// MockAdapter 模拟交易所适配器
+// 通过 HTTP + WebSocket 与模拟交易所服务通信，行为与真实适配器一致，
+// 未配置 base_url 时自动在进程内启动一个模拟交易所服务
+type MockAdapter struct {
+	httpClient *http.Client
+	baseURL    string
+	wsURL      string
+	symbol     string
+	server     *Server // 进程内启动的模拟服务（使用外部服务时为 nil）
+
+	priceDecimals    int
+	quantityDecimals int
+	baseAsset        string
+	quoteAsset       string
+	minNotional      float64
+
+	actionCooldown time.Duration // 模拟交易所的操作冷却（同一交易对相邻下单/撤单的最小间隔）
+
+	// 增量成交推送：订单推送的 ExecutedQty 改为距上次推送新增的成交数量（模拟增量语义的交易所）
+	incrementalQty bool
+	reportedQty    map[int64]float64 // 订单ID -> 已推送的累计成交数量
+
+	// WebSocket 连接（价格流、订单流、K线流共用）
+	wsMu          sync.Mutex
+	wsConn        *websocket.Conn
+	wsRunning     bool
+	wsCancel      context.CancelFunc
+	priceCallback func(price float64)
+	orderCallback func(interface{})
+	klineCallback func(candle interface{})
+	klineSymbols  map[string]bool
+
+	latestPrice float64
+	priceMu     sync.RWMutex
+}
+
+// NewMockAdapter 创建模拟交易所适配器
+// cfg 支持: base_url（外部模拟服务地址，为空则启动进程内服务）、fee_rate、initial_price、initial_balance、
+// funding_rate、fill_latency_ms、queue_through_ticks、action_cooldown_ms、executed_qty_mode
+func NewMockAdapter(cfg map[string]string, symbol string) (*MockAdapter, error) {
+	adapter := &MockAdapter{
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		baseURL:      strings.TrimSuffix(cfg["base_url"], "/"),
+		symbol:       symbol,
+		klineSymbols: make(map[string]bool),
+		reportedQty:  make(map[int64]float64),
+	}
+	adapter.incrementalQty = cfg["executed_qty_mode"] == "incremental"
+	if v, err := strconv.Atoi(cfg["action_cooldown_ms"]); err == nil && v > 0 {
+		adapter.actionCooldown = time.Duration(v) * time.Millisecond
+	}
+
+	if adapter.baseURL == "" {
+		serverCfg := DefaultServerConfig(symbol)
+		if v, err := strconv.ParseFloat(cfg["fee_rate"], 64); err == nil && v >= 0 {
+			serverCfg.FeeRate = v
+		}
+		if v, err := strconv.ParseFloat(cfg["funding_rate"], 64); err == nil {
+			serverCfg.FundingRate = v
+		}
+		if v, err := strconv.ParseFloat(cfg["initial_price"], 64); err == nil && v > 0 {
+			serverCfg.InitialPrice = v
+		}
+		if v, err := strconv.ParseFloat(cfg["initial_balance"], 64); err == nil && v > 0 {
+			serverCfg.InitialBalance = v
+		}
+		if v, err := strconv.Atoi(cfg["fill_latency_ms"]); err == nil && v > 0 {
+			serverCfg.FillLatency = time.Duration(v) * time.Millisecond
+		}
+		if v, err := strconv.Atoi(cfg["queue_through_ticks"]); err == nil && v > 0 {
+			serverCfg.QueueThroughTicks = v
+		}
+		serverCfg.ActionCooldown = adapter.actionCooldown
+
+		server := NewServer(serverCfg)
+		if err := server.Start(""); err != nil {
+			return nil, err
+		}
+		adapter.server = server
+		adapter.baseURL = server.URL()
+	}
+
+	adapter.wsURL = strings.Replace(adapter.baseURL, "http", "ws", 1) + "/ws"
+
+	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := adapter.fetchContractInfo(ctxInit); err != nil {
+		logger.Warn("⚠️ [Mock] 获取合约信息失败: %v，使用默认精度", err)
+		adapter.priceDecimals = 2
+		adapter.quantityDecimals = 3
+		adapter.baseAsset, adapter.quoteAsset = splitSymbol(symbol)
+	}
+
+	logger.Info("ℹ️ [Mock 合约信息] %s, 价格精度:%d, 数量精度:%d, 最小下单金额:%.2f, 服务地址:%s",
+		symbol, adapter.priceDecimals, adapter.quantityDecimals, adapter.minNotional, adapter.baseURL)
+
+	return adapter, nil
+}
+
+// fetchContractInfo 获取合约信息（价格精度、数量精度、最小下单金额）
+func (m *MockAdapter) fetchContractInfo(ctx context.Context) error {
+	var contract contractDTO
+	if err := m.doRequest(ctx, "GET", "/api/v1/contract", nil, nil, &contract); err != nil {
+		return err
+	}
+	m.priceDecimals = contract.PriceDecimals
+	m.quantityDecimals = contract.QuantityDecimals
+	m.baseAsset = contract.BaseAsset
+	m.quoteAsset = contract.QuoteAsset
+	m.minNotional = contract.MinNotional
+	return nil
+}
+
+// RefreshSymbolInfo 重新获取合约信息（运行中交易规则可能变化）
+func (m *MockAdapter) RefreshSymbolInfo(ctx context.Context) error {
+	return m.fetchContractInfo(ctx)
+}
+
+// GetName 获取交易所名称
+func (m *MockAdapter) GetName() string {
+	return "Mock"
+}
+
+// Server 返回进程内模拟服务（用于测试中驱动价格或注入故障，外部服务时为 nil）
+func (m *MockAdapter) Server() *Server {
+	return m.server
+}
+
+// doRequest 发送 HTTP 请求并解析 JSON 响应
+func (m *MockAdapter) doRequest(ctx context.Context, method, path string, query url.Values, body interface{}, result interface{}) error {
+	var bodyReader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("序列化请求体失败: %w", err)
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+
+	fullURL := m.baseURL + path
+	if len(query) > 0 {
+		fullURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr apiError
+		if err := json.Unmarshal(respBody, &apiErr); err == nil && apiErr.Code != "" {
+			return fmt.Errorf("mock API 错误: code=%s, msg=%s (状态码=%d)", apiErr.Code, apiErr.Msg, resp.StatusCode)
+		}
+		return fmt.Errorf("mock API 错误: 状态码=%d, 响应=%s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("解析响应失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// PlaceOrder 下单
+func (m *MockAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	orderType := req.Type
+	if orderType == "" {
+		orderType = OrderTypeLimit
+	}
+
+	var dto orderDTO
+	err := m.doRequest(ctx, "POST", "/api/v1/orders", nil, orderRequestDTO{
+		Symbol:        req.Symbol,
+		Side:          string(req.Side),
+		Type:          string(orderType),
+		TimeInForce:   string(req.TimeInForce),
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		ReduceOnly:    req.ReduceOnly,
+		PostOnly:      req.PostOnly,
+		ClientOrderID: req.ClientOrderID,
+	}, &dto)
+	if err != nil {
+		if strings.Contains(err.Error(), "-2019") {
+			return nil, fmt.Errorf("保证金不足: %w", err)
+		}
+		return nil, err
+	}
+
+	return dtoToOrder(dto), nil
+}
+
+// PlaceTrailingStop 下只减仓的追踪止损单（由模拟服务撮合，价格从激活后的极值回撤 callbackRate% 时按最新价成交）
+func (m *MockAdapter) PlaceTrailingStop(ctx context.Context, symbol string, side Side, quantity, callbackRate, activationPrice float64) (*Order, error) {
+	var dto orderDTO
+	err := m.doRequest(ctx, "POST", "/api/v1/orders", nil, orderRequestDTO{
+		Symbol:          symbol,
+		Side:            string(side),
+		Type:            string(OrderTypeTrailingStopMarket),
+		Quantity:        quantity,
+		ReduceOnly:      true,
+		CallbackRate:    callbackRate,
+		ActivationPrice: activationPrice,
+	}, &dto)
+	if err != nil {
+		return nil, fmt.Errorf("下追踪止损单失败: %w", err)
+	}
+	return dtoToOrder(dto), nil
+}
+
+// BatchPlaceOrders 批量下单
+func (m *MockAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	placedOrders := make([]*Order, 0, len(orders))
+	hasMarginError := false
+
+	for _, orderReq := range orders {
+		order, err := m.PlaceOrder(ctx, orderReq)
+		if err != nil {
+			logger.Warn("⚠️ [Mock] 下单失败 %.2f %s: %v", orderReq.Price, orderReq.Side, err)
+			if strings.Contains(err.Error(), "保证金不足") {
+				hasMarginError = true
+			}
+			continue
+		}
+		placedOrders = append(placedOrders, order)
+	}
+
+	return placedOrders, hasMarginError
+}
+
+// CancelOrder 取消订单
+func (m *MockAdapter) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("order_id", strconv.FormatInt(orderID, 10))
+	if err := m.doRequest(ctx, "DELETE", "/api/v1/orders", query, nil, nil); err != nil {
+		return err
+	}
+	logger.Info("✅ [Mock] 取消订单成功: %d", orderID)
+	return nil
+}
+
+// BatchCancelOrders 批量取消订单
+func (m *MockAdapter) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	for i, orderID := range orderIDs {
+		// 模拟交易所没有批量撤单接口，逐个撤单时遵守操作冷却
+		if i > 0 && m.actionCooldown > 0 {
+			time.Sleep(m.actionCooldown)
+		}
+		if err := m.CancelOrder(ctx, symbol, orderID); err != nil {
+			if strings.Contains(err.Error(), "-2011") {
+				logger.Debug("ℹ️ [Mock] 订单 %d 已不存在(可能已成交/已撤销)", orderID)
+				continue
+			}
+			logger.Warn("⚠️ [Mock] 取消订单失败 %d: %v", orderID, err)
+		}
+	}
+	return nil
+}
+
+// GetOrder 查询订单
+func (m *MockAdapter) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("order_id", strconv.FormatInt(orderID, 10))
+
+	var dto orderDTO
+	if err := m.doRequest(ctx, "GET", "/api/v1/order", query, nil, &dto); err != nil {
+		return nil, err
+	}
+	return dtoToOrder(dto), nil
+}
+
+// GetOpenOrders 查询未完成订单
+func (m *MockAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	query := url.Values{}
+	query.Set("symbol", symbol)
+
+	var dtos []orderDTO
+	if err := m.doRequest(ctx, "GET", "/api/v1/orders", query, nil, &dtos); err != nil {
+		return nil, err
+	}
+
+	orders := make([]*Order, 0, len(dtos))
+	for _, dto := range dtos {
+		orders = append(orders, dtoToOrder(dto))
+	}
+	return orders, nil
+}
+
+// GetAccount 获取账户信息
+func (m *MockAdapter) GetAccount(ctx context.Context) (*Account, error) {
+	var dto accountDTO
+	if err := m.doRequest(ctx, "GET", "/api/v1/account", nil, nil, &dto); err != nil {
+		return nil, err
+	}
+
+	positions, err := m.GetPositions(ctx, m.symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Account{
+		TotalWalletBalance: dto.WalletBalance,
+		TotalMarginBalance: dto.MarginBalance,
+		AvailableBalance:   dto.AvailableBalance,
+		Positions:          positions,
+		AccountLeverage:    dto.Leverage,
+	}, nil
+}
+
+// GetPositions 获取持仓信息
+func (m *MockAdapter) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	query := url.Values{}
+	query.Set("symbol", symbol)
+
+	var dtos []positionDTO
+	if err := m.doRequest(ctx, "GET", "/api/v1/positions", query, nil, &dtos); err != nil {
+		return nil, err
+	}
+
+	positions := make([]*Position, 0, len(dtos))
+	for _, dto := range dtos {
+		positions = append(positions, &Position{
+			Symbol:        dto.Symbol,
+			Size:          dto.Size,
+			EntryPrice:    dto.EntryPrice,
+			MarkPrice:     dto.MarkPrice,
+			UnrealizedPNL: dto.UnrealizedPNL,
+			Leverage:      dto.Leverage,
+			MarginType:    "crossed",
+		})
+	}
+	return positions, nil
+}
+
+// GetUserTrades 查询 since 之后的历史成交
+func (m *MockAdapter) GetUserTrades(ctx context.Context, symbol string, since time.Time) ([]*Trade, error) {
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("since", strconv.FormatInt(since.UnixMilli(), 10))
+
+	var dtos []tradeDTO
+	if err := m.doRequest(ctx, "GET", "/api/v1/trades", query, nil, &dtos); err != nil {
+		return nil, fmt.Errorf("查询成交历史失败: %w", err)
+	}
+
+	trades := make([]*Trade, 0, len(dtos))
+	for _, dto := range dtos {
+		trades = append(trades, &Trade{
+			TradeID:     dto.TradeID,
+			OrderID:     dto.OrderID,
+			Symbol:      dto.Symbol,
+			Side:        Side(dto.Side),
+			Price:       dto.Price,
+			Quantity:    dto.Quantity,
+			RealizedPnL: dto.RealizedPnl,
+			Fee:         dto.Fee,
+			FeeAsset:    dto.FeeAsset,
+			IsMaker:     dto.Maker,
+			Time:        time.UnixMilli(dto.Time),
+		})
+	}
+	return trades, nil
+}
+
+// GetBalance 获取余额
+func (m *MockAdapter) GetBalance(ctx context.Context, asset string) (float64, error) {
+	account, err := m.GetAccount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return account.AvailableBalance, nil
+}
+
+// StartOrderStream 启动订单流
+func (m *MockAdapter) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	m.wsMu.Lock()
+	m.orderCallback = callback
+	m.wsMu.Unlock()
+	return m.ensureWebSocket(ctx)
+}
+
+// StopOrderStream 停止订单流
+func (m *MockAdapter) StopOrderStream() error {
+	m.wsMu.Lock()
+	m.orderCallback = nil
+	m.wsMu.Unlock()
+	m.stopWebSocketIfIdle()
+	return nil
+}
+
+// GetLatestPrice 获取最新价格（从 WebSocket 缓存读取）
+func (m *MockAdapter) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	m.priceMu.RLock()
+	price := m.latestPrice
+	m.priceMu.RUnlock()
+
+	if price > 0 {
+		return price, nil
+	}
+	return 0, fmt.Errorf("WebSocket 价格流未就绪或无价格数据")
+}
+
+// StartPriceStream 启动价格流
+func (m *MockAdapter) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	m.wsMu.Lock()
+	m.priceCallback = callback
+	m.wsMu.Unlock()
+	return m.ensureWebSocket(ctx)
+}
+
+// StartKlineStream 启动K线流（模拟服务只推送当前交易对的K线）
+func (m *MockAdapter) StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
+	m.wsMu.Lock()
+	m.klineCallback = callback
+	for _, s := range symbols {
+		m.klineSymbols[s] = true
+	}
+	m.wsMu.Unlock()
+	return m.ensureWebSocket(ctx)
+}
+
+// StopKlineStream 停止K线流
+func (m *MockAdapter) StopKlineStream() error {
+	m.wsMu.Lock()
+	m.klineCallback = nil
+	m.klineSymbols = make(map[string]bool)
+	m.wsMu.Unlock()
+	m.stopWebSocketIfIdle()
+	return nil
+}
+
+// GetHistoricalKlines 获取历史K线数据
+func (m *MockAdapter) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("interval", interval)
+	query.Set("limit", strconv.Itoa(limit))
+
+	var dtos []candleDTO
+	if err := m.doRequest(ctx, "GET", "/api/v1/klines", query, nil, &dtos); err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	candles := make([]*Candle, 0, len(dtos))
+	for _, dto := range dtos {
+		candles = append(candles, dtoToCandle(dto))
+	}
+	return candles, nil
+}
+
+// GetPriceDecimals 获取价格精度
+func (m *MockAdapter) GetPriceDecimals() int {
+	return m.priceDecimals
+}
+
+// GetQuantityDecimals 获取数量精度
+func (m *MockAdapter) GetQuantityDecimals() int {
+	return m.quantityDecimals
+}
+
+// GetBaseAsset 获取基础资产
+func (m *MockAdapter) GetBaseAsset() string {
+	return m.baseAsset
+}
+
+// GetQuoteAsset 获取计价资产
+func (m *MockAdapter) GetQuoteAsset() string {
+	return m.quoteAsset
+}
+
+// GetTradingFees 获取当前手续费率（maker, taker）
+func (m *MockAdapter) GetTradingFees(ctx context.Context, symbol string) (float64, float64, error) {
+	var dto feeDTO
+	if err := m.doRequest(ctx, "GET", "/api/v1/fee", nil, nil, &dto); err != nil {
+		return 0, 0, fmt.Errorf("获取手续费率失败: %w", err)
+	}
+	return dto.MakerRate, dto.TakerRate, nil
+}
+
+// GetFundingRate 获取当前资金费率
+func (m *MockAdapter) GetFundingRate(ctx context.Context, symbol string) (float64, error) {
+	var dto fundingDTO
+	if err := m.doRequest(ctx, "GET", "/api/v1/funding", nil, nil, &dto); err != nil {
+		return 0, fmt.Errorf("获取资金费率失败: %w", err)
+	}
+	return dto.FundingRate, nil
+}
+
+// GetOrderBookTop 获取买一/卖一（返回 bidPrice, bidQty, askPrice, askQty）
+func (m *MockAdapter) GetOrderBookTop(ctx context.Context, symbol string) (float64, float64, float64, float64, error) {
+	query := url.Values{}
+	query.Set("symbol", symbol)
+
+	var dto depthDTO
+	if err := m.doRequest(ctx, "GET", "/api/v1/depth", query, nil, &dto); err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("获取深度失败: %w", err)
+	}
+	return dto.BidPrice, dto.BidQty, dto.AskPrice, dto.AskQty, nil
+}
+
+// ExecutedQtyIsIncremental 订单推送的成交数量语义（executed_qty_mode: incremental 时模拟增量推送）
+func (m *MockAdapter) ExecutedQtyIsIncremental() bool {
+	return m.incrementalQty
+}
+
+// GetMinActionInterval 同一交易对相邻下单/撤单的最小间隔（与模拟交易所的操作冷却一致）
+func (m *MockAdapter) GetMinActionInterval() time.Duration {
+	return m.actionCooldown
+}
+
+// GetMinNotional 获取最小下单金额
+func (m *MockAdapter) GetMinNotional() float64 {
+	return m.minNotional
+}
+
+// Close 停止 WebSocket 及进程内模拟服务
+func (m *MockAdapter) Close() {
+	m.wsMu.Lock()
+	if m.wsCancel != nil {
+		m.wsCancel()
+	}
+	m.wsMu.Unlock()
+
+	if m.server != nil {
+		m.server.Stop()
+	}
+}
+
+// ensureWebSocket 确保共享的 WebSocket 连接已启动
+func (m *MockAdapter) ensureWebSocket(ctx context.Context) error {
+	m.wsMu.Lock()
+	if m.wsRunning {
+		m.wsMu.Unlock()
+		return nil
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, m.wsURL, nil)
+	if err != nil {
+		m.wsMu.Unlock()
+		return fmt.Errorf("连接模拟交易所 WebSocket 失败: %w", err)
+	}
+
+	wsCtx, cancel := context.WithCancel(ctx)
+	m.wsConn = conn
+	m.wsRunning = true
+	m.wsCancel = cancel
+	m.wsMu.Unlock()
+
+	go m.readLoop(wsCtx, conn)
+	logger.Info("✅ [Mock] WebSocket 已连接: %s", m.wsURL)
+	event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "Mock", Stream: "all"})
+	return nil
+}
+
+// stopWebSocketIfIdle 所有流都停止后关闭连接
+func (m *MockAdapter) stopWebSocketIfIdle() {
+	m.wsMu.Lock()
+	defer m.wsMu.Unlock()
+
+	if m.priceCallback == nil && m.orderCallback == nil && m.klineCallback == nil && m.wsCancel != nil {
+		m.wsCancel()
+	}
+}
+
+// readLoop 读取 WebSocket 推送并分发（断线后自动重连）
+func (m *MockAdapter) readLoop(ctx context.Context, conn *websocket.Conn) {
+	defer func() {
+		m.wsMu.Lock()
+		m.wsRunning = false
+		m.wsConn = nil
+		m.wsMu.Unlock()
+	}()
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Warn("⚠️ [Mock] WebSocket 断开: %v，5秒后重连", err)
+			conn = m.reconnect(ctx)
+			if conn == nil {
+				return
+			}
+			continue
+		}
+
+		var msg wsRawMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		m.dispatch(msg)
+	}
+}
+
+// reconnect 重连 WebSocket，ctx 取消时返回 nil
+func (m *MockAdapter) reconnect(ctx context.Context) *websocket.Conn {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(5 * time.Second):
+		}
+
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, m.wsURL, nil)
+		if err != nil {
+			logger.Warn("⚠️ [Mock] WebSocket 重连失败: %v", err)
+			continue
+		}
+
+		m.wsMu.Lock()
+		m.wsConn = conn
+		m.wsMu.Unlock()
+
+		go func() {
+			<-ctx.Done()
+			conn.Close()
+		}()
+		logger.Info("✅ [Mock] WebSocket 重连成功")
+		event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "Mock", Stream: "all"})
+		return conn
+	}
+}
+
+// dispatch 分发推送消息
+func (m *MockAdapter) dispatch(msg wsRawMessage) {
+	m.wsMu.Lock()
+	priceCallback := m.priceCallback
+	orderCallback := m.orderCallback
+	klineCallback := m.klineCallback
+	klineSymbols := m.klineSymbols
+	m.wsMu.Unlock()
+
+	switch msg.Channel {
+	case "ticker":
+		var ticker tickerDTO
+		if err := json.Unmarshal(msg.Data, &ticker); err != nil || ticker.Symbol != m.symbol {
+			return
+		}
+		m.priceMu.Lock()
+		m.latestPrice = ticker.Price
+		m.priceMu.Unlock()
+		if priceCallback != nil {
+			priceCallback(ticker.Price)
+		}
+
+	case "orders":
+		var dto orderDTO
+		if err := json.Unmarshal(msg.Data, &dto); err != nil || orderCallback == nil {
+			return
+		}
+		executedQty := dto.ExecutedQty
+		if m.incrementalQty {
+			executedQty = dto.ExecutedQty - m.reportedQty[dto.OrderID]
+			switch OrderStatus(dto.Status) {
+			case OrderStatusFilled, OrderStatusCanceled, OrderStatusRejected, OrderStatusExpired:
+				delete(m.reportedQty, dto.OrderID)
+			default:
+				m.reportedQty[dto.OrderID] = dto.ExecutedQty
+			}
+		}
+		// 构造通用的 OrderUpdate 结构（避免导入 exchange 包）
+		orderCallback(struct {
+			OrderID       int64
+			ClientOrderID string
+			Symbol        string
+			Side          string
+			Type          string
+			Status        string
+			Price         float64
+			Quantity      float64
+			ExecutedQty   float64
+			AvgPrice      float64
+			UpdateTime    int64
+		}{
+			OrderID:       dto.OrderID,
+			ClientOrderID: dto.ClientOrderID,
+			Symbol:        dto.Symbol,
+			Side:          dto.Side,
+			Type:          dto.Type,
+			Status:        dto.Status,
+			Price:         dto.Price,
+			Quantity:      dto.Quantity,
+			ExecutedQty:   executedQty,
+			AvgPrice:      dto.AvgPrice,
+			UpdateTime:    dto.UpdateTime,
+		})
+
+	case "kline":
+		var dto candleDTO
+		if err := json.Unmarshal(msg.Data, &dto); err != nil || klineCallback == nil || !klineSymbols[dto.Symbol] {
+			return
+		}
+		klineCallback(dtoToCandle(dto))
+	}
+}
+
+// dtoToOrder 转换订单格式
+func dtoToOrder(dto orderDTO) *Order {
+	return &Order{
+		OrderID:       dto.OrderID,
+		ClientOrderID: dto.ClientOrderID,
+		Symbol:        dto.Symbol,
+		Side:          Side(dto.Side),
+		Type:          OrderType(dto.Type),
+		Price:         dto.Price,
+		Quantity:      dto.Quantity,
+		ExecutedQty:   dto.ExecutedQty,
+		AvgPrice:      dto.AvgPrice,
+		Status:        OrderStatus(dto.Status),
+		CreatedAt:     time.UnixMilli(dto.CreateTime),
+		UpdateTime:    dto.UpdateTime,
+	}
+}
+
+// dtoToCandle 转换K线格式
+func dtoToCandle(dto candleDTO) *Candle {
+	return &Candle{
+		Symbol:    dto.Symbol,
+		Open:      dto.Open,
+		High:      dto.High,
+		Low:       dto.Low,
+		Close:     dto.Close,
+		Volume:    dto.Volume,
+		Timestamp: dto.Timestamp,
+		IsClosed:  dto.IsClosed,
+	}
+}
+
+// ActiveWSEndpoint 当前使用的 WebSocket 地址（模拟交易所只有一个地址）
+func (m *MockAdapter) ActiveWSEndpoint() string {
+	return m.wsURL
+}
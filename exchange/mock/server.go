@@ -0,0 +1,973 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"opensqt/logger"
+	"opensqt/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxTradeHistory 保留的成交记录条数上限
+const maxTradeHistory = 10000
+
+// ServerConfig 模拟交易所服务配置
+type ServerConfig struct {
+	Symbol           string        // 交易对（如 ETHUSDT）
+	InitialPrice     float64       // 初始价格
+	InitialBalance   float64       // 初始钱包余额（USDT）
+	Leverage         int           // 全仓杠杆倍数
+	FeeRate          float64       // 成交手续费率
+	FundingRate      float64       // 资金费率（仅供查询，不实际结算）
+	PriceDecimals    int           // 价格精度
+	QuantityDecimals int           // 数量精度
+	TickInterval     time.Duration // 价格随机游走间隔（0表示不自动变价，仅由 SetPrice 驱动）
+	TickStepPercent  float64       // 每次随机游走的最大幅度（百分比，如 0.05 表示 0.05%）
+	MinNotional      float64       // 最小下单金额（0表示不限制）
+
+	// 成交模型（默认触价立即成交，最乐观，规则见 utils.FillModel，与 system.dry_run 的模拟成交共用）。
+	// 只作用于挂单（maker），下单时即可成交的吃单仍立即成交
+	FillLatency       time.Duration
+	QueueThroughTicks int
+
+	// 同一交易对相邻两次下单/撤单的最小间隔，间隔不足的请求以"操作过于频繁"拒绝（0表示不限制）
+	ActionCooldown time.Duration
+}
+
+// DefaultServerConfig 返回默认的模拟交易所配置
+func DefaultServerConfig(symbol string) ServerConfig {
+	return ServerConfig{
+		Symbol:           symbol,
+		InitialPrice:     3000,
+		InitialBalance:   10000,
+		Leverage:         10,
+		FeeRate:          0.0002,
+		PriceDecimals:    2,
+		QuantityDecimals: 3,
+		TickInterval:     500 * time.Millisecond,
+		TickStepPercent:  0.05,
+		MinNotional:      5,
+	}
+}
+
+// Server 进程内模拟交易所（HTTP + WebSocket）
+// 实现做市系统需要的最小接口面：账户、持仓、下单/撤单、价格流、订单流、K线，
+// 用于 CI 和本地端到端验证，不接触真实交易所
+type Server struct {
+	cfg        ServerConfig
+	httpServer *http.Server
+	listener   net.Listener
+	upgrader   websocket.Upgrader
+
+	mu            sync.Mutex
+	price         float64
+	walletBalance float64
+	positionSize  float64
+	entryPrice    float64
+	feeRate       float64
+	fundingRate   float64
+	orders        map[int64]*orderDTO
+	nextOrderID   int64
+	candle        *candleDTO
+	trades        []tradeDTO
+	nextTradeID   int64
+	lastAction    time.Time // 最近一次下单/撤单时间（用于 ActionCooldown）
+	failNext      int       // 注入故障：接下来 N 次 REST 请求直接返回 failStatus
+	failStatus    int
+	rng           *rand.Rand
+
+	fillModel *utils.FillModel // 挂单成交模型（FillLatency / QueueThroughTicks，自带锁）
+
+	clientsMu sync.Mutex
+	clients   map[*wsClient]struct{}
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// wsClient WebSocket 客户端连接
+type wsClient struct {
+	conn *websocket.Conn
+	send chan []byte
+}
+
+// NewServer 创建模拟交易所服务
+func NewServer(cfg ServerConfig) *Server {
+	defaults := DefaultServerConfig(cfg.Symbol)
+	if cfg.InitialPrice <= 0 {
+		cfg.InitialPrice = defaults.InitialPrice
+	}
+	if cfg.InitialBalance <= 0 {
+		cfg.InitialBalance = defaults.InitialBalance
+	}
+	if cfg.Leverage <= 0 {
+		cfg.Leverage = defaults.Leverage
+	}
+	if cfg.PriceDecimals <= 0 {
+		cfg.PriceDecimals = defaults.PriceDecimals
+	}
+	if cfg.QuantityDecimals <= 0 {
+		cfg.QuantityDecimals = defaults.QuantityDecimals
+	}
+
+	return &Server{
+		cfg:           cfg,
+		upgrader:      websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
+		price:         cfg.InitialPrice,
+		walletBalance: cfg.InitialBalance,
+		feeRate:       cfg.FeeRate,
+		fundingRate:   cfg.FundingRate,
+		orders:        make(map[int64]*orderDTO),
+		fillModel:     utils.NewFillModel(cfg.FillLatency, cfg.QueueThroughTicks),
+		nextOrderID:   1000000,
+		nextTradeID:   5000000,
+		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		clients:       make(map[*wsClient]struct{}),
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// Start 启动服务（addr 为空时监听 127.0.0.1 随机端口）
+func (s *Server) Start(addr string) error {
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("模拟交易所监听失败: %w", err)
+	}
+	s.listener = listener
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/contract", s.handleContract)
+	mux.HandleFunc("POST /api/v1/contract", s.handleSetContract)
+	mux.HandleFunc("GET /api/v1/account", s.handleAccount)
+	mux.HandleFunc("GET /api/v1/positions", s.handlePositions)
+	mux.HandleFunc("POST /api/v1/orders", s.handlePlaceOrder)
+	mux.HandleFunc("GET /api/v1/orders", s.handleOpenOrders)
+	mux.HandleFunc("DELETE /api/v1/orders", s.handleCancelOrder)
+	mux.HandleFunc("GET /api/v1/order", s.handleGetOrder)
+	mux.HandleFunc("GET /api/v1/ticker", s.handleTicker)
+	mux.HandleFunc("POST /api/v1/ticker", s.handleSetPrice)
+	mux.HandleFunc("GET /api/v1/klines", s.handleKlines)
+	mux.HandleFunc("GET /api/v1/depth", s.handleDepth)
+	mux.HandleFunc("GET /api/v1/trades", s.handleTrades)
+	mux.HandleFunc("GET /api/v1/fee", s.handleFee)
+	mux.HandleFunc("POST /api/v1/fee", s.handleSetFee)
+	mux.HandleFunc("GET /api/v1/funding", s.handleFunding)
+	mux.HandleFunc("POST /api/v1/funding", s.handleSetFunding)
+	mux.HandleFunc("POST /api/v1/transfer", s.handleTransfer)
+	mux.HandleFunc("/ws", s.handleWebSocket)
+
+	s.httpServer = &http.Server{Handler: s.withFailureInjection(mux)}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Error("❌ [Mock] 模拟交易所服务异常退出: %v", err)
+		}
+	}()
+
+	if s.cfg.TickInterval > 0 {
+		s.wg.Add(1)
+		go s.randomWalk()
+	}
+
+	logger.Info("✅ [Mock] 模拟交易所已启动: %s (交易对: %s, 初始价格: %.*f, 初始余额: %.2f)",
+		s.URL(), s.cfg.Symbol, s.cfg.PriceDecimals, s.cfg.InitialPrice, s.cfg.InitialBalance)
+	return nil
+}
+
+// Stop 停止服务
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		if s.httpServer != nil {
+			s.httpServer.Close()
+		}
+
+		s.clientsMu.Lock()
+		for c := range s.clients {
+			c.conn.Close()
+			delete(s.clients, c)
+		}
+		s.clientsMu.Unlock()
+
+		s.wg.Wait()
+	})
+}
+
+// URL 返回 REST 基础地址
+func (s *Server) URL() string {
+	if s.listener == nil {
+		return ""
+	}
+	return "http://" + s.listener.Addr().String()
+}
+
+// WSURL 返回 WebSocket 地址
+func (s *Server) WSURL() string {
+	if s.listener == nil {
+		return ""
+	}
+	return "ws://" + s.listener.Addr().String() + "/ws"
+}
+
+// Price 返回当前价格
+func (s *Server) Price() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.price
+}
+
+// SetPrice 设置最新价格并撮合挂单（测试用于驱动行情）
+func (s *Server) SetPrice(price float64) {
+	if price <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	s.price = roundTo(price, s.cfg.PriceDecimals)
+	messages := s.updateCandleLocked()
+	messages = append(messages, wsMessage{Channel: "ticker", Data: tickerDTO{Symbol: s.cfg.Symbol, Price: s.price}})
+	messages = append(messages, s.matchLocked()...)
+	s.mu.Unlock()
+
+	for _, msg := range messages {
+		s.broadcast(msg)
+	}
+}
+
+// InjectFailures 注入故障：接下来 n 次 REST 请求返回指定 HTTP 状态码（如 502）
+func (s *Server) InjectFailures(n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = n
+	s.failStatus = status
+}
+
+// FeeRate 当前手续费率
+func (s *Server) FeeRate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.feeRate
+}
+
+// SetFeeRate 设置手续费率（模拟 VIP 等级变化）
+func (s *Server) SetFeeRate(rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.feeRate = rate
+}
+
+// FundingRate 当前资金费率
+func (s *Server) FundingRate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fundingRate
+}
+
+// SetFundingRate 设置资金费率（模拟多空失衡时资金费率升高）
+func (s *Server) SetFundingRate(rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fundingRate = rate
+}
+
+// randomWalk 价格随机游走
+func (s *Server) randomWalk() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.TickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			step := (s.rng.Float64()*2 - 1) * s.cfg.TickStepPercent / 100
+			next := s.price * (1 + step)
+			s.mu.Unlock()
+			s.SetPrice(next)
+		}
+	}
+}
+
+// withFailureInjection 故障注入中间件（WebSocket 不受影响）
+func (s *Server) withFailureInjection(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws" {
+			s.mu.Lock()
+			fail := s.failNext > 0
+			status := s.failStatus
+			if fail {
+				s.failNext--
+			}
+			s.mu.Unlock()
+
+			if fail {
+				http.Error(w, http.StatusText(status), status)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleContract(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.contract())
+}
+
+// contract 当前合约信息
+func (s *Server) contract() contractDTO {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	base, quote := splitSymbol(s.cfg.Symbol)
+	return contractDTO{
+		Symbol:           s.cfg.Symbol,
+		BaseAsset:        base,
+		QuoteAsset:       quote,
+		PriceDecimals:    s.cfg.PriceDecimals,
+		QuantityDecimals: s.cfg.QuantityDecimals,
+		MinNotional:      s.cfg.MinNotional,
+	}
+}
+
+// handleSetContract 修改交易规则（模拟交易所调整价格精度/数量精度/最小下单金额），未提供的字段保持不变
+func (s *Server) handleSetContract(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PriceDecimals    *int     `json:"price_decimals"`
+		QuantityDecimals *int     `json:"quantity_decimals"`
+		MinNotional      *float64 `json:"min_notional"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil ||
+		(req.PriceDecimals != nil && *req.PriceDecimals < 0) ||
+		(req.QuantityDecimals != nil && *req.QuantityDecimals < 0) ||
+		(req.MinNotional != nil && *req.MinNotional < 0) {
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-1102", Msg: "invalid contract filters"})
+		return
+	}
+	s.mu.Lock()
+	if req.PriceDecimals != nil {
+		s.cfg.PriceDecimals = *req.PriceDecimals
+	}
+	if req.QuantityDecimals != nil {
+		s.cfg.QuantityDecimals = *req.QuantityDecimals
+	}
+	if req.MinNotional != nil {
+		s.cfg.MinNotional = *req.MinNotional
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.contract())
+}
+
+func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unrealized := s.unrealizedPNLLocked()
+	writeJSON(w, http.StatusOK, accountDTO{
+		WalletBalance:    s.walletBalance,
+		MarginBalance:    s.walletBalance + unrealized,
+		AvailableBalance: s.availableLocked(),
+		Leverage:         s.cfg.Leverage,
+	})
+}
+
+func (s *Server) handlePositions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	positions := make([]positionDTO, 0, 1)
+	if s.positionSize != 0 {
+		positions = append(positions, positionDTO{
+			Symbol:        s.cfg.Symbol,
+			Size:          s.positionSize,
+			EntryPrice:    s.entryPrice,
+			MarkPrice:     s.price,
+			UnrealizedPNL: s.unrealizedPNLLocked(),
+			Leverage:      s.cfg.Leverage,
+		})
+	}
+	writeJSON(w, http.StatusOK, positions)
+}
+
+func (s *Server) handlePlaceOrder(w http.ResponseWriter, r *http.Request) {
+	var req orderRequestDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-1102", Msg: "invalid request body"})
+		return
+	}
+
+	if req.Symbol != s.cfg.Symbol {
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-1121", Msg: "invalid symbol"})
+		return
+	}
+	if req.Quantity <= 0 {
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-4003", Msg: "quantity less than zero"})
+		return
+	}
+	if req.Type == "" {
+		req.Type = string(OrderTypeLimit)
+	}
+	if req.Type == string(OrderTypeTrailingStopMarket) && (req.CallbackRate <= 0 || req.CallbackRate > 10) {
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-2007", Msg: "Invalid callBack rate"})
+		return
+	}
+
+	s.mu.Lock()
+	if remaining := s.actionCooldownLocked(); remaining > 0 {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusTooManyRequests, apiError{Code: "-4200", Msg: fmt.Sprintf("order action too frequent, retry after %dms", remaining.Milliseconds())})
+		return
+	}
+	if req.Price > 0 && math.Abs(req.Price-roundTo(req.Price, s.cfg.PriceDecimals)) > 1e-9 {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-4014", Msg: "Price not increased by tick size"})
+		return
+	}
+	now := time.Now().UnixMilli()
+	order := &orderDTO{
+		ClientOrderID: req.ClientOrderID,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Price:         roundTo(req.Price, s.cfg.PriceDecimals),
+		Quantity:      roundTo(req.Quantity, s.cfg.QuantityDecimals),
+		Status:        string(OrderStatusNew),
+		ReduceOnly:    req.ReduceOnly,
+		CreateTime:    now,
+		UpdateTime:    now,
+
+		CallbackRate:    req.CallbackRate,
+		ActivationPrice: roundTo(req.ActivationPrice, s.cfg.PriceDecimals),
+	}
+	trailing := order.Type == string(OrderTypeTrailingStopMarket)
+	if trailing && order.ActivationPrice <= 0 {
+		order.trailExtreme = s.price
+	}
+
+	// 追踪止损挂在服务端，价格从极值回撤到位后由撮合按最新价成交
+	marketable := !trailing && (order.Type == string(OrderTypeMarket) ||
+		(order.Side == string(SideBuy) && order.Price >= s.price) ||
+		(order.Side == string(SideSell) && order.Price <= s.price))
+
+	if req.PostOnly && marketable {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-5022", Msg: "Post Only order would immediately match"})
+		return
+	}
+
+	if s.cfg.MinNotional > 0 && !order.ReduceOnly && order.Type == string(OrderTypeLimit) && order.Quantity*order.Price < s.cfg.MinNotional {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-4164", Msg: fmt.Sprintf("Order's notional must be no smaller than %g", s.cfg.MinNotional)})
+		return
+	}
+
+	if order.ReduceOnly && order.Side == string(SideSell) && s.positionSize <= 0 {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-2022", Msg: "ReduceOnly Order is rejected"})
+		return
+	}
+
+	if order.Side == string(SideBuy) {
+		notional := order.Quantity * s.price
+		if order.Type == string(OrderTypeLimit) {
+			notional = order.Quantity * order.Price
+		}
+		if notional/float64(s.cfg.Leverage) > s.availableLocked() {
+			s.mu.Unlock()
+			writeJSON(w, http.StatusBadRequest, apiError{Code: "-2019", Msg: "Margin is insufficient"})
+			return
+		}
+	}
+
+	s.nextOrderID++
+	order.OrderID = s.nextOrderID
+	s.orders[order.OrderID] = order
+	messages := []wsMessage{{Channel: "orders", Data: *order}}
+
+	// 可立即成交的订单按当前价格成交（吃单）
+	if marketable {
+		s.fillLocked(order, s.price, false)
+		delete(s.orders, order.OrderID)
+		messages = append(messages, wsMessage{Channel: "orders", Data: *order})
+	} else if order.Type == string(OrderTypeMarket) || req.TimeInForce == string(TimeInForceIOC) {
+		order.Status = string(OrderStatusExpired)
+		delete(s.orders, order.OrderID)
+		messages = append(messages, wsMessage{Channel: "orders", Data: *order})
+	}
+	result := *order
+	s.mu.Unlock()
+
+	for _, msg := range messages {
+		s.broadcast(msg)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleOpenOrders(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]orderDTO, 0, len(s.orders))
+	for _, o := range s.orders {
+		orders = append(orders, *o)
+	}
+	writeJSON(w, http.StatusOK, orders)
+}
+
+func (s *Server) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(r.URL.Query().Get("order_id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-1102", Msg: "invalid order_id"})
+		return
+	}
+
+	s.mu.Lock()
+	if remaining := s.actionCooldownLocked(); remaining > 0 {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusTooManyRequests, apiError{Code: "-4200", Msg: fmt.Sprintf("order action too frequent, retry after %dms", remaining.Milliseconds())})
+		return
+	}
+	order, exists := s.orders[orderID]
+	if !exists {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-2011", Msg: "Unknown order sent."})
+		return
+	}
+	order.Status = string(OrderStatusCanceled)
+	order.UpdateTime = time.Now().UnixMilli()
+	delete(s.orders, orderID)
+	result := *order
+	s.mu.Unlock()
+
+	s.broadcast(wsMessage{Channel: "orders", Data: result})
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(r.URL.Query().Get("order_id"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-1102", Msg: "invalid order_id"})
+		return
+	}
+
+	s.mu.Lock()
+	order, exists := s.orders[orderID]
+	var result orderDTO
+	if exists {
+		result = *order
+	}
+	s.mu.Unlock()
+
+	if !exists {
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-2013", Msg: "Order does not exist."})
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleTicker(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, tickerDTO{Symbol: s.cfg.Symbol, Price: s.Price()})
+}
+
+func (s *Server) handleSetPrice(w http.ResponseWriter, r *http.Request) {
+	var req tickerDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Price <= 0 {
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-1102", Msg: "invalid price"})
+		return
+	}
+	s.SetPrice(req.Price)
+	writeJSON(w, http.StatusOK, tickerDTO{Symbol: s.cfg.Symbol, Price: s.Price()})
+}
+
+// handleDepth 合成盘口：买一/卖一紧贴当前价格，挂单量随机，模拟不平衡的订单簿
+func (s *Server) handleDepth(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	priceDecimals := s.cfg.PriceDecimals
+	tick := math.Pow(10, -float64(priceDecimals))
+	price := s.price
+	bidQty := roundTo(1+s.rng.Float64()*9, s.cfg.QuantityDecimals)
+	askQty := roundTo(1+s.rng.Float64()*9, s.cfg.QuantityDecimals)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, depthDTO{
+		Symbol:   s.cfg.Symbol,
+		BidPrice: roundTo(price-tick, priceDecimals),
+		BidQty:   bidQty,
+		AskPrice: roundTo(price+tick, priceDecimals),
+		AskQty:   askQty,
+	})
+}
+
+// handleTrades 查询成交历史（since 为毫秒时间戳）
+func (s *Server) handleTrades(w http.ResponseWriter, r *http.Request) {
+	since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trades := make([]tradeDTO, 0)
+	for _, t := range s.trades {
+		if t.Time >= since {
+			trades = append(trades, t)
+		}
+	}
+	writeJSON(w, http.StatusOK, trades)
+}
+
+func (s *Server) handleFee(w http.ResponseWriter, r *http.Request) {
+	rate := s.FeeRate()
+	writeJSON(w, http.StatusOK, feeDTO{Symbol: s.cfg.Symbol, MakerRate: rate, TakerRate: rate})
+}
+
+func (s *Server) handleSetFee(w http.ResponseWriter, r *http.Request) {
+	var req feeDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MakerRate < 0 {
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-1102", Msg: "invalid fee rate"})
+		return
+	}
+	s.SetFeeRate(req.MakerRate)
+	rate := s.FeeRate()
+	writeJSON(w, http.StatusOK, feeDTO{Symbol: s.cfg.Symbol, MakerRate: rate, TakerRate: rate})
+}
+
+func (s *Server) handleFunding(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, fundingDTO{Symbol: s.cfg.Symbol, FundingRate: s.FundingRate()})
+}
+
+func (s *Server) handleSetFunding(w http.ResponseWriter, r *http.Request) {
+	var req fundingDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-1102", Msg: "invalid funding rate"})
+		return
+	}
+	s.SetFundingRate(req.FundingRate)
+	writeJSON(w, http.StatusOK, fundingDTO{Symbol: s.cfg.Symbol, FundingRate: s.FundingRate()})
+}
+
+// handleTransfer 模拟充值（amount > 0）或提现（amount < 0），直接调整钱包余额
+func (s *Server) handleTransfer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount float64 `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount == 0 {
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-1102", Msg: "invalid transfer amount"})
+		return
+	}
+	s.mu.Lock()
+	if s.walletBalance+req.Amount < 0 {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusBadRequest, apiError{Code: "-2019", Msg: "insufficient balance"})
+		return
+	}
+	s.walletBalance += req.Amount
+	balance := s.walletBalance
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]float64{"wallet_balance": balance})
+}
+
+func (s *Server) handleKlines(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	// 生成平稳的历史K线：交易对使用当前价格，其他币种使用固定价格
+	price := 100.0
+	if symbol == s.cfg.Symbol {
+		price = s.Price()
+	}
+
+	minute := time.Now().Truncate(time.Minute)
+	candles := make([]candleDTO, 0, limit)
+	for i := limit; i > 0; i-- {
+		candles = append(candles, candleDTO{
+			Symbol:    symbol,
+			Open:      price,
+			High:      price,
+			Low:       price,
+			Close:     price,
+			Volume:    100,
+			Timestamp: minute.Add(-time.Duration(i) * time.Minute).UnixMilli(),
+			IsClosed:  true,
+		})
+	}
+	writeJSON(w, http.StatusOK, candles)
+}
+
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	client := &wsClient{conn: conn, send: make(chan []byte, 256)}
+	s.clientsMu.Lock()
+	s.clients[client] = struct{}{}
+	s.clientsMu.Unlock()
+
+	// 写协程
+	go func() {
+		for msg := range client.send {
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		}
+	}()
+
+	// 读协程（仅用于检测断开）
+	go func() {
+		defer func() {
+			s.clientsMu.Lock()
+			if _, ok := s.clients[client]; ok {
+				delete(s.clients, client)
+			}
+			s.clientsMu.Unlock()
+			close(client.send)
+			conn.Close()
+		}()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// 连接后立即推送一次最新价格
+	s.sendTo(client, wsMessage{Channel: "ticker", Data: tickerDTO{Symbol: s.cfg.Symbol, Price: s.Price()}})
+}
+
+// broadcast 向所有客户端推送消息（客户端积压时丢弃，避免阻塞撮合）
+func (s *Server) broadcast(msg wsMessage) {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	for c := range s.clients {
+		s.sendToLocked(c, msg)
+	}
+}
+
+func (s *Server) sendTo(c *wsClient, msg wsMessage) {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	if _, ok := s.clients[c]; ok {
+		s.sendToLocked(c, msg)
+	}
+}
+
+func (s *Server) sendToLocked(c *wsClient, msg wsMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	select {
+	case c.send <- data:
+	default:
+	}
+}
+
+// matchLocked 按最新价格撮合挂单（调用前必须持有 mu）
+func (s *Server) matchLocked() []wsMessage {
+	var messages []wsMessage
+	for id, o := range s.orders {
+		if s.fillModel.Pending(id) {
+			continue
+		}
+		if o.Type == string(OrderTypeTrailingStopMarket) {
+			if s.trailTriggeredLocked(o) {
+				s.fillLocked(o, s.price, false)
+				delete(s.orders, id)
+				messages = append(messages, wsMessage{Channel: "orders", Data: *o})
+			}
+			continue
+		}
+		if s.fillModel.Crossed(o.Side == string(SideBuy), o.Price, s.price, s.cfg.PriceDecimals) {
+			if s.fillModel.Schedule(id, func() { s.delayedFill(id) }) {
+				continue
+			}
+			s.fillLocked(o, o.Price, true)
+			delete(s.orders, id)
+			messages = append(messages, wsMessage{Channel: "orders", Data: *o})
+		}
+	}
+	return messages
+}
+
+// trailTriggeredLocked 更新追踪止损的极值价格，返回是否已从极值回撤 callback_rate（调用前必须持有 mu）
+func (s *Server) trailTriggeredLocked(o *orderDTO) bool {
+	sell := o.Side == string(SideSell)
+	if o.trailExtreme == 0 {
+		if (sell && s.price < o.ActivationPrice) || (!sell && s.price > o.ActivationPrice) {
+			return false
+		}
+		o.trailExtreme = s.price
+	}
+	if sell {
+		o.trailExtreme = math.Max(o.trailExtreme, s.price)
+		return s.price <= o.trailExtreme*(1-o.CallbackRate/100)
+	}
+	o.trailExtreme = math.Min(o.trailExtreme, s.price)
+	return s.price >= o.trailExtreme*(1+o.CallbackRate/100)
+}
+
+// actionCooldownLocked 检查操作冷却，返回还需等待的时间（0 表示允许并记录本次操作，调用前必须持有 mu）
+func (s *Server) actionCooldownLocked() time.Duration {
+	if s.cfg.ActionCooldown <= 0 {
+		return 0
+	}
+	now := time.Now()
+	if elapsed := now.Sub(s.lastAction); elapsed < s.cfg.ActionCooldown {
+		return s.cfg.ActionCooldown - elapsed
+	}
+	s.lastAction = now
+	return 0
+}
+
+// delayedFill 成交延迟到期后成交订单（期间已撤单则忽略）
+func (s *Server) delayedFill(id int64) {
+	s.mu.Lock()
+	o, exists := s.orders[id]
+	if !exists {
+		s.mu.Unlock()
+		return
+	}
+	s.fillLocked(o, o.Price, true)
+	delete(s.orders, id)
+	msg := wsMessage{Channel: "orders", Data: *o}
+	s.mu.Unlock()
+
+	s.broadcast(msg)
+}
+
+// fillLocked 全部成交订单，更新持仓和余额并记录成交（调用前必须持有 mu）
+func (s *Server) fillLocked(o *orderDTO, fillPrice float64, maker bool) {
+	qty := o.Quantity - o.ExecutedQty
+	var realizedPnl float64
+
+	if o.Side == string(SideBuy) {
+		newSize := s.positionSize + qty
+		if newSize > 0 {
+			s.entryPrice = (s.entryPrice*s.positionSize + fillPrice*qty) / newSize
+		}
+		s.positionSize = newSize
+	} else {
+		closeQty := math.Min(qty, s.positionSize)
+		if closeQty > 0 {
+			realizedPnl = (fillPrice - s.entryPrice) * closeQty
+			s.walletBalance += realizedPnl
+		}
+		s.positionSize -= qty
+		if math.Abs(s.positionSize) < 1e-9 {
+			s.positionSize = 0
+			s.entryPrice = 0
+		}
+	}
+
+	fee := fillPrice * qty * s.feeRate
+	s.walletBalance -= fee
+
+	_, quote := splitSymbol(s.cfg.Symbol)
+	s.nextTradeID++
+	s.trades = append(s.trades, tradeDTO{
+		TradeID:     s.nextTradeID,
+		OrderID:     o.OrderID,
+		Symbol:      o.Symbol,
+		Side:        o.Side,
+		Price:       fillPrice,
+		Quantity:    qty,
+		RealizedPnl: realizedPnl,
+		Fee:         fee,
+		FeeAsset:    quote,
+		Maker:       maker,
+		Time:        time.Now().UnixMilli(),
+	})
+	if len(s.trades) > maxTradeHistory {
+		s.trades = s.trades[len(s.trades)-maxTradeHistory:]
+	}
+
+	o.ExecutedQty = o.Quantity
+	o.AvgPrice = fillPrice
+	o.Status = string(OrderStatusFilled)
+	o.UpdateTime = time.Now().UnixMilli()
+}
+
+// updateCandleLocked 更新当前分钟K线，跨分钟时推送已完结K线（调用前必须持有 mu）
+func (s *Server) updateCandleLocked() []wsMessage {
+	var messages []wsMessage
+	minute := time.Now().Truncate(time.Minute).UnixMilli()
+
+	if s.candle != nil && s.candle.Timestamp != minute {
+		closed := *s.candle
+		closed.IsClosed = true
+		messages = append(messages, wsMessage{Channel: "kline", Data: closed})
+		s.candle = nil
+	}
+
+	if s.candle == nil {
+		s.candle = &candleDTO{Symbol: s.cfg.Symbol, Open: s.price, High: s.price, Low: s.price, Timestamp: minute}
+	}
+	s.candle.Close = s.price
+	s.candle.High = math.Max(s.candle.High, s.price)
+	s.candle.Low = math.Min(s.candle.Low, s.price)
+	s.candle.Volume += 1
+
+	messages = append(messages, wsMessage{Channel: "kline", Data: *s.candle})
+	return messages
+}
+
+// unrealizedPNLLocked 未实现盈亏（调用前必须持有 mu）
+func (s *Server) unrealizedPNLLocked() float64 {
+	return (s.price - s.entryPrice) * s.positionSize
+}
+
+// availableLocked 可用保证金 = 保证金余额 - 持仓保证金 - 挂单冻结保证金（调用前必须持有 mu）
+func (s *Server) availableLocked() float64 {
+	leverage := float64(s.cfg.Leverage)
+	used := math.Abs(s.positionSize) * s.price / leverage
+	for _, o := range s.orders {
+		if o.Side == string(SideBuy) {
+			used += o.Quantity * o.Price / leverage
+		}
+	}
+	return s.walletBalance + s.unrealizedPNLLocked() - used
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// roundTo 按小数位数四舍五入
+func roundTo(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
+
+// splitSymbol 拆分交易对为基础资产和计价资产
+func splitSymbol(symbol string) (string, string) {
+	for _, quote := range []string{"USDT", "USDC", "USD"} {
+		if strings.HasSuffix(symbol, quote) && len(symbol) > len(quote) {
+			return strings.TrimSuffix(symbol, quote), quote
+		}
+	}
+	return symbol, "USDT"
+}
@@ -0,0 +1,267 @@
+package mock
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 为了避免循环导入，在这里定义需要的类型
+// 这些类型应该与 exchange/types.go 中的定义保持一致
+
+type Side string
+type OrderType string
+type OrderStatus string
+type TimeInForce string
+
+const (
+	SideBuy  Side = "BUY"
+	SideSell Side = "SELL"
+)
+
+const (
+	OrderTypeLimit              OrderType = "LIMIT"
+	OrderTypeMarket             OrderType = "MARKET"
+	OrderTypeTrailingStopMarket OrderType = "TRAILING_STOP_MARKET"
+)
+
+const (
+	OrderStatusNew             OrderStatus = "NEW"
+	OrderStatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	OrderStatusFilled          OrderStatus = "FILLED"
+	OrderStatusCanceled        OrderStatus = "CANCELED"
+	OrderStatusRejected        OrderStatus = "REJECTED"
+	OrderStatusExpired         OrderStatus = "EXPIRED"
+)
+
+const (
+	TimeInForceGTC TimeInForce = "GTC"
+	TimeInForceIOC TimeInForce = "IOC"
+)
+
+type OrderRequest struct {
+	Symbol        string
+	Side          Side
+	Type          OrderType
+	TimeInForce   TimeInForce
+	Quantity      float64
+	Price         float64
+	ReduceOnly    bool
+	PostOnly      bool
+	PriceDecimals int
+	ClientOrderID string
+}
+
+type Order struct {
+	OrderID       int64
+	ClientOrderID string
+	Symbol        string
+	Side          Side
+	Type          OrderType
+	Price         float64
+	Quantity      float64
+	ExecutedQty   float64
+	AvgPrice      float64
+	Status        OrderStatus
+	CreatedAt     time.Time
+	UpdateTime    int64
+}
+
+type Position struct {
+	Symbol         string
+	Size           float64
+	EntryPrice     float64
+	MarkPrice      float64
+	UnrealizedPNL  float64
+	Leverage       int
+	MarginType     string
+	IsolatedMargin float64
+}
+
+type Account struct {
+	TotalWalletBalance float64
+	TotalMarginBalance float64
+	AvailableBalance   float64
+	Positions          []*Position
+	AccountLeverage    int
+}
+
+type Trade struct {
+	TradeID     int64
+	OrderID     int64
+	Symbol      string
+	Side        Side
+	Price       float64
+	Quantity    float64
+	RealizedPnL float64
+	Fee         float64
+	FeeAsset    string
+	IsMaker     bool
+	Time        time.Time
+}
+
+type OrderUpdate struct {
+	OrderID       int64
+	ClientOrderID string
+	Symbol        string
+	Side          Side
+	Type          OrderType
+	Status        OrderStatus
+	Price         float64
+	Quantity      float64
+	ExecutedQty   float64
+	AvgPrice      float64
+	UpdateTime    int64
+}
+
+// Candle K线数据
+type Candle struct {
+	Symbol    string
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	Volume    float64
+	Timestamp int64
+	IsClosed  bool // K线是否完结
+}
+
+// ============ 模拟交易所线路协议（REST + WebSocket 共用） ============
+
+// apiError 错误响应
+type apiError struct {
+	Code string `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// contractDTO 合约信息
+type contractDTO struct {
+	Symbol           string  `json:"symbol"`
+	BaseAsset        string  `json:"base_asset"`
+	QuoteAsset       string  `json:"quote_asset"`
+	PriceDecimals    int     `json:"price_decimals"`
+	QuantityDecimals int     `json:"quantity_decimals"`
+	MinNotional      float64 `json:"min_notional"`
+}
+
+// accountDTO 账户信息
+type accountDTO struct {
+	WalletBalance    float64 `json:"wallet_balance"`
+	MarginBalance    float64 `json:"margin_balance"`
+	AvailableBalance float64 `json:"available_balance"`
+	Leverage         int     `json:"leverage"`
+}
+
+// positionDTO 持仓信息
+type positionDTO struct {
+	Symbol        string  `json:"symbol"`
+	Size          float64 `json:"size"`
+	EntryPrice    float64 `json:"entry_price"`
+	MarkPrice     float64 `json:"mark_price"`
+	UnrealizedPNL float64 `json:"unrealized_pnl"`
+	Leverage      int     `json:"leverage"`
+}
+
+// orderRequestDTO 下单请求
+type orderRequestDTO struct {
+	Symbol        string  `json:"symbol"`
+	Side          string  `json:"side"`
+	Type          string  `json:"type"`
+	TimeInForce   string  `json:"time_in_force"`
+	Price         float64 `json:"price"`
+	Quantity      float64 `json:"quantity"`
+	ReduceOnly    bool    `json:"reduce_only"`
+	PostOnly      bool    `json:"post_only"`
+	ClientOrderID string  `json:"client_order_id"`
+	// 追踪止损（type=TRAILING_STOP_MARKET）：回撤比例（百分比）和激活价格（0 表示立即激活）
+	CallbackRate    float64 `json:"callback_rate,omitempty"`
+	ActivationPrice float64 `json:"activation_price,omitempty"`
+}
+
+// orderDTO 订单信息（REST 响应与 WebSocket 订单推送共用）
+type orderDTO struct {
+	OrderID       int64   `json:"order_id"`
+	ClientOrderID string  `json:"client_order_id"`
+	Symbol        string  `json:"symbol"`
+	Side          string  `json:"side"`
+	Type          string  `json:"type"`
+	Price         float64 `json:"price"`
+	Quantity      float64 `json:"quantity"`
+	ExecutedQty   float64 `json:"executed_qty"`
+	AvgPrice      float64 `json:"avg_price"`
+	Status        string  `json:"status"`
+	ReduceOnly    bool    `json:"reduce_only"`
+	CreateTime    int64   `json:"create_time"` // 毫秒
+	UpdateTime    int64   `json:"update_time"` // 毫秒
+
+	CallbackRate    float64 `json:"callback_rate,omitempty"`
+	ActivationPrice float64 `json:"activation_price,omitempty"`
+	trailExtreme    float64 // 追踪止损激活后的最高价（卖单）/最低价（买单），0 表示尚未激活
+}
+
+// tradeDTO 成交记录
+type tradeDTO struct {
+	TradeID     int64   `json:"trade_id"`
+	OrderID     int64   `json:"order_id"`
+	Symbol      string  `json:"symbol"`
+	Side        string  `json:"side"`
+	Price       float64 `json:"price"`
+	Quantity    float64 `json:"quantity"`
+	RealizedPnl float64 `json:"realized_pnl"`
+	Fee         float64 `json:"fee"`
+	FeeAsset    string  `json:"fee_asset"`
+	Maker       bool    `json:"maker"`
+	Time        int64   `json:"time"` // 毫秒
+}
+
+// candleDTO K线
+type candleDTO struct {
+	Symbol    string  `json:"symbol"`
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Volume    float64 `json:"volume"`
+	Timestamp int64   `json:"timestamp"`
+	IsClosed  bool    `json:"is_closed"`
+}
+
+// tickerDTO 最新价格
+type tickerDTO struct {
+	Symbol string  `json:"symbol"`
+	Price  float64 `json:"price"`
+}
+
+// feeDTO 手续费率（模拟交易所 maker/taker 同费率）
+type feeDTO struct {
+	Symbol    string  `json:"symbol"`
+	MakerRate float64 `json:"maker_rate"`
+	TakerRate float64 `json:"taker_rate"`
+}
+
+// fundingDTO 资金费率
+type fundingDTO struct {
+	Symbol      string  `json:"symbol"`
+	FundingRate float64 `json:"funding_rate"`
+}
+
+// depthDTO 盘口一档
+type depthDTO struct {
+	Symbol   string  `json:"symbol"`
+	BidPrice float64 `json:"bid_price"`
+	BidQty   float64 `json:"bid_qty"`
+	AskPrice float64 `json:"ask_price"`
+	AskQty   float64 `json:"ask_qty"`
+}
+
+// wsMessage WebSocket 推送消息
+// Channel: ticker / orders / kline
+type wsMessage struct {
+	Channel string      `json:"channel"`
+	Data    interface{} `json:"data"`
+}
+
+// wsRawMessage WebSocket 推送消息（客户端解析用）
+type wsRawMessage struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
@@ -0,0 +1,1140 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"opensqt/logger"
+	"opensqt/utils"
+)
+
+// 为了避免循环导入，在这里定义需要的接口和类型
+// 这些类型应该与 exchange/types.go 中的定义保持一致
+
+type Side string
+type OrderType string
+type OrderStatus string
+type TimeInForce string
+
+const (
+	SideBuy  Side = "BUY"
+	SideSell Side = "SELL"
+)
+
+const (
+	OrderTypeLimit  OrderType = "LIMIT"
+	OrderTypeMarket OrderType = "MARKET"
+)
+
+const (
+	OrderStatusNew OrderStatus = "NEW"
+)
+
+const (
+	TimeInForceGTC TimeInForce = "GTC"
+	TimeInForceIOC TimeInForce = "IOC"
+	TimeInForceFOK TimeInForce = "FOK"
+)
+
+type OrderRequest struct {
+	Symbol        string
+	Side          Side
+	Type          OrderType
+	TimeInForce   TimeInForce
+	Quantity      float64
+	Price         float64
+	ReduceOnly    bool
+	PostOnly      bool // 是否只做 Maker（Post Only）
+	PriceDecimals int
+	ClientOrderID string // 自定义订单ID
+}
+
+type Order struct {
+	OrderID       int64
+	ClientOrderID string
+	Symbol        string
+	Side          Side
+	Type          OrderType
+	Price         float64
+	Quantity      float64
+	ExecutedQty   float64
+	AvgPrice      float64
+	Status        OrderStatus
+	CreatedAt     time.Time
+	UpdateTime    int64
+}
+
+type Position struct {
+	Symbol         string
+	Size           float64
+	EntryPrice     float64
+	MarkPrice      float64
+	UnrealizedPNL  float64
+	Leverage       int
+	MarginType     string
+	IsolatedMargin float64
+}
+
+type Account struct {
+	TotalWalletBalance float64
+	TotalMarginBalance float64
+	AvailableBalance   float64
+	Positions          []*Position
+	PosMode            string // "long_short_mode" or "net_mode"
+	AccountLeverage    int    // 交易对的全仓杠杆倍数
+}
+
+type Trade struct {
+	TradeID     int64
+	OrderID     int64
+	Symbol      string
+	Side        Side
+	Price       float64
+	Quantity    float64
+	RealizedPnL float64
+	Fee         float64
+	FeeAsset    string
+	IsMaker     bool
+	Time        time.Time
+}
+
+type OrderUpdate struct {
+	OrderID       int64
+	ClientOrderID string
+	Symbol        string
+	Side          Side
+	Type          OrderType
+	Status        OrderStatus
+	Price         float64
+	Quantity      float64
+	ExecutedQty   float64
+	AvgPrice      float64
+	UpdateTime    int64
+}
+
+// DefaultWSEndpoints OKX WebSocket 基础地址（可通过 ws_endpoints 配置覆盖或追加备用地址）
+var DefaultWSEndpoints = []string{"wss://ws.okx.com:8443"}
+
+// OKXAdapter OKX 永续合约适配器
+// OKX 合约以张为单位下单，适配器对外统一使用基础币数量（张数 × 合约面值 ctVal）
+type OKXAdapter struct {
+	client         *Client
+	wsManager      *WebSocketManager
+	klineWSManager *KlineWebSocketManager
+	wsEndpoints    *utils.WSEndpoints // WebSocket 基础地址（订单流、价格流、K线流共用）
+	symbol         string             // 交易对（如 ETHUSDT，对外使用）
+	instID         string             // OKX 产品ID（如 ETH-USDT-SWAP）
+
+	// 订单ID到价格的映射注册回调
+	// 用于在下单成功后立即建立映射，避免 WebSocket 更新先到导致找不到槽位
+	orderMappingCallback func(orderID int64, price float64)
+
+	posMode     string  // 持仓模式：long_short_mode（双向）或 net_mode（单向）
+	ctVal       float64 // 合约面值（每张对应的基础币数量）
+	lotSz       float64 // 下单张数精度
+	minSz       float64 // 最小下单张数
+	lotPlace    int     // 张数小数位
+	volumePlace int     // 基础币数量小数位（lotSz × ctVal）
+	pricePlace  int     // 价格小数位（从 tickSz 获取）
+	baseAsset   string  // 基础资产（合约面值币种），如 ETH
+	quoteAsset  string  // 计价资产（结算币种），如 USDT
+}
+
+// NewOKXAdapter 创建 OKX 适配器
+func NewOKXAdapter(cfg map[string]string, symbol string) (*OKXAdapter, error) {
+	apiKey := cfg["api_key"]
+	secretKey := cfg["secret_key"]
+	passphrase := cfg["passphrase"]
+
+	if apiKey == "" || secretKey == "" || passphrase == "" {
+		return nil, fmt.Errorf("okx API 配置不完整")
+	}
+
+	client := NewClient(apiKey, secretKey, passphrase)
+	if baseURL := cfg["base_url"]; baseURL != "" {
+		client.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+	failoverAfter, _ := strconv.Atoi(cfg["ws_failover_after"])
+	wsEndpoints := utils.NewWSEndpoints("OKX", cfg["ws_endpoints"], DefaultWSEndpoints, failoverAfter)
+
+	adapter := &OKXAdapter{
+		client:      client,
+		wsManager:   NewWebSocketManager(apiKey, secretKey, passphrase, wsEndpoints),
+		wsEndpoints: wsEndpoints,
+		symbol:      symbol,
+		instID:      convertToOKXInstID(symbol),
+	}
+
+	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 1. 获取合约信息：合约面值未知时无法换算下单张数，不能使用默认值
+	if err := adapter.fetchInstrumentInfo(ctxInit); err != nil {
+		return nil, fmt.Errorf("获取合约信息失败: %w", err)
+	}
+
+	// 2. 获取持仓模式（双向持仓下单需要 posSide）
+	if err := adapter.fetchAccountConfig(ctxInit); err != nil {
+		logger.Warn("⚠️ [OKX] 获取账户配置失败: %v，按单向持仓处理", err)
+		adapter.posMode = "net_mode"
+	}
+
+	return adapter, nil
+}
+
+// GetName 获取交易所名称
+func (o *OKXAdapter) GetName() string {
+	return "OKX"
+}
+
+// okxInstrument 合约信息（/api/v5/public/instruments 返回的字段）
+type okxInstrument struct {
+	InstID    string `json:"instId"`
+	TickSz    string `json:"tickSz"`    // 价格精度
+	LotSz     string `json:"lotSz"`     // 张数精度
+	MinSz     string `json:"minSz"`     // 最小下单张数
+	CtVal     string `json:"ctVal"`     // 合约面值
+	CtValCcy  string `json:"ctValCcy"`  // 合约面值币种
+	SettleCcy string `json:"settleCcy"` // 结算币种
+	State     string `json:"state"`     // live / suspend / preopen
+}
+
+// queryInstrument 查询永续合约信息
+func (o *OKXAdapter) queryInstrument(ctx context.Context, instID string) (*okxInstrument, error) {
+	path := fmt.Sprintf("/api/v5/public/instruments?instType=SWAP&instId=%s", instID)
+	resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []okxInstrument
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析合约信息失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return nil, fmt.Errorf("未找到合约信息: %s", instID)
+	}
+	return &dataList[0], nil
+}
+
+// fetchInstrumentInfo 获取合约信息（价格精度、张数精度、合约面值等）
+func (o *OKXAdapter) fetchInstrumentInfo(ctx context.Context) error {
+	inst, err := o.queryInstrument(ctx, o.instID)
+	if err != nil {
+		return err
+	}
+
+	ctVal, _ := strconv.ParseFloat(inst.CtVal, 64)
+	lotSz, _ := strconv.ParseFloat(inst.LotSz, 64)
+	if ctVal <= 0 || lotSz <= 0 {
+		return fmt.Errorf("合约信息无效: ctVal=%s, lotSz=%s", inst.CtVal, inst.LotSz)
+	}
+
+	o.ctVal = ctVal
+	o.lotSz = lotSz
+	o.minSz, _ = strconv.ParseFloat(inst.MinSz, 64)
+	o.lotPlace = decimalPlaces(inst.LotSz)
+	o.pricePlace = decimalPlaces(inst.TickSz)
+	o.volumePlace = volumeDecimals(lotSz, ctVal)
+	o.baseAsset = inst.CtValCcy
+	o.quoteAsset = inst.SettleCcy
+
+	if inst.State != "" && inst.State != "live" {
+		logger.Warn("⚠️ [OKX 合约信息] %s 当前状态为 %s，可能无法交易", o.instID, inst.State)
+	}
+	logger.Info("ℹ️ [OKX 合约信息] %s - 合约面值:%s %s, 数量精度:%d, 价格精度:%d, 最小张数:%s, 结算币种:%s",
+		o.instID, inst.CtVal, o.baseAsset, o.volumePlace, o.pricePlace, inst.MinSz, o.quoteAsset)
+	return nil
+}
+
+// fetchAccountConfig 获取账户配置（持仓模式）
+func (o *OKXAdapter) fetchAccountConfig(ctx context.Context) error {
+	resp, err := o.client.DoRequest(ctx, "GET", "/api/v5/account/config", nil)
+	if err != nil {
+		return err
+	}
+
+	var dataList []struct {
+		PosMode string `json:"posMode"` // long_short_mode / net_mode
+		AcctLv  string `json:"acctLv"`  // 1 简单交易模式，2 单币种保证金，3 跨币种保证金，4 组合保证金
+	}
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return fmt.Errorf("解析账户配置失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return fmt.Errorf("账户配置为空")
+	}
+
+	o.posMode = dataList[0].PosMode
+	if dataList[0].AcctLv == "1" {
+		logger.Warn("⚠️ [OKX] 当前账户为简单交易模式，不能交易永续合约，请在交易设置中切换为单币种保证金或跨币种保证金模式")
+	}
+
+	posModeDesc := "单向持仓"
+	if o.posMode == "long_short_mode" {
+		posModeDesc = "双向持仓"
+	}
+	logger.Info("ℹ️ [OKX] 持仓模式: %s (%s)", posModeDesc, o.posMode)
+	return nil
+}
+
+// RefreshSymbolInfo 重新获取合约信息（运行中交易规则可能变化）
+func (o *OKXAdapter) RefreshSymbolInfo(ctx context.Context) error {
+	return o.fetchInstrumentInfo(ctx)
+}
+
+// toContracts 基础币数量换算为下单张数（按张数精度四舍五入）
+func (o *OKXAdapter) toContracts(quantity float64) (string, error) {
+	contracts := math.Round(quantity/o.ctVal/o.lotSz) * o.lotSz
+	if contracts <= 0 || contracts < o.minSz {
+		return "", fmt.Errorf("下单数量 %g 换算为 %.*f 张，小于最小下单张数 %g（合约面值 %g）",
+			quantity, o.lotPlace, contracts, o.minSz, o.ctVal)
+	}
+	return fmt.Sprintf("%.*f", o.lotPlace, contracts), nil
+}
+
+// fromContracts 张数换算为基础币数量
+func (o *OKXAdapter) fromContracts(contracts float64) float64 {
+	return roundTo(contracts*o.ctVal, o.volumePlace)
+}
+
+// PlaceOrder 下单（使用 REST API）
+func (o *OKXAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	size, err := o.toContracts(req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	// 订单类型：PostOnly 优先，市价单不传价格
+	ordType := "limit"
+	switch {
+	case req.Type == OrderTypeMarket:
+		ordType = "market"
+	case req.PostOnly:
+		ordType = "post_only"
+	case req.TimeInForce == TimeInForceIOC:
+		ordType = "ioc"
+	case req.TimeInForce == TimeInForceFOK:
+		ordType = "fok"
+	}
+
+	body := map[string]interface{}{
+		"instId":  o.instID,
+		"tdMode":  "cross",
+		"side":    strings.ToLower(string(req.Side)),
+		"ordType": ordType,
+		"sz":      size,
+	}
+	if ordType != "market" {
+		body["px"] = fmt.Sprintf("%.*f", o.pricePlace, req.Price)
+	}
+	if req.ClientOrderID != "" {
+		body["clOrdId"] = utils.AddBrokerPrefix("okx", req.ClientOrderID)
+	}
+
+	// 🔥 双向持仓必须指定 posSide，平仓由 side + posSide 决定（开多/平空为 buy，平多/开空为 sell），不能传 reduceOnly
+	// 单向持仓省略 posSide，只减仓用 reduceOnly
+	if o.posMode == "long_short_mode" {
+		if (req.Side == SideBuy) != req.ReduceOnly {
+			body["posSide"] = "long"
+		} else {
+			body["posSide"] = "short"
+		}
+	} else if req.ReduceOnly {
+		body["reduceOnly"] = true
+	}
+
+	resp, err := o.client.DoRequest(ctx, "POST", "/api/v5/trade/order", body)
+	if err != nil {
+		// 51008: 保证金不足
+		if strings.Contains(err.Error(), "51008") || strings.Contains(err.Error(), "Insufficient") {
+			return nil, fmt.Errorf("保证金不足: %w", err)
+		}
+		return nil, err
+	}
+
+	var results []itemResult
+	if err := json.Unmarshal(resp.Data, &results); err != nil || len(results) == 0 {
+		return nil, fmt.Errorf("解析下单响应失败: %s", string(resp.Data))
+	}
+	logger.Debug("🔍 [OKX REST] 下单响应: %s", string(resp.Data))
+
+	orderID, _ := strconv.ParseInt(results[0].OrdID, 10, 64)
+	if orderID == 0 {
+		return nil, fmt.Errorf("下单响应中ordId为空或无效: %s", string(resp.Data))
+	}
+
+	// 注意：不在这里打印日志，由executor统一打印避免重复
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: utils.RemoveBrokerPrefix("okx", results[0].ClOrdID),
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		Status:        OrderStatusNew,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// BatchPlaceOrders 批量下单
+func (o *OKXAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	placedOrders := make([]*Order, 0, len(orders))
+	hasMarginError := false
+
+	for _, orderReq := range orders {
+		order, err := o.PlaceOrder(ctx, orderReq)
+		if err != nil {
+			logger.Warn("⚠️ [OKX] 下单失败 %.2f %s: %v",
+				orderReq.Price, orderReq.Side, err)
+
+			if strings.Contains(err.Error(), "保证金不足") {
+				hasMarginError = true
+			}
+			continue
+		}
+
+		// 立即注册订单ID到价格的映射，防止 WebSocket 更新先到导致找不到槽位
+		if o.orderMappingCallback != nil && order.OrderID > 0 {
+			o.orderMappingCallback(order.OrderID, orderReq.Price)
+		}
+
+		placedOrders = append(placedOrders, order)
+	}
+
+	return placedOrders, hasMarginError
+}
+
+// CancelOrder 取消订单
+func (o *OKXAdapter) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	body := map[string]interface{}{
+		"instId": o.instID,
+		"ordId":  fmt.Sprintf("%d", orderID),
+	}
+
+	_, err := o.client.DoRequest(ctx, "POST", "/api/v5/trade/cancel-order", body)
+	if err != nil {
+		// 51400: 订单已成交、已撤销或不存在，不算错误
+		if strings.Contains(err.Error(), "51400") || strings.Contains(err.Error(), "51603") {
+			logger.Info("ℹ️ [OKX] 订单 %d 已不存在，跳过取消", orderID)
+			return nil
+		}
+		return fmt.Errorf("取消订单失败: %w", err)
+	}
+
+	logger.Info("✅ [OKX] 取消订单成功: %d", orderID)
+	return nil
+}
+
+// BatchCancelOrders 批量取消订单
+func (o *OKXAdapter) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	// 🔥 OKX 批量撤单限制：单次最多20个
+	batchSize := 20
+	for i := 0; i < len(orderIDs); i += batchSize {
+		end := i + batchSize
+		if end > len(orderIDs) {
+			end = len(orderIDs)
+		}
+
+		batch := orderIDs[i:end]
+		if len(batch) == 1 {
+			if err := o.CancelOrder(ctx, symbol, batch[0]); err != nil {
+				logger.Warn("⚠️ [OKX] 取消订单失败 %d: %v", batch[0], err)
+			}
+			continue
+		}
+
+		body := make([]map[string]string, len(batch))
+		for j, id := range batch {
+			body[j] = map[string]string{
+				"instId": o.instID,
+				"ordId":  fmt.Sprintf("%d", id),
+			}
+		}
+
+		_, err := o.client.DoRequest(ctx, "POST", "/api/v5/trade/cancel-batch-orders", body)
+		if err != nil && strings.Contains(err.Error(), "code=2,") {
+			// 部分成功（已成交/已撤销的订单撤单失败），不再逐个重试
+			logger.Warn("⚠️ [OKX] 批量撤单部分失败 (共%d个): %v", len(batch), err)
+		} else if err != nil {
+			logger.Warn("⚠️ [OKX] 批量撤单失败 (共%d个): %v", len(batch), err)
+			// 失败时尝试单个撤单
+			logger.Info("🔄 [OKX] 改为逐个撤单...")
+			for _, orderID := range batch {
+				_ = o.CancelOrder(ctx, symbol, orderID)
+				time.Sleep(100 * time.Millisecond) // 避免限频
+			}
+		} else {
+			logger.Info("✅ [OKX] 批量撤单成功: %d 个订单", len(batch))
+		}
+
+		// 避免限频
+		if i+batchSize < len(orderIDs) {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	return nil
+}
+
+// CancelAllOrders 撤销交易对的所有未完成订单
+// OKX 没有普通订单的一键全撤接口，先查询未完成订单再按20个一批撤销
+func (o *OKXAdapter) CancelAllOrders(ctx context.Context, symbol string) error {
+	openOrders, err := o.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("查询未完成订单失败: %w", err)
+	}
+	if len(openOrders) == 0 {
+		return nil
+	}
+
+	orderIDs := make([]int64, len(openOrders))
+	for i, ord := range openOrders {
+		orderIDs[i] = ord.OrderID
+	}
+	return o.BatchCancelOrders(ctx, symbol, orderIDs)
+}
+
+// okxOrder 订单查询接口（order / orders-pending）的订单结构
+type okxOrder struct {
+	InstID    string `json:"instId"`
+	OrdID     string `json:"ordId"`
+	ClOrdID   string `json:"clOrdId"`
+	Px        string `json:"px"`
+	Sz        string `json:"sz"`        // 张数
+	AccFillSz string `json:"accFillSz"` // 累计成交张数
+	AvgPx     string `json:"avgPx"`
+	Side      string `json:"side"`    // buy / sell
+	OrdType   string `json:"ordType"` // limit / post_only / market / ioc / fok
+	State     string `json:"state"`   // live / partially_filled / filled / canceled / mmp_canceled
+	CTime     string `json:"cTime"`
+	UTime     string `json:"uTime"`
+}
+
+// toOrder 转换为通用格式（张数换算为基础币数量）
+func (o *OKXAdapter) toOrder(item okxOrder) *Order {
+	orderID, _ := strconv.ParseInt(item.OrdID, 10, 64)
+	price, _ := strconv.ParseFloat(item.Px, 64)
+	size, _ := strconv.ParseFloat(item.Sz, 64)
+	filled, _ := strconv.ParseFloat(item.AccFillSz, 64)
+	avgPrice, _ := strconv.ParseFloat(item.AvgPx, 64)
+	createTime, _ := strconv.ParseInt(item.CTime, 10, 64)
+	updateTime, _ := strconv.ParseInt(item.UTime, 10, 64)
+
+	side := SideBuy
+	if item.Side == "sell" {
+		side = SideSell
+	}
+	orderType := OrderTypeLimit
+	if item.OrdType == "market" {
+		orderType = OrderTypeMarket
+	}
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: utils.RemoveBrokerPrefix("okx", item.ClOrdID),
+		Symbol:        convertFromOKXInstID(item.InstID),
+		Side:          side,
+		Type:          orderType,
+		Price:         price,
+		Quantity:      o.fromContracts(size),
+		ExecutedQty:   o.fromContracts(filled),
+		AvgPrice:      avgPrice,
+		Status:        convertOrderStatus(item.State),
+		CreatedAt:     time.UnixMilli(createTime),
+		UpdateTime:    updateTime,
+	}
+}
+
+// GetOrder 查询订单
+func (o *OKXAdapter) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	path := fmt.Sprintf("/api/v5/trade/order?instId=%s&ordId=%d", o.instID, orderID)
+	resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []okxOrder
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析订单详情失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return nil, fmt.Errorf("订单 %d does not exist", orderID)
+	}
+	return o.toOrder(dataList[0]), nil
+}
+
+// pendingOrdersLimit OKX 未完成订单单页最大条数
+const pendingOrdersLimit = 100
+
+// GetOpenOrders 查询未完成订单（按 ordId 向前翻页）
+func (o *OKXAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	var orders []*Order
+	after := ""
+	for {
+		path := fmt.Sprintf("/api/v5/trade/orders-pending?instType=SWAP&instId=%s&limit=%d", o.instID, pendingOrdersLimit)
+		if after != "" {
+			path += "&after=" + after
+		}
+		resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var dataList []okxOrder
+		if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+			return nil, fmt.Errorf("解析订单列表失败: %w", err)
+		}
+		for _, item := range dataList {
+			orders = append(orders, o.toOrder(item))
+		}
+
+		if len(dataList) < pendingOrdersLimit {
+			break
+		}
+		after = dataList[len(dataList)-1].OrdID
+	}
+
+	return orders, nil
+}
+
+// userTradesLimit OKX 成交历史单页最大条数
+const userTradesLimit = 100
+
+// GetUserTrades 查询 since 之后的历史成交（fills-history 保留近3个月，按 billId 向前翻页）
+func (o *OKXAdapter) GetUserTrades(ctx context.Context, symbol string, since time.Time) ([]*Trade, error) {
+	var result []*Trade
+	after := ""
+	for {
+		path := fmt.Sprintf("/api/v5/trade/fills-history?instType=SWAP&instId=%s&begin=%d&limit=%d",
+			o.instID, since.UnixMilli(), userTradesLimit)
+		if after != "" {
+			path += "&after=" + after
+		}
+		resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("查询成交历史失败: %w", err)
+		}
+
+		var dataList []struct {
+			TradeID  string `json:"tradeId"`
+			OrdID    string `json:"ordId"`
+			BillID   string `json:"billId"`
+			Side     string `json:"side"` // buy / sell
+			FillPx   string `json:"fillPx"`
+			FillSz   string `json:"fillSz"` // 张数
+			FillPnl  string `json:"fillPnl"`
+			Fee      string `json:"fee"` // 负数表示扣除的手续费
+			FeeCcy   string `json:"feeCcy"`
+			ExecType string `json:"execType"` // M: maker, T: taker
+			Ts       string `json:"ts"`
+		}
+		if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+			return nil, fmt.Errorf("解析成交历史失败: %w", err)
+		}
+
+		for _, item := range dataList {
+			tradeID, _ := strconv.ParseInt(item.TradeID, 10, 64)
+			orderID, _ := strconv.ParseInt(item.OrdID, 10, 64)
+			price, _ := strconv.ParseFloat(item.FillPx, 64)
+			size, _ := strconv.ParseFloat(item.FillSz, 64)
+			pnl, _ := strconv.ParseFloat(item.FillPnl, 64)
+			fee, _ := strconv.ParseFloat(item.Fee, 64)
+			ts, _ := strconv.ParseInt(item.Ts, 10, 64)
+
+			side := SideBuy
+			if item.Side == "sell" {
+				side = SideSell
+			}
+
+			result = append(result, &Trade{
+				TradeID:     tradeID,
+				OrderID:     orderID,
+				Symbol:      symbol,
+				Side:        side,
+				Price:       price,
+				Quantity:    o.fromContracts(size),
+				RealizedPnL: pnl,
+				Fee:         -fee,
+				FeeAsset:    item.FeeCcy,
+				IsMaker:     item.ExecType == "M",
+				Time:        time.UnixMilli(ts),
+			})
+		}
+
+		if len(dataList) < userTradesLimit {
+			break
+		}
+		after = dataList[len(dataList)-1].BillID
+	}
+
+	// 接口按时间倒序返回，转为升序
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
+
+// GetAccount 获取账户信息（结算币种的权益和可用余额）
+func (o *OKXAdapter) GetAccount(ctx context.Context) (*Account, error) {
+	path := fmt.Sprintf("/api/v5/account/balance?ccy=%s", o.quoteAsset)
+	resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []struct {
+		TotalEq string `json:"totalEq"`
+		Details []struct {
+			Ccy      string `json:"ccy"`
+			Eq       string `json:"eq"`       // 币种权益（含未实现盈亏）
+			CashBal  string `json:"cashBal"`  // 币种余额
+			AvailEq  string `json:"availEq"`  // 可用保证金（保证金模式）
+			AvailBal string `json:"availBal"` // 可用余额
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析账户信息失败: %w", err)
+	}
+
+	account := &Account{
+		Positions: []*Position{}, // 持仓信息需要单独查询
+		PosMode:   o.posMode,
+	}
+	if len(dataList) > 0 {
+		for _, d := range dataList[0].Details {
+			if d.Ccy != o.quoteAsset {
+				continue
+			}
+			account.TotalWalletBalance, _ = strconv.ParseFloat(d.CashBal, 64)
+			account.TotalMarginBalance, _ = strconv.ParseFloat(d.Eq, 64)
+			available := d.AvailEq
+			if available == "" {
+				available = d.AvailBal
+			}
+			account.AvailableBalance, _ = strconv.ParseFloat(available, 64)
+		}
+	}
+
+	leverage, err := o.GetSymbolLeverage(ctx, o.symbol)
+	if err != nil {
+		logger.Warn("⚠️ [OKX] 查询杠杆倍数失败: %v", err)
+	}
+	if leverage <= 0 {
+		leverage = 1 // 默认1倍
+	}
+	account.AccountLeverage = leverage
+
+	return account, nil
+}
+
+// GetSymbolLeverage 查询交易对的全仓杠杆倍数
+func (o *OKXAdapter) GetSymbolLeverage(ctx context.Context, symbol string) (int, error) {
+	path := fmt.Sprintf("/api/v5/account/leverage-info?instId=%s&mgnMode=cross", convertToOKXInstID(symbol))
+	resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var dataList []struct {
+		Lever string `json:"lever"`
+	}
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return 0, fmt.Errorf("解析杠杆信息失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return 0, fmt.Errorf("未找到 %s 的杠杆信息", symbol)
+	}
+	lever, _ := strconv.ParseFloat(dataList[0].Lever, 64)
+	return int(lever), nil
+}
+
+// GetPositions 获取持仓信息
+func (o *OKXAdapter) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	path := fmt.Sprintf("/api/v5/account/positions?instType=SWAP&instId=%s", o.instID)
+	resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []struct {
+		InstID  string `json:"instId"`
+		Pos     string `json:"pos"`     // 持仓张数（单向持仓带符号）
+		PosSide string `json:"posSide"` // long / short / net
+		AvgPx   string `json:"avgPx"`
+		MarkPx  string `json:"markPx"`
+		Upl     string `json:"upl"`
+		Lever   string `json:"lever"`
+		MgnMode string `json:"mgnMode"` // cross / isolated
+		Margin  string `json:"margin"`  // 逐仓保证金
+	}
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析持仓信息失败: %w", err)
+	}
+
+	positions := make([]*Position, 0, len(dataList))
+	for _, item := range dataList {
+		pos, _ := strconv.ParseFloat(item.Pos, 64)
+		if pos == 0 {
+			continue // 跳过空持仓
+		}
+
+		entryPrice, _ := strconv.ParseFloat(item.AvgPx, 64)
+		markPrice, _ := strconv.ParseFloat(item.MarkPx, 64)
+		unrealizedPNL, _ := strconv.ParseFloat(item.Upl, 64)
+		leverage, _ := strconv.ParseFloat(item.Lever, 64)
+		margin, _ := strconv.ParseFloat(item.Margin, 64)
+
+		// 双向持仓的空仓 pos 为正数，转为负数
+		size := o.fromContracts(pos)
+		if item.PosSide == "short" {
+			size = -math.Abs(size)
+		}
+
+		positions = append(positions, &Position{
+			Symbol:         convertFromOKXInstID(item.InstID),
+			Size:           size,
+			EntryPrice:     entryPrice,
+			MarkPrice:      markPrice,
+			UnrealizedPNL:  unrealizedPNL,
+			Leverage:       int(leverage),
+			MarginType:     item.MgnMode,
+			IsolatedMargin: margin,
+		})
+	}
+
+	return positions, nil
+}
+
+// GetBalance 获取余额
+func (o *OKXAdapter) GetBalance(ctx context.Context, asset string) (float64, error) {
+	account, err := o.GetAccount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return account.AvailableBalance, nil
+}
+
+// SetOrderMappingCallback 设置订单映射回调
+// 用于在下单成功后立即建立 orderID -> price 的映射
+func (o *OKXAdapter) SetOrderMappingCallback(callback func(orderID int64, price float64)) {
+	o.orderMappingCallback = callback
+}
+
+// StartOrderStream 启动订单流（WebSocket 私有频道 orders，需要登录）
+// 推送中的张数在这里换算为基础币数量
+func (o *OKXAdapter) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	logger.Debug("🔗 [OKX] 启动订单流 WebSocket（私有频道）")
+	return o.wsManager.Start(ctx, o.instID, o.orderUpdateCallback(callback, nil))
+}
+
+// StartMultiSymbolOrderStream 在一条私有频道连接上订阅多个交易对的订单更新
+// 推送的数量为张数，其他交易对按各自的合约面值换算（启动时查询一次合约信息）
+func (o *OKXAdapter) StartMultiSymbolOrderStream(ctx context.Context, symbols []string, callback func(interface{})) error {
+	instIDs := make([]string, 0, len(symbols))
+	contractSizes := make(map[string]okxContractSize, len(symbols))
+	for _, symbol := range symbols {
+		instID := convertToOKXInstID(symbol)
+		instIDs = append(instIDs, instID)
+		if instID == o.instID {
+			continue
+		}
+		queryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		inst, err := o.queryInstrument(queryCtx, instID)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("获取 %s 合约信息失败: %w", instID, err)
+		}
+		ctVal, _ := strconv.ParseFloat(inst.CtVal, 64)
+		lotSz, _ := strconv.ParseFloat(inst.LotSz, 64)
+		if ctVal <= 0 || lotSz <= 0 {
+			return fmt.Errorf("%s 合约信息无效: ctVal=%s, lotSz=%s", instID, inst.CtVal, inst.LotSz)
+		}
+		contractSizes[convertFromOKXInstID(instID)] = okxContractSize{ctVal: ctVal, volumePlace: volumeDecimals(lotSz, ctVal)}
+	}
+
+	logger.Debug("🔗 [OKX] 启动订单流 WebSocket（私有频道，%d 个交易对）", len(instIDs))
+	o.wsManager.SetOrderInstIDs(instIDs)
+	return o.wsManager.Start(ctx, o.instID, o.orderUpdateCallback(callback, contractSizes))
+}
+
+// okxContractSize 其他交易对的合约面值（共用订单流换算张数）
+type okxContractSize struct {
+	ctVal       float64
+	volumePlace int
+}
+
+// orderUpdateCallback 订单推送转换为通用结构，张数换算为基础币数量
+// 本交易对按当前合约面值换算，contractSizes 中的交易对按各自的合约面值换算
+func (o *OKXAdapter) orderUpdateCallback(callback func(interface{}), contractSizes map[string]okxContractSize) func(interface{}) {
+	return func(update interface{}) {
+		localUpdate, ok := update.(*OrderUpdate)
+		if !ok {
+			logger.Warn("⚠️ [OKX Adapter] 订单更新类型断言失败: %T", update)
+			return
+		}
+		fromContracts := o.fromContracts
+		if size, ok := contractSizes[localUpdate.Symbol]; ok {
+			fromContracts = func(contracts float64) float64 {
+				return roundTo(contracts*size.ctVal, size.volumePlace)
+			}
+		}
+		callback(struct {
+			OrderID       int64
+			ClientOrderID string
+			Symbol        string
+			Side          string
+			Type          string
+			Status        string
+			Price         float64
+			Quantity      float64
+			ExecutedQty   float64
+			AvgPrice      float64
+			UpdateTime    int64
+		}{
+			OrderID:       localUpdate.OrderID,
+			ClientOrderID: localUpdate.ClientOrderID,
+			Symbol:        localUpdate.Symbol,
+			Side:          string(localUpdate.Side),
+			Type:          string(localUpdate.Type),
+			Status:        string(localUpdate.Status),
+			Price:         localUpdate.Price,
+			Quantity:      fromContracts(localUpdate.Quantity),
+			ExecutedQty:   fromContracts(localUpdate.ExecutedQty),
+			AvgPrice:      localUpdate.AvgPrice,
+			UpdateTime:    localUpdate.UpdateTime,
+		})
+	}
+}
+
+// StopOrderStream 停止订单流
+func (o *OKXAdapter) StopOrderStream() error {
+	o.wsManager.Stop()
+	return nil
+}
+
+// GetLatestPrice 获取最新价格（仅从 WebSocket 缓存读取）
+// 实时价格应该通过 PriceMonitor.GetLastPrice() 获取，WebSocket 未启动或无数据时返回错误
+func (o *OKXAdapter) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	if o.wsManager != nil {
+		if price := o.wsManager.GetLatestPrice(); price > 0 {
+			return price, nil
+		}
+	}
+	return 0, fmt.Errorf("WebSocket 价格流未就绪或无价格数据")
+}
+
+// StartPriceStream 启动价格流（WebSocket 公共频道 tickers）
+// 价格流和订单流共用同一个 WebSocketManager
+func (o *OKXAdapter) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	o.wsManager.SetPriceCallback(func(instID string, p float64) {
+		if instID == o.instID {
+			callback(p)
+		}
+	})
+
+	// 注意：传入 nil 作为订单回调，表示只订阅价格，不订阅订单
+	if !o.wsManager.IsRunning() {
+		logger.Debug("🔗 [OKX] 启动价格流 WebSocket（公共频道）")
+		return o.wsManager.Start(ctx, o.instID, nil)
+	}
+
+	logger.Debug("✅ [OKX] 价格流回调已注册（WebSocket已在运行）")
+	return nil
+}
+
+// StartKlineStream 启动K线流（WebSocket 业务频道）
+func (o *OKXAdapter) StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
+	if o.klineWSManager == nil {
+		o.klineWSManager = NewKlineWebSocketManager(o.wsEndpoints)
+	}
+	return o.klineWSManager.Start(ctx, symbols, interval, callback)
+}
+
+// StopKlineStream 停止K线流
+func (o *OKXAdapter) StopKlineStream() error {
+	if o.klineWSManager != nil {
+		o.klineWSManager.Stop()
+	}
+	return nil
+}
+
+// ActiveWSEndpoint 当前使用的 WebSocket 地址
+func (o *OKXAdapter) ActiveWSEndpoint() string {
+	return o.wsEndpoints.Active()
+}
+
+// GetHistoricalKlines 获取历史K线数据
+func (o *OKXAdapter) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	// OKX candles 接口单次最多 300 根
+	if limit > 300 {
+		limit = 300
+	}
+
+	path := fmt.Sprintf("/api/v5/market/candles?instId=%s&bar=%s&limit=%d",
+		convertToOKXInstID(symbol), convertToOKXInterval(interval), limit)
+	resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	// OKX 返回格式: [[ts, o, h, l, c, vol, volCcy, volCcyQuote, confirm], ...]，按时间倒序
+	var dataList [][]string
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败: %w", err)
+	}
+
+	candles := make([]*Candle, 0, len(dataList))
+	for i := len(dataList) - 1; i >= 0; i-- {
+		if candle := parseCandle(symbol, dataList[i]); candle != nil {
+			candles = append(candles, candle)
+		}
+	}
+	return candles, nil
+}
+
+// parseCandle 解析 OKX K线数组（REST 与 WebSocket 格式相同），成交量使用基础币数量 volCcy
+func parseCandle(symbol string, item []string) *Candle {
+	if len(item) < 6 {
+		return nil
+	}
+
+	timestamp, _ := strconv.ParseInt(item[0], 10, 64)
+	open, _ := strconv.ParseFloat(item[1], 64)
+	high, _ := strconv.ParseFloat(item[2], 64)
+	low, _ := strconv.ParseFloat(item[3], 64)
+	close, _ := strconv.ParseFloat(item[4], 64)
+	volume, _ := strconv.ParseFloat(item[5], 64)
+	if len(item) > 6 {
+		volume, _ = strconv.ParseFloat(item[6], 64)
+	}
+
+	// confirm: 0 未完结，1 已完结
+	isClosed := true
+	if len(item) > 8 {
+		isClosed = item[8] == "1"
+	}
+
+	return &Candle{
+		Symbol:    symbol,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Volume:    volume,
+		Timestamp: timestamp,
+		IsClosed:  isClosed,
+	}
+}
+
+// convertOrderStatus 将 OKX 订单状态转换为通用状态
+func convertOrderStatus(state string) OrderStatus {
+	switch state {
+	case "live":
+		return "NEW"
+	case "partially_filled":
+		return "PARTIALLY_FILLED"
+	case "filled":
+		return "FILLED"
+	case "canceled", "mmp_canceled":
+		return "CANCELED"
+	default:
+		logger.Warn("⚠️ [OKX] 未知订单状态: %s", state)
+		return OrderStatus(state)
+	}
+}
+
+// convertToOKXInterval 将标准K线周期转换为 OKX 格式
+// 输入: 1m, 3m, 5m, 15m, 30m, 1h, 2h, 4h, 6h, 12h, 1d, 1w, 1M
+// 输出: 1m, 3m, 5m, 15m, 30m, 1H, 2H, 4H, 6H, 12H, 1D, 1W, 1M（小时及以上为大写）
+func convertToOKXInterval(interval string) string {
+	if strings.HasSuffix(interval, "h") || strings.HasSuffix(interval, "d") || strings.HasSuffix(interval, "w") {
+		return strings.ToUpper(interval)
+	}
+	return interval
+}
+
+// okxQuoteAssets 识别计价币种的后缀（按长度从长到短匹配）
+var okxQuoteAssets = []string{"USDT", "USDC", "USD"}
+
+// convertToOKXInstID 将标准符号转换为 OKX 永续合约产品ID（ETHUSDT -> ETH-USDT-SWAP）
+// 已经是 OKX 格式的符号保持不变
+func convertToOKXInstID(symbol string) string {
+	if strings.Contains(symbol, "-") {
+		if strings.HasSuffix(symbol, "-SWAP") {
+			return symbol
+		}
+		return symbol + "-SWAP"
+	}
+	for _, quote := range okxQuoteAssets {
+		if strings.HasSuffix(symbol, quote) && len(symbol) > len(quote) {
+			return strings.TrimSuffix(symbol, quote) + "-" + quote + "-SWAP"
+		}
+	}
+	return symbol
+}
+
+// convertFromOKXInstID 将 OKX 产品ID转换为标准符号（ETH-USDT-SWAP -> ETHUSDT）
+func convertFromOKXInstID(instID string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(instID, "-SWAP"), "-", "")
+}
+
+// decimalPlaces 精度字符串的小数位数（如 "0.01" -> 2，"1" -> 0）
+func decimalPlaces(step string) int {
+	if i := strings.IndexByte(step, '.'); i >= 0 {
+		return len(strings.TrimRight(step[i+1:], "0"))
+	}
+	return 0
+}
+
+// volumeDecimals 基础币数量的小数位（张数精度 × 合约面值）
+func volumeDecimals(lotSz, ctVal float64) int {
+	return decimalPlaces(strconv.FormatFloat(roundTo(lotSz*ctVal, 12), 'f', -1, 64))
+}
+
+// roundTo 按小数位四舍五入（消除张数换算的浮点误差）
+func roundTo(v float64, decimals int) float64 {
+	multiplier := math.Pow(10, float64(decimals))
+	return math.Round(v*multiplier) / multiplier
+}
+
+// GetPriceDecimals 获取价格精度（小数位数）
+func (o *OKXAdapter) GetPriceDecimals() int {
+	return o.pricePlace
+}
+
+// GetQuantityDecimals 获取数量精度（基础币数量的小数位数）
+func (o *OKXAdapter) GetQuantityDecimals() int {
+	return o.volumePlace
+}
+
+// GetBaseAsset 获取基础资产（交易币种）
+func (o *OKXAdapter) GetBaseAsset() string {
+	return o.baseAsset
+}
+
+// GetQuoteAsset 获取计价资产（结算币种）
+func (o *OKXAdapter) GetQuoteAsset() string {
+	return o.quoteAsset
+}
+
+// ExecutedQtyIsIncremental 订单推送的成交数量语义（orders 频道的 accFillSz 为累计成交张数）
+func (o *OKXAdapter) ExecutedQtyIsIncremental() bool {
+	return false
+}
+
+// GetMinNotional 获取最小下单金额
+// OKX 只限制最小下单张数（minSz，下单时检查），没有最小下单金额，返回0
+func (o *OKXAdapter) GetMinNotional() float64 {
+	return 0
+}
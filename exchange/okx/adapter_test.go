@@ -0,0 +1,25 @@
+package okx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrderUpdateCallbackConvertsBySymbol(t *testing.T) {
+	// 本交易对 ETH 每张 0.1，共用订单流中的 BTC 每张 0.01
+	o := &OKXAdapter{symbol: "ETHUSDT", instID: "ETH-USDT-SWAP", ctVal: 0.1, volumePlace: 2}
+	sizes := map[string]okxContractSize{"BTCUSDT": {ctVal: 0.01, volumePlace: 4}}
+
+	got := map[string][2]float64{}
+	callback := o.orderUpdateCallback(func(raw interface{}) {
+		v := reflect.ValueOf(raw)
+		got[v.FieldByName("Symbol").String()] = [2]float64{v.FieldByName("Quantity").Float(), v.FieldByName("ExecutedQty").Float()}
+	}, sizes)
+	callback(&OrderUpdate{Symbol: "ETHUSDT", Quantity: 3, ExecutedQty: 1})
+	callback(&OrderUpdate{Symbol: "BTCUSDT", Quantity: 3, ExecutedQty: 1})
+
+	want := map[string][2]float64{"ETHUSDT": {0.3, 0.1}, "BTCUSDT": {0.03, 0.01}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("张数应按各交易对的合约面值换算，期望 %v，实际 %v", want, got)
+	}
+}
@@ -0,0 +1,107 @@
+package okx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	OKXBaseURL = "https://www.okx.com"
+)
+
+// Client OKX HTTP 客户端
+type Client struct {
+	httpClient *http.Client
+	signer     *Signer
+	baseURL    string
+}
+
+// NewClient 创建 OKX 客户端
+func NewClient(apiKey, secretKey, passphrase string) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		signer:     NewSigner(apiKey, secretKey, passphrase),
+		baseURL:    OKXBaseURL,
+	}
+}
+
+// OKXResponse OKX API 通用响应结构
+type OKXResponse struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// itemResult 下单/撤单等接口 data 中每一项的处理结果（sCode 为 "0" 表示成功）
+type itemResult struct {
+	OrdID   string `json:"ordId"`
+	ClOrdID string `json:"clOrdId"`
+	SCode   string `json:"sCode"`
+	SMsg    string `json:"sMsg"`
+}
+
+// DoRequest 发送 HTTP 请求（带签名）
+// GET 请求的查询参数写在 path 中，参与签名
+func (c *Client) DoRequest(ctx context.Context, method, path string, body interface{}) (*OKXResponse, error) {
+	var bodyBytes []byte
+	var err error
+
+	if body != nil {
+		bodyBytes, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("序列化请求体失败: %w", err)
+		}
+	}
+
+	timestamp := c.signer.GetTimestamp()
+	signature := c.signer.Sign(timestamp, method, path, string(bodyBytes))
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	// 添加 OKX 必需的请求头
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("OK-ACCESS-KEY", c.signer.GetAPIKey())
+	req.Header.Set("OK-ACCESS-SIGN", signature)
+	req.Header.Set("OK-ACCESS-TIMESTAMP", timestamp)
+	req.Header.Set("OK-ACCESS-PASSPHRASE", c.signer.GetPassphrase())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	// 服务端错误（5xx）时响应体通常不是标准 JSON，直接返回状态码便于上层识别
+	if resp.StatusCode >= 500 {
+		return nil, fmt.Errorf("okx 服务端错误: 状态码=%d, 响应=%s", resp.StatusCode, string(respBody))
+	}
+
+	var okxResp OKXResponse
+	if err := json.Unmarshal(respBody, &okxResp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w, 响应体: %s", err, string(respBody))
+	}
+
+	if okxResp.Code != "0" {
+		// 下单/撤单失败时外层只有 "Operation failed"，具体原因在 data[].sCode / sMsg 中
+		var items []itemResult
+		if json.Unmarshal(okxResp.Data, &items) == nil && len(items) > 0 && items[0].SCode != "" && items[0].SCode != "0" {
+			return nil, fmt.Errorf("okx API 错误: code=%s, msg=%s, sCode=%s, sMsg=%s", okxResp.Code, okxResp.Msg, items[0].SCode, items[0].SMsg)
+		}
+		return nil, fmt.Errorf("okx API 错误: code=%s, msg=%s", okxResp.Code, okxResp.Msg)
+	}
+
+	return &okxResp, nil
+}
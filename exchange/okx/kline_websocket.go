@@ -0,0 +1,278 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"opensqt/event"
+	"opensqt/logger"
+	"opensqt/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+// Candle K线数据
+type Candle struct {
+	Symbol    string
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	Volume    float64
+	Timestamp int64
+	IsClosed  bool // K线是否完结
+}
+
+// KlineWebSocketManager OKX K线WebSocket管理器（业务频道 /ws/v5/business，无需登录）
+type KlineWebSocketManager struct {
+	conn           *websocket.Conn
+	mu             sync.RWMutex
+	done           chan struct{}
+	callback       func(candle interface{})
+	symbols        []string
+	interval       string
+	reconnectDelay time.Duration
+	pingInterval   time.Duration
+	isRunning      bool
+	endpoints      *utils.WSEndpoints // WebSocket 基础地址（与订单流共用故障切换状态）
+}
+
+// NewKlineWebSocketManager 创建K线WebSocket管理器
+func NewKlineWebSocketManager(endpoints *utils.WSEndpoints) *KlineWebSocketManager {
+	return &KlineWebSocketManager{
+		done:           make(chan struct{}),
+		endpoints:      endpoints,
+		reconnectDelay: 5 * time.Second,  // 重连延迟
+		pingInterval:   15 * time.Second, // Ping间隔（30秒无消息服务端断开）
+	}
+}
+
+// Start 启动K线流（带自动重连）
+func (k *KlineWebSocketManager) Start(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
+	k.mu.Lock()
+	if k.isRunning {
+		k.mu.Unlock()
+		return fmt.Errorf("K线流已在运行")
+	}
+	k.callback = callback
+	k.symbols = symbols
+	k.interval = interval
+	k.isRunning = true
+	k.mu.Unlock()
+
+	go k.connectLoop(ctx)
+	return nil
+}
+
+// Stop 停止K线流
+func (k *KlineWebSocketManager) Stop() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.isRunning {
+		return
+	}
+
+	k.isRunning = false
+	close(k.done)
+
+	if k.conn != nil {
+		k.conn.Close()
+		k.conn = nil
+	}
+
+	logger.Info("✅ OKX K线WebSocket已停止")
+}
+
+// connectLoop 连接循环（自动重连）
+func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("✅ OKX K线WebSocket已停止（上下文取消）")
+			return
+		case <-k.done:
+			return
+		default:
+		}
+
+		logger.Info("🔗 正在连接 OKX K线WebSocket...")
+		base := k.endpoints.Active()
+		conn, _, err := websocket.DefaultDialer.Dial(base+OKXWSBusiness, nil)
+		if err != nil {
+			logger.Error("❌ OKX K线WebSocket连接失败: %v，%v后重试", err, k.reconnectDelay)
+			k.endpoints.OnConnectFailed(base)
+		} else {
+			k.endpoints.OnConnected(base)
+			k.mu.Lock()
+			k.conn = conn
+			k.mu.Unlock()
+
+			logger.Info("✅ OKX K线WebSocket已连接")
+			event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "OKX", Stream: "kline"})
+
+			if err := k.subscribe(conn); err != nil {
+				logger.Error("❌ OKX K线订阅失败: %v", err)
+				conn.Close()
+			} else {
+				go k.pingLoop(ctx, conn)
+				k.readLoop(ctx, conn) // 阻塞直到连接断开
+			}
+
+			k.mu.Lock()
+			if k.conn == conn {
+				k.conn = nil
+			}
+			k.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				logger.Info("✅ OKX K线WebSocket已停止（上下文取消）")
+				return
+			case <-k.done:
+				return
+			default:
+			}
+			logger.Warn("⚠️ OKX K线WebSocket连接断开，%v后重连...", k.reconnectDelay)
+		}
+
+		// 使用 select 等待，可以立即响应 context 取消
+		select {
+		case <-ctx.Done():
+			logger.Info("✅ OKX K线WebSocket已停止（上下文取消）")
+			return
+		case <-k.done:
+			return
+		case <-time.After(k.reconnectDelay):
+		}
+	}
+}
+
+// subscribe 订阅K线
+// {"op": "subscribe", "args": [{"channel": "candle1m", "instId": "ETH-USDT-SWAP"}]}
+func (k *KlineWebSocketManager) subscribe(conn *websocket.Conn) error {
+	channel := "candle" + convertToOKXInterval(k.interval)
+	args := make([]WSSubscribeArg, len(k.symbols))
+	for i, symbol := range k.symbols {
+		args[i] = WSSubscribeArg{Channel: channel, InstID: convertToOKXInstID(symbol)}
+	}
+
+	subMsg := map[string]interface{}{
+		"op":   "subscribe",
+		"args": args,
+	}
+	if err := conn.WriteJSON(subMsg); err != nil {
+		return fmt.Errorf("发送订阅消息失败: %w", err)
+	}
+
+	logger.Debug("已发送K线订阅请求: %d个币种", len(k.symbols))
+	return nil
+}
+
+// pingLoop ping循环（OKX 使用纯文本 "ping"，服务器返回纯文本 "pong"）
+func (k *KlineWebSocketManager) pingLoop(ctx context.Context, conn *websocket.Conn) {
+	ticker := time.NewTicker(k.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-k.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			k.mu.RLock()
+			currentConn := k.conn
+			k.mu.RUnlock()
+
+			// 检查连接是否还是当前连接
+			if currentConn != conn {
+				return
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+				logger.Warn("⚠️ OKX K线WebSocket发送Ping失败: %v", err)
+				conn.Close()
+				return
+			}
+			logger.Debug("💓 OKX K线WebSocket Ping已发送")
+		}
+	}
+}
+
+// readLoop 读取消息循环
+func (k *KlineWebSocketManager) readLoop(ctx context.Context, conn *websocket.Conn) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("❌ OKX K线WebSocket读取协程panic: %v", r)
+		}
+		conn.Close()
+	}()
+
+	// 初始读取超时：设置为90秒（大于ping间隔的3倍）
+	conn.SetReadDeadline(time.Now().Add(90 * time.Second))
+
+	for {
+		select {
+		case <-k.done:
+			return
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				logger.Warn("⚠️ OKX K线WebSocket异常关闭: %v", err)
+			} else {
+				logger.Debug("OKX K线WebSocket读取错误: %v", err)
+			}
+			return
+		}
+
+		// 收到任何消息都更新读取超时
+		conn.SetReadDeadline(time.Now().Add(90 * time.Second))
+
+		if string(message) == "pong" {
+			logger.Debug("💓 收到 K线WebSocket pong")
+			continue
+		}
+
+		var msg struct {
+			Event string         `json:"event"` // subscribe / error
+			Code  string         `json:"code"`
+			Msg   string         `json:"msg"`
+			Arg   WSSubscribeArg `json:"arg"`
+			Data  [][]string     `json:"data"` // [[ts, o, h, l, c, vol, volCcy, volCcyQuote, confirm]]
+		}
+		if err := json.Unmarshal(message, &msg); err != nil {
+			logger.Debug("解析K线消息失败: %v", err)
+			continue
+		}
+
+		switch msg.Event {
+		case "subscribe":
+			logger.Debug("✅ K线订阅成功: %s %s", msg.Arg.InstID, msg.Arg.Channel)
+			continue
+		case "error":
+			logger.Error("❌ OKX K线订阅错误: code=%s, msg=%s", msg.Code, msg.Msg)
+			continue
+		}
+
+		if !strings.HasPrefix(msg.Arg.Channel, "candle") || k.callback == nil {
+			continue
+		}
+		symbol := convertFromOKXInstID(msg.Arg.InstID)
+		for _, item := range msg.Data {
+			if candle := parseCandle(symbol, item); candle != nil {
+				k.callback(candle)
+			}
+		}
+	}
+}
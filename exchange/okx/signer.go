@@ -0,0 +1,60 @@
+package okx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// Signer OKX API 签名器
+type Signer struct {
+	apiKey     string
+	secretKey  string
+	passphrase string
+}
+
+// NewSigner 创建签名器
+func NewSigner(apiKey, secretKey, passphrase string) *Signer {
+	return &Signer{
+		apiKey:     apiKey,
+		secretKey:  secretKey,
+		passphrase: passphrase,
+	}
+}
+
+// Sign 生成签名
+// OKX 签名规则: Base64(HMAC_SHA256(timestamp + method + requestPath + body, secretKey))
+// REST 的 requestPath 包含查询参数（如 /api/v5/account/balance?ccy=USDT），method 为大写
+func (s *Signer) Sign(timestamp, method, requestPath, body string) string {
+	message := timestamp + method + requestPath + body
+	mac := hmac.New(sha256.New, []byte(s.secretKey))
+	mac.Write([]byte(message))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// GetTimestamp 获取 REST 请求时间戳（ISO 8601 UTC，精确到毫秒，如 2020-12-08T09:08:57.715Z）
+func (s *Signer) GetTimestamp() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// GetWSTimestamp 获取 WebSocket 登录时间戳（Unix 秒）
+func (s *Signer) GetWSTimestamp() string {
+	return fmt.Sprintf("%d", time.Now().Unix())
+}
+
+// SignWSLogin 生成 WebSocket 登录签名（固定为 GET /users/self/verify，无请求体）
+func (s *Signer) SignWSLogin(timestamp string) string {
+	return s.Sign(timestamp, "GET", "/users/self/verify", "")
+}
+
+// GetAPIKey 获取 API Key
+func (s *Signer) GetAPIKey() string {
+	return s.apiKey
+}
+
+// GetPassphrase 获取 Passphrase
+func (s *Signer) GetPassphrase() string {
+	return s.passphrase
+}
@@ -0,0 +1,79 @@
+package okx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testSecretKey 测试用密钥；下面的期望签名由独立实现（Python hmac + base64）对同一预签名字符串计算得到
+const testSecretKey = "22582BD0CFF14C41EDBF1AB98506286D"
+
+func TestSign(t *testing.T) {
+	// 预签名字符串取自 OKX API 文档的签名示例：timestamp + method + requestPath + body
+	tests := []struct {
+		name                          string
+		timestamp, method, path, body string
+		want                          string
+	}{
+		{
+			"GET 请求（查询参数参与签名）",
+			"2020-12-08T09:08:57.715Z", "GET", "/api/v5/account/balance?ccy=BTC", "",
+			"HiZhvSfMtWJA3uUIVXV3a/bSXNPCWvYFXoGCVS8V4zY=",
+		},
+		{
+			"POST 请求（请求体参与签名）",
+			"2020-12-08T09:08:57.715Z", "POST", "/api/v5/trade/order", `{"instId":"BTC-USDT","lever":"5","mgnMode":"isolated"}`,
+			"B1CIgFITj5o4lMDG+Uz5juzEGMCIDXO9bxM4MBgQ62g=",
+		},
+	}
+	signer := NewSigner("key", testSecretKey, "passphrase")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := signer.Sign(tt.timestamp, tt.method, tt.path, tt.body); got != tt.want {
+				t.Fatalf("签名应为 %s，实际 %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSignWSLogin(t *testing.T) {
+	// WebSocket 登录：Unix 秒时间戳 + GET + /users/self/verify
+	signer := NewSigner("key", testSecretKey, "passphrase")
+	if got, want := signer.SignWSLogin("1538054050"), "+LdIr8lkkvhr5hoA3g9TMC0+uQJ849ftAcocA/ouu4M="; got != want {
+		t.Fatalf("登录签名应为 %s，实际 %s", want, got)
+	}
+}
+
+func TestDoRequestSignsPathAndBody(t *testing.T) {
+	var header http.Header
+	var uri, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		uri = r.URL.RequestURI()
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{"code":"0","msg":"","data":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", testSecretKey, "passphrase")
+	client.baseURL = server.URL
+	if _, err := client.DoRequest(context.Background(), "POST", "/api/v5/trade/order?tag=grid",
+		map[string]string{"instId": "BTC-USDT"}); err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+
+	if uri != "/api/v5/trade/order?tag=grid" || body != `{"instId":"BTC-USDT"}` {
+		t.Fatalf("请求路径或请求体不符: %s %s", uri, body)
+	}
+	want := client.signer.Sign(header.Get("OK-ACCESS-TIMESTAMP"), "POST", uri, body)
+	if header.Get("OK-ACCESS-SIGN") != want {
+		t.Fatalf("签名应覆盖时间戳、方法、完整路径（含查询参数）和请求体，期望 %s，实际 %s", want, header.Get("OK-ACCESS-SIGN"))
+	}
+	if header.Get("OK-ACCESS-KEY") != "key" || header.Get("OK-ACCESS-PASSPHRASE") != "passphrase" {
+		t.Fatalf("缺少 API Key 或 Passphrase 请求头: %v", header)
+	}
+}
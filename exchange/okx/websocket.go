@@ -0,0 +1,492 @@
+package okx
+
+/*
+OKX WebSocket 架构说明：
+
+1. **WebSocket用途**（下单统一使用 REST API）：
+   - 公共频道 /ws/v5/public：订阅价格推送 (tickers)
+   - 私有频道 /ws/v5/private：登录后订阅订单更新 (orders)
+   - 业务频道 /ws/v5/business：订阅K线 (candle*)，见 kline_websocket.go
+
+2. **登录签名**：timestamp 为 Unix 秒，sign = Base64(HMAC_SHA256(timestamp + "GET" + "/users/self/verify"))
+
+3. **保活**：30秒内没有消息服务端会断开连接，每15秒发送文本 "ping"，服务端返回文本 "pong"
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"opensqt/event"
+	"opensqt/logger"
+	"opensqt/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// OKX V5 WebSocket 频道路径（拼接在 WebSocket 基础地址后）
+	OKXWSPublic   = "/ws/v5/public"
+	OKXWSPrivate  = "/ws/v5/private"
+	OKXWSBusiness = "/ws/v5/business"
+)
+
+// WebSocketManager OKX WebSocket 管理器
+type WebSocketManager struct {
+	signer *Signer
+
+	// 连接管理
+	privateConn *websocket.Conn
+	publicConn  *websocket.Conn
+	mu          sync.RWMutex
+
+	// 回调函数
+	orderCallback func(interface{})
+	priceCallback func(string, float64) // instId, price
+
+	// 控制
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// 价格缓存
+	latestPrice float64
+	priceMu     sync.RWMutex
+
+	// 标记连接循环是否已启动
+	privateHandlerStarted bool
+	publicHandlerStarted  bool
+
+	reconnectDelay time.Duration
+	subscribedInst string             // 订阅的产品ID，用于重连后重新订阅
+	orderInsts     []string           // 订单频道订阅的产品ID（多交易对共用订单流时设置，为空时只订阅 subscribedInst）
+	endpoints      *utils.WSEndpoints // WebSocket 基础地址（故障切换）
+}
+
+// WSSubscribeArg WebSocket 订阅参数
+type WSSubscribeArg struct {
+	Channel  string `json:"channel"`
+	InstType string `json:"instType,omitempty"`
+	InstID   string `json:"instId,omitempty"`
+}
+
+// wsEvent 登录/订阅响应和推送数据的通用结构
+type wsEvent struct {
+	Event string          `json:"event"` // login / subscribe / error
+	Code  string          `json:"code"`
+	Msg   string          `json:"msg"`
+	Arg   WSSubscribeArg  `json:"arg"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// NewWebSocketManager 创建 WebSocket 管理器
+func NewWebSocketManager(apiKey, secretKey, passphrase string, endpoints *utils.WSEndpoints) *WebSocketManager {
+	return &WebSocketManager{
+		signer:         NewSigner(apiKey, secretKey, passphrase),
+		reconnectDelay: 5 * time.Second,
+		endpoints:      endpoints,
+	}
+}
+
+// SetPriceCallback 设置价格回调
+func (w *WebSocketManager) SetPriceCallback(callback func(string, float64)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.priceCallback = callback
+}
+
+// SetOrderInstIDs 设置订单频道订阅的产品ID（需在 Start 之前调用，重连后按同一列表重新订阅）
+func (w *WebSocketManager) SetOrderInstIDs(instIDs []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.orderInsts = append([]string(nil), instIDs...)
+}
+
+// IsRunning 检查 WebSocket 是否运行中
+func (w *WebSocketManager) IsRunning() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.publicHandlerStarted || w.privateHandlerStarted
+}
+
+// Start 启动 WebSocket 连接（公共频道+私有频道）
+// callback: 订单更新回调函数，为nil时不订阅订单频道
+func (w *WebSocketManager) Start(ctx context.Context, instID string, callback func(interface{})) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ctx == nil {
+		w.ctx, w.cancel = context.WithCancel(ctx)
+	}
+	if callback != nil {
+		w.orderCallback = callback
+	}
+	w.subscribedInst = instID
+
+	if !w.publicHandlerStarted {
+		w.wg.Add(1)
+		go w.connectLoop("公共", w.runPublic)
+		w.publicHandlerStarted = true
+	}
+
+	if callback != nil && !w.privateHandlerStarted {
+		w.wg.Add(1)
+		go w.connectLoop("私有", w.runPrivate)
+		w.privateHandlerStarted = true
+	}
+
+	if callback != nil {
+		logger.Info("✅ [OKX WebSocket] 启动成功，将订阅 %s 的价格和订单更新", instID)
+	} else {
+		logger.Info("✅ [OKX WebSocket] 启动成功，将订阅 %s 的价格更新", instID)
+	}
+	return nil
+}
+
+// Stop 停止 WebSocket
+func (w *WebSocketManager) Stop() {
+	// 第一步：取消 context 并关闭连接（需要加锁）
+	w.mu.Lock()
+	if w.cancel != nil {
+		w.cancel()
+	}
+	if w.privateConn != nil {
+		w.privateConn.Close()
+	}
+	if w.publicConn != nil {
+		w.publicConn.Close()
+	}
+	w.mu.Unlock()
+
+	// 第二步：等待所有 goroutine 退出（不能持有锁，避免死锁）
+	w.wg.Wait()
+	logger.Info("✅ [OKX WebSocket] 已停止")
+}
+
+// connectLoop 连接循环（自动重连）
+// run 建立连接并阻塞读取，连接断开后返回；返回错误表示连接/登录/订阅失败
+func (w *WebSocketManager) connectLoop(connType string, run func(base string) error) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [OKX WS%s] 停止连接循环", connType)
+			return
+		default:
+		}
+
+		logger.Info("🔗 [OKX WS%s] 正在连接...", connType)
+		base := w.endpoints.Active()
+		if err := run(base); err != nil {
+			logger.Error("❌ [OKX WS%s] 连接失败: %v，%v后重试", connType, err, w.reconnectDelay)
+			w.endpoints.OnConnectFailed(base)
+		} else {
+			// 检查是否因为 context 取消而断开，如果是则直接退出
+			select {
+			case <-w.ctx.Done():
+				logger.Info("✅ [OKX WS%s] 停止连接循环", connType)
+				return
+			default:
+			}
+			logger.Warn("⚠️ [OKX WS%s] 连接断开，%v后重连...", connType, w.reconnectDelay)
+		}
+
+		// 使用 select 等待，可以立即响应 context 取消
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [OKX WS%s] 停止连接循环", connType)
+			return
+		case <-time.After(w.reconnectDelay):
+		}
+	}
+}
+
+// runPublic 连接公共频道并订阅 tickers，阻塞直到连接断开
+func (w *WebSocketManager) runPublic(base string) error {
+	conn, _, err := websocket.DefaultDialer.Dial(base+OKXWSPublic, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	w.mu.Lock()
+	w.publicConn = conn
+	instID := w.subscribedInst
+	w.mu.Unlock()
+	defer w.clearConn(&w.publicConn, conn)
+
+	if err := w.subscribe(conn, WSSubscribeArg{Channel: "tickers", InstID: instID}); err != nil {
+		return fmt.Errorf("订阅失败: %w", err)
+	}
+
+	w.endpoints.OnConnected(base)
+	logger.Info("✅ [OKX WS公共] 已连接")
+	event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "OKX", Stream: "price"})
+
+	w.readLoop(conn, "公共", func(msg *wsEvent) {
+		if msg.Arg.Channel == "tickers" && len(msg.Data) > 0 {
+			w.handlePriceUpdate(msg.Data)
+		}
+	})
+	return nil
+}
+
+// runPrivate 连接私有频道、登录并订阅 orders，阻塞直到连接断开
+func (w *WebSocketManager) runPrivate(base string) error {
+	conn, _, err := websocket.DefaultDialer.Dial(base+OKXWSPrivate, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	w.mu.Lock()
+	w.privateConn = conn
+	instIDs := w.orderInsts
+	if len(instIDs) == 0 {
+		instIDs = []string{w.subscribedInst}
+	}
+	w.mu.Unlock()
+	defer w.clearConn(&w.privateConn, conn)
+
+	if err := w.login(conn); err != nil {
+		return err
+	}
+	args := make([]WSSubscribeArg, len(instIDs))
+	for i, instID := range instIDs {
+		args[i] = WSSubscribeArg{Channel: "orders", InstType: "SWAP", InstID: instID}
+	}
+	if err := w.subscribe(conn, args...); err != nil {
+		return fmt.Errorf("订阅失败: %w", err)
+	}
+
+	w.endpoints.OnConnected(base)
+	logger.Info("✅ [OKX WebSocket] 私有频道登录成功")
+	event.Publish(event.TypeStreamConnected, event.StreamConnected{Exchange: "OKX", Stream: "order"})
+
+	w.readLoop(conn, "私有", func(msg *wsEvent) {
+		if msg.Arg.Channel == "orders" && len(msg.Data) > 0 {
+			logger.Debug("🔍 [OKX WS订单] 推送数据: %s", string(msg.Data))
+			w.handleOrderUpdate(msg.Data)
+		}
+	})
+	return nil
+}
+
+// clearConn 连接断开后清理连接引用（只清理仍指向本连接的引用）
+func (w *WebSocketManager) clearConn(field **websocket.Conn, conn *websocket.Conn) {
+	w.mu.Lock()
+	if *field == conn {
+		*field = nil
+	}
+	w.mu.Unlock()
+}
+
+// login 发送登录认证并等待响应
+func (w *WebSocketManager) login(conn *websocket.Conn) error {
+	timestamp := w.signer.GetWSTimestamp()
+	loginMsg := map[string]interface{}{
+		"op": "login",
+		"args": []map[string]string{
+			{
+				"apiKey":     w.signer.GetAPIKey(),
+				"passphrase": w.signer.GetPassphrase(),
+				"timestamp":  timestamp,
+				"sign":       w.signer.SignWSLogin(timestamp),
+			},
+		},
+	}
+	if err := conn.WriteJSON(loginMsg); err != nil {
+		return fmt.Errorf("发送登录消息失败: %w", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	var resp wsEvent
+	if err := conn.ReadJSON(&resp); err != nil {
+		return fmt.Errorf("读取登录响应失败: %w", err)
+	}
+	if resp.Event != "login" || resp.Code != "0" {
+		return fmt.Errorf("登录失败: event=%s, code=%s, msg=%s", resp.Event, resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+// subscribe 发送订阅请求（订阅结果在读取循环中处理）
+func (w *WebSocketManager) subscribe(conn *websocket.Conn, args ...WSSubscribeArg) error {
+	subMsg := map[string]interface{}{
+		"op":   "subscribe",
+		"args": args,
+	}
+	for _, arg := range args {
+		logger.Info("📡 [OKX WS] 订阅频道: %s %s", arg.Channel, arg.InstID)
+	}
+	return conn.WriteJSON(subMsg)
+}
+
+// readLoop 读取消息（阻塞直到连接断开），数据推送交给 handle 处理
+func (w *WebSocketManager) readLoop(conn *websocket.Conn, connType string, handle func(msg *wsEvent)) {
+	done := make(chan struct{})
+	defer close(done)
+	go w.keepAlive(conn, connType, done)
+
+	// 设置读取超时：90秒（大于ping间隔的3倍）
+	conn.SetReadDeadline(time.Now().Add(90 * time.Second))
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-w.ctx.Done():
+			default:
+				logger.Warn("⚠️ [OKX WebSocket] 读取%s消息失败: %v", connType, err)
+			}
+			return
+		}
+
+		// 收到消息后更新读取超时
+		conn.SetReadDeadline(time.Now().Add(90 * time.Second))
+
+		if string(message) == "pong" {
+			logger.Debug("💓 [OKX WS%s] 收到 pong", connType)
+			continue
+		}
+
+		var msg wsEvent
+		if err := json.Unmarshal(message, &msg); err != nil {
+			logger.Warn("⚠️ [OKX WebSocket] 解析%s消息失败: %v", connType, err)
+			continue
+		}
+
+		switch msg.Event {
+		case "subscribe":
+			logger.Debug("✅ [OKX WS] 订阅成功: %s", msg.Arg.Channel)
+		case "error":
+			logger.Error("❌ [OKX WS%s] 错误: code=%s, msg=%s", connType, msg.Code, msg.Msg)
+		case "":
+			handle(&msg)
+		}
+	}
+}
+
+// keepAlive WebSocket 保活（每15秒发送 ping），done 关闭或发送失败时退出
+func (w *WebSocketManager) keepAlive(conn *websocket.Conn, connType string, done <-chan struct{}) {
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+				logger.Warn("⚠️ [OKX WS%s] 发送 ping 失败: %v", connType, err)
+				// ping 失败说明连接已断开，关闭连接使读取循环退出并重连
+				conn.Close()
+				return
+			}
+			logger.Debug("💓 [OKX WS%s] Ping已发送", connType)
+		}
+	}
+}
+
+// handlePriceUpdate 处理价格更新
+// 推送格式: {"arg":{"channel":"tickers","instId":"ETH-USDT-SWAP"},"data":[{"instId":"ETH-USDT-SWAP","last":"3000.1",...}]}
+func (w *WebSocketManager) handlePriceUpdate(data json.RawMessage) {
+	var updates []struct {
+		InstID string `json:"instId"`
+		Last   string `json:"last"`
+	}
+	if err := json.Unmarshal(data, &updates); err != nil {
+		logger.Warn("⚠️ [OKX WebSocket] 解析价格更新失败: %v", err)
+		return
+	}
+
+	for _, update := range updates {
+		price, _ := strconv.ParseFloat(update.Last, 64)
+		if price <= 0 {
+			continue
+		}
+		w.priceMu.Lock()
+		w.latestPrice = price
+		w.priceMu.Unlock()
+
+		w.mu.RLock()
+		callback := w.priceCallback
+		w.mu.RUnlock()
+		if callback != nil {
+			callback(update.InstID, price)
+		}
+	}
+}
+
+// handleOrderUpdate 处理订单更新（数量为张数，由适配器换算为基础币数量）
+func (w *WebSocketManager) handleOrderUpdate(data json.RawMessage) {
+	var updates []okxOrder
+	if err := json.Unmarshal(data, &updates); err != nil {
+		logger.Warn("⚠️ [OKX WebSocket] 解析订单更新失败: %v", err)
+		return
+	}
+
+	w.mu.RLock()
+	callback := w.orderCallback
+	w.mu.RUnlock()
+	if callback == nil {
+		return
+	}
+
+	for _, update := range updates {
+		if update.State == "canceled" || update.State == "mmp_canceled" {
+			logger.Debug("🔍 [OKX WS订单] 订单 %s 已撤销", update.OrdID)
+		}
+		orderUpdate := parseOrderUpdate(update)
+		logger.Debug("🔍 [OKX WS订单] 解析后: ID=%d, Status=%s, ExecutedQty=%.4f（张）",
+			orderUpdate.OrderID, orderUpdate.Status, orderUpdate.ExecutedQty)
+		callback(orderUpdate)
+	}
+}
+
+// parseOrderUpdate 解析订单推送（accFillSz 为累计成交张数）
+func parseOrderUpdate(data okxOrder) *OrderUpdate {
+	orderID, _ := strconv.ParseInt(data.OrdID, 10, 64)
+	price, _ := strconv.ParseFloat(data.Px, 64)
+	quantity, _ := strconv.ParseFloat(data.Sz, 64)
+	executedQty, _ := strconv.ParseFloat(data.AccFillSz, 64)
+	avgPrice, _ := strconv.ParseFloat(data.AvgPx, 64)
+	updateTime, _ := strconv.ParseInt(data.UTime, 10, 64)
+
+	side := SideBuy
+	if data.Side == "sell" {
+		side = SideSell
+	}
+	orderType := OrderTypeLimit
+	if data.OrdType == "market" {
+		orderType = OrderTypeMarket
+	}
+
+	return &OrderUpdate{
+		OrderID:       orderID,
+		ClientOrderID: utils.RemoveBrokerPrefix("okx", data.ClOrdID),
+		Symbol:        convertFromOKXInstID(data.InstID),
+		Side:          side,
+		Type:          orderType,
+		Status:        convertOrderStatus(data.State),
+		Price:         price,
+		Quantity:      quantity,
+		ExecutedQty:   executedQty,
+		AvgPrice:      avgPrice,
+		UpdateTime:    updateTime,
+	}
+}
+
+// GetLatestPrice 获取最新价格
+func (w *WebSocketManager) GetLatestPrice() float64 {
+	w.priceMu.RLock()
+	defer w.priceMu.RUnlock()
+	return w.latestPrice
+}
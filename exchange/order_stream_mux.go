@@ -0,0 +1,171 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"opensqt/logger"
+)
+
+// IMultiSymbolOrderStream 可选接口：一条订单流连接可同时订阅多个交易对的订单更新
+// 多交易对共用交易所连接时由 OrderStreamMux 使用；推送的订单更新需带 Symbol，数量按各自交易对换算为基础币数量
+type IMultiSymbolOrderStream interface {
+	// StartMultiSymbolOrderStream 在一条连接上订阅 symbols 的订单更新
+	StartMultiSymbolOrderStream(ctx context.Context, symbols []string, callback func(interface{})) error
+}
+
+// symbolNormalizer 去掉交易对中的分隔符（ETH_USDT、ETH-USDT、ETH/USDT 均视为 ETHUSDT）
+var symbolNormalizer = strings.NewReplacer("_", "", "-", "", "/", "")
+
+// NormalizeSymbol 交易对的统一写法（去掉分隔符并转为大写），用于比较不同交易所格式的交易对
+func NormalizeSymbol(symbol string) string {
+	return strings.ToUpper(symbolNormalizer.Replace(symbol))
+}
+
+// OrderStreamMux 多交易对共用一条订单流连接
+// 连接在第一个交易对订阅时建立、最后一个交易对退订时关闭，订单更新按 Symbol 分发给对应交易对的回调
+type OrderStreamMux struct {
+	conn    IExchange // 持有订单流连接的实例
+	stream  IMultiSymbolOrderStream
+	symbols []string
+
+	mu       sync.Mutex
+	handlers map[string]func(interface{}) // NormalizeSymbol(交易对) -> 订单更新回调
+
+	connMu  sync.Mutex // 串行化连接的建立和关闭（建立连接期间不持有 mu，推送可以正常分发）
+	started bool
+}
+
+// ShareOrderStream 多交易对共用 exchanges[0] 的订单流连接
+// 返回各交易对使用的实例（与 symbols 一一对应）：订单流改为通过共用连接订阅，其余调用仍走各交易对自己的实例
+// 交易所不支持在一条连接上订阅多个交易对时原样返回 exchanges，shared=false
+func ShareOrderStream(exchanges []IExchange, symbols []string) (views []IExchange, shared bool) {
+	if len(exchanges) != len(symbols) || len(exchanges) < 2 {
+		return exchanges, false
+	}
+	mux := NewOrderStreamMux(exchanges[0], symbols)
+	if mux == nil {
+		return exchanges, false
+	}
+	views = make([]IExchange, len(exchanges))
+	for i, ex := range exchanges {
+		views[i] = &sharedOrderStreamWrapper{IExchange: ex, mux: mux, symbol: symbols[i]}
+	}
+	return views, true
+}
+
+// NewOrderStreamMux 创建在 conn 的订单流连接上订阅 symbols 的分发器
+// conn 不支持多交易对订单流时返回 nil（已自动解开观察包装等外层包装）
+func NewOrderStreamMux(conn IExchange, symbols []string) *OrderStreamMux {
+	stream, ok := findCapability[IMultiSymbolOrderStream](conn)
+	if !ok {
+		return nil
+	}
+	return &OrderStreamMux{
+		conn:     conn,
+		stream:   stream,
+		symbols:  append([]string(nil), symbols...),
+		handlers: make(map[string]func(interface{}), len(symbols)),
+	}
+}
+
+// Subscribe 注册交易对的订单更新回调，第一个订阅时建立连接
+func (m *OrderStreamMux) Subscribe(ctx context.Context, symbol string, callback func(interface{})) error {
+	key := NormalizeSymbol(symbol)
+	if !m.covers(key) {
+		return fmt.Errorf("共用订单流未订阅交易对 %s", symbol)
+	}
+	m.mu.Lock()
+	m.handlers[key] = callback
+	m.mu.Unlock()
+
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
+	if m.started {
+		return nil
+	}
+	if err := m.stream.StartMultiSymbolOrderStream(ctx, m.symbols, m.dispatch); err != nil {
+		m.mu.Lock()
+		delete(m.handlers, key)
+		m.mu.Unlock()
+		return err
+	}
+	m.started = true
+	logger.Info("🔗 [共用订单流] %s 已在一条连接上订阅 %s 的订单更新", m.conn.GetName(), strings.Join(m.symbols, ", "))
+	return nil
+}
+
+// Unsubscribe 注销交易对的回调，最后一个交易对退订时关闭连接
+func (m *OrderStreamMux) Unsubscribe(symbol string) error {
+	m.connMu.Lock()
+	defer m.connMu.Unlock()
+	m.mu.Lock()
+	delete(m.handlers, NormalizeSymbol(symbol))
+	remaining := len(m.handlers)
+	m.mu.Unlock()
+	if !m.started || remaining > 0 {
+		return nil
+	}
+	m.started = false
+	return m.conn.StopOrderStream()
+}
+
+// covers 交易对是否在共用连接的订阅列表中
+func (m *OrderStreamMux) covers(key string) bool {
+	for _, symbol := range m.symbols {
+		if NormalizeSymbol(symbol) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch 按订单更新的交易对分发；未携带交易对的推送交给所有交易对（各仓位管理器忽略不属于自己的订单ID）
+func (m *OrderStreamMux) dispatch(raw interface{}) {
+	update, _, err := ToOrderUpdate(raw)
+	if err != nil {
+		logger.Warn("⚠️ [共用订单流] %v", err)
+		return
+	}
+
+	m.mu.Lock()
+	var targets []func(interface{})
+	if update.Symbol == "" {
+		for _, handler := range m.handlers {
+			targets = append(targets, handler)
+		}
+	} else if handler, ok := m.handlers[NormalizeSymbol(update.Symbol)]; ok {
+		targets = append(targets, handler)
+	}
+	m.mu.Unlock()
+
+	if len(targets) == 0 {
+		logger.Debug("🔍 [共用订单流] 忽略未订阅交易对的订单更新: %s 订单 %d", update.Symbol, update.OrderID)
+		return
+	}
+	for _, handler := range targets {
+		handler(update)
+	}
+}
+
+// sharedOrderStreamWrapper 交易对实例：订单流通过 OrderStreamMux 订阅共用连接，其余调用走本交易对的实例
+type sharedOrderStreamWrapper struct {
+	IExchange
+	mux    *OrderStreamMux
+	symbol string
+}
+
+// Unwrap 返回本交易对的实例（可选能力按原实例查找）
+func (w *sharedOrderStreamWrapper) Unwrap() IExchange {
+	return w.IExchange
+}
+
+func (w *sharedOrderStreamWrapper) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	return w.mux.Subscribe(ctx, w.symbol, callback)
+}
+
+func (w *sharedOrderStreamWrapper) StopOrderStream() error {
+	return w.mux.Unsubscribe(w.symbol)
+}
@@ -0,0 +1,127 @@
+package exchange
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// multiStreamExchange 测试用交易所：记录订单流连接的建立和关闭，push 模拟交易所在这条连接上推送订单更新
+type multiStreamExchange struct {
+	IExchange
+	starts   int
+	stops    int
+	symbols  []string
+	callback func(interface{})
+}
+
+func (f *multiStreamExchange) GetName() string { return "Fake" }
+
+func (f *multiStreamExchange) StartMultiSymbolOrderStream(ctx context.Context, symbols []string, callback func(interface{})) error {
+	f.starts++
+	f.symbols = symbols
+	f.callback = callback
+	return nil
+}
+
+func (f *multiStreamExchange) StopOrderStream() error {
+	f.stops++
+	f.callback = nil
+	return nil
+}
+
+// push 推送适配器格式的订单更新（匿名结构体，与适配器一致）
+func (f *multiStreamExchange) push(orderID int64, symbol string) {
+	f.callback(struct {
+		OrderID int64
+		Symbol  string
+	}{orderID, symbol})
+}
+
+// symbolOnlyExchange 每个交易对独立的实例（不支持多交易对订单流）
+type symbolOnlyExchange struct {
+	IExchange
+	symbol string
+}
+
+// receivedOrders 按交易对记录收到的订单ID
+type receivedOrders map[string][]int64
+
+func (r receivedOrders) callback(symbol string) func(interface{}) {
+	return func(raw interface{}) {
+		update, _, err := ToOrderUpdate(raw)
+		if err != nil {
+			panic(err)
+		}
+		r[symbol] = append(r[symbol], update.OrderID)
+	}
+}
+
+func TestShareOrderStreamRoutesBySymbol(t *testing.T) {
+	conn := &multiStreamExchange{}
+	symbols := []string{"ETHUSDT", "BTCUSDT", "SOLUSDT"}
+	// 主交易对实例带观察包装时仍能找到多交易对订单流
+	exchanges := []IExchange{WithCallObserver(conn, func(string, error) {}), &symbolOnlyExchange{symbol: "BTCUSDT"}, &symbolOnlyExchange{symbol: "SOLUSDT"}}
+	views, shared := ShareOrderStream(exchanges, symbols)
+	if !shared || len(views) != len(symbols) {
+		t.Fatalf("支持多交易对订单流时应共用连接，shared=%v", shared)
+	}
+
+	received := receivedOrders{}
+	for i, view := range views {
+		if err := view.StartOrderStream(context.Background(), received.callback(symbols[i])); err != nil {
+			t.Fatalf("%s 订阅订单流失败: %v", symbols[i], err)
+		}
+	}
+	if conn.starts != 1 || !reflect.DeepEqual(conn.symbols, symbols) {
+		t.Fatalf("所有交易对应共用一条连接订阅 %v，实际建立 %d 次、订阅 %v", symbols, conn.starts, conn.symbols)
+	}
+
+	conn.push(1, "BTCUSDT")
+	conn.push(2, "eth-usdt") // 小写、带分隔符
+	conn.push(3, "SOL_USDT") // Gate 格式
+	conn.push(4, "DOGEUSDT") // 未订阅的交易对
+	conn.push(5, "")         // 未携带交易对
+	want := receivedOrders{"ETHUSDT": {2, 5}, "BTCUSDT": {1, 5}, "SOLUSDT": {3, 5}}
+	if !reflect.DeepEqual(received, want) {
+		t.Fatalf("订单更新应按交易对分发，期望 %v，实际 %v", want, received)
+	}
+
+	// 最后一个交易对退订时才关闭连接
+	for i, view := range views {
+		if err := view.StopOrderStream(); err != nil {
+			t.Fatal(err)
+		}
+		wantStops := 0
+		if i == len(views)-1 {
+			wantStops = 1
+		}
+		if conn.stops != wantStops {
+			t.Fatalf("%d/%d 个交易对退订后连接关闭次数应为 %d，实际 %d", i+1, len(views), wantStops, conn.stops)
+		}
+	}
+
+	// 非主交易对实例的其他调用仍走自己的实例
+	if views[1].(interface{ Unwrap() IExchange }).Unwrap() != exchanges[1] {
+		t.Fatal("共用订单流的包装应解开为该交易对自己的实例")
+	}
+}
+
+func TestShareOrderStreamUnsupported(t *testing.T) {
+	exchanges := []IExchange{&symbolOnlyExchange{symbol: "ETHUSDT"}, &symbolOnlyExchange{symbol: "BTCUSDT"}}
+	views, shared := ShareOrderStream(exchanges, []string{"ETHUSDT", "BTCUSDT"})
+	if shared || !reflect.DeepEqual(views, exchanges) {
+		t.Fatal("不支持多交易对订单流时应原样返回各交易对的实例")
+	}
+}
+
+func TestOrderStreamMuxRejectsUnknownSymbol(t *testing.T) {
+	conn := &multiStreamExchange{}
+	mux := NewOrderStreamMux(conn, []string{"ETHUSDT"})
+	if err := mux.Subscribe(context.Background(), "BTCUSDT", func(interface{}) {}); err == nil {
+		t.Fatal("订阅列表之外的交易对应返回错误")
+	}
+	if conn.starts != 0 {
+		t.Fatal("订阅失败时不应建立连接")
+	}
+}
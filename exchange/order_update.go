@@ -0,0 +1,115 @@
+package exchange
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"opensqt/logger"
+)
+
+// orderUpdateFields 订单推送结构体应包含的字段及类型
+// 各交易所适配器为避免循环导入，通过 StartOrderStream 回调推送各自的结构体（通常是匿名结构体），按字段名提取
+var orderUpdateFields = []struct {
+	name string
+	kind reflect.Kind // reflect.Int64 表示任意整数类型，reflect.Float64 表示任意浮点类型
+}{
+	{"OrderID", reflect.Int64},
+	{"ClientOrderID", reflect.String},
+	{"Symbol", reflect.String},
+	{"Side", reflect.String},
+	{"Type", reflect.String},
+	{"Status", reflect.String},
+	{"Price", reflect.Float64},
+	{"Quantity", reflect.Float64},
+	{"ExecutedQty", reflect.Float64},
+	{"AvgPrice", reflect.Float64},
+	{"UpdateTime", reflect.Int64},
+}
+
+// 字段缺失/类型不符的警告按 结构体类型 + 字段名 只输出一次（订单推送频繁，避免刷屏）
+var orderUpdateWarned sync.Map
+
+// ToOrderUpdate 将适配器推送的订单更新转换为 OrderUpdate
+// 支持 OrderUpdate、结构体及其指针；字段缺失或类型不符时取零值并输出警告（通常意味着适配器字段命名错误）
+// 返回的 missing 为缺失或类型不符的字段名，不是结构体时返回错误
+func ToOrderUpdate(raw interface{}) (update OrderUpdate, missing []string, err error) {
+	switch u := raw.(type) {
+	case OrderUpdate:
+		return u, nil, nil
+	case *OrderUpdate:
+		if u != nil {
+			return *u, nil, nil
+		}
+	}
+
+	v := reflect.ValueOf(raw)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return OrderUpdate{}, nil, fmt.Errorf("订单更新不是结构体类型: %T", raw)
+	}
+
+	values := make(map[string]reflect.Value, len(orderUpdateFields))
+	for _, f := range orderUpdateFields {
+		field := v.FieldByName(f.name)
+		var reason string
+		switch {
+		case !field.IsValid():
+			reason = "缺失"
+		case f.kind == reflect.Int64 && !field.CanInt() && !field.CanUint():
+			reason = fmt.Sprintf("类型为 %s，应为整数", field.Type())
+		case f.kind == reflect.Float64 && !field.CanFloat():
+			reason = fmt.Sprintf("类型为 %s，应为浮点数", field.Type())
+		case f.kind == reflect.String && field.Kind() != reflect.String:
+			reason = fmt.Sprintf("类型为 %s，应为字符串", field.Type())
+		}
+		if reason == "" {
+			values[f.name] = field
+			continue
+		}
+
+		missing = append(missing, f.name)
+		key := v.Type().String() + "." + f.name
+		if _, warned := orderUpdateWarned.LoadOrStore(key, struct{}{}); !warned {
+			logger.Warn("⚠️ [订单推送] %T 的字段 %s %s，按零值处理，请检查交易所适配器", raw, f.name, reason)
+		}
+	}
+
+	getInt := func(name string) int64 {
+		if field, ok := values[name]; ok {
+			if field.CanUint() {
+				return int64(field.Uint())
+			}
+			return field.Int()
+		}
+		return 0
+	}
+	getFloat := func(name string) float64 {
+		if field, ok := values[name]; ok {
+			return field.Float()
+		}
+		return 0
+	}
+	getString := func(name string) string {
+		if field, ok := values[name]; ok {
+			return field.String()
+		}
+		return ""
+	}
+
+	return OrderUpdate{
+		OrderID:       getInt("OrderID"),
+		ClientOrderID: getString("ClientOrderID"),
+		Symbol:        getString("Symbol"),
+		Side:          Side(getString("Side")),
+		Type:          OrderType(getString("Type")),
+		Status:        OrderStatus(getString("Status")),
+		Price:         getFloat("Price"),
+		Quantity:      getFloat("Quantity"),
+		ExecutedQty:   getFloat("ExecutedQty"),
+		AvgPrice:      getFloat("AvgPrice"),
+		UpdateTime:    getInt("UpdateTime"),
+	}, missing, nil
+}
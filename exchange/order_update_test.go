@@ -0,0 +1,129 @@
+package exchange
+
+import (
+	"reflect"
+	"testing"
+
+	"opensqt/exchange/gate"
+)
+
+// adapterUpdate 币安、Bitget、OKX、模拟交易所推送的匿名结构体
+type adapterUpdate = struct {
+	OrderID       int64
+	ClientOrderID string
+	Symbol        string
+	Side          string
+	Type          string
+	Status        string
+	Price         float64
+	Quantity      float64
+	ExecutedQty   float64
+	AvgPrice      float64
+	UpdateTime    int64
+}
+
+func TestToOrderUpdate(t *testing.T) {
+	full := OrderUpdate{
+		OrderID: 42, ClientOrderID: "c42", Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit,
+		Status: OrderStatusPartiallyFilled, Price: 3000.5, Quantity: 0.02, ExecutedQty: 0.01, AvgPrice: 3000.4, UpdateTime: 1700000000000,
+	}
+	anonymous := adapterUpdate{
+		OrderID: 42, ClientOrderID: "c42", Symbol: "ETHUSDT", Side: "BUY", Type: "LIMIT",
+		Status: "PARTIALLY_FILLED", Price: 3000.5, Quantity: 0.02, ExecutedQty: 0.01, AvgPrice: 3000.4, UpdateTime: 1700000000000,
+	}
+	gateUpdate := gate.OrderUpdate{
+		OrderID: 42, ClientOrderID: "c42", Symbol: "ETHUSDT", Side: gate.SideBuy, Type: gate.OrderTypeLimit,
+		Status: gate.OrderStatus("PARTIALLY_FILLED"), Price: 3000.5, Quantity: 0.02, ExecutedQty: 0.01, AvgPrice: 3000.4, UpdateTime: 1700000000000,
+	}
+
+	tests := []struct {
+		name    string
+		raw     interface{}
+		want    OrderUpdate
+		missing []string
+	}{
+		{"OrderUpdate", full, full, nil},
+		{"*OrderUpdate", &full, full, nil},
+		{"币安/Bitget/OKX/模拟交易所的匿名结构体", anonymous, full, nil},
+		{"匿名结构体指针", &anonymous, full, nil},
+		{"Gate 订单推送（字符串别名类型）", gateUpdate, full, nil},
+		{
+			"其他整数和浮点类型",
+			struct {
+				OrderID                                int
+				ClientOrderID, Symbol, Side            string
+				Type, Status                           string
+				Price, Quantity, ExecutedQty, AvgPrice float32
+				UpdateTime                             uint64
+			}{42, "c42", "ETHUSDT", "BUY", "LIMIT", "PARTIALLY_FILLED", 0.5, 0.25, 0.125, 0.5, 1700000000000},
+			OrderUpdate{OrderID: 42, ClientOrderID: "c42", Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit,
+				Status: OrderStatusPartiallyFilled, Price: 0.5, Quantity: 0.25, ExecutedQty: 0.125, AvgPrice: 0.5, UpdateTime: 1700000000000},
+			nil,
+		},
+		{
+			"缺少 UpdateTime 和 AvgPrice",
+			struct {
+				OrderID                      int64
+				ClientOrderID, Symbol, Side  string
+				Type, Status                 string
+				Price, Quantity, ExecutedQty float64
+			}{42, "c42", "ETHUSDT", "BUY", "LIMIT", "FILLED", 3000.5, 0.02, 0.02},
+			OrderUpdate{OrderID: 42, ClientOrderID: "c42", Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit,
+				Status: OrderStatusFilled, Price: 3000.5, Quantity: 0.02, ExecutedQty: 0.02},
+			[]string{"AvgPrice", "UpdateTime"},
+		},
+		{
+			"字段改名（ClientOID、FilledQty）",
+			struct {
+				OrderID                 int64
+				ClientOID, Symbol, Side string
+				Type, Status            string
+				Price, Quantity         float64
+				FilledQty, AvgPrice     float64
+				UpdateTime              int64
+			}{42, "c42", "ETHUSDT", "SELL", "LIMIT", "FILLED", 3000.5, 0.02, 0.02, 3000.5, 1},
+			OrderUpdate{OrderID: 42, Symbol: "ETHUSDT", Side: SideSell, Type: OrderTypeLimit,
+				Status: OrderStatusFilled, Price: 3000.5, Quantity: 0.02, AvgPrice: 3000.5, UpdateTime: 1},
+			[]string{"ClientOrderID", "ExecutedQty"},
+		},
+		{
+			"字段类型不符（价格为字符串）",
+			struct {
+				OrderID                     int64
+				ClientOrderID, Symbol, Side string
+				Type, Status                string
+				Price                       string
+				Quantity, ExecutedQty       float64
+				AvgPrice                    float64
+				UpdateTime                  int64
+			}{42, "c42", "ETHUSDT", "BUY", "LIMIT", "NEW", "3000.5", 0.02, 0, 0, 1},
+			OrderUpdate{OrderID: 42, ClientOrderID: "c42", Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit,
+				Status: OrderStatusNew, Quantity: 0.02, UpdateTime: 1},
+			[]string{"Price"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, missing, err := ToOrderUpdate(tt.raw)
+			if err != nil {
+				t.Fatalf("不应返回错误: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("转换结果\n得到 %+v\n应为 %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(missing, tt.missing) {
+				t.Errorf("缺失字段应为 %v，实际 %v", tt.missing, missing)
+			}
+		})
+	}
+}
+
+func TestToOrderUpdateRejectsNonStruct(t *testing.T) {
+	var nilUpdate *OrderUpdate
+	for _, raw := range []interface{}{nil, 42, "FILLED", nilUpdate, map[string]interface{}{"OrderID": int64(1)}} {
+		if _, _, err := ToOrderUpdate(raw); err == nil {
+			t.Errorf("%T 应返回错误", raw)
+		}
+	}
+}
@@ -14,8 +14,9 @@ const (
 type OrderType string
 
 const (
-	OrderTypeLimit  OrderType = "LIMIT"
-	OrderTypeMarket OrderType = "MARKET"
+	OrderTypeLimit              OrderType = "LIMIT"
+	OrderTypeMarket             OrderType = "MARKET"
+	OrderTypeTrailingStopMarket OrderType = "TRAILING_STOP_MARKET" // 交易所原生追踪止损（触发后市价成交）
 )
 
 // OrderStatus 订单状态
@@ -54,6 +55,15 @@ type OrderRequest struct {
 	ClientOrderID string  // 自定义订单ID
 }
 
+// TrailingStopRequest 交易所原生追踪止损请求（只减仓，触发后市价成交）
+type TrailingStopRequest struct {
+	Symbol          string
+	Side            Side    // 平多仓为 SELL
+	Quantity        float64 // 平仓数量
+	CallbackRate    float64 // 回撤比例（百分比，1 表示价格从激活后的最高价回撤 1% 时触发）
+	ActivationPrice float64 // 激活价格（0 表示立即激活）
+}
+
 // Order 订单信息（通用）
 type Order struct {
 	OrderID       int64
@@ -91,6 +101,21 @@ type Account struct {
 	AccountLeverage    int // 账户级别的杠杆倍数（部分交易所支持）
 }
 
+// Trade 历史成交记录（通用）
+type Trade struct {
+	TradeID     int64
+	OrderID     int64
+	Symbol      string
+	Side        Side
+	Price       float64
+	Quantity    float64
+	RealizedPnL float64 // 交易所返回的已实现盈亏（不含手续费，交易所未提供时为0）
+	Fee         float64 // 手续费（正数表示支付，负数表示返佣）
+	FeeAsset    string
+	IsMaker     bool
+	Time        time.Time
+}
+
 // OrderUpdate WebSocket 订单更新事件（通用）
 type OrderUpdate struct {
 	OrderID       int64
@@ -123,3 +148,26 @@ type Candle struct {
 
 // CandleUpdateCallback K线更新回调函数
 type CandleUpdateCallback func(candle *Candle)
+
+// OrderBookTop 盘口一档（买一/卖一）
+type OrderBookTop struct {
+	BidPrice float64
+	BidQty   float64
+	AskPrice float64
+	AskQty   float64
+}
+
+// Mid 中间价
+func (t OrderBookTop) Mid() float64 {
+	return (t.BidPrice + t.AskPrice) / 2
+}
+
+// Microprice 按挂单量加权的中间价
+// 卖一量大于买一量时更靠近买一（卖压更重），反之更靠近卖一
+func (t OrderBookTop) Microprice() float64 {
+	total := t.BidQty + t.AskQty
+	if total <= 0 {
+		return t.Mid()
+	}
+	return (t.BidPrice*t.AskQty + t.AskPrice*t.BidQty) / total
+}
@@ -2,6 +2,8 @@ package exchange
 
 import (
 	"context"
+	"time"
+
 	"opensqt/exchange/binance"
 )
 
@@ -224,10 +226,41 @@ func (w *binanceWrapper) GetBalance(ctx context.Context, asset string) (float64,
 	return w.adapter.GetBalance(ctx, asset)
 }
 
+func (w *binanceWrapper) GetUserTrades(ctx context.Context, symbol string, since time.Time) ([]*Trade, error) {
+	binanceTrades, err := w.adapter.GetUserTrades(ctx, symbol, since)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]*Trade, len(binanceTrades))
+	for i, t := range binanceTrades {
+		trades[i] = &Trade{
+			TradeID:     t.TradeID,
+			OrderID:     t.OrderID,
+			Symbol:      t.Symbol,
+			Side:        Side(t.Side),
+			Price:       t.Price,
+			Quantity:    t.Quantity,
+			RealizedPnL: t.RealizedPnL,
+			Fee:         t.Fee,
+			FeeAsset:    t.FeeAsset,
+			IsMaker:     t.IsMaker,
+			Time:        t.Time,
+		}
+	}
+
+	return trades, nil
+}
+
 func (w *binanceWrapper) StartOrderStream(ctx context.Context, callback func(interface{})) error {
 	return w.adapter.StartOrderStream(ctx, callback)
 }
 
+// StartMultiSymbolOrderStream 币安的用户数据流是账户级的，一条连接即可收到所有交易对的订单更新
+func (w *binanceWrapper) StartMultiSymbolOrderStream(ctx context.Context, symbols []string, callback func(interface{})) error {
+	return w.adapter.StartOrderStream(ctx, callback)
+}
+
 func (w *binanceWrapper) StopOrderStream() error {
 	return w.adapter.StopOrderStream()
 }
@@ -298,3 +331,74 @@ func (w *binanceWrapper) GetBaseAsset() string {
 func (w *binanceWrapper) GetQuoteAsset() string {
 	return w.adapter.GetQuoteAsset()
 }
+
+func (w *binanceWrapper) GetMinNotional() float64 {
+	return w.adapter.GetMinNotional()
+}
+
+// GetTradingFees 获取当前手续费率（实现 IFeeRateProvider）
+func (w *binanceWrapper) GetTradingFees(ctx context.Context, symbol string) (float64, float64, error) {
+	return w.adapter.GetTradingFees(ctx, symbol)
+}
+
+// GetFundingRate 获取当前资金费率（实现 IFundingRateProvider）
+func (w *binanceWrapper) GetFundingRate(ctx context.Context, symbol string) (float64, error) {
+	return w.adapter.GetFundingRate(ctx, symbol)
+}
+
+// GetSymbolLeverage 查询交易对杠杆倍数（实现 ILeverageProvider）
+func (w *binanceWrapper) GetSymbolLeverage(symbol string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return w.adapter.GetSymbolLeverage(ctx, symbol)
+}
+
+// GetOrderBookTop 获取盘口一档（实现 IDepthProvider）
+func (w *binanceWrapper) GetOrderBookTop(ctx context.Context, symbol string) (*OrderBookTop, error) {
+	bidPrice, bidQty, askPrice, askQty, err := w.adapter.GetOrderBookTop(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderBookTop{BidPrice: bidPrice, BidQty: bidQty, AskPrice: askPrice, AskQty: askQty}, nil
+}
+
+// PlaceTrailingStop 下追踪止损单（实现 ITrailingStopPlacer）
+func (w *binanceWrapper) PlaceTrailingStop(ctx context.Context, req *TrailingStopRequest) (*Order, error) {
+	order, err := w.adapter.PlaceTrailingStop(ctx, req.Symbol, binance.Side(req.Side), req.Quantity, req.CallbackRate, req.ActivationPrice)
+	if err != nil {
+		return nil, err
+	}
+	return &Order{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Side:          Side(order.Side),
+		Type:          OrderType(order.Type),
+		Quantity:      order.Quantity,
+		Status:        OrderStatus(order.Status),
+		CreatedAt:     order.CreatedAt,
+		UpdateTime:    order.UpdateTime,
+	}, nil
+}
+
+// ExecutedQtyIsIncremental 订单推送的成交数量是否为增量（实现 IExecutedQtyProvider）
+func (w *binanceWrapper) ExecutedQtyIsIncremental() bool {
+	return w.adapter.ExecutedQtyIsIncremental()
+}
+
+// Capabilities 实现 ICapabilityProvider
+// 只做 Maker 用 timeInForce=GTX，只减仓单传 reduceOnly=true，追踪止损用 TRAILING_STOP_MARKET。
+// 普通下单固定为限价单，不支持市价/IOC
+func (w *binanceWrapper) Capabilities() Capabilities {
+	return Capabilities{PostOnly: true, ReduceOnly: true, NativeStop: true}
+}
+
+// RefreshSymbolInfo 重新获取合约信息（实现 ISymbolInfoRefresher）
+func (w *binanceWrapper) RefreshSymbolInfo(ctx context.Context) error {
+	return w.adapter.RefreshSymbolInfo(ctx)
+}
+
+// ActiveWSEndpoint 当前使用的 WebSocket 地址
+func (w *binanceWrapper) ActiveWSEndpoint() string {
+	return w.adapter.ActiveWSEndpoint()
+}
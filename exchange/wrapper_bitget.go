@@ -2,6 +2,8 @@ package exchange
 
 import (
 	"context"
+	"time"
+
 	"opensqt/exchange/bitget"
 )
 
@@ -211,10 +213,41 @@ func (w *bitgetWrapper) GetBalance(ctx context.Context, asset string) (float64,
 	return w.adapter.GetBalance(ctx, asset)
 }
 
+func (w *bitgetWrapper) GetUserTrades(ctx context.Context, symbol string, since time.Time) ([]*Trade, error) {
+	bitgetTrades, err := w.adapter.GetUserTrades(ctx, symbol, since)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]*Trade, len(bitgetTrades))
+	for i, t := range bitgetTrades {
+		trades[i] = &Trade{
+			TradeID:     t.TradeID,
+			OrderID:     t.OrderID,
+			Symbol:      t.Symbol,
+			Side:        Side(t.Side),
+			Price:       t.Price,
+			Quantity:    t.Quantity,
+			RealizedPnL: t.RealizedPnL,
+			Fee:         t.Fee,
+			FeeAsset:    t.FeeAsset,
+			IsMaker:     t.IsMaker,
+			Time:        t.Time,
+		}
+	}
+
+	return trades, nil
+}
+
 func (w *bitgetWrapper) StartOrderStream(ctx context.Context, callback func(interface{})) error {
 	return w.adapter.StartOrderStream(ctx, callback)
 }
 
+// StartMultiSymbolOrderStream Bitget 私有频道按 instId=default 订阅所有交易对的订单，一条连接即可收到所有交易对的订单更新
+func (w *bitgetWrapper) StartMultiSymbolOrderStream(ctx context.Context, symbols []string, callback func(interface{})) error {
+	return w.adapter.StartOrderStream(ctx, callback)
+}
+
 func (w *bitgetWrapper) StopOrderStream() error {
 	return w.adapter.StopOrderStream()
 }
@@ -285,3 +318,54 @@ func (w *bitgetWrapper) GetBaseAsset() string {
 func (w *bitgetWrapper) GetQuoteAsset() string {
 	return w.adapter.GetQuoteAsset()
 }
+
+func (w *bitgetWrapper) GetMinNotional() float64 {
+	return w.adapter.GetMinNotional()
+}
+
+// GetFundingRate 获取当前资金费率（实现 IFundingRateProvider）
+func (w *bitgetWrapper) GetFundingRate(ctx context.Context, symbol string) (float64, error) {
+	return w.adapter.GetFundingRate(ctx, symbol)
+}
+
+// PlaceTrailingStop 下追踪止损单（实现 ITrailingStopPlacer）
+func (w *bitgetWrapper) PlaceTrailingStop(ctx context.Context, req *TrailingStopRequest) (*Order, error) {
+	order, err := w.adapter.PlaceTrailingStop(ctx, req.Symbol, bitget.Side(req.Side), req.Quantity, req.CallbackRate, req.ActivationPrice)
+	if err != nil {
+		return nil, err
+	}
+	return &Order{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Side:          Side(order.Side),
+		Type:          OrderType(order.Type),
+		Price:         order.Price,
+		Quantity:      order.Quantity,
+		Status:        OrderStatus(order.Status),
+		CreatedAt:     order.CreatedAt,
+		UpdateTime:    order.UpdateTime,
+	}, nil
+}
+
+// ExecutedQtyIsIncremental 订单推送的成交数量是否为增量（实现 IExecutedQtyProvider）
+func (w *bitgetWrapper) ExecutedQtyIsIncremental() bool {
+	return w.adapter.ExecutedQtyIsIncremental()
+}
+
+// Capabilities 实现 ICapabilityProvider
+// 只做 Maker 传 force=post_only；只减仓单向持仓传 reduceOnly=YES，双向持仓用 tradeSide=close；
+// 追踪止损用计划委托 track_plan。普通下单固定为限价单，不支持市价/IOC
+func (w *bitgetWrapper) Capabilities() Capabilities {
+	return Capabilities{PostOnly: true, ReduceOnly: true, NativeStop: true}
+}
+
+// RefreshSymbolInfo 重新获取合约信息（实现 ISymbolInfoRefresher）
+func (w *bitgetWrapper) RefreshSymbolInfo(ctx context.Context) error {
+	return w.adapter.RefreshSymbolInfo(ctx)
+}
+
+// ActiveWSEndpoint 当前使用的 WebSocket 地址
+func (w *bitgetWrapper) ActiveWSEndpoint() string {
+	return w.adapter.ActiveWSEndpoint()
+}
@@ -0,0 +1,95 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"opensqt/logger"
+)
+
+// cancelAllWrapper 让 CancelAllOrders 在并发调用时幂等（止盈退出、信号退出、紧急平仓可能同时触发）
+// 同一交易对已有撤单在进行时，后来的调用等待并共享这次撤单的结果，不再交错发出撤单请求；
+// 撤单后查询未完成订单确认已全部撤销，仍有残留时按 retries 重试，返回一致的最终状态
+type cancelAllWrapper struct {
+	IExchange
+	retries int
+
+	mu       sync.Mutex
+	inflight map[string]*cancelAllCall
+}
+
+// cancelAllCall 进行中的一次撤单（done 关闭后 err 为最终结果）
+type cancelAllCall struct {
+	done chan struct{}
+	err  error
+}
+
+// WithIdempotentCancelAll 为交易所实例附加并发安全的 CancelAllOrders（retries 为确认失败后的重试次数）
+func WithIdempotentCancelAll(ex IExchange, retries int) IExchange {
+	return &cancelAllWrapper{IExchange: ex, retries: retries, inflight: make(map[string]*cancelAllCall)}
+}
+
+// Unwrap 返回被包装的交易所实例
+func (w *cancelAllWrapper) Unwrap() IExchange {
+	return w.IExchange
+}
+
+// CancelAllOrders 撤销所有订单；与进行中的撤单合并，返回同一结果
+func (w *cancelAllWrapper) CancelAllOrders(ctx context.Context, symbol string) error {
+	w.mu.Lock()
+	if call, ok := w.inflight[symbol]; ok {
+		w.mu.Unlock()
+		logger.Debug("🔁 [撤销全部] %s 已有撤单进行中，等待其结果", symbol)
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	call := &cancelAllCall{done: make(chan struct{})}
+	w.inflight[symbol] = call
+	w.mu.Unlock()
+
+	call.err = w.cancelAndConfirm(ctx, symbol)
+
+	w.mu.Lock()
+	delete(w.inflight, symbol)
+	w.mu.Unlock()
+	close(call.done)
+	return call.err
+}
+
+// cancelAndConfirm 撤单后查询未完成订单，确认全部撤销（查询失败时以撤单结果为准）
+func (w *cancelAllWrapper) cancelAndConfirm(ctx context.Context, symbol string) error {
+	var err error
+	for attempt := 0; attempt <= w.retries; attempt++ {
+		if attempt > 0 {
+			logger.Warn("⚠️ [撤销全部] %s 第 %d 次重试: %v", symbol, attempt, err)
+			select {
+			case <-time.After(time.Duration(attempt) * 500 * time.Millisecond):
+			case <-ctx.Done():
+				return fmt.Errorf("%w (最后一次错误: %v)", ctx.Err(), err)
+			}
+		}
+
+		err = w.IExchange.CancelAllOrders(ctx, symbol)
+		if IsUnsupportedError(err) {
+			return err // 交易所没有一键撤单接口，由调用方改用分批撤单
+		}
+		orders, queryErr := w.IExchange.GetOpenOrders(ctx, symbol)
+		if queryErr != nil {
+			if err == nil {
+				return nil // 无法确认，以撤单成功为准
+			}
+			continue
+		}
+		if len(orders) == 0 {
+			return nil // 已无未完成订单（撤单报错如"无订单可撤"也视为成功）
+		}
+		err = fmt.Errorf("撤单后仍有 %d 个未完成订单", len(orders))
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package exchange
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cancelAllExchange 测试用交易所：记录一键撤单和未完成订单查询次数，release 关闭前撤单请求阻塞
+type cancelAllExchange struct {
+	IExchange
+
+	mu           sync.Mutex
+	release      chan struct{}
+	cancelCalls  int
+	queryCalls   int
+	stuckCancels int // 前 N 次撤单后仍残留订单
+}
+
+func (f *cancelAllExchange) CancelAllOrders(ctx context.Context, symbol string) error {
+	f.mu.Lock()
+	f.cancelCalls++
+	f.mu.Unlock()
+	<-f.release
+	return nil
+}
+
+func (f *cancelAllExchange) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queryCalls++
+	if f.stuckCancels > 0 {
+		f.stuckCancels--
+		return []*Order{{OrderID: 1, Symbol: symbol}}, nil
+	}
+	return nil, nil
+}
+
+func (f *cancelAllExchange) counts() (cancels, queries int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.cancelCalls, f.queryCalls
+}
+
+func TestCancelAllOrdersConcurrentCallsShareOneCancel(t *testing.T) {
+	fake := &cancelAllExchange{release: make(chan struct{})}
+	ex := WithIdempotentCancelAll(fake, 2)
+
+	const callers = 8
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		go func() { errs <- ex.CancelAllOrders(context.Background(), "ETHUSDT") }()
+	}
+	// 等第一个调用发出撤单，其余调用进入等待后再放行
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		if cancels, _ := fake.counts(); cancels > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("撤单请求未发出")
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(fake.release)
+
+	for i := 0; i < callers; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("撤单应成功: %v", err)
+		}
+	}
+	if cancels, queries := fake.counts(); cancels != 1 || queries != 1 {
+		t.Fatalf("并发调用应合并为一次撤单和一次确认查询，实际撤单 %d 次、查询 %d 次", cancels, queries)
+	}
+
+	// 上一次撤单结束后再调用会重新撤单
+	if err := ex.CancelAllOrders(context.Background(), "ETHUSDT"); err != nil {
+		t.Fatalf("撤单应成功: %v", err)
+	}
+	if cancels, _ := fake.counts(); cancels != 2 {
+		t.Fatalf("撤单结束后的调用应重新撤单，实际撤单 %d 次", cancels)
+	}
+}
+
+func TestCancelAllOrdersRetriesUntilConfirmed(t *testing.T) {
+	fake := &cancelAllExchange{release: make(chan struct{}), stuckCancels: 1}
+	close(fake.release)
+	ex := WithIdempotentCancelAll(fake, 2)
+
+	if err := ex.CancelAllOrders(context.Background(), "ETHUSDT"); err != nil {
+		t.Fatalf("重试后撤单应成功: %v", err)
+	}
+	if cancels, queries := fake.counts(); cancels != 2 || queries != 2 {
+		t.Fatalf("确认仍有残留订单时应重试一次，实际撤单 %d 次、查询 %d 次", cancels, queries)
+	}
+}
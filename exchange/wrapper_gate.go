@@ -2,6 +2,8 @@ package exchange
 
 import (
 	"context"
+	"time"
+
 	"opensqt/exchange/gate"
 	"opensqt/utils"
 )
@@ -239,10 +241,41 @@ func (w *gateWrapper) GetBalance(ctx context.Context, asset string) (float64, er
 	return w.adapter.GetBalance(ctx, asset)
 }
 
+func (w *gateWrapper) GetUserTrades(ctx context.Context, symbol string, since time.Time) ([]*Trade, error) {
+	gateTrades, err := w.adapter.GetUserTrades(ctx, symbol, since)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]*Trade, len(gateTrades))
+	for i, t := range gateTrades {
+		trades[i] = &Trade{
+			TradeID:     t.TradeID,
+			OrderID:     t.OrderID,
+			Symbol:      t.Symbol,
+			Side:        Side(t.Side),
+			Price:       t.Price,
+			Quantity:    t.Quantity,
+			RealizedPnL: t.RealizedPnL,
+			Fee:         t.Fee,
+			FeeAsset:    t.FeeAsset,
+			IsMaker:     t.IsMaker,
+			Time:        t.Time,
+		}
+	}
+
+	return trades, nil
+}
+
 func (w *gateWrapper) StartOrderStream(ctx context.Context, callback func(interface{})) error {
 	return w.adapter.StartOrderStream(ctx, callback)
 }
 
+// StartMultiSymbolOrderStream 在一条连接上订阅多个交易对的订单更新（各交易对按自己的合约乘数换算数量）
+func (w *gateWrapper) StartMultiSymbolOrderStream(ctx context.Context, symbols []string, callback func(interface{})) error {
+	return w.adapter.StartMultiSymbolOrderStream(ctx, symbols, callback)
+}
+
 func (w *gateWrapper) StopOrderStream() error {
 	return w.adapter.StopOrderStream()
 }
@@ -322,3 +355,28 @@ func (w *gateWrapper) GetQuoteAsset() string {
 	// 从交易对中提取计价资产
 	return "USDT"
 }
+
+func (w *gateWrapper) GetMinNotional() float64 {
+	return w.adapter.GetMinNotional()
+}
+
+// ExecutedQtyIsIncremental 订单推送的成交数量是否为增量（实现 IExecutedQtyProvider）
+func (w *gateWrapper) ExecutedQtyIsIncremental() bool {
+	return w.adapter.ExecutedQtyIsIncremental()
+}
+
+// Capabilities 实现 ICapabilityProvider
+// 只做 Maker 用 tif=poc，只减仓单传 reduce_only=true。普通下单固定为限价单，不支持市价/IOC 和原生止损
+func (w *gateWrapper) Capabilities() Capabilities {
+	return Capabilities{PostOnly: true, ReduceOnly: true}
+}
+
+// RefreshSymbolInfo 重新获取合约信息（实现 ISymbolInfoRefresher）
+func (w *gateWrapper) RefreshSymbolInfo(ctx context.Context) error {
+	return w.adapter.RefreshSymbolInfo(ctx)
+}
+
+// ActiveWSEndpoint 当前使用的 WebSocket 地址
+func (w *gateWrapper) ActiveWSEndpoint() string {
+	return w.adapter.ActiveWSEndpoint()
+}
@@ -0,0 +1,351 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	"opensqt/exchange/mock"
+)
+
+// mockWrapper 包装模拟交易所适配器以实现 IExchange 接口
+type mockWrapper struct {
+	adapter *mock.MockAdapter
+}
+
+// fromMockOrder 转换模拟交易所订单
+func fromMockOrder(o *mock.Order) *Order {
+	return &Order{
+		OrderID:       o.OrderID,
+		ClientOrderID: o.ClientOrderID,
+		Symbol:        o.Symbol,
+		Side:          Side(o.Side),
+		Type:          OrderType(o.Type),
+		Price:         o.Price,
+		Quantity:      o.Quantity,
+		ExecutedQty:   o.ExecutedQty,
+		AvgPrice:      o.AvgPrice,
+		Status:        OrderStatus(o.Status),
+		CreatedAt:     o.CreatedAt,
+		UpdateTime:    o.UpdateTime,
+	}
+}
+
+// toMockRequest 转换下单请求
+func toMockRequest(req *OrderRequest) *mock.OrderRequest {
+	return &mock.OrderRequest{
+		Symbol:        req.Symbol,
+		Side:          mock.Side(req.Side),
+		Type:          mock.OrderType(req.Type),
+		TimeInForce:   mock.TimeInForce(req.TimeInForce),
+		Quantity:      req.Quantity,
+		Price:         req.Price,
+		ReduceOnly:    req.ReduceOnly,
+		PostOnly:      req.PostOnly,
+		PriceDecimals: req.PriceDecimals,
+		ClientOrderID: req.ClientOrderID,
+	}
+}
+
+// fromMockCandle 转换K线
+func fromMockCandle(c *mock.Candle) *Candle {
+	return &Candle{
+		Symbol:    c.Symbol,
+		Open:      c.Open,
+		High:      c.High,
+		Low:       c.Low,
+		Close:     c.Close,
+		Volume:    c.Volume,
+		Timestamp: c.Timestamp,
+		IsClosed:  c.IsClosed,
+	}
+}
+
+func (w *mockWrapper) GetName() string {
+	return w.adapter.GetName()
+}
+
+func (w *mockWrapper) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	mockOrder, err := w.adapter.PlaceOrder(ctx, toMockRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return fromMockOrder(mockOrder), nil
+}
+
+func (w *mockWrapper) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	mockOrders := make([]*mock.OrderRequest, len(orders))
+	for i, req := range orders {
+		mockOrders[i] = toMockRequest(req)
+	}
+
+	mockResult, hasMarginError := w.adapter.BatchPlaceOrders(ctx, mockOrders)
+
+	result := make([]*Order, len(mockResult))
+	for i, ord := range mockResult {
+		result[i] = fromMockOrder(ord)
+	}
+	return result, hasMarginError
+}
+
+func (w *mockWrapper) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	return w.adapter.CancelOrder(ctx, symbol, orderID)
+}
+
+func (w *mockWrapper) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	return w.adapter.BatchCancelOrders(ctx, symbol, orderIDs)
+}
+
+// CancelAllOrders 撤销所有订单（模拟交易所实现）
+// 查询所有未完成订单后批量撤销
+func (w *mockWrapper) CancelAllOrders(ctx context.Context, symbol string) error {
+	openOrders, err := w.adapter.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return err
+	}
+
+	if len(openOrders) == 0 {
+		return nil
+	}
+
+	orderIDs := make([]int64, len(openOrders))
+	for i, order := range openOrders {
+		orderIDs[i] = order.OrderID
+	}
+	return w.adapter.BatchCancelOrders(ctx, symbol, orderIDs)
+}
+
+func (w *mockWrapper) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	mockOrder, err := w.adapter.GetOrder(ctx, symbol, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return fromMockOrder(mockOrder), nil
+}
+
+func (w *mockWrapper) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	mockOrders, err := w.adapter.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*Order, len(mockOrders))
+	for i, ord := range mockOrders {
+		orders[i] = fromMockOrder(ord)
+	}
+	return orders, nil
+}
+
+func (w *mockWrapper) GetAccount(ctx context.Context) (*Account, error) {
+	mockAccount, err := w.adapter.GetAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]*Position, len(mockAccount.Positions))
+	for i, pos := range mockAccount.Positions {
+		positions[i] = &Position{
+			Symbol:         pos.Symbol,
+			Size:           pos.Size,
+			EntryPrice:     pos.EntryPrice,
+			MarkPrice:      pos.MarkPrice,
+			UnrealizedPNL:  pos.UnrealizedPNL,
+			Leverage:       pos.Leverage,
+			MarginType:     pos.MarginType,
+			IsolatedMargin: pos.IsolatedMargin,
+		}
+	}
+
+	return &Account{
+		TotalWalletBalance: mockAccount.TotalWalletBalance,
+		TotalMarginBalance: mockAccount.TotalMarginBalance,
+		AvailableBalance:   mockAccount.AvailableBalance,
+		Positions:          positions,
+		AccountLeverage:    mockAccount.AccountLeverage,
+	}, nil
+}
+
+func (w *mockWrapper) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	mockPositions, err := w.adapter.GetPositions(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]*Position, len(mockPositions))
+	for i, pos := range mockPositions {
+		positions[i] = &Position{
+			Symbol:         pos.Symbol,
+			Size:           pos.Size,
+			EntryPrice:     pos.EntryPrice,
+			MarkPrice:      pos.MarkPrice,
+			UnrealizedPNL:  pos.UnrealizedPNL,
+			Leverage:       pos.Leverage,
+			MarginType:     pos.MarginType,
+			IsolatedMargin: pos.IsolatedMargin,
+		}
+	}
+	return positions, nil
+}
+
+func (w *mockWrapper) GetBalance(ctx context.Context, asset string) (float64, error) {
+	return w.adapter.GetBalance(ctx, asset)
+}
+
+// GetMinActionInterval 获取最小操作间隔（实现 IActionIntervalProvider）
+func (w *mockWrapper) GetMinActionInterval() time.Duration {
+	return w.adapter.GetMinActionInterval()
+}
+
+func (w *mockWrapper) GetUserTrades(ctx context.Context, symbol string, since time.Time) ([]*Trade, error) {
+	mockTrades, err := w.adapter.GetUserTrades(ctx, symbol, since)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]*Trade, len(mockTrades))
+	for i, t := range mockTrades {
+		trades[i] = &Trade{
+			TradeID:     t.TradeID,
+			OrderID:     t.OrderID,
+			Symbol:      t.Symbol,
+			Side:        Side(t.Side),
+			Price:       t.Price,
+			Quantity:    t.Quantity,
+			RealizedPnL: t.RealizedPnL,
+			Fee:         t.Fee,
+			FeeAsset:    t.FeeAsset,
+			IsMaker:     t.IsMaker,
+			Time:        t.Time,
+		}
+	}
+
+	return trades, nil
+}
+
+func (w *mockWrapper) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	return w.adapter.StartOrderStream(ctx, callback)
+}
+
+func (w *mockWrapper) StopOrderStream() error {
+	return w.adapter.StopOrderStream()
+}
+
+func (w *mockWrapper) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	return w.adapter.GetLatestPrice(ctx, symbol)
+}
+
+func (w *mockWrapper) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return w.adapter.StartPriceStream(ctx, symbol, callback)
+}
+
+func (w *mockWrapper) StartKlineStream(ctx context.Context, symbols []string, interval string, callback CandleUpdateCallback) error {
+	return w.adapter.StartKlineStream(ctx, symbols, interval, func(candle interface{}) {
+		if c, ok := candle.(*mock.Candle); ok {
+			callback(fromMockCandle(c))
+		}
+	})
+}
+
+func (w *mockWrapper) StopKlineStream() error {
+	return w.adapter.StopKlineStream()
+}
+
+func (w *mockWrapper) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	mockCandles, err := w.adapter.GetHistoricalKlines(ctx, symbol, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	candles := make([]*Candle, len(mockCandles))
+	for i, mc := range mockCandles {
+		candles[i] = fromMockCandle(mc)
+	}
+	return candles, nil
+}
+
+func (w *mockWrapper) GetPriceDecimals() int {
+	return w.adapter.GetPriceDecimals()
+}
+
+func (w *mockWrapper) GetQuantityDecimals() int {
+	return w.adapter.GetQuantityDecimals()
+}
+
+func (w *mockWrapper) GetBaseAsset() string {
+	return w.adapter.GetBaseAsset()
+}
+
+func (w *mockWrapper) GetQuoteAsset() string {
+	return w.adapter.GetQuoteAsset()
+}
+
+func (w *mockWrapper) GetMinNotional() float64 {
+	return w.adapter.GetMinNotional()
+}
+
+// MockServer 返回进程内模拟交易所服务（非模拟交易所或使用外部服务时返回 nil）
+// 用于端到端测试中驱动价格、注入故障
+func MockServer(ex IExchange) *mock.Server {
+	if w, ok := findCapability[*mockWrapper](ex); ok {
+		return w.adapter.Server()
+	}
+	return nil
+}
+
+// GetTradingFees 获取当前手续费率（实现 IFeeRateProvider）
+func (w *mockWrapper) GetTradingFees(ctx context.Context, symbol string) (float64, float64, error) {
+	return w.adapter.GetTradingFees(ctx, symbol)
+}
+
+// GetFundingRate 获取当前资金费率（实现 IFundingRateProvider）
+func (w *mockWrapper) GetFundingRate(ctx context.Context, symbol string) (float64, error) {
+	return w.adapter.GetFundingRate(ctx, symbol)
+}
+
+// GetOrderBookTop 获取盘口一档（实现 IDepthProvider）
+func (w *mockWrapper) GetOrderBookTop(ctx context.Context, symbol string) (*OrderBookTop, error) {
+	bidPrice, bidQty, askPrice, askQty, err := w.adapter.GetOrderBookTop(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderBookTop{BidPrice: bidPrice, BidQty: bidQty, AskPrice: askPrice, AskQty: askQty}, nil
+}
+
+// PlaceTrailingStop 下追踪止损单（实现 ITrailingStopPlacer）
+func (w *mockWrapper) PlaceTrailingStop(ctx context.Context, req *TrailingStopRequest) (*Order, error) {
+	order, err := w.adapter.PlaceTrailingStop(ctx, req.Symbol, mock.Side(req.Side), req.Quantity, req.CallbackRate, req.ActivationPrice)
+	if err != nil {
+		return nil, err
+	}
+	return &Order{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Side:          Side(order.Side),
+		Type:          OrderType(order.Type),
+		Price:         order.Price,
+		Quantity:      order.Quantity,
+		Status:        OrderStatus(order.Status),
+		CreatedAt:     order.CreatedAt,
+		UpdateTime:    order.UpdateTime,
+	}, nil
+}
+
+// ExecutedQtyIsIncremental 订单推送的成交数量是否为增量（实现 IExecutedQtyProvider）
+func (w *mockWrapper) ExecutedQtyIsIncremental() bool {
+	return w.adapter.ExecutedQtyIsIncremental()
+}
+
+// Capabilities 模拟服务撮合只做 Maker、只减仓（拒绝无持仓的只减仓卖单）、市价/IOC 和追踪止损（实现 ICapabilityProvider）
+func (w *mockWrapper) Capabilities() Capabilities {
+	return Capabilities{PostOnly: true, ReduceOnly: true, IOC: true, NativeStop: true}
+}
+
+// RefreshSymbolInfo 重新获取合约信息（实现 ISymbolInfoRefresher）
+func (w *mockWrapper) RefreshSymbolInfo(ctx context.Context) error {
+	return w.adapter.RefreshSymbolInfo(ctx)
+}
+
+// ActiveWSEndpoint 当前使用的 WebSocket 地址
+func (w *mockWrapper) ActiveWSEndpoint() string {
+	return w.adapter.ActiveWSEndpoint()
+}
@@ -0,0 +1,149 @@
+package exchange
+
+import (
+	"context"
+	"time"
+)
+
+// CallObserver REST 调用结果观察者
+// method 为接口方法名，err 为调用返回的错误（成功时为 nil）
+type CallObserver func(method string, err error)
+
+// observedWrapper 包装任意交易所实例，在每次 REST 调用后通知观察者
+// 用于交易所健康监测等横切逻辑，不改变被包装交易所的行为
+type observedWrapper struct {
+	inner    IExchange
+	observer CallObserver
+}
+
+// WithCallObserver 为交易所实例附加 REST 调用观察者
+func WithCallObserver(ex IExchange, observer CallObserver) IExchange {
+	if observer == nil {
+		return ex
+	}
+	return &observedWrapper{inner: ex, observer: observer}
+}
+
+// Unwrap 返回被包装的原始交易所实例
+func (w *observedWrapper) Unwrap() IExchange {
+	return w.inner
+}
+
+func (w *observedWrapper) GetName() string {
+	return w.inner.GetName()
+}
+
+func (w *observedWrapper) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	order, err := w.inner.PlaceOrder(ctx, req)
+	w.observer("PlaceOrder", err)
+	return order, err
+}
+
+func (w *observedWrapper) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	// 批量下单不返回错误，失败细节由适配器自行记录
+	return w.inner.BatchPlaceOrders(ctx, orders)
+}
+
+func (w *observedWrapper) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	err := w.inner.CancelOrder(ctx, symbol, orderID)
+	w.observer("CancelOrder", err)
+	return err
+}
+
+func (w *observedWrapper) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	err := w.inner.BatchCancelOrders(ctx, symbol, orderIDs)
+	w.observer("BatchCancelOrders", err)
+	return err
+}
+
+func (w *observedWrapper) CancelAllOrders(ctx context.Context, symbol string) error {
+	err := w.inner.CancelAllOrders(ctx, symbol)
+	w.observer("CancelAllOrders", err)
+	return err
+}
+
+func (w *observedWrapper) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	order, err := w.inner.GetOrder(ctx, symbol, orderID)
+	w.observer("GetOrder", err)
+	return order, err
+}
+
+func (w *observedWrapper) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	orders, err := w.inner.GetOpenOrders(ctx, symbol)
+	w.observer("GetOpenOrders", err)
+	return orders, err
+}
+
+func (w *observedWrapper) GetAccount(ctx context.Context) (*Account, error) {
+	account, err := w.inner.GetAccount(ctx)
+	w.observer("GetAccount", err)
+	return account, err
+}
+
+func (w *observedWrapper) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	positions, err := w.inner.GetPositions(ctx, symbol)
+	w.observer("GetPositions", err)
+	return positions, err
+}
+
+func (w *observedWrapper) GetBalance(ctx context.Context, asset string) (float64, error) {
+	balance, err := w.inner.GetBalance(ctx, asset)
+	w.observer("GetBalance", err)
+	return balance, err
+}
+
+func (w *observedWrapper) GetUserTrades(ctx context.Context, symbol string, since time.Time) ([]*Trade, error) {
+	trades, err := w.inner.GetUserTrades(ctx, symbol, since)
+	w.observer("GetUserTrades", err)
+	return trades, err
+}
+
+func (w *observedWrapper) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	return w.inner.StartOrderStream(ctx, callback)
+}
+
+func (w *observedWrapper) StopOrderStream() error {
+	return w.inner.StopOrderStream()
+}
+
+func (w *observedWrapper) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	return w.inner.GetLatestPrice(ctx, symbol)
+}
+
+func (w *observedWrapper) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return w.inner.StartPriceStream(ctx, symbol, callback)
+}
+
+func (w *observedWrapper) StartKlineStream(ctx context.Context, symbols []string, interval string, callback CandleUpdateCallback) error {
+	return w.inner.StartKlineStream(ctx, symbols, interval, callback)
+}
+
+func (w *observedWrapper) StopKlineStream() error {
+	return w.inner.StopKlineStream()
+}
+
+func (w *observedWrapper) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	candles, err := w.inner.GetHistoricalKlines(ctx, symbol, interval, limit)
+	w.observer("GetHistoricalKlines", err)
+	return candles, err
+}
+
+func (w *observedWrapper) GetPriceDecimals() int {
+	return w.inner.GetPriceDecimals()
+}
+
+func (w *observedWrapper) GetQuantityDecimals() int {
+	return w.inner.GetQuantityDecimals()
+}
+
+func (w *observedWrapper) GetBaseAsset() string {
+	return w.inner.GetBaseAsset()
+}
+
+func (w *observedWrapper) GetQuoteAsset() string {
+	return w.inner.GetQuoteAsset()
+}
+
+func (w *observedWrapper) GetMinNotional() float64 {
+	return w.inner.GetMinNotional()
+}
@@ -0,0 +1,351 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	"opensqt/exchange/okx"
+)
+
+// okxWrapper 包装 OKX 适配器以实现 IExchange 接口
+type okxWrapper struct {
+	adapter *okx.OKXAdapter
+}
+
+func (w *okxWrapper) GetName() string {
+	return w.adapter.GetName()
+}
+
+func (w *okxWrapper) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	// 转换请求类型
+	okxReq := &okx.OrderRequest{
+		Symbol:        req.Symbol,
+		Side:          okx.Side(req.Side),
+		Type:          okx.OrderType(req.Type),
+		TimeInForce:   okx.TimeInForce(req.TimeInForce),
+		Quantity:      req.Quantity,
+		Price:         req.Price,
+		ReduceOnly:    req.ReduceOnly,
+		PostOnly:      req.PostOnly,
+		PriceDecimals: req.PriceDecimals,
+		ClientOrderID: req.ClientOrderID,
+	}
+
+	okxOrder, err := w.adapter.PlaceOrder(ctx, okxReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// 转换返回类型
+	return &Order{
+		OrderID:       okxOrder.OrderID,
+		ClientOrderID: okxOrder.ClientOrderID,
+		Symbol:        okxOrder.Symbol,
+		Side:          Side(okxOrder.Side),
+		Type:          OrderType(okxOrder.Type),
+		Price:         okxOrder.Price,
+		Quantity:      okxOrder.Quantity,
+		ExecutedQty:   okxOrder.ExecutedQty,
+		AvgPrice:      okxOrder.AvgPrice,
+		Status:        OrderStatus(okxOrder.Status),
+		CreatedAt:     okxOrder.CreatedAt,
+		UpdateTime:    okxOrder.UpdateTime,
+	}, nil
+}
+
+func (w *okxWrapper) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	okxOrders := make([]*okx.OrderRequest, len(orders))
+	for i, req := range orders {
+		okxOrders[i] = &okx.OrderRequest{
+			Symbol:        req.Symbol,
+			Side:          okx.Side(req.Side),
+			Type:          okx.OrderType(req.Type),
+			TimeInForce:   okx.TimeInForce(req.TimeInForce),
+			Quantity:      req.Quantity,
+			Price:         req.Price,
+			ReduceOnly:    req.ReduceOnly,
+			PostOnly:      req.PostOnly,
+			PriceDecimals: req.PriceDecimals,
+			ClientOrderID: req.ClientOrderID,
+		}
+	}
+
+	okxResult, hasMarginError := w.adapter.BatchPlaceOrders(ctx, okxOrders)
+
+	result := make([]*Order, len(okxResult))
+	for i, ord := range okxResult {
+		result[i] = &Order{
+			OrderID:       ord.OrderID,
+			ClientOrderID: ord.ClientOrderID,
+			Symbol:        ord.Symbol,
+			Side:          Side(ord.Side),
+			Type:          OrderType(ord.Type),
+			Price:         ord.Price,
+			Quantity:      ord.Quantity,
+			ExecutedQty:   ord.ExecutedQty,
+			AvgPrice:      ord.AvgPrice,
+			Status:        OrderStatus(ord.Status),
+			CreatedAt:     ord.CreatedAt,
+			UpdateTime:    ord.UpdateTime,
+		}
+	}
+
+	return result, hasMarginError
+}
+
+func (w *okxWrapper) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	return w.adapter.CancelOrder(ctx, symbol, orderID)
+}
+
+func (w *okxWrapper) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	return w.adapter.BatchCancelOrders(ctx, symbol, orderIDs)
+}
+
+// CancelAllOrders 撤销所有订单（OKX 无一键全撤接口，适配器查询挂单后批量撤销）
+func (w *okxWrapper) CancelAllOrders(ctx context.Context, symbol string) error {
+	return w.adapter.CancelAllOrders(ctx, symbol)
+}
+
+func (w *okxWrapper) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	okxOrder, err := w.adapter.GetOrder(ctx, symbol, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Order{
+		OrderID:       okxOrder.OrderID,
+		ClientOrderID: okxOrder.ClientOrderID,
+		Symbol:        okxOrder.Symbol,
+		Side:          Side(okxOrder.Side),
+		Type:          OrderType(okxOrder.Type),
+		Price:         okxOrder.Price,
+		Quantity:      okxOrder.Quantity,
+		ExecutedQty:   okxOrder.ExecutedQty,
+		AvgPrice:      okxOrder.AvgPrice,
+		Status:        OrderStatus(okxOrder.Status),
+		CreatedAt:     okxOrder.CreatedAt,
+		UpdateTime:    okxOrder.UpdateTime,
+	}, nil
+}
+
+func (w *okxWrapper) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	okxOrders, err := w.adapter.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*Order, len(okxOrders))
+	for i, ord := range okxOrders {
+		orders[i] = &Order{
+			OrderID:       ord.OrderID,
+			ClientOrderID: ord.ClientOrderID,
+			Symbol:        ord.Symbol,
+			Side:          Side(ord.Side),
+			Type:          OrderType(ord.Type),
+			Price:         ord.Price,
+			Quantity:      ord.Quantity,
+			ExecutedQty:   ord.ExecutedQty,
+			AvgPrice:      ord.AvgPrice,
+			Status:        OrderStatus(ord.Status),
+			CreatedAt:     ord.CreatedAt,
+			UpdateTime:    ord.UpdateTime,
+		}
+	}
+
+	return orders, nil
+}
+
+func (w *okxWrapper) GetAccount(ctx context.Context) (*Account, error) {
+	okxAccount, err := w.adapter.GetAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]*Position, len(okxAccount.Positions))
+	for i, pos := range okxAccount.Positions {
+		positions[i] = &Position{
+			Symbol:         pos.Symbol,
+			Size:           pos.Size,
+			EntryPrice:     pos.EntryPrice,
+			MarkPrice:      pos.MarkPrice,
+			UnrealizedPNL:  pos.UnrealizedPNL,
+			Leverage:       pos.Leverage,
+			MarginType:     pos.MarginType,
+			IsolatedMargin: pos.IsolatedMargin,
+		}
+	}
+
+	return &Account{
+		TotalWalletBalance: okxAccount.TotalWalletBalance,
+		TotalMarginBalance: okxAccount.TotalMarginBalance,
+		AvailableBalance:   okxAccount.AvailableBalance,
+		Positions:          positions,
+		AccountLeverage:    okxAccount.AccountLeverage,
+	}, nil
+}
+
+func (w *okxWrapper) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	okxPositions, err := w.adapter.GetPositions(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]*Position, len(okxPositions))
+	for i, pos := range okxPositions {
+		positions[i] = &Position{
+			Symbol:         pos.Symbol,
+			Size:           pos.Size,
+			EntryPrice:     pos.EntryPrice,
+			MarkPrice:      pos.MarkPrice,
+			UnrealizedPNL:  pos.UnrealizedPNL,
+			Leverage:       pos.Leverage,
+			MarginType:     pos.MarginType,
+			IsolatedMargin: pos.IsolatedMargin,
+		}
+	}
+
+	return positions, nil
+}
+
+func (w *okxWrapper) GetBalance(ctx context.Context, asset string) (float64, error) {
+	return w.adapter.GetBalance(ctx, asset)
+}
+
+func (w *okxWrapper) GetUserTrades(ctx context.Context, symbol string, since time.Time) ([]*Trade, error) {
+	okxTrades, err := w.adapter.GetUserTrades(ctx, symbol, since)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]*Trade, len(okxTrades))
+	for i, t := range okxTrades {
+		trades[i] = &Trade{
+			TradeID:     t.TradeID,
+			OrderID:     t.OrderID,
+			Symbol:      t.Symbol,
+			Side:        Side(t.Side),
+			Price:       t.Price,
+			Quantity:    t.Quantity,
+			RealizedPnL: t.RealizedPnL,
+			Fee:         t.Fee,
+			FeeAsset:    t.FeeAsset,
+			IsMaker:     t.IsMaker,
+			Time:        t.Time,
+		}
+	}
+
+	return trades, nil
+}
+
+func (w *okxWrapper) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	return w.adapter.StartOrderStream(ctx, callback)
+}
+
+// StartMultiSymbolOrderStream 在一条私有频道连接上订阅多个交易对的订单更新（各交易对按自己的合约面值换算数量）
+func (w *okxWrapper) StartMultiSymbolOrderStream(ctx context.Context, symbols []string, callback func(interface{})) error {
+	return w.adapter.StartMultiSymbolOrderStream(ctx, symbols, callback)
+}
+
+func (w *okxWrapper) StopOrderStream() error {
+	return w.adapter.StopOrderStream()
+}
+
+func (w *okxWrapper) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	return w.adapter.GetLatestPrice(ctx, symbol)
+}
+
+func (w *okxWrapper) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return w.adapter.StartPriceStream(ctx, symbol, callback)
+}
+
+func (w *okxWrapper) StartKlineStream(ctx context.Context, symbols []string, interval string, callback CandleUpdateCallback) error {
+	return w.adapter.StartKlineStream(ctx, symbols, interval, func(candle interface{}) {
+		if c, ok := candle.(*okx.Candle); ok {
+			callback(&Candle{
+				Symbol:    c.Symbol,
+				Open:      c.Open,
+				High:      c.High,
+				Low:       c.Low,
+				Close:     c.Close,
+				Volume:    c.Volume,
+				Timestamp: c.Timestamp,
+				IsClosed:  c.IsClosed,
+			})
+		}
+	})
+}
+
+func (w *okxWrapper) StopKlineStream() error {
+	return w.adapter.StopKlineStream()
+}
+
+func (w *okxWrapper) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	candles, err := w.adapter.GetHistoricalKlines(ctx, symbol, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Candle, len(candles))
+	for i, c := range candles {
+		result[i] = &Candle{
+			Symbol:    c.Symbol,
+			Open:      c.Open,
+			High:      c.High,
+			Low:       c.Low,
+			Close:     c.Close,
+			Volume:    c.Volume,
+			Timestamp: c.Timestamp,
+			IsClosed:  c.IsClosed,
+		}
+	}
+	return result, nil
+}
+
+func (w *okxWrapper) GetPriceDecimals() int {
+	return w.adapter.GetPriceDecimals()
+}
+
+func (w *okxWrapper) GetQuantityDecimals() int {
+	return w.adapter.GetQuantityDecimals()
+}
+
+func (w *okxWrapper) GetBaseAsset() string {
+	return w.adapter.GetBaseAsset()
+}
+
+func (w *okxWrapper) GetQuoteAsset() string {
+	return w.adapter.GetQuoteAsset()
+}
+
+func (w *okxWrapper) GetMinNotional() float64 {
+	return w.adapter.GetMinNotional()
+}
+
+// GetSymbolLeverage 查询交易对杠杆倍数（实现 ILeverageProvider）
+func (w *okxWrapper) GetSymbolLeverage(symbol string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return w.adapter.GetSymbolLeverage(ctx, symbol)
+}
+
+// ExecutedQtyIsIncremental 订单推送的成交数量是否为增量（实现 IExecutedQtyProvider）
+func (w *okxWrapper) ExecutedQtyIsIncremental() bool {
+	return w.adapter.ExecutedQtyIsIncremental()
+}
+
+// Capabilities 实现 ICapabilityProvider
+// 只做 Maker 传 ordType=post_only；只减仓单向持仓传 reduceOnly=true，双向持仓用 side + posSide 平仓；
+// 市价单传 ordType=market，IOC 传 ordType=ioc。未接入策略委托，不支持原生追踪止损
+func (w *okxWrapper) Capabilities() Capabilities {
+	return Capabilities{PostOnly: true, ReduceOnly: true, IOC: true}
+}
+
+// RefreshSymbolInfo 重新获取合约信息（实现 ISymbolInfoRefresher）
+func (w *okxWrapper) RefreshSymbolInfo(ctx context.Context) error {
+	return w.adapter.RefreshSymbolInfo(ctx)
+}
+
+// ActiveWSEndpoint 当前使用的 WebSocket 地址
+func (w *okxWrapper) ActiveWSEndpoint() string {
+	return w.adapter.ActiveWSEndpoint()
+}
@@ -0,0 +1,56 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+)
+
+// readOnlyWrapper 账户/持仓/挂单查询走只读密钥实例，其余调用（下单、撤单、订单查询、WebSocket）走交易密钥实例
+// 监控类组件（止盈、对账、风控）只做读取，只读密钥泄露时无法下单或提现
+type readOnlyWrapper struct {
+	IExchange           // 交易密钥实例
+	readOnly  IExchange // 只读密钥实例
+}
+
+func withReadOnlyQueries(trade, readOnly IExchange) IExchange {
+	return &readOnlyWrapper{IExchange: trade, readOnly: readOnly}
+}
+
+// Unwrap 返回交易密钥实例（可选能力按交易密钥实例查找）
+func (w *readOnlyWrapper) Unwrap() IExchange {
+	return w.IExchange
+}
+
+func (w *readOnlyWrapper) GetAccount(ctx context.Context) (*Account, error) {
+	return w.readOnly.GetAccount(ctx)
+}
+
+func (w *readOnlyWrapper) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	return w.readOnly.GetPositions(ctx, symbol)
+}
+
+func (w *readOnlyWrapper) GetBalance(ctx context.Context, asset string) (float64, error) {
+	return w.readOnly.GetBalance(ctx, asset)
+}
+
+func (w *readOnlyWrapper) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	return w.readOnly.GetOpenOrders(ctx, symbol)
+}
+
+// ValidateCredentials 启动时分别用交易密钥和只读密钥查询账户，确认两组密钥都可用
+// 未配置只读密钥时只校验交易密钥
+func ValidateCredentials(ctx context.Context, ex IExchange) error {
+	if w, ok := findCapability[*readOnlyWrapper](ex); ok {
+		if _, err := w.IExchange.GetAccount(ctx); err != nil {
+			return fmt.Errorf("交易密钥校验失败: %w", err)
+		}
+		if _, err := w.readOnly.GetAccount(ctx); err != nil {
+			return fmt.Errorf("只读密钥校验失败: %w", err)
+		}
+		return nil
+	}
+	if _, err := ex.GetAccount(ctx); err != nil {
+		return fmt.Errorf("交易密钥校验失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package logger
+
+import "strings"
+
+// ComponentLogger 组件子日志器
+// 输出格式与全局日志一致，但级别优先使用 system.log_levels 中的组件覆盖
+type ComponentLogger struct {
+	component string
+}
+
+// WithComponent 创建组件子日志器（组件名不区分大小写）
+func WithComponent(component string) *ComponentLogger {
+	name := strings.ToLower(strings.TrimSpace(component))
+	mu.Lock()
+	knownComponents[name] = true
+	mu.Unlock()
+	return &ComponentLogger{component: name}
+}
+
+// Component 返回组件名
+func (l *ComponentLogger) Component() string {
+	return l.component
+}
+
+// Debug 输出调试日志
+func (l *ComponentLogger) Debug(format string, args ...interface{}) {
+	componentLogf(l.component, DEBUG, format, args...)
+}
+
+// Debugln 输出调试日志（无格式）
+func (l *ComponentLogger) Debugln(args ...interface{}) {
+	componentLogln(l.component, DEBUG, args...)
+}
+
+// Info 输出一般信息日志
+func (l *ComponentLogger) Info(format string, args ...interface{}) {
+	componentLogf(l.component, INFO, format, args...)
+}
+
+// Infoln 输出一般信息日志（无格式）
+func (l *ComponentLogger) Infoln(args ...interface{}) {
+	componentLogln(l.component, INFO, args...)
+}
+
+// Warn 输出警告日志
+func (l *ComponentLogger) Warn(format string, args ...interface{}) {
+	componentLogf(l.component, WARN, format, args...)
+}
+
+// Warnln 输出警告日志（无格式）
+func (l *ComponentLogger) Warnln(args ...interface{}) {
+	componentLogln(l.component, WARN, args...)
+}
+
+// Error 输出错误日志
+func (l *ComponentLogger) Error(format string, args ...interface{}) {
+	componentLogf(l.component, ERROR, format, args...)
+}
+
+// Errorln 输出错误日志（无格式）
+func (l *ComponentLogger) Errorln(args ...interface{}) {
+	componentLogln(l.component, ERROR, args...)
+}
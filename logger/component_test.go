@@ -0,0 +1,22 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetComponentLevelsIgnoresUnknownComponents(t *testing.T) {
+	WithComponent("reconciler")
+	defer SetComponentLevels(nil)
+
+	unknown := SetComponentLevels(map[string]LogLevel{"Reconciler": DEBUG, "reconciller": ERROR})
+	if !reflect.DeepEqual(unknown, []string{"reconciller"}) {
+		t.Fatalf("拼写错误的组件名应被报告，实际 %v", unknown)
+	}
+	if got := GetComponentLevel("reconciler"); got != DEBUG {
+		t.Fatalf("已知组件的级别覆盖应生效（不区分大小写），实际 %v", got)
+	}
+	if got := GetComponentLevel("reconciller"); got != GetLevel() {
+		t.Fatalf("未知组件不应写入级别覆盖，实际 %v", got)
+	}
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 日志输出格式（system.log_format）
+const (
+	FormatText = "text" // 时间 [LEVEL] 消息（默认）
+	FormatJSON = "json" // 每行一个 JSON 对象：{"ts":...,"level":"INFO","msg":"..."}，便于日志采集系统解析
+)
+
+// IEncoder 日志编码器：将一条日志编码为一行文本（不含末尾换行），控制台和文件日志共用同一个编码器
+type IEncoder interface {
+	Encode(t time.Time, level LogLevel, message string) string
+}
+
+// TextEncoder 文本编码器：2006/01/02 15:04:05 [INFO] 消息
+type TextEncoder struct{}
+
+// Encode 编码一行文本日志
+func (TextEncoder) Encode(t time.Time, level LogLevel, message string) string {
+	return fmt.Sprintf("%s [%s] %s", t.Format("2006/01/02 15:04:05"), level.String(), message)
+}
+
+// JSONEncoder JSON 编码器：{"ts":"2006-01-02T15:04:05.000+08:00","level":"INFO","msg":"消息"}（消息中的 emoji 和 < > & 原样保留）
+type JSONEncoder struct{}
+
+// Encode 编码一行 JSON 日志
+func (JSONEncoder) Encode(t time.Time, level LogLevel, message string) string {
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(struct {
+		TS    string `json:"ts"`
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}{
+		TS:    t.Format("2006-01-02T15:04:05.000Z07:00"),
+		Level: level.String(),
+		Msg:   message,
+	})
+	if err != nil {
+		return fmt.Sprintf(`{"level":%q,"msg":%q}`, level.String(), message)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// encoderBox atomic.Value 要求每次存入相同的具体类型，包一层结构体
+type encoderBox struct {
+	enc IEncoder
+}
+
+// currentEncoder 当前编码器（不使用 mu 保护：SetLevel 持有 mu 时初始化文件日志也需要输出提示）
+var currentEncoder atomic.Value // encoderBox
+
+// SetFormat 按名称选择内置编码器（text / json，无法识别时使用 text）
+// 需在 SetLevel 之前调用，文件日志启用提示才会按所选格式输出
+func SetFormat(format string) {
+	if strings.ToLower(strings.TrimSpace(format)) == FormatJSON {
+		SetEncoder(JSONEncoder{})
+		return
+	}
+	SetEncoder(TextEncoder{})
+}
+
+// SetEncoder 设置自定义编码器（nil 表示恢复为文本编码器）
+func SetEncoder(enc IEncoder) {
+	if enc == nil {
+		enc = TextEncoder{}
+	}
+	currentEncoder.Store(encoderBox{enc: enc})
+}
+
+// encode 使用当前编码器编码一行日志
+func encode(level LogLevel, message string) string {
+	if box, ok := currentEncoder.Load().(encoderBox); ok {
+		return box.enc.Encode(time.Now(), level, message)
+	}
+	return TextEncoder{}.Encode(time.Now(), level, message)
+}
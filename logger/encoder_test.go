@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// jsonLine JSON 编码器输出的一行
+type jsonLine struct {
+	TS    string `json:"ts"`
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+}
+
+// awkwardMessages 容易破坏 JSON 行格式的消息
+var awkwardMessages = []string{
+	"普通消息",
+	`带 "双引号" 和 '单引号' 的消息`,
+	"多行消息\n第二行\r\n第三行",
+	"制表符\t和反斜杠 \\ 以及 \\n 字面量",
+	"🚀 emoji 开头 ✅ 中间 🛑",
+	"HTML 字符 <a href=\"x\">&amp;</a>",
+	"控制字符 \x00\x01\x1b[31m",
+	`{"看起来像":"JSON"}`,
+	"",
+}
+
+func TestJSONEncoderLinesAreValidJSON(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 123e6, time.FixedZone("CST", 8*3600))
+	for _, msg := range awkwardMessages {
+		line := JSONEncoder{}.Encode(ts, WARN, msg)
+		if strings.ContainsAny(line, "\r\n") {
+			t.Fatalf("编码结果应为单行，消息 %q 得到 %q", msg, line)
+		}
+		var got jsonLine
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("消息 %q 编码结果不是合法 JSON: %v\n%s", msg, err, line)
+		}
+		if got.Msg != msg || got.Level != "WARN" || got.TS != "2024-05-06T07:08:09.123+08:00" {
+			t.Fatalf("解析结果不符，消息 %q 得到 %+v", msg, got)
+		}
+	}
+	// emoji 和 < > & 原样保留，不转义为 \u 序列
+	if line := (JSONEncoder{}).Encode(ts, INFO, "🚀 <ok> & done"); !strings.Contains(line, `"msg":"🚀 <ok> & done"`) {
+		t.Fatalf("emoji 和 HTML 字符应原样保留: %s", line)
+	}
+}
+
+func TestJSONEncoderInvalidUTF8(t *testing.T) {
+	line := JSONEncoder{}.Encode(time.Now(), ERROR, "截断的字符 \xe4\xb8")
+	if !json.Valid([]byte(line)) {
+		t.Fatalf("非法 UTF-8 的消息编码结果也应为合法 JSON: %q", line)
+	}
+}
+
+func TestJSONFormatConsoleOutput(t *testing.T) {
+	var buf bytes.Buffer
+	oldLogger := consoleLogger
+	consoleLogger = log.New(&buf, "", 0)
+	SetFormat(FormatJSON)
+	defer func() {
+		consoleLogger = oldLogger
+		SetFormat(FormatText)
+	}()
+
+	for _, msg := range awkwardMessages {
+		Warn("%s", msg)
+	}
+	Infoln("✅ 多个参数", `"引号"`, 42)
+
+	scanner := bufio.NewScanner(&buf)
+	lines := 0
+	for scanner.Scan() {
+		var got jsonLine
+		if err := json.Unmarshal(scanner.Bytes(), &got); err != nil {
+			t.Fatalf("第 %d 行不是合法 JSON: %v\n%s", lines+1, err, scanner.Text())
+		}
+		if lines < len(awkwardMessages) && got.Msg != awkwardMessages[lines] {
+			t.Fatalf("第 %d 行消息应为 %q，实际 %q", lines+1, awkwardMessages[lines], got.Msg)
+		}
+		lines++
+	}
+	if lines != len(awkwardMessages)+1 {
+		t.Fatalf("每条日志应恰好输出一行，期望 %d 行，实际 %d 行", len(awkwardMessages)+1, lines)
+	}
+}
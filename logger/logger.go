@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -25,12 +26,29 @@ var (
 	globalLevel LogLevel = INFO
 	mu          sync.RWMutex
 
+	// 组件级别覆盖（未配置的组件使用全局级别）
+	componentLevels = make(map[string]LogLevel)
+	// 已通过 WithComponent 创建的组件（校验 system.log_levels 中的组件名）
+	knownComponents = make(map[string]bool)
+
 	// 文件日志相关
 	fileLogger  *log.Logger
 	logFile     *os.File
 	currentDate string
 	fileMu      sync.Mutex
 	logDir      = "log" // 日志文件夹
+	retainDays  int     // 日志保留天数（0 表示不清理）
+
+	// 文件日志单独配置（SetFileLogging 调用后生效，未调用时 DEBUG 级别写入与控制台相同的日志）
+	fileConfigured     bool
+	fileLoggingEnabled bool
+	fileLevel          LogLevel
+
+	// 致命错误退出前的回调（如导出崩溃现场），在输出日志之后、退出之前调用
+	fatalHook func(message string)
+
+	// 控制台输出（标准错误，时间戳由编码器生成）
+	consoleLogger = log.New(os.Stderr, "", 0)
 )
 
 // String 返回日志级别的字符串表示
@@ -71,10 +89,14 @@ func ParseLogLevel(level string) LogLevel {
 }
 
 // SetLevel 设置全局日志级别
+// 未通过 SetFileLogging 单独配置文件日志时，DEBUG 级别同时写入文件（与控制台输出相同的日志）
 func SetLevel(level LogLevel) {
 	mu.Lock()
 	defer mu.Unlock()
 	globalLevel = level
+	if fileConfigured {
+		return
+	}
 
 	// 如果设置为DEBUG级别，启用文件日志
 	if level == DEBUG {
@@ -84,6 +106,47 @@ func SetLevel(level LogLevel) {
 	}
 }
 
+// SetFileLogging 单独配置文件日志（system.log_to_file），与控制台日志级别无关
+// enabled 为 true 时级别不低于 level 的日志都写入 log/opensqt-日期.log；调用后 SetLevel 不再开关文件日志
+func SetFileLogging(enabled bool, level LogLevel) {
+	mu.Lock()
+	defer mu.Unlock()
+	fileConfigured = true
+	fileLoggingEnabled = enabled
+	fileLevel = level
+
+	if enabled {
+		initFileLogger()
+	} else {
+		closeFileLogger()
+	}
+}
+
+// EnableFileLogging 开关文件日志（与日志级别无关，INFO/WARN/ERROR 等日志同样写入文件）
+// 文件日志级别沿用 SetFileLogging 的设置，未设置过时与当前全局级别相同
+func EnableFileLogging(enabled bool) {
+	mu.RLock()
+	level := globalLevel
+	if fileConfigured {
+		level = fileLevel
+	}
+	mu.RUnlock()
+	SetFileLogging(enabled, level)
+}
+
+// consoleLog 日志器内部提示直接输出到控制台（不经过级别判断）
+func consoleLog(level LogLevel, message string) {
+	consoleLogger.Print(encode(level, message))
+}
+
+// SetRetentionDays 设置日志文件保留天数（0 表示永久保留）
+// 每次创建新的日志文件时删除超出保留期的旧文件，需在 SetLevel 之前调用才能覆盖启动时的首次清理
+func SetRetentionDays(days int) {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	retainDays = days
+}
+
 // initFileLogger 初始化文件日志（当日志级别为DEBUG时）
 func initFileLogger() {
 	fileMu.Lock()
@@ -104,7 +167,7 @@ func initFileLogger() {
 	// 创建log文件夹
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		// 如果创建失败，只输出到控制台
-		log.Printf("[WARN] 创建日志文件夹失败: %v，将只输出到控制台", err)
+		consoleLog(WARN, fmt.Sprintf("创建日志文件夹失败: %v，将只输出到控制台", err))
 		return
 	}
 
@@ -113,7 +176,7 @@ func initFileLogger() {
 	file, err := os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		// 如果打开失败，只输出到控制台
-		log.Printf("[WARN] 打开日志文件失败: %v，将只输出到控制台", err)
+		consoleLog(WARN, fmt.Sprintf("打开日志文件失败: %v，将只输出到控制台", err))
 		return
 	}
 
@@ -122,7 +185,8 @@ func initFileLogger() {
 	// 创建文件日志器（不包含时间戳，因为标准log已经包含）
 	fileLogger = log.New(file, "", 0)
 
-	log.Printf("[INFO] 文件日志已启用，日志文件: %s", logFileName)
+	consoleLog(INFO, fmt.Sprintf("文件日志已启用，日志文件: %s", logFileName))
+	cleanupOldLogs()
 }
 
 // closeFileLogger 关闭文件日志
@@ -165,6 +229,58 @@ func checkAndRotateLog() {
 		logFile = file
 		currentDate = today
 		fileLogger = log.New(file, "", 0)
+		cleanupOldLogs()
+	}
+}
+
+// cleanupOldLogs 删除超出保留期的日志文件（按文件名中的日期判断，无法解析时按修改时间）
+// 注意：调用此函数前必须已持有fileMu锁，因此这里只能直接输出，不能调用 Info 等函数
+func cleanupOldLogs() {
+	if retainDays <= 0 {
+		return
+	}
+
+	files, err := filepath.Glob(filepath.Join(logDir, "opensqt-*.log"))
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	cutoff := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, -retainDays)
+	currentFile := fmt.Sprintf("opensqt-%s.log", currentDate)
+
+	removed := 0
+	for _, file := range files {
+		name := filepath.Base(file)
+		if name == currentFile {
+			continue
+		}
+
+		fileDate, err := time.ParseInLocation("2006-01-02", strings.TrimSuffix(strings.TrimPrefix(name, "opensqt-"), ".log"), time.Local)
+		if err != nil {
+			info, statErr := os.Stat(file)
+			if statErr != nil {
+				continue
+			}
+			fileDate = info.ModTime()
+		}
+		if !fileDate.Before(cutoff) {
+			continue
+		}
+
+		if err := os.Remove(file); err != nil {
+			consoleLog(WARN, fmt.Sprintf("删除过期日志文件失败: %s: %v", file, err))
+			continue
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		message := fmt.Sprintf("🧹 已删除 %d 个超过 %d 天的日志文件", removed, retainDays)
+		consoleLog(INFO, message)
+		if fileLogger != nil {
+			fileLogger.Print(encode(INFO, message))
+		}
 	}
 }
 
@@ -173,6 +289,16 @@ func Close() {
 	closeFileLogger()
 }
 
+// GetFileLevel 获取文件日志级别（未单独配置文件日志时与全局级别相同）
+func GetFileLevel() LogLevel {
+	mu.RLock()
+	defer mu.RUnlock()
+	if fileConfigured {
+		return fileLevel
+	}
+	return globalLevel
+}
+
 // GetLevel 获取全局日志级别
 func GetLevel() LogLevel {
 	mu.RLock()
@@ -180,56 +306,114 @@ func GetLevel() LogLevel {
 	return globalLevel
 }
 
+// SetComponentLevels 设置组件级别覆盖（如 reconciler: DEBUG）
+// 组件名不区分大小写，传入空表则清除所有覆盖；不存在的组件名（通常是拼写错误）输出警告并忽略，返回这些组件名
+func SetComponentLevels(levels map[string]LogLevel) (unknown []string) {
+	mu.Lock()
+	componentLevels = make(map[string]LogLevel, len(levels))
+	for name, level := range levels {
+		component := strings.ToLower(strings.TrimSpace(name))
+		if !knownComponents[component] {
+			unknown = append(unknown, name)
+			continue
+		}
+		componentLevels[component] = level
+	}
+	known := make([]string, 0, len(knownComponents))
+	for component := range knownComponents {
+		known = append(known, component)
+	}
+	mu.Unlock()
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		sort.Strings(known)
+		Warn("⚠️ system.log_levels 中的组件 %s 不存在，已忽略（可用组件: %s）", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return unknown
+}
+
+// GetComponentLevel 获取组件的生效日志级别
+func GetComponentLevel(component string) LogLevel {
+	mu.RLock()
+	defer mu.RUnlock()
+	if level, ok := componentLevels[component]; ok {
+		return level
+	}
+	return globalLevel
+}
+
 // shouldLog 判断是否应该输出日志
 func shouldLog(level LogLevel) bool {
 	return level >= globalLevel
 }
 
-// logf 内部日志输出函数
-func logf(level LogLevel, format string, args ...interface{}) {
-	if !shouldLog(level) {
-		return
+// shouldLogComponent 判断组件日志是否应该输出（优先使用组件级别覆盖）
+func shouldLogComponent(component string, level LogLevel) bool {
+	if component == "" {
+		return shouldLog(level)
 	}
-	prefix := fmt.Sprintf("[%s] ", level.String())
-	message := fmt.Sprintf(prefix+format, args...)
+	return level >= GetComponentLevel(component)
+}
 
-	// 输出到控制台（标准输出）
-	log.Printf(prefix+format, args...)
+// logTargets 判断日志是否输出到控制台和文件
+func logTargets(component string, level LogLevel) (console, file bool) {
+	console = shouldLogComponent(component, level)
+	mu.RLock()
+	defer mu.RUnlock()
+	if fileConfigured {
+		return console, fileLoggingEnabled && level >= fileLevel
+	}
+	return console, console && globalLevel == DEBUG
+}
 
-	// 如果日志级别为DEBUG，同时写入文件
-	if globalLevel == DEBUG {
-		fileMu.Lock()
-		// 检查是否需要轮转日志文件
-		checkAndRotateLog()
-		if fileLogger != nil {
-			// 写入文件（包含时间戳）
-			fileLogger.Printf("%s %s", time.Now().Format("2006/01/02 15:04:05"), message)
-		}
-		fileMu.Unlock()
+// logf 内部日志输出函数
+func logf(level LogLevel, format string, args ...interface{}) {
+	componentLogf("", level, format, args...)
+}
+
+// componentLogf 内部日志输出函数（带组件级别判断）
+func componentLogf(component string, level LogLevel, format string, args ...interface{}) {
+	console, file := logTargets(component, level)
+	if !console && !file {
+		return
 	}
+	write(level, fmt.Sprintf(format, args...), console, file)
 }
 
 // logln 内部日志输出函数（无格式）
 func logln(level LogLevel, args ...interface{}) {
-	if !shouldLog(level) {
+	componentLogln("", level, args...)
+}
+
+// componentLogln 内部日志输出函数（无格式，带组件级别判断）
+func componentLogln(component string, level LogLevel, args ...interface{}) {
+	console, file := logTargets(component, level)
+	if !console && !file {
 		return
 	}
-	prefix := fmt.Sprintf("[%s] ", level.String())
-	message := fmt.Sprintln(append([]interface{}{prefix}, args...)...)
+	// 多个参数按 Println 规则以空格连接
+	write(level, strings.TrimSuffix(fmt.Sprintln(args...), "\n"), console, file)
+}
 
-	// 输出到控制台（标准输出）
-	log.Println(append([]interface{}{prefix}, args...)...)
+// write 使用当前编码器编码后输出到控制台和文件（文件日志启用且达到文件日志级别时）
+func write(level LogLevel, message string, console, file bool) {
+	line := encode(level, message)
+	if console {
+		consoleLogger.Print(line)
+	}
+	if file {
+		writeFile(line)
+	}
+}
 
-	// 如果日志级别为DEBUG，同时写入文件
-	if globalLevel == DEBUG {
-		fileMu.Lock()
-		// 检查是否需要轮转日志文件
-		checkAndRotateLog()
-		if fileLogger != nil {
-			// 写入文件（包含时间戳，去掉末尾的换行符，因为Println会自动添加）
-			fileLogger.Printf("%s %s", time.Now().Format("2006/01/02 15:04:05"), strings.TrimSuffix(message, "\n"))
-		}
-		fileMu.Unlock()
+// writeFile 写入一行文件日志（需要时先轮转日志文件）
+func writeFile(line string) {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	checkAndRotateLog()
+	if fileLogger != nil {
+		fileLogger.Print(line)
 	}
 }
 
@@ -273,15 +457,36 @@ func Errorln(args ...interface{}) {
 	logln(ERROR, args...)
 }
 
+// SetFatalHook 设置致命错误退出前的回调（nil 表示清除），任意协程调用 Fatal 时都会在退出前执行
+func SetFatalHook(hook func(message string)) {
+	mu.Lock()
+	defer mu.Unlock()
+	fatalHook = hook
+}
+
+// runFatalHook 执行致命错误回调（回调本身 panic 时不影响退出）
+func runFatalHook(message string) {
+	mu.RLock()
+	hook := fatalHook
+	mu.RUnlock()
+	if hook == nil {
+		return
+	}
+	defer func() { recover() }()
+	hook(message)
+}
+
 // Fatal 输出致命错误日志并退出程序
 func Fatal(format string, args ...interface{}) {
 	logf(FATAL, format, args...)
+	runFatalHook(fmt.Sprintf(format, args...))
 	os.Exit(1)
 }
 
 // Fatalln 输出致命错误日志并退出程序（无格式）
 func Fatalln(args ...interface{}) {
 	logln(FATAL, args...)
+	runFatalHook(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
 	os.Exit(1)
 }
 
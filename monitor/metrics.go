@@ -0,0 +1,137 @@
+package monitor
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"opensqt/logger"
+)
+
+// Labels 指标标签（如 {"symbol": "ETHUSDT"}）
+type Labels map[string]string
+
+// metricSeries 一条时间序列：格式化后的标签 + 抓取时的取值函数
+type metricSeries struct {
+	labels string
+	value  func() float64
+}
+
+// metricFamily 同名指标（HELP/TYPE 只输出一次）
+type metricFamily struct {
+	name   string
+	help   string
+	kind   string // gauge / counter
+	series []metricSeries
+}
+
+// metricsRegistry 进程内的指标注册表，抓取时按注册顺序输出
+var metricsRegistry = struct {
+	mu       sync.Mutex
+	families []*metricFamily
+}{}
+
+// RegisterGauge 注册当前值指标，每次抓取 /metrics 时调用 fn 取值（同名同标签重复注册时替换取值函数）
+func RegisterGauge(name, help string, labels Labels, fn func() float64) {
+	registerMetric(name, help, "gauge", labels, fn)
+}
+
+// RegisterCounter 注册累计值指标，fn 返回单调递增的累计值（进程重启后从0开始）
+func RegisterCounter(name, help string, labels Labels, fn func() float64) {
+	registerMetric(name, help, "counter", labels, fn)
+}
+
+func registerMetric(name, help, kind string, labels Labels, fn func() float64) {
+	metricsRegistry.mu.Lock()
+	defer metricsRegistry.mu.Unlock()
+
+	var family *metricFamily
+	for _, f := range metricsRegistry.families {
+		if f.name == name {
+			family = f
+			break
+		}
+	}
+	if family == nil {
+		family = &metricFamily{name: name, help: help, kind: kind}
+		metricsRegistry.families = append(metricsRegistry.families, family)
+	}
+
+	series := metricSeries{labels: formatLabels(labels), value: fn}
+	for i := range family.series {
+		if family.series[i].labels == series.labels {
+			family.series[i] = series
+			return
+		}
+	}
+	family.series = append(family.series, series)
+}
+
+// formatLabels 按标签名排序格式化为 {a="1",b="2"}（无标签时为空）
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf(`%s="%s"`, name, escaper.Replace(labels[name]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// writeMetrics 按 Prometheus 文本格式输出所有指标
+func writeMetrics(w http.ResponseWriter) {
+	metricsRegistry.mu.Lock()
+	families := make([]metricFamily, len(metricsRegistry.families))
+	for i, f := range metricsRegistry.families {
+		families[i] = *f
+		families[i].series = append([]metricSeries(nil), f.series...)
+	}
+	metricsRegistry.mu.Unlock()
+
+	var b strings.Builder
+	for _, f := range families {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+		for _, s := range f.series {
+			fmt.Fprintf(&b, "%s%s %s\n", f.name, s.labels, strconv.FormatFloat(s.value(), 'g', -1, 64))
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
+
+// StartMetricsServer 启动 Prometheus 指标接口（GET /metrics），addr 为空时不启动
+// 服务随进程退出，取值函数在抓取时调用，应只读取内存中的状态
+func StartMetricsServer(addr string) error {
+	if addr == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("指标接口监听失败: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		writeMetrics(w)
+	})
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			logger.Error("❌ [指标接口] 服务异常退出: %v", err)
+		}
+	}()
+
+	logger.Info("📈 [指标接口] 已启动: http://%s/metrics", listener.Addr())
+	return nil
+}
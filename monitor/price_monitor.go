@@ -6,6 +6,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"opensqt/event"
 	"opensqt/exchange"
 	"opensqt/logger"
 )
@@ -27,6 +28,11 @@ PriceMonitor 架构说明：
    - 依赖 exchange.IExchange 接口
    - 通过 exchange.StartPriceStream() 启动 WebSocket
    - WebSocket 是唯一的价格来源
+
+4. **锚定价格**：
+   - 默认以最新成交价作为挂单锚定价格（PriceChange.NewPrice）
+   - 可配置为盘口中间价或 microprice，由独立协程轮询深度计算
+   - 深度不可用或数据过期时回退到最新成交价，GetLastPrice() 始终返回成交价
 */
 
 // PriceChange 价格变化事件
@@ -37,6 +43,22 @@ type PriceChange struct {
 	Timestamp time.Time
 }
 
+// 锚定价格来源
+const (
+	AnchorLast       = "last"       // 最新成交价
+	AnchorMid        = "mid"        // 盘口中间价
+	AnchorMicroprice = "microprice" // 按挂单量加权的中间价
+)
+
+// depthStaleFactor 深度数据超过 轮询间隔 × 该倍数 未更新视为过期
+const depthStaleFactor = 3
+
+// depthAnchor 由盘口计算出的锚定价格
+type depthAnchor struct {
+	price float64
+	time  time.Time
+}
+
 // PriceMonitor 价格监控器
 type PriceMonitor struct {
 	symbol        string
@@ -53,6 +75,16 @@ type PriceMonitor struct {
 
 	// 时间配置
 	priceSendInterval time.Duration
+
+	// 锚定价格（mid/microprice 时由深度轮询协程更新）
+	anchorSource      string
+	depthPollInterval time.Duration
+	depthAnchor       atomic.Value // *depthAnchor
+	lastAnchor        atomic.Value // float64 - 上一次生成价格事件时使用的锚定价格
+
+	// 事件总线价格推送（限频，每秒最多一次）
+	lastPublishTime  time.Time
+	lastPublishPrice float64
 }
 
 // NewPriceMonitor 创建价格监控器
@@ -74,9 +106,20 @@ func NewPriceMonitor(ex exchange.IExchange, symbol string, priceSendInterval int
 	pm.lastPriceStr.Store("")
 	pm.lastPriceTime.Store(time.Time{})
 	pm.latestPriceChange.Store((*PriceChange)(nil))
+	pm.anchorSource = AnchorLast
+	pm.depthAnchor.Store((*depthAnchor)(nil))
+	pm.lastAnchor.Store(0.0)
 	return pm
 }
 
+// SetAnchorSource 设置挂单锚定价格来源（需在 Start 之前调用）
+// - source: last/mid/microprice
+// - depthPollInterval: 盘口深度轮询间隔（毫秒）
+func (pm *PriceMonitor) SetAnchorSource(source string, depthPollInterval int) {
+	pm.anchorSource = source
+	pm.depthPollInterval = time.Duration(depthPollInterval) * time.Millisecond
+}
+
 // Start 启动价格监控
 func (pm *PriceMonitor) Start() error {
 	if pm.isRunning.Load() {
@@ -99,6 +142,11 @@ func (pm *PriceMonitor) Start() error {
 	logger.Info("✅ 价格监控已启动 (WebSocket 推送)")
 	go pm.periodicPriceSender() // 启动定期发送协程
 
+	if pm.anchorSource != AnchorLast {
+		logger.Info("⚓ 挂单锚定价格: %s (盘口轮询间隔 %v)", pm.anchorSource, pm.depthPollInterval)
+		go pm.pollDepth()
+	}
+
 	return nil
 }
 
@@ -111,19 +159,20 @@ func (pm *PriceMonitor) updatePrice(newPrice float64) {
 		return
 	}
 
-	oldPrice := pm.GetLastPrice()
-
 	// 存储新价格
 	pm.lastPrice.Store(newPrice)
 	pm.lastPriceStr.Store(fmt.Sprintf("%f", newPrice)) // 简单转换，精度由后续逻辑处理
 	pm.lastPriceTime.Store(time.Now())
 
-	// 如果价格有变化，生成事件
-	if oldPrice > 0 && newPrice != oldPrice {
-		change := newPrice - oldPrice
+	// 如果锚定价格有变化，生成事件（last 模式下锚定价格即成交价）
+	oldAnchor := pm.lastAnchor.Load().(float64)
+	newAnchor := pm.GetAnchorPrice()
+	pm.lastAnchor.Store(newAnchor)
+	if oldAnchor > 0 && newAnchor != oldAnchor {
+		change := newAnchor - oldAnchor
 		event := &PriceChange{
-			OldPrice:  oldPrice,
-			NewPrice:  newPrice,
+			OldPrice:  oldAnchor,
+			NewPrice:  newAnchor,
 			Change:    change,
 			Timestamp: time.Now(),
 		}
@@ -131,6 +180,55 @@ func (pm *PriceMonitor) updatePrice(newPrice float64) {
 	}
 }
 
+// pollDepth 定期查询盘口，计算 mid/microprice 锚定价格
+// 交易所不支持深度查询时退出，始终使用最新成交价
+func (pm *PriceMonitor) pollDepth() {
+	ticker := time.NewTicker(pm.depthPollInterval)
+	defer ticker.Stop()
+
+	var lastErrLog time.Time
+	for {
+		select {
+		case <-pm.ctx.Done():
+			return
+		case <-ticker.C:
+			top, ok, err := exchange.GetOrderBookTop(pm.ctx, pm.exchange, pm.symbol)
+			if !ok {
+				logger.Warn("⚠️ 交易所 %s 不支持深度查询，锚定价格回退到最新成交价", pm.exchange.GetName())
+				return
+			}
+			if err != nil {
+				if pm.ctx.Err() == nil && time.Since(lastErrLog) > time.Minute {
+					logger.Warn("⚠️ 获取盘口深度失败，暂时使用最新成交价: %v", err)
+					lastErrLog = time.Now()
+				}
+				continue
+			}
+			if top.BidPrice <= 0 || top.AskPrice <= 0 || top.BidPrice >= top.AskPrice {
+				continue
+			}
+
+			price := top.Mid()
+			if pm.anchorSource == AnchorMicroprice {
+				price = top.Microprice()
+			}
+			pm.depthAnchor.Store(&depthAnchor{price: price, time: time.Now()})
+		}
+	}
+}
+
+// GetAnchorPrice 获取挂单锚定价格
+// mid/microprice 模式下深度数据不可用或已过期时返回最新成交价
+func (pm *PriceMonitor) GetAnchorPrice() float64 {
+	if pm.anchorSource != AnchorLast {
+		if anchor := pm.depthAnchor.Load().(*depthAnchor); anchor != nil &&
+			time.Since(anchor.time) <= pm.depthPollInterval*depthStaleFactor {
+			return anchor.price
+		}
+	}
+	return pm.GetLastPrice()
+}
+
 // periodicPriceSender 定期发送最新价格
 func (pm *PriceMonitor) periodicPriceSender() {
 	ticker := time.NewTicker(pm.priceSendInterval)
@@ -150,6 +248,7 @@ func (pm *PriceMonitor) periodicPriceSender() {
 					case pm.priceChangeCh <- *latestChange:
 						// 成功发送，清空latestPriceChange
 						pm.latestPriceChange.Store((*PriceChange)(nil))
+						pm.publishPrice(latestChange.NewPrice)
 					default:
 						// channel已满，保留最新价格等待下次机会
 					}
@@ -159,6 +258,21 @@ func (pm *PriceMonitor) periodicPriceSender() {
 	}
 }
 
+// publishPrice 向事件总线发布价格（限频，避免高频行情冲击订阅者）
+func (pm *PriceMonitor) publishPrice(price float64) {
+	if time.Since(pm.lastPublishTime) < time.Second {
+		return
+	}
+
+	change := 0.0
+	if pm.lastPublishPrice > 0 {
+		change = price - pm.lastPublishPrice
+	}
+	pm.lastPublishTime = time.Now()
+	pm.lastPublishPrice = price
+	event.Publish(event.TypePriceUpdate, event.PriceUpdate{Symbol: pm.symbol, Price: price, Change: change})
+}
+
 // Stop 停止价格监控
 func (pm *PriceMonitor) Stop() {
 	pm.cancel()
@@ -0,0 +1,82 @@
+package monitor
+
+import (
+	"math"
+	"sync"
+)
+
+// VolatilityEstimator 价格波动率估计
+// 保留最近 window 个价格样本（由调用方按固定间隔从价格流取样），按样本的总体标准差 × multiplier 换算目标价格间隔
+type VolatilityEstimator struct {
+	mu          sync.Mutex
+	window      int
+	multiplier  float64
+	minInterval float64
+	maxInterval float64
+	samples     []float64 // 由旧到新
+}
+
+// NewVolatilityEstimator 创建波动率估计器，目标间隔限制在 [minInterval, maxInterval] 内
+func NewVolatilityEstimator(window int, multiplier, minInterval, maxInterval float64) *VolatilityEstimator {
+	return &VolatilityEstimator{
+		window:      window,
+		multiplier:  multiplier,
+		minInterval: minInterval,
+		maxInterval: maxInterval,
+		samples:     make([]float64, 0, window),
+	}
+}
+
+// AddSample 记录一个价格样本（非正数忽略），超过 window 个时丢弃最旧的样本
+func (v *VolatilityEstimator) AddSample(price float64) {
+	if price <= 0 {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.samples = append(v.samples, price)
+	if len(v.samples) > v.window {
+		v.samples = v.samples[len(v.samples)-v.window:]
+	}
+}
+
+// Ready 样本是否已填满窗口
+func (v *VolatilityEstimator) Ready() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.samples) >= v.window
+}
+
+// StdDev 当前样本的总体标准差（无样本时为0）
+func (v *VolatilityEstimator) StdDev() float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return priceStdDev(v.samples)
+}
+
+// CurrentInterval 按当前波动率计算的价格间隔（标准差 × multiplier，限制在 [min, max] 内）
+// 样本未填满窗口时返回 base（base 同样限制在 [min, max] 内）
+func (v *VolatilityEstimator) CurrentInterval(base float64) float64 {
+	interval := base
+	if v.Ready() {
+		interval = v.StdDev() * v.multiplier
+	}
+	return math.Min(math.Max(interval, v.minInterval), v.maxInterval)
+}
+
+// priceStdDev 价格样本的总体标准差
+func priceStdDev(prices []float64) float64 {
+	if len(prices) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, p := range prices {
+		sum += p
+	}
+	mean := sum / float64(len(prices))
+	var variance float64
+	for _, p := range prices {
+		variance += (p - mean) * (p - mean)
+	}
+	return math.Sqrt(variance / float64(len(prices)))
+}
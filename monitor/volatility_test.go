@@ -0,0 +1,40 @@
+package monitor
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVolatilityEstimatorInterval(t *testing.T) {
+	tests := []struct {
+		name       string
+		samples    []float64
+		wantReady  bool
+		wantStdDev float64
+		want       float64 // CurrentInterval(1)
+	}{
+		{"样本不足窗口时返回基准间隔", []float64{100, 102, 100}, false, 0.9428, 1},
+		{"标准差 1 × 2", []float64{100, 102, 100, 102}, true, 1, 2},
+		{"超过窗口时只用最近的样本", []float64{50, 200, 100, 102, 100, 102}, true, 1, 2},
+		{"非正数价格被忽略", []float64{100, 0, 102, -1, 100, 102}, true, 1, 2},
+		{"波动过大时限制在上限", []float64{100, 104, 100, 104}, true, 2, 3},
+		{"价格不变时限制在下限", []float64{100, 100, 100, 100}, true, 0, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVolatilityEstimator(4, 2, 0.5, 3)
+			for _, p := range tt.samples {
+				v.AddSample(p)
+			}
+			if v.Ready() != tt.wantReady {
+				t.Fatalf("Ready 应为 %v", tt.wantReady)
+			}
+			if got := v.StdDev(); math.Abs(got-tt.wantStdDev) > 1e-4 {
+				t.Fatalf("标准差应为 %.4f，实际 %.4f", tt.wantStdDev, got)
+			}
+			if got := v.CurrentInterval(1); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("间隔应为 %.4f，实际 %.4f", tt.want, got)
+			}
+		})
+	}
+}
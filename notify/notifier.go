@@ -0,0 +1,131 @@
+// Package notify 告警通知（风控、止盈止损、下单失败、程序退出等需要人工关注的事件）
+// 各推送渠道实现 INotifier，MultiNotifier 同时推送到多个渠道；交易路径上通过 Send 异步推送
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"opensqt/logger"
+)
+
+// EventType 告警类型
+type EventType string
+
+const (
+	EventRiskTriggered EventType = "risk_triggered"  // 主动风控触发，暂停交易
+	EventRiskRecovered EventType = "risk_recovered"  // 主动风控解除，恢复交易
+	EventTakeProfitHit EventType = "take_profit_hit" // 自动止盈触发，程序退出
+	EventStopLossHit   EventType = "stop_loss_hit"   // 自动止损触发，程序退出
+	EventOrderFailed   EventType = "order_failed"    // 下单失败（含保证金不足）
+	EventShutdown      EventType = "shutdown"        // 收到退出信号，开始优雅关闭
+)
+
+// Event 告警事件
+type Event struct {
+	Type    EventType              `json:"type"`
+	Time    time.Time              `json:"time"`
+	Symbol  string                 `json:"symbol,omitempty"`
+	Message string                 `json:"message"`          // 可直接展示的告警内容
+	Fields  map[string]interface{} `json:"fields,omitempty"` // 结构化字段（余额、盈利、订单价格等）
+}
+
+// Text 告警的文本形式：消息 + 按字段名排序的 "字段: 值" 行
+func (e Event) Text() string {
+	var b strings.Builder
+	if e.Symbol != "" {
+		fmt.Fprintf(&b, "[%s] ", e.Symbol)
+	}
+	b.WriteString(e.Message)
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\n%s: %v", k, e.Fields[k])
+	}
+	return b.String()
+}
+
+// INotifier 告警推送渠道
+// Notify 同步推送（遵守 ctx 的超时和取消），交易路径上应通过 Send 调用
+type INotifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// MultiNotifier 同时推送到多个渠道，全部完成后返回合并的错误
+type MultiNotifier struct {
+	notifiers []INotifier
+}
+
+// NewMultiNotifier 创建多渠道推送（忽略 nil）
+func NewMultiNotifier(notifiers ...INotifier) *MultiNotifier {
+	m := &MultiNotifier{}
+	for _, n := range notifiers {
+		if n != nil {
+			m.notifiers = append(m.notifiers, n)
+		}
+	}
+	return m
+}
+
+// Notify 并发推送到所有渠道
+func (m *MultiNotifier) Notify(ctx context.Context, e Event) error {
+	errs := make([]error, len(m.notifiers))
+	var wg sync.WaitGroup
+	for i, n := range m.notifiers {
+		wg.Add(1)
+		go func(i int, n INotifier) {
+			defer wg.Done()
+			errs[i] = n.Notify(ctx, e)
+		}(i, n)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// sendTimeout 单条告警的推送超时
+const sendTimeout = 10 * time.Second
+
+// pending 发送中的告警（Wait 等待）
+var pending sync.WaitGroup
+
+// Send 在独立协程中推送告警（立即返回，超时 10 秒，失败只记录告警日志），n 为 nil 时不做任何事
+// 推送渠道缓慢或不可用时不会阻塞交易路径
+func Send(n INotifier, e Event) {
+	if n == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	pending.Add(1)
+	go func() {
+		defer pending.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+		if err := n.Notify(ctx, e); err != nil {
+			logger.Warn("⚠️ [告警通知] 推送 %s 失败: %v", e.Type, err)
+		}
+	}()
+}
+
+// Wait 等待发送中的告警完成（最多等待 timeout），退出前调用以免最后的告警丢失
+func Wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		logger.Warn("⚠️ [告警通知] 等待告警推送超时 (%v)", timeout)
+	}
+}
@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// telegramAPI Telegram Bot API 地址
+const telegramAPI = "https://api.telegram.org"
+
+// TelegramNotifier Telegram 机器人推送（notify 中 type: telegram）
+type TelegramNotifier struct {
+	botToken string
+	chatID   string
+}
+
+// NewTelegramNotifier 创建 Telegram 推送
+func NewTelegramNotifier(botToken, chatID string) *TelegramNotifier {
+	return &TelegramNotifier{
+		botToken: botToken,
+		chatID:   chatID,
+	}
+}
+
+// Notify 调用 sendMessage 接口推送告警文本
+func (t *TelegramNotifier) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(map[string]string{
+		"chat_id": t.chatID,
+		"text":    e.Text(),
+	})
+	if err != nil {
+		return err
+	}
+	// 错误信息中不含 URL（URL 含机器人令牌）
+	return postJSON(ctx, fmt.Sprintf("%s/bot%s/sendMessage", telegramAPI, t.botToken), nil, body)
+}
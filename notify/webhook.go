@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// httpClient 推送使用的 HTTP 客户端（超时由调用方的 ctx 控制）
+var httpClient = &http.Client{}
+
+// WebhookNotifier POST JSON 到自定义地址（notify 中 type: webhook）
+// 请求体为 Event 的 JSON，另附 text（Slack、Mattermost）和 content（Discord）两个文本字段，
+// 因此可以直接填写这些平台的 incoming webhook 地址
+type WebhookNotifier struct {
+	url     string
+	headers map[string]string
+}
+
+// NewWebhookNotifier 创建 webhook 推送，headers 为附加请求头（可为 nil）
+func NewWebhookNotifier(url string, headers map[string]string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:     url,
+		headers: headers,
+	}
+}
+
+// Notify 推送告警
+func (w *WebhookNotifier) Notify(ctx context.Context, e Event) error {
+	text := e.Text()
+	body, err := json.Marshal(struct {
+		Event
+		Text    string `json:"text"`
+		Content string `json:"content"`
+	}{Event: e, Text: text, Content: text})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, w.url, w.headers, body)
+}
+
+// postJSON POST JSON 请求，非 2xx 响应返回错误（错误信息不含 URL，避免泄露其中的令牌）
+func postJSON(ctx context.Context, endpoint string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("无效的推送地址")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+	return nil
+}
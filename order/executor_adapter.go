@@ -2,15 +2,24 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"opensqt/event"
 	"opensqt/exchange"
 	"opensqt/logger"
+	"opensqt/notify"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	"golang.org/x/time/rate"
 )
 
+// orderLog 订单执行组件日志器（级别可通过 system.log_levels.order 单独调整）
+var orderLog = logger.WithComponent("order")
+
+// ErrDraining 执行器已进入退出排空阶段，不再接受新的下单请求
+var ErrDraining = errors.New("执行器正在退出，不再接受新的下单请求")
+
 // OrderRequest 订单请求
 type OrderRequest struct {
 	Symbol        string
@@ -20,7 +29,9 @@ type OrderRequest struct {
 	PriceDecimals int    // 价格小数位数（用于格式化价格字符串）
 	ReduceOnly    bool   // 是否只减仓（平仓单）
 	PostOnly      bool   // 是否只做 Maker（Post Only）
+	Market        bool   // 市价单（IOC，忽略 Price 和 PostOnly）
 	ClientOrderID string // 自定义订单ID
+	Reason        string // 下单原因（event.OrderReason*，写入订单审计记录）
 }
 
 // Order 订单信息
@@ -39,24 +50,238 @@ type Order struct {
 type ExchangeOrderExecutor struct {
 	exchange    exchange.IExchange
 	symbol      string
-	rateLimiter *rate.Limiter
+	rateLimiter *RateLimiter // 可与其他交易对的执行器共享
 
 	// 时间配置
 	rateLimitRetryDelay time.Duration
 	orderRetryDelay     time.Duration
+
+	// 操作节奏：同一交易对相邻下单/撤单的最小间隔（交易所有操作冷却要求时生效）
+	actionMu          sync.Mutex
+	minActionInterval time.Duration
+	nextAction        time.Time // 下一次下单/撤单最早的开始时间（已预约的请求之后）
+
+	// 下单确认：对这些方向的限价单下单后查询确认（trading.placement_confirm）
+	confirmSides       map[string]bool
+	confirmDelay       time.Duration
+	confirmMaxReplaces int
+
+	// 等待下单确认的订单（ClientOrderID -> 收到订单流推送时关闭），订单流先确认的不再 REST 查询
+	ackMu      sync.Mutex
+	ackWaiters map[string]chan struct{}
+	// 后台下单确认的结果推送（重新下单后订单ID变化推送 NEW，确认失败推送 REJECTED），nil 时只输出日志
+	confirmHandler func(exchange.OrderUpdate)
+
+	// 退出排空：Drain 之后拒绝新的下单请求，并等待进行中的下单返回
+	drainMu  sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+
+	// 模拟交易（system.dry_run）：下单撤单只记录在模拟成交引擎中，不调用交易所（nil 表示真实下单）
+	simulator *SimulatedFillEngine
+
+	// 分批撤单每批的订单数（CancelAllViaBatch 使用，交易所不支持一键撤销全部订单时的兜底）
+	cancelBatchSize int
+
+	// 下单统计（PlaceOrder 的最终结果，BatchPlaceOrders 逐笔计入；需要确认的订单确认后才计入成功）
+	placedOrders atomic.Int64
+	failedOrders atomic.Int64
+
+	// 下单失败告警（nil 表示不推送），每 orderFailedAlertInterval 最多一条
+	notifier        notify.INotifier
+	lastFailedAlert atomic.Int64 // 上次告警时间（UnixNano）
 }
 
+// orderFailedAlertInterval 下单失败告警的最小间隔（保证金不足时每一层下单都会失败，避免刷屏）
+const orderFailedAlertInterval = time.Minute
+
+// defaultCancelBatchSize 未设置分批撤单大小时每批撤销的订单数（币安批量撤单接口一次最多10个）
+const defaultCancelBatchSize = 10
+
+// 交易所返回操作冷却拒绝时自动放大操作间隔（未配置间隔时从 defaultCooldownInterval 开始，每次翻倍）
+const (
+	defaultCooldownInterval = 200 * time.Millisecond
+	maxCooldownInterval     = 2 * time.Second
+)
+
 // NewExchangeOrderExecutor 创建基于交易所接口的订单执行器
-func NewExchangeOrderExecutor(ex exchange.IExchange, symbol string, rateLimitRetryDelay, orderRetryDelay int) *ExchangeOrderExecutor {
+func NewExchangeOrderExecutor(ex exchange.IExchange, symbol string, rateLimiter *RateLimiter, rateLimitRetryDelay, orderRetryDelay int) *ExchangeOrderExecutor {
 	return &ExchangeOrderExecutor{
 		exchange:            ex,
 		symbol:              symbol,
-		rateLimiter:         rate.NewLimiter(rate.Limit(25), 30), // 25单/秒，突发30
+		rateLimiter:         rateLimiter,
 		rateLimitRetryDelay: time.Duration(rateLimitRetryDelay) * time.Second,
 		orderRetryDelay:     time.Duration(orderRetryDelay) * time.Millisecond,
 	}
 }
 
+// RateLimitLevels 各限流桶当前可用的令牌数
+func (oe *ExchangeOrderExecutor) RateLimitLevels() map[string]float64 {
+	return oe.rateLimiter.Levels()
+}
+
+// SetMinActionInterval 设置同一交易对相邻下单/撤单的最小间隔（0 表示不限制）
+func (oe *ExchangeOrderExecutor) SetMinActionInterval(interval time.Duration) {
+	oe.actionMu.Lock()
+	oe.minActionInterval = interval
+	oe.actionMu.Unlock()
+
+	if interval > 0 {
+		orderLog.Info("⏱️ [%s] 操作节奏控制已启用: 同一交易对相邻下单/撤单间隔不少于 %v", oe.exchange.GetName(), interval)
+	}
+}
+
+// SetCancelBatchSize 设置 CancelAllViaBatch 每批撤销的订单数（<=0 时使用 defaultCancelBatchSize）
+func (oe *ExchangeOrderExecutor) SetCancelBatchSize(size int) {
+	oe.cancelBatchSize = size
+}
+
+// SetSimulator 启用模拟交易（需在下单之前调用）：下单、撤单和挂单查询都由模拟成交引擎处理
+func (oe *ExchangeOrderExecutor) SetSimulator(simulator *SimulatedFillEngine) {
+	oe.simulator = simulator
+	if simulator != nil {
+		orderLog.Warn("🧪 [%s] 模拟交易已启用: 下单和撤单不会发送到交易所", oe.exchange.GetName())
+	}
+}
+
+// IsDryRun 是否为模拟交易（下单撤单不发送到交易所）
+func (oe *ExchangeOrderExecutor) IsDryRun() bool {
+	return oe.simulator != nil
+}
+
+// SetNotifier 设置下单失败的告警推送（需在下单之前调用）
+func (oe *ExchangeOrderExecutor) SetNotifier(n notify.INotifier) {
+	oe.notifier = n
+}
+
+// SetPlacementConfirm 设置需要下单确认的方向（空表示不确认）
+// 这些方向的限价单下单成功后等待 delay，期间未收到订单流推送时查询订单，查不到时最多重新下单 maxReplaces 次
+func (oe *ExchangeOrderExecutor) SetPlacementConfirm(sides []string, delay time.Duration, maxReplaces int) {
+	oe.ackWaiters = make(map[string]chan struct{})
+	oe.confirmSides = make(map[string]bool, len(sides))
+	for _, side := range sides {
+		oe.confirmSides[side] = true
+	}
+	oe.confirmDelay = delay
+	oe.confirmMaxReplaces = maxReplaces
+
+	if len(sides) > 0 {
+		orderLog.Info("🔎 [%s] 下单确认已启用: %v 方向下单后 %v 内未收到订单流推送时查询确认，查不到时最多重新下单 %d 次",
+			oe.exchange.GetName(), sides, delay, maxReplaces)
+	}
+}
+
+// SetConfirmHandler 设置后台下单确认结果的推送函数（与订单流推送交给同一处理函数，需在下单之前调用）
+func (oe *ExchangeOrderExecutor) SetConfirmHandler(handler func(exchange.OrderUpdate)) {
+	oe.confirmHandler = handler
+}
+
+// NotifyOrderUpdate 订单流收到订单推送（任意状态都说明订单已在交易所存在），结束该订单的下单确认等待
+func (oe *ExchangeOrderExecutor) NotifyOrderUpdate(clientOrderID string) {
+	oe.ackMu.Lock()
+	defer oe.ackMu.Unlock()
+	if ack, ok := oe.ackWaiters[clientOrderID]; ok {
+		close(ack)
+		delete(oe.ackWaiters, clientOrderID)
+	}
+}
+
+// waitAck 注册订单流确认等待（需在下单前注册，避免推送先于下单返回到达），返回等待通道和注销函数
+func (oe *ExchangeOrderExecutor) waitAck(clientOrderID string) (<-chan struct{}, func()) {
+	ack := make(chan struct{})
+	oe.ackMu.Lock()
+	oe.ackWaiters[clientOrderID] = ack
+	oe.ackMu.Unlock()
+	return ack, func() {
+		oe.ackMu.Lock()
+		if oe.ackWaiters[clientOrderID] == ack {
+			delete(oe.ackWaiters, clientOrderID)
+		}
+		oe.ackMu.Unlock()
+	}
+}
+
+// beginPlacement 登记一次进行中的下单，排空阶段返回 false
+func (oe *ExchangeOrderExecutor) beginPlacement() bool {
+	oe.drainMu.Lock()
+	defer oe.drainMu.Unlock()
+	if oe.draining {
+		return false
+	}
+	oe.inflight.Add(1)
+	return true
+}
+
+// Drain 停止接受新的下单请求，并等待进行中的下单（含下单确认和重新下单）返回
+// 退出时在撤销全部订单之前调用，避免撤单之后才返回的下单在交易所留下挂单；ctx 到期时返回 ctx.Err()
+func (oe *ExchangeOrderExecutor) Drain(ctx context.Context) error {
+	oe.drainMu.Lock()
+	oe.draining = true
+	oe.drainMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		oe.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// beginAction 按最小间隔预约本次下单/撤单的开始时间并等待到该时间，返回的函数需在请求返回后调用
+// 只在读取和更新预约时间时持锁，请求本身不串行（慢请求不会阻塞之后的请求）：相邻请求的开始时间至少间隔 minActionInterval，
+// 请求返回后之后的预约从返回时间算起，避免网络抖动导致请求在交易所侧挤在一起
+func (oe *ExchangeOrderExecutor) beginAction(action string) func() {
+	oe.actionMu.Lock()
+	if oe.minActionInterval <= 0 {
+		oe.actionMu.Unlock()
+		return func() {}
+	}
+	start := time.Now()
+	if oe.nextAction.After(start) {
+		start = oe.nextAction
+	}
+	oe.nextAction = start.Add(oe.minActionInterval)
+	oe.actionMu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		orderLog.Debug("⏱️ [%s] 操作节奏控制: %s 等待 %v", oe.exchange.GetName(), action, wait.Round(time.Millisecond))
+		time.Sleep(wait)
+	}
+	return func() {
+		oe.actionMu.Lock()
+		if next := time.Now().Add(oe.minActionInterval); next.After(oe.nextAction) {
+			oe.nextAction = next
+		}
+		oe.actionMu.Unlock()
+	}
+}
+
+// onCooldownRejected 交易所以操作冷却拒绝请求时放大操作间隔
+func (oe *ExchangeOrderExecutor) onCooldownRejected(action string, err error) {
+	oe.actionMu.Lock()
+	old := oe.minActionInterval
+	interval := old * 2
+	if interval < defaultCooldownInterval {
+		interval = defaultCooldownInterval
+	}
+	if interval > maxCooldownInterval {
+		interval = maxCooldownInterval
+	}
+	oe.minActionInterval = interval
+	oe.actionMu.Unlock()
+
+	if interval != old {
+		orderLog.Warn("⚠️ [%s] %s 被拒（操作过于频繁）: %v，操作间隔调整为 %v", oe.exchange.GetName(), action, err, interval)
+	} else {
+		orderLog.Warn("⚠️ [%s] %s 被拒（操作过于频繁）: %v，等待后重试", oe.exchange.GetName(), action, err)
+	}
+}
+
 // isPostOnlyError 检查是否为PostOnly错误
 func isPostOnlyError(err error) bool {
 	if err == nil {
@@ -71,10 +296,194 @@ func isPostOnlyError(err error) bool {
 		strings.Contains(errStr, "ORDER_POC_IMMEDIATE")
 }
 
-// PlaceOrder 下单（带重试）
+// PlaceOrder 下单（带重试），配置了下单确认的方向下单后在后台确认（见 confirmInBackground）
+// 结果（含原因）以 event.TypeOrderAction 发布；Drain 之后返回 ErrDraining
 func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
+	if !oe.beginPlacement() {
+		return nil, ErrDraining
+	}
+	defer oe.inflight.Done()
+
+	var order *Order
+	var err error
+	confirming := false
+	if oe.simulator != nil {
+		order = oe.simulator.Place(req)
+		orderLog.Info("🧪 [模拟下单] %s %.*f 数量: %.4f 订单ID: %d", req.Side, req.PriceDecimals, req.Price, req.Quantity, order.OrderID)
+	} else {
+		confirm := !req.Market && oe.confirmSides[req.Side] && req.ClientOrderID != ""
+		var ack <-chan struct{}
+		unregister := func() {}
+		if confirm {
+			ack, unregister = oe.waitAck(req.ClientOrderID)
+		}
+
+		order, err = oe.placeOrder(req)
+		if err == nil && confirm {
+			// 确认在后台进行，计入进行中的下单（Drain 等待确认和重新下单结束）
+			confirming = true
+			oe.inflight.Add(1)
+			go oe.confirmInBackground(req, order, ack, unregister)
+		} else {
+			unregister()
+		}
+	}
+
+	action := event.OrderAction{
+		Symbol:        req.Symbol,
+		Action:        "place",
+		Side:          req.Side,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		ClientOrderID: req.ClientOrderID,
+		Reason:        req.Reason,
+	}
+	if err != nil {
+		oe.failedOrders.Add(1)
+		action.Error = err.Error()
+		oe.notifyOrderFailed(req, err)
+	} else {
+		if !confirming {
+			oe.placedOrders.Add(1)
+		}
+		action.OrderID = order.OrderID
+	}
+	event.Publish(event.TypeOrderAction, action)
+	return order, err
+}
+
+// notifyOrderFailed 推送下单失败告警（距上次告警不足 orderFailedAlertInterval 时跳过）
+func (oe *ExchangeOrderExecutor) notifyOrderFailed(req *OrderRequest, err error) {
+	if oe.notifier == nil {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := oe.lastFailedAlert.Load()
+	if now-last < int64(orderFailedAlertInterval) || !oe.lastFailedAlert.CompareAndSwap(last, now) {
+		return
+	}
+	message := "⚠️ 下单失败"
+	if isMarginError(err) {
+		message = "⚠️ 保证金不足，下单失败"
+	}
+	notify.Send(oe.notifier, notify.Event{
+		Type:    notify.EventOrderFailed,
+		Symbol:  req.Symbol,
+		Message: message,
+		Fields: map[string]interface{}{
+			"side":     req.Side,
+			"price":    req.Price,
+			"quantity": req.Quantity,
+			"reason":   req.Reason,
+			"error":    err.Error(),
+		},
+	})
+}
+
+// OrderStats 累计下单成功和失败的笔数（含批量下单，Drain 之后被拒绝的请求不计入）
+func (oe *ExchangeOrderExecutor) OrderStats() (placed, failed int64) {
+	return oe.placedOrders.Load(), oe.failedOrders.Load()
+}
+
+// confirmInBackground 后台确认下单：调用方（仓位管理器）持有 spm.mu 批量下单，确认等待不能在调用期间进行，
+// 否则等待期间订单推送无法处理。下单返回后槽位按返回的订单记录，确认后重新下单使订单ID变化时推送 NEW，
+// 确认失败时推送 REJECTED 释放槽位（推送不带 UpdateTime，不影响之后真实推送的乱序判断）
+// 下单统计按确认结果计入成功或失败（opensqt_orders_placed_total 只增不减）
+func (oe *ExchangeOrderExecutor) confirmInBackground(req *OrderRequest, order *Order, ack <-chan struct{}, unregister func()) {
+	defer oe.inflight.Done()
+	defer unregister()
+
+	confirmed, err := oe.confirmPlacement(req, order, ack)
+	if err != nil {
+		orderLog.Warn("⚠️ [%s] %s %.*f 订单 %d %v", oe.exchange.GetName(), req.Side, req.PriceDecimals, req.Price, order.OrderID, err)
+		oe.failedOrders.Add(1)
+		event.Publish(event.TypeOrderAction, event.OrderAction{
+			Symbol:        req.Symbol,
+			Action:        "place",
+			Side:          req.Side,
+			Price:         req.Price,
+			Quantity:      req.Quantity,
+			OrderID:       order.OrderID,
+			ClientOrderID: req.ClientOrderID,
+			Reason:        req.Reason,
+			Error:         err.Error(),
+		})
+		oe.notifyOrderFailed(req, err)
+		oe.deliverConfirmResult(req, order.OrderID, exchange.OrderStatusRejected)
+		return
+	}
+	oe.placedOrders.Add(1)
+	if confirmed.OrderID != order.OrderID {
+		oe.deliverConfirmResult(req, confirmed.OrderID, exchange.OrderStatusNew)
+	}
+}
+
+// deliverConfirmResult 推送下单确认结果
+func (oe *ExchangeOrderExecutor) deliverConfirmResult(req *OrderRequest, orderID int64, status exchange.OrderStatus) {
+	if oe.confirmHandler == nil {
+		return
+	}
+	oe.confirmHandler(exchange.OrderUpdate{
+		OrderID:       orderID,
+		ClientOrderID: req.ClientOrderID,
+		Symbol:        req.Symbol,
+		Side:          exchange.Side(req.Side),
+		Type:          exchange.OrderTypeLimit,
+		Status:        status,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+	})
+}
+
+// confirmPlacement 确认订单已在交易所存在：等待期间收到订单流推送即确认，否则通过 REST 查询，查不到时重新下单
+// 重新下单沿用同一自定义订单ID：原订单只是查询延迟时交易所会拒绝重复ID，不会挂出两笔，此时按原订单已存在处理
+// 查询本身失败（非订单不存在）时无法判断，按下单成功处理，交由对账兜底
+func (oe *ExchangeOrderExecutor) confirmPlacement(req *OrderRequest, order *Order, ack <-chan struct{}) (*Order, error) {
+	for replaces := 0; ; replaces++ {
+		select {
+		case <-ack:
+			orderLog.Debug("🔎 [%s] 下单确认: %s %.*f 订单 %d 已收到订单流推送",
+				oe.exchange.GetName(), req.Side, req.PriceDecimals, req.Price, order.OrderID)
+			return order, nil
+		case <-time.After(oe.confirmDelay):
+		}
+
+		if err := oe.rateLimiter.Wait(context.Background(), BucketQuery, oe.symbol); err != nil {
+			return order, nil
+		}
+		_, err := oe.exchange.GetOrder(context.Background(), oe.symbol, order.OrderID)
+		if err == nil {
+			orderLog.Debug("🔎 [%s] 下单确认: %s %.*f 订单 %d 已存在",
+				oe.exchange.GetName(), req.Side, req.PriceDecimals, req.Price, order.OrderID)
+			return order, nil
+		}
+		if !exchange.IsOrderNotFoundError(err) {
+			orderLog.Warn("⚠️ [%s] 下单确认查询订单 %d 失败，按已下单处理: %v", oe.exchange.GetName(), order.OrderID, err)
+			return order, nil
+		}
+		if replaces >= oe.confirmMaxReplaces {
+			return nil, fmt.Errorf("下单确认失败: 订单 %d 查询不到，已重新下单 %d 次", order.OrderID, replaces)
+		}
+
+		orderLog.Warn("⚠️ [%s] 下单确认: %s %.*f 订单 %d 查询不到，重新下单 (%d/%d)",
+			oe.exchange.GetName(), req.Side, req.PriceDecimals, req.Price, order.OrderID, replaces+1, oe.confirmMaxReplaces)
+		replaced, err := oe.placeOrder(req)
+		if exchange.IsDuplicateOrderError(err) {
+			orderLog.Info("🔎 [%s] 下单确认: 重新下单被拒（自定义订单ID已存在），订单 %d 只是查询延迟，按已下单处理",
+				oe.exchange.GetName(), order.OrderID)
+			return order, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("下单确认后重新下单失败: %w", err)
+		}
+		order = replaced
+	}
+}
+
+// placeOrder 下单（带重试）
+func (oe *ExchangeOrderExecutor) placeOrder(req *OrderRequest) (*Order, error) {
 	// 限流
-	if err := oe.rateLimiter.Wait(context.Background()); err != nil {
+	if err := oe.rateLimiter.Wait(context.Background(), BucketOrder, oe.symbol); err != nil {
 		return nil, fmt.Errorf("速率限制等待失败: %v", err)
 	}
 
@@ -97,17 +506,25 @@ func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
 			PostOnly:      req.PostOnly && !degraded, // 如果已降级，强制为普通单
 			ClientOrderID: req.ClientOrderID,         // 传递自定义订单ID
 		}
+		if req.Market {
+			exchangeReq.Type = exchange.OrderTypeMarket
+			exchangeReq.TimeInForce = exchange.TimeInForceIOC
+			exchangeReq.Price = 0
+			exchangeReq.PostOnly = false
+		}
 
 		// 🔥 如果PostOnly已失败3次，降级为普通限价单
 		if postOnlyFailCount >= 3 && req.PostOnly && !degraded {
 			degraded = true
-			logger.Warn("⚠️ [%s] PostOnly已失败3次，降级为普通限价单: %s %.2f",
+			orderLog.Warn("⚠️ [%s] PostOnly已失败3次，降级为普通限价单: %s %.2f",
 				oe.exchange.GetName(), req.Side, req.Price)
 			exchangeReq.PostOnly = false
 		}
 
 		// 调用交易所接口
+		done := oe.beginAction("下单")
 		exchangeOrder, err := oe.exchange.PlaceOrder(context.Background(), exchangeReq)
+		done()
 		if err == nil {
 			// 转换回 Order 格式
 			order := &Order{
@@ -123,10 +540,12 @@ func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
 
 			// 根据实际使用的订单类型显示日志
 			orderTypeDesc := "PostOnly"
-			if !exchangeReq.PostOnly {
+			if req.Market {
+				orderTypeDesc = "市价单"
+			} else if !exchangeReq.PostOnly {
 				orderTypeDesc = "普通单(PostOnly降级)"
 			}
-			logger.Info("✅ [%s] 下单成功(%s): %s %.*f 数量: %.4f 订单ID: %d",
+			orderLog.Info("✅ [%s] 下单成功(%s): %s %.*f 数量: %.4f 订单ID: %d",
 				oe.exchange.GetName(), orderTypeDesc, req.Side, req.PriceDecimals, req.Price, req.Quantity, exchangeOrder.OrderID)
 			return order, nil
 		}
@@ -141,13 +560,18 @@ func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
 			return nil, fmt.Errorf("持仓模式不匹配: %w", err)
 		} else if strings.Contains(errStr, "-1003") || strings.Contains(errStr, "rate limit") {
 			// 速率限制，等待后重试
-			logger.Warn("⚠️ 触发速率限制，等待后重试...")
+			oe.rateLimiter.OnRejected()
+			orderLog.Warn("⚠️ 触发速率限制，等待后重试...")
 			time.Sleep(oe.rateLimitRetryDelay)
 			continue
+		} else if exchange.IsCooldownError(err) {
+			// 交易对操作冷却：放大间隔，下一轮由 beginAction 等待
+			oe.onCooldownRejected("下单", err)
+			continue
 		} else if isPostOnlyError(err) && !degraded {
 			// 🔥 PostOnly错误：价格会立即成交，记录失败次数(必须放在其他检查之前!)
 			postOnlyFailCount++
-			logger.Warn("⚠️ [%s] PostOnly被拒(%d/3): %s %.2f, 等待500ms后重试",
+			orderLog.Warn("⚠️ [%s] PostOnly被拒(%d/3): %s %.2f, 等待500ms后重试",
 				oe.exchange.GetName(), postOnlyFailCount, req.Side, req.Price)
 
 			// 如果还没达到3次，继续重试PostOnly
@@ -161,12 +585,18 @@ func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
 		} else if strings.Contains(errStr, "-4061") {
 			// 持仓模式不匹配（已在前面处理，这里保留以防万一）
 			return nil, err
-		} else if strings.Contains(errStr, "-2019") || strings.Contains(errStr, "保证金不足") || strings.Contains(errStr, "insufficient") {
+		} else if isMarginError(err) {
 			// 保证金不足，不重试
 			return nil, err
+		} else if exchange.IsDuplicateOrderError(err) {
+			// 自定义订单ID已存在（下单确认重新下单时原订单仍在），重试不会成功
+			return nil, err
 		} else if strings.Contains(errStr, "-1021") {
 			// 时间戳不同步，不重试
 			return nil, err
+		} else if exchange.IsServerError(err) {
+			// 交易所服务端错误（5xx），重试只会加重负担，交由健康监测决定是否暂停
+			return nil, err
 		}
 
 		// 其他错误，短暂等待后重试
@@ -186,15 +616,17 @@ func (oe *ExchangeOrderExecutor) BatchPlaceOrders(orders []*OrderRequest) ([]*Or
 
 	for _, orderReq := range orders {
 		order, err := oe.PlaceOrder(orderReq)
+		if errors.Is(err, ErrDraining) {
+			break // 正在退出，剩余订单不再下单
+		}
 		if err != nil {
-			logger.Warn("⚠️ [%s] 下单失败 %.2f %s: %v",
+			orderLog.Warn("⚠️ [%s] 下单失败 %.2f %s: %v",
 				oe.exchange.GetName(), orderReq.Price, orderReq.Side, err)
 
 			// 检查是否是保证金不足错误
-			errStr := err.Error()
-			if strings.Contains(errStr, "保证金不足") || strings.Contains(errStr, "-2019") || strings.Contains(errStr, "insufficient") {
+			if isMarginError(err) {
 				hasMarginError = true
-				logger.Error("❌ [保证金不足] 订单 %.2f %s 因保证金不足失败", orderReq.Price, orderReq.Side)
+				orderLog.Error("❌ [保证金不足] 订单 %.2f %s 因保证金不足失败", orderReq.Price, orderReq.Side)
 			}
 			continue
 		}
@@ -204,51 +636,202 @@ func (oe *ExchangeOrderExecutor) BatchPlaceOrders(orders []*OrderRequest) ([]*Or
 	return placedOrders, hasMarginError
 }
 
+// isMarginError 是否为保证金不足错误（各交易所适配器统一包装为"保证金不足"，币安原始错误码为 -2019）
+func isMarginError(err error) bool {
+	errStr := err.Error()
+	return strings.Contains(errStr, "保证金不足") || strings.Contains(errStr, "-2019") || strings.Contains(errStr, "insufficient")
+}
+
 // CancelOrder 取消订单
 func (oe *ExchangeOrderExecutor) CancelOrder(orderID int64) error {
+	if oe.simulator != nil {
+		oe.cancelSimulated(orderID)
+		return nil
+	}
+
 	// 限流
-	if err := oe.rateLimiter.Wait(context.Background()); err != nil {
+	if err := oe.rateLimiter.Wait(context.Background(), BucketOrder, oe.symbol); err != nil {
 		return fmt.Errorf("速率限制等待失败: %v", err)
 	}
 
+	done := oe.beginAction("撤单")
 	err := oe.exchange.CancelOrder(context.Background(), oe.symbol, orderID)
+	done()
+	if exchange.IsCooldownError(err) {
+		oe.onCooldownRejected("撤单", err)
+		done = oe.beginAction("撤单")
+		err = oe.exchange.CancelOrder(context.Background(), oe.symbol, orderID)
+		done()
+	}
 	if err != nil {
 		// 如果是"Unknown order"错误，说明订单已经不存在（可能已成交或已取消），不算错误
 		errStr := err.Error()
 		if strings.Contains(errStr, "-2011") || strings.Contains(errStr, "Unknown order") || strings.Contains(errStr, "does not exist") {
-			logger.Info("ℹ️ [%s] 订单 %d 已不存在（可能已成交或已取消），跳过取消", oe.exchange.GetName(), orderID)
+			orderLog.Info("ℹ️ [%s] 订单 %d 已不存在（可能已成交或已取消），跳过取消", oe.exchange.GetName(), orderID)
 			return nil
 		}
 		return fmt.Errorf("取消订单失败: %v", err)
 	}
 
-	logger.Info("✅ [%s] 取消订单成功: %d", oe.exchange.GetName(), orderID)
+	orderLog.Info("✅ [%s] 取消订单成功: %d", oe.exchange.GetName(), orderID)
 	return nil
 }
 
-// BatchCancelOrders 批量撤单
-func (oe *ExchangeOrderExecutor) BatchCancelOrders(orderIDs []int64) error {
+// BatchCancelOrders 批量撤单，reason 为撤单原因（event.OrderReason*），每个订单的结果以 event.TypeOrderAction 发布
+func (oe *ExchangeOrderExecutor) BatchCancelOrders(orderIDs []int64, reason string) error {
 	if len(orderIDs) == 0 {
 		return nil
 	}
+	if oe.simulator != nil {
+		for _, orderID := range orderIDs {
+			oe.cancelSimulated(orderID)
+			oe.publishCancel(orderID, reason, nil)
+		}
+		return nil
+	}
 
 	// 使用交易所的批量撤单接口
+	done := oe.beginAction("批量撤单")
 	err := oe.exchange.BatchCancelOrders(context.Background(), oe.symbol, orderIDs)
-	if err != nil {
-		logger.Warn("⚠️ [%s] 批量撤单失败: %v，尝试单个撤单", oe.exchange.GetName(), err)
-		// 如果批量撤单失败，尝试单个撤单
+	done()
+	if err == nil {
 		for _, orderID := range orderIDs {
-			if err := oe.CancelOrder(orderID); err != nil {
-				logger.Warn("⚠️ [%s] 取消订单 %d 失败: %v", oe.exchange.GetName(), orderID, err)
-			}
+			oe.publishCancel(orderID, reason, nil)
+		}
+		return nil
+	}
+
+	orderLog.Warn("⚠️ [%s] 批量撤单失败: %v，尝试单个撤单", oe.exchange.GetName(), err)
+	// 如果批量撤单失败，尝试单个撤单
+	for _, orderID := range orderIDs {
+		err := oe.CancelOrder(orderID)
+		if err != nil {
+			orderLog.Warn("⚠️ [%s] 取消订单 %d 失败: %v", oe.exchange.GetName(), orderID, err)
+		}
+		oe.publishCancel(orderID, reason, err)
+	}
+
+	return nil
+}
+
+// CancelAllViaBatch 查询未完成订单后分批调用批量撤单接口撤销（交易所不支持一键撤销全部订单时的兜底）
+// 每批失败时等待 order_retry_delay 重试一次（速率限制时等待 rate_limit_retry_delay），仍失败则计入失败数继续下一批，
+// 每个订单的结果以 event.TypeOrderAction 发布，reason 为撤单原因（event.OrderReason*）；模拟交易时撤销所有模拟挂单
+func (oe *ExchangeOrderExecutor) CancelAllViaBatch(ctx context.Context, reason string) error {
+	if oe.simulator != nil {
+		for _, ord := range oe.simulator.OpenOrders() {
+			oe.cancelSimulated(ord.OrderID)
+			oe.publishCancel(ord.OrderID, reason, nil)
+		}
+		return nil
+	}
+
+	if err := oe.rateLimiter.Wait(ctx, BucketQuery, oe.symbol); err != nil {
+		return fmt.Errorf("速率限制等待失败: %v", err)
+	}
+	orders, err := oe.exchange.GetOpenOrders(ctx, oe.symbol)
+	if err != nil {
+		return fmt.Errorf("查询未完成订单失败: %w", err)
+	}
+	if len(orders) == 0 {
+		return nil
+	}
+
+	batchSize := oe.cancelBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultCancelBatchSize
+	}
+	orderIDs := make([]int64, 0, len(orders))
+	for _, ord := range orders {
+		orderIDs = append(orderIDs, ord.OrderID)
+	}
+	orderLog.Info("🔄 [%s] 分批撤销 %d 个未完成订单（每批 %d 个）", oe.exchange.GetName(), len(orderIDs), batchSize)
+
+	failed := 0
+	for start := 0; start < len(orderIDs); start += batchSize {
+		batch := orderIDs[start:min(start+batchSize, len(orderIDs))]
+		err := oe.cancelBatch(ctx, batch)
+		if err != nil {
+			failed += len(batch)
+			orderLog.Warn("⚠️ [%s] 分批撤单失败 (%d 个订单): %v", oe.exchange.GetName(), len(batch), err)
+		}
+		for _, orderID := range batch {
+			oe.publishCancel(orderID, reason, err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
 	}
 
+	if failed > 0 {
+		return fmt.Errorf("分批撤单: %d/%d 个订单撤销失败", failed, len(orderIDs))
+	}
 	return nil
 }
 
+// cancelBatch 撤销一批订单，失败时按重试延迟等待后重试一次
+func (oe *ExchangeOrderExecutor) cancelBatch(ctx context.Context, orderIDs []int64) error {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			delay := oe.orderRetryDelay
+			if strings.Contains(err.Error(), "-1003") || strings.Contains(err.Error(), "rate limit") {
+				oe.rateLimiter.OnRejected()
+				delay = oe.rateLimitRetryDelay
+			} else if exchange.IsCooldownError(err) {
+				oe.onCooldownRejected("批量撤单", err)
+				delay = 0 // 由 beginAction 等待放大后的操作间隔
+			}
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return fmt.Errorf("%w (最后一次错误: %v)", ctx.Err(), err)
+			}
+		}
+
+		if err = oe.rateLimiter.Wait(ctx, BucketOrder, oe.symbol); err != nil {
+			return fmt.Errorf("速率限制等待失败: %v", err)
+		}
+		done := oe.beginAction("批量撤单")
+		err = oe.exchange.BatchCancelOrders(ctx, oe.symbol, orderIDs)
+		done()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// cancelSimulated 撤销模拟挂单（模拟交易时使用）
+func (oe *ExchangeOrderExecutor) cancelSimulated(orderID int64) {
+	if oe.simulator.Cancel(orderID) {
+		orderLog.Info("🧪 [模拟撤单] 订单ID: %d", orderID)
+	} else {
+		orderLog.Info("ℹ️ [模拟撤单] 订单 %d 已不存在（可能已成交或已取消），跳过取消", orderID)
+	}
+}
+
+// publishCancel 发布撤单动作事件
+func (oe *ExchangeOrderExecutor) publishCancel(orderID int64, reason string, err error) {
+	action := event.OrderAction{Symbol: oe.symbol, Action: "cancel", OrderID: orderID, Reason: reason}
+	if err != nil {
+		action.Error = err.Error()
+	}
+	event.Publish(event.TypeOrderAction, action)
+}
+
 // CheckOrderStatus 检查订单状态
 func (oe *ExchangeOrderExecutor) CheckOrderStatus(orderID int64) (string, float64, error) {
+	if oe.simulator != nil {
+		order, err := oe.simulator.GetOrder(orderID)
+		if err != nil {
+			return "", 0, err
+		}
+		return string(order.Status), order.ExecutedQty, nil
+	}
+	if err := oe.rateLimiter.Wait(context.Background(), BucketQuery, oe.symbol); err != nil {
+		return "", 0, fmt.Errorf("速率限制等待失败: %v", err)
+	}
 	order, err := oe.exchange.GetOrder(context.Background(), oe.symbol, orderID)
 	if err != nil {
 		return "", 0, err
@@ -259,9 +842,18 @@ func (oe *ExchangeOrderExecutor) CheckOrderStatus(orderID int64) (string, float6
 
 // GetOpenOrders 获取未完成订单
 func (oe *ExchangeOrderExecutor) GetOpenOrders() ([]interface{}, error) {
-	orders, err := oe.exchange.GetOpenOrders(context.Background(), oe.symbol)
-	if err != nil {
-		return nil, err
+	var orders []*exchange.Order
+	if oe.simulator != nil {
+		orders = oe.simulator.OpenOrders()
+	} else {
+		if err := oe.rateLimiter.Wait(context.Background(), BucketQuery, oe.symbol); err != nil {
+			return nil, fmt.Errorf("速率限制等待失败: %v", err)
+		}
+		var err error
+		orders, err = oe.exchange.GetOpenOrders(context.Background(), oe.symbol)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// 转换为 interface{} 列表（为了兼容现有代码）
@@ -0,0 +1,397 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"opensqt/exchange"
+)
+
+// fakeExchange 测试用交易所：记录下单、查询和撤单，可模拟下单后暂时查询不到和静默丢单
+type fakeExchange struct {
+	exchange.IExchange
+
+	mu             sync.Mutex
+	nextID         int64
+	orders         map[int64]*exchange.Order // 挂单
+	hiddenQueries  int                       // 前 N 次查询订单返回订单不存在（下单后尚不可见）
+	dropPlacements int                       // 前 N 笔下单返回成功但订单不存在（静默丢单）
+	placeDelay     time.Duration             // 下单请求耗时
+	placeCalls     int
+	placeStarts    []time.Time // 每次下单请求到达的时间
+	getCalls       int
+	canceled       []int64
+	cancelBatches  [][]int64 // 每次批量撤单请求的订单ID
+}
+
+func newFakeExchange() *fakeExchange {
+	return &fakeExchange{nextID: 100, orders: make(map[int64]*exchange.Order)}
+}
+
+func (f *fakeExchange) GetName() string { return "Fake" }
+
+func (f *fakeExchange) PlaceOrder(ctx context.Context, req *exchange.OrderRequest) (*exchange.Order, error) {
+	f.mu.Lock()
+	f.placeStarts = append(f.placeStarts, time.Now())
+	f.mu.Unlock()
+	time.Sleep(f.placeDelay)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.placeCalls++
+	for _, ord := range f.orders {
+		if req.ClientOrderID != "" && ord.ClientOrderID == req.ClientOrderID {
+			return nil, errors.New("code=-4116, msg=ClientOrderId is duplicated")
+		}
+	}
+	f.nextID++
+	ord := &exchange.Order{OrderID: f.nextID, ClientOrderID: req.ClientOrderID, Symbol: req.Symbol, Side: req.Side,
+		Price: req.Price, Quantity: req.Quantity, Status: exchange.OrderStatusNew}
+	if f.dropPlacements > 0 {
+		f.dropPlacements--
+		return ord, nil
+	}
+	f.orders[ord.OrderID] = ord
+	return ord, nil
+}
+
+func (f *fakeExchange) GetOrder(ctx context.Context, symbol string, orderID int64) (*exchange.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.getCalls++
+	if f.hiddenQueries > 0 {
+		f.hiddenQueries--
+		return nil, errors.New("code=-2013, msg=Order does not exist")
+	}
+	if ord, ok := f.orders[orderID]; ok {
+		return ord, nil
+	}
+	return nil, errors.New("code=-2013, msg=Order does not exist")
+}
+
+func (f *fakeExchange) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.orders, orderID)
+	f.canceled = append(f.canceled, orderID)
+	return nil
+}
+
+func (f *fakeExchange) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	f.mu.Lock()
+	f.cancelBatches = append(f.cancelBatches, append([]int64(nil), orderIDs...))
+	f.mu.Unlock()
+	for _, id := range orderIDs {
+		_ = f.CancelOrder(ctx, symbol, id)
+	}
+	return nil
+}
+
+func (f *fakeExchange) GetOpenOrders(ctx context.Context, symbol string) ([]*exchange.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	orders := make([]*exchange.Order, 0, len(f.orders))
+	for _, ord := range f.orders {
+		orders = append(orders, ord)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].OrderID < orders[j].OrderID })
+	return orders, nil
+}
+
+func (f *fakeExchange) openOrders() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.orders)
+}
+
+// newTestExecutor 创建不限流的执行器
+func newTestExecutor(ex exchange.IExchange) *ExchangeOrderExecutor {
+	return NewExchangeOrderExecutor(ex, "ETHUSDT", NewRateLimiter(nil, BucketLimit{}), 0, 0)
+}
+
+func sellRequest(clientOrderID string) *OrderRequest {
+	return &OrderRequest{Symbol: "ETHUSDT", Side: "SELL", Price: 3000, Quantity: 0.01, PriceDecimals: 2, ClientOrderID: clientOrderID}
+}
+
+// drain 等待后台下单确认结束
+func drain(t *testing.T, oe *ExchangeOrderExecutor) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := oe.Drain(ctx); err != nil {
+		t.Fatalf("等待下单确认超时: %v", err)
+	}
+}
+
+func TestPlaceOrderConfirmNotYetVisible(t *testing.T) {
+	// 下单成功但第一次查询时交易所尚不可见：重新下单因自定义订单ID重复被拒，应按原订单已存在处理
+	ex := newFakeExchange()
+	ex.hiddenQueries = 1
+	oe := newTestExecutor(ex)
+	delay := 50 * time.Millisecond
+	oe.SetPlacementConfirm([]string{"SELL"}, delay, 1)
+	handler, updates := collectUpdates()
+	oe.SetConfirmHandler(handler)
+
+	start := time.Now()
+	ord, err := oe.PlaceOrder(sellRequest("s1"))
+	if err != nil {
+		t.Fatalf("下单应成功: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= delay {
+		t.Fatalf("下单确认应在后台进行，PlaceOrder 不应等待确认（耗时 %v）", elapsed)
+	}
+	drain(t, oe)
+
+	if got := updates(); len(got) != 0 {
+		t.Fatalf("原订单存在时不应推送确认结果，实际: %+v", got)
+	}
+	if ex.openOrders() != 1 || ex.placeCalls != 2 {
+		t.Fatalf("交易所上应只有原订单（挂单 %d 个，下单请求 %d 次）", ex.openOrders(), ex.placeCalls)
+	}
+	if _, err := ex.GetOrder(context.Background(), "ETHUSDT", ord.OrderID); err != nil {
+		t.Fatalf("原订单应保留: %v", err)
+	}
+	if placed, failed := oe.OrderStats(); placed != 1 || failed != 0 {
+		t.Fatalf("应计入下单成功1笔，实际成功 %d 失败 %d", placed, failed)
+	}
+}
+
+func TestPlaceOrderConfirmReplacesDroppedOrder(t *testing.T) {
+	ex := newFakeExchange()
+	ex.dropPlacements = 1
+	oe := newTestExecutor(ex)
+	oe.SetPlacementConfirm([]string{"SELL"}, 10*time.Millisecond, 1)
+	handler, updates := collectUpdates()
+	oe.SetConfirmHandler(handler)
+
+	ord, err := oe.PlaceOrder(sellRequest("s1"))
+	if err != nil {
+		t.Fatalf("下单应成功: %v", err)
+	}
+	drain(t, oe)
+
+	got := updates()
+	if len(got) != 1 || got[0].Status != exchange.OrderStatusNew || got[0].ClientOrderID != "s1" || got[0].OrderID == ord.OrderID {
+		t.Fatalf("重新下单后应推送新订单ID的 NEW，实际: %+v", got)
+	}
+	if got[0].UpdateTime != 0 {
+		t.Fatalf("确认结果不应带 UpdateTime（避免影响真实推送的乱序判断）")
+	}
+	if ex.openOrders() != 1 {
+		t.Fatalf("交易所上应只有重新下的订单，实际 %d 个", ex.openOrders())
+	}
+}
+
+func TestPlaceOrderConfirmFailureReleases(t *testing.T) {
+	ex := newFakeExchange()
+	ex.dropPlacements = 2
+	oe := newTestExecutor(ex)
+	oe.SetPlacementConfirm([]string{"SELL"}, 10*time.Millisecond, 1)
+	handler, updates := collectUpdates()
+	oe.SetConfirmHandler(handler)
+
+	if _, err := oe.PlaceOrder(sellRequest("s1")); err != nil {
+		t.Fatalf("下单请求本身应成功: %v", err)
+	}
+	if placed, _ := oe.OrderStats(); placed != 0 {
+		t.Fatalf("确认完成前不应计入下单成功（计数只增不减），实际 %d", placed)
+	}
+	drain(t, oe)
+
+	if got := updates(); len(got) != 1 || got[0].Status != exchange.OrderStatusRejected || got[0].ClientOrderID != "s1" {
+		t.Fatalf("重新下单后仍查不到时应推送 REJECTED，实际: %+v", got)
+	}
+	if placed, failed := oe.OrderStats(); placed != 0 || failed != 1 {
+		t.Fatalf("确认失败应计为下单失败，实际成功 %d 失败 %d", placed, failed)
+	}
+}
+
+func TestPlaceOrderConfirmByOrderStream(t *testing.T) {
+	ex := newFakeExchange()
+	oe := newTestExecutor(ex)
+	oe.SetPlacementConfirm([]string{"SELL"}, time.Second, 1)
+
+	if _, err := oe.PlaceOrder(sellRequest("s1")); err != nil {
+		t.Fatalf("下单应成功: %v", err)
+	}
+	oe.NotifyOrderUpdate("s1")
+	drain(t, oe)
+
+	if ex.getCalls != 0 {
+		t.Fatalf("订单流已确认的订单不应再查询，实际查询 %d 次", ex.getCalls)
+	}
+}
+
+func TestBeginActionDoesNotSerializeRequests(t *testing.T) {
+	ex := newFakeExchange()
+	ex.placeDelay = 300 * time.Millisecond
+	oe := newTestExecutor(ex)
+	interval := 50 * time.Millisecond
+	oe.SetMinActionInterval(interval)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, id := range []string{"s1", "s2", "s3"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			if _, err := oe.PlaceOrder(sellRequest(id)); err != nil {
+				t.Errorf("下单应成功: %v", err)
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	// 串行执行需要 3 × 300ms，按间隔错开并发执行约 300ms + 2 × 50ms
+	if elapsed := time.Since(start); elapsed >= 2*ex.placeDelay {
+		t.Fatalf("慢请求不应阻塞之后的请求，3笔下单耗时 %v", elapsed)
+	}
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	for i := 1; i < len(ex.placeStarts); i++ {
+		if gap := ex.placeStarts[i].Sub(ex.placeStarts[i-1]); gap < interval-5*time.Millisecond {
+			t.Fatalf("相邻请求的开始时间应至少间隔 %v，实际 %v", interval, gap)
+		}
+	}
+}
+
+func TestBeginActionSpacesFromReturn(t *testing.T) {
+	ex := newFakeExchange()
+	ex.placeDelay = 100 * time.Millisecond
+	oe := newTestExecutor(ex)
+	interval := 50 * time.Millisecond
+	oe.SetMinActionInterval(interval)
+
+	if _, err := oe.PlaceOrder(sellRequest("s1")); err != nil {
+		t.Fatal(err)
+	}
+	returned := time.Now()
+	if _, err := oe.PlaceOrder(sellRequest("s2")); err != nil {
+		t.Fatal(err)
+	}
+
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	if gap := ex.placeStarts[1].Sub(returned); gap < interval-5*time.Millisecond {
+		t.Fatalf("请求返回后下一次请求应至少等待 %v，实际 %v", interval, gap)
+	}
+}
+
+func TestDrainConcurrentPlacement(t *testing.T) {
+	// 退出时下单与排空并发：下单要么在排空前开始（Drain 等它返回，之后的撤单撤掉它），要么返回 ErrDraining，交易所上不能留下挂单
+	for _, tt := range []struct {
+		name       string
+		waitStart  bool // 等下单请求发到交易所后再排空
+		placeDelay time.Duration
+	}{
+		{"下单请求进行中时排空", true, 200 * time.Millisecond},
+		{"下单与排空同时开始", false, 20 * time.Millisecond},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := newFakeExchange()
+			ex.placeDelay = tt.placeDelay
+			oe := newTestExecutor(ex)
+
+			type result struct {
+				ord *Order
+				err error
+			}
+			placed := make(chan result, 1)
+			go func() {
+				ord, err := oe.PlaceOrder(sellRequest("s1"))
+				placed <- result{ord, err}
+			}()
+			if tt.waitStart {
+				for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+					ex.mu.Lock()
+					started := len(ex.placeStarts) > 0
+					ex.mu.Unlock()
+					if started {
+						break
+					}
+					if time.Now().After(deadline) {
+						t.Fatal("下单请求未发出")
+					}
+				}
+			}
+
+			// 退出流程：排空进行中的下单，再撤销交易所上的全部挂单
+			drain(t, oe)
+			ex.mu.Lock()
+			ids := make([]int64, 0, len(ex.orders))
+			for id := range ex.orders {
+				ids = append(ids, id)
+			}
+			ex.mu.Unlock()
+			if err := oe.BatchCancelOrders(ids, "退出撤单"); err != nil {
+				t.Fatalf("撤单失败: %v", err)
+			}
+
+			var res result
+			select {
+			case res = <-placed:
+			case <-time.After(time.Second):
+				t.Fatal("Drain 返回后下单应已返回")
+			}
+			switch {
+			case res.err == nil:
+				ex.mu.Lock()
+				canceled := len(ex.canceled) == 1 && ex.canceled[0] == res.ord.OrderID
+				ex.mu.Unlock()
+				if !canceled {
+					t.Fatalf("排空前开始的下单应在退出撤单时被撤销，订单ID %d，撤单 %v", res.ord.OrderID, ex.canceled)
+				}
+			case errors.Is(res.err, ErrDraining):
+				if ex.placeCalls != 0 {
+					t.Fatalf("被拒绝的下单不应发到交易所，实际下单请求 %d 次", ex.placeCalls)
+				}
+			default:
+				t.Fatalf("下单应成功或返回 ErrDraining，实际 %v", res.err)
+			}
+			if tt.waitStart && res.err != nil {
+				t.Fatalf("已发出的下单请求应等待返回而不是被拒绝: %v", res.err)
+			}
+			if n := ex.openOrders(); n != 0 {
+				t.Fatalf("退出后交易所上不应残留挂单，实际 %d 个", n)
+			}
+
+			// 排空之后的下单一律拒绝
+			calls := ex.placeCalls
+			if _, err := oe.PlaceOrder(sellRequest("s2")); !errors.Is(err, ErrDraining) {
+				t.Fatalf("排空之后的下单应返回 ErrDraining，实际 %v", err)
+			}
+			if ex.placeCalls != calls {
+				t.Fatalf("排空之后不应再向交易所下单")
+			}
+		})
+	}
+}
+
+func TestCancelAllViaBatchChunks(t *testing.T) {
+	ex := newFakeExchange()
+	oe := newTestExecutor(ex)
+	oe.SetCancelBatchSize(2)
+	for i := 1; i <= 5; i++ {
+		if _, err := oe.PlaceOrder(sellRequest(fmt.Sprintf("s%d", i))); err != nil {
+			t.Fatalf("下单失败: %v", err)
+		}
+	}
+
+	if err := oe.CancelAllViaBatch(context.Background(), "exit"); err != nil {
+		t.Fatalf("分批撤单应成功: %v", err)
+	}
+	want := [][]int64{{101, 102}, {103, 104}, {105}}
+	if !reflect.DeepEqual(ex.cancelBatches, want) {
+		t.Fatalf("应按每批 2 个撤销全部挂单，期望 %v，实际 %v", want, ex.cancelBatches)
+	}
+	if n := ex.openOrders(); n != 0 {
+		t.Fatalf("分批撤单后不应残留挂单，实际 %d 个", n)
+	}
+}
@@ -0,0 +1,112 @@
+package order
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// 限流桶类别（下单/撤单与订单查询分开计数，查询较多时不会挤占下单配额）
+const (
+	BucketOrder = "order" // 下单、撤单
+	BucketQuery = "query" // 订单状态、挂单列表查询
+)
+
+// BucketLimit 限流桶参数
+type BucketLimit struct {
+	Rate  float64 // 每秒补充的令牌数
+	Burst int     // 突发容量
+}
+
+// RateLimiter 按名称划分的限流桶（timing.rate_limits）
+// 每个请求同时占用类别桶和交易对桶（per_symbol 启用时），多个交易对共享同一个 RateLimiter，
+// 活跃交易对耗尽自身配额后不会影响其他交易对
+type RateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*rate.Limiter
+	perSymbol BucketLimit // Rate 为0表示不按交易对限流
+
+	lastRejected atomic.Int64 // 最近一次被交易所速率限制拒绝的时间（UnixNano）
+}
+
+// rejectedPressureWindow 被交易所速率限制拒绝后视为满负荷的时长
+const rejectedPressureWindow = 30 * time.Second
+
+// NewRateLimiter 创建限流器，categories 为类别桶参数，perSymbol 为每个交易对独立桶的参数
+func NewRateLimiter(categories map[string]BucketLimit, perSymbol BucketLimit) *RateLimiter {
+	rl := &RateLimiter{
+		buckets:   make(map[string]*rate.Limiter),
+		perSymbol: perSymbol,
+	}
+	for name, limit := range categories {
+		rl.buckets[name] = rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)
+	}
+	return rl
+}
+
+// symbolBucket 交易对桶名称
+func symbolBucket(symbol string) string {
+	return "symbol:" + symbol
+}
+
+// bucket 获取限流桶（交易对桶首次使用时创建，未配置的类别不限流）
+func (rl *RateLimiter) bucket(name string, create bool) *rate.Limiter {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if l, ok := rl.buckets[name]; ok {
+		return l
+	}
+	if !create {
+		return nil
+	}
+	l := rate.NewLimiter(rate.Limit(rl.perSymbol.Rate), rl.perSymbol.Burst)
+	rl.buckets[name] = l
+	return l
+}
+
+// Wait 等待类别桶和交易对桶各取得一个令牌
+func (rl *RateLimiter) Wait(ctx context.Context, category, symbol string) error {
+	if l := rl.bucket(category, false); l != nil {
+		if err := l.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	if rl.perSymbol.Rate > 0 && symbol != "" {
+		if err := rl.bucket(symbolBucket(symbol), true).Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Levels 各限流桶当前可用的令牌数（用于状态查询和监控）
+func (rl *RateLimiter) Levels() map[string]float64 {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	levels := make(map[string]float64, len(rl.buckets))
+	for name, l := range rl.buckets {
+		levels[name] = l.Tokens()
+	}
+	return levels
+}
+
+// OnRejected 记录交易所返回的速率限制拒绝
+func (rl *RateLimiter) OnRejected() {
+	rl.lastRejected.Store(time.Now().UnixNano())
+}
+
+// Pressure 下单路径的接口压力（0~1）：下单桶已用令牌的比例，最近被交易所速率限制拒绝时为1
+func (rl *RateLimiter) Pressure() float64 {
+	if last := rl.lastRejected.Load(); last > 0 && time.Since(time.Unix(0, last)) < rejectedPressureWindow {
+		return 1
+	}
+	l := rl.bucket(BucketOrder, false)
+	if l == nil || l.Burst() <= 0 {
+		return 0
+	}
+	used := 1 - l.Tokens()/float64(l.Burst())
+	return min(max(used, 0), 1)
+}
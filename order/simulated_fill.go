@@ -0,0 +1,319 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"opensqt/exchange"
+	"opensqt/utils"
+)
+
+// SimulatedFillEngine 模拟成交引擎（system.dry_run）
+// 保存执行器模拟下单的挂单，按最新价格判断成交：限价单按成交模型（utils.FillModel，与模拟交易所共用）判断，
+// 价格需穿过挂单价 queue_through_ticks 个最小价格单位，再等待 fill_latency 后按挂单价成交（期间撤单仍可成功），
+// 市价单按下一次取到的价格立即成交。下单、成交、撤单都以订单更新推送给 handler（与真实订单流一样依次推送 NEW → FILLED / CANCELED），
+// 全程不调用交易所的下单撤单接口。成交同时记入内存中的模拟账户（净持仓、均价、钱包余额），
+// 模拟交易时仓位管理器和对账器查询的持仓和保证金都来自模拟账户，与模拟挂单保持一致
+type SimulatedFillEngine struct {
+	nextID    atomic.Int64
+	handler   func(exchange.OrderUpdate)
+	fillModel *utils.FillModel
+
+	mu      sync.Mutex
+	orders  map[int64]*simulatedOrder // 未完成的模拟挂单
+	pending []exchange.OrderUpdate    // 待推送的订单更新（由 Start 的协程按顺序推送，避免在调用方持锁时回调）
+
+	// 模拟账户（由 mu 保护）
+	lastPrice     float64
+	positionSize  float64
+	entryPrice    float64
+	walletBalance float64
+	feeRate       float64
+	leverage      int
+}
+
+// simulatedOrder 模拟挂单（priceDecimals 用于日志格式化价格）
+type simulatedOrder struct {
+	exchange.Order
+	priceDecimals int
+}
+
+// NewSimulatedFillEngine 创建模拟成交引擎，handler 接收模拟订单的状态更新，fillModel 为 nil 时触价立即成交
+func NewSimulatedFillEngine(handler func(exchange.OrderUpdate), fillModel *utils.FillModel) *SimulatedFillEngine {
+	if fillModel == nil {
+		fillModel = &utils.FillModel{}
+	}
+	e := &SimulatedFillEngine{
+		handler:   handler,
+		fillModel: fillModel,
+		orders:    make(map[int64]*simulatedOrder),
+	}
+	// 订单ID从当前毫秒时间戳开始递增，重启后不会与上次运行的模拟订单重复
+	e.nextID.Store(time.Now().UnixMilli())
+	return e
+}
+
+// SetAccount 设置模拟账户的初始钱包余额、杠杆和手续费率（需在下单之前调用，leverage<=0 时按1倍计算）
+func (e *SimulatedFillEngine) SetAccount(balance float64, leverage int, feeRate float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if leverage <= 0 {
+		leverage = 1
+	}
+	e.walletBalance = balance
+	e.leverage = leverage
+	e.feeRate = feeRate
+}
+
+// Position 模拟账户的净持仓（正数为多仓）和持仓均价
+func (e *SimulatedFillEngine) Position() (size, entryPrice float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.positionSize, e.entryPrice
+}
+
+// Balance 模拟账户的可用保证金、保证金余额（含未实现盈亏）和杠杆
+// 可用保证金 = 保证金余额 - 持仓保证金 - 买单冻结保证金（与模拟交易所相同）
+func (e *SimulatedFillEngine) Balance() (available, equity float64, leverage int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	equity = e.walletBalance + (e.lastPrice-e.entryPrice)*e.positionSize
+	lev := float64(e.leverage)
+	used := math.Abs(e.positionSize) * e.lastPrice / lev
+	for _, ord := range e.orders {
+		if ord.Side == exchange.SideBuy {
+			used += ord.Quantity * ord.Price / lev
+		}
+	}
+	return equity - used, equity, e.leverage
+}
+
+// CancelAll 撤销所有模拟挂单，返回撤销数量
+func (e *SimulatedFillEngine) CancelAll() int {
+	canceled := 0
+	for _, ord := range e.OpenOrders() {
+		if e.Cancel(ord.OrderID) {
+			canceled++
+		}
+	}
+	return canceled
+}
+
+// ClosePosition 按最新价格平掉模拟账户的净持仓，返回平仓数量（模拟交易时代替市价平仓）
+func (e *SimulatedFillEngine) ClosePosition() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	size := e.positionSize
+	if size == 0 || e.lastPrice <= 0 {
+		return 0
+	}
+	side := exchange.SideSell
+	if size < 0 {
+		side = exchange.SideBuy
+	}
+	e.applyFillLocked(side, math.Abs(size), e.lastPrice)
+	return size
+}
+
+// Place 记录一个模拟订单并返回状态为 NEW 的订单
+func (e *SimulatedFillEngine) Place(req *OrderRequest) *Order {
+	now := time.Now()
+	ord := &simulatedOrder{
+		Order: exchange.Order{
+			OrderID:       e.nextID.Add(1),
+			ClientOrderID: req.ClientOrderID,
+			Symbol:        req.Symbol,
+			Side:          exchange.Side(req.Side),
+			Type:          exchange.OrderTypeLimit,
+			Price:         req.Price,
+			Quantity:      req.Quantity,
+			Status:        exchange.OrderStatusNew,
+			CreatedAt:     now,
+			UpdateTime:    now.UnixMilli(),
+		},
+		priceDecimals: req.PriceDecimals,
+	}
+	if req.Market {
+		ord.Type = exchange.OrderTypeMarket
+	}
+
+	e.mu.Lock()
+	e.orders[ord.OrderID] = ord
+	e.pending = append(e.pending, orderUpdateOf(ord))
+	e.mu.Unlock()
+
+	return &Order{
+		OrderID:       ord.OrderID,
+		ClientOrderID: ord.ClientOrderID,
+		Symbol:        ord.Symbol,
+		Side:          req.Side,
+		Price:         ord.Price,
+		Quantity:      ord.Quantity,
+		Status:        string(ord.Status),
+		CreatedAt:     now,
+	}
+}
+
+// Cancel 撤销模拟挂单，订单不存在（已成交或已撤销）时返回 false
+func (e *SimulatedFillEngine) Cancel(orderID int64) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ord, ok := e.orders[orderID]
+	if !ok {
+		return false
+	}
+	delete(e.orders, orderID)
+	ord.Status = exchange.OrderStatusCanceled
+	ord.UpdateTime = time.Now().UnixMilli()
+	e.pending = append(e.pending, orderUpdateOf(ord))
+	return true
+}
+
+// OpenOrders 未完成的模拟挂单
+func (e *SimulatedFillEngine) OpenOrders() []*exchange.Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	orders := make([]*exchange.Order, 0, len(e.orders))
+	for _, ord := range e.orders {
+		copied := ord.Order
+		orders = append(orders, &copied)
+	}
+	return orders
+}
+
+// GetOrder 查询未完成的模拟挂单（已成交或已撤销的订单不保留，返回订单不存在）
+func (e *SimulatedFillEngine) GetOrder(orderID int64) (*exchange.Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ord, ok := e.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("模拟订单 %d does not exist", orderID)
+	}
+	copied := ord.Order
+	return &copied, nil
+}
+
+// Start 每 interval 取一次最新价格撮合模拟挂单并推送订单更新（阻塞直到 ctx 取消）
+func (e *SimulatedFillEngine) Start(ctx context.Context, priceSource func() float64, interval time.Duration) {
+	orderLog.Info("🧪 [模拟成交] 启动: 按最新价格撮合模拟挂单（间隔 %v，成交延迟 %v，需穿过 %d 个最小价格单位）",
+		interval, e.fillModel.Latency, e.fillModel.QueueThroughTicks)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.match(priceSource())
+			e.flush()
+		}
+	}
+}
+
+// match 按价格撮合模拟挂单（价格非正时跳过）
+func (e *SimulatedFillEngine) match(price float64) {
+	if price <= 0 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastPrice = price
+	for id, ord := range e.orders {
+		if ord.Type == exchange.OrderTypeMarket {
+			e.fillLocked(ord, price, price)
+			continue
+		}
+		if e.fillModel.Pending(id) || !e.fillModel.Crossed(ord.Side == exchange.SideBuy, ord.Price, price, ord.priceDecimals) {
+			continue
+		}
+		if e.fillModel.Schedule(id, func() { e.delayedFill(id, price) }) {
+			continue
+		}
+		e.fillLocked(ord, ord.Price, price)
+	}
+}
+
+// delayedFill 成交延迟到期后按挂单价成交（期间已撤单则忽略），更新在下一次撮合时推送
+func (e *SimulatedFillEngine) delayedFill(orderID int64, triggerPrice float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if ord, ok := e.orders[orderID]; ok {
+		e.fillLocked(ord, ord.Price, triggerPrice)
+	}
+}
+
+// fillLocked 全部成交模拟订单（调用前必须持有 mu），triggerPrice 为满足成交条件时的最新价格
+func (e *SimulatedFillEngine) fillLocked(ord *simulatedOrder, fillPrice, triggerPrice float64) {
+	delete(e.orders, ord.OrderID)
+	ord.Status = exchange.OrderStatusFilled
+	ord.ExecutedQty = ord.Quantity
+	ord.AvgPrice = fillPrice
+	ord.UpdateTime = time.Now().UnixMilli()
+	e.pending = append(e.pending, orderUpdateOf(ord))
+	e.applyFillLocked(ord.Side, ord.Quantity, fillPrice)
+	orderLog.Info("🧪 [模拟成交] %s %.*f 数量: %.4f 订单ID: %d（最新价格 %.*f）",
+		ord.Side, ord.priceDecimals, fillPrice, ord.Quantity, ord.OrderID, ord.priceDecimals, triggerPrice)
+}
+
+// applyFillLocked 成交记入模拟账户：更新净持仓和均价，平仓部分结转已实现盈亏，扣除手续费（调用前必须持有 mu）
+func (e *SimulatedFillEngine) applyFillLocked(side exchange.Side, qty, price float64) {
+	signed := qty
+	if side == exchange.SideSell {
+		signed = -qty
+	}
+	// 反向成交先平仓，剩余部分按同向加仓计算均价
+	if e.positionSize*signed < 0 {
+		closeQty := math.Min(qty, math.Abs(e.positionSize))
+		direction := math.Copysign(1, e.positionSize)
+		e.walletBalance += (price - e.entryPrice) * closeQty * direction
+		e.positionSize -= closeQty * direction
+		signed -= -closeQty * direction
+		if math.Abs(e.positionSize) < 1e-9 {
+			e.positionSize = 0
+			e.entryPrice = 0
+		}
+	}
+	if math.Abs(signed) > 1e-9 {
+		newSize := e.positionSize + signed
+		e.entryPrice = (e.entryPrice*math.Abs(e.positionSize) + price*math.Abs(signed)) / math.Abs(newSize)
+		e.positionSize = newSize
+	}
+	e.walletBalance -= price * qty * e.feeRate
+}
+
+// flush 按产生顺序推送待推送的订单更新
+func (e *SimulatedFillEngine) flush() {
+	e.mu.Lock()
+	updates := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+
+	if e.handler == nil {
+		return
+	}
+	for _, update := range updates {
+		e.handler(update)
+	}
+}
+
+// orderUpdateOf 由模拟订单生成订单更新
+func orderUpdateOf(ord *simulatedOrder) exchange.OrderUpdate {
+	return exchange.OrderUpdate{
+		OrderID:       ord.OrderID,
+		ClientOrderID: ord.ClientOrderID,
+		Symbol:        ord.Symbol,
+		Side:          ord.Side,
+		Type:          ord.Type,
+		Status:        ord.Status,
+		Price:         ord.Price,
+		Quantity:      ord.Quantity,
+		ExecutedQty:   ord.ExecutedQty,
+		AvgPrice:      ord.AvgPrice,
+		UpdateTime:    ord.UpdateTime,
+	}
+}
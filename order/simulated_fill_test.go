@@ -0,0 +1,133 @@
+package order
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"opensqt/exchange"
+	"opensqt/utils"
+)
+
+// collectUpdates 返回记录订单更新的 handler 及读取函数
+func collectUpdates() (func(exchange.OrderUpdate), func() []exchange.OrderUpdate) {
+	var updates []exchange.OrderUpdate
+	return func(u exchange.OrderUpdate) { updates = append(updates, u) },
+		func() []exchange.OrderUpdate { return updates }
+}
+
+func buyRequest(price float64) *OrderRequest {
+	return &OrderRequest{Symbol: "ETHUSDT", Side: "BUY", Price: price, Quantity: 0.01, PriceDecimals: 2, ClientOrderID: "c1"}
+}
+
+func TestSimulatedFillEngineTouchFill(t *testing.T) {
+	handler, updates := collectUpdates()
+	e := NewSimulatedFillEngine(handler, nil)
+	ord := e.Place(buyRequest(100))
+
+	e.match(100.01)
+	e.flush()
+	if got := updates(); len(got) != 1 || got[0].Status != exchange.OrderStatusNew {
+		t.Fatalf("价格未触及挂单价时只应推送 NEW，实际: %+v", got)
+	}
+
+	e.match(100)
+	e.flush()
+	got := updates()
+	if len(got) != 2 || got[1].Status != exchange.OrderStatusFilled || got[1].OrderID != ord.OrderID {
+		t.Fatalf("触价应立即成交，实际: %+v", got)
+	}
+	if len(e.OpenOrders()) != 0 {
+		t.Fatalf("成交后不应保留挂单")
+	}
+}
+
+func TestSimulatedFillEngineQueueThrough(t *testing.T) {
+	handler, updates := collectUpdates()
+	e := NewSimulatedFillEngine(handler, utils.NewFillModel(0, 2))
+	e.Place(buyRequest(100))
+
+	e.match(99.99) // 只穿过1个最小价格单位
+	e.flush()
+	if len(e.OpenOrders()) != 1 {
+		t.Fatalf("穿过不足 queue_through_ticks 时不应成交")
+	}
+	e.match(99.98)
+	e.flush()
+	if got := updates(); len(got) != 2 || got[1].Status != exchange.OrderStatusFilled || got[1].AvgPrice != 100 {
+		t.Fatalf("穿过2个最小价格单位后应按挂单价成交，实际: %+v", got)
+	}
+}
+
+func TestSimulatedFillEngineLatencyAllowsCancel(t *testing.T) {
+	handler, updates := collectUpdates()
+	e := NewSimulatedFillEngine(handler, utils.NewFillModel(50*time.Millisecond, 0))
+	filled := e.Place(buyRequest(100))
+	canceled := e.Place(&OrderRequest{Symbol: "ETHUSDT", Side: "BUY", Price: 100, Quantity: 0.01, PriceDecimals: 2, ClientOrderID: "c2"})
+
+	e.match(99)
+	if len(e.OpenOrders()) != 2 {
+		t.Fatalf("成交延迟内不应成交")
+	}
+	if !e.Cancel(canceled.OrderID) {
+		t.Fatalf("成交延迟内撤单应成功")
+	}
+	time.Sleep(100 * time.Millisecond)
+	e.flush()
+
+	var fills, cancels int
+	for _, u := range updates() {
+		switch {
+		case u.Status == exchange.OrderStatusFilled && u.OrderID == filled.OrderID:
+			fills++
+		case u.Status == exchange.OrderStatusFilled:
+			t.Fatalf("已撤销的订单不应成交: %+v", u)
+		case u.Status == exchange.OrderStatusCanceled:
+			cancels++
+		}
+	}
+	if fills != 1 || cancels != 1 {
+		t.Fatalf("应成交1笔、撤销1笔，实际成交 %d、撤销 %d", fills, cancels)
+	}
+}
+
+func TestSimulatedFillEngineAccount(t *testing.T) {
+	e := NewSimulatedFillEngine(nil, nil)
+	e.SetAccount(1000, 10, 0)
+
+	e.Place(buyRequest(100))
+	if available, equity, _ := e.Balance(); equity != 1000 || available != 1000-100*0.01/10 {
+		t.Fatalf("挂买单应冻结保证金，实际可用 %.4f 余额 %.4f", available, equity)
+	}
+	e.match(100)
+	if size, entry := e.Position(); size != 0.01 || entry != 100 {
+		t.Fatalf("买单成交后持仓应为 0.01 @ 100，实际 %.4f @ %.2f", size, entry)
+	}
+
+	e.Place(&OrderRequest{Symbol: "ETHUSDT", Side: "SELL", Price: 110, Quantity: 0.01, PriceDecimals: 2})
+	e.match(110)
+	if size, _ := e.Position(); size != 0 {
+		t.Fatalf("卖单成交后应无持仓，实际 %.4f", size)
+	}
+	if _, equity, _ := e.Balance(); math.Abs(equity-1000.1) > 1e-9 {
+		t.Fatalf("平仓应结转盈利 0.1，实际余额 %.4f", equity)
+	}
+}
+
+func TestSimulatedFillEngineClosePosition(t *testing.T) {
+	e := NewSimulatedFillEngine(nil, nil)
+	e.SetAccount(1000, 10, 0)
+	e.Place(buyRequest(100))
+	e.match(100)
+	e.match(95)
+
+	if closed := e.ClosePosition(); closed != 0.01 {
+		t.Fatalf("应平掉 0.01，实际 %.4f", closed)
+	}
+	if size, _ := e.Position(); size != 0 {
+		t.Fatalf("平仓后应无持仓，实际 %.4f", size)
+	}
+	if _, equity, _ := e.Balance(); math.Abs(equity-999.95) > 1e-9 {
+		t.Fatalf("按最新价格平仓应亏损 0.05，实际余额 %.4f", equity)
+	}
+}
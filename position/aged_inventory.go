@@ -0,0 +1,111 @@
+package position
+
+import (
+	"time"
+
+	"opensqt/event"
+)
+
+// FlattenAgedInventory 市价平掉持有超过 maxHold 的槽位持仓（trading.max_hold_seconds）
+// 只处理超时的槽位，其余持仓和挂单不受影响：先撤销这些槽位的卖单，撤单确认后按槽位持仓数量下只减仓市价单，
+// 成交推送按普通卖单成交更新槽位。挂着买单（可能部分成交）的槽位等买单结束后再处理。返回下单平仓的槽位数
+func (spm *SuperPositionManager) FlattenAgedInventory(maxHold time.Duration) int {
+	if !spm.isInitialized.Load() || maxHold <= 0 {
+		return 0
+	}
+
+	// 持有全局锁，期间 AdjustOrders 不会为这些槽位重新挂卖单
+	spm.mu.Lock()
+	defer spm.mu.Unlock()
+
+	now := time.Now()
+	var aged []float64
+	var cancelIDs []int64
+	spm.slots.Range(func(key, value interface{}) bool {
+		slotPrice := key.(float64)
+		slot := value.(*InventorySlot)
+		slot.mu.Lock()
+		defer slot.mu.Unlock()
+
+		if slot.PositionStatus != PositionStatusFilled || slot.PositionQty <= 0 ||
+			slot.PositionOpenedAt.IsZero() || now.Sub(slot.PositionOpenedAt) < maxHold {
+			return true
+		}
+		if slot.OrderID != 0 && slot.OrderSide == "BUY" {
+			return true
+		}
+		if slot.OrderID != 0 && slot.OrderSide == "SELL" {
+			if slot.OrderStatus == OrderStatusCancelRequested {
+				return true
+			}
+			slot.FeeRepricing = true // 主动撤单，撤单回报不计入 PostOnly 失败
+			cancelIDs = append(cancelIDs, slot.OrderID)
+		}
+		aged = append(aged, slotPrice)
+		return true
+	})
+	if len(aged) == 0 {
+		return 0
+	}
+
+	if len(cancelIDs) > 0 {
+		if err := spm.executor.BatchCancelOrders(cancelIDs, event.OrderReasonAgedInventory); err != nil {
+			positionLog.Error("❌ [持仓超时] 撤销 %d 个卖单失败: %v", len(cancelIDs), err)
+		}
+		// 等待撤单推送更新槽位状态（部分成交的卖单撤销后按剩余持仓平仓）
+		time.Sleep(2 * time.Second)
+	}
+
+	flattened := 0
+	for _, slotPrice := range aged {
+		slot := spm.getOrCreateSlot(slotPrice)
+		slot.mu.Lock()
+		if slot.SlotStatus != SlotStatusFree || slot.OrderID != 0 || slot.ClientOID != "" || slot.PositionQty <= 0 {
+			slot.mu.Unlock()
+			continue
+		}
+		qty := slot.PositionQty
+		held := now.Sub(slot.PositionOpenedAt)
+		slot.SlotStatus = SlotStatusPending
+		slot.mu.Unlock()
+
+		clientOID := spm.generateClientOrderID(slotPrice, "SELL")
+		ord, err := spm.executor.PlaceOrder(&OrderRequest{
+			Symbol:        spm.config.Trading.Symbol,
+			Side:          "SELL",
+			Quantity:      qty,
+			PriceDecimals: spm.priceDecimals,
+			ReduceOnly:    true,
+			Market:        true,
+			ClientOrderID: clientOID,
+			Reason:        event.OrderReasonAgedInventory,
+		})
+
+		slot.mu.Lock()
+		if err != nil {
+			if slot.SlotStatus == SlotStatusPending {
+				slot.SlotStatus = SlotStatusFree
+			}
+			slot.mu.Unlock()
+			positionLog.Error("❌ [持仓超时] 槽位 %s 持有 %v，市价平仓 %.6f 失败: %v",
+				formatPrice(slotPrice, spm.priceDecimals), held.Round(time.Second), qty, err)
+			continue
+		}
+		// 成交推送可能先于下单返回到达，此时槽位已被更新，只在仍为 PENDING 时记录订单
+		if slot.SlotStatus == SlotStatusPending {
+			slot.OrderID = ord.OrderID
+			slot.ClientOID = ord.ClientOrderID
+			slot.OrderSide = "SELL"
+			slot.OrderStatus = OrderStatusPlaced
+			slot.OrderPrice = 0
+			slot.OrderCreatedAt = time.Now()
+			slot.SlotStatus = SlotStatusLocked
+		}
+		slot.mu.Unlock()
+
+		flattened++
+		positionLog.Warn("⏰ [持仓超时] 槽位 %s 持有 %v（上限 %v），市价平仓 %.6f (订单ID: %d)",
+			formatPrice(slotPrice, spm.priceDecimals), held.Round(time.Second), maxHold, qty, ord.OrderID)
+	}
+	return flattened
+}
@@ -0,0 +1,142 @@
+package position
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"opensqt/event"
+)
+
+// StartDustSweep 启动残余持仓清理协程（trading.dust_sweep）
+func (spm *SuperPositionManager) StartDustSweep(ctx context.Context) {
+	sweep := spm.config.Trading.DustSweep
+	if !sweep.Enabled {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(sweep.CheckInterval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				spm.SweepDust()
+			}
+		}
+	}()
+	positionLog.Info("✅ 残余持仓清理已启动 (周期: %ds, 动作: %s)", sweep.CheckInterval, sweep.Action)
+}
+
+// SweepDust 检测名义价值低于阈值、网格卖单无法卖出的槽位持仓
+// action=market 时把残余合并到价格最低的残余槽位，以只减仓市价单一次卖出，成交推送按普通卖单成交清空槽位；
+// 交易所拒绝（如低于市价单最小数量）时保留并记录。action=flag 时只记录。返回检测到的残余槽位数
+func (spm *SuperPositionManager) SweepDust() int {
+	if !spm.isInitialized.Load() {
+		return 0
+	}
+	sweep := spm.config.Trading.DustSweep
+	threshold := sweep.MaxNotional
+	if threshold <= 0 {
+		threshold = spm.minOrderValue()
+	}
+
+	// 持有全局锁，期间 AdjustOrders 不会为这些槽位挂单
+	spm.mu.Lock()
+	defer spm.mu.Unlock()
+
+	priceInterval := spm.GetPriceInterval()
+	var dust []float64
+	var totalQty float64
+	spm.slots.Range(func(key, value interface{}) bool {
+		slotPrice := key.(float64)
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		defer slot.mu.RUnlock()
+
+		if slot.PositionStatus != PositionStatusFilled || slot.PositionQty <= 0 ||
+			slot.SlotStatus != SlotStatusFree || slot.OrderID != 0 || slot.ClientOID != "" {
+			return true
+		}
+		if spm.meetsMinNotional(spm.sellPriceFor(slotPrice, priceInterval), slot.PositionQty, threshold) {
+			return true
+		}
+		dust = append(dust, slotPrice)
+		totalQty += slot.PositionQty
+		return true
+	})
+	if len(dust) == 0 {
+		return 0
+	}
+	sort.Float64s(dust)
+
+	marketPrice, _ := spm.lastMarketPrice.Load().(float64)
+	positionLog.Warn("🧹 [残余清理] 检测到 %d 个残余槽位，合计 %.6f（约 %.4f，阈值 %.2f），最低槽位 %s",
+		len(dust), totalQty, totalQty*marketPrice, threshold, formatPrice(dust[0], spm.priceDecimals))
+	if sweep.Action != "market" {
+		return len(dust)
+	}
+
+	qty := roundPrice(totalQty, spm.quantityDecimals)
+	if qty <= 0 {
+		positionLog.Warn("🧹 [残余清理] 合计数量 %.6f 低于数量精度 %d，无法下单，保留残余", totalQty, spm.quantityDecimals)
+		return len(dust)
+	}
+
+	// 合并到价格最低的残余槽位，其余槽位清空
+	target := spm.getOrCreateSlot(dust[0])
+	target.mu.Lock()
+	for _, slotPrice := range dust[1:] {
+		slot := spm.getOrCreateSlot(slotPrice)
+		slot.mu.Lock()
+		target.PositionCost = target.positionCost() + slot.positionCost()
+		target.PositionQty += slot.PositionQty
+		if !slot.PositionOpenedAt.IsZero() && (target.PositionOpenedAt.IsZero() || slot.PositionOpenedAt.Before(target.PositionOpenedAt)) {
+			target.PositionOpenedAt = slot.PositionOpenedAt
+		}
+		slot.PositionQty = 0
+		slot.PositionCost = 0
+		slot.PositionStatus = PositionStatusEmpty
+		slot.PositionOpenedAt = time.Time{}
+		slot.mu.Unlock()
+	}
+	target.SlotStatus = SlotStatusPending
+	target.mu.Unlock()
+
+	clientOID := spm.generateClientOrderID(dust[0], "SELL")
+	ord, err := spm.executor.PlaceOrder(&OrderRequest{
+		Symbol:        spm.config.Trading.Symbol,
+		Side:          "SELL",
+		Quantity:      qty,
+		PriceDecimals: spm.priceDecimals,
+		ReduceOnly:    true,
+		Market:        true,
+		ClientOrderID: clientOID,
+		Reason:        event.OrderReasonDustSweep,
+	})
+
+	target.mu.Lock()
+	defer target.mu.Unlock()
+	if err != nil {
+		if target.SlotStatus == SlotStatusPending {
+			target.SlotStatus = SlotStatusFree
+		}
+		positionLog.Warn("🧹 [残余清理] 市价卖出 %.6f 失败，残余已合并到槽位 %s 保留: %v",
+			qty, formatPrice(dust[0], spm.priceDecimals), err)
+		return len(dust)
+	}
+	// 成交推送可能先于下单返回到达，此时槽位已被更新，只在仍为 PENDING 时记录订单
+	if target.SlotStatus == SlotStatusPending {
+		target.OrderID = ord.OrderID
+		target.ClientOID = ord.ClientOrderID
+		target.OrderSide = "SELL"
+		target.OrderStatus = OrderStatusPlaced
+		target.OrderPrice = 0
+		target.OrderCreatedAt = time.Now()
+		target.SlotStatus = SlotStatusLocked
+	}
+	positionLog.Warn("🧹 [残余清理] 已合并 %d 个残余槽位到 %s，市价卖出 %.6f (订单ID: %d)",
+		len(dust), formatPrice(dust[0], spm.priceDecimals), qty, ord.OrderID)
+	return len(dust)
+}
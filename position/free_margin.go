@@ -0,0 +1,89 @@
+package position
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// freeMarginSample 一次可用保证金查询结果
+type freeMarginSample struct {
+	available float64 // 可用保证金
+	equity    float64 // 保证金余额（含未实现盈亏）
+	leverage  int
+}
+
+// StartFreeMarginGuard 启动可用保证金下限查询协程（trading.min_free_margin_percent）
+// 启动时先查询一次，之后按 free_margin_check_interval 定期刷新，AdjustOrders 据此限制新增买单
+func (spm *SuperPositionManager) StartFreeMarginGuard(ctx context.Context) {
+	minPercent := spm.config.Trading.MinFreeMarginPercent
+	if minPercent <= 0 {
+		return
+	}
+	interval := spm.config.Trading.FreeMarginCheckInterval
+	spm.UpdateFreeMargin()
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				spm.UpdateFreeMargin()
+			}
+		}
+	}()
+	positionLog.Info("✅ 可用保证金下限已启动 (下限: %.1f%%, 查询间隔: %ds)", minPercent, interval)
+}
+
+// UpdateFreeMargin 查询可用保证金和保证金余额，供之后的订单调整检查（查询失败时保留上一次结果）
+func (spm *SuperPositionManager) UpdateFreeMargin() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 查询期间新增的买单可能未计入本次结果，先清零计数，宁可多扣也不少扣
+	placed := spm.buysSinceMarginSample.Swap(0)
+	available, equity, leverage, err := spm.exchange.GetMarginBalance(ctx, spm.config.Trading.Symbol)
+	if err != nil {
+		spm.buysSinceMarginSample.Add(placed)
+		positionLog.Warn("⚠️ [保证金下限] 获取可用保证金失败，沿用上次结果: %v", err)
+		return
+	}
+	if leverage <= 0 {
+		leverage = spm.config.Trading.MaxLeverage
+	}
+	spm.freeMargin.Store(&freeMarginSample{available: available, equity: equity, leverage: leverage})
+	positionLog.Debug("🔍 [保证金下限] 可用保证金 %.2f / 保证金余额 %.2f = %.1f%% (下限 %.1f%%)",
+		available, equity, spm.FreeMarginPercent(), spm.config.Trading.MinFreeMarginPercent)
+}
+
+// freeMarginBuyLimit 可用保证金下限允许新增的买单数（-1 表示不限制）
+// 新增买单后 (可用保证金 - 新增买单占用的保证金) / 保证金余额 不得低于 min_free_margin_percent，
+// 可用保证金按上次查询结果扣除此后已新增买单占用的保证金
+func (spm *SuperPositionManager) freeMarginBuyLimit() int {
+	sample := spm.freeMargin.Load()
+	minPercent := spm.config.Trading.MinFreeMarginPercent
+	if sample == nil || minPercent <= 0 {
+		return -1
+	}
+	if sample.equity <= 0 {
+		return 0
+	}
+
+	marginPerOrder := spm.GetOrderQuantity() / float64(sample.leverage)
+	available := sample.available - float64(spm.buysSinceMarginSample.Load())*marginPerOrder
+	reserve := sample.equity * minPercent / 100
+	return max(int(math.Floor((available-reserve)/marginPerOrder)), 0)
+}
+
+// FreeMarginPercent 当前可用保证金占保证金余额的百分比（扣除上次查询后新增买单占用的保证金，未启用或尚未查询时返回0）
+func (spm *SuperPositionManager) FreeMarginPercent() float64 {
+	sample := spm.freeMargin.Load()
+	if sample == nil || sample.equity <= 0 {
+		return 0
+	}
+	marginPerOrder := spm.GetOrderQuantity() / float64(sample.leverage)
+	available := sample.available - float64(spm.buysSinceMarginSample.Load())*marginPerOrder
+	return available / sample.equity * 100
+}
@@ -0,0 +1,107 @@
+package position
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"opensqt/event"
+)
+
+// StartGridAudit 启动网格对齐自检协程（trading.grid_audit）
+func (spm *SuperPositionManager) StartGridAudit(ctx context.Context) {
+	if !spm.config.Trading.GridAudit.Enabled {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(spm.config.Trading.GridAudit.CheckInterval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				spm.AuditGrid()
+			}
+		}
+	}()
+	positionLog.Info("✅ 网格对齐自检已启动 (周期: %ds, 容差: %d 个最小价格单位)",
+		spm.config.Trading.GridAudit.CheckInterval, spm.config.Trading.GridAudit.ToleranceTicks)
+}
+
+// AuditGrid 比对挂单价格与理论网格，撤销偏离超过容差的挂单，由 AdjustOrders 按理论价格重新挂出
+// 买单理论价格为离订单价格最近的网格价格（锚点 + N × 价格间隔），
+// 卖单理论价格为 槽位价格 + 当前价格间隔（不低于手续费保本价），启用库存偏斜时收紧后的价格同样视为在网格上；
+// 部分成交和撤单中的订单不处理
+// 返回重新对齐的挂单数量
+func (spm *SuperPositionManager) AuditGrid() int {
+	if !spm.isInitialized.Load() {
+		return 0
+	}
+
+	spm.mu.Lock()
+	priceInterval := spm.GetPriceInterval()
+	decimals := spm.priceDecimals
+	tick := math.Pow(10, -float64(decimals))
+	// 半个最小价格单位吸收浮点误差
+	tolerance := float64(spm.config.Trading.GridAudit.ToleranceTicks)*tick + tick/2
+	// 库存偏斜随持仓变化，卖单价格在 [最大收紧后的价格, 理论价格] 之间都是正常挂单
+	var maxSkew float64
+	if skew := spm.config.Trading.InventorySkew; skew.Enabled {
+		maxSkew = skew.SkewStrength * priceInterval
+	}
+
+	var orderIDs []int64
+	var buyDrifted, sellDrifted int
+	spm.slots.Range(func(key, value interface{}) bool {
+		slotPrice := key.(float64)
+		slot := value.(*InventorySlot)
+		slot.mu.Lock()
+		defer slot.mu.Unlock()
+
+		if slot.OrderID == 0 || slot.OrderFilledQty > 0 ||
+			(slot.OrderStatus != OrderStatusPlaced && slot.OrderStatus != OrderStatusConfirmed) {
+			return true
+		}
+
+		var ideal float64
+		if slot.OrderSide == "BUY" {
+			ideal = spm.findNearestGridPrice(slot.OrderPrice)
+		} else {
+			ideal = spm.sellPriceFor(slotPrice, priceInterval)
+			if maxSkew > 0 && slot.OrderPrice <= ideal+tolerance &&
+				slot.OrderPrice >= spm.skewedSellPrice(slotPrice, priceInterval, maxSkew)-tolerance {
+				return true
+			}
+		}
+		if math.Abs(slot.OrderPrice-ideal) <= tolerance {
+			return true
+		}
+
+		positionLog.Debug("📐 [网格自检] 槽位 %s: %s 单 %s 偏离理论价格 %s (订单ID: %d)",
+			formatPrice(slotPrice, decimals), slot.OrderSide, formatPrice(slot.OrderPrice, decimals),
+			formatPrice(ideal, decimals), slot.OrderID)
+		if slot.OrderSide == "BUY" {
+			buyDrifted++
+		} else {
+			slot.FeeRepricing = true // 主动撤单，撤单回报不计入 PostOnly 失败
+			sellDrifted++
+		}
+		orderIDs = append(orderIDs, slot.OrderID)
+		return true
+	})
+	spm.mu.Unlock()
+
+	if len(orderIDs) == 0 {
+		positionLog.Debug("✅ [网格自检] 挂单均在理论网格上")
+		return 0
+	}
+
+	if err := spm.executor.BatchCancelOrders(orderIDs, event.OrderReasonRepair); err != nil {
+		positionLog.Error("❌ [网格自检] 撤销 %d 个偏离网格的挂单失败: %v", len(orderIDs), err)
+		return 0
+	}
+	positionLog.Warn("📐 [网格自检] 重新对齐 %d 个偏离网格的挂单 (买单 %d, 卖单 %d)，等待按理论价格重新挂出",
+		len(orderIDs), buyDrifted, sellDrifted)
+	return len(orderIDs)
+}
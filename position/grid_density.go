@@ -0,0 +1,23 @@
+package position
+
+import (
+	"fmt"
+	"math"
+)
+
+// GridInterval 按网格密度计算价格间隔（trading.grid_range_percent / grid_levels）
+// grid_levels 层买单均匀覆盖当前价格下方 grid_range_percent% 的区间，间隔按价格精度四舍五入，至少为一个最小价格单位
+func GridInterval(currentPrice, rangePercent float64, levels, priceDecimals int) (float64, error) {
+	if currentPrice <= 0 {
+		return 0, fmt.Errorf("当前价格无效: %f", currentPrice)
+	}
+	if levels <= 0 {
+		return 0, fmt.Errorf("网格层数必须大于0")
+	}
+	tick := math.Pow(10, -float64(priceDecimals))
+	interval := roundPrice(currentPrice*rangePercent/100/float64(levels), priceDecimals)
+	if interval < tick {
+		interval = tick
+	}
+	return interval, nil
+}
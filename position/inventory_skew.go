@@ -0,0 +1,51 @@
+package position
+
+import "math"
+
+// inventorySkewShift 库存偏斜距离（trading.inventory_skew，未启用时为0）
+// (净持仓 - target_inventory) / (max_inventory - target_inventory) 限制在 0~1，乘以 skew_strength 个价格间隔
+func (spm *SuperPositionManager) inventorySkewShift(position, priceInterval float64) float64 {
+	skew := spm.config.Trading.InventorySkew
+	if !skew.Enabled || skew.MaxInventory <= skew.TargetInventory {
+		return 0
+	}
+	ratio := (position - skew.TargetInventory) / (skew.MaxInventory - skew.TargetInventory)
+	ratio = math.Min(math.Max(ratio, 0), 1)
+	return roundPrice(ratio*skew.SkewStrength*priceInterval, spm.priceDecimals)
+}
+
+// skewedSellPrice 库存偏斜收紧后的卖单价格：槽位卖价 - 偏斜距离，不低于手续费保本价 + 1 个最小价格单位，且不高于槽位卖价
+func (spm *SuperPositionManager) skewedSellPrice(slotPrice, priceInterval, shift float64) float64 {
+	sellPrice := spm.sellPriceFor(slotPrice, priceInterval)
+	if shift <= 0 {
+		return sellPrice
+	}
+	factor := math.Pow(10, float64(spm.priceDecimals))
+	floor := slotPrice
+	if feeRate, _ := spm.feeRate.Load().(float64); feeRate > 0 && feeRate < 1 {
+		floor = slotPrice * (1 + feeRate) / (1 - feeRate)
+	}
+	floor = math.Ceil(floor*factor-1e-9)/factor + 1/factor
+
+	tightened := roundPrice(sellPrice-shift, spm.priceDecimals)
+	if tightened < floor {
+		tightened = roundPrice(floor, spm.priceDecimals)
+	}
+	return math.Min(tightened, sellPrice)
+}
+
+// logInventorySkew 偏斜开始和解除时输出日志（调用前必须持有 mu）
+func (spm *SuperPositionManager) logInventorySkew(position, shift float64) {
+	switch {
+	case shift > 0 && !spm.inventorySkewed:
+		positionLog.Info("⚖️ [库存偏斜] 净持仓 %.*f 超过目标库存 %.*f，买单下移、卖单收紧 %s",
+			spm.quantityDecimals, position, spm.quantityDecimals, spm.config.Trading.InventorySkew.TargetInventory,
+			formatPrice(shift, spm.priceDecimals))
+		spm.inventorySkewed = true
+	case shift <= 0 && spm.inventorySkewed:
+		positionLog.Info("⚖️ [库存偏斜] 净持仓 %.*f 回到目标库存以内，恢复正常报价", spm.quantityDecimals, position)
+		spm.inventorySkewed = false
+	case shift > 0:
+		positionLog.Debug("⚖️ [库存偏斜] 净持仓 %.*f，偏斜距离 %s", spm.quantityDecimals, position, formatPrice(shift, spm.priceDecimals))
+	}
+}
@@ -0,0 +1,62 @@
+package position
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// buyLadder 锚点 100、当前价格 100.4 时一次 AdjustOrders 挂出的买单价格（从高到低）
+func buyLadder(t *testing.T, skewEnabled bool, holdings float64) []float64 {
+	t.Helper()
+	cfg := testConfig()
+	cfg.Trading.InventorySkew.Enabled = skewEnabled
+	cfg.Trading.InventorySkew.TargetInventory = 0.5
+	cfg.Trading.InventorySkew.MaxInventory = 1.5
+	cfg.Trading.InventorySkew.SkewStrength = 2
+	spm, executor := newTestManager(cfg)
+	if err := spm.Initialize(100, "100"); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if holdings > 0 {
+		// 远低于当前价格的持仓槽位（卖单不在卖单窗口内，不影响买单）
+		slot := spm.getOrCreateSlot(80)
+		slot.PositionStatus = PositionStatusFilled
+		slot.PositionQty = holdings
+	}
+	if err := spm.AdjustOrders(100.4); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+
+	var prices []float64
+	for _, req := range executor.placed {
+		if req.Side == "BUY" {
+			prices = append(prices, req.Price)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(prices)))
+	return prices
+}
+
+func TestInventorySkewShiftsBuyLadder(t *testing.T) {
+	// 买单窗口 5 层；偏斜时买单上限下移，窗口向下补足被跳过的层数，层数不变
+	tests := []struct {
+		name     string
+		enabled  bool
+		holdings float64
+		want     []float64
+	}{
+		{"未启用", false, 1.5, []float64{100, 99, 98, 97, 96}},
+		{"净持仓不超过目标库存", true, 0.5, []float64{100, 99, 98, 97, 96}},
+		{"超过目标库存一半：下移 1 个间隔", true, 1.0, []float64{99, 98, 97, 96, 95}},
+		{"达到最大库存：下移 skew_strength 个间隔", true, 1.5, []float64{98, 97, 96, 95, 94}},
+		{"超过最大库存：偏斜封顶", true, 3.0, []float64{98, 97, 96, 95, 94}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buyLadder(t, tt.enabled, tt.holdings); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("买单阶梯应为 %v，实际 %v", tt.want, got)
+			}
+		})
+	}
+}
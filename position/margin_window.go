@@ -0,0 +1,120 @@
+package position
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"opensqt/event"
+)
+
+// StartMarginWindow 启动保证金窗口协程（trading.margin_window）
+func (spm *SuperPositionManager) StartMarginWindow(ctx context.Context) {
+	mw := spm.config.Trading.MarginWindow
+	if !mw.Enabled {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(mw.CheckInterval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				spm.UpdateMarginWindow()
+			}
+		}
+	}()
+	buffer := "两个完整买单窗口占用的保证金"
+	if mw.FreeMarginBuffer > 0 {
+		buffer = formatPrice(mw.FreeMarginBuffer, 2)
+	}
+	positionLog.Info("✅ 保证金窗口已启动 (周期: %ds, 可用保证金缓冲: %s, 最小买单层数: %d)",
+		mw.CheckInterval, buffer, mw.MinDepth)
+}
+
+// UpdateMarginWindow 按可用保证金重新计算买单窗口，返回保证金允许的买单层数（0表示不限制）
+// 可支配保证金 = 可用保证金 + 挂单中买单冻结的保证金（缩小窗口时撤销买单释放）。
+// 可支配保证金不低于缓冲时挂满窗口；低于缓冲时按 可支配保证金 / 缓冲 的比例缩小，且不超过可支配保证金能覆盖的层数，
+// 至少保留 min_depth 层。持仓越多可用保证金越少，窗口随之变浅；卖单成交释放保证金后下次检查时恢复
+func (spm *SuperPositionManager) UpdateMarginWindow() int {
+	if !spm.isInitialized.Load() {
+		return 0
+	}
+	mw := spm.config.Trading.MarginWindow
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	available, leverage, err := spm.exchange.GetMarginInfo(ctx, spm.config.Trading.Symbol)
+	if err != nil {
+		positionLog.Warn("⚠️ [保证金窗口] 获取可用保证金失败，保持当前窗口: %v", err)
+		return int(spm.marginBuyLimit.Load())
+	}
+	if leverage <= 0 {
+		leverage = spm.config.Trading.MaxLeverage
+	}
+
+	windowSize := spm.GetBuyWindowSize()
+	marginPerOrder := spm.GetOrderQuantity() / float64(leverage)
+	free := available + float64(spm.activeBuyOrders())*marginPerOrder
+	buffer := mw.FreeMarginBuffer
+	if buffer <= 0 {
+		buffer = 2 * float64(windowSize) * marginPerOrder
+	}
+
+	limit := 0
+	if free < buffer {
+		limit = int(math.Floor(float64(windowSize) * free / buffer))
+		if affordable := int(free / marginPerOrder); affordable < limit {
+			limit = affordable
+		}
+		if limit < mw.MinDepth {
+			limit = mw.MinDepth
+		}
+		if limit >= windowSize {
+			limit = 0
+		}
+	}
+
+	previous := int(spm.marginBuyLimit.Swap(int64(limit)))
+	switch {
+	case limit == previous:
+		positionLog.Debug("🔍 [保证金窗口] 可支配保证金 %.2f (缓冲 %.2f)，买单窗口 %d 层", free, buffer, spm.effectiveBuyWindow())
+	case limit == 0:
+		positionLog.Info("✅ [保证金窗口] 可支配保证金 %.2f ≥ 缓冲 %.2f，恢复完整买单窗口 %d 层 (当前生效 %d 层)",
+			free, buffer, windowSize, spm.effectiveBuyWindow())
+	case limit > marginLimitLabel(previous, windowSize):
+		positionLog.Info("📈 [保证金窗口] 可支配保证金 %.2f 回升 (缓冲 %.2f)，买单窗口 %d -> %d 层 (当前生效 %d 层)",
+			free, buffer, marginLimitLabel(previous, windowSize), limit, spm.effectiveBuyWindow())
+	default:
+		positionLog.Warn("📉 [保证金窗口] 可支配保证金 %.2f < 缓冲 %.2f (每层 %.2f, %dx杠杆)，买单窗口 %d -> %d 层 (当前生效 %d 层)",
+			free, buffer, marginPerOrder, leverage, marginLimitLabel(previous, windowSize), limit, spm.effectiveBuyWindow())
+		// 窗口外的买单仍冻结保证金，撤销后由 AdjustOrders 只挂离价格最近的若干层
+		spm.CancelAllBuyOrders(event.OrderReasonMargin)
+	}
+	return limit
+}
+
+// activeBuyOrders 挂单中的买单数量
+func (spm *SuperPositionManager) activeBuyOrders() int {
+	count := 0
+	spm.slots.Range(func(key, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		if slot.OrderSide == "BUY" && (slot.OrderID != 0 || slot.SlotStatus == SlotStatusLocked) {
+			count++
+		}
+		slot.mu.RUnlock()
+		return true
+	})
+	return count
+}
+
+// marginLimitLabel 保证金限制对应的买单层数（0表示不限制，即完整窗口）
+func marginLimitLabel(limit, windowSize int) int {
+	if limit <= 0 {
+		return windowSize
+	}
+	return limit
+}
@@ -0,0 +1,138 @@
+package position
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// OrderMapping 订单与槽位映射（system.order_map_file），用于排查槽位记账问题时与交易所挂单比对
+type OrderMapping struct {
+	Symbol        string         `json:"symbol"`
+	ExportedAt    time.Time      `json:"exported_at"`
+	AnchorPrice   float64        `json:"anchor_price"`
+	PriceInterval float64        `json:"price_interval"`
+	Orders        []OrderSlotRef `json:"orders"`        // 有订单ID或ClientOID的槽位（按槽位价格从高到低）
+	Inventory     []OrderSlotRef `json:"inventory"`     // 有持仓但没有挂单的槽位
+	PendingSlots  int            `json:"pending_slots"` // 下单中（PENDING）的槽位数
+	LockedSlots   int            `json:"locked_slots"`  // 撤单中（LOCKED）的槽位数
+}
+
+// OrderSlotRef 单个槽位上的订单及持仓
+type OrderSlotRef struct {
+	OrderID        int64     `json:"order_id,omitempty"`
+	ClientOrderID  string    `json:"client_order_id,omitempty"`
+	SlotPrice      float64   `json:"slot_price"`
+	Side           string    `json:"side,omitempty"`
+	OrderPrice     float64   `json:"order_price,omitempty"`
+	OrderStatus    string    `json:"order_status"`
+	FilledQty      float64   `json:"filled_qty,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	SlotStatus     string    `json:"slot_status"`
+	PositionStatus string    `json:"position_status"`
+	PositionQty    float64   `json:"position_qty"`
+}
+
+// GetOrderMapping 获取当前订单与槽位的映射
+func (spm *SuperPositionManager) GetOrderMapping() OrderMapping {
+	spm.mu.RLock()
+	mapping := OrderMapping{
+		Symbol:        spm.config.Trading.Symbol,
+		ExportedAt:    time.Now(),
+		AnchorPrice:   spm.anchorPrice,
+		PriceInterval: spm.GetPriceInterval(),
+		Orders:        []OrderSlotRef{},
+		Inventory:     []OrderSlotRef{},
+	}
+	spm.mu.RUnlock()
+
+	spm.slots.Range(func(key, value interface{}) bool {
+		slotPrice := key.(float64)
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		defer slot.mu.RUnlock()
+
+		switch slot.SlotStatus {
+		case SlotStatusPending:
+			mapping.PendingSlots++
+		case SlotStatusLocked:
+			mapping.LockedSlots++
+		}
+
+		ref := OrderSlotRef{
+			OrderID:        slot.OrderID,
+			ClientOrderID:  slot.ClientOID,
+			SlotPrice:      slotPrice,
+			Side:           slot.OrderSide,
+			OrderPrice:     slot.OrderPrice,
+			OrderStatus:    slot.OrderStatus,
+			FilledQty:      slot.OrderFilledQty,
+			CreatedAt:      slot.OrderCreatedAt,
+			SlotStatus:     slot.SlotStatus,
+			PositionStatus: slot.PositionStatus,
+			PositionQty:    slot.PositionQty,
+		}
+		if slot.OrderID != 0 || slot.ClientOID != "" {
+			mapping.Orders = append(mapping.Orders, ref)
+		} else if slot.PositionQty > 0 {
+			mapping.Inventory = append(mapping.Inventory, ref)
+		}
+		return true
+	})
+
+	sort.Slice(mapping.Orders, func(i, j int) bool { return mapping.Orders[i].SlotPrice > mapping.Orders[j].SlotPrice })
+	sort.Slice(mapping.Inventory, func(i, j int) bool { return mapping.Inventory[i].SlotPrice > mapping.Inventory[j].SlotPrice })
+	return mapping
+}
+
+// ExportOrderMapping 将订单与槽位映射写入 system.order_map_file（先写临时文件再替换，避免读到半个文件）
+// 返回写入的文件路径和订单数量
+func (spm *SuperPositionManager) ExportOrderMapping() (string, int, error) {
+	path := spm.config.System.OrderMapFile
+	if path == "" {
+		return "", 0, fmt.Errorf("未配置 system.order_map_file")
+	}
+	mapping := spm.GetOrderMapping()
+	data, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return "", 0, fmt.Errorf("序列化订单映射失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", 0, fmt.Errorf("创建目录失败: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return "", 0, fmt.Errorf("写入订单映射失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", 0, fmt.Errorf("写入订单映射失败: %w", err)
+	}
+	return path, len(mapping.Orders), nil
+}
+
+// StartOrderMapExport 启动定期导出订单与槽位映射（system.order_map_interval）
+func (spm *SuperPositionManager) StartOrderMapExport(ctx context.Context) {
+	interval := spm.config.System.OrderMapInterval
+	if spm.config.System.OrderMapFile == "" || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, _, err := spm.ExportOrderMapping(); err != nil {
+					positionLog.Warn("⚠️ [订单映射] 定期导出失败: %v", err)
+				}
+			}
+		}
+	}()
+	positionLog.Info("✅ 订单映射定期导出已启动 (周期: %ds, 文件: %s)", interval, spm.config.System.OrderMapFile)
+}
@@ -0,0 +1,95 @@
+package position
+
+import "time"
+
+// orderSequence 单个订单已处理推送的进度（trading.order_update_ordering = update_time）
+type orderSequence struct {
+	updateTime int64     // 已处理推送中最大的 UpdateTime（毫秒，0 表示交易所未提供）
+	terminal   bool      // 已处理过终态推送（FILLED / CANCELED / EXPIRED / REJECTED）
+	seenAt     time.Time // 最近一次处理推送的本地时间（用于清理）
+}
+
+const (
+	// 订单进度记录超过该数量时清理 orderSequenceRetention 内没有新推送的订单
+	orderSequencePruneSize = 2048
+	orderSequenceRetention = 10 * time.Minute
+
+	// 推送时间与本机时钟相差超过阈值时警告（每分钟最多一次）
+	clockDriftWarnThreshold = 5 * time.Second
+	clockDriftWarnInterval  = time.Minute
+)
+
+// isTerminalOrderStatus 是否为订单终态
+func isTerminalOrderStatus(status string) bool {
+	switch status {
+	case "FILLED", "CANCELED", "EXPIRED", "REJECTED":
+		return true
+	}
+	return false
+}
+
+// acceptOrderUpdate 按订单（ClientOrderID）单调处理推送，返回 false 表示应忽略该推送（调用前必须持有对应槽位的 slot.mu）
+// UpdateTime 早于已处理推送的视为迟到的旧推送；订单已处理过终态推送后，之后的推送（含重复的终态推送）都忽略，
+// 避免槽位清空后被旧推送恢复成挂单或重复计入成交。交易所未提供 UpdateTime（为0）时只按终态判断
+func (spm *SuperPositionManager) acceptOrderUpdate(update OrderUpdate) bool {
+	if spm.config.Trading.OrderUpdateOrdering == "arrival" {
+		return true
+	}
+	spm.checkClockDrift(update.UpdateTime)
+
+	spm.orderSeqMu.Lock()
+	defer spm.orderSeqMu.Unlock()
+
+	now := time.Now()
+	seq, ok := spm.orderSeqs[update.ClientOrderID]
+	if ok {
+		switch {
+		case seq.terminal:
+			positionLog.Info("⏮️ [迟到推送] 订单 %d (%s) 已结束，忽略之后的 %s 推送 (UpdateTime: %d)",
+				update.OrderID, update.ClientOrderID, update.Status, update.UpdateTime)
+			return false
+		case update.UpdateTime > 0 && update.UpdateTime < seq.updateTime:
+			positionLog.Info("⏮️ [迟到推送] 订单 %d (%s) 的 %s 推送早于已处理的推送 (%d < %d)，忽略",
+				update.OrderID, update.ClientOrderID, update.Status, update.UpdateTime, seq.updateTime)
+			return false
+		}
+	} else {
+		if len(spm.orderSeqs) >= orderSequencePruneSize {
+			for clientOID, s := range spm.orderSeqs {
+				if now.Sub(s.seenAt) > orderSequenceRetention {
+					delete(spm.orderSeqs, clientOID)
+				}
+			}
+		}
+		seq = &orderSequence{}
+		spm.orderSeqs[update.ClientOrderID] = seq
+	}
+
+	if update.UpdateTime > seq.updateTime {
+		seq.updateTime = update.UpdateTime
+	}
+	seq.terminal = isTerminalOrderStatus(update.Status)
+	seq.seenAt = now
+	return true
+}
+
+// checkClockDrift 推送的 UpdateTime 与本机时钟相差过大时警告（按 UpdateTime 排序依赖两者大致同步）
+func (spm *SuperPositionManager) checkClockDrift(updateTime int64) {
+	if updateTime <= 0 {
+		return
+	}
+	drift := time.Since(time.UnixMilli(updateTime))
+	if drift < 0 {
+		drift = -drift
+	}
+	if drift <= clockDriftWarnThreshold {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := spm.lastDriftWarn.Load()
+	if now-last < int64(clockDriftWarnInterval) || !spm.lastDriftWarn.CompareAndSwap(last, now) {
+		return
+	}
+	positionLog.Warn("⏰ [时钟偏差] 订单推送时间与本机时钟相差 %v（推送延迟或本机时间不同步），乱序判断依赖交易所时间，请检查本机时间同步",
+		drift.Round(time.Millisecond))
+}
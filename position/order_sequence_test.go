@@ -0,0 +1,99 @@
+package position
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAcceptOrderUpdateOrdering(t *testing.T) {
+	spm, _ := newTestManager(testConfig())
+	now := time.Now().UnixMilli()
+	update := func(status string, updateTime int64) OrderUpdate {
+		return OrderUpdate{OrderID: 1, ClientOrderID: "c1", Status: status, UpdateTime: updateTime}
+	}
+
+	steps := []struct {
+		name   string
+		update OrderUpdate
+		accept bool
+	}{
+		{"首个推送", update("NEW", now), true},
+		{"较新的部分成交", update("PARTIALLY_FILLED", now+200), true},
+		{"早于已处理推送的 NEW", update("NEW", now+100), false},
+		{"UpdateTime 相同的推送", update("PARTIALLY_FILLED", now+200), true},
+		{"未提供 UpdateTime", update("PARTIALLY_FILLED", 0), true},
+		{"终态推送", update("FILLED", now+300), true},
+		{"终态之后的 NEW（UpdateTime 更新）", update("NEW", now+400), false},
+		{"重复的终态推送", update("FILLED", now+300), false},
+		{"终态之后未提供 UpdateTime 的推送", update("CANCELED", 0), false},
+	}
+	for _, step := range steps {
+		if got := spm.acceptOrderUpdate(step.update); got != step.accept {
+			t.Fatalf("%s: 应返回 %v，实际 %v", step.name, step.accept, got)
+		}
+	}
+
+	// 其他订单不受影响
+	if !spm.acceptOrderUpdate(OrderUpdate{OrderID: 2, ClientOrderID: "c2", Status: "NEW", UpdateTime: now}) {
+		t.Fatalf("其他订单的推送应被接受")
+	}
+}
+
+func TestAcceptOrderUpdateArrivalMode(t *testing.T) {
+	cfg := testConfig()
+	cfg.Trading.OrderUpdateOrdering = "arrival"
+	spm, _ := newTestManager(cfg)
+
+	for _, u := range []OrderUpdate{
+		{ClientOrderID: "c1", Status: "FILLED", UpdateTime: 2000},
+		{ClientOrderID: "c1", Status: "NEW", UpdateTime: 1000},
+	} {
+		if !spm.acceptOrderUpdate(u) {
+			t.Fatalf("arrival 模式应按到达顺序处理所有推送: %+v", u)
+		}
+	}
+}
+
+func TestAcceptOrderUpdatePrune(t *testing.T) {
+	spm, _ := newTestManager(testConfig())
+	stale := time.Now().Add(-orderSequenceRetention - time.Minute)
+	for i := 0; i < orderSequencePruneSize; i++ {
+		seenAt := stale
+		if i%2 == 0 {
+			seenAt = time.Now() // 一半订单最近仍有推送
+		}
+		spm.orderSeqs[fmt.Sprintf("old-%d", i)] = &orderSequence{updateTime: 1000, terminal: true, seenAt: seenAt}
+	}
+
+	if !spm.acceptOrderUpdate(OrderUpdate{ClientOrderID: "new", Status: "NEW"}) {
+		t.Fatalf("新订单的推送应被接受")
+	}
+	if got, want := len(spm.orderSeqs), orderSequencePruneSize/2+1; got != want {
+		t.Fatalf("超过 %d 条后应清理 %v 内没有推送的订单，剩余应为 %d，实际 %d",
+			orderSequencePruneSize, orderSequenceRetention, want, got)
+	}
+	if _, ok := spm.orderSeqs["old-1"]; ok {
+		t.Fatalf("过期的订单记录应被清理")
+	}
+	if spm.acceptOrderUpdate(OrderUpdate{ClientOrderID: "old-0", Status: "NEW"}) {
+		t.Fatalf("未过期的终态记录应保留，之后的推送仍应忽略")
+	}
+}
+
+func TestOnOrderUpdateIgnoresLateNewAfterFilled(t *testing.T) {
+	spm, _ := newTestManager(testConfig())
+	clientOID := spm.generateClientOrderID(100, "BUY")
+	now := time.Now().UnixMilli()
+
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: clientOID, Status: "FILLED",
+		ExecutedQty: 0.5, Price: 100, Side: "BUY", UpdateTime: now + 100})
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: clientOID, Status: "NEW",
+		Price: 100, Side: "BUY", UpdateTime: now})
+
+	slot := spm.getOrCreateSlot(100)
+	if slot.OrderID != 0 || slot.OrderStatus != OrderStatusNotPlaced || slot.PositionQty != 0.5 {
+		t.Fatalf("迟到的 NEW 不应把已成交的槽位恢复成挂单，实际 OrderID=%d 状态=%s 持仓=%.4f",
+			slot.OrderID, slot.OrderStatus, slot.PositionQty)
+	}
+}
@@ -0,0 +1,182 @@
+package position
+
+import (
+	"math"
+	"time"
+
+	"opensqt/event"
+)
+
+// OnPrecisionChanged 交易所调整交易规则后更新精度（由 safety.SymbolInfoMonitor 回调）
+// 数量精度直接生效；价格精度变细时现有网格价格仍然合法，保持网格精度不变；
+// 价格精度变粗时锚点和价格间隔对齐到新的最小价格单位，撤销不在新网格上的挂单，
+// 撤单确认后将这些槽位的持仓合并到对齐后的价格，空槽位直接移除，由 AdjustOrders 按新网格重新挂单
+func (spm *SuperPositionManager) OnPrecisionChanged(priceDecimals, quantityDecimals int) {
+	gridChanged := false
+	defer func() {
+		if gridChanged {
+			spm.notifyGridChanged("价格精度调整")
+		}
+	}()
+	spm.mu.Lock()
+	defer spm.mu.Unlock()
+
+	oldPriceDecimals, oldQtyDecimals := spm.priceDecimals, spm.quantityDecimals
+	spm.quantityDecimals = quantityDecimals
+	spm.resetMinNotionalNotices()
+
+	if quantityDecimals != oldQtyDecimals {
+		positionLog.Warn("📏 [精度调整] 数量精度 %d -> %d，后续订单按新精度下单", oldQtyDecimals, quantityDecimals)
+	}
+	if priceDecimals >= oldPriceDecimals {
+		if priceDecimals > oldPriceDecimals {
+			positionLog.Info("📏 [精度调整] 价格精度 %d -> %d，现有网格价格仍然有效，保持网格精度 %d",
+				oldPriceDecimals, priceDecimals, oldPriceDecimals)
+		}
+		return
+	}
+
+	gridChanged = true
+	spm.priceDecimals = priceDecimals
+	oldAnchor := spm.anchorPrice
+	spm.anchorPrice = roundPrice(spm.anchorPrice, priceDecimals)
+	oldInterval := spm.GetPriceInterval()
+	interval := ceilToDecimals(oldInterval, priceDecimals)
+	spm.priceInterval.Store(interval)
+	if spm.config.Trading.LadderMode && (spm.anchorPrice != oldAnchor || interval != oldInterval) {
+		// 不在价格单位上的订单会被交易所拒绝，阶梯模式也只能对齐；对齐后阶梯仍固定在新锚点下方
+		positionLog.Warn("🪜 [阶梯模式] 价格精度变粗，买单阶梯必须对齐到新的最小价格单位: 锚点 %s -> %s, 价格间隔 %s -> %s",
+			formatPrice(oldAnchor, oldPriceDecimals), formatPrice(spm.anchorPrice, priceDecimals),
+			formatPrice(oldInterval, oldPriceDecimals), formatPrice(interval, priceDecimals))
+	}
+
+	// 不在新价格单位上的槽位挂单需要撤销；间隔变化时买单都不在新网格上，一并撤销
+	var orderIDs []int64
+	spm.slots.Range(func(key, value interface{}) bool {
+		price := key.(float64)
+		slot := value.(*InventorySlot)
+		slot.mu.Lock()
+		defer slot.mu.Unlock()
+
+		if slot.OrderID == 0 {
+			return true
+		}
+		offTick := roundPrice(price, priceDecimals) != price
+		if offTick || (interval != oldInterval && slot.OrderSide == "BUY") {
+			if slot.OrderSide == "SELL" {
+				slot.FeeRepricing = true // 主动撤单，撤单回报不计入 PostOnly 失败
+			}
+			orderIDs = append(orderIDs, slot.OrderID)
+		}
+		return true
+	})
+
+	positionLog.Warn("📏 [精度调整] 价格精度 %d -> %d，网格对齐到新的最小价格单位: 锚点 %s, 价格间隔 %s -> %s, 撤销 %d 个不在新网格上的挂单",
+		oldPriceDecimals, priceDecimals, formatPrice(spm.anchorPrice, priceDecimals),
+		formatPrice(oldInterval, oldPriceDecimals), formatPrice(interval, priceDecimals), len(orderIDs))
+
+	if len(orderIDs) > 0 {
+		if err := spm.executor.BatchCancelOrders(orderIDs, event.OrderReasonReprice); err != nil {
+			positionLog.Error("❌ [精度调整] 撤销 %d 个挂单失败: %v", len(orderIDs), err)
+		}
+		// 等待撤单推送更新槽位状态（持有全局锁，期间不会挂出新订单）
+		time.Sleep(2 * time.Second)
+	}
+
+	spm.resnapSlotsLocked()
+}
+
+// resnapSlotsLocked 将不在当前价格精度上的槽位对齐到最近的合法价格（调用前必须持有 mu）
+// 持仓合并到对齐后的槽位，空槽位直接移除；仍有挂单的槽位保持原价格
+func (spm *SuperPositionManager) resnapSlotsLocked() {
+	type movedPosition struct {
+		from, to float64
+		qty      float64
+		cost     float64
+		openedAt time.Time
+	}
+	var moved []movedPosition
+	removed, pending := 0, 0
+
+	spm.slots.Range(func(key, value interface{}) bool {
+		price := key.(float64)
+		snapped := roundPrice(price, spm.priceDecimals)
+		if snapped == price {
+			return true
+		}
+
+		slot := value.(*InventorySlot)
+		slot.mu.Lock()
+		defer slot.mu.Unlock()
+
+		if slot.OrderID != 0 || slot.ClientOID != "" || slot.SlotStatus != SlotStatusFree {
+			pending++
+			return true
+		}
+		spm.slots.Delete(price)
+		if slot.PositionQty > 0 {
+			moved = append(moved, movedPosition{from: price, to: snapped, qty: slot.PositionQty, cost: slot.positionCost(), openedAt: slot.PositionOpenedAt})
+		} else {
+			removed++
+		}
+		return true
+	})
+
+	for _, m := range moved {
+		target := spm.getOrCreateSlot(m.to)
+		target.mu.Lock()
+		target.PositionCost = target.positionCost() + m.cost
+		target.PositionQty += m.qty
+		target.PositionStatus = PositionStatusFilled
+		// 合并后的建仓时间取较早的一个
+		if target.PositionOpenedAt.IsZero() || (!m.openedAt.IsZero() && m.openedAt.Before(target.PositionOpenedAt)) {
+			target.PositionOpenedAt = m.openedAt
+		}
+		total := target.PositionQty
+		target.mu.Unlock()
+		positionLog.Info("📏 [精度调整] 槽位 %s -> %s: 持仓 %.6f 合并后 %.6f",
+			formatPrice(m.from, spm.orderIDDecimals), formatPrice(m.to, spm.priceDecimals), m.qty, total)
+	}
+
+	positionLog.Info("📏 [精度调整] 槽位对齐完成: 合并持仓 %d 个, 移除空槽位 %d 个", len(moved), removed)
+	if pending > 0 {
+		positionLog.Warn("⚠️ [精度调整] %d 个槽位的挂单尚未撤销，保持原价格（卖单价格已按新精度取整）", pending)
+	}
+}
+
+// minNotionalNoticeInterval 同一价格层的最小名义价值调整日志间隔
+const minNotionalNoticeInterval = time.Hour
+
+// shouldNoticeMinNotional 价格层是否需要输出最小名义价值调整日志（同一价格层每 minNotionalNoticeInterval 最多一次）
+func (spm *SuperPositionManager) shouldNoticeMinNotional(price float64) bool {
+	now := time.Now().UnixNano()
+	if last, ok := spm.minNotionalNotices.Load(price); ok && now-last.(int64) < int64(minNotionalNoticeInterval) {
+		return false
+	}
+	spm.minNotionalNotices.Store(price, now)
+	return true
+}
+
+// resetMinNotionalNotices 清除最小名义价值日志去重记录（交易规则变化、网格重建后按新价格层重新提示）
+func (spm *SuperPositionManager) resetMinNotionalNotices() {
+	spm.minNotionalNotices.Range(func(key, _ interface{}) bool {
+		spm.minNotionalNotices.Delete(key)
+		return true
+	})
+}
+
+// ceilToDecimals 按小数位数向上取整，结果至少为一个最小单位
+func ceilToDecimals(value float64, decimals int) float64 {
+	multiplier := math.Pow(10, float64(decimals))
+	rounded := math.Ceil(value*multiplier-1e-9) / multiplier
+	if rounded < 1/multiplier {
+		rounded = 1 / multiplier
+	}
+	return rounded
+}
+
+// floorToDecimals 按小数位数向下取整（容忍浮点误差，如 0.3 不会变成 0.29）
+func floorToDecimals(value float64, decimals int) float64 {
+	multiplier := math.Pow(10, float64(decimals))
+	return math.Floor(value*multiplier+1e-9) / multiplier
+}
@@ -0,0 +1,74 @@
+package position
+
+// positionCost 持仓买入成本，未知时按槽位价格计算（调用前必须持有 slot.mu）
+func (slot *InventorySlot) positionCost() float64 {
+	if slot.PositionCost > 0 {
+		return slot.PositionCost
+	}
+	return slot.Price * slot.PositionQty
+}
+
+// recordSellFill 记录一笔卖单成交：按持仓平均成本结转对应的买入成本（调用前必须持有 slot.mu，且在扣减持仓之前调用）
+func (slot *InventorySlot) recordSellFill(fillPrice, qty float64) {
+	if slot.PositionQty <= 0 {
+		return
+	}
+	if qty > slot.PositionQty {
+		qty = slot.PositionQty
+	}
+	cost := slot.positionCost() * qty / slot.PositionQty
+	slot.PositionCost = slot.positionCost() - cost
+	slot.roundTripQty += qty
+	slot.roundTripCost += cost
+	slot.roundTripProceeds += fillPrice * qty
+}
+
+// resetRoundTrip 清空单轮统计（调用前必须持有 slot.mu）
+func (slot *InventorySlot) resetRoundTrip() {
+	slot.roundTripQty = 0
+	slot.roundTripCost = 0
+	slot.roundTripProceeds = 0
+}
+
+// logRoundTrip 卖单结束时累计该轮实现盈亏，按 trading.log_round_trips 输出后清空统计（调用前必须持有 slot.mu）
+// 实现盈亏 = 卖出金额 - 买入成本 - 买卖双边手续费（按当前费率估算）；
+// 预期净利润与启动安全检查一致：数量 × 价格间隔 - 双边手续费
+func (spm *SuperPositionManager) logRoundTrip(slotPrice float64, slot *InventorySlot, partial bool) {
+	defer slot.resetRoundTrip()
+	if slot.roundTripQty <= 0 {
+		return
+	}
+
+	qty := slot.roundTripQty
+	feeRate := spm.GetFeeRate()
+	fees := (slot.roundTripCost + slot.roundTripProceeds) * feeRate
+	profit := slot.roundTripProceeds - slot.roundTripCost - fees
+
+	spm.realizedMu.Lock()
+	spm.roundTrips++
+	spm.realizedPnL += profit
+	spm.realizedMu.Unlock()
+
+	if !spm.config.Trading.LogRoundTrips {
+		return
+	}
+
+	interval := spm.GetSellPriceInterval()
+	expected := qty*interval - qty*(2*slotPrice+interval)*feeRate
+
+	desc := "完整"
+	if partial {
+		desc = "卖单撤销前部分成交"
+	}
+	positionLog.Info("💰 [单轮盈亏] 槽位 %s (%s): 买入均价 %s, 卖出均价 %s, 数量 %.*f, 手续费 %.4f, 实现盈亏 %+.4f (预期 %+.4f)",
+		formatPrice(slotPrice, spm.priceDecimals), desc,
+		formatPrice(slot.roundTripCost/qty, spm.priceDecimals), formatPrice(slot.roundTripProceeds/qty, spm.priceDecimals),
+		spm.quantityDecimals, qty, fees, profit, expected)
+}
+
+// RealizedStats 本次运行完成的买卖轮次和已实现盈亏（含手续费估算）
+func (spm *SuperPositionManager) RealizedStats() (roundTrips int64, pnl float64) {
+	spm.realizedMu.Lock()
+	defer spm.realizedMu.Unlock()
+	return spm.roundTrips, spm.realizedPnL
+}
@@ -0,0 +1,267 @@
+package position
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// PersistedState 仓位管理器状态（system.state_file），重启时恢复锚点和槽位，避免重建网格期间重复管理订单
+type PersistedState struct {
+	Symbol        string          `json:"symbol"`
+	SavedAt       time.Time       `json:"saved_at"`
+	AnchorPrice   float64         `json:"anchor_price"`
+	PriceInterval float64         `json:"price_interval"`
+	PriceDecimals int             `json:"price_decimals"` // ClientOrderID 编码价格使用的小数位数
+	Slots         []PersistedSlot `json:"slots"`          // 有持仓或有订单的槽位（按价格从高到低）
+}
+
+// PersistedSlot 单个槽位的持仓和订单
+type PersistedSlot struct {
+	Price            float64   `json:"price"`
+	PositionStatus   string    `json:"position_status"`
+	PositionQty      float64   `json:"position_qty"`
+	PositionOpenedAt time.Time `json:"position_opened_at"`
+	PositionCost     float64   `json:"position_cost,omitempty"`
+	OrderID          int64     `json:"order_id,omitempty"`
+	ClientOrderID    string    `json:"client_order_id,omitempty"`
+	OrderSide        string    `json:"order_side,omitempty"`
+	OrderStatus      string    `json:"order_status"`
+	OrderPrice       float64   `json:"order_price,omitempty"`
+	OrderFilledQty   float64   `json:"order_filled_qty,omitempty"`
+	OrderCreatedAt   time.Time `json:"order_created_at"`
+}
+
+// snapshotState 获取当前锚点和有持仓或有订单的槽位
+func (spm *SuperPositionManager) snapshotState() PersistedState {
+	spm.mu.RLock()
+	state := PersistedState{
+		Symbol:        spm.config.Trading.Symbol,
+		SavedAt:       time.Now(),
+		AnchorPrice:   spm.anchorPrice,
+		PriceInterval: spm.GetPriceInterval(),
+		PriceDecimals: spm.orderIDDecimals,
+		Slots:         []PersistedSlot{},
+	}
+	spm.mu.RUnlock()
+
+	spm.slots.Range(func(key, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		defer slot.mu.RUnlock()
+
+		if slot.PositionQty <= 0 && slot.OrderID == 0 && slot.ClientOID == "" {
+			return true
+		}
+		state.Slots = append(state.Slots, PersistedSlot{
+			Price:            key.(float64),
+			PositionStatus:   slot.PositionStatus,
+			PositionQty:      slot.PositionQty,
+			PositionOpenedAt: slot.PositionOpenedAt,
+			PositionCost:     slot.PositionCost,
+			OrderID:          slot.OrderID,
+			ClientOrderID:    slot.ClientOID,
+			OrderSide:        slot.OrderSide,
+			OrderStatus:      slot.OrderStatus,
+			OrderPrice:       slot.OrderPrice,
+			OrderFilledQty:   slot.OrderFilledQty,
+			OrderCreatedAt:   slot.OrderCreatedAt,
+		})
+		return true
+	})
+
+	sort.Slice(state.Slots, func(i, j int) bool { return state.Slots[i].Price > state.Slots[j].Price })
+	return state
+}
+
+// SaveState 将锚点和槽位写入 system.state_file（先写临时文件再替换，避免读到半个文件）
+// 初始化完成前不写入，避免启动失败时用空状态覆盖上次运行的状态
+func (spm *SuperPositionManager) SaveState() error {
+	path := spm.config.System.StateFile
+	if path == "" {
+		return fmt.Errorf("未配置 system.state_file")
+	}
+	if !spm.isInitialized.Load() {
+		return nil
+	}
+	data, err := json.MarshalIndent(spm.snapshotState(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化仓位状态失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入仓位状态失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("写入仓位状态失败: %w", err)
+	}
+	return nil
+}
+
+// LoadState 读取 system.state_file（文件不存在时返回 nil, nil）
+// 文件超过 system.state_max_age，或交易对、价格间隔、价格精度与当前不同时返回错误，由调用方改为从持仓重建
+func (spm *SuperPositionManager) LoadState() (*PersistedState, error) {
+	path := spm.config.System.StateFile
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取状态文件失败: %w", err)
+	}
+	var state PersistedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("状态文件解析失败: %w", err)
+	}
+
+	maxAge := time.Duration(spm.config.System.StateMaxAge) * time.Second
+	if age := time.Since(state.SavedAt); age > maxAge {
+		return nil, fmt.Errorf("状态保存于 %v 前，超过有效期 %v", age.Round(time.Second), maxAge)
+	}
+	if state.Symbol != spm.config.Trading.Symbol {
+		return nil, fmt.Errorf("状态文件的交易对 %s 与当前交易对 %s 不同", state.Symbol, spm.config.Trading.Symbol)
+	}
+	if state.AnchorPrice <= 0 {
+		return nil, fmt.Errorf("状态文件的锚点价格无效: %v", state.AnchorPrice)
+	}
+	interval := spm.GetPriceInterval()
+	if math.Abs(state.PriceInterval-interval) > interval*1e-9 {
+		return nil, fmt.Errorf("状态文件的价格间隔 %s 与当前价格间隔 %s 不同",
+			formatPrice(state.PriceInterval, spm.priceDecimals), formatPrice(interval, spm.priceDecimals))
+	}
+	if state.PriceDecimals != spm.orderIDDecimals {
+		return nil, fmt.Errorf("状态文件的价格精度 %d 与当前价格精度 %d 不同", state.PriceDecimals, spm.orderIDDecimals)
+	}
+	return &state, nil
+}
+
+// verifyState 用交易所当前挂单和持仓核对状态文件，返回仍在交易所挂着的订单（订单ID和ClientOrderID）
+// 状态文件中的持仓合计与交易所持仓不一致（停机期间有成交）时返回错误
+func (spm *SuperPositionManager) verifyState(state *PersistedState) (map[int64]bool, map[string]bool, error) {
+	ordersRaw, err := spm.exchange.GetOpenOrders(context.Background(), spm.config.Trading.Symbol)
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询挂单失败: %w", err)
+	}
+	liveIDs, liveClientIDs := extractOpenOrderKeys(ordersRaw)
+
+	var savedQty float64
+	for _, s := range state.Slots {
+		if s.PositionStatus == PositionStatusFilled {
+			savedQty += s.PositionQty
+		}
+	}
+	position := spm.getExistingPosition()
+	if tolerance := math.Pow10(-spm.quantityDecimals) / 2; math.Abs(savedQty-position) > tolerance {
+		return nil, nil, fmt.Errorf("状态文件持仓 %.*f 与交易所持仓 %.*f 不一致",
+			spm.quantityDecimals, savedQty, spm.quantityDecimals, position)
+	}
+	return liveIDs, liveClientIDs, nil
+}
+
+// restoreState 按状态文件恢复锚点和槽位（调用前必须持有 mu）
+// 只保留仍在交易所挂着的订单，其余订单清空后由 AdjustOrders 重新挂单
+func (spm *SuperPositionManager) restoreState(state *PersistedState, liveIDs map[int64]bool, liveClientIDs map[string]bool) {
+	spm.anchorPrice = state.AnchorPrice
+
+	var positions, orders, dropped int
+	for _, s := range state.Slots {
+		slot := spm.getOrCreateSlot(s.Price)
+		slot.mu.Lock()
+		if s.PositionStatus == PositionStatusFilled && s.PositionQty > 0 {
+			slot.PositionStatus = PositionStatusFilled
+			slot.PositionQty = s.PositionQty
+			slot.PositionOpenedAt = s.PositionOpenedAt
+			slot.PositionCost = s.PositionCost
+			positions++
+		}
+		live := (s.OrderID != 0 && liveIDs[s.OrderID]) || (s.ClientOrderID != "" && liveClientIDs[s.ClientOrderID])
+		if live {
+			slot.OrderID = s.OrderID
+			slot.ClientOID = s.ClientOrderID
+			slot.OrderSide = s.OrderSide
+			slot.OrderStatus = s.OrderStatus
+			if s.OrderStatus != OrderStatusConfirmed && s.OrderStatus != OrderStatusPartiallyFilled {
+				// 已下单未确认或撤单请求未生效的订单仍在交易所挂着，按已确认处理
+				slot.OrderStatus = OrderStatusConfirmed
+			}
+			slot.OrderPrice = s.OrderPrice
+			slot.OrderFilledQty = s.OrderFilledQty
+			slot.OrderCreatedAt = s.OrderCreatedAt
+			slot.SlotStatus = SlotStatusLocked
+			orders++
+		} else if s.OrderID != 0 || s.ClientOrderID != "" {
+			dropped++
+		}
+		if !live && slot.PositionStatus == PositionStatusFilled {
+			slot.OrderSide = "SELL" // 有持仓的槽位将来要挂卖单
+		}
+		slot.mu.Unlock()
+	}
+
+	positionLog.Info("🔄 [状态恢复] 已从状态文件恢复锚点 %s（保存于 %v 前）: 持仓槽位 %d 个, 挂单 %d 个, 已不在交易所的订单 %d 个",
+		formatPrice(state.AnchorPrice, spm.priceDecimals), time.Since(state.SavedAt).Round(time.Second), positions, orders, dropped)
+}
+
+// StartStatePersist 启动定期保存仓位状态（system.state_file 和 system.state_save_interval 均设置时生效，退出时保存最终状态）
+func (spm *SuperPositionManager) StartStatePersist(ctx context.Context) {
+	interval := spm.config.System.StateSaveInterval
+	if spm.config.System.StateFile == "" || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				if err := spm.SaveState(); err != nil {
+					positionLog.Warn("⚠️ [状态保存] 退出时保存失败: %v", err)
+				}
+				return
+			case <-ticker.C:
+				if err := spm.SaveState(); err != nil {
+					positionLog.Warn("⚠️ [状态保存] 定期保存失败: %v", err)
+				}
+			}
+		}
+	}()
+	positionLog.Info("✅ 仓位状态定期保存已启动 (周期: %ds, 文件: %s)", interval, spm.config.System.StateFile)
+}
+
+// extractOpenOrderKeys 从交易所挂单列表（[]*Order 等切片）中提取订单ID和ClientOrderID
+func extractOpenOrderKeys(ordersRaw interface{}) (map[int64]bool, map[string]bool) {
+	ids := make(map[int64]bool)
+	clientIDs := make(map[string]bool)
+	v := reflect.ValueOf(ordersRaw)
+	if v.Kind() != reflect.Slice {
+		return ids, clientIDs
+	}
+	for i := 0; i < v.Len(); i++ {
+		item := reflect.Indirect(v.Index(i))
+		if item.Kind() == reflect.Interface {
+			item = reflect.Indirect(item.Elem())
+		}
+		if item.Kind() != reflect.Struct {
+			continue
+		}
+		if field := item.FieldByName("OrderID"); field.IsValid() && field.CanInt() && field.Int() != 0 {
+			ids[field.Int()] = true
+		}
+		if field := item.FieldByName("ClientOrderID"); field.IsValid() && field.Kind() == reflect.String && field.String() != "" {
+			clientIDs[field.String()] = true
+		}
+	}
+	return ids, clientIDs
+}
@@ -0,0 +1,115 @@
+package position
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openOrder 交易所挂单（只含 extractOpenOrderKeys 读取的字段）
+type openOrder struct {
+	OrderID       int64
+	ClientOrderID string
+}
+
+// newStateManager 创建使用 path 作为状态文件的仓位管理器
+func newStateManager(t *testing.T, path string, ex *fakeExchange) *SuperPositionManager {
+	t.Helper()
+	cfg := testConfig()
+	cfg.System.StateFile = path
+	cfg.System.StateMaxAge = 300
+	return NewSuperPositionManager(cfg, &fakeExecutor{}, ex, 2, 4)
+}
+
+// seedSavedState 保存一份状态：锚点100，99 有 0.1 持仓和挂着的卖单，98 有买单
+func seedSavedState(t *testing.T, path string) PersistedState {
+	t.Helper()
+	spm := newStateManager(t, path, &fakeExchange{leverage: 10})
+	spm.anchorPrice = 100
+	opened := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+
+	held := spm.getOrCreateSlot(99)
+	held.PositionStatus = PositionStatusFilled
+	held.PositionQty = 0.1
+	held.PositionOpenedAt = opened
+	held.PositionCost = 9.9
+	held.OrderID, held.ClientOID, held.OrderSide = 7, "c7", "SELL"
+	held.OrderStatus, held.OrderPrice, held.OrderCreatedAt = OrderStatusConfirmed, 100, opened
+
+	buy := spm.getOrCreateSlot(98)
+	buy.OrderID, buy.ClientOID, buy.OrderSide = 8, "c8", "BUY"
+	buy.OrderStatus, buy.OrderPrice = OrderStatusPlaced, 98
+
+	spm.isInitialized.Store(true)
+	if err := spm.SaveState(); err != nil {
+		t.Fatalf("保存状态失败: %v", err)
+	}
+	return spm.snapshotState()
+}
+
+func TestStateSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	saved := seedSavedState(t, path)
+
+	// 重启后：卖单 7 仍在交易所，买单 8 已不在（停机期间被撤销），持仓与状态文件一致
+	ex := &fakeExchange{leverage: 10, positionSize: 0.1, openOrders: []openOrder{{OrderID: 7, ClientOrderID: "c7"}}}
+	spm := newStateManager(t, path, ex)
+	loaded, err := spm.LoadState()
+	if err != nil || loaded == nil {
+		t.Fatalf("应读到保存的状态: %v", err)
+	}
+	want, _ := json.Marshal(saved.Slots)
+	got, _ := json.Marshal(loaded.Slots)
+	if loaded.AnchorPrice != saved.AnchorPrice || loaded.PriceInterval != saved.PriceInterval || string(got) != string(want) {
+		t.Fatalf("读回的状态与保存的不同\n保存 %+v\n读回 %+v", saved, *loaded)
+	}
+
+	if err := spm.Initialize(105, "105"); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if spm.anchorPrice != 100 {
+		t.Fatalf("应恢复上次运行的锚点 100，实际 %.2f", spm.anchorPrice)
+	}
+	held := spm.getOrCreateSlot(99)
+	if held.PositionQty != 0.1 || held.PositionCost != 9.9 || held.OrderID != 7 || held.OrderStatus != OrderStatusConfirmed {
+		t.Fatalf("持仓槽位恢复不符: 持仓 %.4f 成本 %.2f 订单 %d 状态 %s",
+			held.PositionQty, held.PositionCost, held.OrderID, held.OrderStatus)
+	}
+	if buy := spm.getOrCreateSlot(98); buy.OrderID != 0 || buy.ClientOID != "" {
+		t.Fatalf("已不在交易所的买单不应恢复，实际订单 %d", buy.OrderID)
+	}
+}
+
+func TestStateIgnoresStaleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	saved := seedSavedState(t, path)
+
+	// 状态文件保存于有效期（300秒）之前
+	saved.SavedAt = time.Now().Add(-301 * time.Second)
+	data, err := json.Marshal(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ex := &fakeExchange{leverage: 10, positionSize: 0.1, openOrders: []openOrder{{OrderID: 7, ClientOrderID: "c7"}}}
+	spm := newStateManager(t, path, ex)
+	if state, err := spm.LoadState(); err == nil || state != nil {
+		t.Fatalf("过期的状态文件应返回错误，实际 state=%v err=%v", state, err)
+	}
+
+	// 初始化改为按当前价格和持仓重建，不沿用过期的锚点和订单
+	if err := spm.Initialize(105, "105"); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if spm.anchorPrice != 105 {
+		t.Fatalf("过期状态不应恢复锚点，应按当前价格 105 重建，实际 %.2f", spm.anchorPrice)
+	}
+	if slot := spm.getOrCreateSlot(99); slot.OrderID == 7 {
+		t.Fatal("过期状态中的订单不应恢复")
+	}
+}
@@ -11,10 +11,14 @@ import (
 	"time"
 
 	"opensqt/config"
+	"opensqt/event"
 	"opensqt/logger"
 	"opensqt/utils"
 )
 
+// positionLog 仓位管理组件日志器（级别可通过 system.log_levels.position 单独调整）
+var positionLog = logger.WithComponent("position")
+
 // OrderUpdate 订单更新事件（避免依赖 websocket 包）
 type OrderUpdate struct {
 	OrderID       int64
@@ -33,7 +37,7 @@ type OrderUpdate struct {
 type OrderExecutorInterface interface {
 	PlaceOrder(req *OrderRequest) (*Order, error)
 	BatchPlaceOrders(orders []*OrderRequest) ([]*Order, bool)
-	BatchCancelOrders(orderIDs []int64) error
+	BatchCancelOrders(orderIDs []int64, reason string) error // reason 为撤单原因（event.OrderReason*）
 }
 
 // OrderRequest 订单请求（避免循环导入）
@@ -45,7 +49,9 @@ type OrderRequest struct {
 	PriceDecimals int    // 价格小数位数（用于格式化价格字符串）
 	ReduceOnly    bool   // 是否只减仓（平仓单）
 	PostOnly      bool   // 是否只做 Maker（Post Only）
+	Market        bool   // 市价单（IOC，忽略 Price 和 PostOnly）
 	ClientOrderID string // 自定义订单ID
+	Reason        string // 下单原因（event.OrderReason*，写入订单审计记录）
 }
 
 // Order 订单信息（避免循环导入）
@@ -91,6 +97,10 @@ type InventorySlot struct {
 	// 持仓信息
 	PositionStatus string  // 持仓状态：空仓/有仓
 	PositionQty    float64 // 持仓数量（支持小数点后3位）
+	// 建仓时间（空仓后首笔买入成交的时间，启动时恢复的持仓为启动时间），用于 max_hold_seconds
+	PositionOpenedAt time.Time
+	// 持仓买入成本（买单成交价 × 数量累计，0 表示未知，如启动时恢复的持仓，按槽位价格计算）
+	PositionCost float64
 
 	// 订单信息 (买卖互斥)
 	OrderID        int64     // 订单ID
@@ -107,9 +117,28 @@ type InventorySlot struct {
 	// PostOnly失败计数（连续失败3次后降级为普通单）
 	PostOnlyFailCount int
 
+	// 主动撤单重挂中（手续费变化或精度调整，撤单回报不计入PostOnly失败）
+	FeeRepricing bool
+
+	// 当前卖单已成交部分的单轮统计（卖单结束时输出单轮实现盈亏后清零）
+	roundTripQty      float64
+	roundTripCost     float64
+	roundTripProceeds float64
+
+	// 最近一次计入的增量成交推送（executed_qty_mode: incremental 时用于识别交易所重复推送的同一条成交）
+	lastIncrementalFill incrementalFillKey
+
 	mu sync.RWMutex // 槽位级别的锁（细粒度锁）
 }
 
+// incrementalFillKey 增量成交推送的去重键：同一订单、同一 UpdateTime、同一状态和成交数量的推送视为重复推送
+type incrementalFillKey struct {
+	clientOID   string
+	updateTime  int64
+	status      string
+	executedQty float64
+}
+
 // PositionInfo 持仓信息（简化版，避免循环导入）
 type PositionInfo struct {
 	Symbol string
@@ -125,7 +154,12 @@ type IExchange interface {
 	GetOpenOrders(ctx context.Context, symbol string) (interface{}, error)
 	GetOrder(ctx context.Context, symbol string, orderID int64) (interface{}, error)
 	GetBaseAsset() string                                     // 获取基础资产（交易币种）
+	GetMinNotional() float64                                  // 获取交易所最小下单金额（0表示未知）
 	CancelAllOrders(ctx context.Context, symbol string) error // 取消所有订单
+	// GetMarginInfo 获取可用保证金和杠杆倍数（用于初始挂单前的保证金预估，杠杆未知时返回0）
+	GetMarginInfo(ctx context.Context, symbol string) (available float64, leverage int, err error)
+	// GetMarginBalance 获取可用保证金、保证金余额（含未实现盈亏）和杠杆倍数（用于可用保证金下限检查）
+	GetMarginBalance(ctx context.Context, symbol string) (available, equity float64, leverage int, err error)
 }
 
 // SuperPositionManager 超级仓位管理器
@@ -138,8 +172,10 @@ type SuperPositionManager struct {
 	anchorPrice float64
 	// 最后市场价格（用于打印状态）
 	lastMarketPrice atomic.Value // float64
-	// 价格精度（根据锚点价格检测得出的小数位数）
+	// 价格精度（根据锚点价格检测得出的小数位数，交易所调整精度后可能变小）
 	priceDecimals int
+	// ClientOrderID 编码价格使用的小数位数（固定为启动时的价格精度，精度调整前后的订单都能正确解析）
+	orderIDDecimals int
 	// 数量精度（从交易所获取）
 	quantityDecimals int
 
@@ -150,6 +186,62 @@ type SuperPositionManager struct {
 	insufficientMargin bool
 	marginLockTime     time.Time
 	marginLockDuration time.Duration
+	// 保证金预估后的买单窗口上限（0表示不限制，卖单成交释放保证金后逐步放开）
+	seedBuyLimit atomic.Int64
+	// 保证金窗口限制的买单层数（trading.margin_window，0表示不限制）
+	marginBuyLimit atomic.Int64
+	// 最近一次查询的可用保证金（trading.min_free_margin_percent，nil 表示未启用或尚未查询）
+	freeMargin atomic.Pointer[freeMarginSample]
+	// 上次查询可用保证金后新增的买单数（下次查询前按每单占用的保证金扣减）
+	buysSinceMarginSample atomic.Int64
+	freeMarginPaused      bool // 已输出过"可用保证金低于下限"日志（恢复后重置）
+	// 上次调整订单时因 timing.max_orders_per_tick 延后的订单数（> 0 时价格不变也需要再次调整补挂）
+	deferredOrders atomic.Int64
+
+	// 下次挂单的原因（event.OrderReason*，重置锚点、风控解除时设置，挂出订单后清除；受 mu 保护）
+	placementReason string
+	// 是否已完成首次挂单（之后新增的买单/卖单分别记为 grid_buy / fill_sell）
+	initialPlaced bool
+
+	// 各订单已处理推送的进度：ClientOrderID -> 进度（trading.order_update_ordering，锁在 slot.mu 之后获取）
+	orderSeqMu    sync.Mutex
+	orderSeqs     map[string]*orderSequence
+	lastDriftWarn atomic.Int64 // 上次输出时钟偏差警告的时间（UnixNano）
+
+	// 最小名义价值调整日志去重：价格 -> 上次记录时间（UnixNano），网格重建时清空
+	minNotionalNotices sync.Map
+
+	// 可在运行中调整的交易参数（启动时取配置，配置重载通过 UpdateTradingParams 整体替换）
+	tradingParams atomic.Pointer[TradingParams]
+
+	// 当前价格间隔（启动时取配置 price_interval，自适应间隔可在运行中调整）
+	priceInterval atomic.Value // float64
+	// 卖单间隔 / 价格间隔（trading.sell_price_interval 未设置时为1）
+	sellIntervalRatio float64
+
+	// 当前手续费率（启动时取配置 fee_rate，fee_reprice 检测到变化后更新）
+	feeRate atomic.Value // float64
+
+	// 资金分配（capital_allocation 解析后的金额，0表示不限制）
+	// 持仓 + 买单名义价值不超过 分配金额 × 杠杆
+	capitalAllocation  float64
+	allocationLeverage int
+	allocationCapped   bool // 已输出过"达到资金分配上限"日志（解除后重置）
+
+	inventorySkewed bool // 已输出过"库存偏斜"日志（净持仓回到目标库存以内后重置）
+
+	// 订单推送的 ExecutedQty 为本次新增成交数量（false 表示累计成交数量）
+	incrementalFills bool
+
+	// 网格参数变化回调（价格间隔、价格精度、锚点变化后调用，用于盈利复核）
+	onGridChanged func(reason string, price float64)
+
+	// 暂停新增买单的检查函数（运行中安全复核失败时返回 true，卖单不受影响）
+	buyPauseChecker func() bool
+	// 买单深度上限（浮亏回撤时限制买单窗口层数，返回负数表示不限制）
+	buyDepthLimiter func() int
+	// 最新市场价格来源（计算浮动盈亏，未设置时使用最后一次调整订单的价格）
+	marketPriceSource func() float64
 
 	// 统计（注意：以下字段被 safety.Reconciler 和 PrintPositions 使用，不可删除）
 	totalBuyQty       atomic.Value // float64 - 累计买入数量
@@ -157,10 +249,19 @@ type SuperPositionManager struct {
 	reconcileCount    atomic.Int64 // 对账次数
 	lastReconcileTime atomic.Value // time.Time - 最后对账时间
 
+	// 本次运行的已实现盈亏统计（每轮卖单结束时累计，不含启动前回溯的历史成交）
+	realizedMu  sync.Mutex
+	roundTrips  int64
+	realizedPnL float64
+
 	// 初始化标志
 	isInitialized atomic.Bool
 
 	mu sync.RWMutex // 全局锁（用于关键操作）
+
+	// 订单集合守卫（trading.reconcile_guard）：AdjustOrders 下单期间持读锁，对账比对期间持写锁
+	// 加锁顺序: mu → orderSetMu → slot.mu
+	orderSetMu sync.RWMutex
 }
 
 // NewSuperPositionManager 创建超级仓位管理器
@@ -177,36 +278,164 @@ func NewSuperPositionManager(cfg *config.Config, executor OrderExecutorInterface
 		insufficientMargin: false,
 		marginLockDuration: time.Duration(marginLockSec) * time.Second,
 		priceDecimals:      priceDecimals,
+		orderIDDecimals:    priceDecimals,
 		quantityDecimals:   quantityDecimals,
+		orderSeqs:          make(map[string]*orderSequence),
 	}
 	spm.totalBuyQty.Store(0.0)
 	spm.totalSellQty.Store(0.0)
 	spm.lastReconcileTime.Store(time.Now())
 	spm.lastMarketPrice.Store(0.0)
+	spm.tradingParams.Store(tradingParamsFromConfig(cfg))
+	spm.priceInterval.Store(cfg.Trading.PriceInterval)
+	spm.sellIntervalRatio = 1
+	if trading := cfg.Trading; trading.SellPriceInterval > 0 && trading.PriceInterval > 0 {
+		spm.sellIntervalRatio = trading.SellPriceInterval / trading.PriceInterval
+	}
+	spm.feeRate.Store(cfg.Exchanges[cfg.App.CurrentExchange].FeeRate)
 	return spm
 }
 
+// SetCapitalAllocation 设置该交易对可使用的资金（需在 Initialize 之前调用，0表示不限制）
+func (spm *SuperPositionManager) SetCapitalAllocation(amount float64) {
+	spm.capitalAllocation = amount
+}
+
+// SetGridChangeHandler 设置网格参数变化回调（需在 Initialize 之前调用）
+// 价格间隔调整、价格精度变粗后以当前市场价格回调；重置锚点的逻辑也应通过 notifyGridChanged 通知
+func (spm *SuperPositionManager) SetGridChangeHandler(fn func(reason string, price float64)) {
+	spm.onGridChanged = fn
+}
+
+// notifyGridChanged 网格参数变化后回调（不能在持有 spm.mu 时调用）
+func (spm *SuperPositionManager) notifyGridChanged(reason string) {
+	if spm.onGridChanged == nil {
+		return
+	}
+	price, _ := spm.lastMarketPrice.Load().(float64)
+	if price <= 0 {
+		spm.mu.RLock()
+		price = spm.anchorPrice
+		spm.mu.RUnlock()
+	}
+	spm.onGridChanged(reason, price)
+}
+
+// SetBuyPauseChecker 设置暂停新增买单的检查函数（需在 Initialize 之前调用）
+func (spm *SuperPositionManager) SetBuyPauseChecker(fn func() bool) {
+	spm.buyPauseChecker = fn
+}
+
+// SetBuyDepthLimiter 设置买单深度上限函数（需在 Initialize 之前调用）
+// 返回值小于买单窗口时只在离价格最近的若干层挂买单，负数表示不限制
+func (spm *SuperPositionManager) SetBuyDepthLimiter(fn func() int) {
+	spm.buyDepthLimiter = fn
+}
+
+// effectiveBuyWindow 当前生效的买单窗口层数（配置窗口依次受保证金预估、保证金窗口和买单深度上限限制）
+func (spm *SuperPositionManager) effectiveBuyWindow() int {
+	buyWindowSize := spm.GetBuyWindowSize()
+	if limit := int(spm.seedBuyLimit.Load()); limit > 0 && limit < buyWindowSize {
+		buyWindowSize = limit // 保证金预估限制，只挂离价格最近的若干层
+	}
+	if limit := int(spm.marginBuyLimit.Load()); limit > 0 && limit < buyWindowSize {
+		buyWindowSize = limit // 可用保证金低于缓冲，按比例缩小
+	}
+	if spm.buyDepthLimiter != nil {
+		if depth := spm.buyDepthLimiter(); depth >= 0 && depth < buyWindowSize {
+			buyWindowSize = depth // 浮亏回撤限制，不再挂更深的买单
+		}
+	}
+	return buyWindowSize
+}
+
+// SetMarketPriceSource 设置最新市场价格来源（用于状态快照中的浮动盈亏）
+func (spm *SuperPositionManager) SetMarketPriceSource(fn func() float64) {
+	spm.marketPriceSource = fn
+}
+
+// SetIncrementalFills 设置订单推送中 ExecutedQty 的语义（需在订单流启动之前调用）
+// true 表示交易所推送的是本次新增成交数量，false 表示订单累计成交数量
+func (spm *SuperPositionManager) SetIncrementalFills(incremental bool) {
+	spm.incrementalFills = incremental
+}
+
+// SeedTradeStats 将启动前的历史成交计入累计买入/卖出（需在 Initialize 之前调用）
+func (spm *SuperPositionManager) SeedTradeStats(buyQty, sellQty float64) {
+	spm.totalBuyQty.Store(spm.totalBuyQty.Load().(float64) + buyQty)
+	spm.totalSellQty.Store(spm.totalSellQty.Load().(float64) + sellQty)
+}
+
 // Initialize 初始化管理器（设置价格锚点并创建初始槽位）
 func (spm *SuperPositionManager) Initialize(initialPrice float64, initialPriceStr string) error {
-	spm.mu.Lock()
-	defer spm.mu.Unlock()
-
 	if initialPrice <= 0 {
 		return fmt.Errorf("初始价格无效: %.2f", initialPrice)
 	}
+	// 买卖两侧各偏移 offset：偏移的两倍不小于价格间隔时，刚成交买单的卖出价落在卖单下限以内，网格无法运转
+	if offset := spm.firstLevelOffset(initialPrice); offset > 0 {
+		if interval := spm.GetPriceInterval(); 2*offset >= interval {
+			return fmt.Errorf("first_level_offset (%s) 的两倍必须小于价格间隔 (%s)",
+				formatPrice(offset, spm.priceDecimals), formatPrice(interval, spm.priceDecimals))
+		}
+		positionLog.Info("📏 [最近一档偏移] 最近买单至少低于当前价格 %s，最近卖单至少高于当前价格 %s",
+			formatPrice(offset, spm.priceDecimals), formatPrice(offset, spm.priceDecimals))
+	}
+
+	// 0. 保证金预估（在加锁前查询交易所，避免持锁调用外部API）
+	seedBuyLimit := 0
+	if spm.config.Trading.PreseedMarginCheck {
+		seedBuyLimit = spm.estimateSeedBuyLimit()
+	}
+	if spm.capitalAllocation > 0 {
+		spm.allocationLeverage = spm.fetchLeverage()
+		positionLog.Info("💼 [资金分配] 可用资金 %.2f, 杠杆 %dx, 持仓+买单名义价值上限 %.2f",
+			spm.capitalAllocation, spm.allocationLeverage, spm.capitalAllocation*float64(spm.allocationLeverage))
+	}
+	// 状态文件（system.state_file）：未过期且与交易所挂单和持仓核对一致时恢复上次运行的锚点和槽位
+	var state *PersistedState
+	var liveIDs map[int64]bool
+	var liveClientIDs map[string]bool
+	if spm.config.System.StateFile != "" {
+		loaded, err := spm.LoadState()
+		if err == nil && loaded != nil {
+			liveIDs, liveClientIDs, err = spm.verifyState(loaded)
+		}
+		if err != nil {
+			positionLog.Warn("⚠️ [状态恢复] %v，改为从持仓重建槽位", err)
+		} else {
+			state = loaded
+		}
+	}
+
+	spm.mu.Lock()
+	defer spm.mu.Unlock()
+
+	spm.seedBuyLimit.Store(int64(seedBuyLimit))
 
 	// 1. 设置价格锚点（精度信息已经在构造函数中设置，从交易所获取）
 	spm.anchorPrice = initialPrice
 	spm.lastMarketPrice.Store(initialPrice) // 初始化最后市场价格
-	logger.Info("✅ 价格锚点已设置: %s, 价格精度:%d, 数量精度:%d",
+	positionLog.Info("✅ 价格锚点已设置: %s, 价格精度:%d, 数量精度:%d",
 		formatPrice(initialPrice, spm.priceDecimals), spm.priceDecimals, spm.quantityDecimals)
 
+	if state != nil {
+		// 恢复的槽位按上次运行的锚点对齐，买单窗口由 AdjustOrders 按当前价格补挂
+		spm.restoreState(state, liveIDs, liveClientIDs)
+		spm.isInitialized.Store(true)
+		positionLog.Info("✅ 初始化完成，网格锚点: %s", formatPrice(spm.anchorPrice, spm.priceDecimals))
+		if spm.config.Trading.LadderMode {
+			positionLog.Info("🪜 [阶梯模式] 沿用上次运行的买单阶梯（锚点 %s），不按当前价格 %s 重新锚定",
+				formatPrice(spm.anchorPrice, spm.priceDecimals), formatPrice(initialPrice, spm.priceDecimals))
+		}
+		return nil
+	}
+
 	// 2. 直接使用锚点价格作为网格价格（不再对齐到整数）
 	initialGridPrice := spm.anchorPrice
-	logger.Info("✅ 初始网格价格: %s (使用锚点价格)", formatPrice(initialGridPrice, spm.priceDecimals))
+	positionLog.Info("✅ 初始网格价格: %s (使用锚点价格)", formatPrice(initialGridPrice, spm.priceDecimals))
 
 	// 4. 使用统一的槽位价格计算方法创建初始槽位
-	slotPrices := spm.calculateSlotPrices(initialGridPrice, spm.config.Trading.BuyWindowSize, "down")
+	slotPrices := spm.calculateSlotPrices(initialGridPrice, spm.GetBuyWindowSize(), "down")
 	for _, price := range slotPrices {
 		spm.getOrCreateSlot(price)
 	}
@@ -215,18 +444,319 @@ func (spm *SuperPositionManager) Initialize(initialPrice float64, initialPriceSt
 	for i, p := range slotPrices {
 		slotPricesStr[i] = formatPrice(p, spm.priceDecimals)
 	}
-	logger.Info("✅ [初始化] 计算出的槽位价格: %v", slotPricesStr)
+	positionLog.Info("✅ [初始化] 计算出的槽位价格: %v", slotPricesStr)
+	if spm.config.Trading.LadderMode {
+		positionLog.Info("🪜 [阶梯模式] 固定买单阶梯 %d 层: %s ~ %s，价格上涨不重新锚定，每层卖单成交后才重新挂买单",
+			len(slotPrices), formatPrice(slotPrices[0], spm.priceDecimals), formatPrice(slotPrices[len(slotPrices)-1], spm.priceDecimals))
+	}
 
 	// 5. 为初始槽位下买单
 	err := spm.placeInitialBuyOrders()
 	if err == nil {
 		// 标记为已初始化
 		spm.isInitialized.Store(true)
-		logger.Info("✅ 初始化完成，网格价格: %s", formatPrice(initialGridPrice, spm.priceDecimals))
+		positionLog.Info("✅ 初始化完成，网格价格: %s", formatPrice(initialGridPrice, spm.priceDecimals))
 	}
 	return err
 }
 
+// estimateSeedBuyLimit 预估初始网格占用的保证金，返回允许挂出的买单层数（0表示无需限制）
+// 即使启动安全检查通过，一次性铺满大网格也可能因保证金冻结时序导致连续的保证金不足错误，
+// 因此保证金不足时只挂离当前价格最近的若干层，其余层在卖单成交释放保证金后再逐步补挂
+func (spm *SuperPositionManager) estimateSeedBuyLimit() int {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	available, leverage, err := spm.exchange.GetMarginInfo(ctx, spm.config.Trading.Symbol)
+	if err != nil {
+		positionLog.Warn("⚠️ [保证金预估] 获取可用保证金失败，按完整窗口挂单: %v", err)
+		return 0
+	}
+	if spm.capitalAllocation > 0 && spm.capitalAllocation < available {
+		available = spm.capitalAllocation // 只能使用分配给该交易对的资金
+	}
+
+	if leverage <= 0 {
+		leverage = spm.config.Trading.MaxLeverage
+		positionLog.Info("ℹ️ [保证金预估] 未获取到杠杆倍数，按最大允许杠杆 %dx 估算", leverage)
+	}
+
+	windowSize := spm.GetBuyWindowSize()
+	marginPerOrder := spm.GetOrderQuantity() / float64(leverage)
+	requiredMargin := marginPerOrder * float64(windowSize)
+
+	if requiredMargin <= available {
+		positionLog.Info("✅ [保证金预估] 初始网格需要保证金 %.2f, 可用 %.2f, 可挂满 %d 层买单",
+			requiredMargin, available, windowSize)
+		return 0
+	}
+
+	allowed := int(available / marginPerOrder)
+	if allowed < 1 {
+		allowed = 1 // 至少挂一层，保证金不足时由下单错误触发锁定
+	}
+	positionLog.Warn("⚠️ [保证金预估] 初始网格需要保证金 %.2f (每层 %.2f, %dx杠杆), 可用仅 %.2f: 先挂最近的 %d 层买单, 延后 %d 层（卖单成交释放保证金后补挂）",
+		requiredMargin, marginPerOrder, leverage, available, allowed, windowSize-allowed)
+	return allowed
+}
+
+// fetchLeverage 查询交易对杠杆倍数（未获取到时按最大允许杠杆）
+func (spm *SuperPositionManager) fetchLeverage() int {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, leverage, err := spm.exchange.GetMarginInfo(ctx, spm.config.Trading.Symbol)
+	if err != nil || leverage <= 0 {
+		leverage = spm.config.Trading.MaxLeverage
+	}
+	return leverage
+}
+
+// releaseSeedBuyLevel 卖单成交释放保证金后，放开一层被延后的买单
+// 注意：在持有槽位锁的订单回调中调用，只能使用原子操作，不能获取全局锁
+func (spm *SuperPositionManager) releaseSeedBuyLevel() {
+	windowSize := int64(spm.GetBuyWindowSize())
+	for {
+		limit := spm.seedBuyLimit.Load()
+		if limit <= 0 {
+			return
+		}
+
+		next := limit + 1
+		if next >= windowSize {
+			next = 0
+		}
+		if !spm.seedBuyLimit.CompareAndSwap(limit, next) {
+			continue
+		}
+
+		if next == 0 {
+			positionLog.Info("✅ [保证金预估] 延后的买单层已全部放开，恢复完整买单窗口 %d 层", windowSize)
+		} else {
+			positionLog.Info("🔓 [保证金预估] 卖单成交释放保证金，买单窗口扩大至 %d/%d 层", next, windowSize)
+		}
+		return
+	}
+}
+
+// minOrderValue 返回生效的最小订单价值
+// 取用户配置的 min_order_value 与交易所最小下单金额中较大者，避免挂出会被交易所拒绝的订单
+func (spm *SuperPositionManager) minOrderValue() float64 {
+	minValue := spm.tradingParams.Load().MinOrderValue
+	if minValue <= 0 {
+		minValue = 6.0
+	}
+	if exchangeMin := spm.exchange.GetMinNotional(); exchangeMin > minValue {
+		return exchangeMin
+	}
+	return minValue
+}
+
+// meetsMinNotional 判断 价格 × 数量 是否不低于最小订单价值
+// 两者按 trading.quote_decimals 取整后比较，恰好等于最小值的订单不会因浮点误差被判为不足
+func (spm *SuperPositionManager) meetsMinNotional(price, quantity, minValue float64) bool {
+	decimals := spm.config.Trading.QuoteDecimals
+	return roundPrice(price*quantity, decimals) >= roundPrice(minValue, decimals)
+}
+
+// orderQuantityFor 按每单金额计算价格层的下单数量
+// 默认按数量精度向下取整，名义价值不超过 order_quantity；quantity_rounding=round 时四舍五入
+func (spm *SuperPositionManager) orderQuantityFor(price float64) float64 {
+	quantity := spm.GetOrderQuantity() / price
+	if spm.config.Trading.QuantityRounding == "round" {
+		return roundPrice(quantity, spm.quantityDecimals)
+	}
+	return floorToDecimals(quantity, spm.quantityDecimals)
+}
+
+// ensureMinNotional 确保买单名义价值不低于最小订单价值
+// 低于最小值时：若启用自动上调且上调后的金额不超过 order_quantity × 最大倍数，返回上调后的数量；
+// 否则返回 false 表示跳过该价格层
+func (spm *SuperPositionManager) ensureMinNotional(price, quantity float64) (float64, bool) {
+	minValue := spm.minOrderValue()
+
+	if spm.meetsMinNotional(price, quantity, minValue) {
+		return quantity, true
+	}
+
+	notified := !spm.shouldNoticeMinNotional(price)
+
+	if !spm.config.Trading.MinNotionalAutoRaise {
+		if !notified {
+			positionLog.Warn("⚠️ [最小名义价值] 价格层 %s: 数量 %.*f × 价格 = %.4f < 最小值 %.2f，跳过该层（未启用自动上调）",
+				formatPrice(price, spm.priceDecimals), spm.quantityDecimals, quantity, quantity*price, minValue)
+		}
+		return 0, false
+	}
+
+	// 按数量精度取满足最小名义价值的最小数量（先向下取整，不足再加一个最小单位，避免浮点误差多加一个单位）
+	raisedQty := floorToDecimals(minValue/price, spm.quantityDecimals)
+	if !spm.meetsMinNotional(price, raisedQty, minValue) {
+		raisedQty = roundPrice(raisedQty+math.Pow(10, -float64(spm.quantityDecimals)), spm.quantityDecimals)
+	}
+	raisedValue := raisedQty * price
+	maxValue := spm.GetOrderQuantity() * spm.config.Trading.MinNotionalMaxMultiplier
+
+	if raisedValue > maxValue {
+		if !notified {
+			positionLog.Warn("⚠️ [最小名义价值] 价格层 %s: 需上调至 %.4f 才能满足最小值 %.2f，超过上限 %.2f (%.2f倍)，跳过该层",
+				formatPrice(price, spm.priceDecimals), raisedValue, minValue, maxValue, spm.config.Trading.MinNotionalMaxMultiplier)
+		}
+		return 0, false
+	}
+
+	if !notified {
+		positionLog.Info("📈 [最小名义价值] 价格层 %s: 数量 %.*f -> %.*f, 名义价值 %.4f -> %.4f (最小值 %.2f)",
+			formatPrice(price, spm.priceDecimals), spm.quantityDecimals, quantity, spm.quantityDecimals, raisedQty,
+			quantity*price, raisedValue, minValue)
+	}
+	return raisedQty, true
+}
+
+// feeSellFloor 返回槽位卖单的最低价格（保本价 + 最小利润），未启用 fee_reprice 时返回0
+// 保本价: 买入价 × (1 + 费率) / (1 - 费率)，按价格精度向上取整
+func (spm *SuperPositionManager) feeSellFloor(slotPrice float64) float64 {
+	if !spm.config.Trading.FeeReprice.Enabled {
+		return 0
+	}
+
+	feeRate := spm.feeRate.Load().(float64)
+	if feeRate <= 0 || feeRate >= 1 {
+		return 0
+	}
+
+	breakeven := slotPrice * (1 + feeRate) / (1 - feeRate)
+	floor := breakeven * (1 + spm.config.Trading.FeeReprice.MinMarginPercent/100)
+	factor := math.Pow(10, float64(spm.priceDecimals))
+	return math.Ceil(floor*factor-1e-9) / factor
+}
+
+// sellIntervalFor 卖单相对槽位价格的间隔：未设置 sell_price_interval 时等于价格间隔，
+// 设置时为 价格间隔 × sell_price_interval / price_interval（价格间隔在运行中被调整时两侧按相同比例缩放）
+func (spm *SuperPositionManager) sellIntervalFor(priceInterval float64) float64 {
+	if spm.sellIntervalRatio == 1 {
+		return priceInterval
+	}
+	return roundPrice(priceInterval*spm.sellIntervalRatio, spm.priceDecimals)
+}
+
+// sellPriceFor 槽位卖单价格 = 槽位价格 + 卖单间隔，手续费上调后不低于新的保本价 + 最小利润
+func (spm *SuperPositionManager) sellPriceFor(slotPrice, priceInterval float64) float64 {
+	sellPrice := roundPrice(slotPrice+spm.sellIntervalFor(priceInterval), spm.priceDecimals)
+	if floor := spm.feeSellFloor(slotPrice); floor > sellPrice {
+		sellPrice = floor
+	}
+	return sellPrice
+}
+
+// firstLevelOffset 最近一档偏移（trading.first_level_offset）：ticks 个最小价格单位与价格的 percent% 取较大者
+func (spm *SuperPositionManager) firstLevelOffset(price float64) float64 {
+	cfg := spm.config.Trading.FirstLevelOffset
+	offset := float64(cfg.Ticks) / math.Pow(10, float64(spm.priceDecimals))
+	if pct := price * cfg.Percent / 100; pct > offset {
+		offset = pct
+	}
+	return offset
+}
+
+// OnFeeRateChanged 手续费率变化回调
+// 更新当前费率；费率上调时撤销价格低于新保本价的卖单，由 AdjustOrders 按新的最低价重新挂出
+func (spm *SuperPositionManager) OnFeeRateChanged(oldRate, newRate float64) {
+	spm.feeRate.Store(newRate)
+	positionLog.Info("💳 [手续费调整] 费率变化: %.4f%% -> %.4f%%", oldRate*100, newRate*100)
+
+	if !spm.config.Trading.FeeReprice.Enabled || newRate <= oldRate {
+		return
+	}
+
+	var orderIDs []int64
+	spm.slots.Range(func(key, value interface{}) bool {
+		slotPrice := key.(float64)
+		slot := value.(*InventorySlot)
+		slot.mu.Lock()
+		defer slot.mu.Unlock()
+
+		// 只处理挂着且未部分成交的卖单
+		if slot.OrderSide != "SELL" || slot.OrderID == 0 || slot.OrderFilledQty > 0 ||
+			(slot.OrderStatus != OrderStatusPlaced && slot.OrderStatus != OrderStatusConfirmed) {
+			return true
+		}
+
+		floor := spm.feeSellFloor(slotPrice)
+		if slot.OrderPrice >= floor {
+			return true
+		}
+
+		positionLog.Info("📈 [手续费调整] 槽位 %s: 卖单 %s 低于新保本价要求 %s，撤单后重新挂出 (订单ID: %d)",
+			formatPrice(slotPrice, spm.priceDecimals), formatPrice(slot.OrderPrice, spm.priceDecimals),
+			formatPrice(floor, spm.priceDecimals), slot.OrderID)
+		slot.FeeRepricing = true
+		orderIDs = append(orderIDs, slot.OrderID)
+		return true
+	})
+
+	if len(orderIDs) == 0 {
+		positionLog.Info("✅ [手续费调整] 现有卖单均可覆盖新费率，无需调整")
+		return
+	}
+
+	if err := spm.executor.BatchCancelOrders(orderIDs, event.OrderReasonReprice); err != nil {
+		positionLog.Error("❌ [手续费调整] 撤销 %d 个卖单失败: %v", len(orderIDs), err)
+		spm.slots.Range(func(key, value interface{}) bool {
+			slot := value.(*InventorySlot)
+			slot.mu.Lock()
+			slot.FeeRepricing = false
+			slot.mu.Unlock()
+			return true
+		})
+		return
+	}
+	positionLog.Info("🔄 [手续费调整] 已撤销 %d 个卖单，等待按新价格重新挂出", len(orderIDs))
+}
+
+// ReseedForFeeRate 按新费率重新计算价格间隔并重铺网格（fee_reprice.reseed_grid）
+// 新间隔 = 当前价格下的保本间隔 + 目标利润；持仓层数超过容差时不重铺并返回 false，由调用方稍后重试
+func (spm *SuperPositionManager) ReseedForFeeRate(feeRate float64) bool {
+	reseed := spm.config.Trading.FeeReprice.ReseedGrid
+	if feeRate < 0 || feeRate >= 1 {
+		return true
+	}
+
+	inventoryLevels := 0
+	spm.slots.Range(func(key, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		if slot.PositionStatus == PositionStatusFilled && slot.PositionQty > 0 {
+			inventoryLevels++
+		}
+		slot.mu.RUnlock()
+		return true
+	})
+	if inventoryLevels > reseed.MaxInventoryLevels {
+		positionLog.Info("⏳ [费率重铺] 当前持仓 %d 层超过容差 %d 层，暂不调整价格间隔", inventoryLevels, reseed.MaxInventoryLevels)
+		return false
+	}
+
+	price, _ := spm.lastMarketPrice.Load().(float64)
+	if price <= 0 {
+		return false
+	}
+	// 固定金额模式下每笔净利润 > 0 要求 间隔 > 价格 × 2f / (1 - f)
+	breakeven := price * 2 * feeRate / (1 - feeRate)
+	interval := breakeven + price*reseed.TargetMarginPercent/100
+	if interval < reseed.MinInterval {
+		interval = reseed.MinInterval
+	}
+	interval = ceilToDecimals(interval, spm.priceDecimals)
+
+	old := spm.GetPriceInterval()
+	positionLog.Warn("💳 [费率重铺] 费率 %.4f%%, 价格 %s: 保本间隔 %s, 价格间隔 %s -> %s（持仓 %d 层）",
+		feeRate*100, formatPrice(price, spm.priceDecimals), formatPrice(breakeven, spm.priceDecimals),
+		formatPrice(old, spm.priceDecimals), formatPrice(interval, spm.priceDecimals), inventoryLevels)
+	spm.SetPriceInterval(interval)
+	return true
+}
+
 // generateClientOrderID 生成自定义订单ID
 // 使用新的紧凑格式，最大长度不超过18字符
 // 格式: {price_int}_{side}_{timestamp}{seq}
@@ -234,7 +764,7 @@ func (spm *SuperPositionManager) Initialize(initialPrice float64, initialPriceSt
 // side: B=Buy, S=Sell
 func (spm *SuperPositionManager) generateClientOrderID(price float64, side string) string {
 	// 使用统一的 utils 包生成紧凑ID
-	return utils.GenerateOrderID(price, side, spm.priceDecimals)
+	return utils.GenerateOrderID(price, side, spm.orderIDDecimals)
 }
 
 // parseClientOrderID 解析 ClientOrderID
@@ -245,7 +775,7 @@ func (spm *SuperPositionManager) parseClientOrderID(clientOrderID string) (float
 	cleanID := utils.RemoveBrokerPrefix(exchangeName, clientOrderID)
 
 	// 2. 使用统一的 utils 包解析
-	price, side, _, valid := utils.ParseOrderID(cleanID, spm.priceDecimals)
+	price, side, _, valid := utils.ParseOrderID(cleanID, spm.orderIDDecimals)
 	if !valid {
 		return 0, "", false
 	}
@@ -263,15 +793,36 @@ func (spm *SuperPositionManager) placeInitialBuyOrders() error {
 	// 🔥 修改：只恢复持仓槽位，不再主动下单
 	// 所有下单操作由 AdjustOrders 统一处理，避免时序问题
 	existingPosition := spm.getExistingPosition()
+	// 订单流在初始化之前启动，初始化期间到达的成交推送已计入槽位，交易所持仓中也已包含这部分成交，
+	// 从持仓中扣除后再恢复，避免同一笔成交既由推送计入又由持仓恢复重复计入（在查询持仓之后统计）
+	if tracked := spm.trackedPositionQty(); tracked > 0 && existingPosition > 0 {
+		positionLog.Info("🔄 [持仓恢复] 初始化期间的成交推送已计入持仓 %.4f，从交易所持仓 %.4f 中扣除", tracked, existingPosition)
+		if existingPosition -= tracked; existingPosition < 0.000001 {
+			existingPosition = 0
+		}
+	}
 	if existingPosition > 0 {
-		logger.Info("🔄 [持仓恢复] 检测到现有持仓: %.4f，开始初始化卖单槽位", existingPosition)
+		positionLog.Info("🔄 [持仓恢复] 检测到现有持仓: %.4f，开始初始化卖单槽位", existingPosition)
 		spm.initializeSellSlotsFromPosition(existingPosition)
 	}
 
-	logger.Info("✅ [初始化] 槽位已创建，订单下达将由 AdjustOrders 统一处理")
+	positionLog.Info("✅ [初始化] 槽位已创建，订单下达将由 AdjustOrders 统一处理")
 	return nil
 }
 
+// trackedPositionQty 槽位已记录的持仓合计
+func (spm *SuperPositionManager) trackedPositionQty() float64 {
+	total := 0.0
+	spm.slots.Range(func(_, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		total += slot.PositionQty
+		slot.mu.RUnlock()
+		return true
+	})
+	return total
+}
+
 // AdjustOrders 调整订单（交易入口）
 func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 	// 🔥 移除初始化检查：现在完全由 AdjustOrders 控制所有下单
@@ -282,7 +833,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 
 	// 验证价格有效性
 	if currentPrice <= 0 {
-		logger.Warn("⚠️ 收到无效价格: %.2f，跳过订单调整", currentPrice)
+		positionLog.Warn("⚠️ 收到无效价格: %.2f，跳过订单调整", currentPrice)
 		return nil
 	}
 
@@ -292,46 +843,71 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 	// 更新最后市场价格（用于打印状态）
 	spm.lastMarketPrice.Store(currentPrice)
 
+	// 对账正在比对订单集合时跳过本次调整（不等待，避免阻塞价格循环；下一次价格变化时补挂）
+	if spm.config.Trading.ReconcileGuard {
+		if !spm.orderSetMu.TryRLock() {
+			positionLog.Debug("⏸️ [实时调整] 对账进行中，跳过本次订单调整")
+			return nil
+		}
+		defer spm.orderSetMu.RUnlock()
+	}
+
 	// 检查保证金不足状态
 	if spm.insufficientMargin {
 		if time.Since(spm.marginLockTime) >= spm.marginLockDuration {
-			logger.Info("✅ [保证金恢复] 锁定时间已过，恢复下单功能")
+			positionLog.Info("✅ [保证金恢复] 锁定时间已过，恢复下单功能")
 			spm.insufficientMargin = false
 		} else {
 			remainingTime := spm.marginLockDuration - time.Since(spm.marginLockTime)
-			logger.Warn("⏸️ [暂停下单] 保证金不足，暂停下单中... (剩余时间: %.0f秒)", remainingTime.Seconds())
+			positionLog.Warn("⏸️ [暂停下单] 保证金不足，暂停下单中... (剩余时间: %.0f秒)", remainingTime.Seconds())
+			spm.deferredOrders.Store(0) // 暂停期间不补挂，恢复后由价格变化触发
 			return nil
 		}
 	}
 
 	// 计算需要监控的价格范围
-	buyWindowSize := spm.config.Trading.BuyWindowSize
-	sellWindowSize := spm.config.Trading.SellWindowSize
-	priceInterval := spm.config.Trading.PriceInterval
+	buyWindowSize := spm.effectiveBuyWindow()
+	sellWindowSize := spm.tradingParams.Load().SellWindowSize
+	priceInterval := spm.GetPriceInterval()
 
 	// 动态计算网格价格
 	currentGridPrice := spm.findNearestGridPrice(currentPrice)
-	// logger.Debug("🔄 [实时调整] 当前价格: %s, 网格价格: %s, 买单窗口: %d, 卖单窗口: %d",
+	// positionLog.Debug("🔄 [实时调整] 当前价格: %s, 网格价格: %s, 买单窗口: %d, 卖单窗口: %d",
 	// 	formatPrice(currentPrice, spm.priceDecimals), formatPrice(currentGridPrice, spm.priceDecimals), buyWindowSize, sellWindowSize)
 
 	// 计算当前网格价格下方buy_window_size个价格
-	slotPrices := spm.calculateSlotPrices(currentGridPrice, buyWindowSize, "down")
+	// 阶梯模式下买单固定在锚点下方，不随价格上移（高于当前价格的层由下方的安全检查跳过）
+	ladderTop := currentGridPrice
+	if spm.config.Trading.LadderMode {
+		ladderTop = spm.anchorPrice
+	}
+	slotPrices := spm.calculateSlotPrices(ladderTop, buyWindowSize, "down")
+
+	// 最近一档偏移：买单不高于 当前价格 - offset，卖单不低于 当前价格 + offset
+	firstOffset := spm.firstLevelOffset(currentPrice)
+	buyPriceCeiling := roundPrice(currentPrice-firstOffset, spm.priceDecimals)
 
 	var ordersToPlace []*OrderRequest
 	var activeBuyOrdersInWindow int
+	buyReason, sellReason := spm.placementReasons()
 
 	// 统计当前所有订单数量（分别统计买单和卖单）
 	var currentOrderCount int
 	var currentBuyOrderCount int
 	var currentSellOrderCount int
+	var exposure float64 // 持仓 + 挂单中买单的名义价值（资金分配上限检查用）
+	var holdings float64 // 槽位持仓合计（库存偏斜用）
 	spm.slots.Range(func(key, value interface{}) bool {
 		slot := value.(*InventorySlot)
 		slot.mu.RLock()
+		exposure += slot.PositionQty * slot.Price
+		holdings += slot.PositionQty
 		if slot.OrderStatus == OrderStatusPlaced || slot.OrderStatus == OrderStatusConfirmed ||
 			slot.OrderStatus == OrderStatusPartiallyFilled {
 			currentOrderCount++
 			if slot.OrderSide == "BUY" {
 				currentBuyOrderCount++
+				exposure += spm.GetOrderQuantity()
 			} else if slot.OrderSide == "SELL" {
 				currentSellOrderCount++
 			}
@@ -340,6 +916,14 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 		return true
 	})
 
+	// 库存偏斜：净持仓越多，买单上限下移、卖单价格收紧；买单窗口向下补足被跳过的层数
+	skewShift := spm.inventorySkewShift(holdings, priceInterval)
+	spm.logInventorySkew(holdings, skewShift)
+	if skewShift > 0 {
+		buyPriceCeiling = roundPrice(buyPriceCeiling-skewShift, spm.priceDecimals)
+		slotPrices = spm.calculateSlotPrices(ladderTop, buyWindowSize+int(math.Ceil(skewShift/priceInterval)), "down")
+	}
+
 	// 计算允许创建的订单数量上限
 	threshold := spm.config.Trading.OrderCleanupThreshold
 	if threshold <= 0 {
@@ -359,6 +943,46 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 		allowedNewBuyOrders = remainingOrders
 	}
 
+	// 资金分配上限：新增买单后的名义价值不超过 分配金额 × 杠杆
+	if spm.capitalAllocation > 0 {
+		maxExposure := spm.capitalAllocation * float64(spm.allocationLeverage)
+		allowedByCapital := int((maxExposure - exposure) / spm.GetOrderQuantity())
+		if allowedByCapital < 0 {
+			allowedByCapital = 0
+		}
+		if allowedByCapital < allowedNewBuyOrders {
+			allowedNewBuyOrders = allowedByCapital
+			if allowedByCapital == 0 && !spm.allocationCapped {
+				positionLog.Warn("💼 [资金分配] 持仓+买单名义价值 %.2f 已达上限 %.2f，暂停新增买单", exposure, maxExposure)
+				spm.allocationCapped = true
+			}
+		} else if spm.allocationCapped {
+			positionLog.Info("💼 [资金分配] 名义价值 %.2f 已低于上限 %.2f，恢复新增买单", exposure, maxExposure)
+			spm.allocationCapped = false
+		}
+	}
+
+	// 可用保证金下限：新增买单不得让可用保证金比例低于 min_free_margin_percent（不论持仓层数）
+	if limit := spm.freeMarginBuyLimit(); limit >= 0 {
+		if limit < allowedNewBuyOrders {
+			allowedNewBuyOrders = limit
+		}
+		if limit == 0 && !spm.freeMarginPaused {
+			positionLog.Warn("🛡️ [保证金下限] 可用保证金比例 %.1f%%，再挂买单将低于下限 %.1f%%，暂停新增买单",
+				spm.FreeMarginPercent(), spm.config.Trading.MinFreeMarginPercent)
+			spm.freeMarginPaused = true
+		} else if limit > 0 && spm.freeMarginPaused {
+			positionLog.Info("🛡️ [保证金下限] 可用保证金比例回升至 %.1f%% (下限 %.1f%%)，恢复新增买单",
+				spm.FreeMarginPercent(), spm.config.Trading.MinFreeMarginPercent)
+			spm.freeMarginPaused = false
+		}
+	}
+
+	// 安全复核未通过时只暂停新增买单，已有持仓的卖单照常挂出
+	if spm.buyPauseChecker != nil && spm.buyPauseChecker() {
+		allowedNewBuyOrders = 0
+	}
+
 	// 1. 处理买单
 	buyOrdersToCreate := 0
 
@@ -396,16 +1020,22 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 			buyOrdersToCreate < allowedNewBuyOrders
 
 		if shouldCreateBuyOrder {
-			// 安全检查：买单价格不应高于当前价格
-			safetyBuffer := spm.config.Trading.PriceInterval * 0.1
-			if price >= currentPrice-safetyBuffer {
+			// 安全检查：买单价格不应高于当前价格，且至少低于当前价格 first_level_offset
+			safetyBuffer := spm.GetPriceInterval() * 0.1
+			if price >= currentPrice-safetyBuffer || price > buyPriceCeiling {
 				slot.mu.Unlock()
 				continue
 			}
 
-			quantity := spm.config.Trading.OrderQuantity / price
 			// 使用从交易所获取的数量精度
-			quantity = roundPrice(quantity, spm.quantityDecimals)
+			quantity := spm.orderQuantityFor(price)
+
+			// 低价层取整后可能低于最小名义价值：自动上调数量或跳过该层，避免发出必然被拒的订单
+			var ok bool
+			if quantity, ok = spm.ensureMinNotional(price, quantity); !ok {
+				slot.mu.Unlock()
+				continue
+			}
 
 			// 生成 ClientOrderID
 			clientOID := spm.generateClientOrderID(price, "BUY")
@@ -424,6 +1054,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				PriceDecimals: spm.priceDecimals,
 				PostOnly:      usePostOnly,
 				ClientOrderID: clientOID,
+				Reason:        buyReason,
 			})
 			buyOrdersToCreate++
 		}
@@ -433,7 +1064,9 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 
 	// 2. 处理卖单
 	sellWindowMaxPrice := currentPrice + float64(sellWindowSize)*priceInterval
+	sellPriceFloor := roundPrice(currentPrice+firstOffset, spm.priceDecimals)
 	sellWindowMaxPrice = roundPrice(sellWindowMaxPrice, spm.priceDecimals)
+	minValue := spm.minOrderValue()
 
 	type sellCandidate struct {
 		SlotPrice     float64 // 槽位价格 (买入价)
@@ -455,22 +1088,19 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 			slot.OrderID == 0 &&
 			slot.ClientOID == "" {
 
-			sellPrice := slotPrice + priceInterval
-			sellPrice = roundPrice(sellPrice, spm.priceDecimals)
+			sellPrice := spm.skewedSellPrice(slotPrice, priceInterval, skewShift)
 
 			// 窗口检查
 			if slotPrice > sellWindowMaxPrice {
 				return true
 			}
-
-			// 最小名义价值检查
-			orderValue := sellPrice * slot.PositionQty
-			minValue := spm.config.Trading.MinOrderValue
-			if minValue <= 0 {
-				minValue = 6.0
+			// 卖出价距当前价格不足 first_level_offset 时暂不挂出，避免挂单即成交
+			if firstOffset > 0 && sellPrice < sellPriceFloor {
+				return true
 			}
 
-			if orderValue >= minValue {
+			// 最小名义价值检查
+			if spm.meetsMinNotional(sellPrice, slot.PositionQty, minValue) {
 				distance := math.Abs(slotPrice - currentPrice)
 				sellCandidates = append(sellCandidates, sellCandidate{
 					SlotPrice:     slotPrice,
@@ -502,7 +1132,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 	// 生成卖单请求
 	sellOrdersToCreate := 0
 	// 🔥 调试日志: 显示订单配额计算详情（包含买卖单分布）
-	logger.Debug("📊 [订单配额] 阈值:%d, 当前订单:%d(买:%d/卖:%d), 剩余:%d, 新增买单:%d, 卖单候选:%d, 允许卖单:%d",
+	positionLog.Debug("📊 [订单配额] 阈值:%d, 当前订单:%d(买:%d/卖:%d), 剩余:%d, 新增买单:%d, 卖单候选:%d, 允许卖单:%d",
 		threshold, currentOrderCount, currentBuyOrderCount, currentSellOrderCount, remainingOrders, buyOrdersToCreate, len(sellCandidates), allowedNewSellOrders)
 	if allowedNewSellOrders > 0 {
 		for i := 0; i < len(sellCandidates) && sellOrdersToCreate < allowedNewSellOrders; i++ {
@@ -544,21 +1174,29 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				ReduceOnly:    true,
 				PostOnly:      usePostOnly,
 				ClientOrderID: clientOID, // 🔥
+				Reason:        sellReason,
 			})
 			sellOrdersToCreate++
 		}
 	}
 
+	// 单次调整的下单数量上限：优先挂离价格最近的订单，其余延后到下次调整
+	ordersToPlace = spm.limitOrdersPerTick(ordersToPlace, currentPrice)
+
 	// 执行下单
 	if len(ordersToPlace) > 0 {
-		logger.Debug("🔄 [实时调整] 需要新增: %d 个订单", len(ordersToPlace))
+		positionLog.Debug("🔄 [实时调整] 需要新增: %d 个订单", len(ordersToPlace))
 		placedOrders, marginError := spm.executor.BatchPlaceOrders(ordersToPlace)
+		if len(placedOrders) > 0 {
+			spm.initialPlaced = true
+			spm.placementReason = ""
+		}
 
 		if marginError {
-			logger.Warn("⚠️ [保证金不足] 检测到保证金不足错误，暂停下单 %d 秒", int(spm.marginLockDuration.Seconds()))
+			positionLog.Warn("⚠️ [保证金不足] 检测到保证金不足错误，暂停下单 %d 秒", int(spm.marginLockDuration.Seconds()))
 			spm.insufficientMargin = true
 			spm.marginLockTime = time.Now()
-			spm.CancelAllBuyOrders()
+			spm.CancelAllBuyOrders(event.OrderReasonMargin)
 		}
 
 		// 🔥 构建成功订单的ClientOrderID集合
@@ -577,7 +1215,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 					slot.mu.Lock()
 					if slot.SlotStatus == SlotStatusPending {
 						slot.SlotStatus = SlotStatusFree
-						logger.Debug("🔓 [释放槽位] 订单提交失败，释放槽位 %s 的锁 (ClientOID: %s)",
+						positionLog.Debug("🔓 [释放槽位] 订单提交失败，释放槽位 %s 的锁 (ClientOID: %s)",
 							formatPrice(price, spm.priceDecimals), req.ClientOrderID)
 					}
 					slot.mu.Unlock()
@@ -590,9 +1228,12 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 			price, side, valid := spm.parseClientOrderID(ord.ClientOrderID)
 
 			if !valid {
-				logger.Warn("⚠️ [实时调整] 无法解析 ClientOID: %s", ord.ClientOrderID)
+				positionLog.Warn("⚠️ [实时调整] 无法解析 ClientOID: %s", ord.ClientOrderID)
 				continue
 			}
+			if side == "BUY" {
+				spm.buysSinceMarginSample.Add(1)
+			}
 
 			// 获取槽位 (注意：无论是买单还是卖单，ID中编码的都是 SlotPrice)
 			slot := spm.getOrCreateSlot(price)
@@ -618,11 +1259,11 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				if slot.OrderID != 0 && slot.OrderID != ord.OrderID {
 					if slot.ClientOID != "" && slot.ClientOID != ord.ClientOrderID {
 						// 真正的冲突：槽位已被其他订单占用
-						logger.Warn("⚠️ [OrderID冲突] 槽位 %.2f: 下单返回OrderID=%d (ClientOID=%s)，但槽位已被OrderID=%d (ClientOID=%s)占用",
+						positionLog.Warn("⚠️ [OrderID冲突] 槽位 %.2f: 下单返回OrderID=%d (ClientOID=%s)，但槽位已被OrderID=%d (ClientOID=%s)占用",
 							price, ord.OrderID, ord.ClientOrderID, slot.OrderID, slot.ClientOID)
 					} else {
 						// WebSocket推送先到达，这是正常现象
-						logger.Debug("📝 [覆盖OrderID] 槽位 %.2f: WebSocket已设置OrderID=%d，现用下单返回的OrderID=%d (ClientOID: %s)",
+						positionLog.Debug("📝 [覆盖OrderID] 槽位 %.2f: WebSocket已设置OrderID=%d，现用下单返回的OrderID=%d (ClientOID: %s)",
 							price, slot.OrderID, ord.OrderID, ord.ClientOrderID)
 					}
 				}
@@ -638,11 +1279,11 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				// 注意：不在这里重置PostOnlyFailCount，因为订单可能立即被撤销
 				// PostOnly计数只在订单真正成交时重置
 
-				logger.Debug("✅ [实时新增] 槽位价格: %s, %s订单, 订单价格: %s, 订单ID: %d, ClientOID: %s",
+				positionLog.Debug("✅ [实时新增] 槽位价格: %s, %s订单, 订单价格: %s, 订单ID: %d, ClientOID: %s",
 					formatPrice(price, spm.priceDecimals), side, formatPrice(ord.Price, spm.priceDecimals), ord.OrderID, ord.ClientOrderID)
 			} else {
 				// 🔍 秒成交场景：WebSocket已经处理了FILLED,跳过状态更新
-				logger.Debug("🔍 [%s单秒成交] 槽位 %s 的订单已被WebSocket处理，跳过状态更新 (持仓: %.4f, SlotStatus: %s)",
+				positionLog.Debug("🔍 [%s单秒成交] 槽位 %s 的订单已被WebSocket处理，跳过状态更新 (持仓: %.4f, SlotStatus: %s)",
 					side, formatPrice(price, spm.priceDecimals), slot.PositionQty, slot.SlotStatus)
 			}
 
@@ -659,7 +1300,7 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 	price, side, valid := spm.parseClientOrderID(update.ClientOrderID)
 
 	if !valid {
-		logger.Debug("⏳ [忽略] 无法识别的订单更新: ID=%d, ClientOID=%s", update.OrderID, update.ClientOrderID)
+		positionLog.Debug("⏳ [忽略] 无法识别的订单更新: ID=%d, ClientOID=%s", update.OrderID, update.ClientOrderID)
 		return
 	}
 
@@ -667,24 +1308,46 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 	slot.mu.Lock()
 	defer slot.mu.Unlock()
 
+	// 乱序保护：忽略迟到的旧推送（持有槽位锁，保证判断与处理的顺序一致）
+	if !spm.acceptOrderUpdate(update) {
+		return
+	}
+
 	// 校验：确保这个更新属于当前的订单 (防止旧订单的延迟推送干扰新订单)
 	// 优先使用 ClientOrderID 匹配 (某些交易所如 Gate.io 的 OrderID 可能略有差异)
 	if slot.ClientOID != "" && slot.ClientOID != update.ClientOrderID {
 		// ClientOrderID 不匹配，忽略此更新
-		logger.Info("⚠️ [订单更新被忽略] 槽位 %.2f: ClientOID不匹配 (槽位: %s, 推送: %s, OrderID: %d)",
+		positionLog.Info("⚠️ [订单更新被忽略] 槽位 %.2f: ClientOID不匹配 (槽位: %s, 推送: %s, OrderID: %d)",
 			price, slot.ClientOID, update.ClientOrderID, update.OrderID)
 		return
 	}
 
+	// 增量成交推送去重：增量推送无法通过与已记录成交数量的差值去重，重复投递的同一条推送按订单和 UpdateTime 识别
+	// （在更新订单ID之前判断，避免订单结束后重复投递的推送把槽位恢复成挂单）
+	if spm.incrementalFills && (update.Status == "PARTIALLY_FILLED" || update.Status == "FILLED") {
+		key := incrementalFillKey{
+			clientOID:   update.ClientOrderID,
+			updateTime:  update.UpdateTime,
+			status:      update.Status,
+			executedQty: update.ExecutedQty,
+		}
+		if update.UpdateTime > 0 && key == slot.lastIncrementalFill {
+			positionLog.Warn("🔁 [重复成交推送] 槽位 %s: 订单 %d 的 %s 推送 (UpdateTime: %d, 成交 %.6f) 已计入，忽略",
+				formatPrice(price, spm.priceDecimals), update.OrderID, update.Status, update.UpdateTime, update.ExecutedQty)
+			return
+		}
+		slot.lastIncrementalFill = key
+	}
+
 	// 更新订单ID (如果是首个推送)
 	if slot.OrderID == 0 {
-		logger.Debug("📝 [首次设置OrderID] 槽位 %.2f: OrderID=%d, ClientOID=%s", price, update.OrderID, update.ClientOrderID)
+		positionLog.Debug("📝 [首次设置OrderID] 槽位 %.2f: OrderID=%d, ClientOID=%s", price, update.OrderID, update.ClientOrderID)
 		slot.OrderID = update.OrderID
 		slot.ClientOID = update.ClientOrderID
 		slot.OrderSide = side
 	} else if slot.OrderID != update.OrderID {
 		// OrderID 不一致但 ClientOrderID 匹配，更新 OrderID (Gate.io 批量下单可能出现此情况)
-		logger.Debug("📝 [更新OrderID] 槽位 %.2f: %d -> %d (ClientOID: %s)", price, slot.OrderID, update.OrderID, update.ClientOrderID)
+		positionLog.Debug("📝 [更新OrderID] 槽位 %.2f: %d -> %d (ClientOID: %s)", price, slot.OrderID, update.OrderID, update.ClientOrderID)
 		slot.OrderID = update.OrderID
 	}
 
@@ -696,17 +1359,55 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 		}
 
 	case "PARTIALLY_FILLED", "FILLED":
-		// 计算增量
-		deltaQty := update.ExecutedQty - slot.OrderFilledQty
-		if deltaQty < 0 {
-			deltaQty = 0
+		// 计算增量：增量推送直接累加，累计推送取与已记录成交数量的差值
+		var deltaQty float64
+		if spm.incrementalFills {
+			deltaQty = update.ExecutedQty
+			slot.OrderFilledQty += deltaQty
+		} else {
+			deltaQty = update.ExecutedQty - slot.OrderFilledQty
+			if deltaQty < 0 {
+				// 累计成交数量不会减少，出现回退说明交易所推送的很可能是增量
+				positionLog.Warn("⚠️ [成交数量回退] 槽位 %s: 已记录 %.6f, 推送 %.6f (OrderID: %d)，交易所可能推送的是增量成交数量，请检查 executed_qty_mode",
+					formatPrice(price, spm.priceDecimals), slot.OrderFilledQty, update.ExecutedQty, update.OrderID)
+				deltaQty = 0
+			} else if deltaQty == 0 && update.Status == "PARTIALLY_FILLED" {
+				positionLog.Debug("🔁 [重复成交推送] 槽位 %s: 累计成交 %.6f 未变化 (OrderID: %d)",
+					formatPrice(price, spm.priceDecimals), update.ExecutedQty, update.OrderID)
+			}
+			if update.ExecutedQty > slot.OrderFilledQty {
+				slot.OrderFilledQty = update.ExecutedQty
+			}
 		}
 
-		slot.OrderFilledQty = update.ExecutedQty
+		orderPrice := update.Price
+		if orderPrice <= 0 {
+			orderPrice = slot.OrderPrice
+		}
+		fillPrice := update.AvgPrice
+		if fillPrice <= 0 {
+			fillPrice = orderPrice
+		}
+		if deltaQty > 0 {
+			event.Publish(event.TypeOrderFilled, event.OrderFilled{
+				Symbol:     spm.config.Trading.Symbol,
+				Side:       side,
+				SlotPrice:  price,
+				Price:      fillPrice,
+				OrderPrice: orderPrice,
+				Quantity:   deltaQty,
+				OrderID:    update.OrderID,
+				Complete:   update.Status == "FILLED",
+			})
+		}
 
 		// 根据方向更新持仓
 		if side == "BUY" {
 			if deltaQty > 0 {
+				if slot.PositionOpenedAt.IsZero() {
+					slot.PositionOpenedAt = time.Now()
+				}
+				slot.PositionCost = slot.positionCost() + fillPrice*deltaQty
 				slot.PositionQty += deltaQty
 				// 累加统计
 				oldTotal := spm.totalBuyQty.Load().(float64)
@@ -725,17 +1426,18 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 				slot.SlotStatus = SlotStatusFree
 				// 🔥 买单成交，重置PostOnly失败计数
 				slot.PostOnlyFailCount = 0
-				logger.Info("✅ [买单成交] 价格: %s, 持仓: %.4f, 槽位状态: %s -> %s, 订单状态: %s -> %s, SlotStatus: FREE",
+				positionLog.Info("✅ [买单成交] 价格: %s, 持仓: %.4f, 槽位状态: %s -> %s, 订单状态: %s -> %s, SlotStatus: FREE",
 					formatPrice(price, spm.priceDecimals), slot.PositionQty,
 					PositionStatusEmpty, PositionStatusFilled,
 					"FILLED", OrderStatusNotPlaced)
-				logger.Debug("🔍 [买单成交后] 等待下次AdjustOrders调用时挂出卖单...")
+				positionLog.Debug("🔍 [买单成交后] 等待下次AdjustOrders调用时挂出卖单...")
 			} else {
 				slot.OrderStatus = OrderStatusPartiallyFilled
 			}
 
 		} else { // SELL
 			if deltaQty > 0 {
+				slot.recordSellFill(fillPrice, deltaQty)
 				slot.PositionQty -= deltaQty
 				if slot.PositionQty < 0 {
 					slot.PositionQty = 0
@@ -754,20 +1456,25 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 
 				if slot.PositionQty < 0.000001 {
 					slot.PositionStatus = PositionStatusEmpty // 标记为空仓
+					slot.PositionOpenedAt = time.Time{}
+					slot.PositionCost = 0
 				}
 				// 🔥 释放槽位锁：卖单成交，允许后续挂买单
 				slot.SlotStatus = SlotStatusFree
 				// 🔥 卖单成交，重置PostOnly失败计数
 				slot.PostOnlyFailCount = 0
-				logger.Info("✅ [卖单成交] 价格: %s, 剩余持仓: %.4f, 槽位状态: %s, 订单状态: %s, SlotStatus: FREE",
+				positionLog.Info("✅ [卖单成交] 价格: %s, 剩余持仓: %.4f, 槽位状态: %s, 订单状态: %s, SlotStatus: FREE",
 					formatPrice(price, spm.priceDecimals), slot.PositionQty, slot.PositionStatus, slot.OrderStatus)
+				spm.logRoundTrip(price, slot, false)
+				// 卖单成交释放保证金，放开一层因保证金预估被延后的买单
+				spm.releaseSeedBuyLevel()
 			} else {
 				slot.OrderStatus = OrderStatusPartiallyFilled
 			}
 		}
 
 	case "CANCELED", "EXPIRED", "REJECTED":
-		logger.Info("⚠️ [订单%s] 价格: %s, 方向: %s, 原因: %s, 已成交: %.4f",
+		positionLog.Info("⚠️ [订单%s] 价格: %s, 方向: %s, 原因: %s, 已成交: %.4f",
 			update.Status, formatPrice(price, spm.priceDecimals), side, update.Status, slot.OrderFilledQty)
 
 		// 🔥 核心修复：根据订单方向和成交情况处理槽位状态
@@ -775,31 +1482,39 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 			// 买单被取消/拒绝
 			if slot.PositionQty > 0 || slot.OrderFilledQty > 0 {
 				// 部分成交后被取消：保留持仓，允许后续挂卖单
-				logger.Info("💡 [买单部分成交后取消] 价格: %s, 持仓: %.4f, 转为有仓状态",
+				positionLog.Info("💡 [买单部分成交后取消] 价格: %s, 持仓: %.4f, 转为有仓状态",
 					formatPrice(price, spm.priceDecimals), slot.PositionQty)
 				slot.PositionStatus = PositionStatusFilled
 				slot.SlotStatus = SlotStatusFree // 允许挂卖单
 			} else {
 				// 完全未成交被取消：重置为空槽位
-				logger.Info("🔄 [买单未成交取消] 价格: %s, 重置槽位为空闲",
+				positionLog.Info("🔄 [买单未成交取消] 价格: %s, 重置槽位为空闲",
 					formatPrice(price, spm.priceDecimals))
 				slot.PositionStatus = PositionStatusEmpty
 				slot.SlotStatus = SlotStatusFree // 允许重新挂买单
 			}
 		} else if side == "SELL" {
+			// 部分成交后被撤销：已卖出部分单独记一轮
+			spm.logRoundTrip(price, slot, true)
 			// 卖单被取消/拒绝：应该还持有币，保持持仓状态
 			if slot.PositionQty > 0 {
-				// 增加PostOnly失败计数（订单被交易所撤销通常是PostOnly失败）
-				slot.PostOnlyFailCount++
-				logger.Info("🔄 [卖单取消] 价格: %s, 保持持仓状态: %.4f, 等待重挂, PostOnly失败计数: %d",
+				// 增加PostOnly失败计数（订单被交易所撤销通常是PostOnly失败；手续费重新定价的主动撤单除外）
+				if slot.FeeRepricing {
+					slot.FeeRepricing = false
+				} else {
+					slot.PostOnlyFailCount++
+				}
+				positionLog.Info("🔄 [卖单取消] 价格: %s, 保持持仓状态: %.4f, 等待重挂, PostOnly失败计数: %d",
 					formatPrice(price, spm.priceDecimals), slot.PositionQty, slot.PostOnlyFailCount)
 				slot.PositionStatus = PositionStatusFilled
 				slot.SlotStatus = SlotStatusFree // 允许重新挂卖单
 			} else {
 				// 异常情况：卖单取消但没有持仓，重置为空
-				logger.Warn("⚠️ [异常] 卖单取消但无持仓，价格: %s, 重置为空",
+				positionLog.Warn("⚠️ [异常] 卖单取消但无持仓，价格: %s, 重置为空",
 					formatPrice(price, spm.priceDecimals))
 				slot.PositionStatus = PositionStatusEmpty
+				slot.PositionOpenedAt = time.Time{}
+				slot.PositionCost = 0
 				slot.SlotStatus = SlotStatusFree
 			}
 		}
@@ -831,15 +1546,71 @@ func (spm *SuperPositionManager) getOrCreateSlot(price float64) *InventorySlot {
 	return slot
 }
 
+// placementReasons 本次调整新增买单和卖单的原因（调用前必须持有 mu）
+// 首次挂单记为 initial，重置锚点或风控解除后的第一批挂单记为对应原因，其余为补挂买单 / 成交后挂卖单
+func (spm *SuperPositionManager) placementReasons() (buy, sell string) {
+	switch {
+	case !spm.initialPlaced:
+		return event.OrderReasonInitial, event.OrderReasonInitial
+	case spm.placementReason != "":
+		return spm.placementReason, spm.placementReason
+	default:
+		return event.OrderReasonGridBuy, event.OrderReasonFillSell
+	}
+}
+
+// SetPlacementReason 设置下一批挂单的原因（如风控解除后恢复挂单），挂出订单后自动清除
+func (spm *SuperPositionManager) SetPlacementReason(reason string) {
+	spm.mu.Lock()
+	spm.placementReason = reason
+	spm.mu.Unlock()
+}
+
+// limitOrdersPerTick 按 timing.max_orders_per_tick 限制单次调整的下单数量（0表示不限制）
+// 买卖单合并后按离当前价格的距离排序，只保留最近的若干个；其余订单释放槽位锁，留到下次调整时补挂
+func (spm *SuperPositionManager) limitOrdersPerTick(orders []*OrderRequest, currentPrice float64) []*OrderRequest {
+	maxOrders := spm.config.Timing.MaxOrdersPerTick
+	if maxOrders <= 0 || len(orders) <= maxOrders {
+		spm.deferredOrders.Store(0)
+		return orders
+	}
+
+	sort.SliceStable(orders, func(i, j int) bool {
+		return math.Abs(orders[i].Price-currentPrice) < math.Abs(orders[j].Price-currentPrice)
+	})
+	for _, req := range orders[maxOrders:] {
+		price, _, valid := spm.parseClientOrderID(req.ClientOrderID)
+		if !valid {
+			continue
+		}
+		slot := spm.getOrCreateSlot(price)
+		slot.mu.Lock()
+		if slot.SlotStatus == SlotStatusPending {
+			slot.SlotStatus = SlotStatusFree
+		}
+		slot.mu.Unlock()
+	}
+
+	deferred := len(orders) - maxOrders
+	spm.deferredOrders.Store(int64(deferred))
+	positionLog.Debug("⏳ [下单节流] 本次最多新增 %d 个订单，延后 %d 个到下次调整", maxOrders, deferred)
+	return orders[:maxOrders]
+}
+
+// HasDeferredOrders 上次调整订单时是否有订单因 timing.max_orders_per_tick 延后
+func (spm *SuperPositionManager) HasDeferredOrders() bool {
+	return spm.deferredOrders.Load() > 0
+}
+
 // findNearestGridPrice 找到最近的网格价格
 // 根据当前价格动态计算最近的网格对齐价格
 func (spm *SuperPositionManager) findNearestGridPrice(currentPrice float64) float64 {
 	// 计算当前价格相对于锚点的偏移量
 	offset := currentPrice - spm.anchorPrice
 	// 计算离当前价格最近的网格间隔数（四舍五入）
-	intervals := math.Round(offset / spm.config.Trading.PriceInterval)
+	intervals := math.Round(offset / spm.GetPriceInterval())
 	// 计算最近的网格价格
-	gridPrice := spm.anchorPrice + intervals*spm.config.Trading.PriceInterval
+	gridPrice := spm.anchorPrice + intervals*spm.GetPriceInterval()
 	// 使用检测到的价格精度进行舍入
 	return roundPrice(gridPrice, spm.priceDecimals)
 }
@@ -854,7 +1625,7 @@ func (spm *SuperPositionManager) findNearestGridPrice(currentPrice float64) floa
 // 返回：槽位价格列表，从网格价格开始，按价格间隔递减或递增，使用检测到的价格精度
 func (spm *SuperPositionManager) calculateSlotPrices(gridPrice float64, count int, direction string) []float64 {
 	var prices []float64
-	priceInterval := spm.config.Trading.PriceInterval
+	priceInterval := spm.GetPriceInterval()
 
 	for i := 0; i < count; i++ {
 		var price float64
@@ -888,6 +1659,16 @@ type SlotData struct {
 	OrderCreatedAt time.Time
 }
 
+// LockOrderSet 对账比对订单集合期间阻止 AdjustOrders 新增挂单，返回解锁函数
+// 会等待进行中的下单完成；未启用 trading.reconcile_guard 时直接返回
+func (spm *SuperPositionManager) LockOrderSet() func() {
+	if !spm.config.Trading.ReconcileGuard {
+		return func() {}
+	}
+	spm.orderSetMu.Lock()
+	return spm.orderSetMu.Unlock
+}
+
 // IterateSlots 遍历所有槽位（封装 sync.Map.Range）
 // 注意：为了避免类型冲突，这里使用 interface{} 返回槽位数据
 // 调用者需要将其转换为具体的槽位信息
@@ -914,6 +1695,127 @@ func (spm *SuperPositionManager) IterateSlots(fn func(price float64, slot interf
 	})
 }
 
+// StatusSnapshot 仓位管理器状态快照（供管理接口、事件推送使用）
+type StatusSnapshot struct {
+	Symbol           string      `json:"symbol"`
+	AnchorPrice      float64     `json:"anchor_price"`
+	PriceInterval    float64     `json:"price_interval"`
+	LastPrice        float64     `json:"last_price"`
+	PositionQty      float64     `json:"position_qty"`       // 槽位持仓合计
+	FilledSlots      int         `json:"filled_slots"`       // 有持仓的槽位数
+	ActiveBuyOrders  int         `json:"active_buy_orders"`  // 挂单中的买单
+	ActiveSellOrders int         `json:"active_sell_orders"` // 挂单中的卖单
+	TotalBuyQty      float64     `json:"total_buy_qty"`
+	TotalSellQty     float64     `json:"total_sell_qty"`
+	FeeRate          float64     `json:"fee_rate"`
+	EstimatedProfit  float64     `json:"estimated_profit"` // 预计盈利 = 累计卖出数量 × 价格间距
+	Levels           []SlotLevel `json:"levels,omitempty"` // 网格槽位（按价格从高到低）
+
+	DeployedNotional float64 `json:"deployed_notional"` // 当前持仓的买入成本合计
+	AvgEntryPrice    float64 `json:"avg_entry_price"`   // 当前持仓的平均买入价（无持仓时为0）
+	MarkPrice        float64 `json:"mark_price"`        // 计算浮动盈亏使用的市场价格
+	UnrealizedPnL    float64 `json:"unrealized_pnl"`    // 浮动盈亏 = 持仓数量 × 市场价格 - 买入成本（不含手续费）
+
+	BuyWindowSize      int `json:"buy_window_size"`      // 配置的买单窗口层数
+	EffectiveBuyWindow int `json:"effective_buy_window"` // 当前生效的买单窗口层数（受保证金和回撤限制）
+
+	FreeMarginPercent    float64 `json:"free_margin_percent"`     // 可用保证金占保证金余额的百分比（未启用 min_free_margin_percent 时为0）
+	MinFreeMarginPercent float64 `json:"min_free_margin_percent"` // 可用保证金比例下限（0表示不限制）
+}
+
+// SlotLevel 单个网格槽位的状态
+type SlotLevel struct {
+	Price       float64 `json:"price"`
+	PositionQty float64 `json:"position_qty"`
+	OrderSide   string  `json:"order_side,omitempty"` // 挂单方向（无挂单时为空）
+	OrderPrice  float64 `json:"order_price,omitempty"`
+	OrderStatus string  `json:"order_status,omitempty"`
+	SlotStatus  string  `json:"slot_status"`
+}
+
+// GetStatusSnapshot 获取当前状态快照
+func (spm *SuperPositionManager) GetStatusSnapshot() StatusSnapshot {
+	spm.mu.RLock()
+	anchorPrice := spm.anchorPrice // 风控解除、价格精度变化后锚点会被重置
+	spm.mu.RUnlock()
+
+	snapshot := StatusSnapshot{
+		Symbol:        spm.config.Trading.Symbol,
+		AnchorPrice:   anchorPrice,
+		PriceInterval: spm.GetPriceInterval(),
+		TotalBuyQty:   spm.totalBuyQty.Load().(float64),
+		TotalSellQty:  spm.totalSellQty.Load().(float64),
+		FeeRate:       spm.feeRate.Load().(float64),
+	}
+	snapshot.EstimatedProfit = snapshot.TotalSellQty * spm.GetSellPriceInterval()
+	if lastPrice, ok := spm.lastMarketPrice.Load().(float64); ok {
+		snapshot.LastPrice = lastPrice
+	}
+
+	spm.slots.Range(func(key, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		defer slot.mu.RUnlock()
+
+		if slot.PositionStatus == PositionStatusFilled && slot.PositionQty > 0 {
+			snapshot.PositionQty += slot.PositionQty
+			snapshot.DeployedNotional += slot.positionCost()
+			snapshot.FilledSlots++
+		}
+		level := SlotLevel{
+			Price:       slot.Price,
+			PositionQty: slot.PositionQty,
+			SlotStatus:  slot.SlotStatus,
+		}
+		if slot.OrderID != 0 || slot.SlotStatus == SlotStatusLocked {
+			switch slot.OrderSide {
+			case "BUY":
+				snapshot.ActiveBuyOrders++
+			case "SELL":
+				snapshot.ActiveSellOrders++
+			}
+			level.OrderSide = slot.OrderSide
+			level.OrderPrice = slot.OrderPrice
+			level.OrderStatus = slot.OrderStatus
+		}
+		if level.PositionQty > 0 || level.OrderSide != "" {
+			snapshot.Levels = append(snapshot.Levels, level)
+		}
+		return true
+	})
+
+	sort.Slice(snapshot.Levels, func(i, j int) bool {
+		return snapshot.Levels[i].Price > snapshot.Levels[j].Price
+	})
+
+	snapshot.MarkPrice = spm.currentMarketPrice()
+	snapshot.BuyWindowSize = spm.GetBuyWindowSize()
+	snapshot.EffectiveBuyWindow = spm.effectiveBuyWindow()
+	snapshot.FreeMarginPercent = spm.FreeMarginPercent()
+	snapshot.MinFreeMarginPercent = spm.config.Trading.MinFreeMarginPercent
+	if snapshot.PositionQty > 0 {
+		snapshot.AvgEntryPrice = snapshot.DeployedNotional / snapshot.PositionQty
+		snapshot.UnrealizedPnL = snapshot.PositionQty*snapshot.MarkPrice - snapshot.DeployedNotional
+	}
+
+	return snapshot
+}
+
+// currentMarketPrice 最新市场价格：优先使用价格来源，其次最后一次调整订单的价格，都没有时使用锚点价格
+func (spm *SuperPositionManager) currentMarketPrice() float64 {
+	if spm.marketPriceSource != nil {
+		if price := spm.marketPriceSource(); price > 0 {
+			return price
+		}
+	}
+	if price, _ := spm.lastMarketPrice.Load().(float64); price > 0 {
+		return price
+	}
+	spm.mu.RLock()
+	defer spm.mu.RUnlock()
+	return spm.anchorPrice
+}
+
 // GetTotalBuyQty 获取累计买入数量（IPositionManager 接口方法，供 Reconciler 使用）
 func (spm *SuperPositionManager) GetTotalBuyQty() float64 {
 	return spm.totalBuyQty.Load().(float64)
@@ -944,9 +1846,90 @@ func (spm *SuperPositionManager) GetSymbol() string {
 	return spm.config.Trading.Symbol
 }
 
-// GetPriceInterval 获取价格间隔
+// GetPriceInterval 获取当前生效的价格间隔
 func (spm *SuperPositionManager) GetPriceInterval() float64 {
-	return spm.config.Trading.PriceInterval
+	return spm.priceInterval.Load().(float64)
+}
+
+// GetSellPriceInterval 获取当前生效的卖单间隔（卖单价格 - 槽位价格，即每笔盈利的价格差）
+func (spm *SuperPositionManager) GetSellPriceInterval() float64 {
+	return spm.sellIntervalFor(spm.GetPriceInterval())
+}
+
+// GetFeeRate 获取当前生效的手续费率
+func (spm *SuperPositionManager) GetFeeRate() float64 {
+	return spm.feeRate.Load().(float64)
+}
+
+// GetPriceDecimals 获取当前网格的价格精度
+func (spm *SuperPositionManager) GetPriceDecimals() int {
+	spm.mu.RLock()
+	defer spm.mu.RUnlock()
+	return spm.priceDecimals
+}
+
+// SetPriceInterval 调整价格间隔（自适应间隔使用）
+// 新的买单按新间隔挂出，已挂出的买单不在新网格上，因此撤销后由 AdjustOrders 重新挂单；
+// 已有持仓的卖单价格 = 槽位价格 + 新间隔。注意：撤单会阻塞数秒，调用方应在独立协程中调用
+func (spm *SuperPositionManager) SetPriceInterval(interval float64) {
+	if interval <= 0 {
+		return
+	}
+	interval = ceilToDecimals(interval, spm.priceDecimals)
+	old := spm.GetPriceInterval()
+	if interval == old {
+		return
+	}
+	if spm.config.Trading.LadderMode {
+		positionLog.Warn("🪜 [阶梯模式] 买单阶梯固定，忽略价格间隔调整 %s -> %s（重启后按配置的 price_interval 生效）",
+			formatPrice(old, spm.priceDecimals), formatPrice(interval, spm.priceDecimals))
+		return
+	}
+
+	spm.mu.Lock()
+	spm.priceInterval.Store(interval)
+	spm.resetMinNotionalNotices()
+	spm.mu.Unlock()
+
+	positionLog.Info("📐 [价格间隔] %s -> %s，撤销现有买单按新网格重新挂单",
+		formatPrice(old, spm.priceDecimals), formatPrice(interval, spm.priceDecimals))
+	spm.CancelAllBuyOrders(event.OrderReasonReprice)
+	spm.notifyGridChanged("价格间隔调整")
+}
+
+// Reanchor 以指定价格重置网格锚点（风控暂停解除后使用）
+// 新的买单按新锚点挂出；仍有挂单中的买单时撤销后由 AdjustOrders 重新挂单（撤单会阻塞数秒），
+// 已有持仓的槽位保持原价格，卖单价格 = 槽位价格 + 价格间隔；阶梯模式下阶梯固定，不重置锚点
+func (spm *SuperPositionManager) Reanchor(price float64, reason string) {
+	if price <= 0 {
+		return
+	}
+	if spm.config.Trading.LadderMode {
+		spm.mu.Lock()
+		anchor := spm.anchorPrice
+		spm.placementReason = event.OrderReasonRecovery
+		spm.mu.Unlock()
+		positionLog.Info("🪜 [阶梯模式] %s: 买单阶梯固定，不重置锚点（保持 %s）", reason, formatPrice(anchor, spm.priceDecimals))
+		return
+	}
+	spm.mu.Lock()
+	old := spm.anchorPrice
+	anchor := roundPrice(price, spm.priceDecimals)
+	if anchor == old {
+		spm.mu.Unlock()
+		return
+	}
+	spm.anchorPrice = anchor
+	spm.placementReason = event.OrderReasonReanchor
+	spm.resetMinNotionalNotices()
+	spm.mu.Unlock()
+
+	positionLog.Info("⚓ [重置锚点] %s: 网格锚点 %s -> %s，按当前价格重建买单窗口",
+		reason, formatPrice(old, spm.priceDecimals), formatPrice(anchor, spm.priceDecimals))
+	if spm.activeBuyOrders() > 0 {
+		spm.CancelAllBuyOrders(event.OrderReasonReanchor)
+	}
+	spm.notifyGridChanged(reason + "重置锚点")
 }
 
 // ===== 订单清理功能已迁移到 safety.OrderCleaner =====
@@ -960,8 +1943,8 @@ func (spm *SuperPositionManager) UpdateSlotOrderStatus(price float64, status str
 	slot.mu.Unlock()
 }
 
-// CancelAllBuyOrders 撤销所有买单（风控触发时使用）
-func (spm *SuperPositionManager) CancelAllBuyOrders() {
+// CancelAllBuyOrders 撤销所有买单（风控触发时使用），reason 为撤单原因（event.OrderReason*）
+func (spm *SuperPositionManager) CancelAllBuyOrders(reason string) {
 	var buyOrderIDs []int64
 	var buyPrices []float64
 
@@ -983,7 +1966,7 @@ func (spm *SuperPositionManager) CancelAllBuyOrders() {
 		return
 	}
 
-	logger.Info("🔄 [撤销买单] 准备撤销 %d 个买单以释放保证金", len(buyOrderIDs))
+	positionLog.Info("🔄 [撤销买单] 准备撤销 %d 个买单以释放保证金", len(buyOrderIDs))
 
 	// 🔥 重复尝试3次，确保撤单干净
 	for attempt := 1; attempt <= 3; attempt++ {
@@ -991,10 +1974,10 @@ func (spm *SuperPositionManager) CancelAllBuyOrders() {
 			break
 		}
 
-		logger.Info("🔄 [撤销买单] 第 %d 次尝试，剩余 %d 个订单", attempt, len(buyOrderIDs))
+		positionLog.Info("🔄 [撤销买单] 第 %d 次尝试，剩余 %d 个订单", attempt, len(buyOrderIDs))
 
-		if err := spm.executor.BatchCancelOrders(buyOrderIDs); err != nil {
-			logger.Error("❌ [撤销买单] 批量撤单失败: %v", err)
+		if err := spm.executor.BatchCancelOrders(buyOrderIDs, reason); err != nil {
+			positionLog.Error("❌ [撤销买单] 批量撤单失败: %v", err)
 		}
 
 		// 更新槽位状态
@@ -1029,15 +2012,15 @@ func (spm *SuperPositionManager) CancelAllBuyOrders() {
 			})
 
 			if len(buyOrderIDs) > 0 {
-				logger.Warn("⚠️ [撤销买单] 检测到 %d 个残留买单，继续清理", len(buyOrderIDs))
+				positionLog.Warn("⚠️ [撤销买单] 检测到 %d 个残留买单，继续清理", len(buyOrderIDs))
 			} else {
-				logger.Info("✅ [撤销买单] 所有买单已清理完成")
+				positionLog.Info("✅ [撤销买单] 所有买单已清理完成")
 				break
 			}
 		}
 	}
 
-	logger.Info("✅ [撤销买单] 清理完成")
+	positionLog.Info("✅ [撤销买单] 清理完成")
 }
 
 // ===== 对账功能已迁移到 safety.Reconciler =====
@@ -1049,18 +2032,48 @@ func (spm *SuperPositionManager) CancelAllBuyOrders() {
 func (spm *SuperPositionManager) CancelAllOrders() {
 	ctx := context.Background()
 	if err := spm.exchange.CancelAllOrders(ctx, spm.config.Trading.Symbol); err != nil {
-		logger.Error("❌ [%s] 撤销所有订单失败: %v", spm.exchange.GetName(), err)
+		positionLog.Error("❌ [%s] 撤销所有订单失败: %v", spm.exchange.GetName(), err)
 	} else {
-		logger.Info("✅ [%s] 撤销所有订单完成", spm.exchange.GetName())
+		positionLog.Info("✅ [%s] 撤销所有订单完成", spm.exchange.GetName())
 	}
 }
 
+// ResetAllSlots 清空所有槽位的持仓和订单状态（紧急平仓后使用：订单已撤销、持仓已市价平掉）
+// 返回清空的持仓数量合计
+func (spm *SuperPositionManager) ResetAllSlots() float64 {
+	var clearedQty float64
+	spm.slots.Range(func(key, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		slot.mu.Lock()
+		clearedQty += slot.PositionQty
+		slot.PositionStatus = PositionStatusEmpty
+		slot.PositionQty = 0
+		slot.PositionOpenedAt = time.Time{}
+		slot.PositionCost = 0
+		slot.resetRoundTrip()
+		slot.OrderID = 0
+		slot.ClientOID = ""
+		slot.OrderSide = ""
+		slot.OrderStatus = OrderStatusNotPlaced
+		slot.OrderPrice = 0
+		slot.OrderFilledQty = 0
+		slot.OrderCreatedAt = time.Time{}
+		slot.SlotStatus = SlotStatusFree
+		slot.PostOnlyFailCount = 0
+		slot.FeeRepricing = false
+		slot.mu.Unlock()
+		return true
+	})
+	positionLog.Info("🧹 [槽位重置] 已清空所有槽位，释放持仓 %.4f", clearedQty)
+	return clearedQty
+}
+
 // getExistingPosition 获取当前持仓数量（容错处理）
 func (spm *SuperPositionManager) getExistingPosition() float64 {
 	ctx := context.Background()
 	positionsInterface, err := spm.exchange.GetPositions(ctx, spm.config.Trading.Symbol)
 	if err != nil || positionsInterface == nil {
-		logger.Debug("🔍 [持仓恢复] 无法获取持仓信息: %v", err)
+		positionLog.Debug("🔍 [持仓恢复] 无法获取持仓信息: %v", err)
 		return 0
 	}
 
@@ -1071,7 +2084,7 @@ func (spm *SuperPositionManager) getExistingPosition() float64 {
 		// PositionInfo 切片（简化版）
 		for _, pos := range positions {
 			if pos != nil && pos.Symbol == spm.config.Trading.Symbol {
-				logger.Debug("🔍 [持仓恢复] 找到持仓 (PositionInfo): %.4f", pos.Size)
+				positionLog.Debug("🔍 [持仓恢复] 找到持仓 (PositionInfo): %.4f", pos.Size)
 				return pos.Size
 			}
 		}
@@ -1081,7 +2094,7 @@ func (spm *SuperPositionManager) getExistingPosition() float64 {
 			// 尝试直接类型断言为 PositionInfo
 			if posInfo, ok := pos.(*PositionInfo); ok {
 				if posInfo.Symbol == spm.config.Trading.Symbol {
-					logger.Debug("🔍 [持仓恢复] 找到持仓 (interface->PositionInfo): %.4f", posInfo.Size)
+					positionLog.Debug("🔍 [持仓恢复] 找到持仓 (interface->PositionInfo): %.4f", posInfo.Size)
 					return posInfo.Size
 				}
 			}
@@ -1089,7 +2102,7 @@ func (spm *SuperPositionManager) getExistingPosition() float64 {
 			if posMap, ok := pos.(map[string]interface{}); ok {
 				if symbol, ok := posMap["Symbol"].(string); ok && symbol == spm.config.Trading.Symbol {
 					if size, ok := posMap["Size"].(float64); ok {
-						logger.Debug("🔍 [持仓恢复] 找到持仓 (map): %.4f", size)
+						positionLog.Debug("🔍 [持仓恢复] 找到持仓 (map): %.4f", size)
 						return size
 					}
 				}
@@ -1097,13 +2110,13 @@ func (spm *SuperPositionManager) getExistingPosition() float64 {
 		}
 	default:
 		// 其他情况：使用反射尝试提取 Size 字段
-		logger.Debug("🔍 [持仓恢复] 持仓类型: %T，尝试使用反射提取", positionsInterface)
+		positionLog.Debug("🔍 [持仓恢复] 持仓类型: %T，尝试使用反射提取", positionsInterface)
 		// 尝试使用反射处理未知类型
 		// 注意：实际上 exchange 返回的是 []*exchange.Position，但因为接口返回 interface{}，所以需要特殊处理
 		return 0
 	}
 
-	logger.Debug("🔍 [持仓恢复] 未找到匹配的持仓")
+	positionLog.Debug("🔍 [持仓恢复] 未找到匹配的持仓")
 	return 0
 }
 
@@ -1117,39 +2130,41 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 	// 使用锚点价格作为参考价格，使用从交易所获取的数量精度
 
 	// 每单的理论数量 = 目标金额 / 锚点价格
-	theoryQtyPerSlot := spm.config.Trading.OrderQuantity / spm.anchorPrice
-	theoryQtyPerSlot = roundPrice(theoryQtyPerSlot, spm.quantityDecimals)
+	theoryQtyPerSlot := spm.orderQuantityFor(spm.anchorPrice)
+	if theoryQtyPerSlot <= 0 {
+		// 每单金额不足一个最小数量单位时按一个单位计算，避免槽位数无穷大
+		theoryQtyPerSlot = ceilToDecimals(spm.GetOrderQuantity()/spm.anchorPrice, spm.quantityDecimals)
+	}
 
 	// 2. 计算需要创建的总槽位数
 	totalSlotsNeeded := int(math.Ceil(totalPosition / theoryQtyPerSlot))
-	logger.Info("🔄 [持仓恢复] 总持仓: %.4f，每单理论数量: %.4f，需要创建 %d 个槽位",
+	positionLog.Info("🔄 [持仓恢复] 总持仓: %.4f，每单理论数量: %.4f，需要创建 %d 个槽位",
 		totalPosition, theoryQtyPerSlot, totalSlotsNeeded)
 
 	// 3. 确定窗口大小（前N个槽位可以立即挂卖单）
-	sellWindowSize := spm.config.Trading.SellWindowSize
+	sellWindowSize := spm.tradingParams.Load().SellWindowSize
 	if sellWindowSize <= 0 {
-		sellWindowSize = spm.config.Trading.BuyWindowSize // 默认与买单窗口相同
+		sellWindowSize = spm.GetBuyWindowSize() // 默认与买单窗口相同
 	}
 
 	// 4. 计算卖单槽位价格（从锚点价格 + 价格间隔开始）
 	// 卖单最低价 = 锚点价格 + 价格间隔（避免与买单最高价冲突）
-	sellStartPrice := spm.anchorPrice + spm.config.Trading.PriceInterval
+	sellStartPrice := spm.anchorPrice + spm.GetPriceInterval()
 	sellPrices := spm.calculateSlotPrices(sellStartPrice, totalSlotsNeeded, "up")
 
-	logger.Info("🔄 [持仓恢复] 从价格 %s 向上创建 %d 个槽位（前 %d 个将挂卖单）",
+	positionLog.Info("🔄 [持仓恢复] 从价格 %s 向上创建 %d 个槽位（前 %d 个将挂卖单）",
 		formatPrice(sellStartPrice, spm.priceDecimals), totalSlotsNeeded, sellWindowSize)
 
 	// 5. 先计算所有槽位的理论数量总和（固定金额模式）
 	var totalTheoryQty float64
 	theoryQtys := make([]float64, len(sellPrices))
 	for i, price := range sellPrices {
-		theoryQty := spm.config.Trading.OrderQuantity / price
-		theoryQty = roundPrice(theoryQty, spm.quantityDecimals)
+		theoryQty := spm.orderQuantityFor(price)
 		theoryQtys[i] = theoryQty
 		totalTheoryQty += theoryQty
 	}
 
-	logger.Debug("🔍 [持仓恢复] 理论总数量: %.4f, 实际持仓: %.4f, 比例: %.4f",
+	positionLog.Debug("🔍 [持仓恢复] 理论总数量: %.4f, 实际持仓: %.4f, 比例: %.4f",
 		totalTheoryQty, totalPosition, totalPosition/totalTheoryQty)
 
 	// 6. 按比例分配实际持仓到各个槽位
@@ -1174,7 +2189,7 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 		}
 
 		if slotQty <= 0 {
-			logger.Warn("⚠️ [持仓恢复] 槽位 %s 分配数量过小 %.4f，跳过（已分配: %.4f / 总计: %.4f）",
+			positionLog.Warn("⚠️ [持仓恢复] 槽位 %s 分配数量过小 %.4f，跳过（已分配: %.4f / 总计: %.4f）",
 				formatPrice(price, spm.priceDecimals), slotQty, allocatedQty, totalPosition)
 			continue
 		}
@@ -1183,9 +2198,10 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 		slot := spm.getOrCreateSlot(price)
 		slot.mu.Lock()
 
-		// 设置为有仓状态
+		// 设置为有仓状态（建仓时间未知，按启动时间计算持有时长）
 		slot.PositionStatus = PositionStatusFilled
 		slot.PositionQty = slotQty
+		slot.PositionOpenedAt = time.Now()
 
 		// 清空订单信息，但设置方向为SELL（因为这是恢复的持仓，将来要挂卖单）
 		slot.OrderID = 0
@@ -1206,19 +2222,19 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 			} else {
 				inWindow = " [暂不挂单]"
 			}
-			logger.Info("✅ [持仓恢复] 槽位 %s: 分配持仓 %.4f (理论: %.4f)%s",
+			positionLog.Info("✅ [持仓恢复] 槽位 %s: 分配持仓 %.4f (理论: %.4f)%s",
 				formatPrice(price, spm.priceDecimals), slotQty, theoryQtys[i], inWindow)
 		} else if i == 10 {
-			logger.Info("... （省略中间 %d 个槽位）", len(sellPrices)-20)
+			positionLog.Info("... （省略中间 %d 个槽位）", len(sellPrices)-20)
 		}
 	}
 
-	logger.Info("✅ [持仓恢复] 完成持仓恢复，总持仓: %.4f，已分配: %.4f，差异: %.4f",
+	positionLog.Info("✅ [持仓恢复] 完成持仓恢复，总持仓: %.4f，已分配: %.4f，差异: %.4f",
 		totalPosition, allocatedQty, totalPosition-allocatedQty)
 
 	// 8. 提示用户后续会自动下卖单
-	logger.Info("💡 [持仓恢复] 前 %d 个槽位的卖单将在价格调整时自动创建", sellWindowSize)
-	logger.Info("💡 [持仓恢复] 其余 %d 个槽位保持有仓状态，价格接近时自动挂单", totalSlotsNeeded-sellWindowSize)
+	positionLog.Info("💡 [持仓恢复] 前 %d 个槽位的卖单将在价格调整时自动创建", sellWindowSize)
+	positionLog.Info("💡 [持仓恢复] 其余 %d 个槽位保持有仓状态，价格接近时自动挂单", totalSlotsNeeded-sellWindowSize)
 }
 
 // ===== 状态打印功能 =====
@@ -1226,7 +2242,7 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 // PrintPositions 打印持仓状态（由 main.go 定期调用和退出时调用）
 // 注意：该方法内部使用 totalBuyQty 和 totalSellQty 统计数据
 func (spm *SuperPositionManager) PrintPositions() {
-	logger.Info("📊 ===== 当前持仓 =====")
+	positionLog.Info("📊 ===== 当前持仓 =====")
 	total := 0.0
 	count := 0
 
@@ -1288,27 +2304,35 @@ func (spm *SuperPositionManager) PrintPositions() {
 			slotStatusInfo = " [槽位:空]"
 		}
 
-		logger.Info("  %s %s: %s%s%s",
+		positionLog.Info("  %s %s: %s%s%s",
 			statusIcon, priceStr, positionDesc, orderInfo, slotStatusInfo)
 	}
 
-	logger.Info("持仓统计: %.4f %s (%d 个槽位)", total, baseCurrency, count)
+	positionLog.Info("持仓统计: %.4f %s (%d 个槽位)", total, baseCurrency, count)
 	totalBuyQty := spm.totalBuyQty.Load().(float64)
 	totalSellQty := spm.totalSellQty.Load().(float64)
-	// 预计盈利 = 累计卖出数量 × 价格间距（每笔盈利 = 价格间距 × 数量）
-	estimatedProfit := totalSellQty * spm.config.Trading.PriceInterval
-	logger.Info("累计买入: %.2f, 累计卖出: %.2f, 预计盈利: %.2f U",
+	// 预计盈利 = 累计卖出数量 × 卖单间隔（每笔盈利 = 卖单间隔 × 数量）
+	estimatedProfit := totalSellQty * spm.GetSellPriceInterval()
+	positionLog.Info("累计买入: %.2f, 累计卖出: %.2f, 预计盈利: %.2f U",
 		totalBuyQty, totalSellQty, estimatedProfit)
+	summary := spm.GetStatusSnapshot()
+	positionLog.Info("持仓成本: %.2f U, 平均买入价: %s, 浮动盈亏: %+.2f U (按 %s), 挂单: 买 %d / 卖 %d",
+		summary.DeployedNotional, formatPrice(summary.AvgEntryPrice, spm.priceDecimals),
+		summary.UnrealizedPnL, formatPrice(summary.MarkPrice, spm.priceDecimals),
+		summary.ActiveBuyOrders, summary.ActiveSellOrders)
+	if summary.MinFreeMarginPercent > 0 {
+		positionLog.Info("可用保证金比例: %.1f%% (下限 %.1f%%)", summary.FreeMarginPercent, summary.MinFreeMarginPercent)
+	}
 
 	// === 新增：打印买单窗口详细信息 ===
-	logger.Info("🔍 ===== 买单窗口状态 =====")
+	positionLog.Info("🔍 ===== 买单窗口状态 =====")
 
 	// 获取最后的市场价格
 	lastPrice, ok := spm.lastMarketPrice.Load().(float64)
 	if !ok || lastPrice <= 0 {
 		lastPrice = spm.anchorPrice // 如果没有更新过，使用锚点价格
 	}
-	logger.Info("当前市场价格: %s", formatPrice(lastPrice, spm.priceDecimals))
+	positionLog.Info("当前市场价格: %s", formatPrice(lastPrice, spm.priceDecimals))
 
 	// 收集所有槽位信息（包括买单和空槽位）
 	type slotInfo struct {
@@ -1348,10 +2372,10 @@ func (spm *SuperPositionManager) PrintPositions() {
 
 	// 找到最接近当前价格的网格价格
 	currentGridPrice := spm.findNearestGridPrice(lastPrice)
-	logger.Info("当前网格价格: %s", formatPrice(currentGridPrice, spm.priceDecimals))
+	positionLog.Info("当前网格价格: %s", formatPrice(currentGridPrice, spm.priceDecimals))
 
 	// 计算买单窗口范围（当前网格价格下方的买单窗口）
-	buyWindowSize := spm.config.Trading.BuyWindowSize
+	buyWindowSize := spm.effectiveBuyWindow()
 	buyWindowPrices := spm.calculateSlotPrices(currentGridPrice, buyWindowSize, "down")
 
 	// 创建价格查找表
@@ -1361,7 +2385,7 @@ func (spm *SuperPositionManager) PrintPositions() {
 	}
 
 	// 打印买单窗口内的所有槽位
-	logger.Info("买单窗口大小: %d 个槽位 (当前网格价格下方)", buyWindowSize)
+	positionLog.Info("买单窗口大小: %d 个槽位 (当前网格价格下方，配置 %d)", buyWindowSize, spm.GetBuyWindowSize())
 	buyOrderCount := 0
 	emptySlotCount := 0
 	filledSlotCount := 0
@@ -1400,14 +2424,14 @@ func (spm *SuperPositionManager) PrintPositions() {
 				slotStatusInfo = " [槽位:空]"
 			}
 
-			logger.Info("  %s %s: %s%s%s",
+			positionLog.Info("  %s %s: %s%s%s",
 				statusIcon, priceStr, statusDesc, orderInfo, slotStatusInfo)
 		}
 	}
 
-	logger.Info("窗口统计: %d 个买单活跃, %d 个已持仓, %d 个空槽位",
+	positionLog.Info("窗口统计: %d 个买单活跃, %d 个已持仓, %d 个空槽位",
 		buyOrderCount, filledSlotCount, emptySlotCount)
-	logger.Info("==========================")
+	positionLog.Info("==========================")
 }
 
 // 辅助函数
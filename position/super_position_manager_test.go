@@ -0,0 +1,358 @@
+package position
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+
+	"opensqt/config"
+)
+
+// fakeExchange 测试用交易所（只返回预设的持仓和保证金）
+type fakeExchange struct {
+	positionSize float64
+	available    float64
+	equity       float64
+	leverage     int
+	minNotional  float64
+	openOrders   interface{} // GetOpenOrders 返回的挂单
+}
+
+func (f *fakeExchange) GetName() string { return "mock" }
+
+func (f *fakeExchange) GetPositions(ctx context.Context, symbol string) (interface{}, error) {
+	return []*PositionInfo{{Symbol: symbol, Size: f.positionSize}}, nil
+}
+
+func (f *fakeExchange) GetOpenOrders(ctx context.Context, symbol string) (interface{}, error) {
+	return f.openOrders, nil
+}
+
+func (f *fakeExchange) GetOrder(ctx context.Context, symbol string, orderID int64) (interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeExchange) GetBaseAsset() string { return "ETH" }
+
+func (f *fakeExchange) GetMinNotional() float64 { return f.minNotional }
+
+func (f *fakeExchange) CancelAllOrders(ctx context.Context, symbol string) error { return nil }
+
+func (f *fakeExchange) GetMarginInfo(ctx context.Context, symbol string) (float64, int, error) {
+	return f.available, f.leverage, nil
+}
+
+func (f *fakeExchange) GetMarginBalance(ctx context.Context, symbol string) (float64, float64, int, error) {
+	return f.available, f.equity, f.leverage, nil
+}
+
+// fakeExecutor 测试用订单执行器（记录下单和撤单，下单全部成功）
+type fakeExecutor struct {
+	mu       sync.Mutex
+	nextID   int64
+	placed   []*OrderRequest
+	canceled []int64
+}
+
+func (f *fakeExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	f.placed = append(f.placed, req)
+	return &Order{OrderID: f.nextID, ClientOrderID: req.ClientOrderID, Symbol: req.Symbol, Side: req.Side,
+		Price: req.Price, Quantity: req.Quantity, Status: "NEW"}, nil
+}
+
+func (f *fakeExecutor) BatchPlaceOrders(orders []*OrderRequest) ([]*Order, bool) {
+	placed := make([]*Order, 0, len(orders))
+	for _, req := range orders {
+		ord, _ := f.PlaceOrder(req)
+		placed = append(placed, ord)
+	}
+	return placed, false
+}
+
+func (f *fakeExecutor) BatchCancelOrders(orderIDs []int64, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.canceled = append(f.canceled, orderIDs...)
+	return nil
+}
+
+// testConfig 测试用最小配置（mock 交易所，价格间隔1，每单 10 USDT）
+func testConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.App.CurrentExchange = "mock"
+	cfg.Exchanges = map[string]config.ExchangeConfig{"mock": {}}
+	cfg.Trading.Symbol = "ETHUSDT"
+	cfg.Trading.PriceInterval = 1
+	cfg.Trading.OrderQuantity = 10
+	cfg.Trading.BuyWindowSize = 5
+	cfg.Trading.SellWindowSize = 5
+	cfg.Trading.OrderUpdateOrdering = "update_time"
+	return cfg
+}
+
+// newTestManager 创建使用测试交易所和执行器的仓位管理器（价格精度2，数量精度4）
+func newTestManager(cfg *config.Config) (*SuperPositionManager, *fakeExecutor) {
+	executor := &fakeExecutor{}
+	spm := NewSuperPositionManager(cfg, executor, &fakeExchange{leverage: 10}, 2, 4)
+	return spm, executor
+}
+
+func slotPositionQty(spm *SuperPositionManager, price float64) float64 {
+	slot := spm.getOrCreateSlot(price)
+	slot.mu.RLock()
+	defer slot.mu.RUnlock()
+	return slot.PositionQty
+}
+
+func TestOnOrderUpdateExecutedQtyConventions(t *testing.T) {
+	// 同一买单分三次成交（0.3 + 0.3 + 0.4），第二次成交的推送被交易所重复投递
+	tests := []struct {
+		name        string
+		incremental bool
+		ordering    string
+		executed    []float64 // 依次推送的 ExecutedQty（最后一条为 FILLED）
+	}{
+		{"累计/按时间排序", false, "update_time", []float64{0.3, 0.6, 0.6, 1.0}},
+		{"累计/按到达顺序", false, "arrival", []float64{0.3, 0.6, 0.6, 1.0}},
+		{"增量/按时间排序", true, "update_time", []float64{0.3, 0.3, 0.3, 0.4}},
+		{"增量/按到达顺序", true, "arrival", []float64{0.3, 0.3, 0.3, 0.4}},
+	}
+	updateTimes := []int64{1000, 2000, 2000, 3000}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Trading.OrderUpdateOrdering = tt.ordering
+			spm, _ := newTestManager(cfg)
+			spm.SetIncrementalFills(tt.incremental)
+			clientOID := spm.generateClientOrderID(100, "BUY")
+
+			for i, qty := range tt.executed {
+				status := "PARTIALLY_FILLED"
+				if i == len(tt.executed)-1 {
+					status = "FILLED"
+				}
+				spm.OnOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: clientOID, Symbol: "ETHUSDT", Status: status,
+					ExecutedQty: qty, Price: 100, AvgPrice: 100, Side: "BUY", UpdateTime: updateTimes[i]})
+			}
+
+			if got := slotPositionQty(spm, 100); math.Abs(got-1.0) > 1e-9 {
+				t.Fatalf("重复推送不应重复计入成交，持仓应为 1.0，实际 %.4f", got)
+			}
+			if got := spm.totalBuyQty.Load().(float64); math.Abs(got-1.0) > 1e-9 {
+				t.Fatalf("累计买入应为 1.0，实际 %.4f", got)
+			}
+		})
+	}
+}
+
+func TestOnOrderUpdateIncrementalDuplicateAfterFilled(t *testing.T) {
+	cfg := testConfig()
+	cfg.Trading.OrderUpdateOrdering = "arrival"
+	spm, _ := newTestManager(cfg)
+	spm.SetIncrementalFills(true)
+	filled := OrderUpdate{OrderID: 1, ClientOrderID: spm.generateClientOrderID(100, "BUY"), Symbol: "ETHUSDT",
+		Status: "FILLED", ExecutedQty: 0.5, Price: 100, AvgPrice: 100, Side: "BUY", UpdateTime: 1000}
+
+	spm.OnOrderUpdate(filled)
+	spm.OnOrderUpdate(filled)
+
+	if got := slotPositionQty(spm, 100); math.Abs(got-0.5) > 1e-9 {
+		t.Fatalf("订单结束后重复投递的 FILLED 不应再计入，持仓应为 0.5，实际 %.4f", got)
+	}
+	slot := spm.getOrCreateSlot(100)
+	if slot.OrderID != 0 || slot.ClientOID != "" {
+		t.Fatalf("重复推送不应把槽位恢复成挂单，实际 OrderID=%d ClientOID=%s", slot.OrderID, slot.ClientOID)
+	}
+}
+
+func TestOnOrderUpdateIncrementalSameTimeDifferentFills(t *testing.T) {
+	// 同一毫秒内的两笔不同成交（数量不同）不是重复推送，都应计入
+	spm, _ := newTestManager(testConfig())
+	spm.SetIncrementalFills(true)
+	clientOID := spm.generateClientOrderID(100, "BUY")
+
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: clientOID, Status: "PARTIALLY_FILLED",
+		ExecutedQty: 0.2, Price: 100, Side: "BUY", UpdateTime: 1000})
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: clientOID, Status: "FILLED",
+		ExecutedQty: 0.3, Price: 100, Side: "BUY", UpdateTime: 1000})
+
+	if got := slotPositionQty(spm, 100); math.Abs(got-0.5) > 1e-9 {
+		t.Fatalf("持仓应为 0.5，实际 %.4f", got)
+	}
+}
+
+func TestLadderModePinsAnchorAndInterval(t *testing.T) {
+	cfg := testConfig()
+	cfg.Trading.LadderMode = true
+	spm, executor := newTestManager(cfg)
+	spm.anchorPrice = 100
+	spm.priceInterval.Store(1.0)
+
+	spm.Reanchor(120, "风控解除")
+	spm.SetPriceInterval(2)
+
+	if spm.anchorPrice != 100 {
+		t.Fatalf("阶梯模式不应重置锚点，实际 %.2f", spm.anchorPrice)
+	}
+	if got := spm.GetPriceInterval(); got != 1 {
+		t.Fatalf("阶梯模式不应调整价格间隔，实际 %.2f", got)
+	}
+	if len(executor.canceled) != 0 {
+		t.Fatalf("阶梯模式不应为重置锚点或调整间隔撤单，实际撤单 %v", executor.canceled)
+	}
+
+	// 关闭阶梯模式时照常重置锚点
+	cfg.Trading.LadderMode = false
+	spm.Reanchor(120, "风控解除")
+	if spm.anchorPrice != 120 {
+		t.Fatalf("非阶梯模式应重置锚点到 120，实际 %.2f", spm.anchorPrice)
+	}
+}
+
+func TestOrderQuantityFlooringBelowMinOrderValue(t *testing.T) {
+	// 每单 20U、最小订单价值 20U：数量向下取整后名义价值低于 20U 的价格层按 min_notional_auto_raise 上调或跳过
+	tests := []struct {
+		name      string
+		price     float64
+		rounding  string
+		autoRaise bool
+		wantQty   float64 // 0 表示跳过该层
+	}{
+		{"整除时名义价值恰好为 order_quantity", 2000, "floor", false, 0.01},
+		{"向下取整后低于最小值：跳过该层", 3000.7, "floor", false, 0},
+		{"向下取整后低于最小值：上调一个数量单位", 3000.7, "floor", true, 0.0067},
+		{"四舍五入不低于最小值", 3000.7, "round", false, 0.0067},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Trading.OrderQuantity = 20
+			cfg.Trading.MinOrderValue = 20
+			cfg.Trading.QuoteDecimals = 8
+			cfg.Trading.QuantityRounding = tt.rounding
+			cfg.Trading.MinNotionalAutoRaise = tt.autoRaise
+			cfg.Trading.MinNotionalMaxMultiplier = 1.5
+			spm, _ := newTestManager(cfg)
+
+			qty := spm.orderQuantityFor(tt.price)
+			if tt.rounding == "floor" && qty*tt.price > cfg.Trading.OrderQuantity {
+				t.Fatalf("向下取整后名义价值 %.4f 不应超过 order_quantity", qty*tt.price)
+			}
+			got, ok := spm.ensureMinNotional(tt.price, qty)
+			if !ok {
+				got = 0
+			}
+			if math.Abs(got-tt.wantQty) > 1e-9 {
+				t.Fatalf("数量 %.4f 应调整为 %.4f，实际 %.4f (ok=%v)", qty, tt.wantQty, got, ok)
+			}
+			if ok && !spm.meetsMinNotional(tt.price, got, spm.minOrderValue()) {
+				t.Fatalf("挂出的数量 %.4f 名义价值 %.4f 低于最小订单价值", got, got*tt.price)
+			}
+		})
+	}
+}
+
+func TestMinNotionalNoticesResetOnGridRebuild(t *testing.T) {
+	cfg := testConfig()
+	cfg.Trading.OrderQuantity = 20
+	cfg.Trading.MinOrderValue = 20
+	cfg.Trading.QuoteDecimals = 8
+	spm, _ := newTestManager(cfg)
+	spm.anchorPrice = 3000
+	spm.priceInterval.Store(1.0)
+
+	countNotices := func() int {
+		n := 0
+		spm.minNotionalNotices.Range(func(_, _ interface{}) bool { n++; return true })
+		return n
+	}
+	spm.ensureMinNotional(3000.7, 0.0066)
+	if spm.shouldNoticeMinNotional(3000.7) {
+		t.Fatal("同一价格层在间隔内不应重复记录")
+	}
+
+	spm.Reanchor(3100, "风控解除")
+	if n := countNotices(); n != 0 {
+		t.Fatalf("重置锚点后应清空去重记录，实际 %d 条", n)
+	}
+	spm.ensureMinNotional(3100.7, 0.0064)
+	spm.SetPriceInterval(2)
+	if n := countNotices(); n != 0 {
+		t.Fatalf("调整价格间隔后应清空去重记录，实际 %d 条", n)
+	}
+}
+
+func TestMeetsMinNotionalAtExchangeMinimum(t *testing.T) {
+	// 0.57 × 100 的浮点结果为 56.99999999999999，按 quote_decimals 取整后恰好等于最小值 57
+	tests := []struct {
+		name        string
+		price, qty  float64
+		configMin   float64
+		exchangeMin float64
+		want        bool
+	}{
+		{"恰好等于交易所最小下单金额", 0.57, 100, 5, 57, true},
+		{"恰好等于配置的最小订单价值", 0.57, 100, 57, 0, true},
+		{"低于最小值一个数量单位", 0.57, 99.9999, 5, 57, false},
+		{"交易所最小值高于配置时以交易所为准", 0.57, 100, 5, 57.01, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Trading.MinOrderValue = tt.configMin
+			cfg.Trading.QuoteDecimals = 8
+			spm := NewSuperPositionManager(cfg, &fakeExecutor{}, &fakeExchange{leverage: 10, minNotional: tt.exchangeMin}, 2, 4)
+
+			minValue := spm.minOrderValue()
+			if got := spm.meetsMinNotional(tt.price, tt.qty, minValue); got != tt.want {
+				t.Fatalf("%.4f × %.4f 与最小值 %.2f 比较应为 %v，实际 %v", tt.price, tt.qty, minValue, tt.want, got)
+			}
+			qty, ok := spm.ensureMinNotional(tt.price, tt.qty)
+			if ok != tt.want || (ok && qty != tt.qty) {
+				t.Fatalf("恰好满足最小值的数量应原样挂出，不满足时跳过，实际数量 %.4f ok=%v", qty, ok)
+			}
+		})
+	}
+}
+
+func TestUpdateTradingParamsKeepsSharedConfig(t *testing.T) {
+	cfg := testConfig()
+	spm, _ := newTestManager(cfg)
+
+	// 其他组件与仓位管理器共用 cfg，并发读取运行中参数（go test -race 检查数据竞争）
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = spm.GetOrderQuantity() + float64(spm.GetBuyWindowSize())
+			}
+		}
+	}()
+
+	params := spm.CurrentTradingParams()
+	params.OrderQuantity = 30
+	params.BuyWindowSize = 8
+	if _, err := spm.UpdateTradingParams(params); err != nil {
+		t.Fatal(err)
+	}
+	close(stop)
+	wg.Wait()
+
+	if spm.GetOrderQuantity() != 30 || spm.GetBuyWindowSize() != 8 {
+		t.Fatalf("运行中参数应为新值，实际 order_quantity=%g buy_window_size=%d", spm.GetOrderQuantity(), spm.GetBuyWindowSize())
+	}
+	if cfg.Trading.OrderQuantity != 10 || cfg.Trading.BuyWindowSize != 5 {
+		t.Fatal("更新交易参数不应修改共用的 config")
+	}
+}
@@ -0,0 +1,93 @@
+package position
+
+import (
+	"fmt"
+
+	"opensqt/config"
+)
+
+// TradingParams 可在运行中调整的交易参数（配置热重载使用）
+type TradingParams struct {
+	PriceInterval  float64 // 价格间隔（配置值，自适应间隔在此基础上调整）
+	OrderQuantity  float64 // 每单金额
+	BuyWindowSize  int
+	SellWindowSize int
+	MinOrderValue  float64 // 最小订单价值（0 表示使用默认值）
+}
+
+// tradingParamsFromConfig 启动时配置中的交易参数
+func tradingParamsFromConfig(cfg *config.Config) *TradingParams {
+	trading := cfg.Trading
+	return &TradingParams{
+		PriceInterval:  trading.PriceInterval,
+		OrderQuantity:  trading.OrderQuantity,
+		BuyWindowSize:  trading.BuyWindowSize,
+		SellWindowSize: trading.SellWindowSize,
+		MinOrderValue:  trading.MinOrderValue,
+	}
+}
+
+// CurrentTradingParams 返回当前配置的交易参数（价格间隔为配置值，不含自适应间隔的临时调整）
+// 配置重载后 config.Trading 中的对应字段保持启动时的值，运行中读取这些参数应使用本方法或下面的 getter
+func (spm *SuperPositionManager) CurrentTradingParams() TradingParams {
+	return *spm.tradingParams.Load()
+}
+
+// GetOrderQuantity 获取当前每单金额
+func (spm *SuperPositionManager) GetOrderQuantity() float64 {
+	return spm.tradingParams.Load().OrderQuantity
+}
+
+// GetBuyWindowSize 获取当前配置的买单窗口大小
+func (spm *SuperPositionManager) GetBuyWindowSize() int {
+	return spm.tradingParams.Load().BuyWindowSize
+}
+
+// GetConfiguredPriceInterval 获取配置的价格间隔（不含自适应间隔的临时调整，配置重载后为新值）
+func (spm *SuperPositionManager) GetConfiguredPriceInterval() float64 {
+	return spm.tradingParams.Load().PriceInterval
+}
+
+// UpdateTradingParams 运行中更新交易参数，返回实际变化的参数（如 "order_quantity: 20 -> 30"）
+// 订单金额、窗口大小和最小订单价值从下一次 AdjustOrders 起生效，已挂出的订单保持不变；
+// 价格间隔变化时通过 SetPriceInterval 撤销买单并按新网格重新挂单（撤单会阻塞数秒，调用方应在独立协程中调用）
+// 新参数保存在仓位管理器中，不修改与其他组件共用的 config
+func (spm *SuperPositionManager) UpdateTradingParams(params TradingParams) ([]string, error) {
+	if params.PriceInterval <= 0 || params.OrderQuantity <= 0 {
+		return nil, fmt.Errorf("价格间隔和订单金额必须大于0")
+	}
+	if params.BuyWindowSize <= 0 || params.SellWindowSize <= 0 {
+		return nil, fmt.Errorf("买单/卖单窗口大小必须大于0")
+	}
+	if params.MinOrderValue < 0 {
+		return nil, fmt.Errorf("最小订单价值不能为负数")
+	}
+
+	var changes []string
+	spm.mu.Lock()
+	current := spm.tradingParams.Load()
+	if params.OrderQuantity != current.OrderQuantity {
+		changes = append(changes, fmt.Sprintf("order_quantity: %g -> %g", current.OrderQuantity, params.OrderQuantity))
+	}
+	if params.BuyWindowSize != current.BuyWindowSize {
+		changes = append(changes, fmt.Sprintf("buy_window_size: %d -> %d", current.BuyWindowSize, params.BuyWindowSize))
+	}
+	if params.SellWindowSize != current.SellWindowSize {
+		changes = append(changes, fmt.Sprintf("sell_window_size: %d -> %d", current.SellWindowSize, params.SellWindowSize))
+	}
+	if params.MinOrderValue != current.MinOrderValue {
+		changes = append(changes, fmt.Sprintf("min_order_value: %g -> %g", current.MinOrderValue, params.MinOrderValue))
+	}
+	intervalChanged := params.PriceInterval != current.PriceInterval
+	if intervalChanged {
+		changes = append(changes, fmt.Sprintf("price_interval: %s -> %s",
+			formatPrice(current.PriceInterval, spm.priceDecimals), formatPrice(params.PriceInterval, spm.priceDecimals)))
+	}
+	spm.tradingParams.Store(&params)
+	spm.mu.Unlock()
+
+	if intervalChanged {
+		spm.SetPriceInterval(params.PriceInterval)
+	}
+	return changes, nil
+}
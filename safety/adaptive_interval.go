@@ -0,0 +1,160 @@
+package safety
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// IIntervalAdjuster 可调整价格间隔的仓位管理器
+type IIntervalAdjuster interface {
+	GetPriceInterval() float64
+	SetPriceInterval(interval float64)
+	// GetConfiguredPriceInterval 配置的价格间隔（配置重载后为新值）
+	GetConfiguredPriceInterval() float64
+}
+
+// AdaptiveInterval 自适应价格间隔
+// 按固定窗口统计账户净值变化和卖出成交次数：有成交但净值下降说明手续费和逆向成交吞噬了网格利润，
+// 连续多个亏损窗口后放大间隔以降低换手、提高单笔利润；连续盈利后逐步缩回配置的 price_interval
+type AdaptiveInterval struct {
+	cfg           *config.Config
+	exchange      exchange.IExchange
+	pm            IIntervalAdjuster
+	priceDecimals int
+
+	roundTrips    atomic.Int64 // 当前窗口内完成的卖出次数
+	lossStreak    int
+	profitStreak  int
+	windowBalance float64 // 窗口开始时的账户净值
+}
+
+// NewAdaptiveInterval 创建自适应价格间隔控制器
+func NewAdaptiveInterval(cfg *config.Config, ex exchange.IExchange, pm IIntervalAdjuster, priceDecimals int) *AdaptiveInterval {
+	return &AdaptiveInterval{
+		cfg:           cfg,
+		exchange:      ex,
+		pm:            pm,
+		priceDecimals: priceDecimals,
+	}
+}
+
+// Start 启动自适应间隔（阻塞直到 ctx 取消）
+func (a *AdaptiveInterval) Start(ctx context.Context) {
+	adaptive := a.cfg.Trading.AdaptiveInterval
+	if !adaptive.Enabled {
+		return
+	}
+
+	unsubscribe := event.Subscribe("adaptive-interval", func(e event.Event) {
+		// 多交易对时只统计本交易对的成交
+		if fill, ok := e.Payload.(event.OrderFilled); ok && fill.Symbol == a.cfg.Trading.Symbol && fill.Side == "SELL" && fill.Complete {
+			a.roundTrips.Add(1)
+		}
+	}, event.TypeOrderFilled)
+	defer unsubscribe()
+
+	balance, err := a.fetchBalance(ctx)
+	if err != nil {
+		logger.Error("❌ [自适应间隔] 获取初始净值失败，功能未启动: %v", err)
+		return
+	}
+	a.windowBalance = balance
+
+	logger.Info("📐 [自适应间隔] 启动 (窗口: %d分钟, 亏损%d窗口放大/盈利%d窗口缩小, 步长: %.0f%%, 范围: %.*f ~ %.*f)",
+		adaptive.WindowMinutes, adaptive.LossWindows, adaptive.ProfitWindows, adaptive.StepPercent,
+		a.priceDecimals, a.pm.GetConfiguredPriceInterval(), a.priceDecimals, adaptive.MaxInterval)
+
+	ticker := time.NewTicker(time.Duration(adaptive.WindowMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.evaluateWindow(ctx)
+		}
+	}
+}
+
+// evaluateWindow 结算一个统计窗口，必要时调整价格间隔
+func (a *AdaptiveInterval) evaluateWindow(ctx context.Context) {
+	adaptive := a.cfg.Trading.AdaptiveInterval
+
+	balance, err := a.fetchBalance(ctx)
+	if err != nil {
+		logger.Warn("⚠️ [自适应间隔] 获取账户净值失败，跳过本窗口: %v", err)
+		return
+	}
+	change := balance - a.windowBalance
+	roundTrips := a.roundTrips.Swap(0)
+	a.windowBalance = balance
+
+	if roundTrips < int64(adaptive.MinRoundTrips) {
+		logger.Debug("📐 [自适应间隔] 窗口卖出 %d 次（不足 %d 次），不参与评估，净值变化 %.4f",
+			roundTrips, adaptive.MinRoundTrips, change)
+		return
+	}
+
+	if change < 0 {
+		a.lossStreak++
+		a.profitStreak = 0
+	} else {
+		a.profitStreak++
+		a.lossStreak = 0
+	}
+	logger.Info("📐 [自适应间隔] 窗口结算: 卖出 %d 次, 净值变化 %.4f, 连续亏损 %d, 连续盈利 %d",
+		roundTrips, change, a.lossStreak, a.profitStreak)
+
+	current := a.pm.GetPriceInterval()
+	step := 1 + adaptive.StepPercent/100
+	var target float64
+	switch {
+	case a.lossStreak >= adaptive.LossWindows:
+		target = math.Min(a.roundInterval(current*step), adaptive.MaxInterval)
+		a.lossStreak = 0
+		if target > current {
+			logger.Warn("📐 [自适应间隔] 连续 %d 个窗口有成交但净值下降，放大价格间隔: %.*f -> %.*f",
+				adaptive.LossWindows, a.priceDecimals, current, a.priceDecimals, target)
+		}
+	case a.profitStreak >= adaptive.ProfitWindows:
+		target = math.Max(a.roundInterval(current/step), a.pm.GetConfiguredPriceInterval())
+		a.profitStreak = 0
+		if target < current {
+			logger.Info("📐 [自适应间隔] 连续 %d 个窗口盈利，缩小价格间隔: %.*f -> %.*f",
+				adaptive.ProfitWindows, a.priceDecimals, current, a.priceDecimals, target)
+		}
+	default:
+		return
+	}
+
+	if target != current {
+		a.pm.SetPriceInterval(target)
+	}
+}
+
+// roundInterval 按价格精度取整（至少一个最小价格单位）
+func (a *AdaptiveInterval) roundInterval(interval float64) float64 {
+	factor := math.Pow(10, float64(a.priceDecimals))
+	rounded := math.Round(interval*factor) / factor
+	if rounded <= 0 {
+		rounded = 1 / factor
+	}
+	return rounded
+}
+
+// fetchBalance 获取账户净值（含未实现盈亏，逆向成交造成的浮亏也计入窗口表现）
+func (a *AdaptiveInterval) fetchBalance(ctx context.Context) (float64, error) {
+	account, err := a.exchange.GetAccount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return EffectiveBalance(account), nil
+}
@@ -0,0 +1,65 @@
+package safety
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"opensqt/config"
+	"opensqt/logger"
+)
+
+// balanceSanity 余额读数合理性检查（safety.balance_sanity）
+// 交易所异常时账户查询可能短暂返回0或明显错误的余额；余额为0，或相对上次有效读数变化超过
+// max_change_percent% 的读数视为异常，调用方跳过本次检查。连续 confirm_readings 次异常（非0）读数
+// 视为真实的资金变动，接受为新基准。每个使用方各自持有一个实例（比较的余额字段可能不同）
+type balanceSanity struct {
+	cfg  *config.Config
+	name string // 日志中的使用方名称
+
+	mu      sync.Mutex
+	last    float64 // 上次有效读数（0表示尚无基准）
+	rejects int     // 连续异常读数次数
+}
+
+// newBalanceSanity 创建余额读数合理性检查
+func newBalanceSanity(cfg *config.Config, name string) *balanceSanity {
+	return &balanceSanity{cfg: cfg, name: name}
+}
+
+// Check 检查一次余额读数，异常时返回错误（未启用时总是通过）
+func (s *balanceSanity) Check(balance float64) error {
+	sanity := s.cfg.Safety.BalanceSanity
+	if !sanity.Enabled {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if balance <= 0 {
+		return fmt.Errorf("余额读数异常: %.2f（上次有效读数 %.2f），疑似交易所返回不完整数据", balance, s.last)
+	}
+	if s.last <= 0 {
+		s.last = balance
+		return nil
+	}
+
+	changePct := math.Abs(balance-s.last) / s.last * 100
+	if changePct <= sanity.MaxChangePercent {
+		s.last = balance
+		s.rejects = 0
+		return nil
+	}
+
+	s.rejects++
+	if s.rejects >= sanity.ConfirmReadings {
+		logger.Warn("⚠️ [%s] 余额连续 %d 次大幅偏离 (%.2f -> %.2f, %.1f%%)，视为真实资金变动，接受为新基准",
+			s.name, s.rejects, s.last, balance, changePct)
+		s.last = balance
+		s.rejects = 0
+		return nil
+	}
+	return fmt.Errorf("余额读数异常: %.2f 相对上次有效读数 %.2f 变化 %.1f%%，超过 %.0f%%（第 %d/%d 次）",
+		balance, s.last, changePct, sanity.MaxChangePercent, s.rejects, sanity.ConfirmReadings)
+}
@@ -0,0 +1,71 @@
+package safety
+
+import (
+	"fmt"
+	"strings"
+
+	"opensqt/config"
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// capabilityRequirement 策略需要的一项交易所能力
+type capabilityRequirement struct {
+	name    string
+	purpose string
+}
+
+// CheckCapabilities 启动时按配置的策略检查交易所适配器能力，缺少必需能力时直接失败，而不是运行中下单被拒才发现
+// 网格挂单需要只做 Maker 和只减仓，safety.required_capabilities 可额外要求其他能力；
+// 市价平仓、原生追踪止损等可选功能缺少能力时只告警（追踪止损回退为市价平仓，市价单失败时记录错误）。
+// 缺少必需能力时返回 *SafetyCheckError
+func CheckCapabilities(cfg *config.Config, ex exchange.IExchange) error {
+	caps := exchange.GetCapabilities(ex)
+	logger.Info("🧩 [交易所能力] %s: 只做Maker %s, 只减仓 %s, 市价/IOC %s, 原生止损 %s", ex.GetName(),
+		capabilityMark(caps.PostOnly), capabilityMark(caps.ReduceOnly), capabilityMark(caps.IOC), capabilityMark(caps.NativeStop))
+
+	required := []capabilityRequirement{
+		{exchange.CapabilityPostOnly, "网格挂单只做 Maker"},
+		{exchange.CapabilityReduceOnly, "网格卖单只减仓"},
+	}
+	for _, name := range cfg.Safety.RequiredCapabilities {
+		required = append(required, capabilityRequirement{name, "safety.required_capabilities"})
+	}
+
+	var missing []string
+	seen := make(map[string]bool)
+	for _, req := range required {
+		if seen[req.name] {
+			continue
+		}
+		seen[req.name] = true
+		if supported, _ := caps.Supports(req.name); !supported {
+			missing = append(missing, fmt.Sprintf("%s（%s）", req.name, req.purpose))
+		}
+	}
+	if len(missing) > 0 {
+		return &SafetyCheckError{
+			Reason:  ReasonCapabilityUnsupported,
+			Message: fmt.Sprintf("交易所 %s 的适配器不支持策略需要的能力: %s", ex.GetName(), strings.Join(missing, ", ")),
+		}
+	}
+
+	if !caps.IOC {
+		logger.Warn("⚠️ [交易所能力] %s 不支持市价/IOC 下单，止盈退出和紧急平仓的市价平仓单可能被拒绝，需要手动平仓", ex.GetName())
+		if cfg.Trading.DustSweep.Enabled && cfg.Trading.DustSweep.Action == "market" {
+			logger.Warn("⚠️ [交易所能力] %s 不支持市价/IOC 下单，dust_sweep.action=market 的残余清理单可能被拒绝", ex.GetName())
+		}
+	}
+	if !caps.NativeStop && cfg.Trading.TakeProfit.Enabled && cfg.Trading.TakeProfit.UseNativeTrailing {
+		logger.Warn("⚠️ [交易所能力] %s 不支持原生止损单，take_profit.use_native_trailing 将回退为市价平仓", ex.GetName())
+	}
+	return nil
+}
+
+// capabilityMark 能力支持标记
+func capabilityMark(supported bool) string {
+	if supported {
+		return "✅"
+	}
+	return "❌"
+}
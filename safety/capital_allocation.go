@@ -0,0 +1,77 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// ResolveCapitalAllocations 计算每个交易对可使用的资金（trading.capital_allocation）
+// 配置值含义：
+//   - <= 0: 不限制（返回 0）
+//   - (0, 1]: 账户总余额的比例
+//   - > 1: 绝对金额（计价币种）
+//
+// 各交易对的分配之和不得超过账户总余额，否则返回错误
+func ResolveCapitalAllocations(ex exchange.IExchange, allocations map[string]float64) (map[string]float64, error) {
+	resolved := make(map[string]float64, len(allocations))
+
+	limited := false
+	for _, allocation := range allocations {
+		if allocation > 0 {
+			limited = true
+			break
+		}
+	}
+	if !limited {
+		for symbol := range allocations {
+			resolved[symbol] = 0
+		}
+		return resolved, nil
+	}
+
+	account, err := ex.GetAccount(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("获取账户信息失败: %w", err)
+	}
+	totalBalance := account.TotalWalletBalance
+	if totalBalance <= 0 {
+		totalBalance = account.AvailableBalance
+	}
+	if totalBalance <= 0 {
+		return nil, fmt.Errorf("账户余额为0，无法分配资金")
+	}
+
+	symbols := make([]string, 0, len(allocations))
+	for symbol := range allocations {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	quoteCurrency := ex.GetQuoteAsset()
+	var sum float64
+	for _, symbol := range symbols {
+		allocation := allocations[symbol]
+		amount := allocation
+		switch {
+		case allocation <= 0:
+			amount = 0
+			logger.Info("💼 [资金分配] %s: 不限制", symbol)
+		case allocation <= 1:
+			amount = totalBalance * allocation
+			logger.Info("💼 [资金分配] %s: %.2f %s (账户总余额的 %.1f%%)", symbol, amount, quoteCurrency, allocation*100)
+		default:
+			logger.Info("💼 [资金分配] %s: %.2f %s (占账户总余额 %.1f%%)", symbol, amount, quoteCurrency, amount/totalBalance*100)
+		}
+		resolved[symbol] = amount
+		sum += amount
+	}
+
+	if sum > totalBalance {
+		return nil, fmt.Errorf("资金分配合计 %.2f %s 超过账户总余额 %.2f %s", sum, quoteCurrency, totalBalance, quoteCurrency)
+	}
+	return resolved, nil
+}
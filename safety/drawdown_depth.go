@@ -0,0 +1,132 @@
+package safety
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"opensqt/config"
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// DrawdownDepth 回撤限深（trading.drawdown_depth）
+// 定期查询交易对的未实现盈亏，浮亏占资金的比例超过 start_percent 后按比例缩减买单窗口，
+// 达到 stop_percent 时只保留 min_depth 层；浮亏收窄后逐步恢复。只限制新增买单，不撤销已有订单
+type DrawdownDepth struct {
+	cfg               *config.Config
+	ex                exchange.IExchange
+	grid              IGridState
+	capitalAllocation float64
+
+	depth atomic.Int64 // 当前买单深度上限（-1 表示不限制）
+}
+
+// NewDrawdownDepth 创建回撤限深器，grid 提供当前买单窗口，capitalAllocation 为分配给该交易对的资金（0表示不限制）
+func NewDrawdownDepth(cfg *config.Config, ex exchange.IExchange, grid IGridState, capitalAllocation float64) *DrawdownDepth {
+	d := &DrawdownDepth{cfg: cfg, ex: ex, grid: grid, capitalAllocation: capitalAllocation}
+	d.depth.Store(-1)
+	return d
+}
+
+// MaxBuyDepth 当前买单深度上限（负数表示不限制）
+func (d *DrawdownDepth) MaxBuyDepth() int {
+	return int(d.depth.Load())
+}
+
+// Start 按 check_interval 定期更新深度上限（阻塞直到 ctx 取消，未启用时直接返回）
+func (d *DrawdownDepth) Start(ctx context.Context) {
+	dd := d.cfg.Trading.DrawdownDepth
+	if !dd.Enabled {
+		return
+	}
+	logger.Info("📉 [回撤限深] 启动 (浮亏 %.2f%% 起缩减买单深度，%.2f%% 时缩减至 %d 层，每 %ds 检查)",
+		dd.StartPercent, dd.StopPercent, dd.MinDepth, dd.CheckInterval)
+
+	ticker := time.NewTicker(time.Duration(dd.CheckInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		d.update(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// update 查询浮亏并重新计算深度上限，深度变化时记录日志
+func (d *DrawdownDepth) update(ctx context.Context) {
+	drawdown, err := d.drawdownPercent(ctx)
+	if err != nil {
+		logger.Warn("⚠️ [回撤限深] 查询浮亏失败，保持当前深度: %v", err)
+		return
+	}
+
+	next := d.depthFor(drawdown)
+	prev := int(d.depth.Swap(int64(next)))
+	if next == prev {
+		return
+	}
+
+	window := d.grid.GetBuyWindowSize()
+	switch {
+	case next < 0:
+		logger.Info("✅ [回撤限深] 浮亏 %.2f%% 已回落，恢复完整买单窗口 %d 层", drawdown, window)
+	case prev < 0 || next < prev:
+		logger.Warn("📉 [回撤限深] 浮亏 %.2f%%，买单深度缩减至 %d/%d 层", drawdown, next, window)
+	default:
+		logger.Info("📈 [回撤限深] 浮亏 %.2f%%，买单深度恢复至 %d/%d 层", drawdown, next, window)
+	}
+}
+
+// depthFor 按浮亏比例计算深度上限：start 以下不限制，stop 以上为 min_depth，中间线性缩减
+func (d *DrawdownDepth) depthFor(drawdown float64) int {
+	dd := d.cfg.Trading.DrawdownDepth
+	window := d.grid.GetBuyWindowSize()
+	minDepth := dd.MinDepth
+	if minDepth > window {
+		minDepth = window
+	}
+
+	if drawdown <= dd.StartPercent {
+		return -1
+	}
+	if drawdown >= dd.StopPercent {
+		return minDepth
+	}
+	ratio := (dd.StopPercent - drawdown) / (dd.StopPercent - dd.StartPercent)
+	return minDepth + int(math.Floor(float64(window-minDepth)*ratio))
+}
+
+// drawdownPercent 交易对未实现亏损占资金的百分比（盈利时为0）
+func (d *DrawdownDepth) drawdownPercent(ctx context.Context) (float64, error) {
+	symbol := d.cfg.Trading.Symbol
+	positions, err := d.ex.GetPositions(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	var unrealized float64
+	for _, p := range positions {
+		if p.Symbol == symbol {
+			unrealized += p.UnrealizedPNL
+		}
+	}
+	if unrealized >= 0 {
+		return 0, nil
+	}
+
+	account, err := d.ex.GetAccount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	balance := account.TotalWalletBalance
+	if d.capitalAllocation > 0 && d.capitalAllocation < balance {
+		balance = d.capitalAllocation
+	}
+	if balance <= 0 {
+		return 0, nil
+	}
+	return -unrealized / balance * 100, nil
+}
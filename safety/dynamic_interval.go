@@ -0,0 +1,91 @@
+package safety
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"opensqt/config"
+	"opensqt/logger"
+	"opensqt/monitor"
+)
+
+// IDynamicIntervalGrid 波动率动态间隔需要的网格状态
+type IDynamicIntervalGrid interface {
+	IGridState
+	SetPriceInterval(interval float64)
+}
+
+// DynamicInterval 波动率动态间隔（trading.dynamic_interval）
+// 固定间隔在剧烈行情中过窄、频繁换手，在平静行情中过宽、长期不成交。每 sample_interval 秒从价格流采样一次最新价格，
+// 由 monitor.VolatilityEstimator 按最近 window 个样本的价格标准差 × multiplier 计算目标间隔，限制在 [min_interval, max_interval] 内；
+// 目标间隔与当前间隔相差超过 min_change_percent 时才调整，避免每次采样都撤单重挂
+type DynamicInterval struct {
+	cfg       *config.Config
+	grid      IDynamicIntervalGrid
+	priceFn   func() float64
+	estimator *monitor.VolatilityEstimator
+}
+
+// NewDynamicInterval 创建波动率动态间隔控制器，priceFn 返回最新市场价格
+func NewDynamicInterval(cfg *config.Config, grid IDynamicIntervalGrid, priceFn func() float64) *DynamicInterval {
+	dynamic := cfg.Trading.DynamicInterval
+	return &DynamicInterval{
+		cfg:       cfg,
+		grid:      grid,
+		priceFn:   priceFn,
+		estimator: monitor.NewVolatilityEstimator(dynamic.Window, dynamic.Multiplier, dynamic.MinInterval, dynamic.MaxInterval),
+	}
+}
+
+// Start 定期采样价格并调整间隔（阻塞直到 ctx 取消，未启用时直接返回）
+func (d *DynamicInterval) Start(ctx context.Context) {
+	dynamic := d.cfg.Trading.DynamicInterval
+	if !dynamic.Enabled {
+		return
+	}
+	decimals := d.grid.GetPriceDecimals()
+	logger.Info("🌊 [动态间隔] 启动 (每 %d 秒采样, 窗口 %d 个样本, 间隔 = 标准差 × %.2f, 范围: %.*f ~ %.*f)",
+		dynamic.SampleInterval, dynamic.Window, dynamic.Multiplier, decimals, dynamic.MinInterval, decimals, dynamic.MaxInterval)
+
+	ticker := time.NewTicker(time.Duration(dynamic.SampleInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if price := d.priceFn(); price > 0 {
+				d.addSample(price)
+			}
+		}
+	}
+}
+
+// addSample 记录一个价格样本，窗口填满后按最新波动率调整间隔
+func (d *DynamicInterval) addSample(price float64) {
+	dynamic := d.cfg.Trading.DynamicInterval
+	d.estimator.AddSample(price)
+	if !d.estimator.Ready() {
+		return
+	}
+
+	decimals := d.grid.GetPriceDecimals()
+	current := d.grid.GetPriceInterval()
+	stddev := d.estimator.StdDev()
+	target := roundTo(d.estimator.CurrentInterval(current), decimals)
+	if current > 0 && math.Abs(target-current)/current*100 < dynamic.MinChangePercent {
+		logger.Debug("🌊 [动态间隔] 价格标准差 %.*f，目标间隔 %.*f 与当前间隔 %.*f 相差不足 %.0f%%，保持不变",
+			decimals+2, stddev, decimals, target, decimals, current, dynamic.MinChangePercent)
+		return
+	}
+	logger.Info("🌊 [动态间隔] 最近 %d 个样本价格标准差 %.*f，调整价格间隔: %.*f -> %.*f",
+		dynamic.Window, decimals+2, stddev, decimals, current, decimals, target)
+	d.grid.SetPriceInterval(target)
+}
+
+// roundTo 按价格精度取整
+func roundTo(price float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(price*factor) / factor
+}
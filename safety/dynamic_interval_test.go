@@ -0,0 +1,65 @@
+package safety
+
+import (
+	"testing"
+
+	"opensqt/config"
+)
+
+// intervalGrid 测试用网格：记录每次调整后的价格间隔
+type intervalGrid struct {
+	interval float64
+	changes  []float64
+}
+
+func (g *intervalGrid) GetPriceInterval() float64     { return g.interval }
+func (g *intervalGrid) GetSellPriceInterval() float64 { return g.interval }
+func (g *intervalGrid) GetFeeRate() float64           { return 0.0002 }
+func (g *intervalGrid) GetPriceDecimals() int         { return 2 }
+func (g *intervalGrid) GetOrderQuantity() float64     { return 20 }
+func (g *intervalGrid) GetBuyWindowSize() int         { return 10 }
+
+func (g *intervalGrid) SetPriceInterval(interval float64) {
+	g.interval = interval
+	g.changes = append(g.changes, interval)
+}
+
+func TestDynamicIntervalSyntheticSeries(t *testing.T) {
+	cfg := &config.Config{}
+	dynamic := &cfg.Trading.DynamicInterval
+	dynamic.Enabled = true
+	dynamic.Window = 4
+	dynamic.Multiplier = 2
+	dynamic.MinInterval = 0.5
+	dynamic.MaxInterval = 3
+	dynamic.MinChangePercent = 20
+	grid := &intervalGrid{interval: 1}
+	d := NewDynamicInterval(cfg, grid, nil)
+
+	steps := []struct {
+		price float64
+		want  []float64 // 该样本之后累计的间隔调整
+	}{
+		{100, nil},
+		{102, nil},
+		{100, nil},                 // 窗口未填满，不调整
+		{102, []float64{2}},        // 标准差 1 × 2 = 2，相对 1 变化 100%
+		{100, []float64{2}},        // 标准差仍为 1，目标间隔不变
+		{101, []float64{2}},        // 标准差 0.829 × 2 = 1.66，变化 17% 不足 20%
+		{95, []float64{2, 3}},      // 标准差 2.69 × 2 = 5.39，限制在上限 3
+		{95, []float64{2, 3}},      // 窗口 100,101,95,95：标准差 2.77 × 2 限制在 3，不变
+		{95, []float64{2, 3}},      // 窗口 101,95,95,95：标准差 2.60 × 2 限制在 3，不变
+		{95, []float64{2, 3, 0.5}}, // 价格不再变化，标准差 0，限制在下限 0.5
+	}
+	for i, step := range steps {
+		d.addSample(step.price)
+		if len(grid.changes) != len(step.want) {
+			t.Fatalf("第 %d 个样本 %.2f 之后间隔调整应为 %v，实际 %v", i+1, step.price, step.want, grid.changes)
+		}
+		for j := range step.want {
+			if grid.changes[j] != step.want[j] {
+				t.Fatalf("第 %d 个样本 %.2f 之后间隔调整应为 %v，实际 %v", i+1, step.price, step.want, grid.changes)
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package safety
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// ExchangeHealthMonitor 交易所健康监测器
+// 统计 REST 调用中的 5xx 服务端错误，窗口内超过阈值时暂停挂单（非致命），
+// 暂停期间定期做健康检查，交易所恢复后自动解除暂停
+type ExchangeHealthMonitor struct {
+	cfg      *config.Config
+	exchange exchange.IExchange // 用于健康检查（应传入未被观察的原始实例）
+
+	mu           sync.Mutex
+	errorTimes   []time.Time // 统计窗口内的5xx错误时间
+	pausedAt     time.Time
+	pausedErrors int // 暂停期间累计的5xx错误数
+	totalErrors  int64
+
+	paused atomic.Bool
+}
+
+// NewExchangeHealthMonitor 创建交易所健康监测器
+func NewExchangeHealthMonitor(cfg *config.Config, ex exchange.IExchange) *ExchangeHealthMonitor {
+	return &ExchangeHealthMonitor{
+		cfg:        cfg,
+		exchange:   ex,
+		errorTimes: make([]time.Time, 0, cfg.ExchangeHealth.ErrorThreshold),
+	}
+}
+
+// RecordResult 记录一次 REST 调用结果（实现 exchange.CallObserver）
+func (h *ExchangeHealthMonitor) RecordResult(method string, err error) {
+	if !exchange.IsServerError(err) {
+		return
+	}
+
+	now := time.Now()
+	window := time.Duration(h.cfg.ExchangeHealth.ErrorWindow) * time.Second
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.totalErrors++
+
+	if h.paused.Load() {
+		h.pausedErrors++
+		return
+	}
+
+	// 清理窗口外的旧错误
+	valid := h.errorTimes[:0]
+	for _, t := range h.errorTimes {
+		if now.Sub(t) <= window {
+			valid = append(valid, t)
+		}
+	}
+	h.errorTimes = append(valid, now)
+
+	logger.Warn("⚠️ [交易所健康] %s 返回服务端错误 (%d/%d, 窗口%ds): %v",
+		method, len(h.errorTimes), h.cfg.ExchangeHealth.ErrorThreshold, h.cfg.ExchangeHealth.ErrorWindow, err)
+
+	if len(h.errorTimes) >= h.cfg.ExchangeHealth.ErrorThreshold {
+		h.pausedAt = now
+		h.pausedErrors = 0
+		h.paused.Store(true)
+		logger.Error("🚨 [交易所健康] %ds 内出现 %d 次 5xx 错误，暂停挂单（累计 %d 次），每 %ds 检查一次交易所状态",
+			h.cfg.ExchangeHealth.ErrorWindow, len(h.errorTimes), h.totalErrors, h.cfg.ExchangeHealth.RecheckInterval)
+		event.Publish(event.TypeExchangePaused, event.ExchangePaused{
+			ErrorCount:  len(h.errorTimes),
+			TotalErrors: h.totalErrors,
+		})
+		h.errorTimes = h.errorTimes[:0]
+	}
+}
+
+// Start 启动健康检查协程（仅在暂停期间发起检查请求）
+func (h *ExchangeHealthMonitor) Start(ctx context.Context) {
+	if !h.cfg.ExchangeHealth.Enabled {
+		return
+	}
+
+	interval := time.Duration(h.cfg.ExchangeHealth.RecheckInterval) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger.Info("✅ 交易所健康监测已启动 (阈值: %d次/%ds, 检查间隔: %ds)",
+		h.cfg.ExchangeHealth.ErrorThreshold, h.cfg.ExchangeHealth.ErrorWindow, h.cfg.ExchangeHealth.RecheckInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if h.paused.Load() {
+				h.recheck(ctx)
+			}
+		}
+	}
+}
+
+// recheck 暂停期间检查交易所是否恢复
+func (h *ExchangeHealthMonitor) recheck(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := h.exchange.GetAccount(checkCtx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err != nil {
+		if exchange.IsServerError(err) {
+			h.pausedErrors++
+			h.totalErrors++
+		}
+		logger.Warn("⚠️ [交易所健康] 交易所仍不可用（已暂停 %s，暂停期间错误 %d 次）: %v",
+			time.Since(h.pausedAt).Round(time.Second), h.pausedErrors, err)
+		return
+	}
+
+	logger.Info("✅ [交易所健康] 交易所已恢复，恢复挂单（暂停 %s，暂停期间错误 %d 次，累计 %d 次）",
+		time.Since(h.pausedAt).Round(time.Second), h.pausedErrors, h.totalErrors)
+	event.Publish(event.TypeExchangeResumed, event.ExchangeResumed{
+		PausedFor:    time.Since(h.pausedAt),
+		PausedErrors: h.pausedErrors,
+	})
+	h.errorTimes = h.errorTimes[:0]
+	h.paused.Store(false)
+}
+
+// IsPaused 是否因交易所故障暂停挂单
+func (h *ExchangeHealthMonitor) IsPaused() bool {
+	return h.paused.Load()
+}
@@ -0,0 +1,213 @@
+package safety
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"opensqt/config"
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// tradeOverlap 查询成交时向前多取的时间，避免本地时钟与交易所时间偏差漏算边界上的成交（按成交ID去重）
+const tradeOverlap = time.Minute
+
+// ExternalBalanceMonitor 外部资金变动监控器
+// 定期查询钱包余额，与期间成交的已实现盈亏和手续费比较，差额超过阈值视为外部资金变动
+// （手动充值/提现、同账户其他交易对的交易等），记录告警并可选地调整止盈基准
+type ExternalBalanceMonitor struct {
+	cfg        *config.Config
+	exchange   exchange.IExchange
+	takeProfit *TakeProfitMonitor // 为 nil 或未启用止盈时只记录告警
+	stopLoss   *StopLossMonitor   // 为 nil 或未启用止损时不调整止损基准
+
+	lastWallet float64
+	lastCheck  time.Time
+	counted    map[tradeKey]time.Time // 已计入的成交 -> 成交时间
+	pressure   IPressureSource        // 接口压力来源（timing.adaptive_polling），nil 表示固定间隔
+	sanity     *balanceSanity         // 余额读数合理性检查（safety.balance_sanity）
+
+	// 交易对 -> 交易所实例（多交易对时统计所有交易对的成交，未设置时只统计主交易对）
+	symbolExchanges map[string]exchange.IExchange
+}
+
+// SetPressureSource 设置接口压力来源，压力大时拉长检查间隔（需在 Start 之前调用）
+func (m *ExternalBalanceMonitor) SetPressureSource(source IPressureSource) {
+	m.pressure = source
+}
+
+// SetSymbolExchanges 多交易对时设置各交易对的交易所实例（适配器绑定交易对，需用对应实例查询成交），需在 Start 之前调用
+func (m *ExternalBalanceMonitor) SetSymbolExchanges(exchanges map[string]exchange.IExchange) {
+	m.symbolExchanges = exchanges
+}
+
+// SetStopLoss 设置止损监控，rebaseline 时同时调整止损基准（需在 Start 之前调用）
+func (m *ExternalBalanceMonitor) SetStopLoss(stopLoss *StopLossMonitor) {
+	m.stopLoss = stopLoss
+}
+
+// NewExternalBalanceMonitor 创建外部资金变动监控器
+func NewExternalBalanceMonitor(cfg *config.Config, ex exchange.IExchange, takeProfit *TakeProfitMonitor) *ExternalBalanceMonitor {
+	return &ExternalBalanceMonitor{
+		cfg:        cfg,
+		exchange:   ex,
+		takeProfit: takeProfit,
+		counted:    make(map[tradeKey]time.Time),
+		sanity:     newBalanceSanity(cfg, "外部资金监控"),
+	}
+}
+
+// tradeKey 成交的唯一标识（不同交易对的成交ID可能重复）
+type tradeKey struct {
+	symbol string
+	id     int64
+}
+
+// userTrades 查询所有交易对自 since 以来的成交
+func (m *ExternalBalanceMonitor) userTrades(ctx context.Context, since time.Time) (map[string][]*exchange.Trade, error) {
+	exchanges := m.symbolExchanges
+	if len(exchanges) == 0 {
+		exchanges = map[string]exchange.IExchange{m.cfg.Trading.Symbol: m.exchange}
+	}
+	result := make(map[string][]*exchange.Trade, len(exchanges))
+	for symbol, ex := range exchanges {
+		trades, err := ex.GetUserTrades(ctx, symbol, since)
+		if err != nil {
+			return nil, err
+		}
+		result[symbol] = trades
+	}
+	return result, nil
+}
+
+// Start 启动外部资金变动监控
+func (m *ExternalBalanceMonitor) Start(ctx context.Context) {
+	check := m.cfg.Trading.ExternalBalanceCheck
+	if !check.Enabled {
+		return
+	}
+	if !m.baseline(ctx) {
+		return
+	}
+
+	logger.Info("💸 [外部资金监控] 启动 (钱包余额: %.2f %s, 阈值: %.2f, 间隔: %ds, 调整止盈基准: %v)",
+		m.lastWallet, m.exchange.GetQuoteAsset(), check.Threshold, check.CheckInterval, check.Rebaseline)
+
+	interval := newPollInterval(m.cfg, "外部资金监控", time.Duration(check.CheckInterval)*time.Second, m.pressure)
+	timer := time.NewTimer(interval.next())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			m.check(ctx)
+			timer.Reset(interval.next())
+		}
+	}
+}
+
+// baseline 记录初始钱包余额，并把启动前的成交标记为已计入
+func (m *ExternalBalanceMonitor) baseline(ctx context.Context) bool {
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	account, err := m.exchange.GetAccount(reqCtx)
+	if err != nil {
+		logger.Warn("⚠️ [外部资金监控] 获取账户信息失败: %v，监控不生效", err)
+		return false
+	}
+	if account.TotalWalletBalance <= 0 {
+		logger.Warn("⚠️ [外部资金监控] %s 未返回钱包余额，监控不生效", m.exchange.GetName())
+		return false
+	}
+
+	now := time.Now()
+	trades, err := m.userTrades(reqCtx, now.Add(-tradeOverlap))
+	if err != nil {
+		logger.Warn("⚠️ [外部资金监控] 查询成交失败: %v，监控不生效", err)
+		return false
+	}
+	for symbol, symbolTrades := range trades {
+		for _, t := range symbolTrades {
+			m.counted[tradeKey{symbol, t.TradeID}] = t.Time
+		}
+	}
+
+	m.sanity.Check(account.TotalWalletBalance)
+	m.lastWallet = account.TotalWalletBalance
+	m.lastCheck = now
+	return true
+}
+
+// check 比较一次钱包余额变化与成交盈亏
+func (m *ExternalBalanceMonitor) check(ctx context.Context) {
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	trades, err := m.userTrades(reqCtx, m.lastCheck.Add(-tradeOverlap))
+	if err != nil {
+		logger.Warn("⚠️ [外部资金监控] 查询成交失败: %v", err)
+		return
+	}
+	account, err := m.exchange.GetAccount(reqCtx)
+	if err != nil {
+		logger.Warn("⚠️ [外部资金监控] 获取账户信息失败: %v", err)
+		return
+	}
+	if account.TotalWalletBalance <= 0 {
+		return
+	}
+	if err := m.sanity.Check(account.TotalWalletBalance); err != nil {
+		logger.Warn("⚠️ [外部资金监控] %v，跳过本次检查", err)
+		return
+	}
+
+	quoteAsset := m.exchange.GetQuoteAsset()
+	var explained float64
+	newTrades := 0
+	for symbol, symbolTrades := range trades {
+		for _, t := range symbolTrades {
+			key := tradeKey{symbol, t.TradeID}
+			if _, seen := m.counted[key]; seen {
+				continue
+			}
+			m.counted[key] = t.Time
+			newTrades++
+			explained += t.RealizedPnL
+			if t.FeeAsset == "" || t.FeeAsset == quoteAsset {
+				explained -= t.Fee
+			}
+		}
+	}
+	for id, tradeTime := range m.counted {
+		if tradeTime.Before(m.lastCheck.Add(-2 * tradeOverlap)) {
+			delete(m.counted, id)
+		}
+	}
+
+	oldWallet := m.lastWallet
+	change := account.TotalWalletBalance - oldWallet
+	unexplained := change - explained
+	m.lastWallet = account.TotalWalletBalance
+	m.lastCheck = now
+
+	if math.Abs(unexplained) < m.cfg.Trading.ExternalBalanceCheck.Threshold {
+		logger.Debug("💸 [外部资金监控] 钱包余额变化 %+.4f, 成交解释 %+.4f (%d 笔), 未解释 %+.4f",
+			change, explained, newTrades, unexplained)
+		return
+	}
+
+	logger.Warn("💸 [外部资金变动] 钱包余额 %.2f -> %.2f %s, 期间 %d 笔成交解释 %+.4f, 未解释变动 %+.2f %s（充值/提现或其他交易对的交易）",
+		oldWallet, account.TotalWalletBalance, quoteAsset, newTrades, explained, unexplained, quoteAsset)
+
+	if m.cfg.Trading.ExternalBalanceCheck.Rebaseline && m.takeProfit != nil && m.cfg.Trading.TakeProfit.Enabled {
+		m.takeProfit.AdjustInitialBalance(unexplained)
+	}
+	if m.cfg.Trading.ExternalBalanceCheck.Rebaseline && m.stopLoss != nil && m.cfg.Trading.StopLoss.Enabled {
+		m.stopLoss.AdjustInitialBalance(unexplained)
+	}
+}
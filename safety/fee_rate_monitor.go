@@ -0,0 +1,124 @@
+package safety
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// IFeeReseeder 按新费率重铺网格的仓位管理器（返回 false 表示暂不满足条件，稍后重试）
+type IFeeReseeder interface {
+	ReseedForFeeRate(feeRate float64) bool
+}
+
+// FeeRateMonitor 手续费率监控器
+// 定期查询交易所实时 maker 费率，检测到变化（如 VIP 等级调整）时通知仓位管理器重新定价卖单；
+// 启用 reseed_grid 时，费率相对上次铺网明显变化后按新费率重铺网格
+type FeeRateMonitor struct {
+	cfg      *config.Config
+	exchange exchange.IExchange
+	lastRate float64
+
+	reseeder      IFeeReseeder
+	seededRate    float64 // 当前网格间隔对应的费率
+	reseedPending bool
+}
+
+// NewFeeRateMonitor 创建手续费率监控器（初始费率取配置中的 fee_rate）
+func NewFeeRateMonitor(cfg *config.Config, ex exchange.IExchange) *FeeRateMonitor {
+	return &FeeRateMonitor{
+		cfg:        cfg,
+		exchange:   ex,
+		lastRate:   cfg.Exchanges[cfg.App.CurrentExchange].FeeRate,
+		seededRate: cfg.Exchanges[cfg.App.CurrentExchange].FeeRate,
+	}
+}
+
+// SetReseeder 设置费率明显变化后的网格重铺处理（需在 Start 之前调用）
+func (f *FeeRateMonitor) SetReseeder(reseeder IFeeReseeder) {
+	f.reseeder = reseeder
+}
+
+// Start 启动费率监控，费率变化时调用 onChange(旧费率, 新费率)
+func (f *FeeRateMonitor) Start(ctx context.Context, onChange func(oldRate, newRate float64)) {
+	if !f.cfg.Trading.FeeReprice.Enabled {
+		return
+	}
+
+	interval := time.Duration(f.cfg.Trading.FeeReprice.CheckInterval) * time.Second
+	logger.Info("💳 [费率监控] 启动 (初始费率: %.4f%%, 间隔: %ds)", f.lastRate*100, f.cfg.Trading.FeeReprice.CheckInterval)
+
+	// 启动时立即检查一次，配置的 fee_rate 可能与账户实际费率不一致
+	if !f.check(ctx, onChange) {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !f.check(ctx, onChange) {
+				return
+			}
+		}
+	}
+}
+
+// check 查询一次费率，返回 false 表示交易所不支持费率查询（停止监控）
+func (f *FeeRateMonitor) check(ctx context.Context, onChange func(oldRate, newRate float64)) bool {
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	maker, _, supported, err := exchange.GetTradingFees(checkCtx, f.exchange, f.cfg.Trading.Symbol)
+	if !supported {
+		logger.Warn("⚠️ [费率监控] %s 不支持实时查询手续费率，fee_reprice 不生效", f.exchange.GetName())
+		return false
+	}
+	if err != nil {
+		logger.Warn("⚠️ [费率监控] 查询手续费率失败: %v", err)
+		return true
+	}
+
+	if math.Abs(maker-f.lastRate) >= 1e-9 {
+		oldRate := f.lastRate
+		f.lastRate = maker
+		logger.Warn("💳 [费率监控] 检测到手续费率变化: %.4f%% -> %.4f%%", oldRate*100, maker*100)
+		event.Publish(event.TypeFeeRateChanged, event.FeeRateChanged{OldRate: oldRate, NewRate: maker})
+		onChange(oldRate, maker)
+		f.checkReseed(maker)
+	}
+
+	// 持仓超过容差而推迟的重铺，每次检查时重试
+	if f.reseedPending && f.reseeder.ReseedForFeeRate(f.lastRate) {
+		f.reseedPending = false
+		f.seededRate = f.lastRate
+	}
+	return true
+}
+
+// checkReseed 费率相对上次铺网变化超过 min_change_percent 时标记需要重铺
+func (f *FeeRateMonitor) checkReseed(rate float64) {
+	reseed := f.cfg.Trading.FeeReprice.ReseedGrid
+	if !reseed.Enabled || f.reseeder == nil {
+		return
+	}
+	change := math.Abs(rate - f.seededRate)
+	if f.seededRate > 0 {
+		change = change / f.seededRate * 100
+	} else if change > 0 {
+		change = math.Inf(1)
+	}
+	f.reseedPending = change >= reseed.MinChangePercent
+	if f.reseedPending {
+		logger.Info("💳 [费率监控] 费率相对铺网时 %.4f%% 变化 %.1f%%，按新费率重铺网格", f.seededRate*100, change)
+	}
+}
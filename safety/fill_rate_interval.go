@@ -0,0 +1,134 @@
+package safety
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/logger"
+)
+
+// IFillRateGrid 成交频率自适应间隔需要的网格状态
+type IFillRateGrid interface {
+	IGridState
+	SetPriceInterval(interval float64)
+}
+
+// FillRateInterval 成交频率自适应间隔（trading.fill_rate_interval）
+// 间隔相对当前行情过宽时挂单长期不成交、资金闲置，过窄时频繁换手。按固定窗口统计完全成交笔数：
+// 少于 min_fills 时缩小间隔，多于 max_fills 时放大间隔，范围 [min_interval, max_interval]，
+// 缩小后按当前价格估算的每笔净利润必须为正，不会把间隔压到手续费保本线以下
+type FillRateInterval struct {
+	cfg     *config.Config
+	grid    IFillRateGrid
+	priceFn func() float64
+
+	fills  atomic.Int64 // 当前窗口内的完全成交笔数
+	warned bool         // 已提示无法继续缩小（成交恢复前不重复提示）
+}
+
+// NewFillRateInterval 创建成交频率自适应间隔控制器，priceFn 返回最新市场价格
+func NewFillRateInterval(cfg *config.Config, grid IFillRateGrid, priceFn func() float64) *FillRateInterval {
+	return &FillRateInterval{
+		cfg:     cfg,
+		grid:    grid,
+		priceFn: priceFn,
+	}
+}
+
+// Start 启动成交频率自适应间隔（阻塞直到 ctx 取消，未启用时直接返回）
+func (f *FillRateInterval) Start(ctx context.Context) {
+	fillRate := f.cfg.Trading.FillRateInterval
+	if !fillRate.Enabled {
+		return
+	}
+
+	unsubscribe := event.Subscribe("fill-rate-interval", func(e event.Event) {
+		// 多交易对时只统计本交易对的成交
+		if fill, ok := e.Payload.(event.OrderFilled); ok && fill.Symbol == f.cfg.Trading.Symbol && fill.Complete {
+			f.fills.Add(1)
+		}
+	}, event.TypeOrderFilled)
+	defer unsubscribe()
+
+	decimals := f.grid.GetPriceDecimals()
+	logger.Info("🎚️ [成交频率间隔] 启动 (窗口: %d分钟, 成交 < %d 笔缩小 / > %d 笔放大, 步长: %.0f%%, 范围: %.*f ~ %.*f)",
+		fillRate.WindowMinutes, fillRate.MinFills, fillRate.MaxFills, fillRate.StepPercent,
+		decimals, fillRate.MinInterval, decimals, fillRate.MaxInterval)
+
+	ticker := time.NewTicker(time.Duration(fillRate.WindowMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.evaluateWindow()
+		}
+	}
+}
+
+// evaluateWindow 结算一个统计窗口，成交过少或过多时调整价格间隔
+func (f *FillRateInterval) evaluateWindow() {
+	fillRate := f.cfg.Trading.FillRateInterval
+	fills := f.fills.Swap(0)
+	decimals := f.grid.GetPriceDecimals()
+	current := f.grid.GetPriceInterval()
+	step := 1 + fillRate.StepPercent/100
+
+	switch {
+	case fills < int64(fillRate.MinFills):
+		target := math.Max(f.roundInterval(current/step), fillRate.MinInterval)
+		if target >= current {
+			logger.Debug("🎚️ [成交频率间隔] 窗口成交 %d 笔（< %d），间隔 %.*f 已到下限 %.*f",
+				fills, fillRate.MinFills, decimals, current, decimals, fillRate.MinInterval)
+			return
+		}
+		// 缩小后的间隔仍需覆盖手续费
+		if price := f.priceFn(); price > 0 {
+			if trade := EstimateTradeProfit(price, f.grid.GetOrderQuantity(), target, f.grid.GetFeeRate()); trade.NetProfit <= 0 {
+				if !f.warned {
+					f.warned = true
+					logger.Warn("⚠️ [成交频率间隔] 窗口成交 %d 笔（< %d），但间隔 %.*f 的每笔净利润 %.4f ≤ 0，保持当前间隔 %.*f",
+						fills, fillRate.MinFills, decimals, target, trade.NetProfit, decimals, current)
+				}
+				return
+			}
+		}
+		f.warned = false
+		logger.Info("🎚️ [成交频率间隔] 窗口 %d 分钟内成交 %d 笔（< %d），挂单离价格过远，缩小价格间隔: %.*f -> %.*f",
+			fillRate.WindowMinutes, fills, fillRate.MinFills, decimals, current, decimals, target)
+		f.grid.SetPriceInterval(target)
+
+	case fills > int64(fillRate.MaxFills):
+		f.warned = false
+		target := math.Min(f.roundInterval(current*step), fillRate.MaxInterval)
+		if target <= current {
+			logger.Debug("🎚️ [成交频率间隔] 窗口成交 %d 笔（> %d），间隔 %.*f 已到上限 %.*f",
+				fills, fillRate.MaxFills, decimals, current, decimals, fillRate.MaxInterval)
+			return
+		}
+		logger.Info("🎚️ [成交频率间隔] 窗口 %d 分钟内成交 %d 笔（> %d），换手过于频繁，放大价格间隔: %.*f -> %.*f",
+			fillRate.WindowMinutes, fills, fillRate.MaxFills, decimals, current, decimals, target)
+		f.grid.SetPriceInterval(target)
+
+	default:
+		f.warned = false
+		logger.Debug("🎚️ [成交频率间隔] 窗口成交 %d 笔，在 [%d, %d] 范围内，保持间隔 %.*f",
+			fills, fillRate.MinFills, fillRate.MaxFills, decimals, current)
+	}
+}
+
+// roundInterval 按价格精度取整（至少一个最小价格单位）
+func (f *FillRateInterval) roundInterval(interval float64) float64 {
+	factor := math.Pow(10, float64(f.grid.GetPriceDecimals()))
+	rounded := math.Round(interval*factor) / factor
+	if rounded <= 0 {
+		rounded = 1 / factor
+	}
+	return rounded
+}
@@ -0,0 +1,130 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/exchange"
+	"opensqt/logger"
+	"opensqt/notify"
+)
+
+// FundingMonitor 资金费率风控（risk_control.funding）
+// 网格只做多，资金费率为正时多头每个结算周期向空头支付资金费，长时间持有大量多仓会悄悄侵蚀网格利润。
+// 每 check_interval 秒查询一次交易对的资金费率，超过 max_funding_rate 时暂停新增买单（与K线风控一样撤销买单、保留卖单），
+// 回落到上限以内后自动恢复。查询失败时保持当前状态
+type FundingMonitor struct {
+	cfg      *config.Config
+	exchange exchange.IExchange
+	paused   atomic.Bool
+
+	notifier notify.INotifier // 暂停/恢复告警（nil 表示不推送）
+}
+
+// NewFundingMonitor 创建资金费率风控
+func NewFundingMonitor(cfg *config.Config, ex exchange.IExchange) *FundingMonitor {
+	return &FundingMonitor{
+		cfg:      cfg,
+		exchange: ex,
+	}
+}
+
+// SetNotifier 设置告警推送（需在 Start 之前调用）
+func (f *FundingMonitor) SetNotifier(n notify.INotifier) {
+	f.notifier = n
+}
+
+// IsPaused 是否因资金费率过高暂停新增买单
+func (f *FundingMonitor) IsPaused() bool {
+	return f.paused.Load()
+}
+
+// Start 启动资金费率监控（阻塞直到 ctx 取消，未启用或交易所不支持时直接返回）
+func (f *FundingMonitor) Start(ctx context.Context) {
+	funding := f.cfg.RiskControl.Funding
+	if !funding.Enabled {
+		return
+	}
+
+	logger.Info("💸 [资金费率] 启动 (资金费率超过 %.4f%% 时暂停新增买单, 间隔: %ds)",
+		funding.MaxFundingRate*100, funding.CheckInterval)
+
+	if !f.check(ctx) {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(funding.CheckInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !f.check(ctx) {
+				return
+			}
+		}
+	}
+}
+
+// check 查询一次资金费率并切换暂停状态，返回 false 表示交易所不支持资金费率查询（停止监控）
+func (f *FundingMonitor) check(ctx context.Context) bool {
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	symbol := f.cfg.Trading.Symbol
+	rate, supported, err := exchange.GetFundingRate(checkCtx, f.exchange, symbol)
+	if !supported {
+		logger.Warn("⚠️ [资金费率] %s 不支持查询资金费率，risk_control.funding 不生效", f.exchange.GetName())
+		return false
+	}
+	if err != nil {
+		logger.Warn("⚠️ [资金费率] 查询资金费率失败: %v", err)
+		return true
+	}
+	f.apply(rate)
+	return true
+}
+
+// apply 按资金费率切换暂停状态
+func (f *FundingMonitor) apply(rate float64) {
+	maxRate := f.cfg.RiskControl.Funding.MaxFundingRate
+	symbol := f.cfg.Trading.Symbol
+	payload := event.FundingChanged{Symbol: symbol, FundingRate: rate, MaxFundingRate: maxRate}
+	fields := map[string]interface{}{
+		"funding_rate":     fmt.Sprintf("%.4f%%", rate*100),
+		"max_funding_rate": fmt.Sprintf("%.4f%%", maxRate*100),
+	}
+
+	if rate > maxRate {
+		if !f.paused.CompareAndSwap(false, true) {
+			logger.Debug("💸 [资金费率] 当前资金费率 %.4f%%，仍高于上限 %.4f%%", rate*100, maxRate*100)
+			return
+		}
+		logger.Warn("💸 [资金费率] 资金费率 %.4f%% > %.4f%%，撤销买单并暂停新增买单", rate*100, maxRate*100)
+		event.Publish(event.TypeFundingPaused, payload)
+		notify.Send(f.notifier, notify.Event{
+			Type:    notify.EventRiskTriggered,
+			Symbol:  symbol,
+			Message: "💸 资金费率过高，暂停新增买单",
+			Fields:  fields,
+		})
+		return
+	}
+
+	if f.paused.CompareAndSwap(true, false) {
+		logger.Info("✅ [资金费率] 资金费率 %.4f%% 回落到上限 %.4f%% 以内，恢复新增买单", rate*100, maxRate*100)
+		event.Publish(event.TypeFundingResumed, payload)
+		notify.Send(f.notifier, notify.Event{
+			Type:    notify.EventRiskRecovered,
+			Symbol:  symbol,
+			Message: "✅ 资金费率回落，恢复新增买单",
+			Fields:  fields,
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"opensqt/config"
+	"opensqt/exchange"
+)
+
+// fundingExchange 测试用交易所：返回预设的资金费率或查询错误
+type fundingExchange struct {
+	exchange.IExchange
+	rate float64
+	err  error
+}
+
+func (f *fundingExchange) GetName() string { return "Fake" }
+
+func (f *fundingExchange) GetFundingRate(ctx context.Context, symbol string) (float64, error) {
+	return f.rate, f.err
+}
+
+// noFundingExchange 不支持资金费率查询的交易所
+type noFundingExchange struct {
+	exchange.IExchange
+}
+
+func (noFundingExchange) GetName() string { return "Fake" }
+
+func newFundingConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Trading.Symbol = "ETHUSDT"
+	cfg.RiskControl.Funding.Enabled = true
+	cfg.RiskControl.Funding.MaxFundingRate = 0.0005
+	return cfg
+}
+
+func TestFundingMonitorThreshold(t *testing.T) {
+	ex := &fundingExchange{}
+	f := NewFundingMonitor(newFundingConfig(), ex)
+
+	steps := []struct {
+		name   string
+		rate   float64
+		err    error
+		paused bool
+	}{
+		{"低于上限", 0.0001, nil, false},
+		{"等于上限不暂停", 0.0005, nil, false},
+		{"高于上限暂停", 0.0006, nil, true},
+		{"仍高于上限保持暂停", 0.001, nil, true},
+		{"查询失败保持暂停", 0, errors.New("timeout"), true},
+		{"回落到上限恢复", 0.0005, nil, false},
+		{"负费率不暂停", -0.001, nil, false},
+		{"查询失败保持未暂停", 0.01, errors.New("timeout"), false},
+	}
+	for _, step := range steps {
+		ex.rate, ex.err = step.rate, step.err
+		if !f.check(context.Background()) {
+			t.Fatalf("%s: 支持资金费率查询的交易所应继续监控", step.name)
+		}
+		if f.IsPaused() != step.paused {
+			t.Fatalf("%s: 资金费率 %.4f%% 时暂停状态应为 %v", step.name, step.rate*100, step.paused)
+		}
+	}
+}
+
+func TestFundingMonitorUnsupported(t *testing.T) {
+	f := NewFundingMonitor(newFundingConfig(), noFundingExchange{})
+	if f.check(context.Background()) {
+		t.Fatal("交易所不支持资金费率查询时应停止监控")
+	}
+	if f.IsPaused() {
+		t.Fatal("不支持资金费率查询时不应暂停")
+	}
+}
@@ -0,0 +1,91 @@
+package safety
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"opensqt/config"
+	"opensqt/logger"
+)
+
+// IAgedInventoryFlattener 平掉超时持仓所需的仓位管理器接口
+type IAgedInventoryFlattener interface {
+	FlattenAgedInventory(maxHold time.Duration) int
+}
+
+// HoldTimeMonitor 持仓时长监控（trading.max_hold_seconds）
+// 定期市价平掉持有超过上限的槽位持仓；配置了 max_hold_pause_until 时平仓后暂停挂单到下一个交易时段开始
+type HoldTimeMonitor struct {
+	cfg        *config.Config
+	pm         IAgedInventoryFlattener
+	pauseUntil atomic.Int64 // 暂停挂单截止时间（UnixNano，0表示未暂停）
+}
+
+// NewHoldTimeMonitor 创建持仓时长监控器
+func NewHoldTimeMonitor(cfg *config.Config, pm IAgedInventoryFlattener) *HoldTimeMonitor {
+	return &HoldTimeMonitor{cfg: cfg, pm: pm}
+}
+
+// IsPaused 超时平仓后是否处于暂停挂单期间
+func (m *HoldTimeMonitor) IsPaused() bool {
+	until := m.pauseUntil.Load()
+	if until == 0 {
+		return false
+	}
+	if time.Now().UnixNano() < until {
+		return true
+	}
+	if m.pauseUntil.CompareAndSwap(until, 0) {
+		logger.Info("▶️ [持仓超时] 已到下一个交易时段，恢复挂单")
+	}
+	return false
+}
+
+// Start 启动持仓时长监控（阻塞直到 ctx 取消）
+func (m *HoldTimeMonitor) Start(ctx context.Context) {
+	if m.cfg.Trading.MaxHoldSeconds <= 0 {
+		return
+	}
+	maxHold := time.Duration(m.cfg.Trading.MaxHoldSeconds) * time.Second
+
+	// 检查间隔取上限的 1/10，限制在 1~60 秒之间
+	interval := min(max(maxHold/10, time.Second), time.Minute)
+	logger.Info("⏰ [持仓超时] 启动 (最长持有: %v, 检查间隔: %v, 平仓后暂停到: %s)",
+		maxHold, interval, pauseUntilDesc(m.cfg.Trading.MaxHoldPauseUntil))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := m.pm.FlattenAgedInventory(maxHold); n > 0 && m.cfg.Trading.MaxHoldPauseUntil != "" {
+				resume := nextSessionStart(time.Now(), m.cfg.Trading.MaxHoldPauseUntil)
+				m.pauseUntil.Store(resume.UnixNano())
+				logger.Warn("⏸️ [持仓超时] 已平仓 %d 个超时槽位，暂停挂单到 %s", n, resume.Format("2006-01-02 15:04"))
+			}
+		}
+	}
+}
+
+// nextSessionStart 下一个交易时段开始时间（本地时间 HH:MM 的下一次出现）
+func nextSessionStart(now time.Time, hhmm string) time.Time {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return now
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	if !start.After(now) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+func pauseUntilDesc(hhmm string) string {
+	if hhmm == "" {
+		return "不暂停"
+	}
+	return "下一个 " + hhmm
+}
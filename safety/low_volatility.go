@@ -0,0 +1,145 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"opensqt/config"
+	"opensqt/logger"
+)
+
+// IVolatilitySource 波动率来源（风控监视器的K线平均振幅）
+type IVolatilitySource interface {
+	AverageRangePercent(symbol string) (float64, bool)
+}
+
+// ILowVolatilityGrid 低波动集中挂单需要的网格状态
+type ILowVolatilityGrid interface {
+	IGridState
+	SetPriceInterval(interval float64)
+}
+
+// LowVolatilityGrid 低波动集中挂单（trading.low_volatility）
+// 平静行情中宽网格的大部分挂单离价格很远、长期不成交。风控K线的平均振幅低于 calm_percent 时
+// 把价格间隔缩小到 interval_ratio 倍（可同时限制买单层数），挂单集中在当前价格附近换取更频繁的小额成交；
+// 振幅回升到 normal_percent 以上时恢复原间隔。两个阈值之间保持当前状态，避免在临界值附近反复撤单重挂
+type LowVolatilityGrid struct {
+	cfg     *config.Config
+	source  IVolatilitySource
+	grid    ILowVolatilityGrid
+	priceFn func() float64
+
+	narrowed     atomic.Bool
+	baseInterval float64 // 缩小前的价格间隔（恢复时使用）
+	warned       bool    // 已提示缩小后无法盈利（波动回升前不重复提示）
+}
+
+// NewLowVolatilityGrid 创建低波动集中挂单控制器，priceFn 返回最新市场价格
+func NewLowVolatilityGrid(cfg *config.Config, source IVolatilitySource, grid ILowVolatilityGrid, priceFn func() float64) *LowVolatilityGrid {
+	return &LowVolatilityGrid{
+		cfg:     cfg,
+		source:  source,
+		grid:    grid,
+		priceFn: priceFn,
+	}
+}
+
+// IsNarrowed 当前是否处于低波动集中状态
+func (l *LowVolatilityGrid) IsNarrowed() bool {
+	return l.narrowed.Load()
+}
+
+// MaxBuyDepth 当前允许挂出的买单层数（-1 表示不限制，供仓位管理器的深度限制使用）
+func (l *LowVolatilityGrid) MaxBuyDepth() int {
+	if depth := l.cfg.Trading.LowVolatility.MaxBuyDepth; depth > 0 && l.narrowed.Load() {
+		return depth
+	}
+	return -1
+}
+
+// Start 定期检查波动率（阻塞直到 ctx 取消，未启用时直接返回）
+func (l *LowVolatilityGrid) Start(ctx context.Context) {
+	lowVol := l.cfg.Trading.LowVolatility
+	if !lowVol.Enabled {
+		return
+	}
+	depth := "不限层数"
+	if lowVol.MaxBuyDepth > 0 {
+		depth = fmt.Sprintf("最多 %d 层", lowVol.MaxBuyDepth)
+	}
+	logger.Info("🧲 [低波动集中] 启动 (平均振幅 < %.3f%% 时间隔 × %.2f、买单%s，> %.3f%% 时恢复，每 %d 秒检查)",
+		lowVol.CalmPercent, lowVol.IntervalRatio, depth, lowVol.NormalPercent, lowVol.CheckInterval)
+
+	ticker := time.NewTicker(time.Duration(lowVol.CheckInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.evaluate()
+		}
+	}
+}
+
+// evaluate 按最新平均振幅切换集中/恢复状态
+func (l *LowVolatilityGrid) evaluate() {
+	lowVol := l.cfg.Trading.LowVolatility
+	volatility, ok := l.source.AverageRangePercent(l.cfg.Trading.Symbol)
+	if !ok {
+		logger.Debug("🧲 [低波动集中] K线数据不足，跳过本次检查")
+		return
+	}
+	decimals := l.grid.GetPriceDecimals()
+
+	switch {
+	case !l.narrowed.Load() && volatility < lowVol.CalmPercent:
+		base := l.grid.GetPriceInterval()
+		target := l.narrowInterval(base)
+		if target >= base {
+			logger.Debug("🧲 [低波动集中] 平均振幅 %.3f%% 偏低，但间隔 %.*f 已无法再缩小", volatility, decimals, base)
+			return
+		}
+		// 缩小后的间隔仍需覆盖手续费，否则放弃集中
+		if price := l.priceFn(); price > 0 {
+			if trade := EstimateTradeProfit(price, l.grid.GetOrderQuantity(), target, l.grid.GetFeeRate()); trade.NetProfit <= 0 {
+				if l.warned {
+					return
+				}
+				l.warned = true
+				logger.Warn("⚠️ [低波动集中] 平均振幅 %.3f%% < %.3f%%，但间隔 %.*f 的每笔净利润 %.4f ≤ 0，保持原间隔",
+					volatility, lowVol.CalmPercent, decimals, target, trade.NetProfit)
+				return
+			}
+		}
+		l.baseInterval = base
+		l.warned = false
+		l.narrowed.Store(true)
+		logger.Info("🧲 [低波动集中] 平均振幅 %.3f%% < %.3f%%，价格间隔 %.*f -> %.*f，买单集中到当前价格附近",
+			volatility, lowVol.CalmPercent, decimals, base, decimals, target)
+		l.grid.SetPriceInterval(target)
+
+	case l.narrowed.Load() && volatility > lowVol.NormalPercent:
+		l.narrowed.Store(false)
+		logger.Info("🧲 [低波动集中] 平均振幅 %.3f%% > %.3f%%，恢复价格间隔 %.*f -> %.*f",
+			volatility, lowVol.NormalPercent, decimals, l.grid.GetPriceInterval(), decimals, l.baseInterval)
+		l.grid.SetPriceInterval(l.baseInterval)
+
+	default:
+		if volatility >= lowVol.CalmPercent {
+			l.warned = false
+		}
+		logger.Debug("🧲 [低波动集中] 平均振幅 %.3f%%，集中状态: %v，价格间隔 %.*f",
+			volatility, l.narrowed.Load(), decimals, l.grid.GetPriceInterval())
+	}
+}
+
+// narrowInterval 缩小后的价格间隔（按价格精度取整，至少一个最小价格单位）
+func (l *LowVolatilityGrid) narrowInterval(base float64) float64 {
+	factor := math.Pow(10, float64(l.grid.GetPriceDecimals()))
+	target := math.Round(base*l.cfg.Trading.LowVolatility.IntervalRatio*factor) / factor
+	return math.Max(target, 1/factor)
+}
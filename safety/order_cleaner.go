@@ -3,6 +3,7 @@ package safety
 import (
 	"context"
 	"opensqt/config"
+	"opensqt/event"
 	"opensqt/logger"
 	"reflect"
 	"sort"
@@ -19,7 +20,7 @@ type OrderCleanerSlotInfo struct {
 
 // IOrderExecutor 订单执行器接口（用于批量撤单）
 type IOrderExecutor interface {
-	BatchCancelOrders(orderIDs []int64) error
+	BatchCancelOrders(orderIDs []int64, reason string) error
 }
 
 // IOrderCleanerPositionManager 订单清理所需的仓位管理器接口
@@ -193,7 +194,7 @@ func (oc *OrderCleaner) CleanupOrders() {
 				logger.Info("🧹 [订单清理-买单] 买单数: %d, 取消价格最低的 %d 个 (%.2f ~ %.2f)",
 					len(buyOrders), cancelCount, buyOrders[0].Price, buyOrders[cancelCount-1].Price)
 
-				if err := oc.executor.BatchCancelOrders(orderIDs); err != nil {
+				if err := oc.executor.BatchCancelOrders(orderIDs, event.OrderReasonCleanup); err != nil {
 					logger.Error("❌ [订单清理-买单] 批量撤单失败: %v", err)
 				} else {
 					// 更新槽位状态为已申请撤单
@@ -228,7 +229,7 @@ func (oc *OrderCleaner) CleanupOrders() {
 				logger.Info("🧹 [订单清理-卖单] 卖单数: %d, 取消价格最高的 %d 个 (%.2f ~ %.2f)",
 					len(sellOrders), cancelCount, sellOrders[0].Price, sellOrders[cancelCount-1].Price)
 
-				if err := oc.executor.BatchCancelOrders(orderIDs); err != nil {
+				if err := oc.executor.BatchCancelOrders(orderIDs, event.OrderReasonCleanup); err != nil {
 					logger.Error("❌ [订单清理-卖单] 批量撤单失败: %v", err)
 				} else {
 					// 更新槽位状态为已申请撤单
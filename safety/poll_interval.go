@@ -0,0 +1,58 @@
+package safety
+
+import (
+	"time"
+
+	"opensqt/config"
+	"opensqt/logger"
+)
+
+// IPressureSource 接口压力来源（0~1，越大表示下单路径的请求配额越紧张）
+type IPressureSource interface {
+	Pressure() float64
+}
+
+// pollInterval 监控类轮询的自适应间隔（timing.adaptive_polling）
+// 下单路径配额紧张时拉长轮询间隔，把请求配额让给下单撤单，压力缓解后逐步恢复到配置的间隔
+type pollInterval struct {
+	name      string
+	base      time.Duration // 配置的间隔（下限）
+	max       time.Duration
+	threshold float64
+	source    IPressureSource // nil 表示不调整
+	current   time.Duration
+}
+
+func newPollInterval(cfg *config.Config, name string, base time.Duration, source IPressureSource) *pollInterval {
+	p := &pollInterval{name: name, base: base, max: base, current: base}
+	if cfg.Timing.AdaptivePolling.Enabled && source != nil {
+		p.source = source
+		p.threshold = cfg.Timing.AdaptivePolling.PressureThreshold / 100
+		p.max = time.Duration(float64(base) * cfg.Timing.AdaptivePolling.MaxMultiplier)
+	}
+	return p
+}
+
+// next 返回下一次轮询前的等待时间
+// 压力超过阈值时间隔翻倍（不超过上限），压力低于阈值一半时减半（不低于配置的间隔）
+func (p *pollInterval) next() time.Duration {
+	if p.source == nil {
+		return p.base
+	}
+
+	pressure := p.source.Pressure()
+	old := p.current
+	switch {
+	case pressure >= p.threshold:
+		p.current = min(p.current*2, p.max)
+	case pressure < p.threshold/2:
+		p.current = max(p.current/2, p.base)
+	}
+
+	if p.current > old {
+		logger.Warn("🐢 [%s] 接口压力 %.0f%%，轮询间隔 %v -> %v，优先保障下单", p.name, pressure*100, old, p.current)
+	} else if p.current < old {
+		logger.Info("🐇 [%s] 接口压力 %.0f%%，轮询间隔 %v -> %v", p.name, pressure*100, old, p.current)
+	}
+	return p.current
+}
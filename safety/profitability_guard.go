@@ -0,0 +1,116 @@
+package safety
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// IGridState 盈利复核需要的网格参数（由 SuperPositionManager 实现，避免循环导入）
+type IGridState interface {
+	GetPriceInterval() float64
+	GetSellPriceInterval() float64 // 卖单相对槽位价格的间隔（每笔盈利的价格差）
+	GetFeeRate() float64
+	GetPriceDecimals() int
+	// 每单金额和买单窗口（配置重载后为新值，不读 config.Trading）
+	GetOrderQuantity() float64
+	GetBuyWindowSize() int
+}
+
+// ProfitabilityGuard 运行中盈利复核（trading.profitability_recheck）
+// 启动时的手续费盈利检查只针对启动价格；固定金额模式下价格上涨后同样的间隔利润率降低，
+// 精度调整、价格间隔变化、锚点重置也会改变每笔利润。网格参数变化或价格偏离上次复核价格超过阈值时
+// 按当前价格重新计算每笔净利润，无法覆盖手续费时暂停挂单并发出通知，恢复盈利后自动解除
+type ProfitabilityGuard struct {
+	cfg        *config.Config
+	grid       IGridState
+	quoteAsset string
+
+	mu        sync.Mutex
+	lastPrice float64 // 最近一次复核的价格
+	paused    atomic.Bool
+}
+
+// NewProfitabilityGuard 创建盈利复核器（启动价格已通过 CheckAccountSafety 检查）
+func NewProfitabilityGuard(cfg *config.Config, ex exchange.IExchange, grid IGridState, startPrice float64) *ProfitabilityGuard {
+	return &ProfitabilityGuard{
+		cfg:        cfg,
+		grid:       grid,
+		quoteAsset: ex.GetQuoteAsset(),
+		lastPrice:  startPrice,
+	}
+}
+
+// IsPaused 是否因无法覆盖手续费暂停挂单
+func (g *ProfitabilityGuard) IsPaused() bool {
+	return g.paused.Load()
+}
+
+// OnPrice 价格更新时调用：暂停期间每次复核，否则价格相对上次复核偏离超过 move_percent 时复核
+func (g *ProfitabilityGuard) OnPrice(price float64) {
+	if !g.cfg.Trading.ProfitabilityRecheck.Enabled || price <= 0 {
+		return
+	}
+	g.mu.Lock()
+	lastPrice := g.lastPrice
+	g.mu.Unlock()
+
+	if !g.paused.Load() && lastPrice > 0 &&
+		math.Abs(price-lastPrice)/lastPrice*100 < g.cfg.Trading.ProfitabilityRecheck.MovePercent {
+		return
+	}
+	g.Recheck("价格变动", price)
+}
+
+// Recheck 按指定价格和当前网格参数复核每笔净利润
+func (g *ProfitabilityGuard) Recheck(reason string, price float64) {
+	if !g.cfg.Trading.ProfitabilityRecheck.Enabled || price <= 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	lastPrice := g.lastPrice
+	g.lastPrice = price
+	interval := g.grid.GetSellPriceInterval()
+	feeRate := g.grid.GetFeeRate()
+	decimals := g.grid.GetPriceDecimals()
+	trade := EstimateTradeProfit(price, g.grid.GetOrderQuantity(), interval, feeRate)
+
+	if trade.NetProfit <= 0 {
+		if g.paused.CompareAndSwap(false, true) {
+			logger.Error("🚫 [盈利复核] %s后每笔净利润 %.4f %s ≤ 0（价格 %.*f, 间隔 %.*f, 费率 %.4f%%），撤销买单并暂停挂单，请增大 price_interval",
+				reason, trade.NetProfit, g.quoteAsset, decimals, price, decimals, interval, feeRate*100)
+			event.Publish(event.TypeProfitabilityPaused, event.ProfitabilityChanged{
+				Reason:        reason,
+				Price:         price,
+				PriceInterval: interval,
+				FeeRate:       feeRate,
+				NetProfit:     trade.NetProfit,
+			})
+		}
+		return
+	}
+
+	if g.paused.CompareAndSwap(true, false) {
+		logger.Info("✅ [盈利复核] %s后每笔净利润恢复为 %.4f %s（价格 %.*f, 间隔 %.*f），恢复挂单",
+			reason, trade.NetProfit, g.quoteAsset, decimals, price, decimals, interval)
+		event.Publish(event.TypeProfitabilityResumed, event.ProfitabilityChanged{
+			Reason:        reason,
+			Price:         price,
+			PriceInterval: interval,
+			FeeRate:       feeRate,
+			NetProfit:     trade.NetProfit,
+		})
+		return
+	}
+
+	logger.Info("✅ [盈利复核] %s (价格 %.*f -> %.*f, 间隔 %.*f): 每笔净利润 %.4f %s",
+		reason, decimals, lastPrice, decimals, price, decimals, interval, trade.NetProfit, g.quoteAsset)
+}
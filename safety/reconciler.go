@@ -6,9 +6,13 @@ import (
 	"opensqt/config"
 	"opensqt/logger"
 	"reflect"
+	"sync/atomic"
 	"time"
 )
 
+// reconcilerLog 对账组件日志器（级别可通过 system.log_levels.reconciler 单独调整）
+var reconcilerLog = logger.WithComponent("reconciler")
+
 // IExchange 定义对账所需的交易所接口方法
 type IExchange interface {
 	GetPositions(ctx context.Context, symbol string) (interface{}, error)
@@ -42,6 +46,9 @@ type IPositionManager interface {
 	// 获取配置信息
 	GetSymbol() string
 	GetPriceInterval() float64
+	GetSellPriceInterval() float64
+	// 锁定订单集合（对账比对期间阻止新增挂单），返回解锁函数
+	LockOrderSet() func()
 }
 
 // Reconciler 持仓对账器
@@ -50,6 +57,9 @@ type Reconciler struct {
 	exchange     IExchange
 	pm           IPositionManager
 	pauseChecker func() bool
+	pressure     IPressureSource // 接口压力来源（timing.adaptive_polling），nil 表示固定间隔
+	clock        IClock          // 对账调度时钟
+	mismatches   atomic.Int64    // 累计对账差异（交易所未跟踪的挂单 + 本地缺失的挂单）
 }
 
 // NewReconciler 创建对账器
@@ -58,6 +68,7 @@ func NewReconciler(cfg *config.Config, exchange IExchange, pm IPositionManager)
 		cfg:      cfg,
 		exchange: exchange,
 		pm:       pm,
+		clock:    systemClock{},
 	}
 }
 
@@ -66,29 +77,46 @@ func (r *Reconciler) SetPauseChecker(checker func() bool) {
 	r.pauseChecker = checker
 }
 
-// Start 启动对账协程
+// SetPressureSource 设置接口压力来源，压力大时拉长对账间隔（需在 Start 之前调用）
+func (r *Reconciler) SetPressureSource(source IPressureSource) {
+	r.pressure = source
+}
+
+// SetClock 设置对账调度时钟（需在 Start 之前调用）
+func (r *Reconciler) SetClock(clock IClock) {
+	r.clock = clock
+}
+
+// GetMismatchCount 累计对账差异的挂单数（交易所有但本地未跟踪 + 本地有但交易所查不到）
+func (r *Reconciler) GetMismatchCount() int64 {
+	return r.mismatches.Load()
+}
+
+// Start 启动对账协程（按截止时间调度，对账耗时不会让间隔逐次漂移）
 func (r *Reconciler) Start(ctx context.Context) {
 	go func() {
 		interval := time.Duration(r.cfg.Trading.ReconcileInterval) * time.Second
 		if interval <= 0 {
 			interval = 30 * time.Second
 		}
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
+		poll := newPollInterval(r.cfg, "持仓对账", interval, r.pressure)
+		schedule := newDeadlineSchedule("持仓对账", r.clock, poll.next())
 
 		for {
 			select {
 			case <-ctx.Done():
-				logger.Info("⏹️ 持仓对账协程已停止")
+				reconcilerLog.Info("⏹️ 持仓对账协程已停止")
 				return
-			case <-ticker.C:
+			case <-schedule.wait():
+				schedule.checkLate()
 				if err := r.Reconcile(); err != nil {
-					logger.Error("❌ [对账失败] %v", err)
+					reconcilerLog.Error("❌ [对账失败] %v", err)
 				}
+				schedule.advance(poll.next())
 			}
 		}
 	}()
-	logger.Info("✅ 持仓对账已启动 (间隔: %d秒)", r.cfg.Trading.ReconcileInterval)
+	reconcilerLog.Info("✅ 持仓对账已启动 (间隔: %d秒)", r.cfg.Trading.ReconcileInterval)
 }
 
 // Reconcile 执行对账（通用实现，支持所有交易所）
@@ -98,7 +126,7 @@ func (r *Reconciler) Reconcile() error {
 		return nil
 	}
 
-	logger.Debugln("🔍 ===== 开始持仓对账 =====")
+	reconcilerLog.Debugln("🔍 ===== 开始持仓对账 =====")
 
 	symbol := r.pm.GetSymbol()
 
@@ -109,14 +137,17 @@ func (r *Reconciler) Reconcile() error {
 	}
 
 	// 2. 查询所有挂单（使用通用接口）
+	// 从查询挂单到遍历完本地槽位为比对窗口，期间锁定订单集合，避免交易所与本地看到的订单不一致
+	unlockOrderSet := r.pm.LockOrderSet()
 	openOrdersRaw, err := r.exchange.GetOpenOrders(context.Background(), symbol)
 	if err != nil {
+		unlockOrderSet()
 		return fmt.Errorf("查询挂单失败: %w", err)
 	}
 
 	// 3. 解析持仓和挂单信息（通用处理）
-	logger.Debug("📊 交易所持仓信息类型: %T", positionsRaw)
-	logger.Debug("📊 交易所挂单信息类型: %T", openOrdersRaw)
+	reconcilerLog.Debug("📊 交易所持仓信息类型: %T", positionsRaw)
+	reconcilerLog.Debug("📊 交易所挂单信息类型: %T", openOrdersRaw)
 
 	// 4. 计算本地持仓统计
 	var localTotal float64
@@ -124,6 +155,8 @@ func (r *Reconciler) Reconcile() error {
 	var localFilledPosition float64
 	var activeBuyOrders int
 	var activeSellOrders int
+	localOrders := make(map[int64]string)          // 本地跟踪的订单ID -> 订单状态
+	localOrderCreated := make(map[int64]time.Time) // 本地跟踪的订单ID -> 下单时间
 
 	// 订单状态常量（与 position 包保持一致）
 	const (
@@ -162,6 +195,14 @@ func (r *Reconciler) Reconcile() error {
 		positionQty := getFloat64Field("PositionQty")
 		orderSide := getStringField("OrderSide")
 		orderStatus := getStringField("OrderStatus")
+		if orderID := v.FieldByName("OrderID"); orderID.IsValid() && orderID.CanInt() && orderID.Int() != 0 {
+			localOrders[orderID.Int()] = orderStatus
+			if field := v.FieldByName("OrderCreatedAt"); field.IsValid() && field.CanInterface() {
+				if createdAt, ok := field.Interface().(time.Time); ok {
+					localOrderCreated[orderID.Int()] = createdAt
+				}
+			}
+		}
 
 		if positionStatus == PositionStatusFilled {
 			localFilledPosition += positionQty
@@ -179,27 +220,79 @@ func (r *Reconciler) Reconcile() error {
 
 		return true
 	})
+	unlockOrderSet()
 
 	localTotal = localFilledPosition
 
-	logger.Debug("📊 [对账统计] 本地持仓: %.4f, 挂单卖单: %d 个 (%.4f), 挂单买单: %d 个",
+	// 比对交易所挂单与本地槽位
+	// 刚下的订单在 REST 最终一致的交易所上可能短时间查不到，宽限期内不计为缺失
+	exchangeOrders := extractOrderIDs(openOrdersRaw)
+	grace := time.Duration(r.cfg.Trading.ReconcileGracePeriod) * time.Second
+	var untracked, missing, justPlaced int
+	for orderID := range exchangeOrders {
+		if _, ok := localOrders[orderID]; !ok {
+			untracked++
+		}
+	}
+	for orderID, status := range localOrders {
+		if status == OrderStatusPlaced || status == OrderStatusConfirmed || status == OrderStatusPartiallyFilled {
+			if exchangeOrders[orderID] {
+				continue
+			}
+			if createdAt := localOrderCreated[orderID]; !createdAt.IsZero() && time.Since(createdAt) < grace {
+				justPlaced++
+				continue
+			}
+			missing++
+		}
+	}
+	r.mismatches.Add(int64(untracked + missing))
+	if untracked > 0 {
+		reconcilerLog.Warn("⚠️ [对账差异] 交易所有 %d 个挂单未被本地槽位跟踪", untracked)
+	}
+	if missing > 0 {
+		reconcilerLog.Info("ℹ️ [对账差异] 本地有 %d 个挂单不在交易所挂单列表中（可能已成交，推送尚未到达）", missing)
+	}
+	if justPlaced > 0 {
+		reconcilerLog.Debug("⏳ [对账] %d 个刚下的挂单尚未出现在交易所挂单列表中（宽限期 %v 内不计为缺失）", justPlaced, grace)
+	}
+
+	reconcilerLog.Debug("📊 [对账统计] 本地持仓: %.4f, 挂单卖单: %d 个 (%.4f), 挂单买单: %d 个",
 		localTotal, activeSellOrders, localPendingSellQty, activeBuyOrders)
 
 	r.pm.IncrementReconcileCount()
 
 	// 5. 输出对账统计（从交易所接口获取基础币种，支持U本位和币本位合约）
 	baseCurrency := r.exchange.GetBaseAsset()
-	logger.Info("✅ [对账完成] 本地持仓: %.4f %s, 挂单卖单: %d 个 (%.4f), 挂单买单: %d 个",
+	reconcilerLog.Info("✅ [对账完成] 本地持仓: %.4f %s, 挂单卖单: %d 个 (%.4f), 挂单买单: %d 个",
 		localTotal, baseCurrency, activeSellOrders, localPendingSellQty, activeBuyOrders)
 
 	r.pm.UpdateLastReconcileTime(time.Now())
 
 	totalBuyQty := r.pm.GetTotalBuyQty()
 	totalSellQty := r.pm.GetTotalSellQty()
-	priceInterval := r.pm.GetPriceInterval()
-	estimatedProfit := totalSellQty * priceInterval
-	logger.Info("📊 [统计] 对账次数: %d, 累计买入: %.2f, 累计卖出: %.2f, 预计盈利: %.2f U",
+	estimatedProfit := totalSellQty * r.pm.GetSellPriceInterval()
+	reconcilerLog.Info("📊 [统计] 对账次数: %d, 累计买入: %.2f, 累计卖出: %.2f, 预计盈利: %.2f U",
 		r.pm.GetReconcileCount(), totalBuyQty, totalSellQty, estimatedProfit)
-	logger.Debugln("🔍 ===== 对账完成 =====")
+	reconcilerLog.Debugln("🔍 ===== 对账完成 =====")
 	return nil
 }
+
+// extractOrderIDs 从交易所挂单列表（[]*Order 等切片）中提取订单ID
+func extractOrderIDs(ordersRaw interface{}) map[int64]bool {
+	ids := make(map[int64]bool)
+	v := reflect.ValueOf(ordersRaw)
+	if v.Kind() != reflect.Slice {
+		return ids
+	}
+	for i := 0; i < v.Len(); i++ {
+		item := reflect.Indirect(v.Index(i))
+		if item.Kind() != reflect.Struct {
+			continue
+		}
+		if field := item.FieldByName("OrderID"); field.IsValid() && field.CanInt() {
+			ids[field.Int()] = true
+		}
+	}
+	return ids
+}
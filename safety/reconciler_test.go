@@ -0,0 +1,208 @@
+package safety
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"opensqt/config"
+	"opensqt/position"
+)
+
+// gridExchange 测试用交易所：挂单由 gridExecutor 写入（下单请求到达交易所即可查到）
+type gridExchange struct {
+	mu          sync.Mutex
+	orders      []*position.Order
+	orderQuery  atomic.Int32  // GetOpenOrders 调用次数
+	queryGate   chan struct{} // 非 nil 时下一次 GetOpenOrders 查到挂单后等待关闭（模拟对账比对窗口）
+	queryInside chan struct{} // 该次 GetOpenOrders 进入比对窗口时关闭
+}
+
+func (f *gridExchange) GetName() string { return "Fake" }
+
+func (f *gridExchange) GetPositions(ctx context.Context, symbol string) (interface{}, error) {
+	return []*position.PositionInfo{}, nil
+}
+
+func (f *gridExchange) GetOpenOrders(ctx context.Context, symbol string) (interface{}, error) {
+	f.orderQuery.Add(1)
+	f.mu.Lock()
+	orders := append([]*position.Order(nil), f.orders...)
+	gate, inside := f.queryGate, f.queryInside
+	f.queryGate, f.queryInside = nil, nil
+	f.mu.Unlock()
+	if gate != nil {
+		close(inside)
+		<-gate
+	}
+	return orders, nil
+}
+
+func (f *gridExchange) GetOrder(ctx context.Context, symbol string, orderID int64) (interface{}, error) {
+	return nil, nil
+}
+
+func (f *gridExchange) GetBaseAsset() string { return "ETH" }
+
+func (f *gridExchange) GetMinNotional() float64 { return 0 }
+
+func (f *gridExchange) CancelAllOrders(ctx context.Context, symbol string) error { return nil }
+
+func (f *gridExchange) GetMarginInfo(ctx context.Context, symbol string) (float64, int, error) {
+	return 0, 10, nil
+}
+
+func (f *gridExchange) GetMarginBalance(ctx context.Context, symbol string) (float64, float64, int, error) {
+	return 0, 0, 10, nil
+}
+
+// gridExecutor 测试用执行器：订单写入交易所后，下单请求在 release 关闭前不返回（模拟进行中的下单）
+type gridExecutor struct {
+	ex      *gridExchange
+	nextID  atomic.Int64
+	sent    chan struct{} // 第一笔订单写入交易所时关闭
+	once    sync.Once
+	release chan struct{}
+}
+
+func (e *gridExecutor) PlaceOrder(req *position.OrderRequest) (*position.Order, error) {
+	ord := &position.Order{OrderID: e.nextID.Add(1), ClientOrderID: req.ClientOrderID, Symbol: req.Symbol,
+		Side: req.Side, Price: req.Price, Quantity: req.Quantity, Status: "NEW", CreatedAt: time.Now()}
+	e.ex.mu.Lock()
+	e.ex.orders = append(e.ex.orders, ord)
+	e.ex.mu.Unlock()
+	e.once.Do(func() { close(e.sent) })
+	if e.release != nil {
+		<-e.release
+	}
+	return ord, nil
+}
+
+func (e *gridExecutor) BatchPlaceOrders(orders []*position.OrderRequest) ([]*position.Order, bool) {
+	placed := make([]*position.Order, 0, len(orders))
+	for _, req := range orders {
+		ord, _ := e.PlaceOrder(req)
+		placed = append(placed, ord)
+	}
+	return placed, false
+}
+
+func (e *gridExecutor) BatchCancelOrders(orderIDs []int64, reason string) error { return nil }
+
+// newReconcileGrid 已初始化（锚点100）的仓位管理器和使用同一交易所的对账器
+func newReconcileGrid(t *testing.T, guard bool) (*position.SuperPositionManager, *Reconciler, *gridExchange, *gridExecutor) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.App.CurrentExchange = "mock"
+	cfg.Exchanges = map[string]config.ExchangeConfig{"mock": {}}
+	cfg.Trading.Symbol = "ETHUSDT"
+	cfg.Trading.PriceInterval = 1
+	cfg.Trading.OrderQuantity = 10
+	cfg.Trading.BuyWindowSize = 3
+	cfg.Trading.SellWindowSize = 3
+	cfg.Trading.ReconcileGuard = guard
+
+	ex := &gridExchange{}
+	executor := &gridExecutor{ex: ex, sent: make(chan struct{})}
+	spm := position.NewSuperPositionManager(cfg, executor, ex, 2, 4)
+	if err := spm.Initialize(100, "100"); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	return spm, NewReconciler(cfg, ex, spm), ex, executor
+}
+
+// reconcileDuringPlacement 下单请求已到达交易所、尚未返回时开始对账，返回对账发现的差异数
+func reconcileDuringPlacement(t *testing.T, guard bool) int64 {
+	t.Helper()
+	spm, reconciler, ex, executor := newReconcileGrid(t, guard)
+	executor.release = make(chan struct{})
+
+	adjusted := make(chan error, 1)
+	go func() { adjusted <- spm.AdjustOrders(100.4) }()
+	select {
+	case <-executor.sent:
+	case <-time.After(time.Second):
+		t.Fatal("下单请求未发出")
+	}
+
+	reconciled := make(chan error, 1)
+	queries := ex.orderQuery.Load()
+	go func() { reconciled <- reconciler.Reconcile() }()
+	if guard {
+		// 对账等待进行中的调整结束后才读取交易所挂单
+		time.Sleep(50 * time.Millisecond)
+		if ex.orderQuery.Load() != queries {
+			t.Fatal("启用 reconcile_guard 时对账不应在下单进行中读取挂单")
+		}
+	} else if err := <-reconciled; err != nil {
+		t.Fatalf("对账失败: %v", err)
+	}
+	close(executor.release)
+
+	waits := []chan error{adjusted}
+	if guard {
+		waits = append(waits, reconciled)
+	}
+	for _, ch := range waits {
+		select {
+		case err := <-ch:
+			if err != nil {
+				t.Fatalf("调整订单或对账失败: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("调整订单或对账未结束")
+		}
+	}
+	return reconciler.GetMismatchCount()
+}
+
+func TestReconcileDuringInflightPlacement(t *testing.T) {
+	t.Run("未启用 reconcile_guard：进行中的下单计为未跟踪挂单", func(t *testing.T) {
+		if got := reconcileDuringPlacement(t, false); got == 0 {
+			t.Fatal("下单请求已到达交易所而本地尚未记录订单ID时，未加锁的对账应看到未跟踪的挂单")
+		}
+	})
+	t.Run("启用 reconcile_guard：对账等待下单返回，无差异", func(t *testing.T) {
+		if got := reconcileDuringPlacement(t, true); got != 0 {
+			t.Fatalf("对账与下单交错时不应产生差异，实际 %d", got)
+		}
+	})
+}
+
+func TestAdjustOrdersSkipsDuringReconcile(t *testing.T) {
+	spm, reconciler, ex, executor := newReconcileGrid(t, true)
+	gate, inside := make(chan struct{}), make(chan struct{})
+	ex.queryGate, ex.queryInside = gate, inside
+
+	reconciled := make(chan error, 1)
+	go func() { reconciled <- reconciler.Reconcile() }()
+	<-inside
+
+	// 对账比对期间的价格变化跳过调整，不挂新单
+	if err := spm.AdjustOrders(100.4); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	if n := executor.nextID.Load(); n != 0 {
+		t.Fatalf("对账比对期间不应挂单，实际挂出 %d 个", n)
+	}
+	close(gate)
+	if err := <-reconciled; err != nil {
+		t.Fatalf("对账失败: %v", err)
+	}
+
+	// 对账结束后的调整照常挂单，之后的对账无差异
+	if err := spm.AdjustOrders(100.4); err != nil {
+		t.Fatalf("调整订单失败: %v", err)
+	}
+	if executor.nextID.Load() == 0 {
+		t.Fatal("对账结束后应恢复挂单")
+	}
+	if err := reconciler.Reconcile(); err != nil {
+		t.Fatalf("对账失败: %v", err)
+	}
+	if got := reconciler.GetMismatchCount(); got != 0 {
+		t.Fatalf("挂单与本地槽位一致时不应有差异，实际 %d", got)
+	}
+}
@@ -0,0 +1,106 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// reduceOnlyProbeMarkup 验证单挂在当前价格之上的比例（足够远不会成交，又在交易所限价范围内）
+const reduceOnlyProbeMarkup = 1.03
+
+// CheckReduceOnly 启动时确认只减仓标记确实生效（safety.verify_reduce_only）
+// 网格卖单和平仓单都依赖只减仓：标记被适配器静默丢弃时，卖单会在没有多仓时开出空仓。
+// 先检查适配器是否声明传递只减仓标记（未声明直接失败）；probe 为 true 且当前没有多仓时，
+// 再在当前价格上方挂一个最小数量的只减仓限价卖单：交易所拒绝说明标记生效，被接受则立即撤销并返回失败。
+// 检查失败时返回 *SafetyCheckError，无法确认（下单因其他原因失败）时返回普通错误
+func CheckReduceOnly(ctx context.Context, ex exchange.IExchange, symbol string, currentPrice float64, priceDecimals int, probe bool) error {
+	if !exchange.SupportsReduceOnly(ex) {
+		return &SafetyCheckError{
+			Reason:  ReasonReduceOnlyUnsupported,
+			Message: fmt.Sprintf("交易所 %s 的适配器未声明传递只减仓标记，卖单可能开出空仓", ex.GetName()),
+		}
+	}
+	logger.Info("✅ [只减仓] 交易所 %s 的适配器声明传递只减仓标记", ex.GetName())
+	if !probe {
+		return nil
+	}
+
+	positions, err := ex.GetPositions(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("只减仓验证查询持仓失败: %w", err)
+	}
+	for _, p := range positions {
+		if p.Symbol == symbol && p.Size > 0 {
+			// 有多仓时只减仓卖单本来就会被接受，无法据此判断
+			logger.Info("ℹ️ [只减仓] 当前持有 %s 多仓 %.4f，跳过下单验证", symbol, p.Size)
+			return nil
+		}
+	}
+
+	factor := math.Pow(10, float64(priceDecimals))
+	price := math.Ceil(currentPrice*reduceOnlyProbeMarkup*factor) / factor
+	quantity := reduceOnlyProbeQuantity(ex, price)
+	ord, err := ex.PlaceOrder(ctx, &exchange.OrderRequest{
+		Symbol:        symbol,
+		Side:          exchange.SideSell,
+		Type:          exchange.OrderTypeLimit,
+		TimeInForce:   exchange.TimeInForceGTC,
+		Quantity:      quantity,
+		Price:         price,
+		ReduceOnly:    true,
+		PriceDecimals: priceDecimals,
+	})
+	if err != nil {
+		if isReduceOnlyRejection(err) {
+			logger.Info("✅ [只减仓] 无持仓的只减仓卖单被交易所拒绝，只减仓标记生效: %v", err)
+			return nil
+		}
+		return fmt.Errorf("只减仓验证下单失败，无法确认只减仓是否生效: %w", err)
+	}
+
+	// 部分交易所先接受订单再异步撤销，稍等后确认订单状态
+	time.Sleep(time.Second)
+	if latest, err := ex.GetOrder(ctx, symbol, ord.OrderID); err == nil {
+		switch latest.Status {
+		case exchange.OrderStatusCanceled, exchange.OrderStatusExpired, exchange.OrderStatusRejected:
+			logger.Info("✅ [只减仓] 无持仓的只减仓卖单已被交易所撤销 (状态: %s)，只减仓标记生效", latest.Status)
+			return nil
+		}
+	}
+	if err := ex.CancelOrder(ctx, symbol, ord.OrderID); err != nil {
+		logger.Error("❌ [只减仓] 撤销验证卖单失败，请手动撤销 (订单ID: %d): %v", ord.OrderID, err)
+	}
+	return &SafetyCheckError{
+		Reason: ReasonReduceOnlyIgnored,
+		Message: fmt.Sprintf("无持仓时只减仓卖单 %.*f × %g 被交易所 %s 接受，只减仓标记未生效，卖单可能开出空仓",
+			priceDecimals, price, quantity, ex.GetName()),
+	}
+}
+
+// reduceOnlyProbeQuantity 验证单数量：满足最小下单金额的最小数量，避免因下单金额不足被拒绝而误判
+func reduceOnlyProbeQuantity(ex exchange.IExchange, price float64) float64 {
+	factor := math.Pow(10, float64(ex.GetQuantityDecimals()))
+	quantity := 1 / factor
+	if minNotional := ex.GetMinNotional(); minNotional > 0 && price > 0 {
+		quantity = math.Max(quantity, math.Ceil(minNotional/price*factor)/factor)
+	}
+	return quantity
+}
+
+// isReduceOnlyRejection 下单错误是否为"无可减仓位"类拒绝
+// Binance: -2022 ReduceOnly Order is rejected；Gate.io: REDUCE_ONLY_FAIL；Bitget: 22002 No position to close
+func isReduceOnlyRejection(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, keyword := range []string{"reduce", "-2022", "22002", "no position", "position not exist"} {
+		if strings.Contains(msg, keyword) {
+			return true
+		}
+	}
+	return false
+}
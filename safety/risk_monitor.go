@@ -4,13 +4,18 @@ import (
 	"context"
 	"fmt"
 	"opensqt/config"
+	"opensqt/event"
 	"opensqt/exchange"
 	"opensqt/logger"
+	"opensqt/notify"
 	"strings"
 	"sync"
 	"time"
 )
 
+// riskLog 风控组件日志器（级别可通过 system.log_levels.risk 单独调整）
+var riskLog = logger.WithComponent("risk")
+
 // SymbolData 单个币种的K线数据缓存
 type SymbolData struct {
 	candles []*exchange.Candle
@@ -22,47 +27,172 @@ type RiskMonitor struct {
 	cfg           *config.Config
 	exchange      exchange.IExchange
 	symbolDataMap map[string]*SymbolData
+	klineSymbols  map[string]string // 交易所K线交易对名称 -> 监控币种（risk_control.symbol_aliases）
 	mu            sync.RWMutex
 	triggered     bool
 	lastMsg       string
+	notifier      notify.INotifier // 风控触发/解除告警（nil 表示不推送）
 }
 
 // NewRiskMonitor 创建风控监视器
 func NewRiskMonitor(cfg *config.Config, ex exchange.IExchange) *RiskMonitor {
 	symbolDataMap := make(map[string]*SymbolData)
+	klineSymbols := make(map[string]string)
 	for _, symbol := range cfg.RiskControl.MonitorSymbols {
 		symbolDataMap[symbol] = &SymbolData{
 			candles: make([]*exchange.Candle, 0, cfg.RiskControl.AverageWindow+1),
 		}
+		if native := cfg.RiskControl.SymbolAliases[symbol]; native != "" {
+			klineSymbols[native] = symbol
+		}
 	}
 
 	return &RiskMonitor{
 		cfg:           cfg,
 		exchange:      ex,
 		symbolDataMap: symbolDataMap,
+		klineSymbols:  klineSymbols,
+	}
+}
+
+// SetNotifier 设置风控触发/解除的告警推送（需在 Start 之前调用）
+func (r *RiskMonitor) SetNotifier(n notify.INotifier) {
+	r.notifier = n
+}
+
+// klineSymbol 监控币种在交易所K线接口使用的交易对名称（未配置别名时为原名称）
+func (r *RiskMonitor) klineSymbol(symbol string) string {
+	if native := r.cfg.RiskControl.SymbolAliases[symbol]; native != "" {
+		return native
+	}
+	return symbol
+}
+
+// monitorSymbol 将K线推送中的交易对名称还原为监控币种
+// 依次匹配监控币种、别名，最后忽略大小写和分隔符（交易所推送 BTC_USDT / BTC-USDT 等格式时）
+func (r *RiskMonitor) monitorSymbol(name string) (string, bool) {
+	if _, ok := r.symbolDataMap[name]; ok {
+		return name, true
+	}
+	if symbol, ok := r.klineSymbols[name]; ok {
+		return symbol, true
+	}
+	normalized := normalizeSymbol(name)
+	for _, symbol := range r.cfg.RiskControl.MonitorSymbols {
+		if normalizeSymbol(symbol) == normalized || normalizeSymbol(r.klineSymbol(symbol)) == normalized {
+			return symbol, true
+		}
+	}
+	return "", false
+}
+
+// symbolWeight 币种异常信号的权重（交易对为 traded_symbol_weight，其他币种为1）
+func (r *RiskMonitor) symbolWeight(symbol string) float64 {
+	if symbol == r.cfg.Trading.Symbol {
+		return r.cfg.RiskControl.TradedSymbolWeight
+	}
+	return 1
+}
+
+// totalWeight 全部监控币种的权重之和
+func (r *RiskMonitor) totalWeight() float64 {
+	var total float64
+	for _, symbol := range r.cfg.RiskControl.MonitorSymbols {
+		total += r.symbolWeight(symbol)
 	}
+	return total
+}
+
+// triggerScore 触发所需的加权异常分数（未配置时为总权重，即全部币种异常）
+func (r *RiskMonitor) triggerScore() float64 {
+	if score := r.cfg.RiskControl.TriggerScore; score > 0 {
+		return score
+	}
+	return r.totalWeight()
+}
+
+// tradedWeighted 交易对是否在监控币种中且权重高于其他币种
+func (r *RiskMonitor) tradedWeighted() bool {
+	if r.cfg.RiskControl.TradedSymbolWeight <= 1 {
+		return false
+	}
+	_, ok := r.symbolDataMap[r.cfg.Trading.Symbol]
+	return ok
+}
+
+// normalizeSymbol 去除分隔符并转为大写，用于比较不同格式的交易对名称
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.NewReplacer("_", "", "-", "", "/", "").Replace(symbol))
 }
 
 // Start 启动监控
 func (r *RiskMonitor) Start(ctx context.Context) {
 	if !r.cfg.RiskControl.Enabled {
-		logger.Info("⚠️ 主动安全风控未启用")
+		riskLog.Info("⚠️ 主动安全风控未启用")
 		return
 	}
 
-	logger.Info("🛡️ 启动主动安全风控监控 (周期: %s, 倍数: %.1f, 窗口: %d)",
+	riskLog.Info("🛡️ 启动主动安全风控监控 (周期: %s, 倍数: %.1f, 窗口: %d)",
 		r.cfg.RiskControl.Interval, r.cfg.RiskControl.VolumeMultiplier, r.cfg.RiskControl.AverageWindow)
-	logger.Info("🛡️ 监控币种: %v (恢复阈值: %d/%d)", r.cfg.RiskControl.MonitorSymbols,
+	riskLog.Info("🛡️ 监控币种: %v (恢复阈值: %d/%d)", r.cfg.RiskControl.MonitorSymbols,
 		r.cfg.RiskControl.RecoveryThreshold, len(r.cfg.RiskControl.MonitorSymbols))
+	if _, ok := r.symbolDataMap[r.cfg.Trading.Symbol]; ok {
+		riskLog.Info("🛡️ 信号权重: 交易对 %s %.2f，其他币种 1，加权异常分数达到 %.2f/%.2f 时触发",
+			r.cfg.Trading.Symbol, r.cfg.RiskControl.TradedSymbolWeight, r.triggerScore(), r.totalWeight())
+		if r.tradedWeighted() {
+			riskLog.Info("🛡️ 交易对权重高于其他币种，解除风控还要求 %s 本身已恢复", r.cfg.Trading.Symbol)
+		}
+	} else if r.cfg.RiskControl.TriggerScore > 0 {
+		riskLog.Info("🛡️ 信号权重: 各币种均为 1，异常币种达到 %.0f/%d 个时触发",
+			r.triggerScore(), len(r.cfg.RiskControl.MonitorSymbols))
+	}
 
-	// 预加载历史K线数据
-	logger.Info("📊 正在加载历史K线数据...")
+	if path := r.cfg.RiskControl.RecordFile; path != "" {
+		go r.recordEvaluations(ctx, path)
+	}
+
+	// 均线状态文件中仍有效的币种直接恢复，其余币种通过 REST 回填
+	var restored map[string][]*exchange.Candle
+	if path := r.cfg.RiskControl.StateFile; path != "" {
+		restored = r.loadState(path)
+	}
+
+	// 预加载历史K线数据（同时确认各监控币种在交易所存在）
+	riskLog.Info("📊 正在加载历史K线数据...")
+	klineSymbols := make([]string, 0, len(r.cfg.RiskControl.MonitorSymbols))
+	var missing []string
 	for _, symbol := range r.cfg.RiskControl.MonitorSymbols {
-		candles, err := r.exchange.GetHistoricalKlines(ctx, symbol, r.cfg.RiskControl.Interval, r.cfg.RiskControl.AverageWindow+1)
+		native := r.klineSymbol(symbol)
+		klineSymbols = append(klineSymbols, native)
+		if candles, ok := restored[symbol]; ok {
+			symbolData := r.symbolDataMap[symbol]
+			symbolData.mu.Lock()
+			symbolData.candles = candles
+			symbolData.mu.Unlock()
+			riskLog.Info("✅ %s: 已从均线状态恢复 %d 根K线", symbol, len(candles))
+			continue
+		}
+		label := symbol
+		if native != symbol {
+			label = fmt.Sprintf("%s (交易所名称 %s)", symbol, native)
+		}
+
+		candles, err := r.exchange.GetHistoricalKlines(ctx, native, r.cfg.RiskControl.Interval, r.cfg.RiskControl.AverageWindow+1)
 		if err != nil {
-			logger.Warn("⚠️ 加载 %s 历史K线失败: %v", symbol, err)
+			riskLog.Warn("⚠️ 加载 %s 历史K线失败，请确认交易所 %s 上存在该交易对（名称不同时配置 risk_control.symbol_aliases）: %v",
+				label, r.exchange.GetName(), err)
+			missing = append(missing, symbol)
 			continue
 		}
+		if len(candles) == 0 {
+			riskLog.Warn("⚠️ %s 没有返回历史K线，请确认交易所 %s 上存在该交易对（名称不同时配置 risk_control.symbol_aliases）",
+				label, r.exchange.GetName())
+			missing = append(missing, symbol)
+			continue
+		}
+		for _, c := range candles {
+			c.Symbol = symbol
+		}
 
 		if len(candles) > 0 {
 			r.mu.Lock()
@@ -73,39 +203,53 @@ func (r *RiskMonitor) Start(ctx context.Context) {
 				symbolData.mu.Lock()
 				symbolData.candles = candles
 				symbolData.mu.Unlock()
-				logger.Info("✅ %s: 已加载 %d 根历史K线", symbol, len(candles))
+				riskLog.Info("✅ %s: 已加载 %d 根历史K线", symbol, len(candles))
 			}
 		}
 	}
-	logger.Info("✅ 历史K线数据加载完成，风控系统已就绪")
+	if len(missing) > 0 {
+		// 触发需要全部币种同时异常，没有数据的币种会让风控无法触发
+		riskLog.Warn("⚠️ %d/%d 个监控币种没有K线数据: %v，这些币种恢复数据前风控无法触发",
+			len(missing), len(r.cfg.RiskControl.MonitorSymbols), missing)
+	}
+	riskLog.Info("✅ 历史K线数据加载完成，风控系统已就绪")
 
 	// 启动K线流
-	if err := r.exchange.StartKlineStream(ctx, r.cfg.RiskControl.MonitorSymbols, r.cfg.RiskControl.Interval, r.onCandleUpdate); err != nil {
-		logger.Error("❌ 启动K线流失败: %v", err)
+	if err := r.exchange.StartKlineStream(ctx, klineSymbols, r.cfg.RiskControl.Interval, r.onCandleUpdate); err != nil {
+		riskLog.Error("❌ 启动K线流失败: %v", err)
 		return
 	}
 
 	// 启动定期报告协程（每60秒）
 	go r.reportLoop(ctx)
+	if path := r.cfg.RiskControl.StateFile; path != "" {
+		go r.persistState(ctx, path)
+	}
 }
 
 // onCandleUpdate K线更新回调（实时检测）
 func (r *RiskMonitor) onCandleUpdate(candle *exchange.Candle) {
 	if candle == nil {
-		logger.Warn("⚠️ 收到空K线数据")
+		riskLog.Warn("⚠️ 收到空K线数据")
 		return
 	}
 	c := candle
 
-	// 更新缓存
-	r.mu.RLock()
-	symbolData, exists := r.symbolDataMap[c.Symbol]
-	r.mu.RUnlock()
-
-	if !exists {
-		logger.Warn("⚠️ 收到未监控的币种K线: %s", c.Symbol)
+	// 更新缓存（K线推送中的交易所名称还原为监控币种）
+	symbol, monitored := r.monitorSymbol(c.Symbol)
+	if !monitored {
+		riskLog.Warn("⚠️ 收到未监控的币种K线: %s", c.Symbol)
 		return
 	}
+	if symbol != c.Symbol {
+		copied := *c
+		copied.Symbol = symbol
+		c = &copied
+	}
+
+	r.mu.RLock()
+	symbolData := r.symbolDataMap[symbol]
+	r.mu.RUnlock()
 
 	symbolData.mu.Lock()
 
@@ -155,16 +299,17 @@ func (r *RiskMonitor) onCandleUpdate(candle *exchange.Candle) {
 
 	// 只在完结K线时打印日志，避免日志过多
 	if c.IsClosed {
-		logger.Debug("📈 [K线收集] %s: 价格=%.4f, 成交量=%.0f, 完结=%v, 已缓存%d根",
+		riskLog.Debug("📈 [K线收集] %s: 价格=%.4f, 成交量=%.0f, 完结=%v, 已缓存%d根",
 			c.Symbol, c.Close, c.Volume, c.IsClosed, currentCount)
 	}
 
-	// 实时检测（使用最新数据，包括未完结的K线）
-	r.checkMarket()
+	// 实时检测（使用最新数据，包括未完结的K线）；K线完结时记录本次评估
+	r.checkMarket(c.IsClosed)
 }
 
 // checkMarket 执行市场检查（实时，无日志）
-func (r *RiskMonitor) checkMarket() {
+// record 为 true 或风控状态切换时发布 risk_evaluation 评估记录
+func (r *RiskMonitor) checkMarket(record bool) {
 	// 先检查当前状态（不持有锁）
 	r.mu.RLock()
 	triggered := r.triggered
@@ -172,7 +317,7 @@ func (r *RiskMonitor) checkMarket() {
 
 	if triggered {
 		// 已触发状态：检查是否可以解除
-		canRecover, details := r.checkRecovery()
+		canRecover, details, metrics := r.checkRecovery()
 
 		r.mu.Lock()
 		if canRecover {
@@ -183,68 +328,143 @@ func (r *RiskMonitor) checkMarket() {
 					recoveredCount++
 				}
 			}
-			logger.Info("✅ 市场风险信号消失，解除风控限制。(%d/%d 币种已恢复正常，达到恢复阈值 %d)",
+			riskLog.Info("✅ 市场风险信号消失，解除风控限制。(%d/%d 币种已恢复正常，达到恢复阈值 %d)",
 				recoveredCount, len(r.cfg.RiskControl.MonitorSymbols), r.cfg.RiskControl.RecoveryThreshold)
-			logger.Info("详情: %s", strings.Join(details, ", "))
+			riskLog.Info("详情: %s", strings.Join(details, ", "))
 			r.triggered = false
 			r.lastMsg = "已恢复正常"
+			event.Publish(event.TypeRiskRecovered, event.RiskRecovered{
+				RecoveredCount: recoveredCount,
+				TotalSymbols:   len(r.cfg.RiskControl.MonitorSymbols),
+				Details:        details,
+			})
+			notify.Send(r.notifier, notify.Event{
+				Type:    notify.EventRiskRecovered,
+				Message: "✅ 主动风控解除，恢复交易",
+				Fields: map[string]interface{}{
+					"recovered": fmt.Sprintf("%d/%d", recoveredCount, len(r.cfg.RiskControl.MonitorSymbols)),
+					"details":   strings.Join(details, ", "),
+				},
+			})
+			r.publishEvaluation("recovery", "recovered", metrics)
 		} else {
 			r.lastMsg = fmt.Sprintf("风控中，等待恢复: %s", strings.Join(details, ","))
+			if record {
+				r.publishEvaluation("recovery", "holding", metrics)
+			}
 		}
 		r.mu.Unlock()
 	} else {
 		// 未触发状态：检查是否需要触发
 		panicCount := 0
+		var panicScore float64
 		details := []string{}
+		metrics := make([]event.RiskSymbolMetric, 0, len(r.cfg.RiskControl.MonitorSymbols))
 
 		for _, symbol := range r.cfg.RiskControl.MonitorSymbols {
-			isPanic, reason := r.checkSymbol(symbol)
+			isPanic, reason, metric := r.checkSymbol(symbol)
+			metrics = append(metrics, metric)
 			if isPanic {
 				panicCount++
+				panicScore += r.symbolWeight(symbol)
 				details = append(details, fmt.Sprintf("%s(%s)", symbol, reason))
 			}
 		}
 
-		// 全部币种都出现异常时才触发
+		// 异常币种的加权分数达到触发分数时触发（默认需要全部币种都出现异常）
 		r.mu.Lock()
-		if panicCount > 0 && panicCount >= len(r.cfg.RiskControl.MonitorSymbols) {
-			logger.Warn("🚨🚨🚨 触发主动安全风控！市场出现集体异动！🚨🚨🚨")
-			logger.Warn("详情: %s", strings.Join(details, ", "))
+		if panicCount > 0 && panicScore >= r.triggerScore() {
+			if panicCount >= len(r.cfg.RiskControl.MonitorSymbols) {
+				riskLog.Warn("🚨🚨🚨 触发主动安全风控！市场出现集体异动！🚨🚨🚨")
+			} else {
+				riskLog.Warn("🚨🚨🚨 触发主动安全风控！%d/%d 币种异常，加权异常分数 %.2f 达到触发分数 %.2f 🚨🚨🚨",
+					panicCount, len(r.cfg.RiskControl.MonitorSymbols), panicScore, r.triggerScore())
+			}
+			riskLog.Warn("详情: %s", strings.Join(details, ", "))
 			r.triggered = true
 			r.lastMsg = fmt.Sprintf("触发风控: %d/%d 币种异常 (%s)", panicCount, len(r.cfg.RiskControl.MonitorSymbols), strings.Join(details, ","))
+			event.Publish(event.TypeRiskTriggered, event.RiskTriggered{
+				PanicCount:   panicCount,
+				TotalSymbols: len(r.cfg.RiskControl.MonitorSymbols),
+				Details:      details,
+			})
+			notify.Send(r.notifier, notify.Event{
+				Type:    notify.EventRiskTriggered,
+				Message: "🚨 主动风控触发，暂停交易",
+				Fields: map[string]interface{}{
+					"panic":   fmt.Sprintf("%d/%d", panicCount, len(r.cfg.RiskControl.MonitorSymbols)),
+					"details": strings.Join(details, ", "),
+				},
+			})
+			r.publishEvaluation("trigger", "triggered", metrics)
 		} else {
 			r.lastMsg = "监控正常"
+			if record {
+				r.publishEvaluation("trigger", "normal", metrics)
+			}
 		}
 		r.mu.Unlock()
 	}
 }
 
+// publishEvaluation 发布一次评估记录（调用方持有 r.mu）
+func (r *RiskMonitor) publishEvaluation(mode, decision string, metrics []event.RiskSymbolMetric) {
+	abnormal := 0
+	for _, m := range metrics {
+		if m.Abnormal {
+			abnormal++
+		}
+	}
+	event.Publish(event.TypeRiskEvaluation, event.RiskEvaluation{
+		Mode:              mode,
+		Decision:          decision,
+		AbnormalCount:     abnormal,
+		TotalSymbols:      len(r.cfg.RiskControl.MonitorSymbols),
+		RecoveryThreshold: r.cfg.RiskControl.RecoveryThreshold,
+		VolumeMultiplier:  r.cfg.RiskControl.VolumeMultiplier,
+		AverageWindow:     r.cfg.RiskControl.AverageWindow,
+		Symbols:           metrics,
+	})
+}
+
 // checkRecovery 检查是否可以解除风控（价格回到均线上方 + 成交量恢复正常）
-func (r *RiskMonitor) checkRecovery() (bool, []string) {
+func (r *RiskMonitor) checkRecovery() (bool, []string, []event.RiskSymbolMetric) {
 	recoveredCount := 0
+	tradedRecovered := true
 	details := []string{}
+	metrics := make([]event.RiskSymbolMetric, 0, len(r.cfg.RiskControl.MonitorSymbols))
 
 	for _, symbol := range r.cfg.RiskControl.MonitorSymbols {
-		isRecovered, reason := r.checkSymbolRecovery(symbol)
+		isRecovered, reason, metric := r.checkSymbolRecovery(symbol)
+		metrics = append(metrics, metric)
 		if isRecovered {
 			recoveredCount++
 			details = append(details, fmt.Sprintf("%s(%s)", symbol, reason))
 		} else {
 			details = append(details, fmt.Sprintf("%s(未恢复:%s)", symbol, reason))
+			if symbol == r.cfg.Trading.Symbol {
+				tradedRecovered = false
+			}
 		}
 	}
 
-	// 达到恢复阈值即可解除风控
+	// 达到恢复阈值即可解除风控（交易对加权时还要求交易对本身已恢复）
 	threshold := r.cfg.RiskControl.RecoveryThreshold
-	return recoveredCount >= threshold, details
+	if r.tradedWeighted() && !tradedRecovered {
+		return false, details, metrics
+	}
+	return recoveredCount >= threshold, details, metrics
 }
 
 // checkSymbolRecovery 检查单个币种是否恢复（价格>均价 且 成交量<均值×倍数）
-// 解除风控必须使用完结的K线数据
-func (r *RiskMonitor) checkSymbolRecovery(symbol string) (bool, string) {
+// 解除风控必须使用完结的K线数据；metric.Abnormal 表示未恢复
+func (r *RiskMonitor) checkSymbolRecovery(symbol string) (isRecovered bool, reason string, metric event.RiskSymbolMetric) {
+	metric = event.RiskSymbolMetric{Symbol: symbol, Abnormal: true}
+	defer func() { metric.Reason = reason }()
+
 	symbolData, exists := r.symbolDataMap[symbol]
 	if !exists {
-		return false, "无数据"
+		return false, "无数据", metric
 	}
 
 	symbolData.mu.RLock()
@@ -253,7 +473,7 @@ func (r *RiskMonitor) checkSymbolRecovery(symbol string) (bool, string) {
 	symbolData.mu.RUnlock()
 
 	if candleCount < r.cfg.RiskControl.AverageWindow+1 {
-		return false, "数据不足"
+		return false, "数据不足", metric
 	}
 
 	// 找到最新的完结K线用于判断（如果最后一根是未完结的，使用倒数第二根）
@@ -269,7 +489,7 @@ func (r *RiskMonitor) checkSymbolRecovery(symbol string) (bool, string) {
 	}
 
 	if currentCandle == nil {
-		return false, "无完结K线"
+		return false, "无完结K线", metric
 	}
 
 	// 计算移动平均价格和移动平均成交量（只使用完结的K线，排除当前用于判断的这根）
@@ -287,36 +507,60 @@ func (r *RiskMonitor) checkSymbolRecovery(symbol string) (bool, string) {
 	}
 
 	if validCount < window {
-		return false, fmt.Sprintf("完结K线不足(%d<%d)", validCount, window)
+		return false, fmt.Sprintf("完结K线不足(%d<%d)", validCount, window), metric
 	}
 
 	avgPrice := totalPrice / float64(validCount)
 	avgVol := totalVol / float64(validCount)
+	metric = newRiskSymbolMetric(symbol, currentCandle, avgPrice, avgVol)
 
 	// 恢复条件：价格 > 均价 且 成交量 < 均值×倍数（与触发条件对应）
 	priceAboveMA := currentPrice > avgPrice
 	volNormal := currentCandle.Volume < avgVol*r.cfg.RiskControl.VolumeMultiplier
 
 	if priceAboveMA && volNormal {
-		return true, "价格回归均线/量正常"
+		return true, "价格回归均线/量正常", metric
 	}
 
 	// 返回未恢复原因
+	metric.Abnormal = true
 	if !priceAboveMA {
-		return false, fmt.Sprintf("价格%.2f<均价%.2f", currentPrice, avgPrice)
+		return false, fmt.Sprintf("价格%.2f<均价%.2f", currentPrice, avgPrice), metric
 	}
-	return false, fmt.Sprintf("量%.0f>均量×%.1f", currentCandle.Volume, r.cfg.RiskControl.VolumeMultiplier)
+	return false, fmt.Sprintf("量%.0f>均量×%.1f", currentCandle.Volume, r.cfg.RiskControl.VolumeMultiplier), metric
+}
+
+// newRiskSymbolMetric 按判断使用的K线和移动平均值生成风控指标
+func newRiskSymbolMetric(symbol string, candle *exchange.Candle, avgPrice, avgVol float64) event.RiskSymbolMetric {
+	m := event.RiskSymbolMetric{
+		Symbol:       symbol,
+		Price:        candle.Close,
+		AvgPrice:     avgPrice,
+		Volume:       candle.Volume,
+		AvgVolume:    avgVol,
+		CandleClosed: candle.IsClosed,
+	}
+	if avgPrice > 0 {
+		m.PriceDeviation = (candle.Close - avgPrice) / avgPrice * 100
+	}
+	if avgVol > 0 {
+		m.VolumeRatio = candle.Volume / avgVol
+	}
+	return m
 }
 
 // checkSymbol 检查单个币种（基于移动平均线）
 // 触发风控可以使用最新K线数据（包括未完结的K线），以便及时检测到异常
-func (r *RiskMonitor) checkSymbol(symbol string) (bool, string) {
+func (r *RiskMonitor) checkSymbol(symbol string) (bool, string, event.RiskSymbolMetric) {
+	metric := event.RiskSymbolMetric{Symbol: symbol}
+
 	r.mu.RLock()
 	symbolData, exists := r.symbolDataMap[symbol]
 	r.mu.RUnlock()
 
 	if !exists {
-		return false, ""
+		metric.Reason = "无数据"
+		return false, "", metric
 	}
 
 	symbolData.mu.RLock()
@@ -325,7 +569,8 @@ func (r *RiskMonitor) checkSymbol(symbol string) (bool, string) {
 	symbolData.mu.RUnlock()
 
 	if candleCount < r.cfg.RiskControl.AverageWindow+1 {
-		return false, ""
+		metric.Reason = "数据不足"
+		return false, "", metric
 	}
 
 	// 最新K线（可以是未完结的，用于实时检测）
@@ -348,22 +593,53 @@ func (r *RiskMonitor) checkSymbol(symbol string) (bool, string) {
 	}
 
 	if validCount < window {
-		return false, ""
+		metric.Reason = fmt.Sprintf("完结K线不足(%d<%d)", validCount, window)
+		return false, "", metric
 	}
 
 	avgPrice := totalPrice / float64(validCount)
 	avgVol := totalVol / float64(validCount)
+	metric = newRiskSymbolMetric(symbol, currentCandle, avgPrice, avgVol)
 
 	// 计算当前价格偏离均线的百分比
-	priceDeviation := (currentPrice - avgPrice) / avgPrice * 100
-	volRatio := currentCandle.Volume / avgVol
+	priceDeviation := metric.PriceDeviation
+	volRatio := metric.VolumeRatio
 
 	// 触发条件：当前价格 < 均价 且 成交量放大（使用最新数据，包括未完结K线）
 	if currentPrice < avgPrice && currentCandle.Volume > avgVol*r.cfg.RiskControl.VolumeMultiplier {
-		return true, fmt.Sprintf("价格%.2f%%低于均线/量×%.1f", priceDeviation, volRatio)
+		metric.Abnormal = true
+		metric.Reason = fmt.Sprintf("价格%.2f%%低于均线/量×%.1f", priceDeviation, volRatio)
+		return true, metric.Reason, metric
 	}
 
-	return false, ""
+	return false, "", metric
+}
+
+// AverageRangePercent 最近 average_window 根完结K线的平均振幅（(最高价-最低价)/收盘价，百分比）
+// 该币种不在监控列表或完结K线不足时 ok=false
+func (r *RiskMonitor) AverageRangePercent(symbol string) (avg float64, ok bool) {
+	r.mu.RLock()
+	symbolData, exists := r.symbolDataMap[symbol]
+	r.mu.RUnlock()
+	if !exists {
+		return 0, false
+	}
+
+	symbolData.mu.RLock()
+	defer symbolData.mu.RUnlock()
+	window := r.cfg.RiskControl.AverageWindow
+	var total float64
+	var count int
+	for i := len(symbolData.candles) - 1; i >= 0 && count < window; i-- {
+		if c := symbolData.candles[i]; c.IsClosed && c.Close > 0 {
+			total += (c.High - c.Low) / c.Close * 100
+			count++
+		}
+	}
+	if count < window {
+		return 0, false
+	}
+	return total / float64(count), true
 }
 
 // IsTriggered 返回是否触发风控
@@ -395,9 +671,9 @@ func (r *RiskMonitor) reportStatus() {
 	r.mu.RUnlock()
 
 	if triggered {
-		logger.Warn("⚠️ [风控监测] 当前市场交易出现异动,触发主动安全风控,停止交易!")
+		riskLog.Warn("⚠️ [风控监测] 当前市场交易出现异动,触发主动安全风控,停止交易!")
 	} else {
-		logger.Info("🛡️ [风控监测] 市场环境正常。")
+		riskLog.Info("🛡️ [风控监测] 市场环境正常。")
 	}
 
 	// 打印各币种的移动平均线数值
@@ -406,7 +682,7 @@ func (r *RiskMonitor) reportStatus() {
 
 // printMovingAverages 打印各币种的移动平均线数值
 func (r *RiskMonitor) printMovingAverages(inRiskControl bool) {
-	logger.Info("📊 [移动平均线监测] 当前各币种数据:")
+	riskLog.Info("📊 [移动平均线监测] 当前各币种数据:")
 
 	// 检查K线数据是否过期
 	hasStaleData := false
@@ -417,7 +693,7 @@ func (r *RiskMonitor) printMovingAverages(inRiskControl bool) {
 		r.mu.RUnlock()
 
 		if !exists {
-			logger.Info("  %s: 无数据", symbol)
+			riskLog.Info("  %s: 无数据", symbol)
 			continue
 		}
 
@@ -427,7 +703,7 @@ func (r *RiskMonitor) printMovingAverages(inRiskControl bool) {
 		symbolData.mu.RUnlock()
 
 		if candleCount < r.cfg.RiskControl.AverageWindow+1 {
-			logger.Info("  %s: 数据不足 (当前%d根, 需要%d根)", symbol, candleCount, r.cfg.RiskControl.AverageWindow+1)
+			riskLog.Info("  %s: 数据不足 (当前%d根, 需要%d根)", symbol, candleCount, r.cfg.RiskControl.AverageWindow+1)
 			continue
 		}
 
@@ -447,7 +723,7 @@ func (r *RiskMonitor) printMovingAverages(inRiskControl bool) {
 				}
 			}
 			if currentCandle == nil {
-				logger.Info("  %s: 无完结K线", symbol)
+				riskLog.Info("  %s: 无完结K线", symbol)
 				continue
 			}
 		} else {
@@ -472,7 +748,7 @@ func (r *RiskMonitor) printMovingAverages(inRiskControl bool) {
 		}
 
 		if validCount < window {
-			logger.Info("  %s: 完结K线不足 (当前%d根, 需要%d根)", symbol, validCount, window)
+			riskLog.Info("  %s: 完结K线不足 (当前%d根, 需要%d根)", symbol, validCount, window)
 			continue
 		}
 
@@ -548,7 +824,7 @@ func (r *RiskMonitor) printMovingAverages(inRiskControl bool) {
 			}
 		}
 
-		logger.Info("  %s %s", symbol, statusMsg)
+		riskLog.Info("  %s %s", symbol, statusMsg)
 
 		// 检查数据是否过期（超过2分钟）
 		if klineAge > 2*time.Minute {
@@ -558,13 +834,18 @@ func (r *RiskMonitor) printMovingAverages(inRiskControl bool) {
 
 	// 如果有过期数据，发出警告
 	if hasStaleData {
-		logger.Warn("⚠️ [K线数据] 部分币种的K线数据超过2分钟未更新，可能K线流断开或重连中")
+		riskLog.Warn("⚠️ [K线数据] 部分币种的K线数据超过2分钟未更新，可能K线流断开或重连中")
 	}
 }
 
-// Stop 停止监控
+// Stop 停止监控（配置了 state_file 时保存最终的均线状态）
 func (r *RiskMonitor) Stop() {
 	if r.exchange != nil {
 		r.exchange.StopKlineStream()
 	}
+	if path := r.cfg.RiskControl.StateFile; path != "" && r.cfg.RiskControl.Enabled {
+		if err := r.saveState(path); err != nil {
+			riskLog.Warn("⚠️ [均线状态] %v", err)
+		}
+	}
 }
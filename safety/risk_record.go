@@ -0,0 +1,49 @@
+package safety
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"opensqt/event"
+)
+
+// recordEvaluations 将风控评估记录按 JSON Lines 追加保存到 risk_control.record_file（阻塞直到 ctx 取消）
+// 每行包含事件时间和 RiskEvaluation 各字段，便于离线分析阈值与误触发
+func (r *RiskMonitor) recordEvaluations(ctx context.Context, path string) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			riskLog.Error("❌ [风控记录] 创建目录失败，评估记录不保存: %v", err)
+			return
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		riskLog.Error("❌ [风控记录] 打开记录文件失败，评估记录不保存: %v", err)
+		return
+	}
+	defer file.Close()
+
+	type record struct {
+		Time string `json:"time"`
+		event.RiskEvaluation
+	}
+	unsubscribe := event.Subscribe("risk-record", func(e event.Event) {
+		evaluation, ok := e.Payload.(event.RiskEvaluation)
+		if !ok {
+			return
+		}
+		line, err := json.Marshal(record{Time: e.Time.Format("2006-01-02T15:04:05.000Z07:00"), RiskEvaluation: evaluation})
+		if err != nil {
+			return
+		}
+		if _, err := file.Write(append(line, '\n')); err != nil {
+			riskLog.Warn("⚠️ [风控记录] 写入记录文件失败: %v", err)
+		}
+	}, event.TypeRiskEvaluation)
+	defer unsubscribe()
+
+	riskLog.Info("📝 [风控记录] 评估记录保存到 %s", path)
+	<-ctx.Done()
+}
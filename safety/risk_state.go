@@ -0,0 +1,185 @@
+package safety
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"opensqt/exchange"
+)
+
+// riskState 风控均线状态（risk_control.state_file），保存各币种用于计算均价/均量的K线窗口
+type riskState struct {
+	SavedAt       time.Time                     `json:"saved_at"`
+	Exchange      string                        `json:"exchange"`
+	Interval      string                        `json:"interval"`
+	AverageWindow int                           `json:"average_window"`
+	Symbols       map[string][]*exchange.Candle `json:"symbols"` // 监控币种 -> 完结K线（由旧到新）
+}
+
+// saveState 保存各币种的完结K线（先写临时文件再替换，避免读到半个文件）
+func (r *RiskMonitor) saveState(path string) error {
+	state := riskState{
+		SavedAt:       time.Now(),
+		Exchange:      r.exchange.GetName(),
+		Interval:      r.cfg.RiskControl.Interval,
+		AverageWindow: r.cfg.RiskControl.AverageWindow,
+		Symbols:       make(map[string][]*exchange.Candle, len(r.symbolDataMap)),
+	}
+	r.mu.RLock()
+	for symbol, symbolData := range r.symbolDataMap {
+		symbolData.mu.RLock()
+		closed := make([]*exchange.Candle, 0, len(symbolData.candles))
+		for _, c := range symbolData.candles {
+			if c.IsClosed {
+				copied := *c
+				closed = append(closed, &copied)
+			}
+		}
+		symbolData.mu.RUnlock()
+		if len(closed) > 0 {
+			state.Symbols[symbol] = closed
+		}
+	}
+	r.mu.RUnlock()
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("序列化均线状态失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入均线状态失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("写入均线状态失败: %w", err)
+	}
+	return nil
+}
+
+// loadState 读取均线状态，返回可直接恢复的币种K线（文件不存在、过期或与当前配置不符时返回 nil）
+// 单个币种最新K线过旧或完结K线不足 average_window+1 根时不恢复该币种，由调用方通过 REST 回填
+func (r *RiskMonitor) loadState(path string) map[string][]*exchange.Candle {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			riskLog.Warn("⚠️ [均线状态] 读取状态文件失败，改为回填历史K线: %v", err)
+		}
+		return nil
+	}
+	var state riskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		riskLog.Warn("⚠️ [均线状态] 状态文件解析失败，改为回填历史K线: %v", err)
+		return nil
+	}
+
+	maxAge := r.stateMaxAge()
+	if age := time.Since(state.SavedAt); age > maxAge {
+		riskLog.Info("📊 [均线状态] 状态保存于 %v 前，超过有效期 %v，改为回填历史K线",
+			age.Round(time.Second), maxAge)
+		return nil
+	}
+	if state.Exchange != r.exchange.GetName() || state.Interval != r.cfg.RiskControl.Interval ||
+		state.AverageWindow != r.cfg.RiskControl.AverageWindow {
+		riskLog.Info("📊 [均线状态] 状态文件的交易所/K线周期/均线窗口 (%s/%s/%d) 与当前配置不同，改为回填历史K线",
+			state.Exchange, state.Interval, state.AverageWindow)
+		return nil
+	}
+
+	// 最新一根完结K线的开盘时间距今不超过 有效期 + 1个周期
+	oldest := time.Now().Add(-maxAge - klineIntervalDuration(r.cfg.RiskControl.Interval))
+	restored := make(map[string][]*exchange.Candle, len(state.Symbols))
+	for symbol, candles := range state.Symbols {
+		if _, monitored := r.symbolDataMap[symbol]; !monitored {
+			continue
+		}
+		if len(candles) < r.cfg.RiskControl.AverageWindow+1 {
+			riskLog.Info("📊 [均线状态] %s 仅保存了 %d 根K线（需要 %d），改为回填", symbol, len(candles), r.cfg.RiskControl.AverageWindow+1)
+			continue
+		}
+		if last := candles[len(candles)-1]; last == nil || candleTime(last.Timestamp).Before(oldest) {
+			riskLog.Info("📊 [均线状态] %s 的K线已过期，改为回填", symbol)
+			continue
+		}
+		valid := true
+		for _, c := range candles {
+			if c == nil {
+				valid = false
+				break
+			}
+			c.Symbol = symbol
+			c.IsClosed = true
+		}
+		if valid {
+			restored[symbol] = candles
+		}
+	}
+	return restored
+}
+
+// persistState 每分钟保存一次均线状态（阻塞直到 ctx 取消，退出时由 Stop 保存最终状态）
+func (r *RiskMonitor) persistState(ctx context.Context, path string) {
+	ticker := time.NewTicker(60 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.saveState(path); err != nil {
+				riskLog.Warn("⚠️ [均线状态] %v", err)
+			}
+		}
+	}
+}
+
+// stateMaxAge 均线状态的最长有效期（未配置时为3根K线周期，周期无法识别时为5分钟）
+func (r *RiskMonitor) stateMaxAge() time.Duration {
+	if seconds := r.cfg.RiskControl.StateMaxAge; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if interval := klineIntervalDuration(r.cfg.RiskControl.Interval); interval > 0 {
+		return 3 * interval
+	}
+	return 5 * time.Minute
+}
+
+// klineIntervalDuration 解析K线周期（1m / 15m / 1h / 4h / 1d / 1w / 1M），无法识别时返回0
+func klineIntervalDuration(interval string) time.Duration {
+	if len(interval) < 2 {
+		return 0
+	}
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0
+	}
+	switch interval[len(interval)-1:] {
+	case "m":
+		return time.Duration(n) * time.Minute
+	case "h":
+		return time.Duration(n) * time.Hour
+	case "d":
+		return time.Duration(n) * 24 * time.Hour
+	case "w":
+		return time.Duration(n) * 7 * 24 * time.Hour
+	case "M":
+		return time.Duration(n) * 30 * 24 * time.Hour
+	}
+	return 0
+}
+
+// candleTime K线时间戳转换为时间（自动判断毫秒或秒）
+func candleTime(timestamp int64) time.Time {
+	if timestamp > 10000000000 {
+		return time.UnixMilli(timestamp)
+	}
+	return time.Unix(timestamp, 0)
+}
@@ -13,12 +13,16 @@ import (
 //   - symbol: 交易对
 //   - currentPrice: 当前币价
 //   - orderAmount: 每笔交易金额（USDT/USDC）
-//   - priceInterval: 价格间隔（买入价和卖出价的差值）
+//   - sellInterval: 卖单间隔（买入价和卖出价的差值，设置 sell_price_interval 时与买单网格间隔不同）
+//   - minOrderValue: 用户配置的最小订单价值（min_order_value）
+//   - capitalAllocation: 分配给该交易对的资金（0表示不限制，见 ResolveCapitalAllocations）
 //   - feeRate: 手续费率
 //   - requiredPositions: 要求的最少持仓数量（默认100）
 //   - priceDecimals: 价格小数位数（用于格式化显示）
 //   - maxLeverage: 最大允许杠杆倍数（默认10）
-func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orderAmount, priceInterval, feeRate float64, requiredPositions, priceDecimals, maxLeverage int) error {
+//
+// 检查失败时返回 *SafetyCheckError，可通过 SafetyCheckReasonOf 获取失败原因
+func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orderAmount, sellInterval, minOrderValue, capitalAllocation, feeRate float64, requiredPositions, buyWindowSize, priceDecimals, maxLeverage int) error {
 	logger.Info("🔒 ===== 开始持仓安全性检查 =====")
 
 	// 从交易所接口获取计价币种（支持U本位和币本位合约）
@@ -28,7 +32,7 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 	ctx := context.Background()
 	account, err := ex.GetAccount(ctx)
 	if err != nil {
-		return fmt.Errorf("获取账户信息失败: %w", err)
+		return &SafetyCheckError{Reason: ReasonAccountUnavailable, Message: "获取账户信息失败", Err: err}
 	}
 
 	// 2. 获取交易对的杠杆倍数和持仓信息
@@ -63,23 +67,31 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 	}
 	accountBalance := account.AvailableBalance
 	if accountBalance <= 0 {
-		return fmt.Errorf("账户余额不足，当前余额: %.2f %s", accountBalance, quoteCurrency)
+		return &SafetyCheckError{
+			Reason:  ReasonInsufficientBalance,
+			Message: fmt.Sprintf("账户余额不足，当前余额: %.2f %s", accountBalance, quoteCurrency),
+		}
 	}
 	logger.Info("💰 账户余额: %.2f %s (交易对: %s)", accountBalance, quoteCurrency, symbol)
-	// 如果是币安交易所，尝试获取更准确的杠杆信息
+	// 配置了资金分配时，以分配金额作为该交易对的可用余额
+	if capitalAllocation > 0 && capitalAllocation < accountBalance {
+		accountBalance = capitalAllocation
+		logger.Info("💼 按资金分配计算: 可用余额 %.2f %s", accountBalance, quoteCurrency)
+	}
+	// 交易所支持按交易对查询杠杆时（ILeverageProvider），以交易对实际设置的杠杆为准
 	exchangeName := ex.GetName()
-	if leverage == 1 && exchangeName == "Binance" {
-		// 尝试通过币安特定的方法获取杠杆（如果获取失败也没关系，使用默认值）
-		if binanceLeverage := tryGetBinanceLeverage(ex, symbol); binanceLeverage > 0 {
-			leverage = binanceLeverage
-		}
+	if symbolLeverage := trySymbolLeverage(ex, symbol); symbolLeverage > 0 {
+		leverage = symbolLeverage
 	}
 
 	logger.Info("📊 交易所: %s, 交易对: %s, 当前杠杆倍数: %dx, 当前持仓: %.4f", exchangeName, symbol, leverage, positionAmt)
 
 	// 3. 强制杠杆倍数检查
 	if leverage > maxLeverage {
-		return fmt.Errorf("您的账户杠杆倍率太高（%dx），风险太大，禁止开仓。最大允许杠杆倍数: %dx", leverage, maxLeverage)
+		return &SafetyCheckError{
+			Reason:  ReasonLeverageTooHigh,
+			Message: fmt.Sprintf("您的账户杠杆倍率太高（%dx），风险太大，禁止开仓。最大允许杠杆倍数: %dx", leverage, maxLeverage),
+		}
 	}
 
 	// 4. 计算最大可持有仓位
@@ -105,9 +117,41 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 	logger.Info("🎯 最大可持有仓位: %.0f 仓", maxPositions)
 	logger.Info("✅ 要求最少持有: %d 仓", requiredPositions)
 
+	// 最小下单金额：用户配置与交易所限制分开展示，生效值取两者较大者
+	exchangeMinNotional := ex.GetMinNotional()
+	effectiveMinValue := minOrderValue
+	if exchangeMinNotional > effectiveMinValue {
+		effectiveMinValue = exchangeMinNotional
+	}
+	if exchangeMinNotional > 0 {
+		logger.Info("📏 最小下单金额: 配置 %.2f %s, 交易所 %.2f %s, 生效 %.2f %s",
+			minOrderValue, quoteCurrency, exchangeMinNotional, quoteCurrency, effectiveMinValue, quoteCurrency)
+	} else {
+		logger.Info("📏 最小下单金额: 配置 %.2f %s, 交易所 未提供, 生效 %.2f %s",
+			minOrderValue, quoteCurrency, effectiveMinValue, quoteCurrency)
+	}
+	if exchangeMinNotional > minOrderValue {
+		logger.Warn("⚠️ 配置的 min_order_value (%.2f) 低于交易所最小下单金额 (%.2f)，将按交易所限制过滤价格层",
+			minOrderValue, exchangeMinNotional)
+	}
+	if orderAmount < effectiveMinValue {
+		logger.Warn("⚠️ 每笔金额 %.2f %s 低于生效的最小下单金额 %.2f %s，买单需自动上调数量(min_notional_auto_raise)否则将被跳过",
+			orderAmount, quoteCurrency, effectiveMinValue, quoteCurrency)
+	}
+
 	// 5. 验证是否满足要求
 	if maxPositions < float64(requiredPositions) {
-		return fmt.Errorf("持仓安全检查失败：您的账户余额不足，请补充足够保证金或调整配置参数，最少足够向下购买持有 %d 仓。当前最大可持有: %.0f 仓", requiredPositions, maxPositions)
+		return &SafetyCheckError{
+			Reason:  ReasonInsufficientPositions,
+			Message: fmt.Sprintf("持仓安全检查失败：您的账户余额不足，请补充足够保证金或调整配置参数，最少足够向下购买持有 %d 仓。当前最大可持有: %.0f 仓", requiredPositions, maxPositions),
+		}
+	}
+
+	if float64(buyWindowSize) > maxPositions {
+		return &SafetyCheckError{
+			Reason:  ReasonBuyWindowTooLarge,
+			Message: fmt.Sprintf("持仓安全检查失败：买单窗口 %d 层全部成交需要的保证金超过账户可持有仓位 %.0f 仓，请减小 buy_window_size 或 target_utilization", buyWindowSize, maxPositions),
+		}
 	}
 
 	logger.Info("✅ 持仓安全性检查通过：可以安全持有至少 %d 仓", requiredPositions)
@@ -120,50 +164,42 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 		symbol, buyFeeRate*100, sellFeeRate*100)
 
 	// 计算每笔交易的利润和手续费
-	// 🔥 固定金额模式：每笔买入金额固定，数量根据价格动态计算
-	buyPrice := currentPrice
-	sellPrice := currentPrice + priceInterval
-
-	// 买入时：投入固定金额，买到的数量 = orderAmount / buyPrice
-	buyQuantity := orderAmount / buyPrice
-	// 卖出时：卖出价 = buyPrice + priceInterval
-	sellQuantity := buyQuantity // 卖出数量等于买入数量
-
-	// 利润 = 卖出金额 - 买入金额
-	buyAmount := orderAmount               // 买入金额固定
-	sellAmount := sellPrice * sellQuantity // 卖出金额
-	profitPerTrade := sellAmount - buyAmount
-
-	// 手续费 = 买入手续费 + 卖出手续费
-	buyFee := buyAmount * buyFeeRate
-	sellFee := sellAmount * sellFeeRate
+	trade := EstimateTradeProfit(currentPrice, orderAmount, sellInterval, feeRate)
+	buyPrice, sellPrice := trade.BuyPrice, trade.SellPrice
+	buyQuantity, sellQuantity := trade.Quantity, trade.Quantity
+	buyAmount, sellAmount := trade.BuyAmount, trade.SellAmount
+	profitPerTrade := trade.Profit
+	buyFee, sellFee := trade.BuyFee, trade.SellFee
 	totalFee := buyFee + sellFee
 
 	// 计算总手续费率（买入费率 + 卖出费率）
 	totalFeeRate := buyFeeRate + sellFeeRate
 
 	// 计算利润占买入价的比例（利润率）
-	profitRate := priceInterval / buyPrice
+	profitRate := sellInterval / buyPrice
 
 	logger.Info("💰 每笔交易分析 (固定金额模式):")
-	logger.Info("   买入价: %.*f, 卖出价: %.*f, 价格差: %.*f", priceDecimals, buyPrice, priceDecimals, sellPrice, priceDecimals, priceInterval)
+	logger.Info("   买入价: %.*f, 卖出价: %.*f, 价格差: %.*f", priceDecimals, buyPrice, priceDecimals, sellPrice, priceDecimals, sellInterval)
 	logger.Info("   买入金额: %.2f %s, 买入数量: %.4f", buyAmount, quoteCurrency, buyQuantity)
 	logger.Info("   卖出金额: %.2f %s, 卖出数量: %.4f", sellAmount, quoteCurrency, sellQuantity)
 	logger.Info("   每笔利润: %.4f %s (卖出 %.2f - 买入 %.2f)", profitPerTrade, quoteCurrency, sellAmount, buyAmount)
-	logger.Info("   利润率: %.4f%% (价格差 %.*f / 买入价 %.*f)", profitRate*100, priceDecimals, priceInterval, priceDecimals, buyPrice)
+	logger.Info("   利润率: %.4f%% (价格差 %.*f / 买入价 %.*f)", profitRate*100, priceDecimals, sellInterval, priceDecimals, buyPrice)
 	logger.Info("   买入手续费: %.4f %s (金额 %.2f × 费率 %.4f%%)", buyFee, quoteCurrency, buyAmount, buyFeeRate*100)
 	logger.Info("   卖出手续费: %.4f %s (金额 %.2f × 费率 %.4f%%)", sellFee, quoteCurrency, sellAmount, sellFeeRate*100)
 	logger.Info("   总手续费: %.4f %s (费率: %.4f%%)", totalFee, quoteCurrency, totalFeeRate*100)
 
-	netProfit := profitPerTrade - totalFee
+	netProfit := trade.NetProfit
 	logger.Info("   净利润: %.4f %s (利润 %.4f - 手续费 %.4f)", netProfit, quoteCurrency, profitPerTrade, totalFee)
 
 	// 验证利润是否足够支付手续费（净利润必须为正）
 	if netProfit <= 0 {
 		logger.Error("❌ 错误：每笔净利润为负或为零 (%.4f %s)，无法盈利！", netProfit, quoteCurrency)
-		logger.Error("   建议：增加价格间隔或降低手续费率")
-		logger.Error("   当前价格间隔: %.*f, 手续费率: %.4f%%", priceDecimals, priceInterval, totalFeeRate*100)
-		return fmt.Errorf("每笔净利润为负或为零 (%.4f %s)，系统拒绝启动", netProfit, quoteCurrency)
+		logger.Error("   建议：增加价格间隔（或 sell_price_interval）或降低手续费率")
+		logger.Error("   当前卖单间隔: %.*f, 手续费率: %.4f%%", priceDecimals, sellInterval, totalFeeRate*100)
+		return &SafetyCheckError{
+			Reason:  ReasonUnprofitable,
+			Message: fmt.Sprintf("每笔净利润为负或为零 (%.4f %s)，系统拒绝启动", netProfit, quoteCurrency),
+		}
 	}
 
 	logger.Info("✅ 手续费率安全检查通过：每笔净利润 %.4f %s", netProfit, quoteCurrency)
@@ -173,13 +209,47 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 	return nil
 }
 
-// tryGetBinanceLeverage 尝试获取币安的杠杆信息（可选功能，失败不影响主流程）
-func tryGetBinanceLeverage(ex exchange.IExchange, symbol string) int {
-	// 由于币安适配器可能有特定的方法，这里我们通过反射或类型断言来获取
-	// 如果失败，返回0表示无法获取
+// TradeProfit 单笔网格交易（按当前价格买入，上涨一个价格间隔后卖出）的盈亏
+type TradeProfit struct {
+	BuyPrice   float64
+	SellPrice  float64
+	Quantity   float64 // 买入数量 = 卖出数量
+	BuyAmount  float64
+	SellAmount float64
+	Profit     float64 // 卖出金额 - 买入金额
+	BuyFee     float64
+	SellFee    float64
+	NetProfit  float64 // 扣除买卖手续费后的净利润
+}
 
-	// 这里可以根据实际情况实现，暂时返回0让其使用默认值
-	// 后续可以扩展：通过反射或扩展接口来获取特定交易所的杠杆信息
+// EstimateTradeProfit 计算单笔网格交易的盈亏
+// 🔥 固定金额模式：每笔买入金额固定，数量根据价格动态计算，价格越高同样的间隔利润率越低
+func EstimateTradeProfit(price, orderAmount, priceInterval, feeRate float64) TradeProfit {
+	t := TradeProfit{
+		BuyPrice:  price,
+		SellPrice: price + priceInterval,
+		BuyAmount: orderAmount, // 买入金额固定
+	}
+	// 买入时：投入固定金额，买到的数量 = orderAmount / buyPrice；卖出数量等于买入数量
+	t.Quantity = orderAmount / t.BuyPrice
+	t.SellAmount = t.SellPrice * t.Quantity
+	t.Profit = t.SellAmount - t.BuyAmount
+	// 手续费 = 买入手续费 + 卖出手续费
+	t.BuyFee = t.BuyAmount * feeRate
+	t.SellFee = t.SellAmount * feeRate
+	t.NetProfit = t.Profit - t.BuyFee - t.SellFee
+	return t
+}
 
-	return 0 // 表示无法获取，使用默认值
+// trySymbolLeverage 尝试查询交易对的杠杆倍数（可选功能，不支持或查询失败时返回0，沿用持仓/账户中的杠杆）
+func trySymbolLeverage(ex exchange.IExchange, symbol string) int {
+	leverage, ok, err := exchange.GetSymbolLeverage(ex, symbol)
+	if !ok {
+		return 0
+	}
+	if err != nil {
+		logger.Warn("⚠️ 查询 %s 杠杆倍数失败，使用持仓/账户中的杠杆: %v", symbol, err)
+		return 0
+	}
+	return leverage
 }
@@ -0,0 +1,47 @@
+package safety
+
+import "errors"
+
+// SafetyCheckReason 持仓安全检查失败原因（机器可读，便于调用方区分处理）
+type SafetyCheckReason string
+
+const (
+	ReasonAccountUnavailable    SafetyCheckReason = "account_unavailable"    // 获取账户信息失败
+	ReasonInsufficientBalance   SafetyCheckReason = "insufficient_balance"   // 可用余额为零或负数
+	ReasonLeverageTooHigh       SafetyCheckReason = "leverage_too_high"      // 杠杆倍数超过 max_leverage
+	ReasonInsufficientPositions SafetyCheckReason = "insufficient_positions" // 最大可持有仓位不足 required_positions
+	ReasonBuyWindowTooLarge     SafetyCheckReason = "buy_window_too_large"   // 买单窗口全部成交所需保证金超过可持有仓位
+	ReasonUnprofitable          SafetyCheckReason = "unprofitable"           // 每笔净利润无法覆盖手续费
+
+	// 只减仓检查（CheckReduceOnly）
+	ReasonReduceOnlyUnsupported SafetyCheckReason = "reduce_only_unsupported" // 交易所适配器未声明传递只减仓标记
+	ReasonReduceOnlyIgnored     SafetyCheckReason = "reduce_only_ignored"     // 无持仓的只减仓卖单被交易所接受
+	ReasonCapabilityUnsupported SafetyCheckReason = "capability_unsupported"  // 交易所适配器缺少策略需要的能力
+)
+
+// SafetyCheckError 持仓安全检查失败（Error() 返回面向用户的中文说明）
+type SafetyCheckError struct {
+	Reason  SafetyCheckReason
+	Message string
+	Err     error // 底层错误（如交易所接口错误），可能为 nil
+}
+
+func (e *SafetyCheckError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *SafetyCheckError) Unwrap() error {
+	return e.Err
+}
+
+// SafetyCheckReasonOf 提取持仓安全检查失败原因（err 不是 SafetyCheckError 时返回 false）
+func SafetyCheckReasonOf(err error) (SafetyCheckReason, bool) {
+	var safetyErr *SafetyCheckError
+	if errors.As(err, &safetyErr) {
+		return safetyErr.Reason, true
+	}
+	return "", false
+}
@@ -0,0 +1,185 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// SafetyRechecker 运行中安全复核（safety.recheck_interval）
+// 启动时的 CheckAccountSafety 只保证启动那一刻的账户状态；长时间运行后余额被转出、杠杆被调高、
+// 手续费上调都会让这个保证失效。定期按最新账户数据重新检查杠杆、最大可持有仓位和每笔净利润，
+// 不通过时暂停新增买单并发出通知（不退出进程，已有持仓照常挂卖单），恢复通过后自动解除
+type SafetyRechecker struct {
+	cfg               *config.Config
+	ex                exchange.IExchange
+	grid              IGridState
+	priceFn           func() float64
+	capitalAllocation float64
+	sanity            *balanceSanity // 余额读数合理性检查（safety.balance_sanity）
+
+	paused     atomic.Bool
+	lastReason atomic.Value // SafetyCheckReason - 最近一次失败原因
+}
+
+// NewSafetyRechecker 创建运行中安全复核器
+// priceFn 返回最新市场价格，capitalAllocation 为分配给该交易对的资金（0表示不限制）
+func NewSafetyRechecker(cfg *config.Config, ex exchange.IExchange, grid IGridState, priceFn func() float64, capitalAllocation float64) *SafetyRechecker {
+	return &SafetyRechecker{
+		cfg:               cfg,
+		ex:                ex,
+		grid:              grid,
+		priceFn:           priceFn,
+		capitalAllocation: capitalAllocation,
+		sanity:            newBalanceSanity(cfg, "安全复核"),
+	}
+}
+
+// IsPaused 是否因安全复核未通过暂停新增买单
+func (r *SafetyRechecker) IsPaused() bool {
+	return r.paused.Load()
+}
+
+// Start 按 recheck_interval 定期复核（未配置时直接返回）
+func (r *SafetyRechecker) Start(ctx context.Context) {
+	if r.cfg.Safety.RecheckInterval <= 0 {
+		return
+	}
+	interval := time.Duration(r.cfg.Safety.RecheckInterval) * time.Second
+	logger.Info("🔒 [安全复核] 已启用，每 %v 复核一次杠杆、可持有仓位和每笔净利润", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.recheck(ctx)
+		}
+	}
+}
+
+// recheck 执行一次复核并处理暂停/恢复切换
+func (r *SafetyRechecker) recheck(ctx context.Context) {
+	price := r.priceFn()
+	if price <= 0 {
+		return
+	}
+
+	err := r.check(ctx, price)
+	var safetyErr *SafetyCheckError
+	if err != nil && !errors.As(err, &safetyErr) {
+		logger.Warn("⚠️ [安全复核] 复核失败，保持当前状态: %v", err)
+		return
+	}
+
+	if safetyErr != nil {
+		r.lastReason.Store(safetyErr.Reason)
+		if r.paused.CompareAndSwap(false, true) {
+			logger.Error("🚫 [安全复核] [%s] %s，撤销买单并暂停新增买单（已有持仓照常挂卖单）", safetyErr.Reason, safetyErr.Message)
+			event.Publish(event.TypeSafetyCheckFailed, event.SafetyCheckChanged{
+				Reason:  string(safetyErr.Reason),
+				Message: safetyErr.Message,
+			})
+		}
+		return
+	}
+
+	if r.paused.CompareAndSwap(true, false) {
+		reason, _ := r.lastReason.Load().(SafetyCheckReason)
+		logger.Info("✅ [安全复核] 复核恢复通过（上次失败: %s），恢复新增买单", reason)
+		event.Publish(event.TypeSafetyCheckRecovered, event.SafetyCheckChanged{Reason: string(reason)})
+		return
+	}
+	logger.Debug("✅ [安全复核] 复核通过 (价格 %.*f)", r.grid.GetPriceDecimals(), price)
+}
+
+// check 按最新账户数据重新执行启动时的杠杆、可持有仓位和手续费盈利检查
+// 与启动检查不同：运行中已有持仓和挂单占用可用余额，因此按账户权益（保证金余额）计算可持有仓位，
+// 且有持仓时不跳过检查。检查不通过时返回 *SafetyCheckError，查询账户失败时返回普通错误
+func (r *SafetyRechecker) check(ctx context.Context, price float64) error {
+	symbol := r.cfg.Trading.Symbol
+	quoteCurrency := r.ex.GetQuoteAsset()
+
+	account, err := r.ex.GetAccount(ctx)
+	if err != nil {
+		return fmt.Errorf("获取账户信息失败: %w", err)
+	}
+
+	leverage := 1
+	if positions, err := r.ex.GetPositions(ctx, symbol); err == nil {
+		for _, p := range positions {
+			if p.Symbol == symbol && p.Leverage > 0 {
+				leverage = p.Leverage
+				break
+			}
+		}
+	}
+	if leverage == 1 && account.AccountLeverage > 0 {
+		leverage = account.AccountLeverage
+	}
+
+	if leverage > r.cfg.Trading.MaxLeverage {
+		return &SafetyCheckError{
+			Reason:  ReasonLeverageTooHigh,
+			Message: fmt.Sprintf("账户杠杆倍率 %dx 超过最大允许杠杆 %dx", leverage, r.cfg.Trading.MaxLeverage),
+		}
+	}
+
+	balance := account.TotalMarginBalance
+	if balance <= 0 {
+		balance = account.TotalWalletBalance
+	}
+	if err := r.sanity.Check(balance); err != nil {
+		return err // 异常读数跳过本次复核，不据此暂停买单
+	}
+	if r.capitalAllocation > 0 && r.capitalAllocation < balance {
+		balance = r.capitalAllocation
+	}
+	if balance <= 0 {
+		return &SafetyCheckError{
+			Reason:  ReasonInsufficientBalance,
+			Message: fmt.Sprintf("账户余额不足，当前余额: %.2f %s", balance, quoteCurrency),
+		}
+	}
+
+	orderAmount := r.grid.GetOrderQuantity()
+	maxPositions := balance * float64(leverage) / orderAmount
+	required := r.cfg.Trading.PositionSafetyCheck
+	if required <= 0 {
+		required = 100 // 与启动检查一致，默认100
+	}
+	if maxPositions < float64(required) {
+		return &SafetyCheckError{
+			Reason: ReasonInsufficientPositions,
+			Message: fmt.Sprintf("最大可持有 %.0f 仓，低于要求的 %d 仓（余额 %.2f %s × 杠杆 %dx）",
+				maxPositions, required, balance, quoteCurrency, leverage),
+		}
+	}
+	if buyWindowSize := r.grid.GetBuyWindowSize(); float64(buyWindowSize) > maxPositions {
+		return &SafetyCheckError{
+			Reason:  ReasonBuyWindowTooLarge,
+			Message: fmt.Sprintf("买单窗口 %d 层超过最大可持有 %.0f 仓", buyWindowSize, maxPositions),
+		}
+	}
+
+	interval := r.grid.GetSellPriceInterval()
+	feeRate := r.grid.GetFeeRate()
+	if trade := EstimateTradeProfit(price, orderAmount, interval, feeRate); trade.NetProfit <= 0 {
+		decimals := r.grid.GetPriceDecimals()
+		return &SafetyCheckError{
+			Reason: ReasonUnprofitable,
+			Message: fmt.Sprintf("每笔净利润 %.4f %s ≤ 0（价格 %.*f, 间隔 %.*f, 费率 %.4f%%）",
+				trade.NetProfit, quoteCurrency, decimals, price, decimals, interval, feeRate*100),
+		}
+	}
+	return nil
+}
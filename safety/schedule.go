@@ -0,0 +1,60 @@
+package safety
+
+import (
+	"time"
+
+	"opensqt/logger"
+)
+
+// IClock 时钟（截止时间调度使用，默认系统时钟，可替换为模拟时钟控制调度）
+type IClock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock 系统时钟
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// deadlineSchedule 按截止时间调度的周期任务
+// 下一次截止时间 = 本次截止时间 + 间隔，执行耗时和唤醒延迟不会逐次累积成漂移；
+// 唤醒明显晚于截止时间时告警（负载过高导致调度饥饿），执行耗时超过一个间隔时跳过错过的周期，从当前时间重新计时
+type deadlineSchedule struct {
+	name     string
+	clock    IClock
+	interval time.Duration
+	deadline time.Time
+}
+
+// newDeadlineSchedule 创建调度，第一次截止时间为当前时间 + interval
+func newDeadlineSchedule(name string, clock IClock, interval time.Duration) *deadlineSchedule {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &deadlineSchedule{name: name, clock: clock, interval: interval, deadline: clock.Now().Add(interval)}
+}
+
+// wait 返回截止时间到达时触发的通道
+func (s *deadlineSchedule) wait() <-chan time.Time {
+	return s.clock.After(s.deadline.Sub(s.clock.Now()))
+}
+
+// checkLate 唤醒后调用：比截止时间晚超过 max(1秒, 间隔/4) 时告警
+func (s *deadlineSchedule) checkLate() {
+	late := s.clock.Now().Sub(s.deadline)
+	if late > max(time.Second, s.interval/4) {
+		logger.Warn("⏰ [%s] 本轮比计划时间晚 %v 执行（间隔 %v），系统负载可能过高", s.name, late.Round(time.Millisecond), s.interval)
+	}
+}
+
+// advance 本轮执行完成后调用，按新的间隔计算下一次截止时间
+func (s *deadlineSchedule) advance(interval time.Duration) {
+	s.interval = interval
+	s.deadline = s.deadline.Add(interval)
+	if now := s.clock.Now(); !s.deadline.After(now) {
+		logger.Warn("⏰ [%s] 本轮执行超过一个间隔 (%v)，跳过错过的周期", s.name, interval)
+		s.deadline = now.Add(interval)
+	}
+}
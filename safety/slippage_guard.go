@@ -0,0 +1,104 @@
+package safety
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/logger"
+)
+
+// SlippageGuard 成交滑点保护（trading.slippage_guard）
+// 快速行情中挂单容易被逆向成交：成交均价持续差于委托价说明挂单正在被"吃掉"。
+// 统计最近 window 笔成交相对委托价的逆向滑点，平均值超过 max_slippage_bps 时暂停挂单，
+// cooldown_seconds 后自动恢复并清空统计。与 K 线风控互相独立，只看机器人自身的成交质量
+type SlippageGuard struct {
+	cfg *config.Config
+
+	mu      sync.Mutex
+	samples []float64 // 最近成交的逆向滑点（基点）
+	paused  atomic.Bool
+}
+
+// NewSlippageGuard 创建成交滑点保护
+func NewSlippageGuard(cfg *config.Config) *SlippageGuard {
+	return &SlippageGuard{cfg: cfg}
+}
+
+// IsPaused 是否因成交滑点过大暂停挂单
+func (g *SlippageGuard) IsPaused() bool {
+	return g.paused.Load()
+}
+
+// Start 订阅成交事件（阻塞直到 ctx 取消，未启用时直接返回）
+func (g *SlippageGuard) Start(ctx context.Context) {
+	guard := g.cfg.Trading.SlippageGuard
+	if !guard.Enabled {
+		return
+	}
+
+	unsubscribe := event.Subscribe("slippage-guard", func(e event.Event) {
+		// 多交易对时只统计本交易对的成交
+		if fill, ok := e.Payload.(event.OrderFilled); ok && fill.Symbol == g.cfg.Trading.Symbol {
+			g.onFill(fill)
+		}
+	}, event.TypeOrderFilled)
+	defer unsubscribe()
+
+	logger.Info("🎯 [滑点保护] 启动 (最近 %d 笔成交平均逆向滑点超过 %.2f 基点时暂停 %d 秒, 至少 %d 笔参与判断)",
+		guard.Window, guard.MaxSlippageBps, guard.CooldownSeconds, guard.MinFills)
+	<-ctx.Done()
+}
+
+// onFill 记录一笔成交的滑点，必要时触发暂停
+func (g *SlippageGuard) onFill(fill event.OrderFilled) {
+	if fill.OrderPrice <= 0 || fill.Price <= 0 || g.paused.Load() {
+		return
+	}
+	guard := g.cfg.Trading.SlippageGuard
+
+	// 买单成交价高于委托价、卖单成交价低于委托价为逆向滑点（正数）
+	bps := (fill.Price - fill.OrderPrice) / fill.OrderPrice * 10000
+	if fill.Side == "SELL" {
+		bps = -bps
+	}
+
+	g.mu.Lock()
+	g.samples = append(g.samples, bps)
+	if len(g.samples) > guard.Window {
+		g.samples = g.samples[len(g.samples)-guard.Window:]
+	}
+	fills := len(g.samples)
+	var sum float64
+	for _, s := range g.samples {
+		sum += s
+	}
+	avg := sum / float64(fills)
+	if fills < guard.MinFills || avg <= guard.MaxSlippageBps {
+		g.mu.Unlock()
+		return
+	}
+	g.samples = nil
+	g.mu.Unlock()
+
+	if !g.paused.CompareAndSwap(false, true) {
+		return
+	}
+	logger.Warn("🎯 [滑点保护] 最近 %d 笔成交平均逆向滑点 %.2f 基点 > %.2f 基点，撤销买单并暂停挂单 %d 秒",
+		fills, avg, guard.MaxSlippageBps, guard.CooldownSeconds)
+	event.Publish(event.TypeSlippagePaused, event.SlippageChanged{
+		AvgSlippageBps: avg,
+		Fills:          fills,
+		CooldownSecs:   guard.CooldownSeconds,
+	})
+
+	time.AfterFunc(time.Duration(guard.CooldownSeconds)*time.Second, func() {
+		if g.paused.CompareAndSwap(true, false) {
+			logger.Info("✅ [滑点保护] 冷却结束，恢复挂单")
+			event.Publish(event.TypeSlippageResumed, event.SlippageChanged{CooldownSecs: guard.CooldownSeconds})
+		}
+	})
+}
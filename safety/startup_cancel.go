@@ -0,0 +1,52 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"opensqt/exchange"
+	"opensqt/logger"
+	"opensqt/utils"
+)
+
+// CancelStaleOrders 启动时撤销上次运行遗留的网格订单（trading.cancel_on_start）
+// 只撤销 ClientOrderID 符合本程序格式（{价格}_{B|S}_{时间戳}，可带交易所返佣前缀）的订单，
+// 手动下的订单和其他程序的订单保留；返回撤销的订单数量
+func CancelStaleOrders(ctx context.Context, ex exchange.IExchange, symbol string) (int, error) {
+	orders, err := ex.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return 0, fmt.Errorf("查询未完成订单失败: %w", err)
+	}
+
+	exchangeName := strings.ToLower(ex.GetName())
+	var orderIDs []int64
+	var buys, sells, skipped int
+	for _, ord := range orders {
+		cleanID := utils.RemoveBrokerPrefix(exchangeName, ord.ClientOrderID)
+		if _, _, _, valid := utils.ParseOrderID(cleanID, 0); !valid {
+			skipped++
+			continue
+		}
+		orderIDs = append(orderIDs, ord.OrderID)
+		if ord.Side == exchange.SideBuy {
+			buys++
+		} else {
+			sells++
+		}
+	}
+
+	if skipped > 0 {
+		logger.Info("🧹 [启动撤单] 保留 %d 个非本程序订单（ClientOrderID 不匹配）", skipped)
+	}
+	if len(orderIDs) == 0 {
+		logger.Info("🧹 [启动撤单] 没有遗留的网格订单")
+		return 0, nil
+	}
+
+	if err := ex.BatchCancelOrders(ctx, symbol, orderIDs); err != nil {
+		return 0, fmt.Errorf("撤销 %d 个遗留订单失败: %w", len(orderIDs), err)
+	}
+	logger.Info("🧹 [启动撤单] 已撤销 %d 个遗留的网格订单（买单 %d, 卖单 %d）", len(orderIDs), buys, sells)
+	return len(orderIDs), nil
+}
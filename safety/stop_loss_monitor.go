@@ -0,0 +1,224 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"opensqt/config"
+	"opensqt/event"
+	"opensqt/exchange"
+	"opensqt/logger"
+	"opensqt/notify"
+)
+
+// stopLossInitRetries 启动时获取初始余额失败的重试次数（退避 1s、2s、4s）
+const stopLossInitRetries = 3
+
+// StopLossMonitor 自动止损监控（trading.stop_loss）
+// 定期查询账户净值（EffectiveBalance，含未实现盈亏），相对初始余额亏损达到止损线时触发退出
+// 止损线为 max_loss_usdt 与 初始余额 × max_loss_pct% 中较小的一个（未设置的不参与），充值/提现调整基准后比例止损线随之变化
+type StopLossMonitor struct {
+	cfg            *config.Config
+	exchange       exchange.IExchange
+	initialBalance atomic.Value
+	lastBalance    atomic.Value
+	triggered      atomic.Bool
+	isBalanceSet   atomic.Bool
+	pressure       IPressureSource // 接口压力来源（timing.adaptive_polling），nil 表示固定间隔
+	clock          IClock          // 检查调度时钟
+	sanity         *balanceSanity  // 余额读数合理性检查（safety.balance_sanity），避免异常读数误触发止损
+
+	notifier notify.INotifier // 止损触发告警（nil 表示不推送）
+}
+
+// NewStopLossMonitor 创建自动止损监控
+func NewStopLossMonitor(cfg *config.Config, ex exchange.IExchange) *StopLossMonitor {
+	return &StopLossMonitor{
+		cfg:      cfg,
+		exchange: ex,
+		clock:    systemClock{},
+		sanity:   newBalanceSanity(cfg, "止损检查"),
+	}
+}
+
+// SetPressureSource 设置接口压力来源，压力大时拉长检查间隔（需在 Start 之前调用）
+func (s *StopLossMonitor) SetPressureSource(source IPressureSource) {
+	s.pressure = source
+}
+
+// SetNotifier 设置止损触发的告警推送（需在 Start 之前调用）
+func (s *StopLossMonitor) SetNotifier(n notify.INotifier) {
+	s.notifier = n
+}
+
+// SetClock 设置检查调度时钟（需在 Start 之前调用）
+func (s *StopLossMonitor) SetClock(clock IClock) {
+	s.clock = clock
+}
+
+// SetInitialBalance 记录初始余额作为止损基准（获取账户失败时退避重试，全部失败才返回错误）
+func (s *StopLossMonitor) SetInitialBalance(ctx context.Context) error {
+	account, err := s.exchange.GetAccount(ctx)
+	for attempt := 1; err != nil && attempt <= stopLossInitRetries; attempt++ {
+		delay := time.Duration(math.Pow(2, float64(attempt-1))) * time.Second
+		logger.Warn("⚠️ [止损监控] 获取初始余额失败，%v 后第 %d/%d 次重试: %v", delay, attempt, stopLossInitRetries, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("获取初始余额失败: %w", ctx.Err())
+		}
+		account, err = s.exchange.GetAccount(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("获取初始余额失败（已重试 %d 次）: %w", stopLossInitRetries, err)
+	}
+
+	balance := EffectiveBalance(account)
+	if balance <= 0 {
+		return fmt.Errorf("账户余额无效: %.2f", balance)
+	}
+
+	s.initialBalance.Store(balance)
+	s.lastBalance.Store(balance)
+	s.sanity.Check(balance)
+	s.isBalanceSet.Store(true)
+
+	logger.Info("💰 [止损监控] 初始余额已记录: %.2f USDT, 止损线: %.2f USDT",
+		balance, s.MaxLoss())
+	return nil
+}
+
+// AdjustInitialBalance 外部资金变动（充值/提现等）后调整止损基准，避免提现被计为亏损
+func (s *StopLossMonitor) AdjustInitialBalance(delta float64) {
+	if !s.isBalanceSet.Load() || delta == 0 {
+		return
+	}
+
+	balance := s.initialBalance.Load().(float64) + delta
+	s.initialBalance.Store(balance)
+	logger.Info("💰 [止损监控] 外部资金变动 %+.2f USDT，初始余额调整为: %.2f USDT", delta, balance)
+}
+
+// Start 启动止损监控（阻塞直到 ctx 取消或止损触发，未启用时直接返回）
+func (s *StopLossMonitor) Start(ctx context.Context, onTrigger func()) {
+	if !s.cfg.Trading.StopLoss.Enabled {
+		logger.Info("⚠️ 自动止损未启用")
+		return
+	}
+
+	checkInterval := s.cfg.Trading.StopLoss.CheckInterval
+	logger.Info("🛑 [止损监控] 启动 (止损线: %.2f USDT, 间隔: %d秒)", s.MaxLoss(), checkInterval)
+
+	interval := newPollInterval(s.cfg, "止损监控", time.Duration(checkInterval)*time.Second, s.pressure)
+	schedule := newDeadlineSchedule("止损监控", s.clock, interval.next())
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("⏹️ [止损监控] 监控已停止")
+			return
+
+		case <-schedule.wait():
+			schedule.checkLate()
+			if s.isBalanceSet.Load() && s.checkLossAndTrigger() {
+				onTrigger()
+				return
+			}
+			schedule.advance(interval.next())
+		}
+	}
+}
+
+// checkLossAndTrigger 查询当前余额，亏损达到 max_loss_usdt 时标记触发（只触发一次）
+func (s *StopLossMonitor) checkLossAndTrigger() bool {
+	if s.triggered.Load() {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	account, err := s.exchange.GetAccount(ctx)
+	if err != nil {
+		logger.Error("❌ [止损检查] 获取账户余额失败: %v", err)
+		return false
+	}
+
+	currentBalance := EffectiveBalance(account)
+	if currentBalance <= 0 {
+		logger.Warn("⚠️ [止损检查] 账户余额返回 %.2f，跳过本次检查", currentBalance)
+		return false
+	}
+	if err := s.sanity.Check(currentBalance); err != nil {
+		logger.Warn("⚠️ [止损检查] %v，跳过本次检查", err)
+		return false
+	}
+	s.lastBalance.Store(currentBalance)
+
+	initialBalance := s.initialBalance.Load().(float64)
+	change := currentBalance - initialBalance
+	maxLoss := s.maxLossFor(initialBalance)
+	logger.Debug("📊 [止损检查] 初始余额: %.2f USDT, 当前余额: %.2f USDT, 盈亏: %.2f USDT, 止损线: -%.2f USDT",
+		initialBalance, currentBalance, change, maxLoss)
+
+	if change > -maxLoss || !s.triggered.CompareAndSwap(false, true) {
+		return false
+	}
+
+	logger.Warn("🛑 [止损触发] ===")
+	logger.Warn("🛑 [止损触发] 初始余额: %.2f USDT", initialBalance)
+	logger.Warn("🛑 [止损触发] 当前余额: %.2f USDT", currentBalance)
+	logger.Warn("🛑 [止损触发] 亏损: %.2f USDT（止损线 %.2f USDT）", -change, maxLoss)
+	logger.Warn("🛑 [止损触发] ===")
+
+	event.Publish(event.TypeStopLossTriggered, event.StopLossTriggered{
+		InitialBalance: initialBalance,
+		CurrentBalance: currentBalance,
+		Loss:           -change,
+		MaxLoss:        maxLoss,
+	})
+	notify.Send(s.notifier, notify.Event{
+		Type:    notify.EventStopLossHit,
+		Message: "🛑 自动止损触发，程序退出",
+		Fields: map[string]interface{}{
+			"initial_balance": fmt.Sprintf("%.2f USDT", initialBalance),
+			"current_balance": fmt.Sprintf("%.2f USDT", currentBalance),
+			"loss":            fmt.Sprintf("%.2f USDT", -change),
+			"max_loss":        fmt.Sprintf("%.2f USDT", maxLoss),
+		},
+	})
+	return true
+}
+
+// MaxLoss 当前的止损金额（初始余额未记录时只按 max_loss_usdt 计算）
+func (s *StopLossMonitor) MaxLoss() float64 {
+	initialBalance, _ := s.initialBalance.Load().(float64)
+	return s.maxLossFor(initialBalance)
+}
+
+// maxLossFor 按初始余额计算止损金额：max_loss_usdt 与 初始余额 × max_loss_pct% 中较小的一个
+func (s *StopLossMonitor) maxLossFor(initialBalance float64) float64 {
+	stopLoss := s.cfg.Trading.StopLoss
+	maxLoss := stopLoss.MaxLossUSDT
+	if stopLoss.MaxLossPct > 0 && initialBalance > 0 {
+		if pctLoss := initialBalance * stopLoss.MaxLossPct / 100; maxLoss <= 0 || pctLoss < maxLoss {
+			maxLoss = pctLoss
+		}
+	}
+	return maxLoss
+}
+
+// IsTriggered 止损是否已触发
+func (s *StopLossMonitor) IsTriggered() bool {
+	return s.triggered.Load()
+}
+
+// GetCurrentLoss 返回初始余额、最近一次余额和亏损金额（盈利时为负数）
+func (s *StopLossMonitor) GetCurrentLoss() (float64, float64, float64) {
+	initialBalance, _ := s.initialBalance.Load().(float64)
+	currentBalance, _ := s.lastBalance.Load().(float64)
+	return initialBalance, currentBalance, initialBalance - currentBalance
+}
@@ -0,0 +1,106 @@
+package safety
+
+import (
+	"context"
+	"testing"
+
+	"opensqt/config"
+	"opensqt/exchange"
+)
+
+// balanceExchange 测试用交易所：依次返回预设的账户净值（用完后保持最后一个）
+type balanceExchange struct {
+	exchange.IExchange
+	balances []float64
+}
+
+func (f *balanceExchange) GetAccount(ctx context.Context) (*exchange.Account, error) {
+	balance := f.balances[0]
+	if len(f.balances) > 1 {
+		f.balances = f.balances[1:]
+	}
+	return &exchange.Account{TotalMarginBalance: balance}, nil
+}
+
+// newTestStopLoss 创建以 initial 为初始余额的止损监控，之后的检查依次读到 balances
+func newTestStopLoss(t *testing.T, maxLossUSDT, maxLossPct, initial float64, balances ...float64) *StopLossMonitor {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Trading.StopLoss.Enabled = true
+	cfg.Trading.StopLoss.MaxLossUSDT = maxLossUSDT
+	cfg.Trading.StopLoss.MaxLossPct = maxLossPct
+	s := NewStopLossMonitor(cfg, &balanceExchange{balances: append([]float64{initial}, balances...)})
+	if err := s.SetInitialBalance(context.Background()); err != nil {
+		t.Fatalf("记录初始余额失败: %v", err)
+	}
+	return s
+}
+
+func TestStopLossThresholds(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxLossUSDT float64
+		maxLossPct  float64
+		initial     float64
+		wantMaxLoss float64
+		balances    []float64 // 依次检查的净值
+		triggerAt   int       // 第几次检查触发（-1 表示不触发）
+	}{
+		{"金额止损：未达到止损线", 50, 0, 1000, 50, []float64{990, 960, 950.01}, -1},
+		{"金额止损：恰好达到止损线", 50, 0, 1000, 50, []float64{960, 950}, 1},
+		{"金额止损：跌破止损线", 50, 0, 1000, 50, []float64{980, 900}, 1},
+		{"比例止损：未达到止损线", 0, 5, 2000, 100, []float64{1950, 1900.01}, -1},
+		{"比例止损：恰好达到止损线", 0, 5, 2000, 100, []float64{1950, 1900}, 1},
+		{"同时设置时取较小的止损线（比例）", 500, 5, 2000, 100, []float64{1899}, 0},
+		{"同时设置时取较小的止损线（金额）", 50, 10, 2000, 50, []float64{1960, 1949}, 1},
+		{"盈利时不触发", 50, 5, 1000, 50, []float64{1100, 1200}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStopLoss(t, tt.maxLossUSDT, tt.maxLossPct, tt.initial, tt.balances...)
+			if got := s.MaxLoss(); got != tt.wantMaxLoss {
+				t.Fatalf("止损线应为 %.2f，实际 %.2f", tt.wantMaxLoss, got)
+			}
+			for i, balance := range tt.balances {
+				if got, want := s.checkLossAndTrigger(), i == tt.triggerAt; got != want {
+					t.Fatalf("第 %d 次检查（净值 %.2f）触发应为 %v，实际 %v", i+1, balance, want, got)
+				}
+			}
+			if s.IsTriggered() != (tt.triggerAt >= 0) {
+				t.Fatalf("IsTriggered 应为 %v", tt.triggerAt >= 0)
+			}
+		})
+	}
+}
+
+func TestStopLossPercentFollowsAdjustedBalance(t *testing.T) {
+	// 提现 1000 后基准变为 1000，比例止损线随之变为 50
+	s := newTestStopLoss(t, 0, 5, 2000, 960, 950)
+	s.AdjustInitialBalance(-1000)
+	if got := s.MaxLoss(); got != 50 {
+		t.Fatalf("调整基准后止损线应为 50，实际 %.2f", got)
+	}
+	if s.checkLossAndTrigger() {
+		t.Fatal("提现不应计为亏损")
+	}
+	if !s.checkLossAndTrigger() {
+		t.Fatal("相对调整后的基准亏损 50 应触发止损")
+	}
+}
+
+func TestStopLossNoOscillation(t *testing.T) {
+	// 净值在止损线附近来回波动：只在第一次达到止损线时触发一次，之后回升再跌破不重复触发
+	s := newTestStopLoss(t, 50, 0, 1000, 951, 950.5, 949, 960, 940, 1000, 900)
+	var triggers []int
+	for i := 0; i < 7; i++ {
+		if s.checkLossAndTrigger() {
+			triggers = append(triggers, i)
+		}
+	}
+	if len(triggers) != 1 || triggers[0] != 2 {
+		t.Fatalf("止损应只在第 3 次检查触发一次，实际触发于 %v", triggers)
+	}
+	if !s.IsTriggered() {
+		t.Fatal("净值回升后止损仍应保持已触发")
+	}
+}
@@ -0,0 +1,82 @@
+package safety
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"opensqt/config"
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// SymbolInfoMonitor 交易规则监控器
+// 合约信息（价格精度、数量精度、最小下单金额）在启动时获取并缓存，缓存过期后重新获取，
+// 检测到交易所调整规则时通知仓位管理器按新精度对齐网格，避免继续按旧精度下单被连续拒绝
+type SymbolInfoMonitor struct {
+	cfg      *config.Config
+	exchange exchange.IExchange
+	last     exchange.SymbolInfo
+}
+
+// NewSymbolInfoMonitor 创建交易规则监控器（初始规则取交易所启动时缓存的合约信息）
+func NewSymbolInfoMonitor(cfg *config.Config, ex exchange.IExchange) *SymbolInfoMonitor {
+	return &SymbolInfoMonitor{
+		cfg:      cfg,
+		exchange: ex,
+		last:     exchange.CurrentSymbolInfo(ex),
+	}
+}
+
+// Start 启动交易规则监控，规则变化时调用 onChange(旧规则, 新规则)
+func (m *SymbolInfoMonitor) Start(ctx context.Context, onChange func(old, updated exchange.SymbolInfo)) {
+	if !m.cfg.Trading.SymbolInfoRefresh.Enabled {
+		return
+	}
+
+	ttl := time.Duration(m.cfg.Trading.SymbolInfoRefresh.CacheTTL) * time.Second
+	logger.Info("📏 [交易规则] 监控启动 (价格精度: %d, 数量精度: %d, 最小下单金额: %.2f, 缓存有效期: %ds)",
+		m.last.PriceDecimals, m.last.QuantityDecimals, m.last.MinNotional, m.cfg.Trading.SymbolInfoRefresh.CacheTTL)
+
+	ticker := time.NewTicker(ttl)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !m.check(ctx, onChange) {
+				return
+			}
+		}
+	}
+}
+
+// check 重新获取一次合约信息，返回 false 表示交易所不支持刷新（停止监控）
+func (m *SymbolInfoMonitor) check(ctx context.Context, onChange func(old, updated exchange.SymbolInfo)) bool {
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	info, supported, err := exchange.RefreshSymbolInfo(checkCtx, m.exchange)
+	if !supported {
+		logger.Warn("⚠️ [交易规则] %s 不支持刷新合约信息，symbol_info_refresh 不生效", m.exchange.GetName())
+		return false
+	}
+	if err != nil {
+		logger.Warn("⚠️ [交易规则] 刷新合约信息失败: %v（继续使用缓存）", err)
+		return true
+	}
+
+	if info.PriceDecimals == m.last.PriceDecimals && info.QuantityDecimals == m.last.QuantityDecimals &&
+		math.Abs(info.MinNotional-m.last.MinNotional) < 1e-9 {
+		return true
+	}
+
+	old := m.last
+	m.last = info
+	logger.Warn("📏 [交易规则] 检测到交易规则变化: 价格精度 %d -> %d, 数量精度 %d -> %d, 最小下单金额 %.2f -> %.2f",
+		old.PriceDecimals, info.PriceDecimals, old.QuantityDecimals, info.QuantityDecimals, old.MinNotional, info.MinNotional)
+	onChange(old, info)
+	return true
+}
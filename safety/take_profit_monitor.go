@@ -3,9 +3,12 @@ package safety
 import (
 	"context"
 	"fmt"
+	"math"
 	"opensqt/config"
+	"opensqt/event"
 	"opensqt/exchange"
 	"opensqt/logger"
+	"opensqt/notify"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -16,37 +19,130 @@ type TakeProfitMonitor struct {
 	exchange       exchange.IExchange
 	initialBalance atomic.Value
 	lastBalance    atomic.Value
+	targetProfit   atomic.Value // float64 - 止盈目标金额（target_pct 模式下由初始余额换算）
 	triggered      atomic.Bool
 	isBalanceSet   atomic.Bool
+	pressure       IPressureSource // 接口压力来源（timing.adaptive_polling），nil 表示固定间隔
+	clock          IClock          // 检查调度时钟
+	sanity         *balanceSanity  // 余额读数合理性检查（safety.balance_sanity）
 	mu             sync.RWMutex
+
+	notifier notify.INotifier // 止盈触发告警（nil 表示不推送）
+
+	// 交易对 -> 交易所实例（balance_source 为 marked 时估值各交易对的持仓，未设置时只估值主交易对）
+	symbolExchanges map[string]exchange.IExchange
+}
+
+// SetPressureSource 设置接口压力来源，压力大时拉长检查间隔（需在 Start 之前调用）
+func (t *TakeProfitMonitor) SetPressureSource(source IPressureSource) {
+	t.pressure = source
 }
 
 func NewTakeProfitMonitor(cfg *config.Config, ex exchange.IExchange) *TakeProfitMonitor {
 	return &TakeProfitMonitor{
 		cfg:      cfg,
 		exchange: ex,
+		clock:    systemClock{},
+		sanity:   newBalanceSanity(cfg, "止盈检查"),
 	}
 }
 
+// SetSymbolExchanges 多交易对时设置各交易对的交易所实例（适配器绑定交易对，需用对应实例查询持仓和盘口），需在 SetInitialBalance 之前调用
+func (t *TakeProfitMonitor) SetSymbolExchanges(exchanges map[string]exchange.IExchange) {
+	t.symbolExchanges = exchanges
+}
+
+// SetNotifier 设置止盈触发的告警推送（需在 Start 之前调用）
+func (t *TakeProfitMonitor) SetNotifier(n notify.INotifier) {
+	t.notifier = n
+}
+
+// SetClock 设置检查调度时钟（需在 Start 之前调用）
+func (t *TakeProfitMonitor) SetClock(clock IClock) {
+	t.clock = clock
+}
+
+// SetInitialBalance 记录初始余额作为止盈基准
+// 获取账户失败时按 take_profit.init_balance_retries 退避重试（1s、2s、4s…，最长10s），全部失败才返回错误
 func (t *TakeProfitMonitor) SetInitialBalance(ctx context.Context) error {
+	retries := t.cfg.Trading.TakeProfit.InitBalanceRetries
 	account, err := t.exchange.GetAccount(ctx)
+	for attempt := 1; err != nil && attempt <= retries; attempt++ {
+		delay := time.Duration(math.Min(math.Pow(2, float64(attempt-1)), 10)) * time.Second
+		logger.Warn("⚠️ [止盈监控] 获取初始余额失败，%v 后第 %d/%d 次重试: %v", delay, attempt, retries, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("获取初始余额失败: %w", ctx.Err())
+		}
+		account, err = t.exchange.GetAccount(ctx)
+	}
 	if err != nil {
-		return fmt.Errorf("获取初始余额失败: %w", err)
+		return fmt.Errorf("获取初始余额失败（已重试 %d 次）: %w", retries, err)
 	}
 
-	balance := t.getEffectiveBalance(account)
+	balance, err := t.getEffectiveBalance(ctx, account)
+	if err != nil {
+		return fmt.Errorf("获取初始余额失败: %w", err)
+	}
 	if balance <= 0 {
-		return fmt.Errorf("账户余额无效: %.2f", balance)
+		return fmt.Errorf("账户余额无效 (来源: %s): %.2f", t.balanceSource(), balance)
 	}
 
 	t.initialBalance.Store(balance)
 	t.lastBalance.Store(balance)
+	t.updateTarget(balance)
+	t.sanity.Check(balance)
 	t.isBalanceSet.Store(true)
 
-	logger.Info("💰 [止盈监控] 初始余额已记录: %.2f USDT", balance)
+	logger.Info("💰 [止盈监控] 初始余额已记录: %.2f USDT (来源: %s)", balance, t.balanceSource())
 	return nil
 }
 
+// updateTarget 根据初始余额计算止盈目标金额
+func (t *TakeProfitMonitor) updateTarget(initialBalance float64) {
+	if pct := t.cfg.Trading.TakeProfit.TargetPct; pct > 0 {
+		t.targetProfit.Store(initialBalance * pct / 100)
+		return
+	}
+	t.targetProfit.Store(t.cfg.Trading.TakeProfit.TargetProfit)
+}
+
+// GetTargetProfit 获取止盈目标金额（USDT）
+func (t *TakeProfitMonitor) GetTargetProfit() float64 {
+	if target, ok := t.targetProfit.Load().(float64); ok {
+		return target
+	}
+	return t.cfg.Trading.TakeProfit.TargetProfit
+}
+
+// ApplyHistoricalPnL 把启动前已实现的净盈亏计入止盈基准（需在 SetInitialBalance 之后调用）
+// 初始余额回退为这些成交发生前的余额，止盈目标按整个回溯期的盈利计算
+func (t *TakeProfitMonitor) ApplyHistoricalPnL(pnl float64) {
+	if !t.isBalanceSet.Load() || pnl == 0 {
+		return
+	}
+
+	balance := t.initialBalance.Load().(float64) - pnl
+	t.initialBalance.Store(balance)
+	t.updateTarget(balance)
+	logger.Info("💰 [止盈监控] 已计入历史净盈亏 %.4f USDT，初始余额调整为: %.2f USDT", pnl, balance)
+}
+
+// AdjustInitialBalance 外部资金变动（充值/提现等）后调整止盈基准，避免资金变动被计为盈亏
+// target_pct 模式下止盈目标按调整后的初始余额重新计算
+func (t *TakeProfitMonitor) AdjustInitialBalance(delta float64) {
+	if !t.isBalanceSet.Load() || delta == 0 {
+		return
+	}
+
+	balance := t.initialBalance.Load().(float64) + delta
+	t.initialBalance.Store(balance)
+	t.updateTarget(balance)
+	logger.Info("💰 [止盈监控] 外部资金变动 %+.2f USDT，初始余额调整为: %.2f USDT, 止盈目标: %.2f USDT",
+		delta, balance, t.GetTargetProfit())
+}
+
 func (t *TakeProfitMonitor) Start(ctx context.Context, onTrigger func()) {
 	if !t.cfg.Trading.TakeProfit.Enabled {
 		logger.Info("⚠️ 自动止盈未启用")
@@ -58,11 +154,16 @@ func (t *TakeProfitMonitor) Start(ctx context.Context, onTrigger func()) {
 		checkInterval = 30
 	}
 
-	logger.Info("🎯 [止盈监控] 启动 (目标: %.2f USDT, 间隔: %d秒)",
-		t.cfg.Trading.TakeProfit.TargetProfit, checkInterval)
+	if pct := t.cfg.Trading.TakeProfit.TargetPct; pct > 0 {
+		logger.Info("🎯 [止盈监控] 启动 (模式: 收益率 %.2f%%, 目标: %.2f USDT, 间隔: %d秒)",
+			pct, t.GetTargetProfit(), checkInterval)
+	} else {
+		logger.Info("🎯 [止盈监控] 启动 (模式: 固定金额, 目标: %.2f USDT, 间隔: %d秒)",
+			t.GetTargetProfit(), checkInterval)
+	}
 
-	ticker := time.NewTicker(time.Duration(checkInterval) * time.Second)
-	defer ticker.Stop()
+	interval := newPollInterval(t.cfg, "止盈监控", time.Duration(checkInterval)*time.Second, t.pressure)
+	schedule := newDeadlineSchedule("止盈监控", t.clock, interval.next())
 
 	for {
 		select {
@@ -70,15 +171,13 @@ func (t *TakeProfitMonitor) Start(ctx context.Context, onTrigger func()) {
 			logger.Info("⏹️ [止盈监控] 监控已停止")
 			return
 
-		case <-ticker.C:
-			if !t.isBalanceSet.Load() {
-				continue
-			}
-
-			if t.checkProfitAndTrigger() {
+		case <-schedule.wait():
+			schedule.checkLate()
+			if t.isBalanceSet.Load() && t.checkProfitAndTrigger() {
 				onTrigger()
 				return
 			}
+			schedule.advance(interval.next())
 		}
 	}
 }
@@ -97,28 +196,59 @@ func (t *TakeProfitMonitor) checkProfitAndTrigger() bool {
 		return false
 	}
 
-	currentBalance := t.getEffectiveBalance(account)
+	currentBalance, err := t.getEffectiveBalance(ctx, account)
+	if err != nil {
+		logger.Error("❌ [止盈检查] 持仓估值失败: %v", err)
+		return false
+	}
+	if currentBalance <= 0 {
+		// 指定的余额字段暂时为0（交易所返回不完整），跳过本次检查而不是切换来源
+		logger.Warn("⚠️ [止盈检查] 余额来源 %s 返回 %.2f，跳过本次检查", t.balanceSource(), currentBalance)
+		return false
+	}
+	if err := t.sanity.Check(currentBalance); err != nil {
+		logger.Warn("⚠️ [止盈检查] %v，跳过本次检查", err)
+		return false
+	}
 	t.lastBalance.Store(currentBalance)
 
 	initialBalance := t.initialBalance.Load().(float64)
 	totalProfit := currentBalance - initialBalance
+	targetProfit := t.GetTargetProfit()
 
 	logger.Info("📊 [止盈检查] 初始余额: %.2f USDT, 当前余额: %.2f USDT, 盈利: %.2f USDT, 目标: %.2f USDT",
-		initialBalance, currentBalance, totalProfit, t.cfg.Trading.TakeProfit.TargetProfit)
+		initialBalance, currentBalance, totalProfit, targetProfit)
 
-	if totalProfit >= t.cfg.Trading.TakeProfit.TargetProfit {
+	if totalProfit >= targetProfit {
 		t.triggered.Store(true)
 
 		logger.Info("🎯 [止盈触发] ===")
 		logger.Info("🎯 [止盈触发] 初始余额: %.2f USDT", initialBalance)
 		logger.Info("🎯 [止盈触发] 当前余额: %.2f USDT", currentBalance)
 		logger.Info("🎯 [止盈触发] 总盈利: %.2f USDT", totalProfit)
-		logger.Info("🎯 [止盈触发] 目标盈利: %.2f USDT", t.cfg.Trading.TakeProfit.TargetProfit)
+		logger.Info("🎯 [止盈触发] 目标盈利: %.2f USDT", targetProfit)
 		if initialBalance > 0 {
 			logger.Info("🎯 [止盈触发] 盈利率: %.2f%%", (totalProfit/initialBalance)*100)
 		}
 		logger.Info("🎯 [止盈触发] ===")
 
+		event.Publish(event.TypeTakeProfitTriggered, event.TakeProfitTriggered{
+			InitialBalance: initialBalance,
+			CurrentBalance: currentBalance,
+			Profit:         totalProfit,
+			Target:         targetProfit,
+		})
+		notify.Send(t.notifier, notify.Event{
+			Type:    notify.EventTakeProfitHit,
+			Message: "🎯 自动止盈触发，程序退出",
+			Fields: map[string]interface{}{
+				"initial_balance": fmt.Sprintf("%.2f USDT", initialBalance),
+				"current_balance": fmt.Sprintf("%.2f USDT", currentBalance),
+				"profit":          fmt.Sprintf("%.2f USDT", totalProfit),
+				"target":          fmt.Sprintf("%.2f USDT", targetProfit),
+			},
+		})
+
 		return true
 	}
 
@@ -130,13 +260,134 @@ func (t *TakeProfitMonitor) IsTriggered() bool {
 }
 
 func (t *TakeProfitMonitor) GetCurrentProfit() (float64, float64, float64) {
-	initialBalance := t.initialBalance.Load().(float64)
-	currentBalance := t.lastBalance.Load().(float64)
+	initialBalance, _ := t.initialBalance.Load().(float64)
+	currentBalance, _ := t.lastBalance.Load().(float64)
 	profit := currentBalance - initialBalance
 	return initialBalance, currentBalance, profit
 }
 
-func (t *TakeProfitMonitor) getEffectiveBalance(account *exchange.Account) float64 {
+// getEffectiveBalance 按 take_profit.balance_source 选择计算盈利的余额
+func (t *TakeProfitMonitor) getEffectiveBalance(ctx context.Context, account *exchange.Account) (float64, error) {
+	switch t.balanceSource() {
+	case "margin":
+		return account.TotalMarginBalance, nil
+	case "wallet":
+		return account.TotalWalletBalance, nil
+	case "available":
+		return account.AvailableBalance, nil
+	case "marked":
+		if account.TotalWalletBalance <= 0 {
+			return account.TotalWalletBalance, nil
+		}
+		pnl, err := t.markedInventoryPnL(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return account.TotalWalletBalance + pnl, nil
+	default:
+		return EffectiveBalance(account), nil
+	}
+}
+
+// markedInventoryPnL 按 take_profit.inventory_mark 保守估值所有交易对持仓的未实现盈亏合计
+func (t *TakeProfitMonitor) markedInventoryPnL(ctx context.Context) (float64, error) {
+	exchanges := t.symbolExchanges
+	if len(exchanges) == 0 {
+		exchanges = map[string]exchange.IExchange{t.cfg.Trading.Symbol: t.exchange}
+	}
+	total := 0.0
+	for symbol, ex := range exchanges {
+		pnl, err := t.symbolInventoryPnL(ctx, ex, symbol)
+		if err != nil {
+			if len(exchanges) > 1 {
+				return 0, fmt.Errorf("%s %w", symbol, err)
+			}
+			return 0, err
+		}
+		total += pnl
+	}
+	return total, nil
+}
+
+// symbolInventoryPnL 保守估值单个交易对持仓的未实现盈亏
+// 多仓按买一价、空仓按卖一价（平仓实际可得的价格），避免标记价格的短暂波动把浮盈计为止盈
+func (t *TakeProfitMonitor) symbolInventoryPnL(ctx context.Context, ex exchange.IExchange, symbol string) (float64, error) {
+	positions, err := ex.GetPositions(ctx, symbol)
+	if err != nil {
+		return 0, fmt.Errorf("获取持仓失败: %w", err)
+	}
+
+	mode := t.cfg.Trading.TakeProfit.InventoryMark
+	haircut := t.cfg.Trading.TakeProfit.InventoryHaircutPct / 100
+	var top *exchange.OrderBookTop
+	var latest float64
+	pnl := 0.0
+	for _, pos := range positions {
+		if pos == nil || pos.Size == 0 || pos.Symbol != symbol {
+			continue
+		}
+		long := pos.Size > 0
+
+		price := pos.MarkPrice
+		if mode == "bid" {
+			if top == nil && latest == 0 {
+				if top, _, err = exchange.GetOrderBookTop(ctx, ex, symbol); err != nil {
+					return 0, fmt.Errorf("获取盘口失败: %w", err)
+				}
+				if top == nil {
+					// 交易所不支持盘口查询：取标记价格与最新价中较不利的一个
+					if latest, err = ex.GetLatestPrice(ctx, symbol); err != nil {
+						return 0, fmt.Errorf("获取最新价格失败: %w", err)
+					}
+				}
+			}
+			price = conservativePrice(long, top, pos.MarkPrice, latest)
+		}
+		if price <= 0 {
+			return 0, fmt.Errorf("持仓估值价格无效: %.8f", price)
+		}
+		if long {
+			price *= 1 - haircut
+		} else {
+			price *= 1 + haircut
+		}
+
+		unrealized := pos.Size * (price - pos.EntryPrice)
+		if mode == "loss_only" && unrealized > 0 {
+			unrealized = 0
+		}
+		pnl += unrealized
+	}
+	return pnl, nil
+}
+
+// conservativePrice 持仓的平仓估值价格：有盘口时多仓取买一、空仓取卖一，否则取标记价格与最新价中较不利的一个
+func conservativePrice(long bool, top *exchange.OrderBookTop, markPrice, latest float64) float64 {
+	if top != nil {
+		if long && top.BidPrice > 0 {
+			return top.BidPrice
+		}
+		if !long && top.AskPrice > 0 {
+			return top.AskPrice
+		}
+	}
+	price := markPrice
+	if latest > 0 && (price <= 0 || (long && latest < price) || (!long && latest > price)) {
+		price = latest
+	}
+	return price
+}
+
+// balanceSource 当前使用的余额来源（未配置时为 auto）
+func (t *TakeProfitMonitor) balanceSource() string {
+	if source := t.cfg.Trading.TakeProfit.BalanceSource; source != "" {
+		return source
+	}
+	return "auto"
+}
+
+// EffectiveBalance 账户净值：优先保证金余额（含未实现盈亏），其次钱包余额、可用余额
+func EffectiveBalance(account *exchange.Account) float64 {
 	balance := account.TotalMarginBalance
 	if balance > 0 {
 		return balance
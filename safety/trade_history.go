@@ -0,0 +1,96 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// TradeHistorySummary 启动前回溯期内的成交汇总
+type TradeHistorySummary struct {
+	Since          time.Time
+	Trades         int
+	BuyQty         float64
+	SellQty        float64
+	RealizedPnL    float64 // 已实现盈亏（不含手续费）
+	Fees           float64 // 计价币种支付的手续费
+	InventoryQty   float64 // 回溯期内买入后尚未卖出的数量
+	InventoryBasis float64 // 上述数量的平均持仓成本
+}
+
+// NetPnL 扣除手续费后的已实现盈亏
+func (s *TradeHistorySummary) NetPnL() float64 {
+	return s.RealizedPnL - s.Fees
+}
+
+// LoadTradeHistory 查询回溯期内的成交并汇总（用于重启后延续累计统计和止盈基准）
+// 交易所返回单笔已实现盈亏时直接累加，否则按回溯期内的平均持仓成本估算；
+// 回溯期之前建立的持仓成本未知，卖出超过回溯期内买入数量的部分不计盈亏
+func LoadTradeHistory(ctx context.Context, ex exchange.IExchange, symbol string, lookback time.Duration) (*TradeHistorySummary, error) {
+	since := time.Now().Add(-lookback)
+	trades, err := ex.GetUserTrades(ctx, symbol, since)
+	if err != nil {
+		return nil, fmt.Errorf("查询历史成交失败: %w", err)
+	}
+
+	quoteAsset := ex.GetQuoteAsset()
+	summary := &TradeHistorySummary{Since: since, Trades: len(trades)}
+
+	var exchangePnL, estimatedPnL float64
+	hasExchangePnL := false
+	skippedFeeAssets := make(map[string]bool)
+
+	for _, t := range trades {
+		if t.FeeAsset == "" || t.FeeAsset == quoteAsset {
+			summary.Fees += t.Fee
+		} else if t.Fee != 0 {
+			skippedFeeAssets[t.FeeAsset] = true
+		}
+		if t.RealizedPnL != 0 {
+			hasExchangePnL = true
+		}
+		exchangePnL += t.RealizedPnL
+
+		if t.Side == exchange.SideBuy {
+			summary.BuyQty += t.Quantity
+			newQty := summary.InventoryQty + t.Quantity
+			summary.InventoryBasis = (summary.InventoryBasis*summary.InventoryQty + t.Price*t.Quantity) / newQty
+			summary.InventoryQty = newQty
+			continue
+		}
+
+		summary.SellQty += t.Quantity
+		closeQty := t.Quantity
+		if closeQty > summary.InventoryQty {
+			closeQty = summary.InventoryQty
+		}
+		estimatedPnL += (t.Price - summary.InventoryBasis) * closeQty
+		summary.InventoryQty -= closeQty
+		if summary.InventoryQty <= 0 {
+			summary.InventoryQty = 0
+			summary.InventoryBasis = 0
+		}
+	}
+
+	pnlSource := "交易所"
+	summary.RealizedPnL = exchangePnL
+	if !hasExchangePnL {
+		pnlSource = "按平均成本估算"
+		summary.RealizedPnL = estimatedPnL
+	}
+
+	logger.Info("📜 [历史成交] %s 起共 %d 笔: 买入 %.4f, 卖出 %.4f, 已实现盈亏 %.4f（%s）, 手续费 %.4f, 净盈亏 %.4f %s",
+		since.Format("2006-01-02 15:04:05"), summary.Trades, summary.BuyQty, summary.SellQty,
+		summary.RealizedPnL, pnlSource, summary.Fees, summary.NetPnL(), quoteAsset)
+	if summary.InventoryQty > 0 {
+		logger.Info("📜 [历史成交] 回溯期内未卖出的持仓: %.4f, 平均成本: %.6f", summary.InventoryQty, summary.InventoryBasis)
+	}
+	for asset := range skippedFeeAssets {
+		logger.Warn("⚠️ [历史成交] 部分手续费以 %s 支付，未计入净盈亏", asset)
+	}
+
+	return summary, nil
+}
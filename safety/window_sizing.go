@@ -0,0 +1,101 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// WindowSizingParams 按目标资金使用率计算买单窗口所需的参数
+type WindowSizingParams struct {
+	Symbol            string
+	CurrentPrice      float64
+	OrderAmount       float64 // 每笔金额（计价币种）
+	PriceInterval     float64
+	CapitalAllocation float64 // 分配给该交易对的资金（0 表示不限制）
+	TargetUtilization float64 // 目标资金使用率（百分比）
+	MaxOrders         int     // 买单窗口上限（避免与订单清理阈值冲突）
+	PriceDecimals     int
+}
+
+// DeriveBuyWindow 按目标资金使用率计算买单窗口大小（trading.target_utilization）
+// 满仓（窗口内买单全部成交、价格跌到最低一档）时占用的资金 = 保证金（名义价值 / 杠杆）+ 浮动亏损，
+// 取占用资金不超过 余额 × 目标使用率 的最大窗口
+func DeriveBuyWindow(ex exchange.IExchange, p WindowSizingParams) (int, error) {
+	ctx := context.Background()
+	quoteCurrency := ex.GetQuoteAsset()
+
+	account, err := ex.GetAccount(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("获取账户信息失败: %w", err)
+	}
+	balance := account.AvailableBalance
+	if p.CapitalAllocation > 0 && p.CapitalAllocation < balance {
+		balance = p.CapitalAllocation
+	}
+	if balance <= 0 {
+		return 0, fmt.Errorf("账户余额不足，当前余额: %.2f %s", balance, quoteCurrency)
+	}
+
+	leverage := 1
+	if positions, err := ex.GetPositions(ctx, p.Symbol); err == nil {
+		for _, pos := range positions {
+			if pos.Symbol == p.Symbol && pos.Leverage > 0 {
+				leverage = pos.Leverage
+				break
+			}
+		}
+	}
+	if leverage == 1 && account.AccountLeverage > 0 {
+		leverage = account.AccountLeverage
+	}
+
+	budget := balance * p.TargetUtilization / 100
+
+	window := 0
+	var margin, floatingLoss float64
+	for n := 1; n <= p.MaxOrders; n++ {
+		bottom := p.CurrentPrice - float64(n)*p.PriceInterval
+		if bottom <= 0 {
+			break
+		}
+		nextMargin, nextLoss := fullDepthUsage(p.CurrentPrice, p.OrderAmount, p.PriceInterval, n, leverage)
+		if nextMargin+nextLoss > budget {
+			break
+		}
+		window, margin, floatingLoss = n, nextMargin, nextLoss
+	}
+
+	if window == 0 {
+		return 0, fmt.Errorf("目标资金使用率 %.2f%% 过低：可用资金 %.2f %s 不足以挂出一个买单（每笔 %.2f %s，杠杆 %dx）",
+			p.TargetUtilization, budget, quoteCurrency, p.OrderAmount, quoteCurrency, leverage)
+	}
+
+	logger.Info("📐 [窗口计算] 余额 %.2f %s × 目标使用率 %.2f%% = %.2f %s, 杠杆 %dx, 每笔 %.2f %s, 间隔 %.*f",
+		balance, quoteCurrency, p.TargetUtilization, budget, quoteCurrency, leverage,
+		p.OrderAmount, quoteCurrency, p.PriceDecimals, p.PriceInterval)
+	logger.Info("📐 [窗口计算] 买单窗口: %d 层 (覆盖 %.*f ~ %.*f), 满仓名义价值: %.2f %s, 保证金: %.2f %s, 最低档浮亏: %.2f %s, 合计占用 %.2f%%",
+		window, p.PriceDecimals, p.CurrentPrice-float64(window)*p.PriceInterval, p.PriceDecimals, p.CurrentPrice-p.PriceInterval,
+		float64(window)*p.OrderAmount, quoteCurrency, margin, quoteCurrency, floatingLoss, quoteCurrency,
+		(margin+floatingLoss)/balance*100)
+	if window == p.MaxOrders {
+		logger.Warn("⚠️ [窗口计算] 买单窗口已达上限 %d（受订单清理阈值限制），实际资金使用率低于目标", p.MaxOrders)
+	}
+
+	return window, nil
+}
+
+// fullDepthUsage 计算 n 层买单全部成交、价格跌到最低一档时的保证金和浮动亏损
+// 第 i 层买入价 price - i×interval，数量 orderAmount / 买入价
+func fullDepthUsage(price, orderAmount, interval float64, n, leverage int) (margin, floatingLoss float64) {
+	bottom := price - float64(n)*interval
+	for i := 1; i <= n; i++ {
+		buyPrice := price - float64(i)*interval
+		qty := orderAmount / buyPrice
+		floatingLoss += qty * (buyPrice - bottom)
+	}
+	margin = float64(n) * orderAmount / float64(leverage)
+	return margin, floatingLoss
+}
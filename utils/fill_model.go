@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// FillModel 挂单成交模型（模拟交易所和 system.dry_run 的模拟成交引擎共用，零值为触价立即成交，最乐观）
+// 挂单价格需被穿过 QueueThroughTicks 个最小价格单位才成交，近似排在同价位前面的挂单先被吃掉；
+// 满足条件后再等待 Latency 才成交，期间撤单仍可成功（模拟撮合和推送延迟）
+type FillModel struct {
+	Latency           time.Duration
+	QueueThroughTicks int
+
+	mu      sync.Mutex
+	pending map[int64]bool // 已满足成交条件、等待 Latency 的订单
+}
+
+// NewFillModel 创建成交模型
+func NewFillModel(latency time.Duration, queueThroughTicks int) *FillModel {
+	return &FillModel{Latency: latency, QueueThroughTicks: queueThroughTicks}
+}
+
+// Crossed 最新价格是否已穿过挂单价 QueueThroughTicks 个最小价格单位（buy 为买单，价格均已按 priceDecimals 取整）
+func (m *FillModel) Crossed(buy bool, orderPrice, price float64, priceDecimals int) bool {
+	tick := math.Pow(10, -float64(priceDecimals))
+	// 留出半个最小价格单位的浮点余量
+	through := float64(m.QueueThroughTicks)*tick - tick/2
+	if buy {
+		return price <= orderPrice-through
+	}
+	return price >= orderPrice+through
+}
+
+// Pending 订单是否已满足成交条件、正在等待 Latency
+func (m *FillModel) Pending(orderID int64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pending[orderID]
+}
+
+// Schedule 订单满足成交条件后调用：未设置 Latency 时返回 false，由调用方立即成交；
+// 否则登记等待，Latency 到期后调用 fill（fill 需自行确认订单仍未撤销），返回 true
+func (m *FillModel) Schedule(orderID int64, fill func()) bool {
+	if m.Latency <= 0 {
+		return false
+	}
+	m.mu.Lock()
+	if m.pending == nil {
+		m.pending = make(map[int64]bool)
+	}
+	m.pending[orderID] = true
+	m.mu.Unlock()
+
+	time.AfterFunc(m.Latency, func() {
+		fill()
+		m.mu.Lock()
+		delete(m.pending, orderID)
+		m.mu.Unlock()
+	})
+	return true
+}
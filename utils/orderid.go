@@ -112,6 +112,7 @@ func ParseOrderID(clientOrderID string, priceDecimals int) (float64, string, int
 // 交易所限制:
 //   - Binance: 36字符限制，返佣前缀 "x-zdfVM8vY" (10字符)
 //   - Gate.io: 30字符限制，返佣前缀 "t-" (2字符)
+//   - OKX: 32字符限制，只允许字母和数字，下划线替换为 "x"（无返佣前缀）
 func AddBrokerPrefix(exchange, clientOrderID string) string {
 	switch exchange {
 	case "binance":
@@ -148,6 +149,10 @@ func AddBrokerPrefix(exchange, clientOrderID string) string {
 		}
 		return result
 
+	case "okx":
+		// OKX clOrdId 只允许字母和数字，紧凑ID中的下划线替换为 x（ID 只含数字、B/S 和下划线，可无歧义还原）
+		return strings.ReplaceAll(clientOrderID, "_", "x")
+
 	default:
 		return clientOrderID
 	}
@@ -169,6 +174,14 @@ func RemoveBrokerPrefix(exchange, clientOrderID string) string {
 		}
 		return clientOrderID
 
+	case "okx":
+		// 只还原符合紧凑ID格式的订单（手动下单或其他程序的订单保持原样）
+		restored := strings.ReplaceAll(clientOrderID, "x", "_")
+		if _, _, _, valid := ParseOrderID(restored, 0); valid {
+			return restored
+		}
+		return clientOrderID
+
 	default:
 		return clientOrderID
 	}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package utils
+
+import (
+	"os"
+	"syscall"
+)
+
+// 紧急平仓/恢复交易信号
+var (
+	FlattenSignal os.Signal = syscall.SIGUSR1 // 撤销所有订单并市价平仓，进程保持运行
+	ResumeSignal  os.Signal = syscall.SIGUSR2 // 紧急平仓后恢复自动交易
+)
+
+// ReloadSignal 重新加载配置文件并在运行中应用交易参数
+var ReloadSignal os.Signal = syscall.SIGHUP
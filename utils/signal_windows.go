@@ -0,0 +1,14 @@
+//go:build windows
+
+package utils
+
+import "os"
+
+// 紧急平仓/恢复交易信号（Windows 不支持 SIGUSR1/SIGUSR2，只能通过管理接口触发）
+var (
+	FlattenSignal os.Signal
+	ResumeSignal  os.Signal
+)
+
+// ReloadSignal 配置热重载信号（Windows 不支持 SIGHUP，修改配置后需重启）
+var ReloadSignal os.Signal
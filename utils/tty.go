@@ -0,0 +1,16 @@
+package utils
+
+import "os"
+
+// IsTerminal 判断文件是否为终端；后台运行、重定向或 nohup（标准输入为 /dev/null）时为 false
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// /dev/null 也是字符设备，需要单独排除
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
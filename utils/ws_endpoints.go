@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+
+	"opensqt/logger"
+)
+
+// defaultWSFailoverAfter 同一地址连续连接失败多少次后切换到下一个地址
+const defaultWSFailoverAfter = 3
+
+// WSEndpoints WebSocket 地址列表与故障切换（同一交易所的各个推送流共用）
+// 地址为 scheme://host 形式的基础地址，由各推送流拼接自己的路径。
+// 当前地址连续连接失败达到阈值后轮换到下一个地址（循环），连接成功后清零失败计数
+type WSEndpoints struct {
+	name string // 交易所名称（日志用）
+
+	mu            sync.Mutex
+	urls          []string
+	index         int
+	failures      int
+	failoverAfter int
+}
+
+// NewWSEndpoints 创建地址列表：override 为逗号分隔的配置覆盖（为空时使用 defaults），failoverAfter<=0 时默认3
+func NewWSEndpoints(name, override string, defaults []string, failoverAfter int) *WSEndpoints {
+	var urls []string
+	for _, u := range strings.Split(override, ",") {
+		if u = strings.TrimSuffix(strings.TrimSpace(u), "/"); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		urls = defaults
+	}
+	if failoverAfter <= 0 {
+		failoverAfter = defaultWSFailoverAfter
+	}
+	if len(urls) > 1 {
+		logger.Info("🔀 [%s] WebSocket 地址: %s（连续失败 %d 次切换）", name, strings.Join(urls, ", "), failoverAfter)
+	}
+	return &WSEndpoints{name: name, urls: urls, failoverAfter: failoverAfter}
+}
+
+// Active 当前使用的地址
+func (e *WSEndpoints) Active() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.urls[e.index]
+}
+
+// OnConnected 连接 url 成功，清零失败计数
+func (e *WSEndpoints) OnConnected(url string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.urls[e.index] == url {
+		e.failures = 0
+	}
+}
+
+// OnConnectFailed 连接 url 失败，达到阈值时切换到下一个地址
+// 已被其他推送流切换走的旧地址不再计数
+func (e *WSEndpoints) OnConnectFailed(url string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.urls[e.index] != url || len(e.urls) < 2 {
+		return
+	}
+	e.failures++
+	if e.failures < e.failoverAfter {
+		return
+	}
+	e.index = (e.index + 1) % len(e.urls)
+	e.failures = 0
+	logger.Warn("🔀 [%s] WebSocket 地址 %s 连续 %d 次连接失败，切换到 %s", e.name, url, e.failoverAfter, e.urls[e.index])
+}